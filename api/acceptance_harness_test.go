@@ -0,0 +1,134 @@
+//go:build acceptance
+
+package api
+
+// The acceptance tests run against a live Keyfactor Command instance and are only built with the acceptance tag:
+//
+//	KEYFACTOR_HOSTNAME=... KEYFACTOR_USERNAME=... KEYFACTOR_PASSWORD=... KEYFACTOR_COMMAND_VERSION=12.0 \
+//		go test -tags acceptance -run '^TestAcceptance' ./api/
+//
+// KEYFACTOR_COMMAND_VERSION is the major.minor version of the target server. Tests of features that the target does
+// not have call requireCapability, which skips them when the version is too old or the server does not advertise the
+// endpoint, so the same suite can be run against every supported Command release.
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// EnvCommandVersion names the environment variable holding the Command version targeted by the acceptance tests.
+const EnvCommandVersion = "KEYFACTOR_COMMAND_VERSION"
+
+// capability is a server feature that acceptance tests may depend on.
+type capability struct {
+	name string
+	// minVersion is the first Command release with the feature.
+	minVersion string
+	// endpoint is an endpoint only servers with the feature advertise under /Status/Endpoints, as a method and path
+	// such as "GET /Audit". It catches features that are licensed or disabled on a server that is new enough.
+	endpoint string
+}
+
+// Capabilities checked by the acceptance tests. Add one here when contributing an endpoint that older servers lack.
+var (
+	capabilitySecurityClaims = capability{name: "security claims", minVersion: "11.0", endpoint: "GET /Security/Claims"}
+	capabilityCAManagement   = capability{name: "CA management", minVersion: "10.0", endpoint: "GET /CertificateAuthority"}
+	capabilityAuditLog       = capability{name: "audit log", minVersion: "10.0", endpoint: "GET /Audit"}
+)
+
+var (
+	acceptanceOnce      sync.Once
+	acceptanceC         *Client
+	acceptanceVersion   commandVersion
+	acceptanceEndpoints []string
+	acceptanceErr       error
+)
+
+// acceptanceClient returns the client shared by the acceptance tests, failing t if the target server is not
+// configured or cannot be reached.
+func acceptanceClient(t *testing.T) *Client {
+	t.Helper()
+	acceptanceOnce.Do(func() {
+		acceptanceVersion, acceptanceErr = parseCommandVersion(os.Getenv(EnvCommandVersion))
+		if acceptanceErr != nil {
+			acceptanceErr = fmt.Errorf("%s: %v", EnvCommandVersion, acceptanceErr)
+			return
+		}
+		acceptanceC, acceptanceErr = NewKeyfactorClient(&AuthConfig{})
+		if acceptanceErr != nil {
+			return
+		}
+		acceptanceEndpoints, acceptanceErr = acceptanceC.getStatusEndpoints()
+	})
+	if acceptanceErr != nil {
+		t.Fatalf("acceptance tests need a reachable Keyfactor Command instance: %v", acceptanceErr)
+	}
+	return acceptanceC
+}
+
+// requireCapability skips t unless the target server has feature.
+func requireCapability(t *testing.T, feature capability) {
+	t.Helper()
+	acceptanceClient(t)
+	min, err := parseCommandVersion(feature.minVersion)
+	if err != nil {
+		t.Fatalf("capability %s: %v", feature.name, err)
+	}
+	if acceptanceVersion.less(min) {
+		t.Skipf("%s requires Command %s or later; target is %s", feature.name, feature.minVersion, acceptanceVersion)
+	}
+	if feature.endpoint != "" && !advertisesEndpoint(acceptanceEndpoints, feature.endpoint) {
+		t.Skipf("%s is not available: the server does not advertise %s", feature.name, feature.endpoint)
+	}
+}
+
+// advertisesEndpoint reports whether endpoint, a method and path, is among the advertised endpoints. The path is
+// matched anywhere in each advertised endpoint, so that it does not matter whether they include the API path.
+func advertisesEndpoint(advertised []string, endpoint string) bool {
+	method, path, _ := strings.Cut(endpoint, " ")
+	for _, e := range advertised {
+		if strings.HasPrefix(strings.ToUpper(e), strings.ToUpper(method)+" ") && strings.Contains(strings.ToLower(e), strings.ToLower(path)) {
+			return true
+		}
+	}
+	return false
+}
+
+// commandVersion is a Keyfactor Command major.minor release.
+type commandVersion struct {
+	major, minor int
+}
+
+func parseCommandVersion(s string) (commandVersion, error) {
+	if s == "" {
+		return commandVersion{}, fmt.Errorf("a Command version such as 12.0 is required")
+	}
+	majorStr, minorStr, _ := strings.Cut(s, ".")
+	major, err := strconv.Atoi(majorStr)
+	if err != nil {
+		return commandVersion{}, fmt.Errorf("invalid Command version %q", s)
+	}
+	var minor int
+	if minorStr != "" {
+		minorStr, _, _ = strings.Cut(minorStr, ".")
+		if minor, err = strconv.Atoi(minorStr); err != nil {
+			return commandVersion{}, fmt.Errorf("invalid Command version %q", s)
+		}
+	}
+	return commandVersion{major: major, minor: minor}, nil
+}
+
+func (v commandVersion) less(other commandVersion) bool {
+	if v.major != other.major {
+		return v.major < other.major
+	}
+	return v.minor < other.minor
+}
+
+func (v commandVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.major, v.minor)
+}
@@ -0,0 +1,101 @@
+//go:build acceptance
+
+package api
+
+import (
+	"errors"
+	"testing"
+)
+
+// The acceptance tests only read from the target server, so they are safe to run against shared instances.
+
+func TestAcceptance_SearchCertificates(t *testing.T) {
+	c := acceptanceClient(t)
+	errStop := errors.New("stop")
+	err := c.SearchCertificatePages("", &SearchCertificatesOptions{PageSize: 5, Verbose: VerboseSANs}, func(page []GetCertificateResponse) error {
+		for _, cert := range page {
+			if cert.Id == 0 || cert.Thumbprint == "" {
+				t.Errorf("certificate missing Id or Thumbprint: %+v", cert)
+			}
+			if _, err := cert.SubjectAltNames(); err != nil {
+				t.Errorf("SubjectAltNames() error = %v", err)
+			}
+		}
+		return errStop
+	})
+	if err != nil && !errors.Is(err, errStop) {
+		t.Fatalf("SearchCertificatePages() error = %v", err)
+	}
+}
+
+func TestAcceptance_GetTemplates(t *testing.T) {
+	c := acceptanceClient(t)
+	if _, err := c.GetTemplates(); err != nil {
+		t.Fatalf("GetTemplates() error = %v", err)
+	}
+}
+
+func TestAcceptance_ListCertificateStoreTypes(t *testing.T) {
+	c := acceptanceClient(t)
+	types, err := c.ListCertificateStoreTypes()
+	if err != nil {
+		t.Fatalf("ListCertificateStoreTypes() error = %v", err)
+	}
+	if types == nil || len(*types) == 0 {
+		t.Errorf("ListCertificateStoreTypes() returned no store types; Command ships with built-in types")
+	}
+}
+
+func TestAcceptance_GetAgentList(t *testing.T) {
+	c := acceptanceClient(t)
+	if _, err := c.GetAgentList(); err != nil {
+		t.Fatalf("GetAgentList() error = %v", err)
+	}
+}
+
+func TestAcceptance_GetCA(t *testing.T) {
+	requireCapability(t, capabilityCAManagement)
+	c := acceptanceClient(t)
+	cas, err := c.GetCAList()
+	if err != nil {
+		t.Fatalf("GetCAList() error = %v", err)
+	}
+	if len(cas) == 0 {
+		t.Skip("no certificate authorities are configured")
+	}
+	ca, err := c.GetCA(cas[0].Id)
+	if err != nil {
+		t.Fatalf("GetCA() error = %v", err)
+	}
+	if ca.LogicalName != cas[0].LogicalName {
+		t.Errorf("GetCA() LogicalName = %q, want %q", ca.LogicalName, cas[0].LogicalName)
+	}
+}
+
+func TestAcceptance_SearchAuditLogs(t *testing.T) {
+	requireCapability(t, capabilityAuditLog)
+	c := acceptanceClient(t)
+	if _, err := c.SearchAuditLogs("", &Paging{ReturnLimit: 5}); err != nil {
+		t.Fatalf("SearchAuditLogs() error = %v", err)
+	}
+}
+
+func TestAcceptance_SecurityModel(t *testing.T) {
+	c := acceptanceClient(t)
+	model, err := c.GetSecurityModel()
+	if err != nil {
+		t.Fatalf("GetSecurityModel() error = %v", err)
+	}
+	wantClaims := !acceptanceVersion.less(commandVersion{major: 11})
+	if (model == SecurityModelClaims) != wantClaims {
+		t.Errorf("GetSecurityModel() = %d for Command %s", model, acceptanceVersion)
+	}
+}
+
+func TestAcceptance_GetSecurityClaims(t *testing.T) {
+	requireCapability(t, capabilitySecurityClaims)
+	c := acceptanceClient(t)
+	if _, err := c.GetSecurityClaims(); err != nil {
+		t.Fatalf("GetSecurityClaims() error = %v", err)
+	}
+}
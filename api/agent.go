@@ -3,27 +3,28 @@ package api
 import (
 	"context"
 	"fmt"
-
-	"github.com/Keyfactor/keyfactor-go-client-sdk/api/keyfactor"
 )
 
 // GetAgentList returns a list of orchestrators registered in the Keyfactor instance
 func (c *Client) GetAgentList() ([]Agent, error) {
+	return c.GetAgentListContext(context.Background())
+}
+
+// GetAgentListContext is like GetAgentList but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) GetAgentListContext(ctx context.Context) ([]Agent, error) {
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
-
-	resp, _, err := apiClient.AgentApi.AgentGetAgents(context.Background()).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	apiClient := c.sdkClient()
 
-	var revResp []Agent
+	resp, _, err := apiClient.AgentApi.AgentGetAgents(ctx).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
-		return revResp, err
+		return nil, err
 	}
 
+	revResp := make([]Agent, 0, len(resp))
 	for i := range resp {
 		newAgent := Agent{
 			AgentId:          *resp[i].AgentId,
@@ -35,9 +36,10 @@ func (c *Client) GetAgentList() ([]Agent, error) {
 			EnableDiscover:   false, //TODO
 			EnableMonitor:    false, //TODO
 			Version:          *resp[i].Version,
-			LastSeen:         resp[i].LastSeen.String(),
+			LastSeen:         NewTimestamp(resp[i].GetLastSeen()),
 			Thumbprint:       *resp[i].Thumbprint,
 			LegacyThumbprint: *resp[i].LegacyThumbprint,
+			Capabilities:     resp[i].Capabilities,
 		}
 		revResp = append(revResp, newAgent)
 	}
@@ -46,22 +48,27 @@ func (c *Client) GetAgentList() ([]Agent, error) {
 }
 
 func (c *Client) GetAgent(id string) ([]Agent, error) {
+	return c.GetAgentContext(context.Background(), id)
+}
+
+// GetAgentContext is like GetAgent but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) GetAgentContext(ctx context.Context, id string) ([]Agent, error) {
+	if err := validateGUID("orchestrator agent", id); err != nil {
+		return nil, err
+	}
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
-	resp, _, err := apiClient.AgentApi.AgentGetAgentDetail(context.Background(), id).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
-
-	var revResp []Agent
+	resp, _, err := apiClient.AgentApi.AgentGetAgentDetail(ctx, id).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
-		return revResp, err
+		return nil, err
 	}
 
-	revResp = []Agent{
+	revResp := []Agent{
 		{
 			AgentId:          resp.GetAgentId(),
 			AgentPoolId:      "",
@@ -72,9 +79,10 @@ func (c *Client) GetAgent(id string) ([]Agent, error) {
 			EnableDiscover:   false, //TODO
 			EnableMonitor:    false, //TODO
 			Version:          resp.GetVersion(),
-			LastSeen:         resp.GetLastSeen().String(),
+			LastSeen:         NewTimestamp(resp.GetLastSeen()),
 			Thumbprint:       resp.GetThumbprint(),
 			LegacyThumbprint: resp.GetLegacyThumbprint(),
+			Capabilities:     resp.GetCapabilities(),
 		},
 	}
 
@@ -82,18 +90,25 @@ func (c *Client) GetAgent(id string) ([]Agent, error) {
 }
 
 func (c *Client) ApproveAgent(id string) (string, error) {
+	return c.ApproveAgentContext(context.Background(), id)
+}
+
+// ApproveAgentContext is like ApproveAgent but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) ApproveAgentContext(ctx context.Context, id string) (string, error) {
+	if err := validateGUID("orchestrator agent", id); err != nil {
+		return "", err
+	}
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
 	var ids = []string{id}
 
-	resp, err := apiClient.AgentApi.AgentApprove(context.Background()).XKeyfactorRequestedWith(xKeyfactorRequestedWith).AgentIds(ids).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, err := apiClient.AgentApi.AgentApprove(ctx).XKeyfactorRequestedWith(xKeyfactorRequestedWith).AgentIds(ids).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
-	if resp.StatusCode == 204 {
+	if resp != nil && resp.StatusCode == 204 {
 		return "Approve agent successful.", nil
 	}
 
@@ -105,16 +120,23 @@ func (c *Client) ApproveAgent(id string) (string, error) {
 }
 
 func (c *Client) DisApproveAgent(id string) (string, error) {
+	return c.DisApproveAgentContext(context.Background(), id)
+}
+
+// DisApproveAgentContext is like DisApproveAgent but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) DisApproveAgentContext(ctx context.Context, id string) (string, error) {
+	if err := validateGUID("orchestrator agent", id); err != nil {
+		return "", err
+	}
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
 	var ids = []string{id}
 
-	resp, err := apiClient.AgentApi.AgentDisapprove(context.Background()).AgentIds(ids).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, err := apiClient.AgentApi.AgentDisapprove(ctx).AgentIds(ids).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if resp.StatusCode == 204 {
 		return fmt.Sprintf("Disapproving %s successful.", id), nil
@@ -128,14 +150,21 @@ func (c *Client) DisApproveAgent(id string) (string, error) {
 }
 
 func (c *Client) ResetAgent(id string) (string, error) {
+	return c.ResetAgentContext(context.Background(), id)
+}
+
+// ResetAgentContext is like ResetAgent but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) ResetAgentContext(ctx context.Context, id string) (string, error) {
+	if err := validateGUID("orchestrator agent", id); err != nil {
+		return "", err
+	}
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
-	resp, err := apiClient.AgentApi.AgentReset1(context.Background(), id).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, err := apiClient.AgentApi.AgentReset1(ctx, id).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if resp.StatusCode == 204 {
 		return "Reset agent successful.", nil
@@ -149,17 +178,24 @@ func (c *Client) ResetAgent(id string) (string, error) {
 }
 
 func (c *Client) FetchAgentLogs(id string) (string, error) {
+	return c.FetchAgentLogsContext(context.Background(), id)
+}
+
+// FetchAgentLogsContext is like FetchAgentLogs but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) FetchAgentLogsContext(ctx context.Context, id string) (string, error) {
+	if err := validateGUID("orchestrator agent", id); err != nil {
+		return "", err
+	}
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
-	resp, err := apiClient.AgentApi.AgentFetchLogs(context.Background(), id).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, err := apiClient.AgentApi.AgentFetchLogs(ctx, id).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
-	if resp.StatusCode == 204 {
-		return "Reset agent successful.", nil
+	if resp != nil && resp.StatusCode == 204 {
+		return "Fetch logs successful.", nil
 	}
 
 	if err != nil {
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// DefaultAgentApprovalInterval is the time between checks for new orchestrators used by AutoApproveAgents when
+// AgentAutoApprovalOptions.Interval is not set.
+const DefaultAgentApprovalInterval = time.Minute
+
+// AgentApprovalRule describes orchestrators that may be approved without review. Patterns are shell globs as
+// understood by path.Match, such as "*.prod.example.com", and are compared case-insensitively.
+type AgentApprovalRule struct {
+	// ClientMachine is the pattern the orchestrator's client machine name must match.
+	ClientMachine string
+	// Capabilities are patterns that must each match at least one capability the orchestrator registered, e.g.
+	// "CertStores.IISU.*". An empty list matches any orchestrator.
+	Capabilities []string
+}
+
+// AgentAutoApprovalOptions configures AutoApproveAgents.
+type AgentAutoApprovalOptions struct {
+	// Rules lists the orchestrators to approve. An orchestrator matching any rule is approved. Required.
+	Rules []AgentApprovalRule
+	// Interval is the time between checks for new orchestrators. It defaults to DefaultAgentApprovalInterval.
+	Interval time.Duration
+	// OnApprove is called for each orchestrator approved.
+	OnApprove func(Agent)
+	// OnError is called when a check or an approval fails; AutoApproveAgents carries on with the next check. Errors
+	// are logged if it is nil.
+	OnError func(error)
+}
+
+// Matches reports whether the orchestrator matches the rule. Malformed patterns match nothing; use Validate to check
+// a rule.
+func (r *AgentApprovalRule) Matches(a *Agent) bool {
+	if !globMatch(r.ClientMachine, a.ClientMachine) {
+		return false
+	}
+	for _, pattern := range r.Capabilities {
+		matched := false
+		for _, capability := range a.Capabilities {
+			if globMatch(pattern, capability) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate returns an error if the rule has no ClientMachine pattern or a malformed pattern.
+func (r *AgentApprovalRule) Validate() error {
+	if r.ClientMachine == "" {
+		return errors.New("client machine pattern is required for an orchestrator approval rule")
+	}
+	for _, pattern := range append([]string{r.ClientMachine}, r.Capabilities...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid orchestrator approval pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func globMatch(pattern, name string) bool {
+	ok, err := path.Match(strings.ToLower(pattern), strings.ToLower(name))
+	return err == nil && ok
+}
+
+// ApproveMatchingAgents takes arguments for a list of approval rules to facilitate calls to Keyfactor that approve
+// every new orchestrator matching one of the rules. Orchestrators that were disapproved are left alone. The approved
+// orchestrators are returned; if an approval fails, those approved before it are returned along with the error.
+func (c *Client) ApproveMatchingAgents(rules []AgentApprovalRule) ([]Agent, error) {
+	return c.ApproveMatchingAgentsContext(context.Background(), rules)
+}
+
+// ApproveMatchingAgentsContext is like ApproveMatchingAgents but uses ctx for the requests, allowing it to be
+// cancelled.
+func (c *Client) ApproveMatchingAgentsContext(ctx context.Context, rules []AgentApprovalRule) ([]Agent, error) {
+	if len(rules) == 0 {
+		return nil, errors.New("at least one rule is required to approve orchestrators")
+	}
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	agents, err := c.GetAgentListContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	approved := []Agent{}
+	for _, agent := range agents {
+		if agent.Status != AgentStatusNew || !matchesAnyRule(rules, &agent) {
+			continue
+		}
+		c.infof("Approving orchestrator %s (%s) matching an approval rule", agent.ClientMachine, agent.AgentId)
+		if _, err := c.ApproveAgentContext(ctx, agent.AgentId); err != nil {
+			return approved, fmt.Errorf("unable to approve orchestrator %s: %w", agent.ClientMachine, err)
+		}
+		agent.Status = AgentStatusApproved
+		approved = append(approved, agent)
+	}
+	return approved, nil
+}
+
+func matchesAnyRule(rules []AgentApprovalRule, a *Agent) bool {
+	for i := range rules {
+		if rules[i].Matches(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// AutoApproveAgents checks for new orchestrators every opts.Interval, starting right away, and approves those matching
+// opts.Rules, so that orchestrators rolled out by configuration management start working without a manual approval.
+// It runs until ctx is done and returns ctx's error, or returns an error straight away if the rules are invalid.
+func (c *Client) AutoApproveAgents(ctx context.Context, opts *AgentAutoApprovalOptions) error {
+	if opts == nil || len(opts.Rules) == 0 {
+		return errors.New("at least one rule is required to approve orchestrators")
+	}
+	for i := range opts.Rules {
+		if err := opts.Rules[i].Validate(); err != nil {
+			return err
+		}
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultAgentApprovalInterval
+	}
+	onError := opts.OnError
+	if onError == nil {
+		onError = func(err error) { c.errorf("Approving orchestrators: %s", err) }
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+		approved, err := c.ApproveMatchingAgentsContext(ctx, opts.Rules)
+		if opts.OnApprove != nil {
+			for _, agent := range approved {
+				opts.OnApprove(agent)
+			}
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			onError(err)
+		}
+		timer.Reset(interval)
+	}
+}
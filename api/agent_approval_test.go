@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAgentApprovalRule_Matches(t *testing.T) {
+	agent := &Agent{ClientMachine: "orch01.Prod.example.com", Capabilities: []string{"CertStores.IISU.Inventory", "CertStores.IISU.Management"}}
+	tests := []struct {
+		name string
+		rule AgentApprovalRule
+		want bool
+	}{
+		{name: "Hostname", rule: AgentApprovalRule{ClientMachine: "*.prod.example.com"}, want: true},
+		{name: "OtherHost", rule: AgentApprovalRule{ClientMachine: "*.dev.example.com"}},
+		{name: "Capability", rule: AgentApprovalRule{ClientMachine: "orch*", Capabilities: []string{"certstores.iisu.*"}}, want: true},
+		{name: "MissingCapability", rule: AgentApprovalRule{ClientMachine: "orch*", Capabilities: []string{"CertStores.IISU.*", "CertStores.K8S.*"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(agent); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if err := (&AgentApprovalRule{ClientMachine: "orch[01"}).Validate(); err == nil {
+		t.Errorf("Validate() of a malformed pattern succeeded, want error")
+	}
+}
+
+func TestClient_AutoApproveAgents(t *testing.T) {
+	const (
+		prodId = "0b6b5ec4-3b8e-4f55-a6c3-1d1b5a3a9a01"
+		devId  = "0b6b5ec4-3b8e-4f55-a6c3-1d1b5a3a9a02"
+		oldId  = "0b6b5ec4-3b8e-4f55-a6c3-1d1b5a3a9a03"
+	)
+	var mu sync.Mutex
+	status := map[string]int{prodId: AgentStatusNew, devId: AgentStatusNew, oldId: AgentStatusDisapproved}
+	machines := map[string]string{prodId: "orch01.prod.example.com", devId: "orch01.dev.example.com", oldId: "orch02.prod.example.com"}
+	var approveCalls [][]string
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/KeyfactorAPI/Agents":
+			var agents []map[string]interface{}
+			for _, id := range []string{prodId, devId, oldId} {
+				agents = append(agents, map[string]interface{}{
+					"AgentId": id, "ClientMachine": machines[id], "Username": "svc", "AgentPlatform": 2, "Status": status[id],
+					"Version": "10.4", "LastSeen": "2024-05-01T12:00:00Z", "Thumbprint": "", "LegacyThumbprint": "",
+					"Capabilities": []string{"CertStores.IISU.Inventory"},
+				})
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(agents)
+		case "/KeyfactorAPI/Agents/Approve":
+			var ids []string
+			json.NewDecoder(r.Body).Decode(&ids)
+			approveCalls = append(approveCalls, ids)
+			for _, id := range ids {
+				status[id] = AgentStatusApproved
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	approved := make(chan Agent, 10)
+	done := make(chan error)
+	go func() {
+		done <- c.AutoApproveAgents(ctx, &AgentAutoApprovalOptions{
+			Rules:     []AgentApprovalRule{{ClientMachine: "*.prod.example.com", Capabilities: []string{"CertStores.IISU.*"}}},
+			Interval:  10 * time.Millisecond,
+			OnApprove: func(a Agent) { approved <- a },
+			OnError:   func(err error) { t.Errorf("AutoApproveAgents() error = %v", err) },
+		})
+	}()
+
+	select {
+	case a := <-approved:
+		if a.AgentId != prodId || a.Status != AgentStatusApproved {
+			t.Errorf("approved %+v, want %s", a, prodId)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("AutoApproveAgents() approved nothing")
+	}
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("AutoApproveAgents() = %v, want %v", err, context.Canceled)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := [][]string{{prodId}}; !reflect.DeepEqual(approveCalls, want) {
+		t.Errorf("approved %v, want %v", approveCalls, want)
+	}
+	if status[devId] != AgentStatusNew || status[oldId] != AgentStatusDisapproved {
+		t.Errorf("statuses = %v, want non-matching orchestrators unchanged", status)
+	}
+}
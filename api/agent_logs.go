@@ -0,0 +1,100 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Keyfactor/keyfactor-go-client/query"
+)
+
+// AgentLogsJobType is the job type of the orchestrator job that uploads an orchestrator's logs to Keyfactor.
+const AgentLogsJobType = "FetchLogs"
+
+// DownloadAgentLogs takes arguments for an orchestrator ID to facilitate calls to Keyfactor that collect the
+// orchestrator's logs: it schedules the job that has the orchestrator upload them, polls every pollInterval until the
+// orchestrator has run the job, and returns the uploaded log archive. Keyfactor reports the archive base64 encoded; it
+// is returned decoded. If the job fails, a *JobError carrying the orchestrator's message is returned. Waiting stops
+// with ctx's error if ctx is cancelled or its deadline passes first, which is how to bound the wait for an orchestrator
+// that is offline.
+func (c *Client) DownloadAgentLogs(ctx context.Context, id string, pollInterval time.Duration) (io.ReadCloser, error) {
+	if pollInterval <= 0 {
+		pollInterval = DefaultJobPollInterval
+	}
+	agents, err := c.GetAgentContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("orchestrator %s not found", id)
+	}
+	machine := agents[0].ClientMachine
+	c.infof("Collecting logs of orchestrator %s (%s)", machine, id)
+
+	jobQuery := query.And(query.Field("JobType").Eq(AgentLogsJobType), query.Field("AgentMachine").Eq(machine))
+	q, err := jobQuery.Build()
+	if err != nil {
+		return nil, err
+	}
+	previous, err := c.ListJobHistory(q)
+	if err != nil {
+		return nil, err
+	}
+	var after int64
+	for _, run := range previous {
+		if run.JobHistoryId > after {
+			after = run.JobHistoryId
+		}
+	}
+
+	if _, err := c.FetchAgentLogsContext(ctx, id); err != nil {
+		return nil, fmt.Errorf("unable to request logs of orchestrator %s: %w", machine, err)
+	}
+
+	if q, err = query.And(jobQuery, query.Field("JobHistoryId").Gt(after)).Build(); err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for logs of orchestrator %s: %w", machine, ctx.Err())
+		case <-timer.C:
+		}
+
+		runs, err := c.ListJobHistory(q)
+		if err != nil {
+			return nil, err
+		}
+		for _, run := range runs {
+			if run.JobHistoryId <= after || run.Result == JobResultUnknown {
+				continue
+			}
+			if run.Result == JobResultFailure {
+				return nil, &JobError{JobId: run.JobId, Message: run.Message}
+			}
+			data, err := c.GetCustomJobResultDataContext(ctx, run.JobHistoryId)
+			if err != nil {
+				return nil, err
+			}
+			return io.NopCloser(bytes.NewReader(decodeAgentLogs(data))), nil
+		}
+
+		c.debugf("Logs of orchestrator %s not uploaded yet, checking again in %s", machine, pollInterval)
+		timer.Reset(pollInterval)
+	}
+}
+
+// decodeAgentLogs returns the log archive reported as a job's result data, decoding it if it is base64 encoded.
+func decodeAgentLogs(data string) []byte {
+	trimmed := strings.TrimSpace(data)
+	if decoded, err := base64.StdEncoding.DecodeString(trimmed); err == nil && trimmed != "" {
+		return decoded
+	}
+	return []byte(data)
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient_DownloadAgentLogs(t *testing.T) {
+	const agentId = "0b6b5ec4-3b8e-4f55-a6c3-1d1b5a3a9a01"
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	f, _ := zw.Create("Orchestrator.log")
+	f.Write([]byte("2024-05-01 12:00:00 INFO started"))
+	zw.Close()
+
+	tests := []struct {
+		name    string
+		result  int
+		wantErr bool
+	}{
+		{name: "Success", result: int(JobResultSuccess)},
+		{name: "Failure", result: int(JobResultFailure), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			fetched := false
+			var queries []string
+			c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/KeyfactorAPI/Agents/" + agentId:
+					w.Write([]byte(`{"AgentId": "` + agentId + `", "ClientMachine": "orch01.example.com"}`))
+				case "/KeyfactorAPI/Agents/" + agentId + "/FetchLogs":
+					fetched = true
+					w.WriteHeader(http.StatusNoContent)
+				case "/KeyfactorAPI/OrchestratorJobs/JobHistory":
+					queries = append(queries, r.URL.Query().Get("pq.queryString"))
+					runs := []JobHistory{{JobHistoryId: 5, JobId: "old", JobType: AgentLogsJobType, Result: JobResultSuccess}}
+					if fetched && len(queries) > 2 {
+						runs = append(runs, JobHistory{JobHistoryId: 9, JobId: "logs", JobType: AgentLogsJobType, Result: JobResult(tt.result), Message: "disk full"})
+					}
+					json.NewEncoder(w).Encode(runs)
+				case "/KeyfactorAPI/OrchestratorJobs/JobStatus/Data":
+					if r.URL.Query().Get("jobHistoryId") != "9" {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					json.NewEncoder(w).Encode(map[string]interface{}{"JobHistoryId": 9, "Data": base64.StdEncoding.EncodeToString(archive.Bytes())})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			rc, err := c.DownloadAgentLogs(ctx, agentId, 10*time.Millisecond)
+			if tt.wantErr {
+				var jobErr *JobError
+				if !errors.As(err, &jobErr) || jobErr.Message != "disk full" {
+					t.Fatalf("DownloadAgentLogs() error = %v, want *JobError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DownloadAgentLogs() error = %v", err)
+			}
+			defer rc.Close()
+			data, _ := io.ReadAll(rc)
+			zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+			if err != nil || len(zr.File) != 1 || zr.File[0].Name != "Orchestrator.log" {
+				t.Fatalf("DownloadAgentLogs() returned %d bytes that are not the log archive: %v", len(data), err)
+			}
+			if !strings.Contains(queries[0], `AgentMachine -eq "orch01.example.com"`) || !strings.Contains(queries[1], "JobHistoryId -gt 5") {
+				t.Errorf("DownloadAgentLogs() searched job history with %q", queries)
+			}
+		})
+	}
+
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotFound) })
+	if _, err := c.DownloadAgentLogs(context.Background(), "not-a-guid", 0); err == nil {
+		t.Errorf("DownloadAgentLogs() with an invalid ID succeeded, want error")
+	}
+}
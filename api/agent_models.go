@@ -1,16 +1,25 @@
 package api
 
+// Orchestrator agent statuses. Only approved orchestrators are sent jobs.
+const (
+	AgentStatusNew         = 1
+	AgentStatusApproved    = 2
+	AgentStatusDisapproved = 3
+)
+
 type Agent struct {
-	AgentId          string `json:"AgentId"`
-	AgentPoolId      string `json:"AgentPoolId"`
-	ClientMachine    string `json:"ClientMachine"`
-	Username         string `json:"Username"`
-	AgentPlatform    int    `json:"AgentPlatform"`
-	Status           int    `json:"Status"`
-	EnableDiscover   bool   `json:"EnableDiscover"`
-	EnableMonitor    bool   `json:"EnableMonitor"`
-	Version          string `json:"Version"`
-	LastSeen         string `json:"LastSeen"`
-	Thumbprint       string `json:"Thumbprint"`
-	LegacyThumbprint string `json:"LegacyThumbprint"`
+	AgentId          string    `json:"AgentId"`
+	AgentPoolId      string    `json:"AgentPoolId"`
+	ClientMachine    string    `json:"ClientMachine"`
+	Username         string    `json:"Username"`
+	AgentPlatform    int       `json:"AgentPlatform"`
+	Status           int       `json:"Status"`
+	EnableDiscover   bool      `json:"EnableDiscover"`
+	EnableMonitor    bool      `json:"EnableMonitor"`
+	Version          string    `json:"Version"`
+	LastSeen         Timestamp `json:"LastSeen"`
+	Thumbprint       string    `json:"Thumbprint"`
+	LegacyThumbprint string    `json:"LegacyThumbprint"`
+	// Capabilities lists the jobs the orchestrator registered for, e.g. "CertStores.IIS.Inventory".
+	Capabilities []string `json:"Capabilities"`
 }
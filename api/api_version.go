@@ -0,0 +1,113 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// EnvCommandAPIVersions pins endpoints to API versions, as comma separated Endpoint=Version pairs, e.g.
+// "Certificates=2,CertificateStores=1". See APIVersions.
+const EnvCommandAPIVersions = "KEYFACTOR_API_VERSIONS"
+
+// apiVersionHeader is the header Keyfactor reads the requested API version from.
+const apiVersionHeader = "x-keyfactor-api-version"
+
+// APIVersions pins Keyfactor API endpoints to the API version requested of them, overriding the version the client
+// sends. Endpoints are given relative to the API path, such as "Certificates" or "Certificates/Import", are compared
+// case-insensitively, and cover the endpoints below them: with {"Certificates": "2", "Certificates/Import": "1"},
+// certificate searches ask for version 2 and imports for version 1. Pinning keeps the request and response formats
+// stable when the nodes of a Command cluster behind a load balancer run different releases.
+type APIVersions map[string]string
+
+// ParseAPIVersions parses the value of EnvCommandAPIVersions.
+func ParseAPIVersions(s string) (APIVersions, error) {
+	versions := APIVersions{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		endpoint, version, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid API version pin %q: must be Endpoint=Version", strings.TrimSpace(pair))
+		}
+		versions[strings.TrimSpace(endpoint)] = strings.TrimSpace(version)
+	}
+	if err := versions.validate(); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// validate returns an error if an endpoint or a version is empty.
+func (v APIVersions) validate() error {
+	for endpoint, version := range v {
+		if strings.Trim(endpoint, "/ ") == "" {
+			return fmt.Errorf("invalid API version pin for endpoint %q: endpoint is required", endpoint)
+		}
+		if version == "" {
+			return fmt.Errorf("invalid API version pin for endpoint %q: version is required", endpoint)
+		}
+	}
+	return nil
+}
+
+// Version returns the version pinned for the endpoint, relative to the API path, by its longest pinned prefix, and
+// whether there is one.
+func (v APIVersions) Version(endpoint string) (string, bool) {
+	segments := strings.Split(strings.ToLower(strings.Trim(endpoint, "/")), "/")
+	best, bestLen := "", -1
+	for pinned, version := range v {
+		pinnedSegments := strings.Split(strings.ToLower(strings.Trim(pinned, "/")), "/")
+		if len(pinnedSegments) > len(segments) || len(pinnedSegments) <= bestLen {
+			continue
+		}
+		match := true
+		for i := range pinnedSegments {
+			if pinnedSegments[i] != segments[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			best, bestLen = version, len(pinnedSegments)
+		}
+	}
+	return best, bestLen >= 0
+}
+
+// String formats the pins as EnvCommandAPIVersions expects them, sorted by endpoint.
+func (v APIVersions) String() string {
+	pairs := make([]string, 0, len(v))
+	for endpoint, version := range v {
+		pairs = append(pairs, endpoint+"="+version)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// apiVersionTransport is an http.RoundTripper that sets the API version of requests to endpoints pinned by the
+// client's APIVersions, for requests built by the client and by the Keyfactor SDK alike.
+type apiVersionTransport struct {
+	base   http.RoundTripper
+	client *Client
+}
+
+func (t *apiVersionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(t.client.apiVersions) == 0 {
+		return t.base.RoundTrip(req)
+	}
+	prefix := strings.ToLower(t.client.apiPrefix())
+	path := strings.TrimPrefix(req.URL.Path, "/")
+	if !strings.HasPrefix(strings.ToLower(path), prefix) {
+		return t.base.RoundTrip(req)
+	}
+	version, ok := t.client.apiVersions.Version(path[len(prefix):])
+	if !ok || req.Header.Get(apiVersionHeader) == version {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set(apiVersionHeader, version)
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestAPIVersions_Version(t *testing.T) {
+	versions := APIVersions{"Certificates": "2", "certificates/import": "1", "/CertificateStores/": "1"}
+	tests := []struct {
+		endpoint string
+		want     string
+		wantOK   bool
+	}{
+		{endpoint: "Certificates", want: "2", wantOK: true},
+		{endpoint: "Certificates/42/History", want: "2", wantOK: true},
+		{endpoint: "Certificates/Import", want: "1", wantOK: true},
+		{endpoint: "CertificateStores/Types", want: "1", wantOK: true},
+		{endpoint: "CertificateStoreTypes"},
+		{endpoint: "Agents"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			got, ok := versions.Version(tt.endpoint)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Version(%q) = %q, %v, want %q, %v", tt.endpoint, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseAPIVersions(t *testing.T) {
+	got, err := ParseAPIVersions(" Certificates=2, CertificateStores = 1,")
+	if err != nil {
+		t.Fatalf("ParseAPIVersions() error = %v", err)
+	}
+	if want := (APIVersions{"Certificates": "2", "CertificateStores": "1"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAPIVersions() = %v, want %v", got, want)
+	}
+	if s := got.String(); s != "CertificateStores=1,Certificates=2" {
+		t.Errorf("String() = %q", s)
+	}
+	for _, bad := range []string{"Certificates", "Certificates=", "=2"} {
+		if _, err := ParseAPIVersions(bad); err == nil {
+			t.Errorf("ParseAPIVersions(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestClient_APIVersionPinning(t *testing.T) {
+	var mu sync.Mutex
+	versions := map[string]string{}
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		versions[r.URL.Path] = r.Header.Get("x-keyfactor-api-version")
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	})
+	c.apiVersions = APIVersions{"Audit": "2", "Agents": "3"}
+
+	if _, err := c.SearchAuditLogs("", nil); err != nil {
+		t.Fatalf("SearchAuditLogs() error = %v", err)
+	}
+	if _, err := c.ListWorkflowInstances("", nil); err != nil {
+		t.Fatalf("ListWorkflowInstances() error = %v", err)
+	}
+	if _, err := c.GetAgentList(); err != nil {
+		t.Fatalf("GetAgentList() error = %v", err)
+	}
+	want := map[string]string{
+		"/KeyfactorAPI/Audit":              "2",
+		"/KeyfactorAPI/Workflow/Instances": "1",
+		"/KeyfactorAPI/Agents":             "3",
+	}
+	if !reflect.DeepEqual(versions, want) {
+		t.Errorf("requested API versions %v, want %v", versions, want)
+	}
+}
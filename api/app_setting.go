@@ -0,0 +1,208 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GetAppSettings asks Keyfactor for the complete list of application settings.
+func (c *Client) GetAppSettings() ([]AppSetting, error) {
+	c.infof("Getting Keyfactor application settings")
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "GET",
+		Endpoint: "AppSetting",
+		Headers:  headers,
+		Payload:  nil,
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonResp []AppSetting
+	err = c.decodeResponse(resp.Body, &jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// GetAppSetting takes arguments for an application setting ID to facilitate a call to Keyfactor that retrieves the
+// setting.
+func (c *Client) GetAppSetting(id int) (*AppSetting, error) {
+	c.infof("Getting Keyfactor application setting with ID %d", id)
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "GET",
+		Endpoint: fmt.Sprintf("AppSetting/%d", id),
+		Headers:  headers,
+		Payload:  nil,
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonResp := &AppSetting{}
+	err = c.decodeResponse(resp.Body, jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// GetAppSettingByName looks up an application setting by its short name, ignoring case.
+func (c *Client) GetAppSettingByName(shortName string) (*AppSetting, error) {
+	settings, err := c.GetAppSettings()
+	if err != nil {
+		return nil, err
+	}
+	for i := range settings {
+		if strings.EqualFold(settings[i].ShortName, shortName) {
+			return &settings[i], nil
+		}
+	}
+	return nil, fmt.Errorf("application setting %s not found", shortName)
+}
+
+// SetAppSetting takes arguments for an application setting ID and value to facilitate a call to Keyfactor that
+// updates the setting. The updated setting is returned.
+func (c *Client) SetAppSetting(id int, value string) (*AppSetting, error) {
+	c.infof("Setting Keyfactor application setting with ID %d", id)
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "PUT",
+		Endpoint: fmt.Sprintf("AppSetting/%d/Set", id),
+		Headers:  headers,
+		Payload:  value,
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonResp := &AppSetting{}
+	err = c.decodeResponse(resp.Body, jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// UpdateAppSettings takes a list of UpdateAppSettingArg to facilitate a call to Keyfactor that updates several
+// application settings at once. The updated settings are returned.
+func (c *Client) UpdateAppSettings(args []UpdateAppSettingArg) ([]AppSetting, error) {
+	c.infof("Updating %d Keyfactor application settings", len(args))
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "PUT",
+		Endpoint: "AppSetting",
+		Headers:  headers,
+		Payload:  args,
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonResp []AppSetting
+	err = c.decodeResponse(resp.Body, &jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// GetAppSettingDrift compares the server's application settings against desired, a map of setting short names to
+// values, and returns the settings that differ, sorted by short name. Boolean settings are compared ignoring case. An
+// error is returned if desired names a setting the server does not have.
+func (c *Client) GetAppSettingDrift(desired map[string]string) ([]AppSettingDrift, error) {
+	settings, err := c.GetAppSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]AppSetting, len(settings))
+	for _, s := range settings {
+		byName[strings.ToLower(s.ShortName)] = s
+	}
+
+	var drift []AppSettingDrift
+	var missing []string
+	for name, want := range desired {
+		s, ok := byName[strings.ToLower(name)]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		if !appSettingValueEqual(s, want) {
+			drift = append(drift, AppSettingDrift{Setting: s, Want: want})
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("application settings not found: %s", strings.Join(missing, ", "))
+	}
+
+	sort.Slice(drift, func(i, j int) bool { return drift[i].Setting.ShortName < drift[j].Setting.ShortName })
+	return drift, nil
+}
+
+// CorrectAppSettingDrift updates every setting in drift to its desired value in a single call and returns the updated
+// settings.
+func (c *Client) CorrectAppSettingDrift(drift []AppSettingDrift) ([]AppSetting, error) {
+	args := make([]UpdateAppSettingArg, len(drift))
+	for i, d := range drift {
+		c.infof("Correcting application setting %s from %q to %q", d.Setting.ShortName, d.Setting.Value, d.Want)
+		args[i] = UpdateAppSettingArg{Id: d.Setting.Id, Value: d.Want}
+	}
+	return c.UpdateAppSettings(args)
+}
+
+func appSettingValueEqual(s AppSetting, want string) bool {
+	if strings.EqualFold(s.SettingType, "Bool") {
+		return strings.EqualFold(s.Value, want)
+	}
+	return s.Value == want
+}
@@ -0,0 +1,25 @@
+package api
+
+// AppSetting is a Keyfactor Command application setting, such as an enrollment or API behaviour toggle. Value is
+// always reported as a string; SettingType describes how Keyfactor interprets it (e.g. "Bool", "Int", "String").
+type AppSetting struct {
+	Id          int    `json:"Id"`
+	ShortName   string `json:"ShortName"`
+	DisplayName string `json:"DisplayName"`
+	Description string `json:"Description"`
+	Value       string `json:"Value"`
+	SettingType string `json:"SettingType"`
+}
+
+// UpdateAppSettingArg holds the new value for a single application setting in a call to UpdateAppSettings.
+type UpdateAppSettingArg struct {
+	Id    int    `json:"Id"`
+	Value string `json:"Value"`
+}
+
+// AppSettingDrift describes an application setting whose value on the server differs from the desired value passed
+// to GetAppSettingDrift.
+type AppSettingDrift struct {
+	Setting AppSetting
+	Want    string
+}
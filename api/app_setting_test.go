@@ -0,0 +1,86 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestClient_AppSettingDrift(t *testing.T) {
+	settings := []AppSetting{
+		{Id: 1, ShortName: "EnrollmentAgentEnabled", Value: "False", SettingType: "Bool"},
+		{Id: 2, ShortName: "CertificateRequestPageSize", Value: "50", SettingType: "Int"},
+		{Id: 3, ShortName: "ApiAuditLogging", Value: "true", SettingType: "Bool"},
+	}
+	var updates []UpdateAppSettingArg
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /KeyfactorAPI/AppSetting":
+			json.NewEncoder(w).Encode(settings)
+		case "PUT /KeyfactorAPI/AppSetting":
+			json.NewDecoder(r.Body).Decode(&updates)
+			var resp []AppSetting
+			for _, u := range updates {
+				s := settings[u.Id-1]
+				s.Value = u.Value
+				resp = append(resp, s)
+			}
+			json.NewEncoder(w).Encode(resp)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	tests := []struct {
+		name      string
+		desired   map[string]string
+		wantDrift []string
+		wantErr   bool
+	}{
+		{
+			name:    "NoDrift",
+			desired: map[string]string{"enrollmentagentenabled": "false", "ApiAuditLogging": "True"},
+		},
+		{
+			name:      "Drift",
+			desired:   map[string]string{"EnrollmentAgentEnabled": "true", "CertificateRequestPageSize": "100", "ApiAuditLogging": "true"},
+			wantDrift: []string{"CertificateRequestPageSize", "EnrollmentAgentEnabled"},
+		},
+		{
+			name:    "UnknownSetting",
+			desired: map[string]string{"NoSuchSetting": "1"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drift, err := c.GetAppSettingDrift(tt.desired)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetAppSettingDrift() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(drift) != len(tt.wantDrift) {
+				t.Fatalf("GetAppSettingDrift() = %+v, want %v", drift, tt.wantDrift)
+			}
+			for i := range drift {
+				if drift[i].Setting.ShortName != tt.wantDrift[i] {
+					t.Errorf("GetAppSettingDrift()[%d] = %s, want %s", i, drift[i].Setting.ShortName, tt.wantDrift[i])
+				}
+			}
+			if len(drift) == 0 {
+				return
+			}
+
+			updated, err := c.CorrectAppSettingDrift(drift)
+			if err != nil {
+				t.Fatalf("CorrectAppSettingDrift() error = %v", err)
+			}
+			if len(updates) != len(drift) || updates[0].Id != 2 || updates[0].Value != "100" {
+				t.Errorf("CorrectAppSettingDrift() sent %+v", updates)
+			}
+			if len(updated) != len(drift) || updated[1].Value != "true" {
+				t.Errorf("CorrectAppSettingDrift() = %+v", updated)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+)
+
+// SearchAuditLogs takes arguments for a query string, such as one built with the query package, to facilitate a call
+// to Keyfactor that returns the matching audit log entries. An empty query matches every entry. paging may be nil;
+// its sort settings default to Keyfactor's order, so set SortField to "Timestamp" to get the latest entries first.
+func (c *Client) SearchAuditLogs(q string, paging *Paging) ([]AuditLogEntry, error) {
+	return c.SearchAuditLogsContext(context.Background(), q, paging)
+}
+
+// SearchAuditLogsContext is like SearchAuditLogs but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) SearchAuditLogsContext(ctx context.Context, q string, paging *Paging) ([]AuditLogEntry, error) {
+	c.infof("Searching audit log entries matching query '%s'", q)
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	var params []StringTuple
+	if q != "" {
+		params = append(params, StringTuple{"pq.queryString", q})
+	}
+	params = append(params, paging.query()...)
+
+	keyfactorAPIStruct := &request{
+		Method:   "GET",
+		Endpoint: "Audit",
+		Headers:  headers,
+		Query:    &apiQuery{Query: params},
+		Context:  ctx,
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonResp []AuditLogEntry
+	err = c.decodeResponse(resp.Body, &jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
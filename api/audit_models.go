@@ -0,0 +1,17 @@
+package api
+
+// AuditLogEntry is a single entry of the Keyfactor audit log, returned by SearchAuditLogs.
+type AuditLogEntry struct {
+	Id        int       `json:"Id"`
+	Timestamp Timestamp `json:"Timestamp"`
+	Message   string    `json:"Message"`
+	Signature string    `json:"Signature"`
+	Category  int       `json:"Category"`
+	Operation int       `json:"Operation"`
+	Level     int       `json:"Level"`
+	User      string    `json:"User"`
+	// EntityType is the type of object the entry is about, e.g. "Certificate".
+	EntityType          string `json:"EntityType"`
+	AuditIdentifier     string `json:"AuditIdentifier"`
+	ImmutableIdentifier string `json:"ImmutableIdentifier"`
+}
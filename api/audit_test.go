@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestClient_SearchAuditLogs(t *testing.T) {
+	var lastQuery url.Values
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/KeyfactorAPI/Audit" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		lastQuery = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"Id": 7, "Timestamp": "2023-04-01T12:00:00Z", "Message": "Certificate revoked", "User": "EXAMPLE\\admin", "EntityType": "Certificate"}]`))
+	})
+
+	got, err := c.SearchAuditLogs(`EntityType -eq "Certificate"`, &Paging{ReturnLimit: 10, SortField: "Timestamp"})
+	if err != nil {
+		t.Fatalf("SearchAuditLogs() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Id != 7 || got[0].User != `EXAMPLE\admin` {
+		t.Errorf("SearchAuditLogs() = %+v", got)
+	}
+	want := map[string]string{
+		"pq.queryString":   `EntityType -eq "Certificate"`,
+		"pq.returnLimit":   "10",
+		"pq.sortField":     "Timestamp",
+		"pq.sortAscending": "1",
+	}
+	for k, v := range want {
+		if lastQuery.Get(k) != v {
+			t.Errorf("SearchAuditLogs() sent %s = %q, want %q", k, lastQuery.Get(k), v)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrServerUnavailable is matched by errors returned while the circuit breaker enabled with
+// Client.EnableCircuitBreaker is open, i.e. without Keyfactor being contacted.
+var ErrServerUnavailable = errors.New("keyfactor server unavailable")
+
+// Defaults used by EnableCircuitBreaker when the corresponding CircuitBreakerConfig field is not set.
+const (
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// CircuitBreakerConfig configures the optional circuit breaker enabled with Client.EnableCircuitBreaker.
+type CircuitBreakerConfig struct {
+	// Threshold is the number of consecutive 5xx responses that trip the breaker. Defaults to DefaultBreakerThreshold.
+	Threshold int
+	// Cooldown is how long requests fail fast once the breaker has tripped, before a single request is let through
+	// to probe whether Keyfactor has recovered. A longer Retry-After on the response that tripped the breaker takes
+	// precedence. Defaults to DefaultBreakerCooldown.
+	Cooldown time.Duration
+}
+
+// EnableCircuitBreaker turns on a circuit breaker that trips after consecutive 5xx responses from Keyfactor, such as
+// the 503s returned while Command is down for patching. While it is open, requests fail immediately with an error
+// wrapping ErrServerUnavailable instead of adding load to the instance, so callers that retry in a loop, such as
+// controllers, back off without hammering it. After the cooldown one request is let through; the breaker closes if it
+// succeeds and opens again if it does not. Fresh responses from the cache enabled with EnableCache are still served
+// while the breaker is open. A nil config uses the defaults. Enabling the breaker again replaces it and closes it.
+func (c *Client) EnableCircuitBreaker(config *CircuitBreakerConfig) {
+	if config == nil {
+		config = &CircuitBreakerConfig{}
+	}
+	threshold := config.Threshold
+	if threshold <= 0 {
+		threshold = DefaultBreakerThreshold
+	}
+	cooldown := config.Cooldown
+	if cooldown <= 0 {
+		cooldown = DefaultBreakerCooldown
+	}
+
+	c.breakerMu.Lock()
+	defer c.breakerMu.Unlock()
+	c.breaker = &circuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now, logger: c.loggerOrDefault()}
+}
+
+// DisableCircuitBreaker turns off the circuit breaker.
+func (c *Client) DisableCircuitBreaker() {
+	c.breakerMu.Lock()
+	defer c.breakerMu.Unlock()
+	c.breaker = nil
+}
+
+func (c *Client) circuitBreaker() *circuitBreaker {
+	c.breakerMu.Lock()
+	defer c.breakerMu.Unlock()
+	return c.breaker
+}
+
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	logger    Logger
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow reports whether a request may be sent, and returns the error to fail it with if not. Once the cooldown has
+// passed, only one request at a time is allowed through until one of them succeeds; probe reports whether the request
+// is that one, and must be passed to record with its outcome.
+func (b *circuitBreaker) allow() (probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return false, nil
+	}
+	now := b.now()
+	if now.Before(b.openUntil) {
+		return false, fmt.Errorf("%w: circuit breaker open after %d consecutive server errors, retry in %s", ErrServerUnavailable, b.failures, b.openUntil.Sub(now).Round(time.Second))
+	}
+	if b.probing {
+		return false, fmt.Errorf("%w: circuit breaker open after %d consecutive server errors, waiting for recovery", ErrServerUnavailable, b.failures)
+	}
+	b.probing = true
+	return true, nil
+}
+
+// record updates the breaker with the outcome of a request that was allowed through, and lets another probe through if
+// the request was the probe. Requests let through before the breaker tripped do not end a probe when they complete.
+// resp is nil if the request got no response, which neither trips nor closes the breaker.
+func (b *circuitBreaker) record(probe bool, resp *http.Response) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if probe {
+		b.probing = false
+	}
+	if resp == nil {
+		return
+	}
+	if resp.StatusCode < 500 {
+		if b.failures >= b.threshold {
+			logTo(b.logger, LogLevelInfo, nil, "Keyfactor responded with status %d; closing circuit breaker", resp.StatusCode)
+		}
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures < b.threshold {
+		return
+	}
+	cooldown := b.cooldown
+	if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After")); retryAfter > cooldown {
+		cooldown = retryAfter
+	}
+	b.openUntil = b.now().Add(cooldown)
+	if probe || b.failures == b.threshold {
+		logTo(b.logger, LogLevelWarn, nil, "Keyfactor returned %d consecutive server errors (last status %d); failing requests for %s", b.failures, resp.StatusCode, cooldown)
+	}
+}
+
+// parseRetryAfter returns the delay of a Retry-After header given in seconds, or zero.
+func parseRetryAfter(v string) time.Duration {
+	seconds, err := strconv.Atoi(v)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// breakerTransport is an http.RoundTripper that fails requests while the client's circuit breaker is open.
+type breakerTransport struct {
+	base   http.RoundTripper
+	client *Client
+}
+
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	b := t.client.circuitBreaker()
+	if b == nil {
+		return t.base.RoundTrip(req)
+	}
+	probe, err := b.allow()
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	b.record(probe, resp)
+	return resp, err
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	var hits int
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"Message": "maintenance"}`))
+	})
+	c.EnableCircuitBreaker(&CircuitBreakerConfig{Threshold: 3, Cooldown: time.Minute})
+	now := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+	c.circuitBreaker().now = func() time.Time { return now }
+
+	get := func() error {
+		_, err := c.sendRequest(&request{Method: "GET", Endpoint: "Status/Endpoints", Headers: &apiHeaders{}})
+		return err
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := get(); err == nil || errors.Is(err, ErrServerUnavailable) {
+			t.Fatalf("request %d error = %v, want server error", i+1, err)
+		}
+	}
+	if err := get(); !errors.Is(err, ErrServerUnavailable) {
+		t.Fatalf("request after tripping error = %v, want ErrServerUnavailable", err)
+	}
+	if hits != 3 {
+		t.Errorf("Keyfactor received %d requests, want 3", hits)
+	}
+
+	// The probe after the cooldown fails, so the breaker opens again.
+	now = now.Add(time.Minute)
+	if err := get(); err == nil || errors.Is(err, ErrServerUnavailable) {
+		t.Fatalf("probe error = %v, want server error", err)
+	}
+	if err := get(); !errors.Is(err, ErrServerUnavailable) {
+		t.Fatalf("request after failed probe error = %v, want ErrServerUnavailable", err)
+	}
+
+	// Once Keyfactor recovers, the next probe closes the breaker.
+	status = http.StatusOK
+	now = now.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		if err := get(); err != nil {
+			t.Fatalf("request %d after recovery error = %v", i+1, err)
+		}
+	}
+	if hits != 6 {
+		t.Errorf("Keyfactor received %d requests, want 6", hits)
+	}
+
+	c.DisableCircuitBreaker()
+	if c.circuitBreaker() != nil {
+		t.Errorf("DisableCircuitBreaker() left the breaker enabled")
+	}
+}
+
+func TestCircuitBreaker_RetryAfter(t *testing.T) {
+	now := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+	b := &circuitBreaker{threshold: 1, cooldown: time.Second, now: func() time.Time { return now }}
+	b.record(false, &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{"Retry-After": {"120"}}})
+	if want := now.Add(2 * time.Minute); !b.openUntil.Equal(want) {
+		t.Errorf("openUntil = %s, want %s", b.openUntil, want)
+	}
+}
+
+func TestCircuitBreaker_SingleProbe(t *testing.T) {
+	now := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+	b := &circuitBreaker{threshold: 1, cooldown: time.Minute, now: func() time.Time { return now }}
+	slow, _ := b.allow()
+	b.record(false, &http.Response{StatusCode: http.StatusServiceUnavailable})
+
+	now = now.Add(time.Minute)
+	probe, err := b.allow()
+	if !probe || err != nil {
+		t.Fatalf("allow() after the cooldown = %v, %v, want the probe", probe, err)
+	}
+	// A request let through before the breaker tripped completes while the probe is in flight.
+	b.record(slow, nil)
+	if _, err := b.allow(); !errors.Is(err, ErrServerUnavailable) {
+		t.Errorf("allow() during the probe error = %v, want ErrServerUnavailable", err)
+	}
+
+	b.record(probe, &http.Response{StatusCode: http.StatusServiceUnavailable})
+	now = now.Add(time.Minute)
+	if probe, err := b.allow(); !probe || err != nil {
+		t.Errorf("allow() after the failed probe and cooldown = %v, %v, want another probe", probe, err)
+	}
+}
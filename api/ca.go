@@ -3,25 +3,27 @@ package api
 import (
 	"context"
 	"encoding/json"
-
-	"github.com/Keyfactor/keyfactor-go-client-sdk/api/keyfactor"
+	"errors"
+	"fmt"
 )
 
 // GetCAList returns a list of certificate authorities supported by the Keyfactor instance
 func (c *Client) GetCAList() ([]CA, error) {
+	return c.GetCAListContext(context.Background())
+}
+
+// GetCAListContext is like GetCAList but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) GetCAListContext(ctx context.Context) ([]CA, error) {
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
-
-	resp, _, err := apiClient.CertificateAuthorityApi.CertificateAuthorityGetCas(context.Background()).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	apiClient := c.sdkClient()
 
-	var revResp []CA
+	resp, _, err := apiClient.CertificateAuthorityApi.CertificateAuthorityGetCas(ctx).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
-		return revResp, err
+		return nil, err
 	}
 
 	type ExplicitPassword struct {
@@ -30,6 +32,7 @@ func (c *Client) GetCAList() ([]CA, error) {
 		Provider    int      `json:"Provider"`
 	}
 
+	revResp := make([]CA, 0, len(resp))
 	for _, val := range resp {
 		vJson, _ := json.Marshal(val)
 		var newCA CA
@@ -39,3 +42,174 @@ func (c *Client) GetCAList() ([]CA, error) {
 
 	return revResp, nil
 }
+
+// GetCA takes arguments for a certificate authority ID to facilitate a call to Keyfactor that returns the CA's
+// configuration.
+func (c *Client) GetCA(id int) (*CA, error) {
+	c.infof("Fetching Keyfactor certificate authority with ID %d", id)
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "GET",
+		Endpoint: fmt.Sprintf("CertificateAuthority/%d", id),
+		Headers:  headers,
+		Payload:  nil,
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonResp := &CA{}
+	err = c.decodeResponse(resp.Body, jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// CreateCA takes arguments for CreateCAArgs to facilitate a call to Keyfactor that adds a certificate authority,
+// either a Microsoft CA reached over DCOM or an AnyCA Gateway reached over HTTPS. Required arguments are:
+//   - LogicalName : string
+//   - HostName    : string
+//   - AuthCertificate and AuthCertificatePassword, for HTTPS CAs
+func (c *Client) CreateCA(args *CreateCAArgs) (*CA, error) {
+	c.infof("Creating new certificate authority with Keyfactor")
+
+	if err := validateCreateCAArgs(args, true); err != nil {
+		return nil, err
+	}
+	return c.saveCA("POST", args)
+}
+
+// UpdateCA takes arguments for UpdateCAArgs to facilitate a call to Keyfactor that replaces the configuration of an
+// existing certificate authority. The required arguments are those of CreateCA plus Id, except that AuthCertificate
+// may be omitted to keep the current client certificate of an HTTPS CA.
+func (c *Client) UpdateCA(args *UpdateCAArgs) (*CA, error) {
+	if args == nil || args.Id <= 0 {
+		return nil, errors.New("certificate authority id is required to update a certificate authority")
+	}
+	c.infof("Updating Keyfactor certificate authority with ID %d", args.Id)
+
+	if err := validateCreateCAArgs(&args.CreateCAArgs, false); err != nil {
+		return nil, err
+	}
+	return c.saveCA("PUT", args)
+}
+
+// DeleteCA takes arguments for a certificate authority ID, and makes an associated call to Keyfactor to delete the
+// CA.
+func (c *Client) DeleteCA(id int) error {
+	c.infof("Deleting Keyfactor certificate authority with ID %d", id)
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "DELETE",
+		Endpoint: fmt.Sprintf("CertificateAuthority/%d", id),
+		Headers:  headers,
+		Payload:  nil,
+	}
+
+	_, err := c.sendRequest(keyfactorAPIStruct)
+	return err
+}
+
+// saveCA sends a CA configuration to Keyfactor with method, POST to create or PUT to update.
+func (c *Client) saveCA(method string, payload interface{}) (*CA, error) {
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   method,
+		Endpoint: "CertificateAuthority",
+		Headers:  headers,
+		Payload:  payload,
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonResp := &CA{}
+	err = c.decodeResponse(resp.Body, jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// validateCreateCAArgs checks the fields of args that Keyfactor requires for its CA type. requireAuthCertificate is
+// false for updates, which keep the current client certificate of an HTTPS CA when none is given.
+func validateCreateCAArgs(args *CreateCAArgs, requireAuthCertificate bool) error {
+	if args == nil {
+		return errors.New("certificate authority arguments are required")
+	}
+	if args.LogicalName == "" {
+		return errors.New("logical name is required to save a certificate authority")
+	}
+	if args.HostName == "" {
+		return errors.New("host name is required to save a certificate authority")
+	}
+
+	switch args.CAType {
+	case CATypeDCOM:
+		if args.AuthCertificate != nil || args.AuthCertificatePassword != nil {
+			return errors.New("auth certificate is only supported for HTTPS certificate authorities")
+		}
+		if args.ExplicitCredentials && (args.ExplicitUser == "" || args.ExplicitPassword == nil) {
+			return errors.New("explicit user and password are required when explicit credentials are enabled")
+		}
+	case CATypeHTTPS:
+		if args.ExplicitCredentials {
+			return errors.New("explicit credentials are only supported for DCOM certificate authorities")
+		}
+		if requireAuthCertificate && args.AuthCertificate == nil {
+			return errors.New("auth certificate is required to create an HTTPS certificate authority")
+		}
+		if args.AuthCertificate != nil && args.AuthCertificatePassword == nil {
+			return errors.New("auth certificate password is required with an auth certificate")
+		}
+	default:
+		return fmt.Errorf("unknown certificate authority type %d", args.CAType)
+	}
+
+	switch args.KeyRetention {
+	case KeyRetentionIndefinite:
+	case KeyRetentionAfterExpiration, KeyRetentionFromIssuance:
+		if args.KeyRetentionDays <= 0 {
+			return errors.New("key retention days must be greater than zero for the key retention policy")
+		}
+	case KeyRetentionUntilDate:
+		if args.KeyRetentionDate == "" {
+			return errors.New("key retention date is required for the key retention policy")
+		}
+	default:
+		return fmt.Errorf("unknown key retention policy %d", args.KeyRetention)
+	}
+
+	if args.UseAllowedRequesters && len(args.AllowedRequesters) == 0 {
+		return errors.New("allowed requesters are required when restricting requesters")
+	}
+	return nil
+}
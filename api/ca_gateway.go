@@ -0,0 +1,194 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+)
+
+// ListCATemplateMappings takes arguments for the ID of a certificate authority reached through an AnyCA Gateway to
+// facilitate a call to Keyfactor that returns the CA's product-to-template mappings.
+func (c *Client) ListCATemplateMappings(caId int) ([]CATemplateMapping, error) {
+	return c.ListCATemplateMappingsContext(context.Background(), caId)
+}
+
+// ListCATemplateMappingsContext is like ListCATemplateMappings but uses ctx for the request, allowing it to be
+// cancelled.
+func (c *Client) ListCATemplateMappingsContext(ctx context.Context, caId int) ([]CATemplateMapping, error) {
+	c.infof("Listing template mappings of Keyfactor certificate authority with ID %d", caId)
+
+	if caId <= 0 {
+		return nil, errors.New("certificate authority id is required to list template mappings")
+	}
+	jsonResp := []CATemplateMapping{}
+	err := c.sendCARequest(&request{
+		Method:   "GET",
+		Endpoint: fmt.Sprintf("CertificateAuthority/%d/TemplateMappings", caId),
+		Context:  ctx,
+	}, &jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// SetCATemplateMapping takes arguments for a certificate authority ID and a CATemplateMapping to facilitate a call to
+// Keyfactor that maps the product to the template, replacing any existing mapping of the product. Required arguments
+// are:
+//   - ProductId         : string
+//   - TemplateShortName : string
+func (c *Client) SetCATemplateMapping(caId int, mapping *CATemplateMapping) (*CATemplateMapping, error) {
+	return c.SetCATemplateMappingContext(context.Background(), caId, mapping)
+}
+
+// SetCATemplateMappingContext is like SetCATemplateMapping but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) SetCATemplateMappingContext(ctx context.Context, caId int, mapping *CATemplateMapping) (*CATemplateMapping, error) {
+	if err := validateCATemplateMapping(caId, mapping); err != nil {
+		return nil, err
+	}
+	c.infof("Mapping product %s of Keyfactor certificate authority with ID %d to template %s", mapping.ProductId, caId, mapping.TemplateShortName)
+
+	jsonResp := &CATemplateMapping{}
+	err := c.sendCARequest(&request{
+		Method:   "PUT",
+		Endpoint: fmt.Sprintf("CertificateAuthority/%d/TemplateMappings", caId),
+		Payload:  mapping,
+		Context:  ctx,
+	}, jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// DeleteCATemplateMapping takes arguments for a certificate authority ID and a product ID, and makes an associated
+// call to Keyfactor to remove the product's template mapping.
+func (c *Client) DeleteCATemplateMapping(caId int, productId string) error {
+	return c.DeleteCATemplateMappingContext(context.Background(), caId, productId)
+}
+
+// DeleteCATemplateMappingContext is like DeleteCATemplateMapping but uses ctx for the request, allowing it to be
+// cancelled.
+func (c *Client) DeleteCATemplateMappingContext(ctx context.Context, caId int, productId string) error {
+	c.infof("Removing template mapping of product %s from Keyfactor certificate authority with ID %d", productId, caId)
+
+	if caId <= 0 {
+		return errors.New("certificate authority id is required to delete a template mapping")
+	}
+	if productId == "" {
+		return errors.New("product id is required to delete a template mapping")
+	}
+	return c.sendCARequest(&request{
+		Method:   "DELETE",
+		Endpoint: fmt.Sprintf("CertificateAuthority/%d/TemplateMappings/%s", caId, url.PathEscape(productId)),
+		Context:  ctx,
+	}, nil)
+}
+
+// SyncCATemplateMappings makes the template mappings of a certificate authority match mappings, setting the mappings
+// that are missing or differ and removing those of products not in mappings. Mappings that already match are left
+// alone, so running it again with the same mappings makes no changes. The returned changes are those made before any
+// error.
+func (c *Client) SyncCATemplateMappings(caId int, mappings []CATemplateMapping) (*CATemplateMappingChanges, error) {
+	return c.SyncCATemplateMappingsContext(context.Background(), caId, mappings)
+}
+
+// SyncCATemplateMappingsContext is like SyncCATemplateMappings but uses ctx for the requests, allowing it to be
+// cancelled.
+func (c *Client) SyncCATemplateMappingsContext(ctx context.Context, caId int, mappings []CATemplateMapping) (*CATemplateMappingChanges, error) {
+	wanted := make(map[string]CATemplateMapping, len(mappings))
+	for i := range mappings {
+		if err := validateCATemplateMapping(caId, &mappings[i]); err != nil {
+			return nil, err
+		}
+		if _, ok := wanted[mappings[i].ProductId]; ok {
+			return nil, fmt.Errorf("product %s is mapped more than once", mappings[i].ProductId)
+		}
+		wanted[mappings[i].ProductId] = mappings[i]
+	}
+
+	current, err := c.ListCATemplateMappingsContext(ctx, caId)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]CATemplateMapping, len(current))
+	for _, m := range current {
+		existing[m.ProductId] = m
+	}
+
+	changes := &CATemplateMappingChanges{}
+	for _, m := range mappings {
+		if have, ok := existing[m.ProductId]; ok && sameCATemplateMapping(have, m) {
+			continue
+		}
+		m := m
+		if _, err := c.SetCATemplateMappingContext(ctx, caId, &m); err != nil {
+			return changes, err
+		}
+		changes.Set = append(changes.Set, m.ProductId)
+	}
+
+	var stale []string
+	for productId := range existing {
+		if _, ok := wanted[productId]; !ok {
+			stale = append(stale, productId)
+		}
+	}
+	sort.Strings(stale)
+	for _, productId := range stale {
+		if err := c.DeleteCATemplateMappingContext(ctx, caId, productId); err != nil {
+			return changes, err
+		}
+		changes.Removed = append(changes.Removed, productId)
+	}
+	return changes, nil
+}
+
+// sameCATemplateMapping reports whether two mappings of a product are equivalent, treating nil and empty parameters
+// alike.
+func sameCATemplateMapping(a, b CATemplateMapping) bool {
+	if a.ProductId != b.ProductId || a.TemplateShortName != b.TemplateShortName {
+		return false
+	}
+	if len(a.Parameters) == 0 && len(b.Parameters) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a.Parameters, b.Parameters)
+}
+
+// validateCATemplateMapping checks the fields of a mapping that Keyfactor requires.
+func validateCATemplateMapping(caId int, mapping *CATemplateMapping) error {
+	if caId <= 0 {
+		return errors.New("certificate authority id is required to set a template mapping")
+	}
+	if mapping == nil || mapping.ProductId == "" {
+		return errors.New("product id is required to set a template mapping")
+	}
+	if mapping.TemplateShortName == "" {
+		return fmt.Errorf("template short name is required to map product %s", mapping.ProductId)
+	}
+	return nil
+}
+
+// sendCARequest sets the Keyfactor headers on req, sends it, and decodes the response into v unless v is nil.
+func (c *Client) sendCARequest(req *request, v interface{}) error {
+	// Set Keyfactor-specific headers
+	req.Headers = &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	resp, err := c.sendRequest(req)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return nil
+	}
+	return c.decodeResponse(resp.Body, v)
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestClient_SyncCATemplateMappings(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		mappings = map[string]CATemplateMapping{
+			"ssl_plus":     {ProductId: "ssl_plus", TemplateShortName: "DigiCertSSL", Parameters: map[string]string{"LifetimeDays": "397"}},
+			"ssl_ev_basic": {ProductId: "ssl_ev_basic", TemplateShortName: "DigiCertEV"},
+			"wildcard":     {ProductId: "wildcard", TemplateShortName: "DigiCertWildcard"},
+		}
+		writes []string
+	)
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		const base = "/KeyfactorAPI/CertificateAuthority/4/TemplateMappings"
+		switch {
+		case r.Method == "GET" && r.URL.Path == base:
+			list := []CATemplateMapping{}
+			for _, m := range mappings {
+				list = append(list, m)
+			}
+			json.NewEncoder(w).Encode(list)
+		case r.Method == "PUT" && r.URL.Path == base:
+			var m CATemplateMapping
+			json.NewDecoder(r.Body).Decode(&m)
+			mappings[m.ProductId] = m
+			writes = append(writes, "PUT "+m.ProductId)
+			json.NewEncoder(w).Encode(m)
+		case r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, base+"/"):
+			productId := strings.TrimPrefix(r.URL.Path, base+"/")
+			delete(mappings, productId)
+			writes = append(writes, "DELETE "+productId)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	desired := []CATemplateMapping{
+		{ProductId: "ssl_plus", TemplateShortName: "DigiCertSSL", Parameters: map[string]string{"LifetimeDays": "397"}},
+		{ProductId: "ssl_ev_basic", TemplateShortName: "DigiCertEVBasic", Parameters: map[string]string{}},
+		{ProductId: "Sectigo OV SSL", TemplateShortName: "SectigoOV"},
+	}
+	changes, err := c.SyncCATemplateMappings(4, desired)
+	if err != nil {
+		t.Fatalf("SyncCATemplateMappings() error = %v", err)
+	}
+	want := &CATemplateMappingChanges{Set: []string{"ssl_ev_basic", "Sectigo OV SSL"}, Removed: []string{"wildcard"}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("SyncCATemplateMappings() = %+v, want %+v", changes, want)
+	}
+
+	got, err := c.ListCATemplateMappings(4)
+	if err != nil {
+		t.Fatalf("ListCATemplateMappings() error = %v", err)
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i].ProductId < got[j].ProductId })
+	if len(got) != 3 || got[0].ProductId != "Sectigo OV SSL" || got[1].TemplateShortName != "DigiCertEVBasic" {
+		t.Errorf("ListCATemplateMappings() = %+v", got)
+	}
+
+	writes = nil
+	changes, err = c.SyncCATemplateMappings(4, desired)
+	if err != nil || len(changes.Set) != 0 || len(changes.Removed) != 0 || len(writes) != 0 {
+		t.Errorf("second SyncCATemplateMappings() = %+v, %v with writes %v, want no changes", changes, err, writes)
+	}
+}
+
+func TestClient_SetCATemplateMapping_Validation(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected %s request to %s", r.Method, r.URL.Path)
+	})
+
+	tests := []struct {
+		name    string
+		caId    int
+		mapping *CATemplateMapping
+		wantErr string
+	}{
+		{name: "NoCA", mapping: &CATemplateMapping{ProductId: "ssl_plus", TemplateShortName: "DigiCertSSL"}, wantErr: "certificate authority id is required"},
+		{name: "NoMapping", caId: 4, wantErr: "product id is required"},
+		{name: "NoTemplate", caId: 4, mapping: &CATemplateMapping{ProductId: "ssl_plus"}, wantErr: "template short name is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := c.SetCATemplateMapping(tt.caId, tt.mapping); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("SetCATemplateMapping() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	duplicate := []CATemplateMapping{
+		{ProductId: "ssl_plus", TemplateShortName: "DigiCertSSL"},
+		{ProductId: "ssl_plus", TemplateShortName: "DigiCertOther"},
+	}
+	if _, err := c.SyncCATemplateMappings(4, duplicate); err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Errorf("SyncCATemplateMappings() with a duplicate product error = %v", err)
+	}
+}
@@ -1,5 +1,6 @@
 package api
 
+// CA is a certificate authority as returned by Keyfactor.
 type CA struct {
 	Id                     int    `json:"Id"`
 	LogicalName            string `json:"LogicalName"`
@@ -30,4 +31,127 @@ type CA struct {
 	} `json:"ExplicitPassword"`
 	UseAllowedRequesters bool     `json:"UseAllowedRequesters"`
 	AllowedRequesters    []string `json:"AllowedRequesters"`
+
+	// CAType is CATypeDCOM for a Microsoft CA reached over DCOM, or CATypeHTTPS for a CA reached through an AnyCA
+	// Gateway over HTTPS.
+	CAType int `json:"CAType"`
+	// ConfigurationTenant is the forest or tenant the CA is configured in.
+	ConfigurationTenant string    `json:"ConfigurationTenant,omitempty"`
+	DelegateEnrollment  bool      `json:"DelegateEnrollment"`
+	KeyRetentionDate    Timestamp `json:"KeyRetentionDate,omitempty"`
+	EnforceUniqueDN     bool      `json:"EnforceUniqueDN"`
+	// AllowOneClickRenewals and NewEndEntityOnRenewAndReissue control renewals of certificates issued by the CA.
+	AllowOneClickRenewals         bool `json:"AllowOneClickRenewals"`
+	NewEndEntityOnRenewAndReissue bool `json:"NewEndEntityOnRenewAndReissue"`
+	UseForEnrollment              bool `json:"UseForEnrollment"`
+	// FullScan, IncrementalScan, and ThresholdCheck are the CA synchronization and monitoring schedules.
+	FullScan        *InventorySchedule `json:"FullScan,omitempty"`
+	IncrementalScan *InventorySchedule `json:"IncrementalScan,omitempty"`
+	ThresholdCheck  *InventorySchedule `json:"ThresholdCheck,omitempty"`
+	// AuthCertificate describes the client certificate Keyfactor authenticates to an HTTPS CA with.
+	AuthCertificate *CAAuthCertificate `json:"AuthCertificate,omitempty"`
+	LastScan        Timestamp          `json:"LastScan,omitempty"`
+}
+
+// Certificate authority connection types.
+const (
+	CATypeDCOM  = 0
+	CATypeHTTPS = 1
+)
+
+// Key retention policies for the private keys of certificates issued by a CA. KeyRetentionAfterExpiration and
+// KeyRetentionFromIssuance keep keys for KeyRetentionDays, and KeyRetentionUntilDate until KeyRetentionDate.
+const (
+	KeyRetentionIndefinite      = 0
+	KeyRetentionAfterExpiration = 1
+	KeyRetentionFromIssuance    = 2
+	KeyRetentionUntilDate       = 3
+)
+
+// CAAuthCertificate identifies the client certificate used to authenticate to an HTTPS CA.
+type CAAuthCertificate struct {
+	IssuedDN       string    `json:"IssuedDN,omitempty"`
+	IssuerDN       string    `json:"IssuerDN,omitempty"`
+	Thumbprint     string    `json:"Thumbprint,omitempty"`
+	ExpirationDate Timestamp `json:"ExpirationDate,omitempty"`
+}
+
+// CreateCAArgs holds the function arguments used for calling the CreateCA method. Which fields apply depends on
+// CAType: DCOM CAs may use ExplicitCredentials, ExplicitUser, and ExplicitPassword, while HTTPS CAs require
+// AuthCertificate and AuthCertificatePassword.
+type CreateCAArgs struct {
+	LogicalName         string `json:"LogicalName"`
+	HostName            string `json:"HostName"`
+	ForestRoot          string `json:"ForestRoot,omitempty"`
+	ConfigurationTenant string `json:"ConfigurationTenant,omitempty"`
+	CAType              int    `json:"CAType"`
+	Delegate            bool   `json:"Delegate"`
+	DelegateEnrollment  bool   `json:"DelegateEnrollment"`
+	Remote              bool   `json:"Remote"`
+	Agent               string `json:"Agent,omitempty"`
+	Standalone          bool   `json:"Standalone"`
+	UseForEnrollment    bool   `json:"UseForEnrollment"`
+	// Properties is a JSON object of CA-specific settings, such as those of an AnyCA Gateway plugin.
+	Properties             string `json:"Properties,omitempty"`
+	AllowedEnrollmentTypes int    `json:"AllowedEnrollmentTypes"`
+
+	// Enforcement policies applied to enrollments through the CA.
+	RFCEnforcement                bool     `json:"RFCEnforcement"`
+	EnforceUniqueDN               bool     `json:"EnforceUniqueDN"`
+	SubscriberTerms               bool     `json:"SubscriberTerms"`
+	AllowOneClickRenewals         bool     `json:"AllowOneClickRenewals"`
+	NewEndEntityOnRenewAndReissue bool     `json:"NewEndEntityOnRenewAndReissue"`
+	UseAllowedRequesters          bool     `json:"UseAllowedRequesters"`
+	AllowedRequesters             []string `json:"AllowedRequesters,omitempty"`
+
+	// KeyRetention is one of the KeyRetention constants.
+	KeyRetention     int    `json:"KeyRetention"`
+	KeyRetentionDays int    `json:"KeyRetentionDays,omitempty"`
+	KeyRetentionDate string `json:"KeyRetentionDate,omitempty"`
+
+	MonitorThresholds bool `json:"MonitorThresholds"`
+	IssuanceMax       int  `json:"IssuanceMax,omitempty"`
+	IssuanceMin       int  `json:"IssuanceMin,omitempty"`
+	DenialMax         int  `json:"DenialMax,omitempty"`
+	FailureMax        int  `json:"FailureMax,omitempty"`
+
+	FullScan        *InventorySchedule `json:"FullScan,omitempty"`
+	IncrementalScan *InventorySchedule `json:"IncrementalScan,omitempty"`
+	ThresholdCheck  *InventorySchedule `json:"ThresholdCheck,omitempty"`
+
+	ExplicitCredentials bool         `json:"ExplicitCredentials"`
+	ExplicitUser        string       `json:"ExplicitUser,omitempty"`
+	ExplicitPassword    *StoreSecret `json:"ExplicitPassword,omitempty"`
+
+	// AuthCertificate is the base64 encoded PFX of the client certificate used to authenticate to an HTTPS CA, and
+	// AuthCertificatePassword its password.
+	AuthCertificate         *StoreSecret `json:"AuthCertificate,omitempty"`
+	AuthCertificatePassword *StoreSecret `json:"AuthCertificatePassword,omitempty"`
+}
+
+// UpdateCAArgs holds the function arguments used for calling the UpdateCA method. Keyfactor replaces the whole CA
+// configuration, so every field should be set, not just the changed ones. AuthCertificate may be left nil to keep the
+// current client certificate.
+type UpdateCAArgs struct {
+	Id int `json:"Id"`
+	CreateCAArgs
+}
+
+// CATemplateMapping maps a product of a public CA reached through an AnyCA Gateway, such as a DigiCert or Sectigo
+// certificate product, to the Keyfactor template that enrollments for the product use.
+type CATemplateMapping struct {
+	// ProductId is the gateway's identifier of the product, e.g. "ssl_plus" for DigiCert.
+	ProductId string `json:"ProductId"`
+	// TemplateShortName is the short name of the Keyfactor template mapped to the product.
+	TemplateShortName string `json:"TemplateShortName"`
+	// Parameters are product-specific enrollment settings passed to the gateway plugin, such as a validity period or
+	// organization ID.
+	Parameters map[string]string `json:"Parameters,omitempty"`
+}
+
+// CATemplateMappingChanges lists the products whose mappings SyncCATemplateMappings created or replaced, and those
+// whose mappings it removed.
+type CATemplateMappingChanges struct {
+	Set     []string
+	Removed []string
 }
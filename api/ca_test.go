@@ -1,8 +1,10 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -38,3 +40,115 @@ func TestClient_GetCAList(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_SaveCA(t *testing.T) {
+	var lastMethod string
+	var lastBody map[string]interface{}
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		lastMethod = r.Method
+		lastBody = nil
+		json.NewDecoder(r.Body).Decode(&lastBody)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/KeyfactorAPI/CertificateAuthority/3" && r.Method == "DELETE":
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/KeyfactorAPI/CertificateAuthority/3":
+			w.Write([]byte(`{"Id": 3, "LogicalName": "gateway", "CAType": 1, "AuthCertificate": {"IssuedDN": "CN=kf-client", "Thumbprint": "ABCD"}}`))
+		case r.URL.Path == "/KeyfactorAPI/CertificateAuthority":
+			w.Write([]byte(`{"Id": 3, "LogicalName": "gateway", "CAType": 1, "KeyRetention": 1, "KeyRetentionDays": 30}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	https := CreateCAArgs{
+		LogicalName:             "gateway",
+		HostName:                "gateway.example.com",
+		CAType:                  CATypeHTTPS,
+		EnforceUniqueDN:         true,
+		KeyRetention:            KeyRetentionAfterExpiration,
+		KeyRetentionDays:        30,
+		AuthCertificate:         &StoreSecret{SecretValue: "MIIK..."},
+		AuthCertificatePassword: &StoreSecret{SecretValue: "secret"},
+		FullScan:                &InventorySchedule{Daily: &InventoryDaily{Time: "2024-01-01T02:00:00Z"}},
+	}
+	dcom := CreateCAArgs{
+		LogicalName:         "CorpIssuing",
+		HostName:            "ca01.corp.example.com",
+		ForestRoot:          "corp.example.com",
+		ExplicitCredentials: true,
+		ExplicitUser:        "CORP\\svc-kf",
+		ExplicitPassword:    &StoreSecret{Provider: 2, Parameters: map[string]string{"SecretName": "svc-kf"}},
+	}
+	withArgs := func(base CreateCAArgs, edit func(*CreateCAArgs)) *CreateCAArgs {
+		edit(&base)
+		return &base
+	}
+
+	tests := []struct {
+		name    string
+		args    *CreateCAArgs
+		wantErr string
+	}{
+		{name: "HTTPS", args: &https},
+		{name: "DCOM", args: &dcom},
+		{name: "HTTPSWithoutAuthCertificate", args: withArgs(https, func(a *CreateCAArgs) { a.AuthCertificate = nil }), wantErr: "auth certificate is required"},
+		{name: "HTTPSWithExplicitCredentials", args: withArgs(https, func(a *CreateCAArgs) { a.ExplicitCredentials = true }), wantErr: "only supported for DCOM"},
+		{name: "DCOMWithAuthCertificate", args: withArgs(dcom, func(a *CreateCAArgs) { a.AuthCertificate = https.AuthCertificate }), wantErr: "only supported for HTTPS"},
+		{name: "RetentionWithoutDays", args: withArgs(https, func(a *CreateCAArgs) { a.KeyRetentionDays = 0 }), wantErr: "key retention days"},
+		{name: "UnknownType", args: withArgs(dcom, func(a *CreateCAArgs) { a.CAType = 7 }), wantErr: "unknown certificate authority type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lastBody = nil
+			ca, err := c.CreateCA(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("CreateCA() error = %v, want %q", err, tt.wantErr)
+				}
+				if lastBody != nil {
+					t.Errorf("CreateCA() sent a request after failing validation")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateCA() error = %v", err)
+			}
+			if ca.Id != 3 || lastMethod != "POST" {
+				t.Errorf("CreateCA() = %+v via %s", ca, lastMethod)
+			}
+			if lastBody["CAType"] != float64(tt.args.CAType) || lastBody["HostName"] != tt.args.HostName {
+				t.Errorf("CreateCA() body = %v", lastBody)
+			}
+		})
+	}
+
+	t.Run("Update", func(t *testing.T) {
+		args := &UpdateCAArgs{Id: 3, CreateCAArgs: *withArgs(https, func(a *CreateCAArgs) { a.AuthCertificate, a.AuthCertificatePassword = nil, nil })}
+		if _, err := c.UpdateCA(args); err != nil {
+			t.Fatalf("UpdateCA() error = %v", err)
+		}
+		if lastMethod != "PUT" || lastBody["Id"] != float64(3) || lastBody["EnforceUniqueDN"] != true {
+			t.Errorf("UpdateCA() sent %s %v", lastMethod, lastBody)
+		}
+		if _, ok := lastBody["AuthCertificate"]; ok {
+			t.Errorf("UpdateCA() sent AuthCertificate without one being set")
+		}
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		ca, err := c.GetCA(3)
+		if err != nil {
+			t.Fatalf("GetCA() error = %v", err)
+		}
+		if ca.CAType != CATypeHTTPS || ca.AuthCertificate == nil || ca.AuthCertificate.Thumbprint != "ABCD" {
+			t.Errorf("GetCA() = %+v", ca)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := c.DeleteCA(3); err != nil || lastMethod != "DELETE" {
+			t.Errorf("DeleteCA() error = %v via %s", err, lastMethod)
+		}
+	})
+}
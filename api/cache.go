@@ -0,0 +1,215 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheConfig configures the optional read-through response cache enabled with Client.EnableCache.
+type CacheConfig struct {
+	// TTLs maps an endpoint, relative to the API path (e.g. "CertificateStoreTypes"), to how long GET responses from
+	// it and the paths below it are served from the cache. Endpoints not listed are never cached.
+	TTLs map[string]time.Duration
+}
+
+// DefaultCacheConfig returns a CacheConfig that caches the slow-changing resources: certificate store types,
+// templates, certificate authorities and certificate store containers.
+func DefaultCacheConfig() *CacheConfig {
+	return &CacheConfig{
+		TTLs: map[string]time.Duration{
+			"CertificateStoreTypes":      10 * time.Minute,
+			"Templates":                  10 * time.Minute,
+			"CertificateAuthority":       10 * time.Minute,
+			"CertificateStoreContainers": 5 * time.Minute,
+		},
+	}
+}
+
+// EnableCache turns on a read-through cache for GET requests to the endpoints in config, keyed by endpoint and
+// query. Fresh entries are returned without contacting Keyfactor; once an entry expires it is revalidated with
+// If-None-Match or If-Modified-Since when the original response carried an ETag or Last-Modified header. Any
+// non-GET request to a cached endpoint drops its entries, so the client's own changes are visible immediately.
+// A nil config uses DefaultCacheConfig. Enabling the cache again replaces the configuration and clears it.
+func (c *Client) EnableCache(config *CacheConfig) {
+	if config == nil {
+		config = DefaultCacheConfig()
+	}
+	ttls := make(map[string]time.Duration, len(config.TTLs))
+	for endpoint, ttl := range config.TTLs {
+		endpoint = strings.Trim(endpoint, "/")
+		if endpoint == "" || ttl <= 0 {
+			continue
+		}
+		ttls[endpoint] = ttl
+	}
+
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	c.cache = &responseCache{ttls: ttls, entries: map[string]*cacheEntry{}}
+}
+
+// DisableCache turns off the response cache and discards its entries.
+func (c *Client) DisableCache() {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	c.cache = nil
+}
+
+// InvalidateCache discards cached responses for endpoint and the paths below it, e.g. after the resource was changed
+// by another client. An empty endpoint discards everything.
+func (c *Client) InvalidateCache(endpoint string) {
+	if rc := c.responseCache(); rc != nil {
+		rc.invalidate(strings.Trim(endpoint, "/"))
+	}
+}
+
+func (c *Client) responseCache() *responseCache {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	return c.cache
+}
+
+type cacheEntry struct {
+	endpoint   string
+	statusCode int
+	header     http.Header
+	body       []byte
+	expires    time.Time
+}
+
+// response builds a fresh http.Response from the entry for req.
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        http.StatusText(e.statusCode),
+		StatusCode:    e.statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+type responseCache struct {
+	ttls map[string]time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// match returns the configured endpoint that urlPath falls under, and its TTL. The endpoint may appear anywhere in
+// the path so that it matches regardless of the API path prefix.
+func (rc *responseCache) match(urlPath string) (string, time.Duration, bool) {
+	p := strings.ToLower(strings.TrimSuffix(urlPath, "/"))
+	for endpoint, ttl := range rc.ttls {
+		e := "/" + strings.ToLower(endpoint)
+		if strings.HasSuffix(p, e) || strings.Contains(p, e+"/") {
+			return endpoint, ttl, true
+		}
+	}
+	return "", 0, false
+}
+
+func (rc *responseCache) get(key string) *cacheEntry {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.entries[key]
+}
+
+func (rc *responseCache) put(key string, entry *cacheEntry) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.entries[key] = entry
+}
+
+func (rc *responseCache) invalidate(endpoint string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for key, entry := range rc.entries {
+		if endpoint == "" || strings.EqualFold(entry.endpoint, endpoint) {
+			delete(rc.entries, key)
+		}
+	}
+}
+
+// cacheTransport is an http.RoundTripper that serves GET requests from the client's response cache, when enabled.
+type cacheTransport struct {
+	base   http.RoundTripper
+	client *Client
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rc := t.client.responseCache()
+	if rc == nil {
+		return t.base.RoundTrip(req)
+	}
+	endpoint, ttl, ok := rc.match(req.URL.Path)
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+	if req.Method != http.MethodGet {
+		resp, err := t.base.RoundTrip(req)
+		rc.invalidate(endpoint)
+		return resp, err
+	}
+
+	key := req.URL.String()
+	cached := rc.get(key)
+	if cached != nil && time.Now().Before(cached.expires) {
+		t.client.debugf("Serving %s from cache", key)
+		return cached.response(req), nil
+	}
+
+	outReq := req
+	if cached != nil {
+		etag := cached.header.Get("ETag")
+		lastModified := cached.header.Get("Last-Modified")
+		if etag != "" || lastModified != "" {
+			outReq = req.Clone(req.Context())
+			if etag != "" {
+				outReq.Header.Set("If-None-Match", etag)
+			}
+			if lastModified != "" {
+				outReq.Header.Set("If-Modified-Since", lastModified)
+			}
+		}
+	}
+
+	resp, err := t.base.RoundTrip(outReq)
+	if err != nil {
+		return resp, err
+	}
+	if resp.StatusCode == http.StatusNotModified && cached != nil && outReq != req {
+		resp.Body.Close()
+		t.client.debugf("Cached response for %s is still valid", key)
+		refreshed := *cached
+		refreshed.expires = time.Now().Add(ttl)
+		rc.put(key, &refreshed)
+		return refreshed.response(req), nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	rc.put(key, &cacheEntry{
+		endpoint:   endpoint,
+		statusCode: resp.StatusCode,
+		header:     resp.Header.Clone(),
+		body:       body,
+		expires:    time.Now().Add(ttl),
+	})
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	return resp, nil
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient_EnableCache(t *testing.T) {
+	hits := map[string]int{}
+	revalidated := 0
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		hits[r.Method+" "+r.URL.Path]++
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /KeyfactorAPI/CertificateStoreTypes":
+			w.Write([]byte(`[{"StoreType": 1, "ShortName": "IIS"}]`))
+		case "GET /KeyfactorAPI/Templates":
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				revalidated++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Write([]byte(`[{"Id": 1, "CommonName": "WebServer"}]`))
+		case "DELETE /KeyfactorAPI/CertificateStoreTypes/1":
+			w.WriteHeader(http.StatusNoContent)
+		case "GET /KeyfactorAPI/AppSetting":
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	// Nothing is cached until the cache is enabled.
+	c.ListCertificateStoreTypes()
+	c.ListCertificateStoreTypes()
+	if got := hits["GET /KeyfactorAPI/CertificateStoreTypes"]; got != 2 {
+		t.Fatalf("uncached store type requests = %d, want 2", got)
+	}
+
+	c.EnableCache(&CacheConfig{TTLs: map[string]time.Duration{
+		"CertificateStoreTypes": time.Hour,
+		"Templates":             time.Millisecond,
+	}})
+	hits = map[string]int{}
+
+	for i := 0; i < 3; i++ {
+		types, err := c.ListCertificateStoreTypes()
+		if err != nil || len(*types) != 1 || (*types)[0].ShortName != "IIS" {
+			t.Fatalf("ListCertificateStoreTypes() = %v, %v", types, err)
+		}
+	}
+	if got := hits["GET /KeyfactorAPI/CertificateStoreTypes"]; got != 1 {
+		t.Errorf("cached store type requests = %d, want 1", got)
+	}
+
+	// Endpoints not in the config are passed through.
+	c.GetAppSettings()
+	c.GetAppSettings()
+	if got := hits["GET /KeyfactorAPI/AppSetting"]; got != 2 {
+		t.Errorf("uncached app setting requests = %d, want 2", got)
+	}
+
+	// A write to a cached endpoint drops its entries.
+	if _, err := c.DeleteCertificateStoreType(1); err != nil {
+		t.Fatalf("DeleteCertificateStoreType() error = %v", err)
+	}
+	c.ListCertificateStoreTypes()
+	if got := hits["GET /KeyfactorAPI/CertificateStoreTypes"]; got != 2 {
+		t.Errorf("store type requests after delete = %d, want 2", got)
+	}
+
+	// Expired entries are revalidated with their ETag.
+	c.GetTemplates()
+	time.Sleep(5 * time.Millisecond)
+	templates, err := c.GetTemplates()
+	if err != nil || len(templates) != 1 || templates[0].CommonName != "WebServer" {
+		t.Fatalf("GetTemplates() after revalidation = %v, %v", templates, err)
+	}
+	if revalidated != 1 {
+		t.Errorf("revalidations = %d, want 1", revalidated)
+	}
+
+	c.InvalidateCache("")
+	c.ListCertificateStoreTypes()
+	c.DisableCache()
+	c.ListCertificateStoreTypes()
+	if got := hits["GET /KeyfactorAPI/CertificateStoreTypes"]; got != 4 {
+		t.Errorf("store type requests after invalidate and disable = %d, want 4", got)
+	}
+}
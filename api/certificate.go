@@ -8,10 +8,11 @@ import (
 	"errors"
 	"fmt"
 	"github.com/Keyfactor/keyfactor-go-client-sdk/api/keyfactor"
+	"github.com/Keyfactor/keyfactor-go-client/query"
 	"github.com/spbsoluble/go-pkcs12"
 	"go.mozilla.org/pkcs7"
-	"log"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -20,7 +21,12 @@ import (
 // EnrollPFX takes arguments for EnrollPFXFctArgs to facilitate a call to Keyfactor
 // that enrolls a PFX certificate with the supplied arguments.
 func (c *Client) EnrollPFX(ea *EnrollPFXFctArgs) (*EnrollResponse, error) {
-	log.Println("[INFO] Enrolling PFX certificate with Keyfactor")
+	return c.EnrollPFXContext(context.Background(), ea)
+}
+
+// EnrollPFXContext is like EnrollPFX but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) EnrollPFXContext(ctx context.Context, ea *EnrollPFXFctArgs) (*EnrollResponse, error) {
+	c.infof("Enrolling PFX certificate with Keyfactor")
 
 	/* Ensure required inputs exist */
 	var missingFields []string
@@ -50,18 +56,24 @@ func (c *Client) EnrollPFX(ea *EnrollPFXFctArgs) (*EnrollResponse, error) {
 			if err != nil {
 				return nil, err
 			}
+			c.debugf("Certificate subject created: %s", subject)
 			ea.SubjectString = subject
 		} else {
 			return nil, fmt.Errorf("subject is required to use enrollpfx(). Please configure either SubjectString or Subject")
 		}
 	}
 
+	if ea.ValidateTemplate {
+		if err := c.validateAgainstTemplate(ctx, ea.Template, ea.SANs, ea.AdditionalEnrollmentFields, ea.KeyUsage); err != nil {
+			return nil, err
+		}
+	}
+
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 	xCertificateFormat := ea.CertFormat
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
 	newRenewalCertId := int32(ea.RenewalCertificateId)
 	newTimestamp, err := time.Parse(ea.Timestamp, ea.Timestamp)
@@ -84,8 +96,12 @@ func (c *Client) EnrollPFX(ea *EnrollPFXFctArgs) (*EnrollResponse, error) {
 		Template:                    &ea.Template,
 		SANs:                        &newSANs,
 	}
+	if len(ea.AdditionalEnrollmentFields) > 0 {
+		// The SDK models enrollment fields as nested objects, but Keyfactor expects a flat name/value map.
+		req.AdditionalProperties = map[string]interface{}{"AdditionalEnrollmentFields": ea.AdditionalEnrollmentFields}
+	}
 
-	resp, _, err := apiClient.EnrollmentApi.EnrollmentPostPFXEnroll(context.Background()).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XCertificateformat(xCertificateFormat).XKeyfactorApiVersion(xKeyfactorApiVersion).Request(req).Execute()
+	resp, _, err := apiClient.EnrollmentApi.EnrollmentPostPFXEnroll(ctx).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XCertificateformat(xCertificateFormat).XKeyfactorApiVersion(xKeyfactorApiVersion).Request(req).Execute()
 
 	//newIssuerDN := resp.CertificateInformation.IssuerDN.Get()
 
@@ -128,7 +144,12 @@ func (c *Client) EnrollPFX(ea *EnrollPFXFctArgs) (*EnrollResponse, error) {
 //   - Leaf certificate
 //   - Certificate chain
 func (c *Client) DownloadCertificate(certId int, thumbprint string, serialNumber string, issuerDn string) (*x509.Certificate, []*x509.Certificate, error) {
-	log.Println("[INFO] Downloading certificate")
+	return c.DownloadCertificateContext(context.Background(), certId, thumbprint, serialNumber, issuerDn)
+}
+
+// DownloadCertificateContext is like DownloadCertificate but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) DownloadCertificateContext(ctx context.Context, certId int, thumbprint string, serialNumber string, issuerDn string) (*x509.Certificate, []*x509.Certificate, error) {
+	c.infof("Downloading certificate")
 
 	/* The download certificate endpoint requires one of the following to retrieve a cert:
 		- CertID
@@ -149,12 +170,12 @@ func (c *Client) DownloadCertificate(certId int, thumbprint string, serialNumber
 	if !validInput {
 		return nil, nil, fmt.Errorf("certID, thumbprint, or serial number AND issuer DN required to dowload certificate")
 	}
+	thumbprint = NormalizeThumbprint(thumbprint)
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
 	newCertId := int32(certId)
 	newIssuerDN := keyfactor.NullableString{}
@@ -168,7 +189,11 @@ func (c *Client) DownloadCertificate(certId int, thumbprint string, serialNumber
 		IncludeChain: nil,
 	}
 
-	resp, _, err := apiClient.CertificateApi.CertificateDownloadCertificateAsync(context.Background()).Rq(rq).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	downloadReq := apiClient.CertificateApi.CertificateDownloadCertificateAsync(ctx).Rq(rq).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion)
+	if collectionId := collectionFromContext(ctx); collectionId > 0 {
+		downloadReq = downloadReq.CollectionId(int32(collectionId))
+	}
+	resp, _, err := downloadReq.Execute()
 
 	mapResp, _ := resp.ToMap()
 	jsonData, _ := json.Marshal(mapResp)
@@ -205,13 +230,24 @@ func (c *Client) DownloadCertificate(certId int, thumbprint string, serialNumber
 //   - Template             : string
 //   - CertificateAuthority : string
 func (c *Client) EnrollCSR(ea *EnrollCSRFctArgs) (*EnrollResponse, error) {
-	log.Println("[INFO] Signing CSR with Keyfactor")
+	return c.EnrollCSRContext(context.Background(), ea)
+}
+
+// EnrollCSRContext is like EnrollCSR but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) EnrollCSRContext(ctx context.Context, ea *EnrollCSRFctArgs) (*EnrollResponse, error) {
+	c.infof("Signing CSR with Keyfactor")
 
 	/* Ensure required inputs exist */
 	if (ea.Template == "") || (ea.CertificateAuthority == "") {
 		return nil, errors.New("invalid or nonexistent values required for csr enrollment")
 	}
 
+	if ea.ValidateTemplate {
+		if err := c.validateAgainstTemplate(ctx, ea.Template, ea.SANs, ea.AdditionalEnrollmentFields, ea.KeyUsage); err != nil {
+			return nil, err
+		}
+	}
+
 	if ea.Timestamp == "" {
 		ea.Timestamp = getTimestamp()
 	}
@@ -220,14 +256,17 @@ func (c *Client) EnrollCSR(ea *EnrollCSRFctArgs) (*EnrollResponse, error) {
 	xKeyfactorApiVersion := "1"
 	xCertificateFormat := ea.CertFormat
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
 	eaJson, _ := json.Marshal(ea)
 	var req keyfactor.ModelsEnrollmentCSREnrollmentRequest
 	json.Unmarshal(eaJson, &req)
+	if len(ea.AdditionalEnrollmentFields) > 0 {
+		// The SDK models enrollment fields as nested objects, but Keyfactor expects a flat name/value map.
+		req.AdditionalProperties = map[string]interface{}{"AdditionalEnrollmentFields": ea.AdditionalEnrollmentFields}
+	}
 
-	resp, _, err := apiClient.EnrollmentApi.EnrollmentPostCSREnroll(context.Background()).XCertificateformat(xCertificateFormat).Request(req).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, _, err := apiClient.EnrollmentApi.EnrollmentPostCSREnroll(ctx).XCertificateformat(xCertificateFormat).Request(req).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
 		return nil, err
@@ -253,9 +292,14 @@ func (c *Client) EnrollCSR(ea *EnrollCSRFctArgs) (*EnrollResponse, error) {
 //   - CertificateIds : []int
 //   - Comment        : string
 func (c *Client) RevokeCert(rvargs *RevokeCertArgs) error {
-	log.Println("[INFO] Revoking certificates")
+	return c.RevokeCertContext(context.Background(), rvargs)
+}
+
+// RevokeCertContext is like RevokeCert but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) RevokeCertContext(ctx context.Context, rvargs *RevokeCertArgs) error {
+	c.infof("Revoking certificates")
 	for _, certs := range rvargs.CertificateIds {
-		log.Printf("[TRACE] Revoking ID %d", certs)
+		c.debugf("Revoking ID %d", certs)
 	}
 
 	// Fields required by revoke cert API request are cert ID & comment
@@ -271,14 +315,18 @@ func (c *Client) RevokeCert(rvargs *RevokeCertArgs) error {
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
 	raJson, _ := json.Marshal(rvargs)
 	var req keyfactor.ModelsRevokeCertificateRequest
 	json.Unmarshal(raJson, &req)
+	if rvargs.CollectionId == 0 {
+		if collectionId := int32(collectionFromContext(ctx)); collectionId > 0 {
+			req.CollectionId = &collectionId
+		}
+	}
 
-	_, httpResp, err := apiClient.CertificateApi.CertificateRevoke(context.Background()).Request(req).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	_, httpResp, err := apiClient.CertificateApi.CertificateRevoke(ctx).Request(req).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
 		return err
@@ -299,6 +347,11 @@ func (c *Client) RevokeCert(rvargs *RevokeCertArgs) error {
 //   - CertificateId : int
 //   - RequestId     : int
 func (c *Client) DeployPFXCertificate(args *DeployPFXArgs) (*DeployPFXResp, error) {
+	return c.DeployPFXCertificateContext(context.Background(), args)
+}
+
+// DeployPFXCertificateContext is like DeployPFXCertificate but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) DeployPFXCertificateContext(ctx context.Context, args *DeployPFXArgs) (*DeployPFXResp, error) {
 	err := validateDeployPFXArgs(args)
 	if err != nil {
 		return nil, err
@@ -307,14 +360,13 @@ func (c *Client) DeployPFXCertificate(args *DeployPFXArgs) (*DeployPFXResp, erro
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
 	argsJson, _ := json.Marshal(args)
 	var req keyfactor.KeyfactorApiModelsEnrollmentEnrollmentManagementRequest
 	json.Unmarshal(argsJson, &req)
 
-	resp, _, err := apiClient.EnrollmentApi.EnrollmentInstallPFXToCertStore(context.Background()).Request(req).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, _, err := apiClient.EnrollmentApi.EnrollmentInstallPFXToCertStore(ctx).Request(req).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
 		return nil, err
@@ -331,10 +383,16 @@ func (c *Client) DeployPFXCertificate(args *DeployPFXArgs) (*DeployPFXResp, erro
 // GetCertificateContext takes arguments for GetCertificateContextArgs used to facilitate the retrieval
 // of certificate context. The primary query required to get certificate context is the certificate ID. Include metadata
 // and include locations add additional data, but can be set to false if they are unneeded. A pointer to a
-// GetCertificateResponse structure is returned, containing the certificate context.
+// GetCertificateResponse structure is returned, containing the certificate context. Verbose can be set to return
+// subject alternative names and other details with it.
 
 // TODO: change to allow acception of Thumbprint in place of ID
 func (c *Client) GetCertificateContext(gca *GetCertificateContextArgs) (*GetCertificateResponse, error) {
+	return c.GetCertificateContextContext(context.Background(), gca)
+}
+
+// GetCertificateContextContext is like GetCertificateContext but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) GetCertificateContextContext(ctx context.Context, gca *GetCertificateContextArgs) (*GetCertificateResponse, error) {
 
 	if gca.Id == 0 {
 		return nil, errors.New("keyfactor certificate id is required to get certificate")
@@ -343,10 +401,24 @@ func (c *Client) GetCertificateContext(gca *GetCertificateContextArgs) (*GetCert
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
-	resp, _, err := apiClient.CertificateApi.CertificateGetCertificate(context.Background(), int32(gca.Id)).IncludeLocations(*gca.IncludeLocations).IncludeMetadata(*gca.IncludeMetadata).CollectionId(int32(*gca.CollectionId)).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	collectionId := collectionFromContext(ctx)
+	if gca.CollectionId != nil && *gca.CollectionId > 0 {
+		collectionId = *gca.CollectionId
+	}
+
+	getReq := apiClient.CertificateApi.CertificateGetCertificate(ctx, int32(gca.Id)).CollectionId(int32(collectionId)).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion)
+	if gca.IncludeLocations != nil {
+		getReq = getReq.IncludeLocations(*gca.IncludeLocations)
+	}
+	if gca.IncludeMetadata != nil {
+		getReq = getReq.IncludeMetadata(*gca.IncludeMetadata)
+	}
+	if gca.Verbose != nil {
+		getReq = getReq.Verbose(int32(*gca.Verbose))
+	}
+	resp, _, err := getReq.Execute()
 
 	if err != nil {
 		return nil, err
@@ -360,11 +432,23 @@ func (c *Client) GetCertificateContext(gca *GetCertificateContextArgs) (*GetCert
 	return &newResp, err
 }
 
+// ListCertificates takes arguments for a map of search terms to facilitate a call to Keyfactor that lists the
+// matching certificates. The supported keys are "collection", "subject", "thumbprint", and "sortField" to sort the
+// results by a field, descending unless "sortAscending" is "true". What is returned with each certificate is set with
+// "includeMetadata", "includeHasPrivateKey", and "includeLocations" (which defaults to "true"), and with "verbose",
+// a Keyfactor verbosity level such as VerboseSANs. Without "collection", ListCertificatesContext uses
+// the collection set with WithCollection, if any.
 func (c *Client) ListCertificates(q map[string]string) ([]GetCertificateResponse, error) {
+	return c.ListCertificatesContext(context.Background(), q)
+}
+
+// ListCertificatesContext is like ListCertificates but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) ListCertificatesContext(ctx context.Context, q map[string]string) ([]GetCertificateResponse, error) {
 
-	type query struct {
+	type certQuery struct {
 		collectionId         int32
 		pqQueryString        string
+		includeLocations     bool
 		includeMetadata      bool
 		includeHasPrivateKey bool
 		verbose              int32
@@ -376,9 +460,10 @@ func (c *Client) ListCertificates(q map[string]string) ([]GetCertificateResponse
 		pqIncludeExpired     bool
 	}
 
-	newQuery := query{
+	newQuery := certQuery{
 		collectionId:         0,
 		pqQueryString:        "",
+		includeLocations:     true,
 		includeMetadata:      false,
 		includeHasPrivateKey: false,
 		verbose:              0,
@@ -394,31 +479,46 @@ func (c *Client) ListCertificates(q map[string]string) ([]GetCertificateResponse
 	if ok {
 		collectionIdInt, _ := strconv.ParseInt(searchCollection, 10, 64)
 		newQuery.collectionId = int32(collectionIdInt)
+	} else {
+		newQuery.collectionId = int32(collectionFromContext(ctx))
 	}
 	subjectName, ok := q["subject"]
 	if ok {
-		newQuery.pqQueryString = fmt.Sprintf(`IssuedCN -eq "%s"`, subjectName)
+		newQuery.pqQueryString = query.Field("IssuedCN").Eq(subjectName).String()
 	}
 	tp, tpOk := q["thumbprint"]
 
 	if tpOk {
-		newQuery.pqQueryString = fmt.Sprintf(`Thumbprint -eq "%s"`, tp)
+		newQuery.pqQueryString = query.Field("Thumbprint").Eq(NormalizeThumbprint(tp)).String()
+	}
+	if include, ok := q["includeLocations"]; ok {
+		newQuery.includeLocations, _ = strconv.ParseBool(include)
+	}
+	newQuery.includeMetadata, _ = strconv.ParseBool(q["includeMetadata"])
+	newQuery.includeHasPrivateKey, _ = strconv.ParseBool(q["includeHasPrivateKey"])
+	if verbose, err := strconv.Atoi(q["verbose"]); err == nil {
+		newQuery.verbose = int32(verbose)
+	}
+	if sortField, ok := q["sortField"]; ok {
+		newQuery.pqSortField = sortField
+		// Keyfactor sorts ascending for 0 and descending for 1.
+		if ascending, _ := strconv.ParseBool(q["sortAscending"]); !ascending {
+			newQuery.pqSortAscending = 1
+		}
 	}
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
-	resp, _, err := apiClient.CertificateApi.CertificateQueryCertificates(context.Background()).XKeyfactorRequestedWith(xKeyfactorRequestedWith).CollectionId(newQuery.collectionId).IncludeLocations(true).IncludeMetadata(newQuery.includeMetadata).IncludeHasPrivateKey(newQuery.includeHasPrivateKey).Verbose(newQuery.verbose).XKeyfactorApiVersion(xKeyfactorApiVersion).PqQueryString(newQuery.pqQueryString).PqPageReturned(newQuery.pqPageReturned).PqReturnLimit(newQuery.pqReturnLimit).PqSortField(newQuery.pqSortField).PqSortAscending(newQuery.pqSortAscending).PqIncludeRevoked(newQuery.pqIncludeRevoked).PqIncludeExpired(newQuery.pqIncludeExpired).Execute()
+	resp, _, err := apiClient.CertificateApi.CertificateQueryCertificates(ctx).XKeyfactorRequestedWith(xKeyfactorRequestedWith).CollectionId(newQuery.collectionId).IncludeLocations(newQuery.includeLocations).IncludeMetadata(newQuery.includeMetadata).IncludeHasPrivateKey(newQuery.includeHasPrivateKey).Verbose(newQuery.verbose).XKeyfactorApiVersion(xKeyfactorApiVersion).PqQueryString(newQuery.pqQueryString).PqPageReturned(newQuery.pqPageReturned).PqReturnLimit(newQuery.pqReturnLimit).PqSortField(newQuery.pqSortField).PqSortAscending(newQuery.pqSortAscending).PqIncludeRevoked(newQuery.pqIncludeRevoked).PqIncludeExpired(newQuery.pqIncludeExpired).Execute()
 
 	if err != nil {
 		return nil, err
 	}
 
-	var newResp []GetCertificateResponse
-
+	newResp := make([]GetCertificateResponse, 0, len(resp))
 	for i := range resp {
 		mapResp, _ := resp[i].ToMap()
 		jsonData, _ := json.Marshal(mapResp)
@@ -427,7 +527,68 @@ func (c *Client) ListCertificates(q map[string]string) ([]GetCertificateResponse
 		newResp = append(newResp, newCert)
 	}
 
-	return newResp, err
+	return newResp, nil
+}
+
+// GetExpiringCertificates searches Keyfactor for certificates that expire within the supplied window, measured from
+// now. Revoked and already-expired certificates are excluded. If collectionId is greater than zero, the search is
+// scoped to that certificate collection. Paging is optional; when nil, Keyfactor's default page settings are used.
+// Results are returned sorted by NotAfter, soonest first, unless paging sets a SortField.
+func (c *Client) GetExpiringCertificates(window time.Duration, collectionId int, paging *Paging) ([]GetCertificateResponse, error) {
+	c.infof("Searching for certificates expiring within %s", window)
+
+	if window <= 0 {
+		return nil, errors.New("expiration window must be greater than zero")
+	}
+
+	now := time.Now().UTC()
+	qStr, err := query.Field("NotAfter").Ge(now).And(query.Field("NotAfter").Le(now.Add(window))).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	q := apiQuery{
+		Query: []StringTuple{
+			{"pq.queryString", qStr},
+			{"pq.sortField", "NotAfter"},
+			{"pq.sortAscending", "0"},
+			{"pq.includeRevoked", "false"},
+			{"pq.includeExpired", "false"},
+		},
+	}
+	if collectionId > 0 {
+		q.Query = append(q.Query, StringTuple{"collectionId", strconv.Itoa(collectionId)})
+	}
+	q.Query = append(q.Query, paging.query()...)
+
+	keyfactorAPIStruct := &request{
+		Method:   "GET",
+		Endpoint: "Certificates",
+		Headers:  headers,
+		Query:    &q,
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonResp []GetCertificateResponse
+	err = c.decodeResponse(resp.Body, &jsonResp)
+	if err != nil {
+		return nil, err
+	}
+
+	sortCertificatesByNotAfter(jsonResp)
+	return jsonResp, nil
 }
 
 // RecoverCertificate takes arguments for RecoverCertArgs to facilitate a call to Keyfactor
@@ -443,7 +604,12 @@ func (c *Client) ListCertificates(q map[string]string) ([]GetCertificateResponse
 //   - Leaf certificate (*x509.Certificate)
 //   - Certificate chain ([]*x509.Certificate)
 func (c *Client) RecoverCertificate(certId int, thumbprint string, serialNumber string, issuerDn string, password string) (interface{}, *x509.Certificate, []*x509.Certificate, error) {
-	log.Println("[INFO] Recovering certificate ID:", certId)
+	return c.RecoverCertificateContext(context.Background(), certId, thumbprint, serialNumber, issuerDn, password)
+}
+
+// RecoverCertificateContext is like RecoverCertificate but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) RecoverCertificateContext(ctx context.Context, certId int, thumbprint string, serialNumber string, issuerDn string, password string) (interface{}, *x509.Certificate, []*x509.Certificate, error) {
+	c.infof("Recovering certificate ID: %d", certId)
 	/* The download certificate endpoint requires one of the following to retrieve a cert:
 		- CertID
 		- Thumbprint
@@ -463,6 +629,7 @@ func (c *Client) RecoverCertificate(certId int, thumbprint string, serialNumber
 	if !validInput {
 		return nil, nil, nil, fmt.Errorf("certID, thumbprint, or serial number AND issuer DN required to dowload certificate")
 	}
+	thumbprint = NormalizeThumbprint(thumbprint)
 
 	if password == "" {
 		return nil, nil, nil, fmt.Errorf("password required to recover private key with certificate")
@@ -471,8 +638,7 @@ func (c *Client) RecoverCertificate(certId int, thumbprint string, serialNumber
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
 	newCertId := int32(certId)
 	newIssuerDN := keyfactor.NullableString{}
@@ -488,7 +654,7 @@ func (c *Client) RecoverCertificate(certId int, thumbprint string, serialNumber
 		IncludeChain: &newIncludeChain,
 	}
 
-	resp, _, err := apiClient.CertificateApi.CertificateRecoverCertificateAsync(context.Background()).XKeyfactorRequestedWith(xKeyfactorRequestedWith).Rq(newReq).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, _, err := apiClient.CertificateApi.CertificateRecoverCertificateAsync(ctx).XKeyfactorRequestedWith(xKeyfactorRequestedWith).Rq(newReq).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
 		return nil, nil, nil, err
@@ -509,6 +675,39 @@ func (c *Client) RecoverCertificate(certId int, thumbprint string, serialNumber
 	return priv, leaf, chain, nil
 }
 
+// MetadataValues returns the certificate's metadata, returned when it is retrieved with metadata included, as a map of
+// field name to value. Non-string values are formatted with fmt. It returns nil if the certificate has no metadata.
+func (r *GetCertificateResponse) MetadataValues() map[string]string {
+	fields, ok := r.Metadata.(map[string]interface{})
+	if !ok || len(fields) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(fields))
+	for name, value := range fields {
+		if value == nil {
+			continue
+		}
+		if s, ok := value.(string); ok {
+			values[name] = s
+		} else {
+			values[name] = fmt.Sprint(value)
+		}
+	}
+	return values
+}
+
+// sortCertificatesByNotAfter sorts certificates by expiration, soonest first. Certificates with an unparseable NotAfter
+// are placed at the end.
+func sortCertificatesByNotAfter(certs []GetCertificateResponse) {
+	sort.SliceStable(certs, func(i, j int) bool {
+		ti, tj := certs[i].NotAfter, certs[j].NotAfter
+		if !ti.Valid() || !tj.Valid() {
+			return ti.Valid() && !tj.Valid()
+		}
+		return ti.Before(tj.Time)
+	})
+}
+
 // createSubject builds the certificate subject string from a passed CertificateSubject argument.
 func createSubject(cs CertificateSubject) (string, error) {
 	var subject string
@@ -534,7 +733,6 @@ func createSubject(cs CertificateSubject) (string, error) {
 		subject += "C=" + cs.SubjectCountry + ","
 	}
 	subject = strings.TrimRight(subject, ",") // remove trailing comma
-	log.Printf("[DEBUG] createSubject(): Certificate subject created: %s\n", subject)
 	return subject, nil
 }
 
@@ -560,7 +758,6 @@ func validateDeployPFXArgs(dpfxa *DeployPFXArgs) error {
 
 // decodePKCS12Blob decodes a PKCS12 blob.
 func decodePKCS12Blob(resp *EnrollResponse) error {
-	log.Println("[TRACE] Decoding certificate")
 	// Keyfactor returns base-64 PFX (PKCS#12) or zipped certificate. Decode here.
 	if resp.CertificateInformation.PKCS12Blob != "" {
 		cert, err := base64.StdEncoding.DecodeString(resp.CertificateInformation.PKCS12Blob)
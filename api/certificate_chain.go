@@ -0,0 +1,150 @@
+package api
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"go.mozilla.org/pkcs7"
+)
+
+// GetCertificateChain takes arguments for a Keyfactor certificate ID and downloads the certificate along with its full
+// issuing chain from Keyfactor Command. The returned slice is ordered from the leaf certificate to the root, such that
+// each certificate is followed by its issuer.
+func (c *Client) GetCertificateChain(certId int) ([]*x509.Certificate, error) {
+	c.infof("Downloading certificate chain for certificate ID %d", certId)
+
+	if certId <= 0 {
+		return nil, errors.New("keyfactor certificate id is required to get certificate chain")
+	}
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+			{"x-certificateformat", "P7B"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "POST",
+		Endpoint: "Certificates/Download",
+		Headers:  headers,
+		Payload: &downloadCertificateBody{
+			CertID:       certId,
+			IncludeChain: true,
+		},
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonResp downloadCertificateResponse
+	err = c.decodeResponse(resp.Body, &jsonResp)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := base64.StdEncoding.DecodeString(jsonResp.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	p7, err := pkcs7.Parse(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return orderCertificateChain(p7.Certificates)
+}
+
+// ValidateChain verifies that chain, ordered leaf first as returned by GetCertificateChain, builds a valid path to one
+// of the certificates in roots. If roots is nil, the system root pool is used. Certificates in the chain other than the
+// leaf are used as intermediates; a self-signed root included in the chain is only trusted if it is also present in
+// roots. The verified chains are returned on success.
+func ValidateChain(chain []*x509.Certificate, roots *x509.CertPool) ([][]*x509.Certificate, error) {
+	if len(chain) == 0 {
+		return nil, errors.New("certificate chain is empty")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		if isSelfSigned(cert) {
+			continue
+		}
+		intermediates.AddCert(cert)
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	return chain[0].Verify(opts)
+}
+
+// orderCertificateChain sorts an unordered set of certificates into a chain starting at the leaf and ending at the
+// highest certificate in the set. An error is returned if the set does not form a single chain.
+func orderCertificateChain(certs []*x509.Certificate) ([]*x509.Certificate, error) {
+	if len(certs) == 0 {
+		return nil, errors.New("no certificates returned by Keyfactor")
+	}
+
+	// The leaf is the only certificate that did not issue any other certificate in the set.
+	var leaves []*x509.Certificate
+	for _, candidate := range certs {
+		issuedOther := false
+		for _, other := range certs {
+			if other != candidate && issuedBy(other, candidate) {
+				issuedOther = true
+				break
+			}
+		}
+		if !issuedOther {
+			leaves = append(leaves, candidate)
+		}
+	}
+	if len(leaves) != 1 {
+		return nil, fmt.Errorf("unable to determine leaf certificate, found %d candidates", len(leaves))
+	}
+
+	chain := []*x509.Certificate{leaves[0]}
+	current := leaves[0]
+	for len(chain) < len(certs) && !isSelfSigned(current) {
+		var next *x509.Certificate
+		for _, cert := range certs {
+			if cert != current && issuedBy(current, cert) {
+				next = cert
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		chain = append(chain, next)
+		current = next
+	}
+
+	if len(chain) != len(certs) {
+		return nil, fmt.Errorf("certificates returned by Keyfactor do not form a single chain: ordered %d of %d", len(chain), len(certs))
+	}
+	return chain, nil
+}
+
+// issuedBy reports whether child was issued by parent, comparing names and verifying the signature.
+func issuedBy(child, parent *x509.Certificate) bool {
+	if !bytes.Equal(child.RawIssuer, parent.RawSubject) {
+		return false
+	}
+	return child.CheckSignatureFrom(parent) == nil
+}
+
+// isSelfSigned reports whether cert is signed by its own key.
+func isSelfSigned(cert *x509.Certificate) bool {
+	return issuedBy(cert, cert)
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.mozilla.org/pkcs7"
+)
+
+// testChain holds a generated three-tier PKI used by certificate tests.
+type testChain struct {
+	root, intermediate, leaf *x509.Certificate
+	leafKey                  *ecdsa.PrivateKey
+}
+
+// newTestCert issues a certificate for cn signed by parent (or self-signed when parent is nil).
+func newTestCert(t *testing.T, cn string, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: cn},
+		DNSNames:              []string{cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if isCA {
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+		tmpl.ExtKeyUsage = nil
+		tmpl.DNSNames = nil
+	}
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent, parentKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func newTestChain(t *testing.T) *testChain {
+	t.Helper()
+	root, rootKey := newTestCert(t, "Test Root CA", true, nil, nil)
+	intermediate, intKey := newTestCert(t, "Test Issuing CA", true, root, rootKey)
+	leaf, leafKey := newTestCert(t, "www.example.com", false, intermediate, intKey)
+	return &testChain{root: root, intermediate: intermediate, leaf: leaf, leafKey: leafKey}
+}
+
+func TestClient_GetCertificateChain(t *testing.T) {
+	chain := newTestChain(t)
+
+	// Keyfactor does not guarantee the order of certificates inside the P7B
+	var raw []byte
+	for _, cert := range []*x509.Certificate{chain.intermediate, chain.root, chain.leaf} {
+		raw = append(raw, cert.Raw...)
+	}
+	p7, err := pkcs7.DegenerateCertificate(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/KeyfactorAPI/Certificates/Download" || r.Header.Get("x-certificateformat") != "P7B" || body["IncludeChain"] != true {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"Content": base64.StdEncoding.EncodeToString(p7)})
+	})
+
+	tests := []struct {
+		name    string
+		certId  int
+		want    []*x509.Certificate
+		wantErr bool
+	}{
+		{
+			name:   "OrderedLeafToRoot",
+			certId: 42,
+			want:   []*x509.Certificate{chain.leaf, chain.intermediate, chain.root},
+		},
+		{
+			name:    "MissingId",
+			certId:  0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.GetCertificateChain(tt.certId)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetCertificateChain() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("GetCertificateChain() returned %d certificates, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("GetCertificateChain()[%d] = %s, want %s", i, got[i].Subject, tt.want[i].Subject)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateChain(t *testing.T) {
+	chain := newTestChain(t)
+	other := newTestChain(t)
+
+	trusted := x509.NewCertPool()
+	trusted.AddCert(chain.root)
+	untrusted := x509.NewCertPool()
+	untrusted.AddCert(other.root)
+
+	tests := []struct {
+		name    string
+		chain   []*x509.Certificate
+		roots   *x509.CertPool
+		wantErr bool
+	}{
+		{
+			name:  "TrustedRoot",
+			chain: []*x509.Certificate{chain.leaf, chain.intermediate, chain.root},
+			roots: trusted,
+		},
+		{
+			name:    "UntrustedRootInChainIsIgnored",
+			chain:   []*x509.Certificate{chain.leaf, chain.intermediate, chain.root},
+			roots:   untrusted,
+			wantErr: true,
+		},
+		{
+			name:    "MissingIntermediate",
+			chain:   []*x509.Certificate{chain.leaf},
+			roots:   trusted,
+			wantErr: true,
+		},
+		{
+			name:    "Empty",
+			roots:   trusted,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateChain(tt.chain, tt.roots)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateChain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_orderCertificateChain(t *testing.T) {
+	chain := newTestChain(t)
+	other := newTestChain(t)
+
+	tests := []struct {
+		name    string
+		certs   []*x509.Certificate
+		wantErr bool
+	}{
+		{name: "Shuffled", certs: []*x509.Certificate{chain.root, chain.leaf, chain.intermediate}},
+		{name: "LeafOnly", certs: []*x509.Certificate{chain.leaf}},
+		{name: "TwoChains", certs: []*x509.Certificate{chain.leaf, other.leaf}, wantErr: true},
+		{name: "Empty", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := orderCertificateChain(tt.certs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("orderCertificateChain() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !got[0].Equal(chain.leaf) {
+				t.Errorf("orderCertificateChain()[0] = %s, want leaf", got[0].Subject)
+			}
+		})
+	}
+}
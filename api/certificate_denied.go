@@ -0,0 +1,214 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Keyfactor/keyfactor-go-client/query"
+)
+
+// DenialReason classifies why a certificate request was denied.
+type DenialReason string
+
+// Reasons of a DeniedRequest.
+const (
+	// DenialReasonApprover is a request rejected by a person approving requests for its template or CA.
+	DenialReasonApprover DenialReason = "approver"
+	// DenialReasonPolicy is a request that broke a template or CA policy, such as a key size or subject restriction.
+	DenialReasonPolicy DenialReason = "policy"
+	// DenialReasonWorkflow is a request denied by a workflow step, such as a failed webhook.
+	DenialReasonWorkflow DenialReason = "workflow"
+	// DenialReasonUnknown is a request denied for a reason Keyfactor did not record.
+	DenialReasonUnknown DenialReason = "unknown"
+)
+
+// DeniedRequest is a denied certificate request, returned by ListDeniedRequests.
+type DeniedRequest struct {
+	Id                   int               `json:"Id"`
+	CARequestId          string            `json:"CARequestId"`
+	CommonName           string            `json:"CommonName"`
+	DistinguishedName    string            `json:"DistinguishedName"`
+	SubmissionDate       Timestamp         `json:"SubmissionDate"`
+	CertificateAuthority string            `json:"CertificateAuthority"`
+	Template             string            `json:"Template"`
+	Requester            string            `json:"Requester"`
+	StateLabel           string            `json:"StateLabel"`
+	Metadata             map[string]string `json:"Metadata"`
+	// Comment is the comment given when the request was denied.
+	Comment string `json:"Comment"`
+
+	// Reason and DeniedBy are filled in by ListDeniedRequests from the comment and from the audit log entry recording
+	// the denial, if one names the request. DeniedBy is empty if no entry does.
+	Reason   DenialReason `json:"-"`
+	DeniedBy string       `json:"-"`
+	// DeniedAt is the time of the audit log entry recording the denial, or the zero Timestamp if there is none.
+	DeniedAt Timestamp `json:"-"`
+}
+
+// DenialSummary counts denied certificate requests, for reporting. It is returned by SummarizeDeniedRequests.
+type DenialSummary struct {
+	Total      int
+	ByReason   map[DenialReason]int
+	ByTemplate map[string]int
+	// ByDenier counts requests by DeniedBy. Requests with no recorded denier are counted under "".
+	ByDenier map[string]int
+}
+
+// ListDeniedRequests takes arguments for a time to facilitate calls to Keyfactor that return the certificate requests
+// submitted since then that were denied, oldest first. Each request's Reason is inferred from its denial comment and
+// the wording of the audit log entry recording the denial, and DeniedBy is the user of that entry, so denials Keyfactor
+// words differently may be reported as DenialReasonUnknown.
+func (c *Client) ListDeniedRequests(since time.Time) ([]DeniedRequest, error) {
+	return c.ListDeniedRequestsContext(context.Background(), since)
+}
+
+// ListDeniedRequestsContext is like ListDeniedRequests but uses ctx for the requests, allowing it to be cancelled.
+func (c *Client) ListDeniedRequestsContext(ctx context.Context, since time.Time) ([]DeniedRequest, error) {
+	c.infof("Listing certificate requests denied since %s", since.Format(time.RFC3339))
+
+	if since.IsZero() {
+		return nil, errors.New("a start time is required to list denied certificate requests")
+	}
+	q, err := query.Field("SubmissionDate").Ge(since).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	denied := []DeniedRequest{}
+	err = c.fetchAllPages(ctx, (*FetchAllOptions)(nil).withDefaults(), func(paging *Paging) (int, error) {
+		page, err := c.searchDeniedRequests(ctx, q, paging)
+		denied = append(denied, page...)
+		return len(page), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(denied) == 0 {
+		return denied, nil
+	}
+
+	auditQuery, err := query.And(
+		query.Field("Timestamp").Ge(since),
+		query.Field("Message").Contains("denied"),
+	).Build()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := c.ListAllAuditLogsContext(ctx, auditQuery, &FetchAllOptions{SortField: "Timestamp", SortAscending: true})
+	if err != nil {
+		return nil, err
+	}
+	for i := range denied {
+		req := &denied[i]
+		if entry := denialAuditEntry(req, entries); entry != nil {
+			req.DeniedBy = entry.User
+			req.DeniedAt = entry.Timestamp
+		}
+		req.Reason = classifyDenial(req)
+	}
+	sort.SliceStable(denied, func(i, j int) bool { return denied[i].SubmissionDate.Before(denied[j].SubmissionDate.Time) })
+	return denied, nil
+}
+
+// searchDeniedRequests returns a page of the denied certificate requests matching q.
+func (c *Client) searchDeniedRequests(ctx context.Context, q string, paging *Paging) ([]DeniedRequest, error) {
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	params := []StringTuple{{"pq.queryString", q}}
+	params = append(params, paging.query()...)
+
+	keyfactorAPIStruct := &request{
+		Method:   "GET",
+		Endpoint: "Workflow/Certificates/Denied",
+		Headers:  headers,
+		Query:    &apiQuery{Query: params},
+		Context:  ctx,
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonResp []DeniedRequest
+	err = c.decodeResponse(resp.Body, &jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// denialAuditEntry returns the latest audit log entry naming the denied request by its CA request ID, or nil.
+func denialAuditEntry(req *DeniedRequest, entries []AuditLogEntry) *AuditLogEntry {
+	if req.CARequestId == "" {
+		return nil
+	}
+	var found *AuditLogEntry
+	for i := range entries {
+		e := &entries[i]
+		if e.AuditIdentifier == req.CARequestId || containsWord(e.Message, req.CARequestId) {
+			found = e
+		}
+	}
+	return found
+}
+
+// containsWord reports whether s contains word, delimited by characters other than letters and digits.
+func containsWord(s, word string) bool {
+	for i := 0; ; {
+		j := strings.Index(s[i:], word)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(word)
+		if (start == 0 || !isAlnum(s[start-1])) && (end == len(s) || !isAlnum(s[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func isAlnum(b byte) bool {
+	return b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// classifyDenial infers why a request was denied from its comment and who denied it.
+func classifyDenial(req *DeniedRequest) DenialReason {
+	comment := strings.ToLower(req.Comment)
+	switch {
+	case strings.Contains(comment, "policy") || strings.Contains(comment, "key size") ||
+		strings.Contains(comment, "not allowed") || strings.Contains(comment, "not permitted"):
+		return DenialReasonPolicy
+	case strings.Contains(comment, "workflow") || strings.Contains(comment, "webhook"):
+		return DenialReasonWorkflow
+	case req.DeniedBy != "" || comment != "":
+		return DenialReasonApprover
+	default:
+		return DenialReasonUnknown
+	}
+}
+
+// SummarizeDeniedRequests counts the denied requests by reason, template, and denier.
+func SummarizeDeniedRequests(denied []DeniedRequest) *DenialSummary {
+	s := &DenialSummary{
+		Total:      len(denied),
+		ByReason:   map[DenialReason]int{},
+		ByTemplate: map[string]int{},
+		ByDenier:   map[string]int{},
+	}
+	for _, req := range denied {
+		s.ByReason[req.Reason]++
+		s.ByTemplate[req.Template]++
+		s.ByDenier[req.DeniedBy]++
+	}
+	return s
+}
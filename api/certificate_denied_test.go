@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_ListDeniedRequests(t *testing.T) {
+	var deniedQuery string
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/KeyfactorAPI/Workflow/Certificates/Denied":
+			deniedQuery = r.URL.Query().Get("pq.queryString")
+			w.Write([]byte(`[
+				{"Id": 3, "CARequestId": "118", "CommonName": "db.example.com", "SubmissionDate": "2024-05-03T09:00:00Z", "Template": "WebServer", "Comment": "RSA key size below policy minimum"},
+				{"Id": 1, "CARequestId": "11", "CommonName": "app.example.com", "SubmissionDate": "2024-05-01T09:00:00Z", "Template": "WebServer", "Comment": "Not an approved hostname"},
+				{"Id": 2, "CARequestId": "12", "CommonName": "vpn.example.com", "SubmissionDate": "2024-05-02T09:00:00Z", "Template": "VPN"}
+			]`))
+		case "/KeyfactorAPI/Audit":
+			w.Write([]byte(`[
+				{"Id": 40, "Timestamp": "2024-05-01T10:00:00Z", "Message": "Certificate request 11 denied", "User": "EXAMPLE\\approver"},
+				{"Id": 41, "Timestamp": "2024-05-03T10:00:00Z", "Message": "Certificate request 118 denied", "User": "EXAMPLE\\secops"}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	got, err := c.ListDeniedRequests(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("ListDeniedRequests() error = %v", err)
+	}
+	if !strings.HasPrefix(deniedQuery, "SubmissionDate -ge ") {
+		t.Errorf("ListDeniedRequests() searched %q", deniedQuery)
+	}
+
+	type denial struct {
+		Id       int
+		Reason   DenialReason
+		DeniedBy string
+	}
+	var denials []denial
+	for _, req := range got {
+		denials = append(denials, denial{req.Id, req.Reason, req.DeniedBy})
+	}
+	want := []denial{
+		{1, DenialReasonApprover, `EXAMPLE\approver`},
+		{2, DenialReasonUnknown, ""},
+		{3, DenialReasonPolicy, `EXAMPLE\secops`},
+	}
+	if !reflect.DeepEqual(denials, want) {
+		t.Errorf("ListDeniedRequests() = %+v, want %+v", denials, want)
+	}
+
+	summary := SummarizeDeniedRequests(got)
+	if summary.Total != 3 || summary.ByTemplate["WebServer"] != 2 || summary.ByReason[DenialReasonPolicy] != 1 || summary.ByDenier[""] != 1 {
+		t.Errorf("SummarizeDeniedRequests() = %+v", summary)
+	}
+
+	if _, err := c.ListDeniedRequests(time.Time{}); err == nil {
+		t.Errorf("ListDeniedRequests() with no start time succeeded, want error")
+	}
+}
@@ -0,0 +1,391 @@
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SAN type flags used by Keyfactor in enrollment requests and template regexes.
+const (
+	SANTypeDNS   = "dns"
+	SANTypeIP4   = "ip4"
+	SANTypeIP6   = "ip6"
+	SANTypeURI   = "uri"
+	SANTypeUPN   = "ms_ntprincipalname"
+	SANTypeEmail = "rfc822"
+)
+
+// Keyfactor template enrollment field data types.
+const (
+	enrollmentFieldString         = 1
+	enrollmentFieldMultipleChoice = 2
+)
+
+var (
+	oidExtensionSubjectAltName   = asn1.ObjectIdentifier{2, 5, 29, 17}
+	oidExtensionKeyUsage         = asn1.ObjectIdentifier{2, 5, 29, 15}
+	oidExtensionExtendedKeyUsage = asn1.ObjectIdentifier{2, 5, 29, 37}
+	oidExtensionTLSFeature       = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 24}
+	oidUserPrincipalName         = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 20, 2, 3}
+
+	// tlsFeatureStatusRequest is the status_request TLS extension number used to signal must-staple.
+	tlsFeatureStatusRequest = 5
+)
+
+// extKeyUsageOIDs maps the extended key usages that can be hinted to their object identifiers.
+var extKeyUsageOIDs = map[x509.ExtKeyUsage]asn1.ObjectIdentifier{
+	x509.ExtKeyUsageAny:             {2, 5, 29, 37, 0},
+	x509.ExtKeyUsageServerAuth:      {1, 3, 6, 1, 5, 5, 7, 3, 1},
+	x509.ExtKeyUsageClientAuth:      {1, 3, 6, 1, 5, 5, 7, 3, 2},
+	x509.ExtKeyUsageCodeSigning:     {1, 3, 6, 1, 5, 5, 7, 3, 3},
+	x509.ExtKeyUsageEmailProtection: {1, 3, 6, 1, 5, 5, 7, 3, 4},
+	x509.ExtKeyUsageTimeStamping:    {1, 3, 6, 1, 5, 5, 7, 3, 8},
+	x509.ExtKeyUsageOCSPSigning:     {1, 3, 6, 1, 5, 5, 7, 3, 9},
+}
+
+// byType returns the SANs keyed by their Keyfactor SAN type flag, omitting empty types.
+func (s *SANs) byType() map[string][]string {
+	out := map[string][]string{}
+	if s == nil {
+		return out
+	}
+	for sanType, values := range map[string][]string{
+		SANTypeDNS:   s.DNS,
+		SANTypeIP4:   s.IP4,
+		SANTypeIP6:   s.IP6,
+		SANTypeURI:   s.URI,
+		SANTypeUPN:   s.UPN,
+		SANTypeEmail: s.Email,
+	} {
+		if len(values) > 0 {
+			out[sanType] = values
+		}
+	}
+	return out
+}
+
+// sanTypeForSubjectPart maps the subject part of a template regex to the SAN type it governs. Keyfactor versions
+// differ in how SAN regexes are labelled, so the common spellings are accepted.
+func sanTypeForSubjectPart(part string) (string, bool) {
+	switch strings.ToLower(part) {
+	case "dns", "dnsname":
+		return SANTypeDNS, true
+	case "ip4", "ipv4", "ipv4address":
+		return SANTypeIP4, true
+	case "ip6", "ipv6", "ipv6address":
+		return SANTypeIP6, true
+	case "uri", "uniformresourceidentifier":
+		return SANTypeURI, true
+	case "upn", "ms_ntprincipalname":
+		return SANTypeUPN, true
+	case "rfc822", "rfc822name", "email", "mail":
+		return SANTypeEmail, true
+	}
+	return "", false
+}
+
+// AllowedSANTypes returns the SAN types the template accepts. A template that defines regexes for SAN subject parts
+// only accepts those SAN types; a template without SAN regexes places no restriction on SAN types and nil is returned.
+func (t *GetTemplateResponse) AllowedSANTypes() []string {
+	return allowedSANTypes(t.TemplateRegexes)
+}
+
+func allowedSANTypes(regexes []TemplateRegex) []string {
+	var allowed []string
+	seen := map[string]bool{}
+	for _, regex := range regexes {
+		if sanType, ok := sanTypeForSubjectPart(regex.SubjectPart); ok && !seen[sanType] {
+			seen[sanType] = true
+			allowed = append(allowed, sanType)
+		}
+	}
+	sort.Strings(allowed)
+	return allowed
+}
+
+// ValidateEnrollment checks SANs, custom enrollment field values, and key usage hints against the template's policy
+// and returns an error describing every violation. Any of the arguments may be nil.
+func (t *GetTemplateResponse) ValidateEnrollment(sans *SANs, fields map[string]interface{}, hints *KeyUsageHints) error {
+	var problems []string
+	problems = append(problems, validateSANs(t.CommonName, t.TemplateRegexes, sans)...)
+	problems = append(problems, validateEnrollmentFields(t.EnrollmentFields, fields)...)
+	problems = append(problems, t.validateKeyUsageHints(hints)...)
+	if len(problems) > 0 {
+		return fmt.Errorf("enrollment does not satisfy template %s: %s", t.CommonName, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validateSANs checks sans against the SAN types permitted by regexes and the regexes themselves.
+func validateSANs(template string, regexes []TemplateRegex, sans *SANs) []string {
+	var problems []string
+	allowed := allowedSANTypes(regexes)
+	byType := sans.byType()
+
+	types := make([]string, 0, len(byType))
+	for sanType := range byType {
+		types = append(types, sanType)
+	}
+	sort.Strings(types)
+
+	for _, sanType := range types {
+		if allowed != nil && !containsString(allowed, sanType) {
+			problems = append(problems, fmt.Sprintf("SAN type %s is not allowed (allowed: %s)", sanType, strings.Join(allowed, ", ")))
+			continue
+		}
+		for _, regex := range regexes {
+			if part, ok := sanTypeForSubjectPart(regex.SubjectPart); !ok || part != sanType {
+				continue
+			}
+			re, err := regexp.Compile(regex.RegEx)
+			if err != nil {
+				logTo(nil, LogLevelWarn, nil, "Ignoring invalid %s regex on template %s: %v", regex.SubjectPart, template, err)
+				continue
+			}
+			for _, value := range byType[sanType] {
+				if !re.MatchString(value) {
+					msg := regex.Error
+					if msg == "" {
+						msg = fmt.Sprintf("does not match %s", regex.RegEx)
+					}
+					problems = append(problems, fmt.Sprintf("%s SAN %q %s", sanType, value, msg))
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// validateEnrollmentFields checks that every field is defined in defs and that multiple choice values are valid.
+func validateEnrollmentFields(defs []TemplateEnrollmentFields, fields map[string]interface{}) []string {
+	var problems []string
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var def *TemplateEnrollmentFields
+		for i := range defs {
+			if strings.EqualFold(defs[i].Name, name) {
+				def = &defs[i]
+				break
+			}
+		}
+		if def == nil {
+			problems = append(problems, fmt.Sprintf("enrollment field %q is not defined on the template", name))
+			continue
+		}
+		value := fmt.Sprint(fields[name])
+		if def.DataType == enrollmentFieldMultipleChoice && !containsString(def.Options, value) {
+			problems = append(problems, fmt.Sprintf("enrollment field %q must be one of %s, got %q", name, strings.Join(def.Options, ", "), value))
+		}
+	}
+	return problems
+}
+
+func (t *GetTemplateResponse) validateKeyUsageHints(hints *KeyUsageHints) []string {
+	if hints == nil {
+		return nil
+	}
+	var problems []string
+	if hints.KeyUsage != 0 && t.KeyUsage != 0 {
+		if missing := keyUsageFlags(hints.KeyUsage) &^ t.KeyUsage; missing != 0 {
+			problems = append(problems, fmt.Sprintf("template does not grant key usage flags 0x%x", missing))
+		}
+	}
+	if len(hints.ExtKeyUsage) > 0 && len(t.ExtendedKeyUsages) > 0 {
+		for _, eku := range hints.ExtKeyUsage {
+			oid, ok := extKeyUsageOIDs[eku]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("unsupported extended key usage %d", eku))
+				continue
+			}
+			granted := false
+			for _, templateEku := range t.ExtendedKeyUsages {
+				granted = granted || templateEku.Oid == oid.String()
+			}
+			if !granted {
+				problems = append(problems, fmt.Sprintf("template does not grant extended key usage %s", oid))
+			}
+		}
+	}
+	return problems
+}
+
+// keyUsageFlags converts an x509.KeyUsage to the X509KeyUsageFlags bitmask Keyfactor reports on templates.
+func keyUsageFlags(ku x509.KeyUsage) int {
+	var flags int
+	for bit := 0; bit < 9; bit++ {
+		if ku&(1<<bit) == 0 {
+			continue
+		}
+		if bit == 8 {
+			flags |= 0x8000 // DecipherOnly
+		} else {
+			flags |= 0x80 >> bit
+		}
+	}
+	return flags
+}
+
+// findTemplate looks up a template by its common name or template name.
+func (c *Client) findTemplate(ctx context.Context, name string) (*GetTemplateResponse, error) {
+	templates, err := c.GetTemplatesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range templates {
+		if strings.EqualFold(templates[i].CommonName, name) || strings.EqualFold(templates[i].TemplateName, name) {
+			return &templates[i], nil
+		}
+	}
+	return nil, fmt.Errorf("template %s not found", name)
+}
+
+// validateAgainstTemplate looks up the named template and validates the enrollment against it.
+func (c *Client) validateAgainstTemplate(ctx context.Context, name string, sans *SANs, fields map[string]interface{}, hints *KeyUsageHints) error {
+	template, err := c.findTemplate(ctx, name)
+	if err != nil {
+		return err
+	}
+	return template.ValidateEnrollment(sans, fields, hints)
+}
+
+// NewCSR builds a PEM encoded certificate signing request for key, suitable for EnrollCSRFctArgs.CSR. All SAN types in
+// sans are encoded, including UPNs as Microsoft otherName entries, and hints are written as key usage, extended key
+// usage, and TLS feature (must-staple) extensions.
+func NewCSR(key crypto.Signer, subject pkix.Name, sans *SANs, hints *KeyUsageHints) (string, error) {
+	var extensions []pkix.Extension
+
+	if len(sans.byType()) > 0 {
+		ext, err := marshalSANs(sans)
+		if err != nil {
+			return "", err
+		}
+		extensions = append(extensions, ext)
+	}
+
+	if hints != nil {
+		if hints.KeyUsage != 0 {
+			ext, err := marshalKeyUsage(hints.KeyUsage)
+			if err != nil {
+				return "", err
+			}
+			extensions = append(extensions, ext)
+		}
+		if len(hints.ExtKeyUsage) > 0 {
+			oids := make([]asn1.ObjectIdentifier, 0, len(hints.ExtKeyUsage))
+			for _, eku := range hints.ExtKeyUsage {
+				oid, ok := extKeyUsageOIDs[eku]
+				if !ok {
+					return "", fmt.Errorf("unsupported extended key usage %d", eku)
+				}
+				oids = append(oids, oid)
+			}
+			value, err := asn1.Marshal(oids)
+			if err != nil {
+				return "", err
+			}
+			extensions = append(extensions, pkix.Extension{Id: oidExtensionExtendedKeyUsage, Value: value})
+		}
+		if hints.MustStaple {
+			value, err := asn1.Marshal([]int{tlsFeatureStatusRequest})
+			if err != nil {
+				return "", err
+			}
+			extensions = append(extensions, pkix.Extension{Id: oidExtensionTLSFeature, Value: value})
+		}
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:         subject,
+		ExtraExtensions: extensions,
+	}, key)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})), nil
+}
+
+// marshalSANs encodes sans as a subjectAltName extension (RFC 5280 section 4.2.1.6).
+func marshalSANs(sans *SANs) (pkix.Extension, error) {
+	var names []asn1.RawValue
+	for _, email := range sans.Email {
+		names = append(names, asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 1, Bytes: []byte(email)})
+	}
+	for _, dns := range sans.DNS {
+		names = append(names, asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 2, Bytes: []byte(dns)})
+	}
+	for _, uri := range sans.URI {
+		names = append(names, asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 6, Bytes: []byte(uri)})
+	}
+	for _, ip := range sans.IP4 {
+		parsed := net.ParseIP(ip).To4()
+		if parsed == nil {
+			return pkix.Extension{}, fmt.Errorf("invalid IPv4 SAN %q", ip)
+		}
+		names = append(names, asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 7, Bytes: parsed})
+	}
+	for _, ip := range sans.IP6 {
+		parsed := net.ParseIP(ip)
+		if parsed == nil || parsed.To4() != nil {
+			return pkix.Extension{}, fmt.Errorf("invalid IPv6 SAN %q", ip)
+		}
+		names = append(names, asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 7, Bytes: parsed.To16()})
+	}
+	for _, upn := range sans.UPN {
+		typeId, err := asn1.Marshal(oidUserPrincipalName)
+		if err != nil {
+			return pkix.Extension{}, err
+		}
+		value, err := asn1.MarshalWithParams(upn, "explicit,tag:0,utf8")
+		if err != nil {
+			return pkix.Extension{}, err
+		}
+		names = append(names, asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: append(typeId, value...)})
+	}
+
+	value, err := asn1.Marshal(names)
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	return pkix.Extension{Id: oidExtensionSubjectAltName, Value: value}, nil
+}
+
+// marshalKeyUsage encodes ku as a critical key usage extension.
+func marshalKeyUsage(ku x509.KeyUsage) (pkix.Extension, error) {
+	var b [2]byte
+	bitLength := 0
+	for bit := 0; bit < 9; bit++ {
+		if ku&(1<<bit) != 0 {
+			b[bit/8] |= 0x80 >> (bit % 8)
+			bitLength = bit + 1
+		}
+	}
+	value, err := asn1.Marshal(asn1.BitString{Bytes: b[:(bitLength+7)/8], BitLength: bitLength})
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	return pkix.Extension{Id: oidExtensionKeyUsage, Critical: true, Value: value}, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
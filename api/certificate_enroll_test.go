@@ -0,0 +1,273 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var enrollTestTemplate = GetTemplateResponse{
+	Id:         12,
+	CommonName: "WebServer",
+	TemplateRegexes: []TemplateRegex{
+		{SubjectPart: "CN", RegEx: `.*`},
+		{SubjectPart: "DNS", RegEx: `^[a-z0-9.-]+\.example\.com$`, Error: "must be in example.com"},
+		{SubjectPart: "IPv4", RegEx: `^10\.`},
+	},
+	EnrollmentFields: []TemplateEnrollmentFields{
+		{Id: 1, Name: "Ticket", DataType: enrollmentFieldString},
+		{Id: 2, Name: "Environment", DataType: enrollmentFieldMultipleChoice, Options: []string{"prod", "test"}},
+	},
+	KeyUsage:          0xa0, // DigitalSignature | KeyEncipherment
+	ExtendedKeyUsages: []TemplateExtendedKeyUsage{{Id: 1, Oid: "1.3.6.1.5.5.7.3.1", DisplayName: "Server Authentication"}},
+}
+
+func TestGetTemplateResponse_ValidateEnrollment(t *testing.T) {
+	tests := []struct {
+		name     string
+		template GetTemplateResponse
+		sans     *SANs
+		fields   map[string]interface{}
+		hints    *KeyUsageHints
+		wantErr  string
+	}{
+		{
+			name:     "Valid",
+			template: enrollTestTemplate,
+			sans:     &SANs{DNS: []string{"www.example.com"}, IP4: []string{"10.0.0.5"}},
+			fields:   map[string]interface{}{"Ticket": "CHG0042", "environment": "prod"},
+			hints: &KeyUsageHints{
+				KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+				ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			},
+		},
+		{
+			name:     "SANTypeNotAllowed",
+			template: enrollTestTemplate,
+			sans:     &SANs{DNS: []string{"www.example.com"}, UPN: []string{"svc@example.com"}},
+			wantErr:  "SAN type ms_ntprincipalname is not allowed",
+		},
+		{
+			name:     "SANRegexMismatch",
+			template: enrollTestTemplate,
+			sans:     &SANs{DNS: []string{"www.example.org"}},
+			wantErr:  `dns SAN "www.example.org" must be in example.com`,
+		},
+		{
+			name:     "NoSANRegexesAllowsAllTypes",
+			template: GetTemplateResponse{CommonName: "User"},
+			sans:     &SANs{UPN: []string{"jdoe@example.com"}, Email: []string{"jdoe@example.com"}},
+		},
+		{
+			name:     "UnknownEnrollmentField",
+			template: enrollTestTemplate,
+			fields:   map[string]interface{}{"CostCenter": "42"},
+			wantErr:  `enrollment field "CostCenter" is not defined`,
+		},
+		{
+			name:     "InvalidMultipleChoice",
+			template: enrollTestTemplate,
+			fields:   map[string]interface{}{"Environment": "staging"},
+			wantErr:  `must be one of prod, test, got "staging"`,
+		},
+		{
+			name:     "KeyUsageNotGranted",
+			template: enrollTestTemplate,
+			hints:    &KeyUsageHints{KeyUsage: x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign},
+			wantErr:  "key usage flags 0x4",
+		},
+		{
+			name:     "ExtKeyUsageNotGranted",
+			template: enrollTestTemplate,
+			hints:    &KeyUsageHints{ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}},
+			wantErr:  "extended key usage 1.3.6.1.5.5.7.3.2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.template.ValidateEnrollment(tt.sans, tt.fields, tt.hints)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateEnrollment() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateEnrollment() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewCSR(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sans := &SANs{
+		DNS:   []string{"www.example.com"},
+		IP4:   []string{"10.0.0.5"},
+		IP6:   []string{"2001:db8::1"},
+		URI:   []string{"spiffe://example.com/web"},
+		UPN:   []string{"web@example.com"},
+		Email: []string{"webmaster@example.com"},
+	}
+	hints := &KeyUsageHints{
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageDecipherOnly,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		MustStaple:  true,
+	}
+
+	csrPEM, err := NewCSR(key, pkix.Name{CommonName: "www.example.com"}, sans, hints)
+	if err != nil {
+		t.Fatalf("NewCSR() error = %v", err)
+	}
+	block, _ := pem.Decode([]byte(csrPEM))
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		t.Fatalf("NewCSR() did not return a PEM certificate request")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatalf("ParseCertificateRequest() error = %v", err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		t.Errorf("CheckSignature() error = %v", err)
+	}
+
+	if len(csr.DNSNames) != 1 || csr.DNSNames[0] != "www.example.com" {
+		t.Errorf("DNSNames = %v", csr.DNSNames)
+	}
+	if len(csr.EmailAddresses) != 1 || csr.EmailAddresses[0] != "webmaster@example.com" {
+		t.Errorf("EmailAddresses = %v", csr.EmailAddresses)
+	}
+	if len(csr.IPAddresses) != 2 || csr.IPAddresses[0].String() != "10.0.0.5" || csr.IPAddresses[1].String() != "2001:db8::1" {
+		t.Errorf("IPAddresses = %v", csr.IPAddresses)
+	}
+	if len(csr.URIs) != 1 || csr.URIs[0].String() != "spiffe://example.com/web" {
+		t.Errorf("URIs = %v", csr.URIs)
+	}
+
+	found := map[string]bool{}
+	for _, ext := range csr.Extensions {
+		found[ext.Id.String()] = true
+		switch {
+		case ext.Id.Equal(oidExtensionSubjectAltName):
+			if !strings.Contains(string(ext.Value), "web@example.com") {
+				t.Errorf("subjectAltName extension does not contain the UPN")
+			}
+		case ext.Id.Equal(oidExtensionKeyUsage):
+			if !ext.Critical || len(ext.Value) != 5 || ext.Value[3] != 0x80 || ext.Value[4] != 0x80 {
+				t.Errorf("key usage extension = %x (critical %v)", ext.Value, ext.Critical)
+			}
+		}
+	}
+	for _, oid := range []string{"2.5.29.17", "2.5.29.15", "2.5.29.37", "1.3.6.1.5.5.7.1.24"} {
+		if !found[oid] {
+			t.Errorf("CSR is missing extension %s", oid)
+		}
+	}
+
+	if _, err := NewCSR(key, pkix.Name{}, &SANs{IP4: []string{"2001:db8::1"}}, nil); err == nil {
+		t.Errorf("NewCSR() with an IPv6 address in IP4 succeeded, want error")
+	}
+}
+
+func TestClient_EnrollCSR(t *testing.T) {
+	var body map[string]interface{}
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /KeyfactorAPI/Templates":
+			json.NewEncoder(w).Encode([]GetTemplateResponse{enrollTestTemplate})
+		case "POST /KeyfactorAPI/Enrollment/CSR":
+			body = nil
+			json.NewDecoder(r.Body).Decode(&body)
+			w.Write([]byte(`{"CertificateInformation": {"SerialNumber": "01", "Certificates": ["MIIB"]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	tests := []struct {
+		name       string
+		args       *EnrollCSRFctArgs
+		wantFields map[string]interface{}
+		wantSANs   map[string]interface{}
+		wantErr    bool
+	}{
+		{
+			name: "EnrollmentFieldsAndSANs",
+			args: &EnrollCSRFctArgs{
+				CSR:                        "csr",
+				Template:                   "WebServer",
+				CertificateAuthority:       `ca.example.com\Example CA`,
+				SANs:                       &SANs{DNS: []string{"www.example.com"}, Email: []string{"webmaster@example.com"}},
+				AdditionalEnrollmentFields: map[string]interface{}{"Ticket": "CHG0042"},
+			},
+			wantFields: map[string]interface{}{"Ticket": "CHG0042"},
+			wantSANs: map[string]interface{}{
+				"dns":    []interface{}{"www.example.com"},
+				"rfc822": []interface{}{"webmaster@example.com"},
+			},
+		},
+		{
+			name: "ValidatedAgainstTemplate",
+			args: &EnrollCSRFctArgs{
+				CSR:                        "csr",
+				Template:                   "webserver",
+				CertificateAuthority:       `ca.example.com\Example CA`,
+				SANs:                       &SANs{DNS: []string{"www.example.com"}},
+				AdditionalEnrollmentFields: map[string]interface{}{"Environment": "prod"},
+				ValidateTemplate:           true,
+			},
+			wantFields: map[string]interface{}{"Environment": "prod"},
+			wantSANs:   map[string]interface{}{"dns": []interface{}{"www.example.com"}},
+		},
+		{
+			name: "RejectedByTemplate",
+			args: &EnrollCSRFctArgs{
+				CSR:                  "csr",
+				Template:             "WebServer",
+				CertificateAuthority: `ca.example.com\Example CA`,
+				SANs:                 &SANs{URI: []string{"spiffe://example.com/web"}},
+				ValidateTemplate:     true,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body = nil
+			got, err := c.EnrollCSR(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EnrollCSR() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if body != nil {
+					t.Errorf("EnrollCSR() submitted an enrollment that failed template validation")
+				}
+				return
+			}
+			if got.CertificateInformation.SerialNumber != "01" {
+				t.Errorf("EnrollCSR() got = %+v", got)
+			}
+			gotFields, _ := json.Marshal(body["AdditionalEnrollmentFields"])
+			wantFields, _ := json.Marshal(tt.wantFields)
+			if string(gotFields) != string(wantFields) {
+				t.Errorf("AdditionalEnrollmentFields = %s, want %s", gotFields, wantFields)
+			}
+			gotSANs, _ := json.Marshal(body["SANs"])
+			wantSANs, _ := json.Marshal(tt.wantSANs)
+			if string(gotSANs) != string(wantSANs) {
+				t.Errorf("SANs = %s, want %s", gotSANs, wantSANs)
+			}
+		})
+	}
+}
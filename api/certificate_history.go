@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Keyfactor/keyfactor-go-client/query"
+)
+
+// CertificateEventType classifies an event in the history of a certificate.
+type CertificateEventType string
+
+// Event types of a CertificateEvent.
+const (
+	CertificateEventIssued   CertificateEventType = "issued"
+	CertificateEventRenewed  CertificateEventType = "renewed"
+	CertificateEventImported CertificateEventType = "imported"
+	CertificateEventRevoked  CertificateEventType = "revoked"
+	// CertificateEventDeployed and CertificateEventRemoved mark the certificate being added to and removed from a
+	// certificate store.
+	CertificateEventDeployed CertificateEventType = "deployed"
+	CertificateEventRemoved  CertificateEventType = "removed"
+	// CertificateEventOther is any other audited change, such as a metadata update.
+	CertificateEventOther CertificateEventType = "other"
+)
+
+// CertificateEvent is an entry of the audit log about a certificate, returned by GetCertificateHistory.
+type CertificateEvent struct {
+	Type      CertificateEventType
+	Timestamp Timestamp
+	User      string
+	Message   string
+	// Entry is the audit log entry the event was read from.
+	Entry AuditLogEntry
+}
+
+// GetCertificateHistory takes arguments for a certificate ID to facilitate calls to Keyfactor that return the
+// lifecycle of the certificate, oldest event first: its issuance or import, renewal, deployment to and removal from
+// certificate stores, revocation, and other audited changes. The events are read from the audit log entries that name
+// the certificate's thumbprint, and their Type is inferred from the entry's message, so events Keyfactor words
+// differently are reported as CertificateEventOther.
+func (c *Client) GetCertificateHistory(certId int) ([]CertificateEvent, error) {
+	return c.GetCertificateHistoryContext(context.Background(), certId)
+}
+
+// GetCertificateHistoryContext is like GetCertificateHistory but uses ctx for the requests, allowing it to be
+// cancelled.
+func (c *Client) GetCertificateHistoryContext(ctx context.Context, certId int) ([]CertificateEvent, error) {
+	c.infof("Getting history of certificate %d", certId)
+
+	if certId <= 0 {
+		return nil, errors.New("keyfactor certificate id is required to get certificate history")
+	}
+	cert, err := c.GetCertificateContextContext(ctx, &GetCertificateContextArgs{Id: certId})
+	if err != nil {
+		return nil, err
+	}
+	thumbprint := NormalizeThumbprint(cert.Thumbprint)
+	if thumbprint == "" {
+		return nil, fmt.Errorf("keyfactor returned no thumbprint for certificate %d", certId)
+	}
+
+	q, err := query.Or(
+		query.Field("AuditIdentifier").Eq(thumbprint),
+		query.Field("Message").Contains(thumbprint),
+	).Build()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := c.ListAllAuditLogsContext(ctx, q, &FetchAllOptions{SortField: "Timestamp", SortAscending: true})
+	if err != nil {
+		return nil, fmt.Errorf("unable to search audit log for certificate %d: %w", certId, err)
+	}
+
+	events := make([]CertificateEvent, 0, len(entries))
+	for _, entry := range entries {
+		events = append(events, CertificateEvent{
+			Type:      classifyCertificateEvent(entry),
+			Timestamp: entry.Timestamp,
+			User:      entry.User,
+			Message:   entry.Message,
+			Entry:     entry,
+		})
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Timestamp.Before(events[j].Timestamp.Time) })
+	return events, nil
+}
+
+// CertificateEventType returns the type of certificate event the audit log entry records, inferred from its message.
+// It is meaningful only for entries about a certificate, such as those of EntityType "Certificate".
+func (e *AuditLogEntry) CertificateEventType() CertificateEventType {
+	return classifyCertificateEvent(*e)
+}
+
+// classifyCertificateEvent infers the type of a certificate event from the wording of its audit log entry.
+func classifyCertificateEvent(entry AuditLogEntry) CertificateEventType {
+	msg := strings.ToLower(entry.Message)
+	store := strings.Contains(msg, "store") || strings.Contains(strings.ToLower(entry.EntityType), "store")
+	switch {
+	case strings.Contains(msg, "revok"):
+		return CertificateEventRevoked
+	case strings.Contains(msg, "renew"):
+		return CertificateEventRenewed
+	case store && strings.Contains(msg, "remov"):
+		return CertificateEventRemoved
+	case store && (strings.Contains(msg, "add") || strings.Contains(msg, "deploy")):
+		return CertificateEventDeployed
+	case strings.Contains(msg, "import"):
+		return CertificateEventImported
+	case strings.Contains(msg, "enroll") || strings.Contains(msg, "issued"):
+		return CertificateEventIssued
+	default:
+		return CertificateEventOther
+	}
+}
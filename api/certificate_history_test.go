@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestClient_GetCertificateHistory(t *testing.T) {
+	var auditQuery string
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/KeyfactorAPI/Certificates/42":
+			w.Write([]byte(`{"Id": 42, "Thumbprint": "ab12cd34", "IssuedCN": "app.example.com"}`))
+		case "/KeyfactorAPI/Audit":
+			auditQuery = r.URL.Query().Get("pq.queryString")
+			w.Write([]byte(`[
+				{"Id": 4, "Timestamp": "2024-03-01T09:00:00Z", "Message": "Certificate AB12CD34 was revoked", "User": "EXAMPLE\\pki", "EntityType": "Certificate"},
+				{"Id": 1, "Timestamp": "2024-01-01T09:00:00Z", "Message": "Certificate AB12CD34 enrolled via CSR", "User": "EXAMPLE\\app", "EntityType": "Certificate"},
+				{"Id": 2, "Timestamp": "2024-01-01T09:05:00Z", "Message": "Certificate AB12CD34 added to certificate store web01", "EntityType": "CertificateStore"},
+				{"Id": 3, "Timestamp": "2024-02-01T09:00:00Z", "Message": "Metadata of certificate AB12CD34 updated", "EntityType": "Certificate"}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	events, err := c.GetCertificateHistory(42)
+	if err != nil {
+		t.Fatalf("GetCertificateHistory() error = %v", err)
+	}
+	if want := `AuditIdentifier -eq "AB12CD34" OR Message -contains "AB12CD34"`; auditQuery != want {
+		t.Errorf("GetCertificateHistory() searched %q, want %q", auditQuery, want)
+	}
+	var got []CertificateEventType
+	for _, e := range events {
+		got = append(got, e.Type)
+	}
+	want := []CertificateEventType{CertificateEventIssued, CertificateEventDeployed, CertificateEventOther, CertificateEventRevoked}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetCertificateHistory() event types = %v, want %v", got, want)
+	}
+	if events[0].User != `EXAMPLE\app` || !events[0].Timestamp.Valid() {
+		t.Errorf("GetCertificateHistory() first event = %+v", events[0])
+	}
+
+	if _, err := c.GetCertificateHistory(0); err == nil {
+		t.Errorf("GetCertificateHistory(0) succeeded, want error")
+	}
+}
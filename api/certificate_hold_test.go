@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Keyfactor/keyfactor-go-client/enums"
+)
+
+func TestClient_HoldCertificates(t *testing.T) {
+	var revoked revokeCertificateBody
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/KeyfactorAPI/Certificates":
+			json.NewEncoder(w).Encode([]GetCertificateResponse{
+				{Id: 1, CertState: int(enums.CertificateStateActive)},
+				{Id: 2, CertState: int(enums.CertificateStateRevoked), RevocationReason: int(RevocationReasonCertificateHold)},
+				{Id: 3, CertState: int(enums.CertificateStateRevoked), RevocationReason: int(RevocationReasonKeyCompromise)},
+			})
+		case "/KeyfactorAPI/Certificates/Revoke":
+			json.NewDecoder(r.Body).Decode(&revoked)
+			json.NewEncoder(w).Encode(RevocationResponse{RevokedIds: revoked.CertificateIds})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	tests := []struct {
+		name       string
+		release    bool
+		args       *CertificateHoldArgs
+		wantReason RevocationReason
+		wantErr    bool
+	}{
+		{
+			name:       "HoldActive",
+			args:       &CertificateHoldArgs{CertificateIds: []int{1}, Comment: "suspected misuse"},
+			wantReason: RevocationReasonCertificateHold,
+		},
+		{
+			name:       "ReleaseHeld",
+			release:    true,
+			args:       &CertificateHoldArgs{CertificateIds: []int{2}, Comment: "investigation closed"},
+			wantReason: RevocationReasonRemoveFromHold,
+		},
+		{
+			name:    "HoldAlreadyHeld",
+			args:    &CertificateHoldArgs{CertificateIds: []int{1, 2}, Comment: "suspected misuse"},
+			wantErr: true,
+		},
+		{
+			name:    "ReleaseRevoked",
+			release: true,
+			args:    &CertificateHoldArgs{CertificateIds: []int{3}, Comment: "oops"},
+			wantErr: true,
+		},
+		{
+			name:    "NotFound",
+			args:    &CertificateHoldArgs{CertificateIds: []int{99}, Comment: "suspected misuse"},
+			wantErr: true,
+		},
+		{
+			name:    "MissingComment",
+			args:    &CertificateHoldArgs{CertificateIds: []int{1}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revoked = revokeCertificateBody{}
+			var got *RevocationResponse
+			var err error
+			if tt.release {
+				got, err = c.ReleaseCertificates(tt.args)
+			} else {
+				got, err = c.HoldCertificates(tt.args)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				if revoked.CertificateIds != nil {
+					t.Errorf("revoke request sent despite invalid transition")
+				}
+				return
+			}
+			if revoked.Reason != tt.wantReason {
+				t.Errorf("revocation reason = %d, want %d", revoked.Reason, tt.wantReason)
+			}
+			if len(got.RevokedIds) != len(tt.args.CertificateIds) {
+				t.Errorf("RevokedIds = %v, want %v", got.RevokedIds, tt.args.CertificateIds)
+			}
+		})
+	}
+}
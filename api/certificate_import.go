@@ -0,0 +1,43 @@
+package api
+
+import (
+	"errors"
+)
+
+// ImportCertificate takes arguments for ImportCertificateArgs to facilitate a call to Keyfactor that imports an
+// existing certificate, optionally with its private key as a PFX, into Keyfactor Command. Certificate must be the
+// base64 encoded DER certificate or PFX file.
+func (c *Client) ImportCertificate(args *ImportCertificateArgs) (*ImportCertificateResponse, error) {
+	c.infof("Importing certificate into Keyfactor")
+
+	if args == nil || args.Certificate == "" {
+		return nil, errors.New("certificate contents are required to import a certificate")
+	}
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "POST",
+		Endpoint: "Certificates/Import",
+		Headers:  headers,
+		Payload:  args,
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonResp := &ImportCertificateResponse{}
+	err = c.decodeResponse(resp.Body, jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
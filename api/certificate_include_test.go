@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestClient_CertificateIncludeFlags(t *testing.T) {
+	var lastQuery url.Values
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		lastQuery = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		cert := `{"Id": 1, "Metadata": {"Owner": "pki", "Cost": "12"}, "SubjectAltNameElements": [{"Id": 1, "Value": "www.example.com", "Type": 2}], "Locations": [{"StoreMachine": "web01", "StorePath": "My", "CertStoreId": "s1"}]}`
+		if r.URL.Path == "/KeyfactorAPI/Certificates" {
+			cert = "[" + cert + "]"
+		}
+		w.Write([]byte(cert))
+	})
+	yes := true
+	verbose := VerboseSANs
+
+	tests := []struct {
+		name      string
+		call      func() (*GetCertificateResponse, error)
+		wantQuery map[string]string
+	}{
+		{
+			name:      "Get",
+			wantQuery: map[string]string{"includeMetadata": "true", "includeLocations": "true", "verbose": "1"},
+			call: func() (*GetCertificateResponse, error) {
+				return c.GetCertificateContext(&GetCertificateContextArgs{Id: 1, IncludeMetadata: &yes, IncludeLocations: &yes, Verbose: &verbose})
+			},
+		},
+		{
+			name:      "GetDefaults",
+			wantQuery: map[string]string{"includeMetadata": "", "includeLocations": "", "verbose": ""},
+			call: func() (*GetCertificateResponse, error) {
+				return c.GetCertificateContext(&GetCertificateContextArgs{Id: 1})
+			},
+		},
+		{
+			name:      "List",
+			wantQuery: map[string]string{"includeMetadata": "true", "includeLocations": "false", "includeHasPrivateKey": "true", "verbose": "2"},
+			call: func() (*GetCertificateResponse, error) {
+				certs, err := c.ListCertificatesContext(context.Background(), map[string]string{"includeMetadata": "true", "includeLocations": "false", "includeHasPrivateKey": "true", "verbose": "2"})
+				if err != nil {
+					return nil, err
+				}
+				return &certs[0], nil
+			},
+		},
+		{
+			name:      "Search",
+			wantQuery: map[string]string{"includeMetadata": "true", "includeLocations": "true", "includeHasPrivateKey": "true", "verbose": "1"},
+			call: func() (*GetCertificateResponse, error) {
+				certs, err := c.searchAllCertificates("", &SearchCertificatesOptions{PageSize: 10, IncludeMetadata: true, IncludeLocations: true, IncludeHasPrivateKey: true, Verbose: VerboseSANs})
+				if err != nil {
+					return nil, err
+				}
+				return &certs[0], nil
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert, err := tt.call()
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			for k, v := range tt.wantQuery {
+				if got := lastQuery.Get(k); got != v {
+					t.Errorf("query %s = %q, want %q", k, got, v)
+				}
+			}
+			if len(cert.SubjectAltNameElements) != 1 || cert.SubjectAltNameElements[0].Value != "www.example.com" {
+				t.Errorf("SubjectAltNameElements = %+v", cert.SubjectAltNameElements)
+			}
+			if len(cert.Locations) != 1 || cert.Locations[0].CertStoreId != "s1" {
+				t.Errorf("Locations = %+v", cert.Locations)
+			}
+			md := cert.MetadataValues()
+			if md["Owner"] != "pki" || md["Cost"] != "12" {
+				t.Errorf("MetadataValues() = %v", md)
+			}
+		})
+	}
+}
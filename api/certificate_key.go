@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"sort"
+
+	"github.com/Keyfactor/keyfactor-go-client/enums"
+)
+
+// namedCurves maps the size of a NIST curve to its name, for ECC keys whose certificate content is not available.
+var namedCurves = map[int]string{
+	256: "P-256",
+	384: "P-384",
+	521: "P-521",
+}
+
+// KeyInfo returns the algorithm, size, and curve of the certificate's public key. They are read from the certificate
+// itself when ContentBytes holds it, and otherwise from KeyType and KeySizeInBits, in which case the curve of an ECC
+// key is inferred from its size for the NIST curves P-256, P-384, and P-521.
+func (r *GetCertificateResponse) KeyInfo() CertificateKeyInfo {
+	info := CertificateKeyInfo{Algorithm: enums.KeyType(r.KeyType), Size: r.KeySizeInBits}
+	if cert := r.parsedContent(); cert != nil {
+		switch key := cert.PublicKey.(type) {
+		case *rsa.PublicKey:
+			return CertificateKeyInfo{Algorithm: enums.KeyTypeRSA, Size: key.N.BitLen()}
+		case *ecdsa.PublicKey:
+			params := key.Curve.Params()
+			return CertificateKeyInfo{Algorithm: enums.KeyTypeECC, Size: params.BitSize, Curve: params.Name}
+		case ed25519.PublicKey:
+			return CertificateKeyInfo{Algorithm: enums.KeyTypeEd25519, Size: 256}
+		}
+	}
+	if info.Algorithm == enums.KeyTypeECC {
+		info.Curve = namedCurves[info.Size]
+	}
+	return info
+}
+
+// parsedContent returns the certificate in ContentBytes, or nil if it is empty or cannot be parsed.
+func (r *GetCertificateResponse) parsedContent() *x509.Certificate {
+	if r.ContentBytes == "" {
+		return nil
+	}
+	der, err := base64.StdEncoding.DecodeString(r.ContentBytes)
+	if err != nil {
+		return nil
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil
+	}
+	return cert
+}
+
+// ReportKeyStrength takes arguments for a query string, such as one built with the query package, to facilitate a
+// series of calls to Keyfactor that walk every matching certificate and count them by the algorithm, size, and curve
+// of their keys, e.g. to find the certificates still using 2048-bit RSA keys. opts may be nil.
+func (c *Client) ReportKeyStrength(q string, opts *SearchCertificatesOptions) (*KeyStrengthReport, error) {
+	return c.ReportKeyStrengthContext(context.Background(), q, opts)
+}
+
+// ReportKeyStrengthContext is like ReportKeyStrength but uses ctx for the requests, allowing it to be cancelled.
+func (c *Client) ReportKeyStrengthContext(ctx context.Context, q string, opts *SearchCertificatesOptions) (*KeyStrengthReport, error) {
+	report := &KeyStrengthReport{}
+	groups := map[CertificateKeyInfo]*KeyStrengthGroup{}
+	err := c.SearchCertificatePagesContext(ctx, q, opts, func(page []GetCertificateResponse) error {
+		for i := range page {
+			info := page[i].KeyInfo()
+			group, ok := groups[info]
+			if !ok {
+				group = &KeyStrengthGroup{CertificateKeyInfo: info}
+				groups[info] = group
+			}
+			group.Count++
+			group.CertificateIds = append(group.CertificateIds, page[i].Id)
+			report.Total++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		report.Groups = append(report.Groups, *group)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if a.Algorithm != b.Algorithm {
+			return a.Algorithm < b.Algorithm
+		}
+		if a.Size != b.Size {
+			return a.Size < b.Size
+		}
+		return a.Curve < b.Curve
+	})
+	return report, nil
+}
+
+// Below returns the groups of the report whose keys use algorithm with fewer than minSize bits, such as RSA keys
+// below 3072 bits.
+func (r *KeyStrengthReport) Below(algorithm enums.KeyType, minSize int) []KeyStrengthGroup {
+	var weak []KeyStrengthGroup
+	for _, group := range r.Groups {
+		if group.Algorithm == algorithm && group.Size < minSize {
+			weak = append(weak, group)
+		}
+	}
+	return weak
+}
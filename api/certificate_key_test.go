@@ -0,0 +1,122 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Keyfactor/keyfactor-go-client/enums"
+)
+
+// newRSATestCert returns the base64 DER of a self-signed certificate with an RSA key of bits.
+func newRSATestCert(t *testing.T, bits int) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "rsa.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(der)
+}
+
+func TestGetCertificateResponse_KeyInfo(t *testing.T) {
+	ecCert, _ := newTestCert(t, "ec.example.com", false, nil, nil)
+	rsaContent := newRSATestCert(t, 2048)
+
+	tests := []struct {
+		name string
+		cert GetCertificateResponse
+		want CertificateKeyInfo
+	}{
+		{
+			name: "RSAFromContent",
+			// Keyfactor's fields are overridden by the certificate content.
+			cert: GetCertificateResponse{KeyType: int(enums.KeyTypeRSA), KeySizeInBits: 4096, ContentBytes: rsaContent},
+			want: CertificateKeyInfo{Algorithm: enums.KeyTypeRSA, Size: 2048},
+		},
+		{
+			name: "ECCFromContent",
+			cert: GetCertificateResponse{ContentBytes: base64.StdEncoding.EncodeToString(ecCert.Raw)},
+			want: CertificateKeyInfo{Algorithm: enums.KeyTypeECC, Size: 256, Curve: "P-256"},
+		},
+		{
+			name: "ECCFromFields",
+			cert: GetCertificateResponse{KeyType: int(enums.KeyTypeECC), KeySizeInBits: 384},
+			want: CertificateKeyInfo{Algorithm: enums.KeyTypeECC, Size: 384, Curve: "P-384"},
+		},
+		{
+			name: "InvalidContent",
+			cert: GetCertificateResponse{KeyType: int(enums.KeyTypeRSA), KeySizeInBits: 3072, ContentBytes: "bm90IGEgY2VydA=="},
+			want: CertificateKeyInfo{Algorithm: enums.KeyTypeRSA, Size: 3072},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cert.KeyInfo(); got != tt.want {
+				t.Errorf("KeyInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_ReportKeyStrength(t *testing.T) {
+	certs := []GetCertificateResponse{
+		{Id: 1, KeyType: int(enums.KeyTypeRSA), KeySizeInBits: 2048},
+		{Id: 2, KeyType: int(enums.KeyTypeECC), KeySizeInBits: 256},
+		{Id: 3, KeyType: int(enums.KeyTypeRSA), KeySizeInBits: 4096},
+		{Id: 4, KeyType: int(enums.KeyTypeRSA), KeySizeInBits: 2048},
+		{Id: 5, KeyType: int(enums.KeyTypeRSA), KeySizeInBits: 1024},
+	}
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("pq.pageReturned"))
+		var resp []GetCertificateResponse
+		for i := (page - 1) * 2; i < page*2 && i < len(certs); i++ {
+			resp = append(resp, certs[i])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	report, err := c.ReportKeyStrength(`IssuerDN -contains "Corp"`, &SearchCertificatesOptions{PageSize: 2})
+	if err != nil {
+		t.Fatalf("ReportKeyStrength() error = %v", err)
+	}
+	want := &KeyStrengthReport{
+		Total: 5,
+		Groups: []KeyStrengthGroup{
+			{CertificateKeyInfo: CertificateKeyInfo{Algorithm: enums.KeyTypeRSA, Size: 1024}, Count: 1, CertificateIds: []int{5}},
+			{CertificateKeyInfo: CertificateKeyInfo{Algorithm: enums.KeyTypeRSA, Size: 2048}, Count: 2, CertificateIds: []int{1, 4}},
+			{CertificateKeyInfo: CertificateKeyInfo{Algorithm: enums.KeyTypeRSA, Size: 4096}, Count: 1, CertificateIds: []int{3}},
+			{CertificateKeyInfo: CertificateKeyInfo{Algorithm: enums.KeyTypeECC, Size: 256, Curve: "P-256"}, Count: 1, CertificateIds: []int{2}},
+		},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("ReportKeyStrength() = %+v, want %+v", report, want)
+	}
+
+	weak := report.Below(enums.KeyTypeRSA, 3072)
+	if len(weak) != 2 || weak[0].Size != 1024 || weak[1].Size != 2048 {
+		t.Errorf("Below(RSA, 3072) = %+v", weak)
+	}
+	if got := report.Below(enums.KeyTypeECC, 256); len(got) != 0 {
+		t.Errorf("Below(ECC, 256) = %+v, want none", got)
+	}
+}
@@ -1,11 +1,31 @@
 package api
 
-// SANs holds arrays of strings associated with IPv4 (IP4), IPv6 (IP6), DNS, and URI SANs.
+import (
+	"crypto/x509"
+
+	"github.com/Keyfactor/keyfactor-go-client/enums"
+)
+
+// SANs holds arrays of strings associated with IPv4 (IP4), IPv6 (IP6), DNS, URI, user principal name (UPN), and email
+// (RFC 822) SANs. The JSON tags are the SAN type flags Keyfactor expects in enrollment requests.
 type SANs struct {
-	IP4 []string `json:"ip4,omitempty"`
-	IP6 []string `json:"ip6,omitempty"`
-	DNS []string `json:"dns,omitempty"`
-	URI []string `json:"uri,omitempty"`
+	IP4   []string `json:"ip4,omitempty"`
+	IP6   []string `json:"ip6,omitempty"`
+	DNS   []string `json:"dns,omitempty"`
+	URI   []string `json:"uri,omitempty"`
+	UPN   []string `json:"ms_ntprincipalname,omitempty"`
+	Email []string `json:"rfc822,omitempty"`
+}
+
+// KeyUsageHints describes the key usages a caller expects on an enrolled certificate. For PFX enrollments the issued
+// key usages are dictated by the template, so the hints are only checked against it when ValidateTemplate is set. For
+// CSR enrollments, NewCSR writes the hints into the request as extensions.
+type KeyUsageHints struct {
+	KeyUsage    x509.KeyUsage
+	ExtKeyUsage []x509.ExtKeyUsage
+	// MustStaple requests the TLS feature (status_request) extension defined in RFC 7633. It is only honoured by
+	// NewCSR, as the key for a PFX enrollment is generated by Keyfactor.
+	MustStaple bool
 }
 
 // EnrollPFXFctArgs holds the function arguments used for calling the EnrollPFX method.
@@ -28,6 +48,14 @@ type EnrollPFXFctArgs struct {
 	SANs                 *SANs                  `json:"SANs,omitempty"`
 	Metadata             map[string]interface{} `json:"Metadata,omitempty"`
 	CertFormat           string                 `json:"-"`
+
+	// AdditionalEnrollmentFields holds values for the custom enrollment fields defined on the template, keyed by
+	// field name.
+	AdditionalEnrollmentFields map[string]interface{} `json:"-"`
+	KeyUsage                   *KeyUsageHints         `json:"-"`
+	// ValidateTemplate looks up Template before submitting the enrollment and checks the SANs, enrollment fields,
+	// and key usage hints against it, so policy violations are reported without a round trip to the CA.
+	ValidateTemplate bool `json:"-"`
 }
 
 // EnrollCSRFctArgs holds the function arguments used for calling the EnrollCSR method.
@@ -40,6 +68,15 @@ type EnrollCSRFctArgs struct {
 	IncludeChain         bool                   `json:"IncludeChain"`
 	SANs                 *SANs                  `json:"SANs"`
 	Metadata             map[string]interface{} `json:"Metadata"`
+
+	// AdditionalEnrollmentFields holds values for the custom enrollment fields defined on the template, keyed by
+	// field name.
+	AdditionalEnrollmentFields map[string]interface{} `json:"-"`
+	// KeyUsage should match the hints the CSR was built with; it is only used when ValidateTemplate is set.
+	KeyUsage *KeyUsageHints `json:"-"`
+	// ValidateTemplate looks up Template before submitting the enrollment and checks the SANs, enrollment fields,
+	// and key usage hints against it.
+	ValidateTemplate bool `json:"-"`
 }
 
 // RevokeCertArgs holds the function arguments used for calling the RevokeCert method.
@@ -58,8 +95,22 @@ type GetCertificateContextArgs struct {
 	CollectionId     *int   // Query
 	Thumbprint       string // Query
 	Id               int    // Query
+	// Verbose is the Keyfactor verbosity level of the response, e.g. VerboseSANs to include subject alternative names.
+	// Keyfactor's default is used when nil.
+	Verbose *int // Query
 }
 
+// Keyfactor verbosity levels for certificate retrieval. Higher levels return more of each certificate, saving a
+// follow-up call per certificate at the cost of a larger response.
+const (
+	// VerboseMinimal returns the summary fields of a certificate.
+	VerboseMinimal = 0
+	// VerboseSANs adds SubjectAltNameElements, CRLDistributionPoints, and DetailedKeyUsage.
+	VerboseSANs = 1
+	// VerboseFull adds every detail Keyfactor keeps for the certificate, including extended key usages.
+	VerboseFull = 2
+)
+
 // DeployPFXArgs holds the function arguments used for calling the DeployPFXCertificate method.
 type DeployPFXArgs struct {
 	StoreIds      []string     `json:"StoreIds"`
@@ -104,13 +155,13 @@ type CertificateSubject struct {
 	SubjectState              string
 }
 
-// downloadCertificateBody is the API POST request body for PFX enrollment (DownloadCertificate).
+// downloadCertificateBody is the API POST request body for /Certificates/Download.
 type downloadCertificateBody struct {
-	CertID       int
-	SerialNumber string
-	IssuerDN     string
-	Thumbprint   string
-	IncludeChain bool
+	CertID       int    `json:"CertID,omitempty"`
+	SerialNumber string `json:"SerialNumber,omitempty"`
+	IssuerDN     string `json:"IssuerDN,omitempty"`
+	Thumbprint   string `json:"Thumbprint,omitempty"`
+	IncludeChain bool   `json:"IncludeChain"`
 }
 
 // EnrollResponse is the outer certificate enrollment response. When Enroll functions are called, the certificates are
@@ -136,38 +187,38 @@ type CertificateInformation struct {
 
 // GetCertificateResponse contains the response elements returned from the GetCertificateContext method.
 type GetCertificateResponse struct {
-	Id                       int    `json:"Id"`
-	Thumbprint               string `json:"Thumbprint"`
-	SerialNumber             string `json:"SerialNumber"`
-	IssuedDN                 string `json:"IssuedDN"`
-	IssuedCN                 string `json:"IssuedCN"`
-	ImportDate               string `json:"ImportDate"`
-	NotBefore                string `json:"NotBefore"`
-	NotAfter                 string `json:"NotAfter"`
-	IssuerDN                 string `json:"IssuerDN"`
-	PrincipalId              string `json:"PrincipalId"`
-	TemplateId               int    `json:"TemplateId"`
-	CertState                int    `json:"CertState"`
-	KeySizeInBits            int    `json:"KeySizeInBits"`
-	KeyType                  int    `json:"KeyType"`
-	RequesterId              int    `json:"RequesterId"`
-	IssuedOU                 string `json:"IssuedOU"`
-	KeyUsage                 int    `json:"KeyUsage"`
-	SigningAlgorithm         string `json:"SigningAlgorithm"`
-	CertStateString          string `json:"CertStateString"`
-	KeyTypeString            string `json:"KeyTypeString"`
-	RevocationEffDate        string `json:"RevocationEffDate"`
-	RevocationReason         int    `json:"RevocationReason"`
-	RevocationComment        string `json:"RevocationComment"`
-	CertificateAuthorityId   int    `json:"CertificateAuthorityId"`
-	CertificateAuthorityName string `json:"CertificateAuthorityName"`
-	TemplateName             string `json:"TemplateName"`
-	ArchivedKey              bool   `json:"ArchivedKey"`
-	HasPrivateKey            bool   `json:"HasPrivateKey"`
-	PrincipalName            string `json:"PrincipalName"`
-	CertRequestId            int    `json:"CertRequestId"`
-	RequesterName            string `json:"RequesterName"`
-	ContentBytes             string `json:"ContentBytes"`
+	Id                       int       `json:"Id"`
+	Thumbprint               string    `json:"Thumbprint"`
+	SerialNumber             string    `json:"SerialNumber"`
+	IssuedDN                 string    `json:"IssuedDN"`
+	IssuedCN                 string    `json:"IssuedCN"`
+	ImportDate               Timestamp `json:"ImportDate"`
+	NotBefore                Timestamp `json:"NotBefore"`
+	NotAfter                 Timestamp `json:"NotAfter"`
+	IssuerDN                 string    `json:"IssuerDN"`
+	PrincipalId              string    `json:"PrincipalId"`
+	TemplateId               int       `json:"TemplateId"`
+	CertState                int       `json:"CertState"`
+	KeySizeInBits            int       `json:"KeySizeInBits"`
+	KeyType                  int       `json:"KeyType"`
+	RequesterId              int       `json:"RequesterId"`
+	IssuedOU                 string    `json:"IssuedOU"`
+	KeyUsage                 int       `json:"KeyUsage"`
+	SigningAlgorithm         string    `json:"SigningAlgorithm"`
+	CertStateString          string    `json:"CertStateString"`
+	KeyTypeString            string    `json:"KeyTypeString"`
+	RevocationEffDate        Timestamp `json:"RevocationEffDate"`
+	RevocationReason         int       `json:"RevocationReason"`
+	RevocationComment        string    `json:"RevocationComment"`
+	CertificateAuthorityId   int       `json:"CertificateAuthorityId"`
+	CertificateAuthorityName string    `json:"CertificateAuthorityName"`
+	TemplateName             string    `json:"TemplateName"`
+	ArchivedKey              bool      `json:"ArchivedKey"`
+	HasPrivateKey            bool      `json:"HasPrivateKey"`
+	PrincipalName            string    `json:"PrincipalName"`
+	CertRequestId            int       `json:"CertRequestId"`
+	RequesterName            string    `json:"RequesterName"`
+	ContentBytes             string    `json:"ContentBytes"`
 	ExtendedKeyUsages        []interface{}
 	SubjectAltNameElements   []SubjectAltNameElements `json:"SubjectAltNameElements"`
 	CRLDistributionPoints    []CRLDistributionPoints  `json:"CRLDistributionPoints"`
@@ -179,6 +230,9 @@ type GetCertificateResponse struct {
 	CARowIndex               int                      `json:"CARowIndex"`
 	DetailedKeyUsage         []DetailedKeyUsage       `json:"detailed_key_usage"`
 	KeyRecoverable           bool                     `json:"KeyRecoverable"`
+	// OwnerRoleId and OwnerRoleName identify the security role that owns the certificate, if any.
+	OwnerRoleId   int    `json:"OwnerRoleId,omitempty"`
+	OwnerRoleName string `json:"OwnerRoleName,omitempty"`
 }
 
 type ListCertificateResponse struct {
@@ -240,7 +294,8 @@ type CRLDistributionPoints struct {
 }
 
 // SubjectAltNameElements contains detailed information on the SANs attached to a certificate, and is returned inside
-// the GetCertificateContext method
+// the GetCertificateContext method. Type is one of the SANElement constants; use GetCertificateResponse.SubjectAltNames to
+// parse them.
 type SubjectAltNameElements struct {
 	Id        int    `json:"Id"`
 	Value     string `json:"Value"`
@@ -252,3 +307,185 @@ type SubjectAltNameElements struct {
 type downloadCertificateResponse struct {
 	Content string `json:"Content"`
 }
+
+// RevocationReason is a revocation reason code accepted by the Keyfactor revoke endpoint.
+type RevocationReason = enums.RevocationReason
+
+// Revocation reason codes understood by Keyfactor. RevocationReasonCertificateHold and
+// RevocationReasonRemoveFromHold are only honoured by CAs that support certificate suspension.
+const (
+	RevocationReasonRemoveFromHold       = enums.RevocationReasonRemoveFromHold
+	RevocationReasonUnspecified          = enums.RevocationReasonUnspecified
+	RevocationReasonKeyCompromise        = enums.RevocationReasonKeyCompromise
+	RevocationReasonCACompromise         = enums.RevocationReasonCACompromise
+	RevocationReasonAffiliationChanged   = enums.RevocationReasonAffiliationChanged
+	RevocationReasonSuperseded           = enums.RevocationReasonSuperseded
+	RevocationReasonCessationOfOperation = enums.RevocationReasonCessationOfOperation
+	RevocationReasonCertificateHold      = enums.RevocationReasonCertificateHold
+	RevocationReasonRemoveFromCRL        = enums.RevocationReasonRemoveFromCRL
+	RevocationReasonUnknown              = enums.RevocationReasonUnknown
+)
+
+// CertificateHoldState describes where a certificate sits in the hold lifecycle.
+type CertificateHoldState int
+
+const (
+	// HoldStateUnknown is a certificate that is neither active nor revoked, such as a pending or denied request.
+	HoldStateUnknown CertificateHoldState = iota
+	// HoldStateActive is an issued certificate that may be placed on hold.
+	HoldStateActive
+	// HoldStateOnHold is a certificate revoked with reason RevocationReasonCertificateHold, which may be released.
+	HoldStateOnHold
+	// HoldStateRevoked is a certificate permanently revoked for any other reason.
+	HoldStateRevoked
+)
+
+// CertificateHoldArgs holds the function arguments used for calling the HoldCertificates and ReleaseCertificates
+// methods.
+type CertificateHoldArgs struct {
+	CertificateIds []int
+	Comment        string
+	CollectionId   int
+}
+
+// RevocationResponse contains the response elements returned by the Keyfactor revoke endpoint. Certificates whose
+// revocation is waiting on a workflow are listed in SuspendedCerts rather than RevokedIds.
+type RevocationResponse struct {
+	RevokedIds     []int                 `json:"RevokedIds"`
+	SuspendedCerts []SuspendedRevocation `json:"SuspendedCerts"`
+}
+
+// SuspendedRevocation describes a revocation request held by a Keyfactor workflow.
+type SuspendedRevocation struct {
+	CertId     int    `json:"CertId"`
+	WorkflowId string `json:"WorkflowId"`
+	Message    string `json:"Message"`
+}
+
+// revokeCertificateBody is the request body sent to the Keyfactor revoke endpoint.
+type revokeCertificateBody struct {
+	CertificateIds []int            `json:"CertificateIds"`
+	Reason         RevocationReason `json:"Reason"`
+	Comment        string           `json:"Comment"`
+	EffectiveDate  string           `json:"EffectiveDate"`
+	CollectionId   int              `json:"CollectionId,omitempty"`
+}
+
+// RevokeByQueryOptions holds optional settings for the RevokeCertificatesByQuery method.
+type RevokeByQueryOptions struct {
+	// BatchSize is the number of certificates sent in each revoke request. Defaults to DefaultRevokeBatchSize.
+	BatchSize int
+	// CollectionId scopes both the search and the revocation to a certificate collection when greater than zero.
+	CollectionId int
+	// Progress, if set, is called once the matching certificates are found, after each batch with the number of
+	// certificates revoked and failed so far, and when the run is done.
+	Progress ProgressFunc
+}
+
+// RevokeByQueryResult summarizes a RevokeCertificatesByQuery run. For a dry run only Matched is populated.
+type RevokeByQueryResult struct {
+	DryRun         bool
+	Matched        []GetCertificateResponse
+	RevokedIds     []int
+	SuspendedCerts []SuspendedRevocation
+	// FailedIds lists certificates whose batch was rejected by Keyfactor.
+	FailedIds []int
+}
+
+// SearchCertificatesOptions holds optional settings for the SearchCertificatePages method.
+type SearchCertificatesOptions struct {
+	// PageSize is the number of certificates requested per page. Defaults to 500.
+	PageSize int
+	// CollectionId scopes the search to a certificate collection when greater than zero.
+	CollectionId int
+	// IncludeRevoked and IncludeExpired add revoked and expired certificates to the results.
+	IncludeRevoked bool
+	IncludeExpired bool
+	// IncludeMetadata returns each certificate's metadata fields.
+	IncludeMetadata bool
+	// IncludeLocations returns the certificate stores each certificate is in, and IncludeHasPrivateKey sets
+	// HasPrivateKey on each result.
+	IncludeLocations     bool
+	IncludeHasPrivateKey bool
+	// Verbose is the Keyfactor verbosity level of each result; VerboseSANs or higher includes subject alternative names.
+	Verbose int
+	// OwnerRoleId and OwnerRoleName restrict the search to certificates owned by the given security role.
+	OwnerRoleId   int
+	OwnerRoleName string
+	// SortField is the field to sort certificates by, e.g. "ImportDate". Defaults to "Id". Certificates are sorted in
+	// descending order unless SortAscending is set.
+	SortField     string
+	SortAscending bool
+}
+
+// ImportCertificateArgs holds the function arguments used for calling the ImportCertificate method.
+type ImportCertificateArgs struct {
+	// Certificate is the base64 encoded DER certificate or PFX file.
+	Certificate string `json:"Certificate"`
+	// Password protects the PFX, if Certificate is one.
+	Password string `json:"Password,omitempty"`
+	// Metadata is set on the imported certificate.
+	Metadata map[string]string `json:"Metadata,omitempty"`
+	// StoreIds associates the certificate with existing certificate stores.
+	StoreIds []string `json:"StoreIds,omitempty"`
+	// ImportMetadata validates and imports Metadata. It must be true for Metadata to be applied.
+	ImportMetadata bool `json:"ImportMetadata,omitempty"`
+}
+
+// ImportCertificateResponse contains the response elements returned from the ImportCertificate method.
+type ImportCertificateResponse struct {
+	ImportStatus     int               `json:"ImportStatus"`
+	JobStatus        int               `json:"JobStatus"`
+	InvalidKeystores []InvalidKeystore `json:"InvalidKeystores"`
+	Thumbprint       string            `json:"Thumbprint"`
+}
+
+// InvalidKeystore identifies a certificate store the imported certificate could not be associated with.
+type InvalidKeystore struct {
+	KeystoreId    string `json:"KeystoreId"`
+	ClientMachine string `json:"ClientMachine"`
+	StorePath     string `json:"StorePath"`
+	Alias         string `json:"Alias"`
+	Reason        int    `json:"Reason"`
+	Explanation   string `json:"Explanation"`
+}
+
+// CertificateOwner identifies the security role that owns a certificate. When setting an owner, either RoleId or
+// RoleName identifies the role.
+type CertificateOwner struct {
+	RoleId   int
+	RoleName string
+}
+
+// setCertificateOwnerBody is the request body for /Certificates/{id}/Owner.
+type setCertificateOwnerBody struct {
+	NewRoleId   int    `json:"NewRoleId,omitempty"`
+	NewRoleName string `json:"NewRoleName,omitempty"`
+}
+
+// CertificateKeyInfo describes the public key of a certificate.
+type CertificateKeyInfo struct {
+	// Algorithm is the public key algorithm, e.g. enums.KeyTypeRSA.
+	Algorithm enums.KeyType
+	// Size is the key size in bits, which for elliptic curve keys is the size of the curve.
+	Size int
+	// Curve names the elliptic curve of an ECC key, e.g. "P-256", and is empty for other algorithms or when the curve
+	// is not known.
+	Curve string
+}
+
+// KeyStrengthReport counts the certificates matching a search by the algorithm, size, and curve of their keys, and is
+// returned by the ReportKeyStrength method.
+type KeyStrengthReport struct {
+	// Total is the number of certificates that matched the search.
+	Total int
+	// Groups holds one entry per distinct key, ordered by algorithm, then size, then curve.
+	Groups []KeyStrengthGroup
+}
+
+// KeyStrengthGroup is the set of certificates in a KeyStrengthReport whose keys share an algorithm, size, and curve.
+type KeyStrengthGroup struct {
+	CertificateKeyInfo
+	Count          int
+	CertificateIds []int
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestClient_CertificateOwner(t *testing.T) {
+	var setBody map[string]interface{}
+	var setCollection, searchQuery string
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /KeyfactorAPI/Certificates/1":
+			w.Write([]byte(`{"Id": 1, "OwnerRoleId": 7, "OwnerRoleName": "WebTeam"}`))
+		case "GET /KeyfactorAPI/Certificates/2":
+			w.Write([]byte(`{"Id": 2}`))
+		case "PUT /KeyfactorAPI/Certificates/1/Owner":
+			setCollection = r.URL.Query().Get("collectionId")
+			json.NewDecoder(r.Body).Decode(&setBody)
+			w.WriteHeader(http.StatusNoContent)
+		case "GET /KeyfactorAPI/Certificates":
+			searchQuery = r.URL.Query().Get("pq.queryString")
+			w.Write([]byte(`[{"Id": 1, "OwnerRoleName": "WebTeam"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	owner, err := c.GetCertificateOwner(1, 0)
+	if err != nil || owner == nil || owner.RoleId != 7 || owner.RoleName != "WebTeam" {
+		t.Errorf("GetCertificateOwner(1) = %+v, %v", owner, err)
+	}
+	if owner, err := c.GetCertificateOwner(2, 0); err != nil || owner != nil {
+		t.Errorf("GetCertificateOwner(2) = %+v, %v, want nil, nil", owner, err)
+	}
+
+	setTests := []struct {
+		name     string
+		owner    *CertificateOwner
+		wantBody map[string]interface{}
+		wantErr  bool
+	}{
+		{name: "ById", owner: &CertificateOwner{RoleId: 7, RoleName: "ignored"}, wantBody: map[string]interface{}{"NewRoleId": float64(7)}},
+		{name: "ByName", owner: &CertificateOwner{RoleName: "WebTeam"}, wantBody: map[string]interface{}{"NewRoleName": "WebTeam"}},
+		{name: "NoRole", owner: &CertificateOwner{}, wantErr: true},
+		{name: "Nil", wantErr: true},
+	}
+	for _, tt := range setTests {
+		t.Run(tt.name, func(t *testing.T) {
+			setBody = nil
+			err := c.SetCertificateOwner(1, tt.owner, 3)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetCertificateOwner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(setBody) != len(tt.wantBody) {
+				t.Errorf("SetCertificateOwner() body = %v, want %v", setBody, tt.wantBody)
+			}
+			for k, v := range tt.wantBody {
+				if setBody[k] != v {
+					t.Errorf("SetCertificateOwner() body = %v, want %v", setBody, tt.wantBody)
+				}
+			}
+			if setCollection != "3" {
+				t.Errorf("SetCertificateOwner() collectionId = %q, want 3", setCollection)
+			}
+		})
+	}
+
+	certs, err := c.ListCertificatesByOwner(0, "WebTeam", nil)
+	if err != nil || len(certs) != 1 {
+		t.Fatalf("ListCertificatesByOwner() = %v, %v", certs, err)
+	}
+	if searchQuery != `OwnerRoleName -eq "WebTeam"` {
+		t.Errorf("ListCertificatesByOwner() query = %q", searchQuery)
+	}
+
+	err = c.SearchCertificatePages(`IssuedCN -contains "example"`, &SearchCertificatesOptions{OwnerRoleId: 7}, func([]GetCertificateResponse) error { return nil })
+	if err != nil {
+		t.Fatalf("SearchCertificatePages() error = %v", err)
+	}
+	if searchQuery != `(IssuedCN -contains "example") AND OwnerRoleId -eq 7` {
+		t.Errorf("SearchCertificatePages() query = %q", searchQuery)
+	}
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultRevokeBatchSize is the number of certificates revoked per request by RevokeCertificatesByQuery.
+const DefaultRevokeBatchSize = 100
+
+// RevokeCertificatesByQuery searches Keyfactor for active certificates matching a query string, such as one built
+// with the query package, and revokes them in batches with the supplied reason and comment. When dryRun is true
+// nothing is revoked and the result only lists the matched certificates, so the affected set can be previewed first.
+// opts may be nil.
+//
+// Batches are independent: if Keyfactor rejects one, its certificates are recorded in FailedIds, the remaining
+// batches are still attempted, and an error describing the failures is returned alongside the result.
+func (c *Client) RevokeCertificatesByQuery(q string, reason RevocationReason, comment string, dryRun bool, opts *RevokeByQueryOptions) (*RevokeByQueryResult, error) {
+	c.infof("Revoking certificates matching query '%s' (dry run: %t)", q, dryRun)
+
+	if q == "" {
+		return nil, errors.New("a query is required to revoke certificates by query")
+	}
+	if comment == "" {
+		return nil, errors.New("a comment is required to revoke certificates")
+	}
+	if opts == nil {
+		opts = &RevokeByQueryOptions{}
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultRevokeBatchSize
+	}
+
+	opts.Progress.report(Progress{Phase: ProgressPhaseSearching})
+	matched, err := c.searchAllCertificates(q, &SearchCertificatesOptions{CollectionId: opts.CollectionId})
+	if err != nil {
+		return nil, err
+	}
+	result := &RevokeByQueryResult{DryRun: dryRun, Matched: matched}
+	c.infof("Query matched %d certificates", len(matched))
+	if dryRun || len(matched) == 0 {
+		opts.Progress.report(Progress{Phase: ProgressPhaseDone, Total: len(matched)})
+		return result, nil
+	}
+	opts.Progress.report(Progress{Phase: ProgressPhaseProcessing, Total: len(matched)})
+
+	ids := make([]int, len(matched))
+	for i, cert := range matched {
+		ids[i] = cert.Id
+	}
+
+	var failures []error
+	for start := 0; start < len(ids); start += batchSize {
+		end := start + batchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch := ids[start:end]
+
+		resp, err := c.revokeCertificates(&revokeCertificateBody{
+			CertificateIds: batch,
+			Reason:         reason,
+			Comment:        comment,
+			EffectiveDate:  getTimestamp(),
+			CollectionId:   opts.CollectionId,
+		})
+		if err != nil {
+			c.errorf("Revoking certificates %d-%d of %d failed: %s", start+1, end, len(ids), err)
+			result.FailedIds = append(result.FailedIds, batch...)
+			failures = append(failures, err)
+		} else {
+			result.RevokedIds = append(result.RevokedIds, resp.RevokedIds...)
+			result.SuspendedCerts = append(result.SuspendedCerts, resp.SuspendedCerts...)
+		}
+
+		c.infof("Processed %d of %d certificates", end, len(ids))
+		opts.Progress.report(Progress{Phase: ProgressPhaseProcessing, Completed: end - len(result.FailedIds), Failed: len(result.FailedIds), Total: len(ids)})
+	}
+	opts.Progress.report(Progress{Phase: ProgressPhaseDone, Completed: len(ids) - len(result.FailedIds), Failed: len(result.FailedIds), Total: len(ids)})
+
+	if len(failures) > 0 {
+		return result, fmt.Errorf("%d of %d certificates could not be revoked: %w", len(result.FailedIds), len(ids), failures[0])
+	}
+	return result, nil
+}
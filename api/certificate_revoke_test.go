@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/Keyfactor/keyfactor-go-client/enums"
+)
+
+func TestClient_RevokeCertificatesByQuery(t *testing.T) {
+	defer func(size int) { searchPageSize = size }(searchPageSize)
+	searchPageSize = 2
+
+	var revokeCalls int
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/KeyfactorAPI/Certificates":
+			if r.URL.Query().Get("pq.includeRevoked") != "false" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			// Five matching certificates, served two per page
+			page, _ := strconv.Atoi(r.URL.Query().Get("pq.pageReturned"))
+			var certs []GetCertificateResponse
+			for id := (page-1)*2 + 1; id <= page*2 && id <= 5; id++ {
+				certs = append(certs, GetCertificateResponse{Id: id, CertState: int(enums.CertificateStateActive)})
+			}
+			json.NewEncoder(w).Encode(certs)
+		case "/KeyfactorAPI/Certificates/Revoke":
+			revokeCalls++
+			var body revokeCertificateBody
+			json.NewDecoder(r.Body).Decode(&body)
+			for _, id := range body.CertificateIds {
+				if id == 3 {
+					w.WriteHeader(http.StatusBadRequest)
+					json.NewEncoder(w).Encode(map[string]string{"Message": "CA rejected revocation"})
+					return
+				}
+			}
+			json.NewEncoder(w).Encode(RevocationResponse{RevokedIds: body.CertificateIds})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		revokeCalls = 0
+		got, err := c.RevokeCertificatesByQuery(`IssuerDN -contains "Compromised CA"`, RevocationReasonCACompromise, "tabletop", true, nil)
+		if err != nil {
+			t.Fatalf("RevokeCertificatesByQuery() error = %v", err)
+		}
+		if len(got.Matched) != 5 || revokeCalls != 0 || !got.DryRun {
+			t.Errorf("RevokeCertificatesByQuery() matched %d, revoke calls %d; want 5 matched and no revocation", len(got.Matched), revokeCalls)
+		}
+	})
+
+	t.Run("BatchesWithFailure", func(t *testing.T) {
+		revokeCalls = 0
+		var progress []Progress
+		opts := &RevokeByQueryOptions{BatchSize: 2, Progress: func(p Progress) { progress = append(progress, p) }}
+		got, err := c.RevokeCertificatesByQuery(`IssuerDN -contains "Compromised CA"`, RevocationReasonCACompromise, "tabletop", false, opts)
+		if err == nil {
+			t.Fatal("RevokeCertificatesByQuery() expected error for failed batch")
+		}
+		if revokeCalls != 3 {
+			t.Errorf("RevokeCertificatesByQuery() made %d revoke calls, want 3", revokeCalls)
+		}
+		if len(got.RevokedIds) != 3 || len(got.FailedIds) != 2 || got.FailedIds[0] != 3 {
+			t.Errorf("RevokeCertificatesByQuery() revoked %v, failed %v", got.RevokedIds, got.FailedIds)
+		}
+		want := []Progress{
+			{Phase: ProgressPhaseSearching},
+			{Phase: ProgressPhaseProcessing, Total: 5},
+			{Phase: ProgressPhaseProcessing, Completed: 2, Total: 5},
+			{Phase: ProgressPhaseProcessing, Completed: 2, Failed: 2, Total: 5},
+			{Phase: ProgressPhaseProcessing, Completed: 3, Failed: 2, Total: 5},
+			{Phase: ProgressPhaseDone, Completed: 3, Failed: 2, Total: 5},
+		}
+		if !reflect.DeepEqual(progress, want) {
+			t.Errorf("RevokeCertificatesByQuery() progress = %+v, want %+v", progress, want)
+		}
+	})
+
+	t.Run("MissingComment", func(t *testing.T) {
+		if _, err := c.RevokeCertificatesByQuery(`CertState -eq 1`, RevocationReasonUnspecified, "", false, nil); err == nil {
+			t.Error("RevokeCertificatesByQuery() expected error for missing comment")
+		}
+	})
+}
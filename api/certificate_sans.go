@@ -0,0 +1,185 @@
+package api
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Keyfactor SubjectAltNameElements types. They follow the GeneralName tags of RFC 5280, with Microsoft's user
+// principal name given its own type.
+const (
+	SANElementOtherName     = 0
+	SANElementEmail         = 1
+	SANElementDNS           = 2
+	SANElementDirectoryName = 4
+	SANElementURI           = 6
+	SANElementIPAddress     = 7
+	SANElementRegisteredID  = 8
+	SANElementUPN           = 100
+)
+
+// CertificateSANs holds the subject alternative names of an issued certificate, parsed by type.
+type CertificateSANs struct {
+	DNSNames       []string
+	IPAddresses    []net.IP
+	URIs           []*url.URL
+	UPNs           []string
+	EmailAddresses []string
+}
+
+// SubjectAltNames parses the certificate's SubjectAltNameElements, returned when it is retrieved with a verbosity of
+// VerboseSANs or higher, into a CertificateSANs. Elements of other types, such as directory names, are skipped. An
+// error is returned if an IP address or URI element cannot be parsed.
+func (r *GetCertificateResponse) SubjectAltNames() (*CertificateSANs, error) {
+	sans := &CertificateSANs{}
+	for _, e := range r.SubjectAltNameElements {
+		switch e.Type {
+		case SANElementDNS:
+			sans.DNSNames = append(sans.DNSNames, e.Value)
+		case SANElementIPAddress:
+			ip := net.ParseIP(e.Value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address SAN %q on certificate %d", e.Value, r.Id)
+			}
+			sans.IPAddresses = append(sans.IPAddresses, ip)
+		case SANElementURI:
+			u, err := url.Parse(e.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid URI SAN %q on certificate %d: %v", e.Value, r.Id, err)
+			}
+			sans.URIs = append(sans.URIs, u)
+		case SANElementUPN:
+			sans.UPNs = append(sans.UPNs, e.Value)
+		case SANElementEmail:
+			sans.EmailAddresses = append(sans.EmailAddresses, e.Value)
+		}
+	}
+	return sans, nil
+}
+
+// SANsFromCertificate returns the subject alternative names of cert. Unlike the fields of x509.Certificate, these
+// include user principal names.
+func SANsFromCertificate(cert *x509.Certificate) (*CertificateSANs, error) {
+	upns, err := parseUPNs(cert)
+	if err != nil {
+		return nil, err
+	}
+	return &CertificateSANs{
+		DNSNames:       cert.DNSNames,
+		IPAddresses:    cert.IPAddresses,
+		URIs:           cert.URIs,
+		UPNs:           upns,
+		EmailAddresses: cert.EmailAddresses,
+	}, nil
+}
+
+// Equal reports whether s and other hold the same names, ignoring order, duplicates, and the case of DNS names and
+// email addresses.
+func (s *CertificateSANs) Equal(other *CertificateSANs) bool {
+	missing, extra := s.diff(other)
+	return len(missing) == 0 && len(extra) == 0
+}
+
+// MatchesCertificate returns an error describing the differences between s and the subject alternative names of
+// cert, or nil if they hold the same names. It is useful to check that a certificate found in a store is the one
+// Keyfactor has on record.
+func (s *CertificateSANs) MatchesCertificate(cert *x509.Certificate) error {
+	other, err := SANsFromCertificate(cert)
+	if err != nil {
+		return err
+	}
+	missing, extra := s.diff(other)
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "missing "+strings.Join(missing, ", "))
+	}
+	if len(extra) > 0 {
+		problems = append(problems, "unexpected "+strings.Join(extra, ", "))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("certificate SANs do not match: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// diff returns the names in s that are not in other, and those in other that are not in s, formatted as type:value.
+func (s *CertificateSANs) diff(other *CertificateSANs) (missing, extra []string) {
+	mine, theirs := s.names(), other.names()
+	for name := range mine {
+		if !theirs[name] {
+			missing = append(missing, name)
+		}
+	}
+	for name := range theirs {
+		if !mine[name] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(extra)
+	return missing, extra
+}
+
+// names returns the set of normalized names in s, keyed by type:value.
+func (s *CertificateSANs) names() map[string]bool {
+	names := map[string]bool{}
+	if s == nil {
+		return names
+	}
+	for _, dns := range s.DNSNames {
+		names["dns:"+strings.ToLower(dns)] = true
+	}
+	for _, ip := range s.IPAddresses {
+		names["ip:"+ip.String()] = true
+	}
+	for _, u := range s.URIs {
+		names["uri:"+u.String()] = true
+	}
+	for _, upn := range s.UPNs {
+		names["upn:"+upn] = true
+	}
+	for _, email := range s.EmailAddresses {
+		names["email:"+strings.ToLower(email)] = true
+	}
+	return names
+}
+
+// parseUPNs returns the user principal names in the subjectAltName extension of cert, which crypto/x509 skips.
+func parseUPNs(cert *x509.Certificate) ([]string, error) {
+	var upns []string
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidExtensionSubjectAltName) {
+			continue
+		}
+		var names []asn1.RawValue
+		if _, err := asn1.Unmarshal(ext.Value, &names); err != nil {
+			return nil, fmt.Errorf("invalid subjectAltName extension: %v", err)
+		}
+		for _, name := range names {
+			if name.Class != asn1.ClassContextSpecific || name.Tag != 0 {
+				continue
+			}
+			var otherName struct {
+				TypeId asn1.ObjectIdentifier
+				Value  asn1.RawValue `asn1:"explicit,tag:0"`
+			}
+			if _, err := asn1.UnmarshalWithParams(name.FullBytes, &otherName, "tag:0"); err != nil {
+				return nil, fmt.Errorf("invalid otherName SAN: %v", err)
+			}
+			if !otherName.TypeId.Equal(oidUserPrincipalName) {
+				continue
+			}
+			var upn string
+			if _, err := asn1.Unmarshal(otherName.Value.Bytes, &upn); err != nil {
+				return nil, fmt.Errorf("invalid user principal name SAN: %v", err)
+			}
+			upns = append(upns, upn)
+		}
+	}
+	return upns, nil
+}
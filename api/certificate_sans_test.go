@@ -0,0 +1,91 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCertificateSANs(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ext, err := marshalSANs(&SANs{
+		DNS:   []string{"www.example.com", "api.example.com"},
+		IP4:   []string{"10.0.0.1"},
+		URI:   []string{"spiffe://example.com/web"},
+		UPN:   []string{"svc-web@example.com"},
+		Email: []string{"pki@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber:    big.NewInt(1),
+		Subject:         pkix.Name{CommonName: "www.example.com"},
+		NotBefore:       time.Now(),
+		NotAfter:        time.Now().Add(time.Hour),
+		ExtraExtensions: []pkix.Extension{ext},
+	}, &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "issuer"}}, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	elements := []SubjectAltNameElements{
+		{Type: SANElementDNS, Value: "WWW.example.com"},
+		{Type: SANElementDNS, Value: "api.example.com"},
+		{Type: SANElementIPAddress, Value: "10.0.0.1"},
+		{Type: SANElementURI, Value: "spiffe://example.com/web"},
+		{Type: SANElementUPN, Value: "svc-web@example.com"},
+		{Type: SANElementEmail, Value: "pki@example.com"},
+		{Type: SANElementDirectoryName, Value: "CN=ignored"},
+	}
+
+	tests := []struct {
+		name     string
+		elements []SubjectAltNameElements
+		wantErr  string
+	}{
+		{name: "Match", elements: elements},
+		{name: "Missing", elements: elements[1:], wantErr: "unexpected dns:www.example.com"},
+		{name: "Extra", elements: append([]SubjectAltNameElements{{Type: SANElementDNS, Value: "old.example.com"}}, elements...), wantErr: "missing dns:old.example.com"},
+		{name: "InvalidIP", elements: []SubjectAltNameElements{{Type: SANElementIPAddress, Value: "not-an-ip"}}, wantErr: "invalid IP address SAN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &GetCertificateResponse{Id: 1, SubjectAltNameElements: tt.elements}
+			sans, err := resp.SubjectAltNames()
+			if err == nil {
+				err = sans.MatchesCertificate(cert)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("error = %v", err)
+				}
+				if len(sans.UPNs) != 1 || len(sans.IPAddresses) != 1 || len(sans.URIs) != 1 || len(sans.EmailAddresses) != 1 {
+					t.Errorf("SubjectAltNames() = %+v", sans)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	fromCert, err := SANsFromCertificate(cert)
+	if err != nil {
+		t.Fatalf("SANsFromCertificate() error = %v", err)
+	}
+	if len(fromCert.UPNs) != 1 || fromCert.UPNs[0] != "svc-web@example.com" {
+		t.Errorf("SANsFromCertificate() UPNs = %v", fromCert.UPNs)
+	}
+	if !fromCert.Equal(fromCert) {
+		t.Errorf("Equal() = false for identical SANs")
+	}
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"strconv"
+)
+
+// searchPageSize is the default page size used when walking every result of a certificate search.
+var searchPageSize = 500
+
+// SearchCertificatePages takes arguments for a query string, such as one built with the query package, to facilitate
+// a series of calls to Keyfactor that walk every page of matching certificates. fn is called once per page, in order,
+// so large result sets can be streamed without being held in memory; returning an error from fn stops the search and
+// that error is returned. opts may be nil; its owner fields, if set, are combined with q.
+func (c *Client) SearchCertificatePages(q string, opts *SearchCertificatesOptions, fn func(page []GetCertificateResponse) error) error {
+	return c.SearchCertificatePagesContext(context.Background(), q, opts, fn)
+}
+
+// SearchCertificatePagesContext is like SearchCertificatePages but uses ctx for the requests, allowing the search to
+// be cancelled. Unless opts sets a CollectionId, the search is scoped to the collection set with WithCollection.
+func (c *Client) SearchCertificatePagesContext(ctx context.Context, q string, opts *SearchCertificatesOptions, fn func(page []GetCertificateResponse) error) error {
+	c.infof("Searching certificates matching query '%s'", q)
+
+	if opts == nil {
+		opts = &SearchCertificatesOptions{}
+	}
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = searchPageSize
+	}
+	q, err := ownerQuery(q, opts)
+	if err != nil {
+		return err
+	}
+	collectionId := opts.CollectionId
+	if collectionId <= 0 {
+		collectionId = collectionFromContext(ctx)
+	}
+
+	for page := 1; ; page++ {
+		certs, err := c.searchCertificatesPage(ctx, q, opts, collectionId, page, pageSize)
+		if err != nil {
+			return err
+		}
+		if len(certs) > 0 {
+			if err := fn(certs); err != nil {
+				return err
+			}
+		}
+
+		if len(certs) < pageSize {
+			return nil
+		}
+	}
+}
+
+// searchCertificatesPage requests one page of a certificate search. q must already include the owner settings of
+// opts, whose PageSize is ignored in favour of pageSize.
+func (c *Client) searchCertificatesPage(ctx context.Context, q string, opts *SearchCertificatesOptions, collectionId, page, pageSize int) ([]GetCertificateResponse, error) {
+	sortField := opts.SortField
+	if sortField == "" {
+		sortField = "Id"
+	}
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	params := []StringTuple{
+		{"pq.queryString", q},
+		{"pq.includeRevoked", strconv.FormatBool(opts.IncludeRevoked)},
+		{"pq.includeExpired", strconv.FormatBool(opts.IncludeExpired)},
+		{"pq.sortField", sortField},
+		{"pq.sortAscending", sortAscending(opts.SortAscending)},
+	}
+	if collectionId > 0 {
+		params = append(params, StringTuple{"collectionId", strconv.Itoa(collectionId)})
+	}
+	if opts.IncludeMetadata {
+		params = append(params, StringTuple{"includeMetadata", "true"})
+	}
+	if opts.IncludeLocations {
+		params = append(params, StringTuple{"includeLocations", "true"})
+	}
+	if opts.IncludeHasPrivateKey {
+		params = append(params, StringTuple{"includeHasPrivateKey", "true"})
+	}
+	if opts.Verbose > 0 {
+		params = append(params, StringTuple{"verbose", strconv.Itoa(opts.Verbose)})
+	}
+	params = append(params, (&Paging{PageReturned: page, ReturnLimit: pageSize}).query()...)
+
+	keyfactorAPIStruct := &request{
+		Method:   "GET",
+		Endpoint: "Certificates",
+		Headers:  headers,
+		Query:    &apiQuery{Query: params},
+		Context:  ctx,
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonResp []GetCertificateResponse
+	err = c.decodeResponse(resp.Body, &jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// searchAllCertificates walks every page of a certificate search and returns the combined results.
+func (c *Client) searchAllCertificates(q string, opts *SearchCertificatesOptions) ([]GetCertificateResponse, error) {
+	all := []GetCertificateResponse{}
+	err := c.SearchCertificatePages(q, opts, func(page []GetCertificateResponse) error {
+		all = append(all, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
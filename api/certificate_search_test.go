@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestClient_SearchCertificatePages(t *testing.T) {
+	stop := errors.New("stop")
+	tests := []struct {
+		name      string
+		opts      *SearchCertificatesOptions
+		stopAfter int
+		wantPages int
+		wantQuery map[string]string
+		wantErr   error
+	}{
+		{
+			name:      "AllPages",
+			opts:      &SearchCertificatesOptions{PageSize: 2, IncludeMetadata: true, Verbose: 1, CollectionId: 4},
+			wantPages: 3,
+			wantQuery: map[string]string{"includeMetadata": "true", "verbose": "1", "collectionId": "4", "pq.returnLimit": "2", "pq.sortField": "Id", "pq.sortAscending": "1"},
+		},
+		{
+			name:      "Sorted",
+			opts:      &SearchCertificatesOptions{PageSize: 2, SortField: "ImportDate", SortAscending: true},
+			wantPages: 3,
+			wantQuery: map[string]string{"pq.sortField": "ImportDate", "pq.sortAscending": "0"},
+		},
+		{
+			name:      "CallbackStops",
+			opts:      &SearchCertificatesOptions{PageSize: 2},
+			stopAfter: 1,
+			wantPages: 1,
+			wantErr:   stop,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lastQuery map[string][]string
+			c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				lastQuery = r.URL.Query()
+				page, _ := strconv.Atoi(r.URL.Query().Get("pq.pageReturned"))
+				// Five certificates in pages of two.
+				var certs []GetCertificateResponse
+				for id := (page-1)*2 + 1; id <= page*2 && id <= 5; id++ {
+					certs = append(certs, GetCertificateResponse{Id: id})
+				}
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(certs)
+			})
+
+			pages := 0
+			err := c.SearchCertificatePages(`IssuedCN -contains "example"`, tt.opts, func(page []GetCertificateResponse) error {
+				pages++
+				if tt.stopAfter > 0 && pages == tt.stopAfter {
+					return stop
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SearchCertificatePages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if pages != tt.wantPages {
+				t.Errorf("SearchCertificatePages() delivered %d pages, want %d", pages, tt.wantPages)
+			}
+			for k, v := range tt.wantQuery {
+				if got := lastQuery[k]; len(got) != 1 || got[0] != v {
+					t.Errorf("query parameter %s = %v, want %s", k, got, v)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_ListCertificates_SortDirection(t *testing.T) {
+	var got []string
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.URL.Query().Get("pq.sortAscending"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	})
+	for _, q := range []map[string]string{
+		{"sortField": "NotAfter"},
+		{"sortField": "NotAfter", "sortAscending": "true"},
+	} {
+		if _, err := c.ListCertificates(q); err != nil {
+			t.Fatalf("ListCertificates(%v) error = %v", q, err)
+		}
+	}
+	// Keyfactor sorts descending for 1 and ascending for 0.
+	if want := []string{"1", "0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListCertificates() sent pq.sortAscending %q, want %q", got, want)
+	}
+}
@@ -1,5 +1,12 @@
 package api
 
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
 //
 //import (
 //	"reflect"
@@ -363,3 +370,76 @@ package api
 //		})
 //	}
 //}
+
+func TestClient_GetExpiringCertificates(t *testing.T) {
+	now := time.Now().UTC()
+	certs := []GetCertificateResponse{
+		{Id: 3, NotAfter: Timestamp{Raw: now.Add(72 * time.Hour).Format("2006-01-02T15:04:05")}},
+		{Id: 1, NotAfter: Timestamp{Raw: now.Add(24 * time.Hour).Format(time.RFC3339)}},
+		{Id: 4, NotAfter: Timestamp{Raw: "not a date"}},
+		{Id: 2, NotAfter: Timestamp{Raw: now.Add(48 * time.Hour).Format(time.RFC3339)}},
+	}
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/KeyfactorAPI/Certificates" || q.Get("pq.sortField") != "NotAfter" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if q.Get("collectionId") != "7" || q.Get("pq.returnLimit") != "50" || q.Get("pq.pageReturned") != "2" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"Message": "unexpected query parameters"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(certs)
+	})
+
+	tests := []struct {
+		name         string
+		window       time.Duration
+		collectionId int
+		paging       *Paging
+		wantIds      []int
+		wantErr      bool
+	}{
+		{
+			name:         "SortedByNotAfter",
+			window:       7 * 24 * time.Hour,
+			collectionId: 7,
+			paging:       &Paging{PageReturned: 2, ReturnLimit: 50},
+			wantIds:      []int{1, 2, 3, 4},
+		},
+		{
+			name:    "ServerRejectsQuery",
+			window:  time.Hour,
+			wantErr: true,
+		},
+		{
+			name:    "InvalidWindow",
+			window:  0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.GetExpiringCertificates(tt.window, tt.collectionId, tt.paging)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetExpiringCertificates() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			var gotIds []int
+			for _, cert := range got {
+				gotIds = append(gotIds, cert.Id)
+			}
+			if len(gotIds) != len(tt.wantIds) {
+				t.Fatalf("GetExpiringCertificates() got ids = %v, want %v", gotIds, tt.wantIds)
+			}
+			for i := range gotIds {
+				if gotIds[i] != tt.wantIds[i] {
+					t.Errorf("GetExpiringCertificates() got ids = %v, want %v", gotIds, tt.wantIds)
+					break
+				}
+			}
+		})
+	}
+}
@@ -2,18 +2,24 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"os"
 	"path"
+	"sort"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/Keyfactor/keyfactor-go-client-sdk/api/keyfactor"
+	"github.com/Keyfactor/keyfactor-go-client/query"
 )
 
 var (
@@ -21,13 +27,62 @@ var (
 	EnvCommandUsername = "KEYFACTOR_USERNAME"
 	EnvCommandPassword = "KEYFACTOR_PASSWORD"
 	EnvCommandDomain   = "KEYFACTOR_DOMAIN"
+	EnvCommandAPIPath  = "KEYFACTOR_API_PATH"
+	// EnvCommandSkipVerify disables TLS certificate verification when set to a true value accepted by
+	// strconv.ParseBool. Only use it against test instances with self-signed certificates.
+	EnvCommandSkipVerify = "KEYFACTOR_SKIP_VERIFY"
+
+	EnvCommandClientID     = "KEYFACTOR_AUTH_CLIENT_ID"
+	EnvCommandClientSecret = "KEYFACTOR_AUTH_CLIENT_SECRET"
+	EnvCommandTokenURL     = "KEYFACTOR_AUTH_TOKEN_URL"
+	EnvCommandScopes       = "KEYFACTOR_AUTH_SCOPES"
+	EnvCommandAudience     = "KEYFACTOR_AUTH_AUDIENCE"
+	EnvCommandAccessToken  = "KEYFACTOR_AUTH_ACCESS_TOKEN"
 )
 
 type Client struct {
-	hostname        string
-	httpClient      *http.Client
+	hostname   string
+	httpClient *http.Client
+	apiPath    string
+	username   string
+
+	// basicAuthString is replaced by RotateCredentials, so it is read with authorization once the client is in use.
+	authMu          sync.RWMutex
 	basicAuthString string
-	apiPath         string
+
+	disableCompression bool
+	apiVersions        APIVersions
+	codec              Codec
+	maxRequestBytes    int
+
+	securityModelMu sync.Mutex
+	securityModel   SecurityModel
+
+	sdkOnce sync.Once
+	sdk     *keyfactor.APIClient
+
+	instrumentedOnce sync.Once
+	instrumented     *http.Client
+	responseHookMu   sync.RWMutex
+	responseHook     func(*ResponseMetadata)
+
+	cacheMu sync.Mutex
+	cache   *responseCache
+
+	breakerMu sync.Mutex
+	breaker   *circuitBreaker
+
+	storeLocksMu sync.Mutex
+	storeLocks   map[string]*sync.Mutex
+
+	storeTypeCacheTTL time.Duration
+	storeTypesMu      sync.Mutex
+	storeTypes        *storeTypeCache
+
+	userAgent       string
+	applicationName string
+
+	logger Logger
 }
 
 // AuthConfig is a struct holding all necessary client configuration data
@@ -39,6 +94,42 @@ type AuthConfig struct {
 	Password string
 	Domain   string
 	APIPath  string
+	// Timeouts sets the request, dial, TLS handshake, and response header timeouts. Defaults to a request timeout of
+	// DefaultRequestTimeout.
+	Timeouts *TimeoutConfig
+	// DisableCompression requests uncompressed responses. By default, responses are requested gzip compressed.
+	DisableCompression bool
+	// SkipVerify disables verification of the Keyfactor server's TLS certificate.
+	SkipVerify bool
+	// OAuth authenticates with an OAuth 2.0 bearer token instead of Username and Password, which are then not
+	// required.
+	OAuth *OAuthConfig
+	// WindowsAuth authenticates with Kerberos or NTLM instead of basic authentication, for Command instances that only
+	// accept Windows integrated authentication.
+	WindowsAuth *WindowsAuthConfig
+	// ProxyAuth adds a bearer token for a reverse proxy in front of Keyfactor, such as Azure AD Application Proxy.
+	ProxyAuth *ProxyAuthConfig
+	// APIVersions pins endpoints to the API version requested of them.
+	APIVersions APIVersions
+	// Codec decodes response bodies. Defaults to LenientCodec; use StrictCodec to fail on fields the models do not
+	// define.
+	Codec Codec
+	// MaxRequestBytes is the largest request body sent to Keyfactor. Larger requests fail with ErrPayloadTooLarge
+	// without being sent, and AddCertificateToStores and RemoveCertificateFromStores split their store lists to stay
+	// under it. Defaults to DefaultMaxRequestBytes; use a negative value to send requests of any size.
+	MaxRequestBytes int
+	// StoreTypeCacheTTL is how long certificate store types looked up to validate new stores, such as with
+	// CreateStoreFctArgs.ValidateProperties, are reused. Defaults to DefaultStoreTypeCacheTTL; use a negative value to
+	// look the store type up for every store.
+	StoreTypeCacheTTL time.Duration
+	// UserAgent is appended to DefaultUserAgent in the User-Agent of every request, e.g. "my-tool/1.2.0", so the
+	// Command IIS logs attribute traffic to the tool built on this client.
+	UserAgent string
+	// ApplicationName is sent in the ApplicationNameHeader of every request, if set.
+	ApplicationName string
+	// Logger receives the messages the client logs. Defaults to the standard logger of the log package, as returned by
+	// NewStdLogger; use NewJSONLogger or an adapter to another logging library to keep them out of it.
+	Logger Logger
 }
 
 // NewKeyfactorClient creates a new Keyfactor client instance. A configured Client is returned with methods used to
@@ -63,30 +154,28 @@ func loginToKeyfactor(auth *AuthConfig) (*Client, error) {
 			return nil, fmt.Errorf("%s is required", EnvCommandHostname)
 		}
 	}
-	if auth.Username == "" {
-		envUsername := os.Getenv(EnvCommandUsername)
-		if envUsername != "" {
-			envDomain := os.Getenv(EnvCommandDomain)
-			if envDomain != "" && auth.Domain == "" && !strings.Contains(envUsername, envDomain) {
-				auth.Domain = envDomain
-			}
-			if auth.Domain != "" && !strings.Contains(envUsername, envDomain) {
-				auth.Username = auth.Domain + "\\" + envUsername
-			} else {
-				auth.Username = envUsername
-			}
-		} else {
-			return nil, fmt.Errorf("%s is required", EnvCommandUsername)
+	if auth.OAuth != nil && auth.WindowsAuth != nil {
+		return nil, errors.New("OAuth and Windows authentication cannot both be configured")
+	}
+	if auth.OAuth != nil {
+		if err := auth.OAuth.validate(); err != nil {
+			return nil, err
 		}
+	} else if auth.WindowsAuth != nil {
+		if err := auth.WindowsAuth.validate(auth); err != nil {
+			return nil, err
+		}
+	} else if err := loadBasicAuthFromEnv(auth); err != nil {
+		return nil, err
 	}
-	if auth.Password == "" {
-		envPassword := os.Getenv(EnvCommandPassword)
-		if envPassword != "" {
-			auth.Password = envPassword
-		} else {
-			return nil, fmt.Errorf("%s is required", EnvCommandPassword)
+	if auth.ProxyAuth != nil {
+		if err := auth.ProxyAuth.validate(); err != nil {
+			return nil, err
 		}
 	}
+	if err := auth.APIVersions.validate(); err != nil {
+		return nil, err
+	}
 
 	headers := &apiHeaders{
 		Headers: []StringTuple{
@@ -102,10 +191,22 @@ func loginToKeyfactor(auth *AuthConfig) (*Client, error) {
 	}
 
 	c := &Client{
-		hostname:        auth.Hostname,
-		httpClient:      &http.Client{Timeout: 10 * time.Second},
-		basicAuthString: buildBasicAuthString(auth),
-		apiPath:         auth.APIPath,
+		hostname:   auth.Hostname,
+		httpClient: newAuthenticatedHTTPClient(auth),
+		apiPath:    auth.APIPath,
+		username:   auth.Username,
+
+		disableCompression: auth.DisableCompression,
+		apiVersions:        auth.APIVersions,
+		codec:              auth.Codec,
+		maxRequestBytes:    auth.MaxRequestBytes,
+		storeTypeCacheTTL:  auth.StoreTypeCacheTTL,
+		userAgent:          auth.UserAgent,
+		applicationName:    auth.ApplicationName,
+		logger:             auth.Logger,
+	}
+	if auth.OAuth == nil && auth.WindowsAuth == nil {
+		c.basicAuthString = buildBasicAuthString(auth)
 	}
 
 	_, err := c.sendRequest(keyfactorAPIStruct)
@@ -113,33 +214,144 @@ func loginToKeyfactor(auth *AuthConfig) (*Client, error) {
 		return nil, err
 	}
 
-	log.Printf("[INFO] Successfully logged into Keyfactor at host %s", c.hostname)
+	c.infof("Successfully logged into Keyfactor at host %s", c.hostname)
 
 	return c, nil
 }
 
-// sendRequest takes an APIRequest struct as input and generates an API call
-// using the configuration data inside. It returns a pointer to an http response
-// struct and an error, if applicable.
-func (c *Client) sendRequest(request *request) (*http.Response, error) {
-	if c == nil {
-		return nil, errors.New("invalid Keyfactor client, please check your configuration")
+// loadBasicAuthFromEnv fills the username, domain, and password of auth that are not set from the environment,
+// returning an error if either the username or password remains unset.
+func loadBasicAuthFromEnv(auth *AuthConfig) error {
+	if auth.Username == "" {
+		envUsername := os.Getenv(EnvCommandUsername)
+		if envUsername != "" {
+			envDomain := os.Getenv(EnvCommandDomain)
+			if envDomain != "" && auth.Domain == "" && !strings.Contains(envUsername, envDomain) {
+				auth.Domain = envDomain
+			}
+			if auth.Domain != "" && !strings.Contains(envUsername, envDomain) {
+				auth.Username = auth.Domain + "\\" + envUsername
+			} else {
+				auth.Username = envUsername
+			}
+		} else {
+			return fmt.Errorf("%s is required", EnvCommandUsername)
+		}
 	}
-	u, err := url.Parse(c.hostname) // Parse raw hostname into URL structure
-	if err != nil {
-		return nil, err
+	if auth.Password == "" {
+		envPassword := os.Getenv(EnvCommandPassword)
+		if envPassword != "" {
+			auth.Password = envPassword
+		} else {
+			return fmt.Errorf("%s is required", EnvCommandPassword)
+		}
 	}
-	if u.Scheme != "https" {
-		u.Scheme = "https"
+
+	return nil
+}
+
+// newAuthenticatedHTTPClient returns the HTTP client used to reach Keyfactor, configured with the timeouts, TLS
+// verification, OAuth, and proxy authentication settings of auth.
+func newAuthenticatedHTTPClient(auth *AuthConfig) *http.Client {
+	hc := newHTTPClient(auth.Timeouts)
+	if auth.WindowsAuth != nil {
+		hc.Transport = windowsAuthBaseTransport(hc.Transport)
+	}
+	if auth.SkipVerify {
+		transport, ok := hc.Transport.(*http.Transport)
+		if !ok {
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+		}
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.InsecureSkipVerify = true
+		hc.Transport = transport
+	}
+	// Tokens are requested from the identity provider directly, without the other token.
+	tokenTransport := hc.Transport
+	if tokenTransport == nil {
+		tokenTransport = http.DefaultTransport
+	}
+	if auth.OAuth != nil {
+		hc.Transport = &oauthTransport{base: tokenTransport, tokenBase: tokenTransport, config: *auth.OAuth}
+	}
+	if auth.WindowsAuth != nil {
+		hc.Transport = newWindowsAuthTransport(tokenTransport, auth)
 	}
+	if auth.ProxyAuth != nil {
+		base := hc.Transport
+		if base == nil {
+			base = tokenTransport
+		}
+		hc.Transport = &oauthTransport{base: base, tokenBase: tokenTransport, config: auth.ProxyAuth.OAuth, header: auth.ProxyAuth.header()}
+	}
+	return hc
+}
 
-	// Set request endpoint
+// sdkClient returns the Keyfactor SDK client used by the SDK-backed methods. It is created on first use from the
+// client's hostname and credentials and shares the client's http.Client, so connections are reused across calls and
+// response metadata is reported for SDK calls too. Its requests are sent under the client's API path.
+// Settings the client does not carry fall back to the SDK's KEYFACTOR_* environment variables.
+func (c *Client) sdkClient() *keyfactor.APIClient {
+	c.sdkOnce.Do(func() {
+		config := keyfactor.NewConfiguration(make(map[string]string))
+		if c.hostname != "" {
+			hostname := c.hostname
+			if !strings.HasPrefix(hostname, "http://") && !strings.HasPrefix(hostname, "https://") {
+				hostname = "https://" + hostname
+			}
+			if u, err := url.Parse(hostname); err == nil {
+				config.Host = u.Host
+			}
+		}
+		if authz := c.authorization(); authz != "" {
+			authReq := &http.Request{Header: http.Header{"Authorization": {authz}}}
+			if username, password, ok := authReq.BasicAuth(); ok {
+				config.BasicAuth = keyfactor.BasicAuth{UserName: username, Password: password}
+			}
+		}
+		hc := c.instrumentedHTTPClient()
+		if prefix := c.apiPrefix(); prefix != sdkAPIPrefix {
+			sdkHC := *hc
+			sdkHC.Transport = &sdkPathTransport{base: hc.Transport, prefix: "/" + prefix}
+			hc = &sdkHC
+		}
+		config.HTTPClient = hc
+		c.sdk = keyfactor.NewAPIClient(config)
+	})
+	return c.sdk
+}
+
+// sdkAPIPrefix is the API path the SDK sends every request under, in the format of apiPrefix.
+const sdkAPIPrefix = "KeyfactorAPI/"
+
+// sdkPathTransport is an http.RoundTripper that moves the requests of the SDK, whose endpoints are fixed under
+// /KeyfactorAPI, to the API path of the client, e.g. one set with KEYFACTOR_API_PATH.
+type sdkPathTransport struct {
+	base   http.RoundTripper
+	prefix string
+}
+
+func (t *sdkPathTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.HasPrefix(req.URL.Path, "/"+sdkAPIPrefix) {
+		return t.base.RoundTrip(req)
+	}
+	r := req.Clone(req.Context())
+	r.URL.Path = t.prefix + strings.TrimPrefix(req.URL.Path, "/"+sdkAPIPrefix)
+	r.URL.RawPath = ""
+	return t.base.RoundTrip(r)
+}
+
+// apiPrefix returns the API path of the client, or of the environment if the client has none, without a leading
+// slash and with a trailing one, e.g. "KeyfactorAPI/".
+func (c *Client) apiPrefix() string {
 	var (
 		apiPrefix string
 		prefixSet bool
 	)
 	if c.apiPath == "" {
-		apiPrefix, prefixSet = os.LookupEnv("KEYFACTOR_API_PATH")
+		apiPrefix, prefixSet = os.LookupEnv(EnvCommandAPIPath)
 		if !prefixSet { // If not set, use default
 			apiPrefix = "KeyfactorAPI/"
 		}
@@ -155,8 +367,26 @@ func (c *Client) sendRequest(request *request) (*http.Response, error) {
 	if strings.HasPrefix(apiPrefix, "/") {
 		apiPrefix = strings.TrimPrefix(apiPrefix, "/")
 	}
+	return apiPrefix
+}
+
+// sendRequest takes an APIRequest struct as input and generates an API call
+// using the configuration data inside. It returns a pointer to an http response
+// struct and an error, if applicable.
+func (c *Client) sendRequest(request *request) (*http.Response, error) {
+	if c == nil {
+		return nil, errors.New("invalid Keyfactor client, please check your configuration")
+	}
+	u, err := url.Parse(c.hostname) // Parse raw hostname into URL structure
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "https" {
+		u.Scheme = "https"
+	}
 
-	endpoint := apiPrefix + request.Endpoint
+	// Set request endpoint
+	endpoint := c.apiPrefix() + request.Endpoint
 	u.Path = path.Join(u.Path, endpoint) // Attach enroll endpoint
 
 	// Set request query
@@ -171,46 +401,66 @@ func (c *Client) sendRequest(request *request) (*http.Response, error) {
 
 	keyfactorPath := u.String() // Convert absolute path to string
 
-	log.Printf("[INFO] Preparing a %s request to path '%s'", request.Method, keyfactorPath)
+	ctx := request.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	corrID := correlationID(ctx)
+	fields := LogFields{"method": request.Method, "endpoint": request.Endpoint, "correlation_id": corrID}
+
+	c.logWith(LogLevelInfo, fields, "Preparing a %s request to path '%s' with correlation ID %s", request.Method, keyfactorPath, corrID)
 	jsonByes, mErr := json.Marshal(request.Payload)
 	if mErr != nil {
 		return nil, mErr
 	}
-	//log.Printf("[TRACE] Request body: %s", jsonByes)
+	//c.debugf("Request body: %s", jsonByes)
 
-	req, reqErr := http.NewRequest(request.Method, keyfactorPath, bytes.NewBuffer(jsonByes))
+	req, reqErr := http.NewRequestWithContext(ctx, request.Method, keyfactorPath, bytes.NewBuffer(jsonByes))
 	if reqErr != nil {
 		return nil, reqErr
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", c.basicAuthString)
+	if authz := c.authorization(); authz != "" {
+		req.Header.Set("Authorization", authz)
+	}
 
 	// Set custom Keyfactor headers
 	for _, headers := range request.Headers.Headers {
 		req.Header.Set(headers.Elem1, headers.Elem2)
 	}
+	req.Header.Set(CorrelationIDHeader, corrID)
 
-	resp, respErr := c.httpClient.Do(req)
+	resp, respErr := c.instrumentedHTTPClient().Do(req)
 	if respErr != nil {
-		return nil, respErr
+		c.logWith(LogLevelError, fields, "Call to %s with correlation ID %s failed: %v", keyfactorPath, corrID, respErr)
+		return nil, fmt.Errorf("%w (correlation ID %s)", respErr, corrID)
+	}
+	if htmlErr := checkHTMLResponse(resp, corrID); htmlErr != nil {
+		c.logWith(LogLevelError, fields, "Call to %s returned an HTML page with status %d: %s", keyfactorPath, resp.StatusCode, htmlErr)
+		return nil, htmlErr
 	}
 	var stringMessage string
 	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNoContent {
-		log.Printf("[DEBUG] %s succeeded with response code %d", request.Method, resp.StatusCode)
+		c.logWith(LogLevelDebug, fields, "%s succeeded with response code %d", request.Method, resp.StatusCode)
 		return resp, nil
 	} else if resp.StatusCode == http.StatusNotFound {
 		stringMessage = fmt.Sprintf("Error %d - the requested resource was not found. Please check the request and try again.", resp.StatusCode)
-		log.Printf("[ERROR] Call to %s returned status %d. %s", keyfactorPath, resp.StatusCode, stringMessage)
-		return nil, errors.New(stringMessage)
+		apiErr := &RequestError{StatusCode: resp.StatusCode, Message: stringMessage, CorrelationID: corrID, ActivityID: responseActivityID(resp, nil)}
+		c.logWith(LogLevelError, fields, "Call to %s returned status %d. %s", keyfactorPath, resp.StatusCode, apiErr)
+		return nil, apiErr
 	} else if resp.StatusCode == http.StatusUnauthorized {
 		_, derr := httputil.DumpResponse(resp, true)
 		if derr != nil {
 			return nil, derr
 		}
-		err = errors.New("401 - Unauthorized: Access is denied due to invalid credentials")
-		return nil, err
+		return nil, &RequestError{
+			StatusCode:    resp.StatusCode,
+			Message:       "401 - Unauthorized: Access is denied due to invalid credentials",
+			CorrelationID: corrID,
+			ActivityID:    responseActivityID(resp, nil),
+		}
 	} else {
 		var errorMessage map[string]interface{} // Decode JSON body to handle issue
 		err = json.NewDecoder(resp.Body).Decode(&errorMessage)
@@ -220,11 +470,16 @@ func (c *Client) sendRequest(request *request) (*http.Response, error) {
 			if derr != nil {
 				return nil, derr
 			}
-			uerr := errors.New(fmt.Sprintf("%d - Unknown error connecting to Keyfactor %s, please check your connection.", resp.StatusCode, endpoint))
-			return nil, uerr
+			return nil, &RequestError{
+				StatusCode:    resp.StatusCode,
+				Message:       fmt.Sprintf("%d - Unknown error connecting to Keyfactor %s, please check your connection.", resp.StatusCode, endpoint),
+				CorrelationID: corrID,
+				ActivityID:    responseActivityID(resp, nil),
+			}
 		}
 
-		log.Printf("[DEBUG] Request failed with code %d and message %v", resp.StatusCode, errorMessage)
+		activityID := responseActivityID(resp, errorMessage)
+		c.logWith(LogLevelDebug, fields, "Request with correlation ID %s failed with code %d, activity ID %s, and message %v", corrID, resp.StatusCode, activityID, errorMessage)
 		_, hasFailedOps := errorMessage["FailedOperations"]
 		if hasFailedOps {
 			var fOps []string
@@ -238,7 +493,7 @@ func (c *Client) sendRequest(request *request) (*http.Response, error) {
 		if hasMsg {
 			stringMessage += fmt.Sprintf("%s", errorMessage["Message"])
 		}
-		return nil, errors.New(stringMessage)
+		return nil, &RequestError{StatusCode: resp.StatusCode, Message: stringMessage, CorrelationID: corrID, ActivityID: activityID}
 	}
 }
 
@@ -246,7 +501,7 @@ func (c *Client) sendRequest(request *request) (*http.Response, error) {
 // returns a base-64 encoded auth string including the 'Basic ' prefix.
 func buildBasicAuthString(auth *AuthConfig) string {
 	var authString string
-	//log.Println("[TRACE] Building Authorization field")
+	//c.debugf("Building Authorization field")
 	if auth.Domain != "" && !strings.Contains(auth.Username, auth.Domain) {
 		authString = strings.Join([]string{auth.Domain, "\\", auth.Username, ":", auth.Password}, "")
 	} else {
@@ -262,49 +517,63 @@ func getTimestamp() string {
 	return time.Now().UTC().Format(time.RFC3339)
 }
 
+// keyfactorTimeLayouts lists the timestamp formats returned by the Keyfactor API. Older Command releases omit the
+// zone designator; those timestamps are treated as UTC.
+var keyfactorTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04:05",
+}
+
+// parseKeyfactorTime parses a timestamp returned by the Keyfactor API into a UTC time.Time.
+func parseKeyfactorTime(s string) (time.Time, error) {
+	for _, layout := range keyfactorTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unable to parse Keyfactor timestamp %q", s)
+}
+
+// buildQuery converts a map of field names to values into a Keyfactor query string, joining each field with AND.
+// Slice values are matched with OR. Fields are emitted in sorted order so the resulting query is deterministic.
 func buildQuery(params map[string]interface{}, filterName string) (apiQuery, error) {
-	query := apiQuery{
+	q := apiQuery{
 		Query: []StringTuple{},
 	}
-	var qStr string
-	for k, v := range params {
-		switch v.(type) {
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var conds []*query.Condition
+	for _, k := range keys {
+		switch v := params[k].(type) {
 		case []string:
-			var nestedQuery []string
-			for _, val := range v.([]string) {
+			var values []interface{}
+			for _, val := range v {
 				if val != "" {
-					nestedQuery = append(nestedQuery, fmt.Sprintf("%s -eq \"%s\"", k, val))
+					values = append(values, val)
 				}
 			}
-			if v != nil && len(v.([]string)) > 0 {
-				if qStr != "" {
-					qStr += fmt.Sprintf("AND (%s)", strings.Join(nestedQuery, " OR "))
-				} else {
-					qStr += fmt.Sprintf("(%s)", strings.Join(nestedQuery, " OR "))
-				}
+			if len(values) > 0 {
+				conds = append(conds, query.Field(k).In(values...))
 			}
-
 		case string:
-			if qStr != "" {
-				qStr += fmt.Sprintf("AND %s -eq \"%s\"", k, v.(string))
-			} else if v != "" {
-				qStr += fmt.Sprintf("(%s -eq \"%s\")", k, v.(string))
-			}
-		case int:
-			if qStr != "" {
-				qStr += fmt.Sprintf("AND %s -eq %d", k, v.(int))
-			} else {
-				qStr += fmt.Sprintf("(%s -eq %d)", k, v.(int))
-			}
-		case bool:
-			if qStr != "" {
-				qStr += fmt.Sprintf("AND %s -eq %t", k, v.(bool))
-			} else {
-				qStr += fmt.Sprintf("(%s -eq %t)", k, v.(bool))
+			if v != "" {
+				conds = append(conds, query.Field(k).Eq(v))
 			}
+		case int, bool:
+			conds = append(conds, query.Field(k).Eq(v))
 		}
+	}
 
+	qStr, err := query.And(conds...).Build()
+	if err != nil {
+		return q, err
 	}
-	query.Query = append(query.Query, StringTuple{filterName, qStr})
-	return query, nil
+	q.Query = append(q.Query, StringTuple{filterName, qStr})
+	return q, nil
 }
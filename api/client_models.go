@@ -1,5 +1,10 @@
 package api
 
+import (
+	"context"
+	"strconv"
+)
+
 // StringTuple is a struct holding two string elements used by the Keyfactor
 // Go Client library for data types requiring a tuple of strings
 type StringTuple struct {
@@ -28,4 +33,49 @@ type request struct {
 	Headers  *apiHeaders
 	Query    *apiQuery
 	Payload  interface{}
+	Context  context.Context
+}
+
+// Paging holds the page and sort settings used by paged Keyfactor list and search calls. Zero values are omitted,
+// letting Keyfactor apply its defaults.
+type Paging struct {
+	// The 1-based page of results to return.
+	PageReturned int
+	// The maximum number of results to return per page.
+	ReturnLimit int
+	// SortField is the field to sort results by, e.g. "ImportDate". Results are sorted in descending order, such as
+	// most recent first, unless SortAscending is set.
+	SortField     string
+	SortAscending bool
+}
+
+// query converts the paging settings into pq.* query parameters. A nil Paging yields no parameters.
+func (p *Paging) query() []StringTuple {
+	return p.prefixedQuery("pq")
+}
+
+// prefixedQuery is like query, for endpoints that name their paging parameters with a prefix other than pq.
+func (p *Paging) prefixedQuery(prefix string) []StringTuple {
+	var q []StringTuple
+	if p == nil {
+		return q
+	}
+	if p.PageReturned > 0 {
+		q = append(q, StringTuple{prefix + ".pageReturned", strconv.Itoa(p.PageReturned)})
+	}
+	if p.ReturnLimit > 0 {
+		q = append(q, StringTuple{prefix + ".returnLimit", strconv.Itoa(p.ReturnLimit)})
+	}
+	if p.SortField != "" {
+		q = append(q, StringTuple{prefix + ".sortField", p.SortField}, StringTuple{prefix + ".sortAscending", sortAscending(p.SortAscending)})
+	}
+	return q
+}
+
+// sortAscending formats a sort direction as Keyfactor expects it: 0 for ascending and 1 for descending.
+func sortAscending(ascending bool) string {
+	if ascending {
+		return "0"
+	}
+	return "1"
 }
@@ -1,12 +1,27 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 )
 
+// newTestServer starts a TLS test server backed by handler and returns a Client wired to talk to it. The server is
+// closed when the test completes.
+func newTestServer(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewTLSServer(handler)
+	t.Cleanup(srv.Close)
+	return &Client{
+		hostname:   srv.URL,
+		httpClient: srv.Client(),
+		apiPath:    "KeyfactorAPI",
+	}
+}
+
 func TestClient_sendRequest(t *testing.T) {
 	type fields struct {
 		hostname        string
@@ -132,3 +147,204 @@ func Test_loginToKeyfactor(t *testing.T) {
 		})
 	}
 }
+
+func Test_buildQuery(t *testing.T) {
+	type args struct {
+		params     map[string]interface{}
+		filterName string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    apiQuery
+		wantErr bool
+	}{
+		{
+			name: "MixedTypes",
+			args: args{
+				params: map[string]interface{}{
+					"ClientMachine": "host1",
+					"ContainerId":   3,
+					"Approved":      true,
+					"AgentId":       []string{"a", "", "b"},
+				},
+				filterName: "certificateStoreQuery.queryString",
+			},
+			want: apiQuery{Query: []StringTuple{{
+				Elem1: "certificateStoreQuery.queryString",
+				Elem2: `(AgentId -eq "a" OR AgentId -eq "b") AND Approved -eq true AND ClientMachine -eq "host1" AND ContainerId -eq 3`,
+			}}},
+		},
+		{
+			name: "EscapesValues",
+			args: args{
+				params:     map[string]interface{}{"StorePath": `C:\certs\"x"`},
+				filterName: "pq.queryString",
+			},
+			want: apiQuery{Query: []StringTuple{{
+				Elem1: "pq.queryString",
+				Elem2: `StorePath -eq "C:\\certs\\\"x\""`,
+			}}},
+		},
+		{
+			name: "InvalidFieldName",
+			args: args{
+				params:     map[string]interface{}{`Id -eq "1" OR x`: "y"},
+				filterName: "pq.queryString",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildQuery(tt.args.params, tt.args.filterName)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("buildQuery() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildQuery() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_sdkClient(t *testing.T) {
+	var authUsers []string
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/KeyfactorAPI/CertificateStoreTypes" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		user, _, _ := r.BasicAuth()
+		authUsers = append(authUsers, user)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"StoreType": 2, "Name": "IIS Personal", "ShortName": "IIS"}]`))
+	})
+	c.basicAuthString = buildBasicAuthString(&AuthConfig{Username: "svc_kf", Password: "secret", Domain: "KEYFACTOR"})
+
+	tests := []struct {
+		name    string
+		cancel  bool
+		wantErr bool
+	}{
+		{name: "UsesClientConfig"},
+		{name: "ReusesClient"},
+		{name: "CancelledContext", cancel: true, wantErr: true},
+	}
+	first := c.sdkClient()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.cancel {
+				cancel()
+			} else {
+				defer cancel()
+			}
+
+			got, err := c.ListCertificateStoreTypesContext(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ListCertificateStoreTypesContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if c.sdkClient() != first {
+				t.Errorf("sdkClient() returned a new client, want the cached one")
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(*got) != 1 || (*got)[0].ShortName != "IIS" {
+				t.Errorf("ListCertificateStoreTypesContext() = %+v", got)
+			}
+			if authUsers[len(authUsers)-1] != `KEYFACTOR\svc_kf` {
+				t.Errorf("SDK request authenticated as %q, want %q", authUsers[len(authUsers)-1], `KEYFACTOR\svc_kf`)
+			}
+		})
+	}
+}
+
+func TestClient_sdkClient_APIPath(t *testing.T) {
+	var paths []string
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"StoreType": 2, "Name": "IIS Personal", "ShortName": "IIS"}]`))
+	})
+	c.apiPath = "/Keyfactor/API"
+
+	if _, err := c.ListCertificateStoreTypesContext(context.Background()); err != nil {
+		t.Fatalf("ListCertificateStoreTypesContext() error = %v", err)
+	}
+	if len(paths) != 1 || paths[0] != "/Keyfactor/API/CertificateStoreTypes" {
+		t.Errorf("SDK requests were sent to %q, want the client's API path", paths)
+	}
+}
+
+func TestClient_ListMethods_EmptyVersusError(t *testing.T) {
+	var status int
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			w.Write([]byte(`[]`))
+		} else {
+			w.Write([]byte(`{"ErrorCode": "0xA0110002", "Message": "An unknown error occurred."}`))
+		}
+	})
+
+	tests := []struct {
+		name string
+		list func() (interface{}, error)
+	}{
+		{"ListCertificateStores", func() (interface{}, error) {
+			stores, err := c.ListCertificateStores(nil)
+			if stores == nil {
+				return nil, err
+			}
+			return *stores, err
+		}},
+		{"SearchCertificateStores", func() (interface{}, error) { return c.SearchCertificateStores("", nil) }},
+		{"ListAllCertificateStores", func() (interface{}, error) { return c.ListAllCertificateStores("", nil) }},
+		{"ListCertificates", func() (interface{}, error) { return c.ListCertificates(nil) }},
+		{"ListAllAuditLogs", func() (interface{}, error) { return c.ListAllAuditLogs("", nil) }},
+		{"ListJobHistory", func() (interface{}, error) { return c.ListJobHistory("Status -eq 1") }},
+		{"GetAgentList", func() (interface{}, error) { return c.GetAgentList() }},
+		{"GetCAList", func() (interface{}, error) { return c.GetCAList() }},
+		{"GetTemplates", func() (interface{}, error) { return c.GetTemplates() }},
+		{"GetAllMetadataFields", func() (interface{}, error) { return c.GetAllMetadataFields() }},
+		{"ListCertificateStoreTypes", func() (interface{}, error) {
+			types, err := c.ListCertificateStoreTypes()
+			if types == nil {
+				return nil, err
+			}
+			return *types, err
+		}},
+		{"GetStoreContainers", func() (interface{}, error) {
+			containers, err := c.GetStoreContainers()
+			if containers == nil {
+				return nil, err
+			}
+			return *containers, err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status = http.StatusOK
+			got, err := tt.list()
+			if err != nil {
+				t.Fatalf("%s() error = %v", tt.name, err)
+			}
+			if v := reflect.ValueOf(got); v.Kind() != reflect.Slice || v.IsNil() || v.Len() != 0 {
+				t.Errorf("%s() = %#v, want an empty slice", tt.name, got)
+			}
+
+			status = http.StatusInternalServerError
+			got, err = tt.list()
+			if err == nil {
+				t.Fatalf("%s() succeeded, want error", tt.name)
+			}
+			if v := reflect.ValueOf(got); got != nil && !v.IsNil() {
+				t.Errorf("%s() = %#v with an error, want nil", tt.name, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	awsAccountIdPattern = regexp.MustCompile(`^[0-9]{12}$`)
+	awsRoleARNPattern   = regexp.MustCompile(`^arn:aws[a-z-]*:iam::[0-9]{12}:role/.+$`)
+)
+
+// NewAKVStoreArgs takes arguments for AKVStoreArgs to facilitate a call to Keyfactor that returns the arguments of
+// CreateStore for an Azure Key Vault store. The properties are checked against the store type installed in Keyfactor:
+// an error is returned if it does not define a property that was set, or if it requires one that was not. Required
+// arguments are:
+//   - AgentId           : string
+//   - TenantId          : string
+//   - SubscriptionId    : string
+//   - ResourceGroupName : string
+//   - VaultName         : string
+func (c *Client) NewAKVStoreArgs(args *AKVStoreArgs) (*CreateStoreFctArgs, error) {
+	return c.NewAKVStoreArgsContext(context.Background(), args)
+}
+
+// NewAKVStoreArgsContext is like NewAKVStoreArgs but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) NewAKVStoreArgsContext(ctx context.Context, args *AKVStoreArgs) (*CreateStoreFctArgs, error) {
+	if args == nil || args.TenantId == "" || args.SubscriptionId == "" || args.ResourceGroupName == "" || args.VaultName == "" {
+		return nil, errors.New("tenant id, subscription id, resource group name, and vault name are required for an Azure Key Vault store")
+	}
+	if (args.ApplicationId == nil) != (args.ClientSecret == nil) {
+		return nil, errors.New("both an application id and a client secret are required to access an Azure Key Vault as an application")
+	}
+	props := map[string]interface{}{
+		"TenantId":        args.TenantId,
+		"AzureCloud":      args.AzureCloud,
+		"PrivateEndpoint": args.PrivateEndpoint,
+		"SkuType":         args.SkuType,
+		"VaultRegion":     args.VaultRegion,
+	}
+	addSecretProperty(props, "ServerUsername", args.ApplicationId)
+	addSecretProperty(props, "ServerPassword", args.ClientSecret)
+	storePath := strings.Join([]string{args.SubscriptionId, args.ResourceGroupName, args.VaultName}, ":")
+	return c.cloudStoreArgs(ctx, defaultShortName(args.ShortName, "AKV"), args.AgentId, args.TenantId, storePath, props)
+}
+
+// NewACMStoreArgs takes arguments for ACMStoreArgs to facilitate a call to Keyfactor that returns the arguments of
+// CreateStore for an AWS Certificate Manager store. The properties are checked against the store type installed in
+// Keyfactor, as by NewAKVStoreArgs. Required arguments are:
+//   - AgentId   : string
+//   - AccountId : string
+//   - Region    : string
+//   - RoleARN   : string
+func (c *Client) NewACMStoreArgs(args *ACMStoreArgs) (*CreateStoreFctArgs, error) {
+	return c.NewACMStoreArgsContext(context.Background(), args)
+}
+
+// NewACMStoreArgsContext is like NewACMStoreArgs but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) NewACMStoreArgsContext(ctx context.Context, args *ACMStoreArgs) (*CreateStoreFctArgs, error) {
+	if args == nil || args.AccountId == "" || args.Region == "" || args.RoleARN == "" {
+		return nil, errors.New("account id, region, and role ARN are required for an AWS Certificate Manager store")
+	}
+	if !awsAccountIdPattern.MatchString(args.AccountId) {
+		return nil, fmt.Errorf("invalid AWS account id %q", args.AccountId)
+	}
+	if !awsRoleARNPattern.MatchString(args.RoleARN) {
+		return nil, fmt.Errorf("invalid IAM role ARN %q", args.RoleARN)
+	}
+	if (args.AccessKeyId == nil) != (args.SecretAccessKey == nil) {
+		return nil, errors.New("both an access key id and a secret access key are required to access AWS as an IAM user")
+	}
+	props := map[string]interface{}{
+		"ExternalId": args.ExternalId,
+	}
+	if args.AccessKeyId != nil {
+		props["UseIAM"] = "true"
+		props["IAMAssumeRole"] = args.RoleARN
+		addSecretProperty(props, "ServerUsername", args.AccessKeyId)
+		addSecretProperty(props, "ServerPassword", args.SecretAccessKey)
+	} else {
+		props["UseDefaultSdkAuth"] = "true"
+		props["DefaultSdkAssumeRole"] = args.RoleARN
+	}
+	return c.cloudStoreArgs(ctx, defaultShortName(args.ShortName, "AWS-ACM-v3"), args.AgentId, args.AccountId, args.Region, props)
+}
+
+// NewGCPStoreArgs takes arguments for GCPStoreArgs to facilitate a call to Keyfactor that returns the arguments of
+// CreateStore for a Google Cloud Certificate Manager store. The properties are checked against the store type
+// installed in Keyfactor, as by NewAKVStoreArgs. Required arguments are:
+//   - AgentId   : string
+//   - ProjectId : string
+func (c *Client) NewGCPStoreArgs(args *GCPStoreArgs) (*CreateStoreFctArgs, error) {
+	return c.NewGCPStoreArgsContext(context.Background(), args)
+}
+
+// NewGCPStoreArgsContext is like NewGCPStoreArgs but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) NewGCPStoreArgsContext(ctx context.Context, args *GCPStoreArgs) (*CreateStoreFctArgs, error) {
+	if args == nil || args.ProjectId == "" {
+		return nil, errors.New("project id is required for a GCP Certificate Manager store")
+	}
+	location := args.Location
+	if location == "" {
+		location = "global"
+	}
+	props := map[string]interface{}{
+		"Location":      location,
+		"ProjectNumber": args.ProjectNumber,
+	}
+	addSecretProperty(props, "ServiceAccountKey", args.ServiceAccountKey)
+	return c.cloudStoreArgs(ctx, defaultShortName(args.ShortName, "GcpCertMgr"), args.AgentId, args.ProjectId, location, props)
+}
+
+// cloudStoreArgs returns the arguments of CreateStore for a store of the store type with the given short name, after
+// checking props against the properties the store type defines. Unset properties the store type does not define are
+// dropped, as extension versions differ in the properties they define.
+func (c *Client) cloudStoreArgs(ctx context.Context, shortName, agentId, clientMachine, storePath string, props map[string]interface{}) (*CreateStoreFctArgs, error) {
+	if agentId == "" {
+		return nil, errors.New("orchestrator agent id is required for creation of new certificate store")
+	}
+	storeType, err := c.cachedStoreTypeByName(ctx, shortName)
+	if err != nil {
+		return nil, err
+	}
+
+	defined := storeType.propertyDefinitions()
+	for name, value := range props {
+		_, ok := defined[name]
+		if s, isString := value.(string); (ok && value == "") || (!ok && isString && (s == "" || s == "false")) {
+			delete(props, name)
+		}
+	}
+	if err := checkStoreProperties(storeType, props); err != nil {
+		return nil, err
+	}
+
+	return &CreateStoreFctArgs{
+		ClientMachine: clientMachine,
+		StorePath:     storePath,
+		CertStoreType: storeType.StoreType,
+		AgentId:       agentId,
+		Properties:    props,
+	}, nil
+}
+
+func defaultShortName(shortName, def string) string {
+	if shortName == "" {
+		return def
+	}
+	return shortName
+}
+
+// isEmptyDefault reports whether a property default value decoded from Keyfactor is unset.
+func isEmptyDefault(v interface{}) bool {
+	s, ok := v.(string)
+	return v == nil || (ok && s == "")
+}
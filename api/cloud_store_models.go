@@ -0,0 +1,64 @@
+package api
+
+// AKVStoreArgs holds the arguments of NewAKVStoreArgs, describing an Azure Key Vault managed by the Azure Key Vault
+// orchestrator extension.
+type AKVStoreArgs struct {
+	// AgentId is the ID of the orchestrator that manages the vault.
+	AgentId           string
+	TenantId          string
+	SubscriptionId    string
+	ResourceGroupName string
+	VaultName         string
+	// ApplicationId and ClientSecret are the credentials of the Azure AD application used to access the vault.
+	// Leave them nil to use the managed identity of the orchestrator's host.
+	ApplicationId *StoreSecret
+	ClientSecret  *StoreSecret
+	// AzureCloud is the Azure cloud the vault is in, such as "public" or "government". Empty uses the public cloud.
+	AzureCloud string
+	// PrivateEndpoint is the private endpoint to reach the vault through, if any.
+	PrivateEndpoint string
+	// SkuType and VaultRegion describe a vault to be created by the orchestrator, such as "standard" and "eastus".
+	SkuType     string
+	VaultRegion string
+	// ShortName is the short name the store type is installed with. Defaults to "AKV".
+	ShortName string
+}
+
+// ACMStoreArgs holds the arguments of NewACMStoreArgs, describing the certificates of an AWS account in a region, as
+// managed by the AWS Certificate Manager orchestrator extension.
+type ACMStoreArgs struct {
+	// AgentId is the ID of the orchestrator that manages the certificates.
+	AgentId string
+	// AccountId is the 12 digit ID of the AWS account.
+	AccountId string
+	// Region is the AWS region, such as "us-east-1".
+	Region string
+	// RoleARN is the ARN of the IAM role to assume in the account, such as
+	// "arn:aws:iam::123456789012:role/KeyfactorACM".
+	RoleARN string
+	// ExternalId is passed when assuming the role, if the role's trust policy requires one.
+	ExternalId string
+	// AccessKeyId and SecretAccessKey are the credentials of the IAM user that assumes the role. Leave them nil to
+	// use the default credentials of the orchestrator's host.
+	AccessKeyId     *StoreSecret
+	SecretAccessKey *StoreSecret
+	// ShortName is the short name the store type is installed with. Defaults to "AWS-ACM-v3".
+	ShortName string
+}
+
+// GCPStoreArgs holds the arguments of NewGCPStoreArgs, describing a Google Cloud Certificate Manager location managed
+// by the GCP Certificate Manager orchestrator extension.
+type GCPStoreArgs struct {
+	// AgentId is the ID of the orchestrator that manages the certificates.
+	AgentId   string
+	ProjectId string
+	// ProjectNumber is the number of the project, required by some versions of the extension.
+	ProjectNumber string
+	// Location is the Certificate Manager location, such as "us-central1". Defaults to "global".
+	Location string
+	// ServiceAccountKey is the JSON key of the service account used to access the project. Leave it nil to use the
+	// application default credentials of the orchestrator's host.
+	ServiceAccountKey *StoreSecret
+	// ShortName is the short name the store type is installed with. Defaults to "GcpCertMgr".
+	ShortName string
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClient_NewCloudStoreArgs(t *testing.T) {
+	const agentId = "3a1c9a42-5b1e-4d2f-9f0a-7e6b5c4d3e2f"
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch strings.TrimPrefix(r.URL.Path, "/KeyfactorAPI/CertificateStoreTypes/Name/") {
+		case "AKV":
+			w.Write([]byte(`[{"StoreType": 110, "ShortName": "AKV", "Properties": [
+				{"Name": "TenantId", "Type": "String"},
+				{"Name": "AzureCloud", "Type": "MultipleChoice", "DefaultValue": "public"},
+				{"Name": "PrivateEndpoint", "Type": "String"},
+				{"Name": "ServerUsername", "Type": "Secret"},
+				{"Name": "ServerPassword", "Type": "Secret"}]}]`))
+		case "AWS-ACM-v3":
+			w.Write([]byte(`[{"StoreType": 120, "ShortName": "AWS-ACM-v3", "Properties": [
+				{"Name": "UseDefaultSdkAuth", "Type": "Bool", "Required": true},
+				{"Name": "DefaultSdkAssumeRole", "Type": "String"},
+				{"Name": "UseIAM", "Type": "Bool", "Required": true},
+				{"Name": "IAMAssumeRole", "Type": "String"},
+				{"Name": "ServerUsername", "Type": "Secret"},
+				{"Name": "ServerPassword", "Type": "Secret"}]}]`))
+		case "GcpCertMgr":
+			w.Write([]byte(`[{"StoreType": 130, "ShortName": "GcpCertMgr", "Properties": [
+				{"Name": "Location", "Type": "String", "Required": true},
+				{"Name": "ProjectNumber", "Type": "String", "Required": true},
+				{"Name": "ServiceAccountKey", "Type": "Secret"}]}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	t.Run("AKV", func(t *testing.T) {
+		got, err := c.NewAKVStoreArgs(&AKVStoreArgs{
+			AgentId: agentId, TenantId: "tenant", SubscriptionId: "sub", ResourceGroupName: "rg", VaultName: "kv-prod",
+			ApplicationId: &StoreSecret{SecretValue: "app"}, ClientSecret: &StoreSecret{SecretValue: "secret"},
+		})
+		if err != nil {
+			t.Fatalf("NewAKVStoreArgs() error = %v", err)
+		}
+		want := &CreateStoreFctArgs{
+			ClientMachine: "tenant", StorePath: "sub:rg:kv-prod", CertStoreType: 110, AgentId: agentId,
+			Properties: map[string]interface{}{
+				"TenantId": "tenant", "ServerUsername": StoreSecret{SecretValue: "app"}, "ServerPassword": StoreSecret{SecretValue: "secret"},
+			},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("NewAKVStoreArgs() = %+v, want %+v", got, want)
+		}
+
+		if _, err := c.NewAKVStoreArgs(&AKVStoreArgs{
+			AgentId: agentId, TenantId: "tenant", SubscriptionId: "sub", ResourceGroupName: "rg", VaultName: "kv", SkuType: "premium",
+		}); err == nil || !strings.Contains(err.Error(), "SkuType") {
+			t.Errorf("NewAKVStoreArgs() with a property the store type does not define: error = %v", err)
+		}
+	})
+
+	t.Run("ACM", func(t *testing.T) {
+		got, err := c.NewACMStoreArgs(&ACMStoreArgs{
+			AgentId: agentId, AccountId: "123456789012", Region: "us-east-1", RoleARN: "arn:aws:iam::123456789012:role/KeyfactorACM",
+		})
+		if err != nil {
+			t.Fatalf("NewACMStoreArgs() error = %v", err)
+		}
+		wantProps := map[string]interface{}{"UseDefaultSdkAuth": "true", "DefaultSdkAssumeRole": "arn:aws:iam::123456789012:role/KeyfactorACM"}
+		if got.ClientMachine != "123456789012" || got.StorePath != "us-east-1" || got.CertStoreType != 120 || !reflect.DeepEqual(got.Properties, wantProps) {
+			t.Errorf("NewACMStoreArgs() = %+v", got)
+		}
+
+		if _, err := c.NewACMStoreArgs(&ACMStoreArgs{AgentId: agentId, AccountId: "123456789012", Region: "us-east-1", RoleARN: "KeyfactorACM"}); err == nil {
+			t.Errorf("NewACMStoreArgs() with an invalid role ARN succeeded, want error")
+		}
+	})
+
+	t.Run("GCP", func(t *testing.T) {
+		if _, err := c.NewGCPStoreArgs(&GCPStoreArgs{AgentId: agentId, ProjectId: "pki-prod"}); err == nil || !strings.Contains(err.Error(), "ProjectNumber") {
+			t.Errorf("NewGCPStoreArgs() without a required property: error = %v", err)
+		}
+		got, err := c.NewGCPStoreArgs(&GCPStoreArgs{AgentId: agentId, ProjectId: "pki-prod", ProjectNumber: "4815162342"})
+		if err != nil {
+			t.Fatalf("NewGCPStoreArgs() error = %v", err)
+		}
+		wantProps := map[string]interface{}{"Location": "global", "ProjectNumber": "4815162342"}
+		if got.ClientMachine != "pki-prod" || got.StorePath != "global" || !reflect.DeepEqual(got.Properties, wantProps) {
+			t.Errorf("NewGCPStoreArgs() = %+v", got)
+		}
+	})
+}
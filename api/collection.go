@@ -0,0 +1,30 @@
+package api
+
+import "context"
+
+type collectionKey struct{}
+
+// WithCollection returns a copy of ctx that scopes the certificate calls using it to the certificate collection with
+// the given ID. Keyfactor checks a collection-scoped request against the permissions granted on that collection, so
+// API accounts that are only allowed to see a collection, rather than all certificates, need this to get, search,
+// download, or revoke anything:
+//
+//	cert, err := client.GetCertificateContextContext(api.WithCollection(ctx, 3), args)
+//
+// An explicit collection ID passed in a call's arguments takes precedence. It only applies to the methods taking a
+// context.
+func WithCollection(ctx context.Context, id int) context.Context {
+	return context.WithValue(ctx, collectionKey{}, id)
+}
+
+// collectionFromContext returns the collection ID set on ctx with WithCollection, or zero if there is none.
+func collectionFromContext(ctx context.Context) int {
+	if ctx == nil {
+		return 0
+	}
+	id, _ := ctx.Value(collectionKey{}).(int)
+	if id < 0 {
+		return 0
+	}
+	return id
+}
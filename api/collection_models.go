@@ -0,0 +1,47 @@
+package api
+
+// Permissions a role can be granted on a certificate collection.
+const (
+	CollectionPermissionRead             = "Read"
+	CollectionPermissionEditMetadata     = "EditMetadata"
+	CollectionPermissionRecover          = "Recover"
+	CollectionPermissionRevoke           = "Revoke"
+	CollectionPermissionDelete           = "Delete"
+	CollectionPermissionImportPrivateKey = "ImportPrivateKey"
+)
+
+// CertificateCollection holds the response data returned by /CertificateCollections
+type CertificateCollection struct {
+	Id          int    `json:"Id"`
+	Name        string `json:"Name"`
+	Description string `json:"Description,omitempty"`
+}
+
+// RoleCollectionPermissions holds the permissions a security role has on a certificate collection, as returned by
+// /Security/Roles/{id}/Permissions/Collections
+type RoleCollectionPermissions struct {
+	CollectionId int      `json:"CollectionId"`
+	Permissions  []string `json:"Permissions"`
+}
+
+// CollectionRolePermissions holds the permissions of a security role on a certificate collection, as set with
+// /CertificateCollections/{id}/Permissions
+type CollectionRolePermissions struct {
+	RoleId      int      `json:"RoleId"`
+	Permissions []string `json:"Permissions"`
+}
+
+// CollectionPermissionMatrix holds the permissions of security roles on certificate collections, keyed by role name and
+// then collection name, e.g. as loaded from a YAML file:
+//
+//	PKI Administrators:
+//	  Web Servers: [Read, Revoke]
+//	Auditors:
+//	  Web Servers: [Read]
+type CollectionPermissionMatrix map[string]map[string][]string
+
+// CollectionPermissionChanges lists the certificate collections whose role permissions were set by
+// SyncCollectionPermissions.
+type CollectionPermissionChanges struct {
+	Collections []string
+}
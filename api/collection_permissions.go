@@ -0,0 +1,316 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ListCertificateCollections returns the certificate collections defined in Keyfactor.
+func (c *Client) ListCertificateCollections() ([]CertificateCollection, error) {
+	return c.ListCertificateCollectionsContext(context.Background())
+}
+
+// ListCertificateCollectionsContext is like ListCertificateCollections but uses ctx for the request, allowing it to be
+// cancelled.
+func (c *Client) ListCertificateCollectionsContext(ctx context.Context) ([]CertificateCollection, error) {
+	c.infof("Listing Keyfactor certificate collections")
+
+	jsonResp := []CertificateCollection{}
+	err := c.sendCollectionRequest(&request{
+		Method:   "GET",
+		Endpoint: "CertificateCollections",
+		Context:  ctx,
+	}, &jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// GetRoleCollectionPermissions takes arguments for the ID of a security role to facilitate a call to Keyfactor that
+// returns the role's permissions on each certificate collection it has access to.
+func (c *Client) GetRoleCollectionPermissions(roleId int) ([]RoleCollectionPermissions, error) {
+	return c.GetRoleCollectionPermissionsContext(context.Background(), roleId)
+}
+
+// GetRoleCollectionPermissionsContext is like GetRoleCollectionPermissions but uses ctx for the request, allowing it
+// to be cancelled.
+func (c *Client) GetRoleCollectionPermissionsContext(ctx context.Context, roleId int) ([]RoleCollectionPermissions, error) {
+	c.infof("Getting collection permissions of Keyfactor security role with ID %d", roleId)
+
+	if roleId <= 0 {
+		return nil, errors.New("security role id is required to get collection permissions")
+	}
+	jsonResp := []RoleCollectionPermissions{}
+	err := c.sendCollectionRequest(&request{
+		Method:   "GET",
+		Endpoint: fmt.Sprintf("Security/Roles/%d/Permissions/Collections", roleId),
+		Context:  ctx,
+	}, &jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// SetCollectionPermissions takes arguments for the ID of a certificate collection and the permissions of security
+// roles on it to facilitate a call to Keyfactor that replaces the collection's role permissions. Roles that are not
+// listed lose their access to the collection.
+func (c *Client) SetCollectionPermissions(collectionId int, permissions []CollectionRolePermissions) error {
+	return c.SetCollectionPermissionsContext(context.Background(), collectionId, permissions)
+}
+
+// SetCollectionPermissionsContext is like SetCollectionPermissions but uses ctx for the request, allowing it to be
+// cancelled.
+func (c *Client) SetCollectionPermissionsContext(ctx context.Context, collectionId int, permissions []CollectionRolePermissions) error {
+	c.infof("Setting role permissions of Keyfactor certificate collection with ID %d", collectionId)
+
+	if collectionId <= 0 {
+		return errors.New("certificate collection id is required to set permissions")
+	}
+	payload := make([]CollectionRolePermissions, 0, len(permissions))
+	seen := make(map[int]bool, len(permissions))
+	for _, p := range permissions {
+		if p.RoleId <= 0 {
+			return fmt.Errorf("security role id is required to set permissions on certificate collection %d", collectionId)
+		}
+		if seen[p.RoleId] {
+			return fmt.Errorf("security role %d is given permissions on certificate collection %d more than once", p.RoleId, collectionId)
+		}
+		seen[p.RoleId] = true
+		payload = append(payload, CollectionRolePermissions{RoleId: p.RoleId, Permissions: canonicalCollectionPermissions(p.Permissions)})
+	}
+
+	return c.sendCollectionRequest(&request{
+		Method:   "POST",
+		Endpoint: fmt.Sprintf("CertificateCollections/%d/Permissions", collectionId),
+		Payload:  payload,
+		Context:  ctx,
+	}, nil)
+}
+
+// GetCollectionPermissionMatrix returns the permissions of every security role on the certificate collections, by role
+// and collection name. Roles without access to any collection are left out.
+func (c *Client) GetCollectionPermissionMatrix() (CollectionPermissionMatrix, error) {
+	return c.GetCollectionPermissionMatrixContext(context.Background())
+}
+
+// GetCollectionPermissionMatrixContext is like GetCollectionPermissionMatrix but uses ctx for the requests, allowing
+// it to be cancelled.
+func (c *Client) GetCollectionPermissionMatrixContext(ctx context.Context) (CollectionPermissionMatrix, error) {
+	s, err := c.collectionPermissionState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matrix := CollectionPermissionMatrix{}
+	for roleId, collections := range s.granted {
+		for collectionId, permissions := range collections {
+			if len(permissions) == 0 {
+				continue
+			}
+			roleName := s.roleNames[roleId]
+			if matrix[roleName] == nil {
+				matrix[roleName] = map[string][]string{}
+			}
+			matrix[roleName][s.collectionName(collectionId)] = permissions
+		}
+	}
+	return matrix, nil
+}
+
+// SyncCollectionPermissions sets the permissions of security roles on certificate collections to those of matrix,
+// only changing the collections whose permissions differ. The matrix is authoritative for the collections it names:
+// roles it does not give permissions on such a collection lose their access to it. Collections the matrix does not
+// name are left as they are. Role and collection names are matched case-insensitively, and every name must exist
+// before anything is changed.
+func (c *Client) SyncCollectionPermissions(matrix CollectionPermissionMatrix) (*CollectionPermissionChanges, error) {
+	return c.SyncCollectionPermissionsContext(context.Background(), matrix)
+}
+
+// SyncCollectionPermissionsContext is like SyncCollectionPermissions but uses ctx for the requests, allowing it to be
+// cancelled.
+func (c *Client) SyncCollectionPermissionsContext(ctx context.Context, matrix CollectionPermissionMatrix) (*CollectionPermissionChanges, error) {
+	s, err := c.collectionPermissionState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// wanted holds the role permissions of each collection the matrix names.
+	wanted := map[int]map[int][]string{}
+	for roleName, collections := range matrix {
+		roleId, ok := s.roleIds[strings.ToLower(roleName)]
+		if !ok {
+			return nil, fmt.Errorf("security role %s does not exist", roleName)
+		}
+		for collectionName, permissions := range collections {
+			collectionId, ok := s.collectionIds[strings.ToLower(collectionName)]
+			if !ok {
+				return nil, fmt.Errorf("certificate collection %s does not exist", collectionName)
+			}
+			if wanted[collectionId] == nil {
+				wanted[collectionId] = map[int][]string{}
+			}
+			if _, ok := wanted[collectionId][roleId]; ok {
+				return nil, fmt.Errorf("permissions of security role %s on certificate collection %s are given more than once", roleName, collectionName)
+			}
+			wanted[collectionId][roleId] = canonicalCollectionPermissions(permissions)
+		}
+	}
+
+	collectionIds := make([]int, 0, len(wanted))
+	for collectionId := range wanted {
+		collectionIds = append(collectionIds, collectionId)
+	}
+	sort.Slice(collectionIds, func(i, j int) bool {
+		return s.collectionName(collectionIds[i]) < s.collectionName(collectionIds[j])
+	})
+
+	changes := &CollectionPermissionChanges{}
+	for _, collectionId := range collectionIds {
+		var (
+			permissions []CollectionRolePermissions
+			changed     bool
+		)
+		for _, roleId := range s.roleIdsSorted() {
+			want := wanted[collectionId][roleId]
+			if !reflect.DeepEqual(want, s.granted[roleId][collectionId]) {
+				changed = true
+			}
+			if len(want) > 0 {
+				permissions = append(permissions, CollectionRolePermissions{RoleId: roleId, Permissions: want})
+			}
+		}
+		if !changed {
+			continue
+		}
+		if err := c.SetCollectionPermissionsContext(ctx, collectionId, permissions); err != nil {
+			return changes, err
+		}
+		changes.Collections = append(changes.Collections, s.collectionName(collectionId))
+	}
+	return changes, nil
+}
+
+// collectionPermissionState holds the security roles and certificate collections of Keyfactor and the permissions of
+// each role on each collection.
+type collectionPermissionState struct {
+	roleNames       map[int]string
+	roleIds         map[string]int
+	collectionNames map[int]string
+	collectionIds   map[string]int
+	// granted holds the canonical permissions of each role, by role and collection ID.
+	granted map[int]map[int][]string
+}
+
+// collectionPermissionState reads the roles, collections, and collection permissions of every role.
+func (c *Client) collectionPermissionState(ctx context.Context) (*collectionPermissionState, error) {
+	var roles GetSecurityRolesResponse
+	err := c.sendCollectionRequest(&request{
+		Method:   "GET",
+		Endpoint: "Security/Roles",
+		Context:  ctx,
+	}, &roles)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list security roles: %w", err)
+	}
+	collections, err := c.ListCertificateCollectionsContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list certificate collections: %w", err)
+	}
+
+	s := &collectionPermissionState{
+		roleNames:       make(map[int]string, len(roles)),
+		roleIds:         make(map[string]int, len(roles)),
+		collectionNames: make(map[int]string, len(collections)),
+		collectionIds:   make(map[string]int, len(collections)),
+		granted:         make(map[int]map[int][]string, len(roles)),
+	}
+	for _, collection := range collections {
+		s.collectionNames[collection.Id] = collection.Name
+		s.collectionIds[strings.ToLower(collection.Name)] = collection.Id
+	}
+	for _, role := range roles {
+		s.roleNames[role.ID] = role.Name
+		s.roleIds[strings.ToLower(role.Name)] = role.ID
+
+		permissions, err := c.GetRoleCollectionPermissionsContext(ctx, role.ID)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get collection permissions of security role %s: %w", role.Name, err)
+		}
+		s.granted[role.ID] = make(map[int][]string, len(permissions))
+		for _, p := range permissions {
+			s.granted[role.ID][p.CollectionId] = canonicalCollectionPermissions(p.Permissions)
+		}
+	}
+	return s, nil
+}
+
+// collectionName returns the name of a collection, or its ID if it was not listed.
+func (s *collectionPermissionState) collectionName(id int) string {
+	if name, ok := s.collectionNames[id]; ok {
+		return name
+	}
+	return strconv.Itoa(id)
+}
+
+// roleIdsSorted returns the IDs of the roles in ascending order, so permissions are sent in a stable order.
+func (s *collectionPermissionState) roleIdsSorted() []int {
+	ids := make([]int, 0, len(s.roleNames))
+	for id := range s.roleNames {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// canonicalCollectionPermissions returns permissions sorted and without duplicates, with the known permissions spelled
+// as Keyfactor spells them, so permission lists can be compared. It returns nil if there are none.
+func canonicalCollectionPermissions(permissions []string) []string {
+	known := []string{
+		CollectionPermissionRead, CollectionPermissionEditMetadata, CollectionPermissionRecover,
+		CollectionPermissionRevoke, CollectionPermissionDelete, CollectionPermissionImportPrivateKey,
+	}
+	seen := map[string]bool{}
+	var canonical []string
+	for _, p := range permissions {
+		p = strings.TrimSpace(p)
+		for _, k := range known {
+			if strings.EqualFold(p, k) {
+				p = k
+				break
+			}
+		}
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		canonical = append(canonical, p)
+	}
+	sort.Strings(canonical)
+	return canonical
+}
+
+// sendCollectionRequest sets the Keyfactor headers on req, sends it, and decodes the response into v unless v is nil.
+func (c *Client) sendCollectionRequest(req *request, v interface{}) error {
+	// Set Keyfactor-specific headers
+	req.Headers = &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	resp, err := c.sendRequest(req)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return nil
+	}
+	return c.decodeResponse(resp.Body, v)
+}
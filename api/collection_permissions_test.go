@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestClient_SyncCollectionPermissions(t *testing.T) {
+	var (
+		mu sync.Mutex
+		// granted holds the permissions of each role ID on each collection ID.
+		granted = map[int]map[int][]string{
+			1: {10: {"Read", "Revoke"}},
+			2: {10: {"Read"}, 11: {"Read"}},
+			3: {},
+		}
+		writes []string
+	)
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		roleId, isRole := pathId(r.URL.Path, "/KeyfactorAPI/Security/Roles/", "/Permissions/Collections")
+		collectionId, isCollection := pathId(r.URL.Path, "/KeyfactorAPI/CertificateCollections/", "/Permissions")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/KeyfactorAPI/Security/Roles":
+			w.Write([]byte(`[{"Id": 1, "Name": "PKI Administrators"}, {"Id": 2, "Name": "Auditors"}, {"Id": 3, "Name": "Web Team"}]`))
+		case r.Method == "GET" && r.URL.Path == "/KeyfactorAPI/CertificateCollections":
+			w.Write([]byte(`[{"Id": 10, "Name": "Web Servers"}, {"Id": 11, "Name": "Expiring"}, {"Id": 12, "Name": "Code Signing"}]`))
+		case r.Method == "GET" && isRole:
+			list := []RoleCollectionPermissions{}
+			for id, permissions := range granted[roleId] {
+				list = append(list, RoleCollectionPermissions{CollectionId: id, Permissions: permissions})
+			}
+			json.NewEncoder(w).Encode(list)
+		case r.Method == "POST" && isCollection:
+			var permissions []CollectionRolePermissions
+			json.NewDecoder(r.Body).Decode(&permissions)
+			for _, collections := range granted {
+				delete(collections, collectionId)
+			}
+			var roles []string
+			for _, p := range permissions {
+				granted[p.RoleId][collectionId] = p.Permissions
+				roles = append(roles, fmt.Sprintf("%d=%s", p.RoleId, strings.Join(p.Permissions, "+")))
+			}
+			writes = append(writes, fmt.Sprintf("%d: %s", collectionId, strings.Join(roles, " ")))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	matrix, err := c.GetCollectionPermissionMatrix()
+	if err != nil {
+		t.Fatalf("GetCollectionPermissionMatrix() error = %v", err)
+	}
+	want := CollectionPermissionMatrix{
+		"PKI Administrators": {"Web Servers": {"Read", "Revoke"}},
+		"Auditors":           {"Web Servers": {"Read"}, "Expiring": {"Read"}},
+	}
+	if !reflect.DeepEqual(matrix, want) {
+		t.Errorf("GetCollectionPermissionMatrix() = %v, want %v", matrix, want)
+	}
+
+	desired := CollectionPermissionMatrix{
+		"PKI Administrators": {"Web Servers": {"revoke", "read"}, "code signing": {"Read", "Delete"}},
+		"auditors":           {"Web Servers": {"Read"}},
+		"Web Team":           {"Web Servers": {"Read", "EditMetadata", "Read"}},
+	}
+	changes, err := c.SyncCollectionPermissions(desired)
+	if err != nil {
+		t.Fatalf("SyncCollectionPermissions() error = %v", err)
+	}
+	// Expiring is not in the matrix, so the Auditors keep their access to it.
+	wantWrites := []string{"12: 1=Delete+Read", "10: 1=Read+Revoke 2=Read 3=EditMetadata+Read"}
+	if !reflect.DeepEqual(changes.Collections, []string{"Code Signing", "Web Servers"}) || !reflect.DeepEqual(writes, wantWrites) {
+		t.Errorf("SyncCollectionPermissions() = %+v with writes %q, want %q", changes, writes, wantWrites)
+	}
+	if !reflect.DeepEqual(granted[2][11], []string{"Read"}) {
+		t.Errorf("SyncCollectionPermissions() changed a collection the matrix does not name: %v", granted[2])
+	}
+
+	writes = nil
+	if changes, err := c.SyncCollectionPermissions(desired); err != nil || len(changes.Collections) != 0 || len(writes) != 0 {
+		t.Errorf("second SyncCollectionPermissions() = %+v, %v with writes %q, want no changes", changes, err, writes)
+	}
+
+	for _, bad := range []CollectionPermissionMatrix{
+		{"Operators": {"Web Servers": {"Read"}}},
+		{"Auditors": {"Internal": {"Read"}}},
+	} {
+		if _, err := c.SyncCollectionPermissions(bad); err == nil || !strings.Contains(err.Error(), "does not exist") || len(writes) != 0 {
+			t.Errorf("SyncCollectionPermissions(%v) error = %v with writes %q, want an unknown name error", bad, err, writes)
+		}
+	}
+}
+
+// pathId returns the ID between prefix and suffix of path, if path has them.
+func pathId(path, prefix, suffix string) (int, bool) {
+	if !strings.HasPrefix(path, prefix) || !strings.HasSuffix(path, suffix) {
+		return 0, false
+	}
+	id, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(path, prefix), suffix))
+	return id, err == nil
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestWithCollection(t *testing.T) {
+	var lastPath, lastCollection string
+	var lastBody map[string]interface{}
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		lastPath = r.URL.Path
+		lastCollection = r.URL.Query().Get("collectionId")
+		lastBody = nil
+		if b, _ := io.ReadAll(r.Body); len(b) > 0 {
+			json.Unmarshal(b, &lastBody)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/KeyfactorAPI/Certificates/Revoke":
+			w.WriteHeader(http.StatusNoContent)
+		case "/KeyfactorAPI/Certificates":
+			w.Write([]byte(`[{"Id": 1}]`))
+		default:
+			w.Write([]byte(`{"Id": 1}`))
+		}
+	})
+	ctx := WithCollection(context.Background(), 3)
+	explicit := 7
+	includeFalse := false
+
+	tests := []struct {
+		name           string
+		call           func() error
+		wantPath       string
+		wantCollection string
+		wantBody       int
+	}{
+		{name: "Get", wantPath: "/KeyfactorAPI/Certificates/1", wantCollection: "3", call: func() error {
+			_, err := c.GetCertificateContextContext(ctx, &GetCertificateContextArgs{Id: 1, IncludeMetadata: &includeFalse, IncludeLocations: &includeFalse})
+			return err
+		}},
+		{name: "GetExplicit", wantPath: "/KeyfactorAPI/Certificates/1", wantCollection: "7", call: func() error {
+			_, err := c.GetCertificateContextContext(ctx, &GetCertificateContextArgs{Id: 1, IncludeMetadata: &includeFalse, IncludeLocations: &includeFalse, CollectionId: &explicit})
+			return err
+		}},
+		{name: "List", wantPath: "/KeyfactorAPI/Certificates", wantCollection: "3", call: func() error {
+			_, err := c.ListCertificatesContext(ctx, map[string]string{"subject": "www.example.com"})
+			return err
+		}},
+		{name: "Search", wantPath: "/KeyfactorAPI/Certificates", wantCollection: "3", call: func() error {
+			return c.SearchCertificatePagesContext(ctx, "", &SearchCertificatesOptions{PageSize: 10}, func([]GetCertificateResponse) error { return nil })
+		}},
+		{name: "SearchExplicit", wantPath: "/KeyfactorAPI/Certificates", wantCollection: "7", call: func() error {
+			return c.SearchCertificatePagesContext(ctx, "", &SearchCertificatesOptions{PageSize: 10, CollectionId: 7}, func([]GetCertificateResponse) error { return nil })
+		}},
+		{name: "SearchUnscoped", wantPath: "/KeyfactorAPI/Certificates", call: func() error {
+			return c.SearchCertificatePages("", &SearchCertificatesOptions{PageSize: 10}, func([]GetCertificateResponse) error { return nil })
+		}},
+		{name: "Revoke", wantPath: "/KeyfactorAPI/Certificates/Revoke", wantBody: 3, call: func() error {
+			return c.RevokeCertContext(ctx, &RevokeCertArgs{CertificateIds: []int{1}, Comment: "test", EffectiveDate: "2024-01-01T00:00:00Z"})
+		}},
+		{name: "RevokeExplicit", wantPath: "/KeyfactorAPI/Certificates/Revoke", wantBody: 7, call: func() error {
+			return c.RevokeCertContext(ctx, &RevokeCertArgs{CertificateIds: []int{1}, Comment: "test", EffectiveDate: "2024-01-01T00:00:00Z", CollectionId: 7})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if lastPath != tt.wantPath {
+				t.Errorf("path = %s, want %s", lastPath, tt.wantPath)
+			}
+			if lastCollection != tt.wantCollection {
+				t.Errorf("collectionId = %q, want %q", lastCollection, tt.wantCollection)
+			}
+			if tt.wantBody > 0 {
+				if got, _ := lastBody["CollectionId"].(float64); int(got) != tt.wantBody {
+					t.Errorf("body CollectionId = %v, want %s", lastBody["CollectionId"], strconv.Itoa(tt.wantBody))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// compressionTransport is an http.RoundTripper that asks Keyfactor for gzip compressed responses and decompresses
+// them before they reach the rest of the client, so that large searches, audit logs, and exports cross the network
+// compressed whatever transport the client was given. Requests that set their own Accept-Encoding are passed through
+// unchanged. If disabled, responses are requested uncompressed.
+type compressionTransport struct {
+	base     http.RoundTripper
+	disabled bool
+}
+
+func (t *compressionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") != "" {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	if t.disabled {
+		req.Header.Set("Accept-Encoding", "identity")
+		return t.base.RoundTrip(req)
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") || req.Method == http.MethodHead ||
+		resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified {
+		return resp, err
+	}
+
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		if err == io.EOF {
+			// An empty body, sent with a gzip Content-Encoding by some proxies.
+			resp.Body = http.NoBody
+			return resp, nil
+		}
+		return nil, err
+	}
+	resp.Body = &gzipBody{Reader: zr, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// gzipBody decompresses a response body, closing the underlying body when closed.
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b *gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
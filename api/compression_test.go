@@ -0,0 +1,55 @@
+package api
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClient_Compression(t *testing.T) {
+	tests := []struct {
+		name         string
+		disabled     bool
+		wantEncoding string
+	}{
+		{name: "Gzip", wantEncoding: "gzip"},
+		{name: "Disabled", disabled: true, wantEncoding: "identity"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotEncoding string
+			c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				gotEncoding = r.Header.Get("Accept-Encoding")
+				w.Header().Set("Content-Type", "application/json")
+				body := `[{"Id": 1, "Thumbprint": "` + strings.Repeat("AB", 20) + `"}]`
+				if !strings.Contains(gotEncoding, "gzip") {
+					w.Write([]byte(body))
+					return
+				}
+				w.Header().Set("Content-Encoding", "gzip")
+				zw := gzip.NewWriter(w)
+				zw.Write([]byte(body))
+				zw.Close()
+			})
+			c.disableCompression = tt.disabled
+
+			resp, err := c.sendRequest(&request{Method: "GET", Endpoint: "Certificates", Headers: &apiHeaders{}})
+			if err != nil {
+				t.Fatalf("sendRequest() error = %v", err)
+			}
+			defer resp.Body.Close()
+			if gotEncoding != tt.wantEncoding {
+				t.Errorf("Accept-Encoding = %q, want %q", gotEncoding, tt.wantEncoding)
+			}
+			if enc := resp.Header.Get("Content-Encoding"); enc != "" {
+				t.Errorf("Content-Encoding = %q, want the body decompressed", enc)
+			}
+			var certs []GetCertificateResponse
+			if err := json.NewDecoder(resp.Body).Decode(&certs); err != nil || len(certs) != 1 || certs[0].Id != 1 {
+				t.Errorf("decoded %+v, %v", certs, err)
+			}
+		})
+	}
+}
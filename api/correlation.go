@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CorrelationIDHeader is the request header carrying the correlation ID of each call to Keyfactor.
+const CorrelationIDHeader = "x-correlation-id"
+
+// activityIdHeaders lists the response headers Keyfactor uses to report the ID of the server-side activity that
+// handled a request, which identifies its entries in the Command logs.
+var activityIdHeaders = []string{"x-keyfactor-activity-id", "activity-id"}
+
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a copy of ctx that sends id as the correlation ID of every request made with it, e.g. to
+// tie the calls made by one Terraform apply together. Without it, each request gets a new random ID.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationIDFromContext returns the correlation ID set on ctx with WithCorrelationID, or "" if there is none.
+func CorrelationIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// correlationID returns the correlation ID to send with a request made with ctx.
+func correlationID(ctx context.Context) string {
+	if id := CorrelationIDFromContext(ctx); id != "" {
+		return id
+	}
+	return newCorrelationID()
+}
+
+// newCorrelationID returns a random version 4 UUID.
+func newCorrelationID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// correlationTransport is an http.RoundTripper that adds a correlation ID to requests that do not carry one, such as
+// those made through the SDK.
+type correlationTransport struct {
+	base http.RoundTripper
+}
+
+func (t *correlationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get(CorrelationIDHeader) == "" {
+		if id := correlationID(req.Context()); id != "" {
+			req = req.Clone(req.Context())
+			req.Header.Set(CorrelationIDHeader, id)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// RequestError is returned when Keyfactor responds to a request with an error status. It carries the IDs needed to
+// find the request in the Command logs when raising a support case.
+type RequestError struct {
+	StatusCode int
+	Message    string
+	// CorrelationID is the correlation ID sent with the request.
+	CorrelationID string
+	// ActivityID is the server-side activity ID reported in the response, if any.
+	ActivityID string
+}
+
+func (e *RequestError) Error() string {
+	return e.Message + e.idSuffix()
+}
+
+// idSuffix returns the correlation and activity IDs of the request in parentheses, preceded by a space, or "" if there
+// are none.
+func (e *RequestError) idSuffix() string {
+	var ids []string
+	if e.CorrelationID != "" {
+		ids = append(ids, "correlation ID "+e.CorrelationID)
+	}
+	if e.ActivityID != "" {
+		ids = append(ids, "activity ID "+e.ActivityID)
+	}
+	if len(ids) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", strings.Join(ids, ", "))
+}
+
+// responseActivityID returns the activity ID reported in the headers of resp or in its decoded error body.
+func responseActivityID(resp *http.Response, body map[string]interface{}) string {
+	for _, h := range activityIdHeaders {
+		if id := resp.Header.Get(h); id != "" {
+			return id
+		}
+	}
+	for k, v := range body {
+		if strings.EqualFold(k, "ActivityId") {
+			if id, ok := v.(string); ok {
+				return id
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClient_CorrelationID(t *testing.T) {
+	var sent []string
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Header.Get(CorrelationIDHeader))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/KeyfactorAPI/Bad":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ErrorCode": "0xA0110002", "Message": "Invalid request.", "ActivityId": "7c1f2e9a"}`))
+		case "/KeyfactorAPI/Gone":
+			w.Header().Set("x-keyfactor-activity-id", "5d0e4b11")
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.Write([]byte(`{}`))
+		}
+	})
+	get := func(ctx context.Context, endpoint string) error {
+		_, err := c.sendRequest(&request{Method: "GET", Endpoint: endpoint, Headers: &apiHeaders{}, Context: ctx})
+		return err
+	}
+
+	ctx := WithCorrelationID(context.Background(), "tf-apply-42")
+	err := get(ctx, "Bad")
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("error = %v, want *RequestError", err)
+	}
+	if reqErr.StatusCode != http.StatusBadRequest || reqErr.CorrelationID != "tf-apply-42" || reqErr.ActivityID != "7c1f2e9a" {
+		t.Errorf("error = %+v", reqErr)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "Invalid request.") || !strings.Contains(msg, "correlation ID tf-apply-42") ||
+		!strings.Contains(msg, "activity ID 7c1f2e9a") {
+		t.Errorf("error message = %q, want the message and both IDs", msg)
+	}
+	if sent[0] != "tf-apply-42" {
+		t.Errorf("sent correlation ID %q, want tf-apply-42", sent[0])
+	}
+
+	if err := get(context.Background(), "Gone"); !errors.As(err, &reqErr) || reqErr.ActivityID != "5d0e4b11" {
+		t.Errorf("error = %v, want activity ID from header", err)
+	}
+
+	captureCtx, capture := WithResponseCapture(context.Background())
+	if err := get(captureCtx, "Ok"); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	if len(sent) != 3 || sent[1] == "" || sent[1] == sent[2] {
+		t.Errorf("sent correlation IDs %q, want a new ID per request", sent)
+	}
+	if m := capture.Last(); m == nil || m.CorrelationID != sent[2] {
+		t.Errorf("captured metadata %+v, want correlation ID %s", m, sent[2])
+	}
+}
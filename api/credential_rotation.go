@@ -0,0 +1,373 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Defaults of CredentialRotationOptions.
+const (
+	DefaultCredentialVerifyTimeout  = 2 * time.Minute
+	DefaultCredentialVerifyInterval = 5 * time.Second
+)
+
+// generatedSecretLength is the length of the secrets generated by RotateCredentials.
+const generatedSecretLength = 32
+
+// CredentialRotator sets a new secret for the identity the client authenticates as, at the directory or identity
+// provider that owns it, such as the Active Directory password of a service account or the secret of an OAuth client.
+// Rotators are normally built with admin credentials for that directory. Rotate returns the secret in effect, which
+// is secret unless the provider generates secrets itself.
+type CredentialRotator interface {
+	Rotate(ctx context.Context, secret string) (string, error)
+}
+
+// CredentialRotatorFunc adapts a function to a CredentialRotator, e.g. one that resets a password over LDAP:
+//
+//	rotator := api.CredentialRotatorFunc(func(ctx context.Context, secret string) (string, error) {
+//		return secret, resetADPassword(ctx, adminConn, "svc-keyfactor", secret)
+//	})
+type CredentialRotatorFunc func(ctx context.Context, secret string) (string, error)
+
+// Rotate calls f(ctx, secret).
+func (f CredentialRotatorFunc) Rotate(ctx context.Context, secret string) (string, error) {
+	return f(ctx, secret)
+}
+
+// CredentialRotationOptions configures RotateCredentials.
+type CredentialRotationOptions struct {
+	// Rotator sets the new secret. It is required.
+	Rotator CredentialRotator
+	// NewSecret is the password or client secret to rotate to. A random secret is generated if it is empty.
+	NewSecret string
+	// VerifyTimeout is how long Keyfactor is retried with the new secret before giving up, allowing for directory
+	// replication. Defaults to DefaultCredentialVerifyTimeout.
+	VerifyTimeout time.Duration
+	// VerifyInterval is the time between attempts. Defaults to DefaultCredentialVerifyInterval.
+	VerifyInterval time.Duration
+}
+
+// ErrCredentialNotVerified is returned by RotateCredentials when the new secret was set but Keyfactor did not accept
+// it before VerifyTimeout. The client keeps using the old secret.
+var ErrCredentialNotVerified = errors.New("new credentials were not accepted by Keyfactor")
+
+// RotateCredentials rotates the secret the client authenticates with: the password of a client using basic
+// authentication, or the client secret of one using the OAuth client credentials flow. The new secret is set with
+// opts.Rotator, then Keyfactor is retried with it until it is accepted, and only then does the client switch to it, so
+// requests in flight and those sent in the meantime keep using the old secret. The new secret is returned so that it
+// can be stored, including with an error wrapping ErrCredentialNotVerified, as the secret has been changed by then.
+//
+// Clients authenticating with a fixed OAuth access token cannot be rotated. A proxy token configured with
+// AuthConfig.ProxyAuth is not affected.
+func (c *Client) RotateCredentials(ctx context.Context, opts *CredentialRotationOptions) (string, error) {
+	if opts == nil || opts.Rotator == nil {
+		return "", errors.New("a credential rotator is required to rotate credentials")
+	}
+	oauth := c.keyfactorOAuthTransport()
+	if oauth == nil && c.authorization() == "" {
+		return "", errors.New("client has no password or client secret to rotate")
+	}
+	if oauth != nil && oauth.config.AccessToken != "" {
+		return "", errors.New("client authenticates with a fixed OAuth access token, which cannot be rotated")
+	}
+
+	secret := opts.NewSecret
+	if secret == "" {
+		var err error
+		if secret, err = generateSecret(generatedSecretLength); err != nil {
+			return "", fmt.Errorf("unable to generate secret: %w", err)
+		}
+	}
+
+	c.infof("Rotating Keyfactor credentials of client for host %s", c.hostname)
+	secret, err := opts.Rotator.Rotate(ctx, secret)
+	if err != nil {
+		return "", fmt.Errorf("unable to rotate credentials: %w", err)
+	}
+	if secret == "" {
+		return "", errors.New("credential rotator returned an empty secret")
+	}
+
+	trial, err := c.withSecret(secret)
+	if err != nil {
+		return secret, err
+	}
+	if err := trial.verifyCredentials(ctx, opts); err != nil {
+		c.errorf("Keyfactor did not accept the rotated credentials: %v", err)
+		return secret, err
+	}
+
+	if oauth != nil {
+		oauth.setClientSecret(secret)
+	} else {
+		c.setAuthorization(trial.authorization())
+	}
+	c.infof("Rotated Keyfactor credentials of client for host %s", c.hostname)
+	return secret, nil
+}
+
+// verifyCredentials retries a request to Keyfactor until it succeeds or opts.VerifyTimeout elapses.
+func (c *Client) verifyCredentials(ctx context.Context, opts *CredentialRotationOptions) error {
+	timeout := opts.VerifyTimeout
+	if timeout <= 0 {
+		timeout = DefaultCredentialVerifyTimeout
+	}
+	interval := opts.VerifyInterval
+	if interval <= 0 {
+		interval = DefaultCredentialVerifyInterval
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		resp, err := c.sendRequest(&request{
+			Method:   "GET",
+			Endpoint: "Status/Endpoints",
+			Headers: &apiHeaders{
+				Headers: []StringTuple{
+					{"x-keyfactor-api-version", "1"},
+					{"x-keyfactor-requested-with", "APIClient"},
+				},
+			},
+			Context: ctx,
+		})
+		if err == nil {
+			resp.Body.Close()
+			return nil
+		}
+		c.debugf("Rotated credentials not yet accepted: %v", err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %v", ErrCredentialNotVerified, err)
+		case <-time.After(interval):
+		}
+	}
+}
+
+// withSecret returns a copy of the client that authenticates with secret instead of its current password or client
+// secret.
+func (c *Client) withSecret(secret string) (*Client, error) {
+	trial := &Client{hostname: c.hostname, httpClient: c.httpClient, apiPath: c.apiPath, username: c.username}
+	if c.keyfactorOAuthTransport() != nil {
+		hc := *c.httpClient
+		hc.Transport = withClientSecret(hc.Transport, secret)
+		trial.httpClient = &hc
+		return trial, nil
+	}
+
+	authReq := &http.Request{Header: http.Header{"Authorization": {c.authorization()}}}
+	username, _, ok := authReq.BasicAuth()
+	if !ok {
+		return nil, errors.New("client authorization is not basic authentication")
+	}
+	trial.basicAuthString = buildBasicAuthString(&AuthConfig{Username: username, Password: secret})
+	return trial, nil
+}
+
+// keyfactorOAuthTransport returns the transport that authenticates the client to Keyfactor with OAuth, or nil if the
+// client uses basic authentication.
+func (c *Client) keyfactorOAuthTransport() *oauthTransport {
+	if c.httpClient == nil {
+		return nil
+	}
+	t, _ := c.httpClient.Transport.(*oauthTransport)
+	for t != nil && t.header != "" {
+		t, _ = t.base.(*oauthTransport)
+	}
+	return t
+}
+
+// withClientSecret returns a copy of the transport chain rt in which the transport authenticating to Keyfactor with
+// OAuth uses secret. Proxy token transports are copied with their cached token.
+func withClientSecret(rt http.RoundTripper, secret string) http.RoundTripper {
+	t, ok := rt.(*oauthTransport)
+	if !ok {
+		return rt
+	}
+	if t.header != "" {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return &oauthTransport{
+			base:      withClientSecret(t.base, secret),
+			tokenBase: t.tokenBase,
+			config:    t.config,
+			header:    t.header,
+			token:     t.token,
+			expires:   t.expires,
+		}
+	}
+	config := t.config
+	config.ClientSecret = secret
+	return &oauthTransport{base: t.base, tokenBase: t.tokenBase, config: config}
+}
+
+// setClientSecret replaces the client secret of the transport and discards its cached token.
+func (t *oauthTransport) setClientSecret(secret string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.config.ClientSecret = secret
+	t.token = ""
+	t.expires = time.Time{}
+}
+
+// authorization returns the basic Authorization header of the client, or "" if it does not use basic authentication.
+func (c *Client) authorization() string {
+	c.authMu.RLock()
+	defer c.authMu.RUnlock()
+	return c.basicAuthString
+}
+
+func (c *Client) setAuthorization(authz string) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+	c.basicAuthString = authz
+}
+
+// basicAuthTransport is an http.RoundTripper that replaces the basic authorization of a request with the client's
+// current one, so that requests sent by the SDK, which keeps the credentials the client was created with, pick up
+// rotated credentials.
+type basicAuthTransport struct {
+	base   http.RoundTripper
+	client *Client
+}
+
+func (t *basicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	authz := t.client.authorization()
+	if authz != "" && strings.HasPrefix(req.Header.Get("Authorization"), "Basic ") && req.Header.Get("Authorization") != authz {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", authz)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// secretAlphabets are the character classes of generated secrets. Each is used at least once so that the secrets meet
+// the complexity requirements of Active Directory and most identity providers.
+var secretAlphabets = []string{
+	"ABCDEFGHJKLMNPQRSTUVWXYZ",
+	"abcdefghijkmnopqrstuvwxyz",
+	"23456789",
+	"!#%+-.:=?@_~",
+}
+
+// generateSecret returns a random secret of n characters, n being at least len(secretAlphabets).
+func generateSecret(n int) (string, error) {
+	all := strings.Join(secretAlphabets, "")
+	secret := make([]byte, n)
+	for i := range secret {
+		alphabet := all
+		if i < len(secretAlphabets) {
+			alphabet = secretAlphabets[i]
+		}
+		ch, err := randomChar(alphabet)
+		if err != nil {
+			return "", err
+		}
+		secret[i] = ch
+	}
+	// Shuffle so that the guaranteed characters are not always first.
+	for i := len(secret) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		secret[i], secret[j.Int64()] = secret[j.Int64()], secret[i]
+	}
+	return string(secret), nil
+}
+
+func randomChar(alphabet string) (byte, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+	if err != nil {
+		return 0, err
+	}
+	return alphabet[i.Int64()], nil
+}
+
+// KeycloakSecretRotator is a CredentialRotator for OAuth clients registered in Keycloak, which backs the Keyfactor
+// Identity Provider. It regenerates the client's secret with the Keycloak admin REST API; Keycloak chooses the new
+// secret, so the secret passed to Rotate is ignored.
+type KeycloakSecretRotator struct {
+	// BaseURL is the URL of the Keycloak server, e.g. "https://idp.example.com".
+	BaseURL string
+	Realm   string
+	// ClientID is the client_id of the OAuth client whose secret is rotated.
+	ClientID string
+	// Admin holds the client credentials of an admin client allowed to manage clients in the realm.
+	Admin OAuthConfig
+	// HTTPClient sends the requests to Keycloak. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Rotate regenerates the secret of the client and returns it.
+func (r *KeycloakSecretRotator) Rotate(ctx context.Context, _ string) (string, error) {
+	if r.BaseURL == "" || r.Realm == "" || r.ClientID == "" {
+		return "", errors.New("keycloak base url, realm, and client id are required to rotate a client secret")
+	}
+	if err := r.Admin.validate(); err != nil {
+		return "", fmt.Errorf("keycloak admin: %w", err)
+	}
+	base := http.DefaultTransport
+	if r.HTTPClient != nil && r.HTTPClient.Transport != nil {
+		base = r.HTTPClient.Transport
+	}
+	hc := &http.Client{Transport: &oauthTransport{base: base, config: r.Admin}}
+	clientsURL := fmt.Sprintf("%s/admin/realms/%s/clients", strings.TrimSuffix(r.BaseURL, "/"), url.PathEscape(r.Realm))
+
+	var clients []struct {
+		Id       string `json:"id"`
+		ClientId string `json:"clientId"`
+	}
+	if err := keycloakRequest(ctx, hc, http.MethodGet, clientsURL+"?clientId="+url.QueryEscape(r.ClientID), &clients); err != nil {
+		return "", err
+	}
+	var id string
+	for _, client := range clients {
+		if client.ClientId == r.ClientID {
+			id = client.Id
+		}
+	}
+	if id == "" {
+		return "", fmt.Errorf("keycloak client %s not found in realm %s", r.ClientID, r.Realm)
+	}
+
+	var credential struct {
+		Value string `json:"value"`
+	}
+	if err := keycloakRequest(ctx, hc, http.MethodPost, clientsURL+"/"+url.PathEscape(id)+"/client-secret", &credential); err != nil {
+		return "", err
+	}
+	if credential.Value == "" {
+		return "", errors.New("keycloak returned an empty client secret")
+	}
+	return credential.Value, nil
+}
+
+// keycloakRequest sends a request to the Keycloak admin API and decodes the response body into v.
+func keycloakRequest(ctx context.Context, hc *http.Client, method, u string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("keycloak request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("keycloak %s %s returned status %d", method, req.URL.Path, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("unable to decode keycloak response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode"
+)
+
+func TestClient_RotateCredentials(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		accepted   = map[string]bool{"old-secret": true}
+		replicated = map[string]int{}
+	)
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, password, _ := r.BasicAuth()
+		mu.Lock()
+		defer mu.Unlock()
+		// New passwords are rejected once before they replicate.
+		if replicated[password] > 0 {
+			replicated[password]--
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if !accepted[password] {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	})
+	c.basicAuthString = buildBasicAuthString(&AuthConfig{Username: "svc-keyfactor", Domain: "EXAMPLE", Password: "old-secret"})
+
+	rotator := CredentialRotatorFunc(func(ctx context.Context, secret string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		accepted[secret] = true
+		replicated[secret] = 1
+		return secret, nil
+	})
+
+	secret, err := c.RotateCredentials(context.Background(), &CredentialRotationOptions{Rotator: rotator, VerifyInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("RotateCredentials() error = %v", err)
+	}
+	if len(secret) != generatedSecretLength {
+		t.Errorf("RotateCredentials() generated secret of length %d, want %d", len(secret), generatedSecretLength)
+	}
+	authReq := &http.Request{Header: http.Header{"Authorization": {c.authorization()}}}
+	if username, password, _ := authReq.BasicAuth(); username != `EXAMPLE\svc-keyfactor` || password != secret {
+		t.Errorf("client authenticates as %s:%s, want EXAMPLE\\svc-keyfactor:%s", username, password, secret)
+	}
+
+	// A secret Keyfactor never accepts leaves the client on the current one.
+	current := c.authorization()
+	never := CredentialRotatorFunc(func(ctx context.Context, secret string) (string, error) { return "unknown", nil })
+	secret, err = c.RotateCredentials(context.Background(), &CredentialRotationOptions{Rotator: never, VerifyTimeout: 20 * time.Millisecond, VerifyInterval: time.Millisecond})
+	if !errors.Is(err, ErrCredentialNotVerified) || secret != "unknown" {
+		t.Errorf("RotateCredentials() = %q, %v, want unknown, ErrCredentialNotVerified", secret, err)
+	}
+	if c.authorization() != current {
+		t.Errorf("client credentials changed after failed verification")
+	}
+
+	failing := CredentialRotatorFunc(func(ctx context.Context, secret string) (string, error) { return "", errors.New("access denied") })
+	if _, err := c.RotateCredentials(context.Background(), &CredentialRotationOptions{Rotator: failing}); err == nil {
+		t.Errorf("RotateCredentials() with a failing rotator succeeded, want error")
+	}
+	if _, err := c.RotateCredentials(context.Background(), nil); err == nil {
+		t.Errorf("RotateCredentials(nil) succeeded, want error")
+	}
+}
+
+func TestClient_RotateCredentialsKeycloak(t *testing.T) {
+	var (
+		mu           sync.Mutex
+		clientSecret = "old-secret"
+		tokens       = map[string]string{}
+	)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/realms/command/protocol/openid-connect/token":
+			r.ParseForm()
+			id, secret := r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+			if (id != "admin-cli" || secret != "admin-secret") && (id != "svc-keyfactor" || secret != clientSecret) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			token := id + "-" + secret
+			tokens[token] = id
+			w.Write([]byte(`{"access_token": "` + token + `", "expires_in": 300}`))
+		case strings.HasPrefix(r.URL.Path, "/admin/realms/command/clients"):
+			if tokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")] != "admin-cli" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			if r.Method == http.MethodPost && r.URL.Path == "/admin/realms/command/clients/0b5c6a1e/client-secret" {
+				clientSecret = "new-secret"
+				w.Write([]byte(`{"type": "secret", "value": "new-secret"}`))
+				return
+			}
+			w.Write([]byte(`[{"id": "0b5c6a1e", "clientId": "svc-keyfactor"}]`))
+		case strings.HasPrefix(r.URL.Path, "/KeyfactorAPI/"):
+			if r.Header.Get("Authorization") != "Bearer svc-keyfactor-"+clientSecret {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	tokenURL := srv.URL + "/realms/command/protocol/openid-connect/token"
+	c := &Client{
+		hostname: srv.URL,
+		apiPath:  "KeyfactorAPI",
+		httpClient: newAuthenticatedHTTPClient(&AuthConfig{
+			SkipVerify: true,
+			OAuth:      &OAuthConfig{ClientID: "svc-keyfactor", ClientSecret: "old-secret", TokenURL: tokenURL},
+		}),
+	}
+	if _, err := c.SearchAuditLogs("", nil); err != nil {
+		t.Fatalf("SearchAuditLogs() before rotation error = %v", err)
+	}
+
+	rotator := &KeycloakSecretRotator{
+		BaseURL:    srv.URL,
+		Realm:      "command",
+		ClientID:   "svc-keyfactor",
+		Admin:      OAuthConfig{ClientID: "admin-cli", ClientSecret: "admin-secret", TokenURL: tokenURL},
+		HTTPClient: srv.Client(),
+	}
+	secret, err := c.RotateCredentials(context.Background(), &CredentialRotationOptions{Rotator: rotator, VerifyInterval: time.Millisecond})
+	if err != nil || secret != "new-secret" {
+		t.Fatalf("RotateCredentials() = %q, %v, want new-secret", secret, err)
+	}
+	if got := c.keyfactorOAuthTransport().config.ClientSecret; got != "new-secret" {
+		t.Errorf("client secret = %q, want new-secret", got)
+	}
+	if _, err := c.SearchAuditLogs("", nil); err != nil {
+		t.Errorf("SearchAuditLogs() after rotation error = %v", err)
+	}
+
+	fixed := &Client{httpClient: newAuthenticatedHTTPClient(&AuthConfig{OAuth: &OAuthConfig{AccessToken: "token"}})}
+	if _, err := fixed.RotateCredentials(context.Background(), &CredentialRotationOptions{Rotator: rotator}); err == nil {
+		t.Errorf("RotateCredentials() of a fixed access token succeeded, want error")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		secret, err := generateSecret(generatedSecretLength)
+		if err != nil {
+			t.Fatalf("generateSecret() error = %v", err)
+		}
+		if len(secret) != generatedSecretLength {
+			t.Fatalf("generateSecret() = %q, want %d characters", secret, generatedSecretLength)
+		}
+		var upper, lower, digit, symbol bool
+		for _, r := range secret {
+			switch {
+			case unicode.IsUpper(r):
+				upper = true
+			case unicode.IsLower(r):
+				lower = true
+			case unicode.IsDigit(r):
+				digit = true
+			default:
+				symbol = true
+			}
+		}
+		if !upper || !lower || !digit || !symbol {
+			t.Errorf("generateSecret() = %q, want every character class", secret)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package api
+
+// Keyfactor can generate a certificate signing request and hold its private key until the signed certificate comes
+// back, which suits CAs that Keyfactor cannot reach, such as an offline root:
+//
+//	pending, err := c.GenerateCSR(&api.GenerateCSRArgs{Subject: "CN=app.example.com", KeyType: "RSA", KeyLength: 2048})
+//	// Carry pending.CSR to the CA and have it signed, then:
+//	resp, err := c.CompletePendingCSR(pending.Id, signedPEM)
+//
+// Importing the signed certificate pairs it with the held private key and removes the CSR from the pending list.
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// GenerateCSR takes arguments for GenerateCSRArgs to facilitate a call to Keyfactor that generates a key pair and a
+// certificate signing request for it. The private key never leaves Keyfactor. The returned PendingCSR holds the CSR
+// to be signed externally and the ID to pass to CompletePendingCSR once it has been. Keyfactor does not return the ID
+// when generating the CSR, so it is looked up among the pending CSRs; if it cannot be found, Id is zero.
+func (c *Client) GenerateCSR(args *GenerateCSRArgs) (*PendingCSR, error) {
+	c.infof("Generating certificate signing request in Keyfactor")
+
+	if args == nil || args.Subject == "" {
+		return nil, errors.New("subject is required to generate a certificate signing request")
+	}
+	if args.KeyType == "" || args.KeyLength <= 0 {
+		return nil, errors.New("key type and key length are required to generate a certificate signing request")
+	}
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	body := &generateCSRBody{GenerateCSRArgs: args}
+	if sans := args.SANs.byType(); len(sans) > 0 {
+		body.SANs = sans
+	}
+	keyfactorAPIStruct := &request{
+		Method:   "POST",
+		Endpoint: "CSRGeneration/Generate",
+		Headers:  headers,
+		Payload:  body,
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonResp := &csrGenerationResponse{}
+	err = c.decodeResponse(resp.Body, jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	if jsonResp.CSR == "" {
+		return nil, errors.New("keyfactor did not return a certificate signing request")
+	}
+
+	pending, err := c.ListPendingCSRs()
+	if err != nil {
+		return nil, fmt.Errorf("certificate signing request was generated, but pending requests could not be listed: %w", err)
+	}
+	for i := range pending {
+		if sameCSR(pending[i].CSR, jsonResp.CSR) {
+			return &pending[i], nil
+		}
+	}
+	c.warnf("Generated certificate signing request was not found among pending requests")
+	return &PendingCSR{CSR: jsonResp.CSR}, nil
+}
+
+// ListPendingCSRs returns the certificate signing requests generated by Keyfactor that are waiting for a signed
+// certificate.
+func (c *Client) ListPendingCSRs() ([]PendingCSR, error) {
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "GET",
+		Endpoint: "CSRGeneration/Pending",
+		Headers:  headers,
+		Query:    &apiQuery{Query: []StringTuple{{"pq.returnLimit", "1000"}}},
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonResp []PendingCSR
+	err = c.decodeResponse(resp.Body, &jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// DownloadPendingCSR takes arguments for a pending CSR ID to facilitate a call to Keyfactor that returns the PEM
+// encoded certificate signing request.
+func (c *Client) DownloadPendingCSR(id int) (string, error) {
+	if id <= 0 {
+		return "", errors.New("pending CSR id is required")
+	}
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "GET",
+		Endpoint: fmt.Sprintf("CSRGeneration/Pending/%d", id),
+		Headers:  headers,
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return "", err
+	}
+
+	jsonResp := &csrGenerationResponse{}
+	err = c.decodeResponse(resp.Body, jsonResp)
+	if err != nil {
+		return "", err
+	}
+	if jsonResp.CSRText == "" {
+		return "", fmt.Errorf("keyfactor returned no certificate signing request for pending CSR %d", id)
+	}
+	return jsonResp.CSRText, nil
+}
+
+// DeletePendingCSR takes arguments for a pending CSR ID to facilitate a call to Keyfactor that discards the
+// certificate signing request along with its private key.
+func (c *Client) DeletePendingCSR(id int) error {
+	c.infof("Deleting pending CSR %d", id)
+	if id <= 0 {
+		return errors.New("pending CSR id is required")
+	}
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "DELETE",
+		Endpoint: fmt.Sprintf("CSRGeneration/Pending/%d", id),
+		Headers:  headers,
+	}
+
+	_, err := c.sendRequest(keyfactorAPIStruct)
+	return err
+}
+
+// CompletePendingCSR takes arguments for a pending CSR ID and the certificate issued for it, PEM or DER encoded, to
+// facilitate a call to Keyfactor that imports the certificate. Keyfactor pairs the imported certificate with the
+// private key it generated for the CSR. The certificate is first checked against the CSR, so that a certificate
+// signed for a different request is not imported in its place.
+func (c *Client) CompletePendingCSR(id int, certificate []byte) (*ImportCertificateResponse, error) {
+	c.infof("Completing pending CSR %d", id)
+
+	cert, err := parseCertificate(certificate)
+	if err != nil {
+		return nil, err
+	}
+	csrPEM, err := c.DownloadPendingCSR(id)
+	if err != nil {
+		return nil, err
+	}
+	csr, err := parseCSR(csrPEM)
+	if err != nil {
+		return nil, fmt.Errorf("pending CSR %d: %w", id, err)
+	}
+	if pub, ok := csr.PublicKey.(interface{ Equal(crypto.PublicKey) bool }); !ok || !pub.Equal(cert.PublicKey) {
+		return nil, fmt.Errorf("certificate %s was not issued for pending CSR %d: public keys differ", cert.Subject, id)
+	}
+
+	return c.ImportCertificate(&ImportCertificateArgs{Certificate: base64.StdEncoding.EncodeToString(cert.Raw)})
+}
+
+// parseCertificate parses a PEM or DER encoded certificate.
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+	cert, err := x509.ParseCertificate(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// parseCSR parses a certificate signing request returned by Keyfactor, which may be PEM encoded or bare base64.
+func parseCSR(s string) (*x509.CertificateRequest, error) {
+	var der []byte
+	if block, _ := pem.Decode([]byte(s)); block != nil {
+		der = block.Bytes
+	} else {
+		var err error
+		if der, err = base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), "")); err != nil {
+			return nil, fmt.Errorf("invalid certificate signing request: %w", err)
+		}
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate signing request: %w", err)
+	}
+	return csr, nil
+}
+
+// sameCSR reports whether a and b are the same certificate signing request, ignoring PEM armor and line breaks.
+func sameCSR(a, b string) bool {
+	ca, errA := parseCSR(a)
+	cb, errB := parseCSR(b)
+	return errA == nil && errB == nil && string(ca.Raw) == string(cb.Raw)
+}
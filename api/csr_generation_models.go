@@ -0,0 +1,38 @@
+package api
+
+// GenerateCSRArgs holds the function arguments used for calling the GenerateCSR method.
+type GenerateCSRArgs struct {
+	// Subject is the distinguished name of the request, e.g. "CN=app.example.com,O=Example".
+	Subject string `json:"Subject"`
+	// KeyType is "RSA" or "ECC".
+	KeyType string `json:"KeyType"`
+	// KeyLength is the key size in bits for RSA keys, or the curve size (256, 384, or 521) for ECC keys.
+	KeyLength int `json:"KeyLength"`
+	// Template is the short name of the certificate template the request is intended for, if any.
+	Template string `json:"Template,omitempty"`
+	// SANs are written into the request as the subjectAltName extension.
+	SANs *SANs `json:"-"`
+}
+
+// generateCSRBody is the request body sent to the Keyfactor CSR generation endpoint.
+type generateCSRBody struct {
+	*GenerateCSRArgs
+	SANs map[string][]string `json:"SANs,omitempty"`
+}
+
+// PendingCSR is a certificate signing request generated by Keyfactor whose private key is held by Keyfactor until
+// the signed certificate is imported.
+type PendingCSR struct {
+	Id int `json:"Id"`
+	// CSR is the PEM encoded certificate signing request.
+	CSR         string    `json:"CSR"`
+	RequestTime Timestamp `json:"RequestTime"`
+	Subject     []string  `json:"Subject"`
+}
+
+// csrGenerationResponse is the response returned by the Keyfactor CSR generation endpoints.
+type csrGenerationResponse struct {
+	CSR         string `json:"CSR"`
+	CSRText     string `json:"CSRText"`
+	CSRFilePath string `json:"CSRFilePath"`
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestCSR returns a PEM encoded CSR for a new key along with a PEM encoded certificate for the same key.
+func newTestCSR(t *testing.T, cn string) (string, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr, err := NewCSR(key, pkix.Name{CommonName: cn}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}, &x509.Certificate{Subject: pkix.Name{CommonName: "Offline Root"}}, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return csr, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestClient_PendingCSRFlow(t *testing.T) {
+	csr, signed := newTestCSR(t, "app.example.com")
+	otherCSR, otherSigned := newTestCSR(t, "other.example.com")
+	bare := func(s string) string {
+		block, _ := pem.Decode([]byte(s))
+		return base64.StdEncoding.EncodeToString(block.Bytes)
+	}
+
+	var generated map[string]interface{}
+	var imported *ImportCertificateArgs
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "POST /KeyfactorAPI/CSRGeneration/Generate":
+			json.NewDecoder(r.Body).Decode(&generated)
+			json.NewEncoder(w).Encode(map[string]string{"CSR": csr})
+		case "GET /KeyfactorAPI/CSRGeneration/Pending":
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"Id": 4, "CSR": bare(otherCSR), "RequestTime": "2024-05-01T10:00:00"},
+				{"Id": 5, "CSR": bare(csr), "RequestTime": "2024-05-01T11:00:00"},
+			})
+		case "GET /KeyfactorAPI/CSRGeneration/Pending/5":
+			json.NewEncoder(w).Encode(map[string]string{"CSRText": csr})
+		case "POST /KeyfactorAPI/Certificates/Import":
+			imported = &ImportCertificateArgs{}
+			json.NewDecoder(r.Body).Decode(imported)
+			w.Write([]byte(`{"ImportStatus": 1, "Thumbprint": "ABC123"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	pending, err := c.GenerateCSR(&GenerateCSRArgs{
+		Subject:   "CN=app.example.com",
+		KeyType:   "ECC",
+		KeyLength: 256,
+		SANs:      &SANs{DNS: []string{"app.example.com"}},
+	})
+	if err != nil {
+		t.Fatalf("GenerateCSR() error = %v", err)
+	}
+	if pending.Id != 5 || !pending.RequestTime.Valid() {
+		t.Errorf("GenerateCSR() = %+v, want pending CSR 5", pending)
+	}
+	if generated["KeyType"] != "ECC" || generated["KeyLength"] != float64(256) {
+		t.Errorf("GenerateCSR() sent %v", generated)
+	}
+	if sans, _ := generated["SANs"].(map[string]interface{}); len(sans) != 1 {
+		t.Errorf("GenerateCSR() sent SANs %v, want dns only", generated["SANs"])
+	}
+
+	if _, err := c.CompletePendingCSR(5, otherSigned); err == nil || !strings.Contains(err.Error(), "public keys differ") {
+		t.Errorf("CompletePendingCSR() with a certificate for another CSR error = %v, want mismatch", err)
+	}
+	if imported != nil {
+		t.Fatalf("CompletePendingCSR() imported a mismatched certificate")
+	}
+
+	resp, err := c.CompletePendingCSR(5, signed)
+	if err != nil {
+		t.Fatalf("CompletePendingCSR() error = %v", err)
+	}
+	if resp.Thumbprint != "ABC123" {
+		t.Errorf("CompletePendingCSR() = %+v", resp)
+	}
+	block, _ := pem.Decode(signed)
+	if imported == nil || imported.Certificate != base64.StdEncoding.EncodeToString(block.Bytes) {
+		t.Errorf("CompletePendingCSR() imported %+v, want the signed certificate", imported)
+	}
+}
+
+func TestClient_GenerateCSR_Validation(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	})
+	for _, args := range []*GenerateCSRArgs{nil, {KeyType: "RSA", KeyLength: 2048}, {Subject: "CN=x"}} {
+		if _, err := c.GenerateCSR(args); err == nil {
+			t.Errorf("GenerateCSR(%+v) succeeded, want error", args)
+		}
+	}
+}
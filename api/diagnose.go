@@ -0,0 +1,250 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// diagnosticChecks are the names of the checks run by Diagnose, in order.
+var diagnosticChecks = []string{
+	DiagnosticConnectivity, DiagnosticAuthentication, DiagnosticAPIPath, DiagnosticVersion, DiagnosticSecurityModel,
+	DiagnosticCertificatePermissions, DiagnosticStorePermissions, DiagnosticEnrollmentPermissions, DiagnosticLatency,
+}
+
+// Diagnose checks that the client is configured to work with Keyfactor and returns a report of the outcome of each
+// check: whether the server is reachable, the credentials are accepted, and the API path is right, which version and
+// security model the server has, whether the identity can read certificates, certificate stores, and the templates
+// enrollment needs, and how long requests take. Checks that depend on a failed check are skipped rather than run.
+// Only read requests are sent.
+//
+// An error is returned, along with the checks run so far, only if ctx is done before the checks are. Problems found
+// by the checks are reported in the report; use DiagnosticReport.OK to tell whether there are any.
+func (c *Client) Diagnose(ctx context.Context) (*DiagnosticReport, error) {
+	c.infof("Diagnosing Keyfactor configuration of host %s", c.hostname)
+
+	start := time.Now()
+	d := &diagnosis{
+		client: c,
+		ctx:    ctx,
+		report: &DiagnosticReport{Hostname: c.hostname, APIPath: c.apiPrefix()},
+	}
+	err := d.run()
+	d.report.Latency = d.latency()
+	if err == nil {
+		d.addLatencyCheck()
+	}
+	d.report.Duration = time.Since(start)
+	return d.report, err
+}
+
+// OK reports whether no check of the report failed. Warnings and skipped checks do not count as failures.
+func (r *DiagnosticReport) OK() bool {
+	for _, check := range r.Checks {
+		if check.Status == DiagnosticFail {
+			return false
+		}
+	}
+	return true
+}
+
+// Check returns the check named name, e.g. DiagnosticAuthentication, or nil if it is not in the report.
+func (r *DiagnosticReport) Check(name string) *DiagnosticCheck {
+	for i := range r.Checks {
+		if r.Checks[i].Name == name {
+			return &r.Checks[i]
+		}
+	}
+	return nil
+}
+
+// String formats the report for a terminal, one check per line, e.g.
+//
+//	[PASS] authentication: Credentials for DOMAIN\user were accepted
+func (r *DiagnosticReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Keyfactor %s (%s)\n", r.Hostname, r.APIPath)
+	for _, check := range r.Checks {
+		fmt.Fprintf(&b, "[%s] %s: %s\n", strings.ToUpper(string(check.Status)), check.Name, check.Message)
+	}
+	return b.String()
+}
+
+// diagnosis holds the state of a Diagnose call.
+type diagnosis struct {
+	client *Client
+	ctx    context.Context
+	report *DiagnosticReport
+	// latencies holds the time taken by the requests that got a response.
+	latencies []time.Duration
+}
+
+// run runs the checks up to the latency check, returning ctx's error if it is done before they are.
+func (d *diagnosis) run() error {
+	var endpoints []string
+	latency, status, err := d.get("Status/Endpoints", nil, &endpoints)
+	switch {
+	case status == 0:
+		if d.ctx.Err() != nil {
+			return d.ctx.Err()
+		}
+		d.add(DiagnosticConnectivity, DiagnosticFail, latency, err, "Unable to reach Keyfactor at %s: %v", d.client.hostname, err)
+		d.skipRest("Keyfactor cannot be reached")
+		return nil
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		d.add(DiagnosticConnectivity, DiagnosticPass, 0, nil, "Reached Keyfactor at %s", d.client.hostname)
+		d.add(DiagnosticAuthentication, DiagnosticFail, latency, err, "Credentials%s were rejected: %v", d.identity(), err)
+		d.skipRest("the credentials were rejected")
+		return nil
+	case err != nil:
+		d.add(DiagnosticConnectivity, DiagnosticPass, 0, nil, "Reached Keyfactor at %s", d.client.hostname)
+		d.add(DiagnosticAuthentication, DiagnosticSkip, 0, nil, "Credentials cannot be checked until the API path is right")
+		d.add(DiagnosticAPIPath, DiagnosticFail, latency, err, "No Keyfactor API found at %s: %v", d.report.APIPath, err)
+		d.skipRest("the Keyfactor API was not found")
+		return nil
+	}
+	d.add(DiagnosticConnectivity, DiagnosticPass, 0, nil, "Reached Keyfactor at %s", d.client.hostname)
+	d.add(DiagnosticAuthentication, DiagnosticPass, 0, nil, "Credentials%s were accepted", d.identity())
+	d.add(DiagnosticAPIPath, DiagnosticPass, latency, nil, "Found the Keyfactor API at %s, advertising %d endpoints", d.report.APIPath, len(endpoints))
+
+	license := &GetLicenseResponse{}
+	latency, status, err = d.get("License", nil, license)
+	switch {
+	case err == nil && license.KeyfactorVersion != "":
+		d.report.KeyfactorVersion = license.KeyfactorVersion
+		d.add(DiagnosticVersion, DiagnosticPass, latency, nil, "Keyfactor Command %s", license.KeyfactorVersion)
+	case err == nil:
+		d.add(DiagnosticVersion, DiagnosticWarn, latency, nil, "The license does not name the Keyfactor version")
+	case status == http.StatusForbidden:
+		d.add(DiagnosticVersion, DiagnosticWarn, latency, err, "The license, which names the Keyfactor version, cannot be read by the identity: %v", err)
+	default:
+		d.add(DiagnosticVersion, DiagnosticWarn, latency, err, "Unable to detect the Keyfactor version: %v", err)
+	}
+	if d.ctx.Err() != nil {
+		return d.ctx.Err()
+	}
+
+	d.report.SecurityModel = securityModelOf(endpoints)
+	if d.report.SecurityModel == SecurityModelClaims {
+		d.add(DiagnosticSecurityModel, DiagnosticPass, 0, nil, "Claims-based security API (Command 11 or later)")
+	} else {
+		d.add(DiagnosticSecurityModel, DiagnosticPass, 0, nil, "Legacy identity security API (before Command 11)")
+	}
+
+	probes := []struct {
+		name, endpoint, what string
+	}{
+		{DiagnosticCertificatePermissions, "Certificates", "certificates"},
+		{DiagnosticStorePermissions, "CertificateStores", "certificate stores"},
+		{DiagnosticEnrollmentPermissions, "Templates", "the certificate templates enrollment uses"},
+	}
+	for _, p := range probes {
+		latency, status, err := d.get(p.endpoint, (&Paging{PageReturned: 1, ReturnLimit: 1}).query(), nil)
+		switch {
+		case err == nil:
+			d.add(p.name, DiagnosticPass, latency, nil, "The identity can read %s", p.what)
+		case status == http.StatusUnauthorized || status == http.StatusForbidden:
+			d.add(p.name, DiagnosticFail, latency, err, "The identity cannot read %s: %v", p.what, err)
+		case status == 0 && d.ctx.Err() != nil:
+			return d.ctx.Err()
+		default:
+			d.add(p.name, DiagnosticWarn, latency, err, "Unable to check whether the identity can read %s: %v", p.what, err)
+		}
+	}
+	return nil
+}
+
+// get sends a GET request to endpoint and decodes the response into v, unless v is nil. It returns the time the
+// request took, the status code of the response, or 0 if there was none, and the error of the request.
+func (d *diagnosis) get(endpoint string, query []StringTuple, v interface{}) (time.Duration, int, error) {
+	req := &request{
+		Method:   "GET",
+		Endpoint: endpoint,
+		Headers: &apiHeaders{
+			Headers: []StringTuple{
+				{"x-keyfactor-api-version", "1"},
+				{"x-keyfactor-requested-with", "APIClient"},
+			},
+		},
+		Context: d.ctx,
+	}
+	if len(query) > 0 {
+		req.Query = &apiQuery{Query: query}
+	}
+
+	start := time.Now()
+	resp, err := d.client.sendRequest(req)
+	latency := time.Since(start)
+	if err != nil {
+		var reqErr *RequestError
+		if !errors.As(err, &reqErr) {
+			return latency, 0, err
+		}
+		d.latencies = append(d.latencies, latency)
+		return latency, reqErr.StatusCode, err
+	}
+	d.latencies = append(d.latencies, latency)
+	if v == nil {
+		discard(resp)
+		return latency, resp.StatusCode, nil
+	}
+	if err := d.client.decodeResponse(resp.Body, v); err != nil {
+		return latency, resp.StatusCode, fmt.Errorf("unable to decode the response of %s: %w", endpoint, err)
+	}
+	return latency, resp.StatusCode, nil
+}
+
+// add adds a check to the report. The correlation ID of err, if Keyfactor answered with an error, is kept with it.
+func (d *diagnosis) add(name string, status DiagnosticStatus, latency time.Duration, err error, format string, args ...interface{}) {
+	check := DiagnosticCheck{Name: name, Status: status, Message: fmt.Sprintf(format, args...), Latency: latency}
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) {
+		check.CorrelationID = reqErr.CorrelationID
+	}
+	d.report.Checks = append(d.report.Checks, check)
+}
+
+// skipRest adds the checks before the latency check that have not been run as skipped, because of reason.
+func (d *diagnosis) skipRest(reason string) {
+	for _, name := range diagnosticChecks {
+		if name != DiagnosticLatency && d.report.Check(name) == nil {
+			d.add(name, DiagnosticSkip, 0, nil, "Skipped because %s", reason)
+		}
+	}
+}
+
+// identity returns the username of the client, formatted to follow "Credentials", or "" if it has none, as with
+// OAuth.
+func (d *diagnosis) identity() string {
+	if d.client.username == "" {
+		return ""
+	}
+	return " for " + d.client.username
+}
+
+// latency summarizes the latencies of the requests, or returns nil if none got a response.
+func (d *diagnosis) latency() *DiagnosticLatencySummary {
+	if len(d.latencies) == 0 {
+		return nil
+	}
+	sorted := append([]time.Duration(nil), d.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return &DiagnosticLatencySummary{Min: sorted[0], Median: sorted[len(sorted)/2], Max: sorted[len(sorted)-1]}
+}
+
+// addLatencyCheck adds the latency check, which warns when the median latency exceeds DiagnosticSlowLatency.
+func (d *diagnosis) addLatencyCheck() {
+	l := d.report.Latency
+	switch {
+	case l == nil:
+		d.add(DiagnosticLatency, DiagnosticSkip, 0, nil, "Skipped because no request got a response")
+	case l.Median > DiagnosticSlowLatency:
+		d.add(DiagnosticLatency, DiagnosticWarn, 0, nil, "Requests are slow: median %s over %d requests (min %s, max %s)", l.Median, len(d.latencies), l.Min, l.Max)
+	default:
+		d.add(DiagnosticLatency, DiagnosticPass, 0, nil, "Median %s over %d requests (min %s, max %s)", l.Median, len(d.latencies), l.Min, l.Max)
+	}
+}
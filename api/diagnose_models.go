@@ -0,0 +1,71 @@
+package api
+
+import "time"
+
+// DiagnosticStatus is the outcome of a check run by Diagnose.
+type DiagnosticStatus string
+
+const (
+	// DiagnosticPass means the check succeeded.
+	DiagnosticPass DiagnosticStatus = "pass"
+	// DiagnosticWarn means the check found something that does not stop the client from working, such as a license
+	// the identity may not read or slow responses.
+	DiagnosticWarn DiagnosticStatus = "warn"
+	// DiagnosticFail means the check found a configuration or permission problem.
+	DiagnosticFail DiagnosticStatus = "fail"
+	// DiagnosticSkip means the check was not run because a check it depends on failed.
+	DiagnosticSkip DiagnosticStatus = "skip"
+)
+
+// Names of the checks run by Diagnose, in the order they are run.
+const (
+	DiagnosticConnectivity           = "connectivity"
+	DiagnosticAuthentication         = "authentication"
+	DiagnosticAPIPath                = "api_path"
+	DiagnosticVersion                = "version"
+	DiagnosticSecurityModel          = "security_model"
+	DiagnosticCertificatePermissions = "certificate_permissions"
+	DiagnosticStorePermissions       = "store_permissions"
+	DiagnosticEnrollmentPermissions  = "enrollment_permissions"
+	DiagnosticLatency                = "latency"
+)
+
+// DiagnosticSlowLatency is the median request latency above which the latency check of Diagnose warns.
+const DiagnosticSlowLatency = 2 * time.Second
+
+// DiagnosticReport is the result of Diagnose.
+type DiagnosticReport struct {
+	// Hostname and APIPath are where the client sends requests, e.g. "keyfactor.example.com" and "KeyfactorAPI/".
+	Hostname string `json:"hostname"`
+	APIPath  string `json:"api_path"`
+	// KeyfactorVersion is the version of Command, e.g. "12.4.0", if the license could be read.
+	KeyfactorVersion string `json:"keyfactor_version,omitempty"`
+	// SecurityModel is the security API the server exposes, if its endpoints could be listed.
+	SecurityModel SecurityModel `json:"security_model,omitempty"`
+	// Latency summarizes the time taken by the requests of the checks that got a response.
+	Latency *DiagnosticLatencySummary `json:"latency,omitempty"`
+	Checks  []DiagnosticCheck         `json:"checks"`
+	// Duration is the time Diagnose took.
+	Duration time.Duration `json:"duration"`
+}
+
+// DiagnosticCheck is the outcome of one check of a DiagnosticReport.
+type DiagnosticCheck struct {
+	// Name is one of the Diagnostic check names, e.g. DiagnosticAuthentication.
+	Name   string           `json:"name"`
+	Status DiagnosticStatus `json:"status"`
+	// Message describes the outcome, e.g. the version found or what to fix.
+	Message string `json:"message"`
+	// Latency is the time taken by the check's request, if it sent one.
+	Latency time.Duration `json:"latency,omitempty"`
+	// CorrelationID identifies the request of a check that Keyfactor answered with an error, to find it in the
+	// Command logs.
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// DiagnosticLatencySummary holds the fastest, median, and slowest request latency of a DiagnosticReport.
+type DiagnosticLatencySummary struct {
+	Min    time.Duration `json:"min"`
+	Median time.Duration `json:"median"`
+	Max    time.Duration `json:"max"`
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClient_Diagnose(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    map[string]DiagnosticStatus
+		wantOK  bool
+		version string
+		model   SecurityModel
+	}{
+		{
+			name: "StoresDenied",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/KeyfactorAPI/Status/Endpoints":
+					w.Write([]byte(`["GET /Certificates", "GET /Security/Claims"]`))
+				case "/KeyfactorAPI/License":
+					w.Write([]byte(`{"KeyfactorVersion": "12.4.0", "LicenseData": {}}`))
+				case "/KeyfactorAPI/Certificates", "/KeyfactorAPI/Templates":
+					if r.URL.Query().Get("pq.returnLimit") != "1" {
+						t.Errorf("%s probed without a return limit: %s", r.URL.Path, r.URL.RawQuery)
+					}
+					w.Write([]byte(`[]`))
+				default:
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte(`{"ErrorCode": "0xA0110002", "Message": "User does not have the required permissions"}`))
+				}
+			},
+			want: map[string]DiagnosticStatus{
+				DiagnosticConnectivity: DiagnosticPass, DiagnosticAuthentication: DiagnosticPass, DiagnosticAPIPath: DiagnosticPass,
+				DiagnosticVersion: DiagnosticPass, DiagnosticSecurityModel: DiagnosticPass,
+				DiagnosticCertificatePermissions: DiagnosticPass, DiagnosticStorePermissions: DiagnosticFail,
+				DiagnosticEnrollmentPermissions: DiagnosticPass, DiagnosticLatency: DiagnosticPass,
+			},
+			version: "12.4.0",
+			model:   SecurityModelClaims,
+		},
+		{
+			name: "WrongAPIPath",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`<html><head><title>404 - File or directory not found.</title></head><body></body></html>`))
+			},
+			want: map[string]DiagnosticStatus{
+				DiagnosticConnectivity: DiagnosticPass, DiagnosticAuthentication: DiagnosticSkip, DiagnosticAPIPath: DiagnosticFail,
+				DiagnosticVersion: DiagnosticSkip, DiagnosticSecurityModel: DiagnosticSkip,
+				DiagnosticCertificatePermissions: DiagnosticSkip, DiagnosticStorePermissions: DiagnosticSkip,
+				DiagnosticEnrollmentPermissions: DiagnosticSkip, DiagnosticLatency: DiagnosticPass,
+			},
+		},
+		{
+			name: "Unauthorized",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			want: map[string]DiagnosticStatus{
+				DiagnosticConnectivity: DiagnosticPass, DiagnosticAuthentication: DiagnosticFail, DiagnosticAPIPath: DiagnosticSkip,
+				DiagnosticVersion: DiagnosticSkip, DiagnosticSecurityModel: DiagnosticSkip,
+				DiagnosticCertificatePermissions: DiagnosticSkip, DiagnosticStorePermissions: DiagnosticSkip,
+				DiagnosticEnrollmentPermissions: DiagnosticSkip, DiagnosticLatency: DiagnosticPass,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestServer(t, tt.handler)
+			report, err := c.Diagnose(context.Background())
+			if err != nil {
+				t.Fatalf("Diagnose() error = %v", err)
+			}
+
+			var names []string
+			got := map[string]DiagnosticStatus{}
+			for _, check := range report.Checks {
+				names = append(names, check.Name)
+				got[check.Name] = check.Status
+			}
+			if !reflect.DeepEqual(names, diagnosticChecks) {
+				t.Errorf("Diagnose() ran checks %q, want %q", names, diagnosticChecks)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diagnose() = %v, want %v\n%s", got, tt.want, report)
+			}
+			if report.OK() != tt.wantOK || report.KeyfactorVersion != tt.version || report.SecurityModel != tt.model {
+				t.Errorf("Diagnose() OK = %v, version %q, model %d\n%s", report.OK(), report.KeyfactorVersion, report.SecurityModel, report)
+			}
+			if report.Latency == nil || report.Latency.Min > report.Latency.Max {
+				t.Errorf("Diagnose() latency = %+v", report.Latency)
+			}
+		})
+	}
+
+	t.Run("StoresDeniedDetails", func(t *testing.T) {
+		c := newTestServer(t, tests[0].handler)
+		report, _ := c.Diagnose(context.Background())
+		check := report.Check(DiagnosticStorePermissions)
+		if check.CorrelationID == "" || !strings.Contains(check.Message, "certificate stores") {
+			t.Errorf("store permissions check = %+v", check)
+		}
+		if !strings.Contains(report.String(), "[FAIL] store_permissions: The identity cannot read certificate stores") {
+			t.Errorf("String() =\n%s", report)
+		}
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		c := newTestServer(t, tests[0].handler)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		report, err := c.Diagnose(ctx)
+		if !errors.Is(err, context.Canceled) || report == nil || len(report.Checks) != 0 {
+			t.Errorf("Diagnose() = %+v, %v, want no checks and context.Canceled", report, err)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// AuthConfigFromEnv returns the client configuration held in the KEYFACTOR_* environment variables, the same ones
+// read by the Keyfactor Terraform provider and the kfutil CLI:
+//
+//	KEYFACTOR_HOSTNAME            Keyfactor Command hostname
+//	KEYFACTOR_USERNAME            username for basic authentication
+//	KEYFACTOR_PASSWORD            password for basic authentication
+//	KEYFACTOR_DOMAIN              domain of the user, if not part of KEYFACTOR_USERNAME
+//	KEYFACTOR_API_PATH            API path, defaults to KeyfactorAPI
+//	KEYFACTOR_SKIP_VERIFY         skip TLS certificate verification, true or false
+//	KEYFACTOR_AUTH_CLIENT_ID      OAuth client ID
+//	KEYFACTOR_AUTH_CLIENT_SECRET  OAuth client secret
+//	KEYFACTOR_AUTH_TOKEN_URL      OAuth token endpoint of the identity provider
+//	KEYFACTOR_AUTH_SCOPES         OAuth scopes, separated by commas or spaces
+//	KEYFACTOR_AUTH_AUDIENCE       OAuth audience
+//	KEYFACTOR_AUTH_ACCESS_TOKEN   OAuth access token, used instead of the client credentials
+//	KEYFACTOR_API_VERSIONS        API versions of endpoints, e.g. Certificates=2,CertificateStores=1
+//	KEYFACTOR_USER_AGENT          product tokens appended to the User-Agent, e.g. my-tool/1.2.0
+//	KEYFACTOR_APPLICATION_NAME    application name sent in the x-keyfactor-application-name header
+//
+// OAuth is used if KEYFACTOR_AUTH_CLIENT_ID or KEYFACTOR_AUTH_ACCESS_TOKEN is set, and basic authentication
+// otherwise. Required settings that are missing are reported when the client is created, not here.
+func AuthConfigFromEnv() (*AuthConfig, error) {
+	auth := &AuthConfig{
+		Hostname: os.Getenv(EnvCommandHostname),
+		Username: os.Getenv(EnvCommandUsername),
+		Password: os.Getenv(EnvCommandPassword),
+		Domain:   os.Getenv(EnvCommandDomain),
+		APIPath:  os.Getenv(EnvCommandAPIPath),
+
+		UserAgent:       os.Getenv(EnvCommandUserAgent),
+		ApplicationName: os.Getenv(EnvCommandApplicationName),
+	}
+
+	if v := strings.TrimSpace(os.Getenv(EnvCommandSkipVerify)); v != "" {
+		skip, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for %s: must be true or false", v, EnvCommandSkipVerify)
+		}
+		auth.SkipVerify = skip
+	}
+
+	if v := strings.TrimSpace(os.Getenv(EnvCommandAPIVersions)); v != "" {
+		versions, err := ParseAPIVersions(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", EnvCommandAPIVersions, err)
+		}
+		auth.APIVersions = versions
+	}
+
+	clientID := os.Getenv(EnvCommandClientID)
+	accessToken := os.Getenv(EnvCommandAccessToken)
+	if clientID != "" || accessToken != "" {
+		auth.OAuth = &OAuthConfig{
+			ClientID:     clientID,
+			ClientSecret: os.Getenv(EnvCommandClientSecret),
+			TokenURL:     os.Getenv(EnvCommandTokenURL),
+			Audience:     os.Getenv(EnvCommandAudience),
+			AccessToken:  accessToken,
+		}
+		scopes := strings.FieldsFunc(os.Getenv(EnvCommandScopes), func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})
+		if len(scopes) > 0 {
+			auth.OAuth.Scopes = scopes
+		}
+	}
+	return auth, nil
+}
+
+// NewClientFromEnv creates a new Keyfactor client configured from the environment, as described by
+// AuthConfigFromEnv.
+func NewClientFromEnv() (*Client, error) {
+	auth, err := AuthConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyfactorClient(auth)
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestAuthConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    *AuthConfig
+		wantErr bool
+	}{
+		{
+			name: "BasicAuth",
+			env: map[string]string{
+				EnvCommandHostname:   "kf.example.com",
+				EnvCommandUsername:   "svc-terraform",
+				EnvCommandPassword:   "hunter2",
+				EnvCommandDomain:     "EXAMPLE",
+				EnvCommandAPIPath:    "Keyfactor/API",
+				EnvCommandSkipVerify: "true",
+			},
+			want: &AuthConfig{
+				Hostname:   "kf.example.com",
+				Username:   "svc-terraform",
+				Password:   "hunter2",
+				Domain:     "EXAMPLE",
+				APIPath:    "Keyfactor/API",
+				SkipVerify: true,
+			},
+		},
+		{
+			name: "OAuthClientCredentials",
+			env: map[string]string{
+				EnvCommandHostname:     "kf.example.com",
+				EnvCommandClientID:     "kfutil",
+				EnvCommandClientSecret: "s3cret",
+				EnvCommandTokenURL:     "https://idp.example.com/oauth/token",
+				EnvCommandScopes:       "openid, keyfactor-api offline",
+				EnvCommandAudience:     "https://kf.example.com",
+			},
+			want: &AuthConfig{
+				Hostname: "kf.example.com",
+				OAuth: &OAuthConfig{
+					ClientID:     "kfutil",
+					ClientSecret: "s3cret",
+					TokenURL:     "https://idp.example.com/oauth/token",
+					Scopes:       []string{"openid", "keyfactor-api", "offline"},
+					Audience:     "https://kf.example.com",
+				},
+			},
+		},
+		{
+			name: "OAuthAccessToken",
+			env:  map[string]string{EnvCommandHostname: "kf.example.com", EnvCommandAccessToken: "eyJhbGciOi"},
+			want: &AuthConfig{Hostname: "kf.example.com", OAuth: &OAuthConfig{AccessToken: "eyJhbGciOi"}},
+		},
+		{
+			name: "APIVersions",
+			env:  map[string]string{EnvCommandHostname: "kf.example.com", EnvCommandAPIVersions: "Certificates=2,CertificateStores=1"},
+			want: &AuthConfig{Hostname: "kf.example.com", APIVersions: APIVersions{"Certificates": "2", "CertificateStores": "1"}},
+		},
+		{
+			name: "UserAgent",
+			env:  map[string]string{EnvCommandHostname: "kf.example.com", EnvCommandUserAgent: "kfutil/1.6.0", EnvCommandApplicationName: "kfutil"},
+			want: &AuthConfig{Hostname: "kf.example.com", UserAgent: "kfutil/1.6.0", ApplicationName: "kfutil"},
+		},
+		{
+			name:    "InvalidAPIVersions",
+			env:     map[string]string{EnvCommandAPIVersions: "Certificates"},
+			wantErr: true,
+		},
+		{
+			name:    "InvalidSkipVerify",
+			env:     map[string]string{EnvCommandSkipVerify: "sometimes"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{
+				EnvCommandHostname, EnvCommandUsername, EnvCommandPassword, EnvCommandDomain, EnvCommandAPIPath,
+				EnvCommandSkipVerify, EnvCommandClientID, EnvCommandClientSecret, EnvCommandTokenURL,
+				EnvCommandScopes, EnvCommandAudience, EnvCommandAccessToken, EnvCommandAPIVersions,
+				EnvCommandUserAgent, EnvCommandApplicationName,
+			} {
+				t.Setenv(name, tt.env[name])
+			}
+			got, err := AuthConfigFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AuthConfigFromEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AuthConfigFromEnv() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClientFromEnv_OAuth(t *testing.T) {
+	var tokenRequests int
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/token":
+			tokenRequests++
+			r.ParseForm()
+			if r.PostForm.Get("grant_type") != "client_credentials" || r.PostForm.Get("client_secret") != "s3cret" ||
+				r.PostForm.Get("scope") != "openid keyfactor-api" {
+				t.Errorf("token request form = %v", r.PostForm)
+			}
+			w.Write([]byte(`{"access_token": "tok-1", "token_type": "Bearer", "expires_in": 3600}`))
+		case "/KeyfactorAPI/Status/Endpoints":
+			if got := r.Header.Get("Authorization"); got != "Bearer tok-1" {
+				t.Errorf("Authorization = %q, want bearer token", got)
+			}
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv(EnvCommandHostname, srv.URL)
+	t.Setenv(EnvCommandUsername, "")
+	t.Setenv(EnvCommandPassword, "")
+	t.Setenv(EnvCommandAPIPath, "")
+	os.Unsetenv(EnvCommandAPIPath)
+	t.Setenv(EnvCommandAccessToken, "")
+	t.Setenv(EnvCommandSkipVerify, "1")
+	t.Setenv(EnvCommandClientID, "kfutil")
+	t.Setenv(EnvCommandClientSecret, "s3cret")
+	t.Setenv(EnvCommandTokenURL, srv.URL+"/oauth/token")
+	t.Setenv(EnvCommandScopes, "openid,keyfactor-api")
+
+	c, err := NewClientFromEnv()
+	if err != nil {
+		t.Fatalf("NewClientFromEnv() error = %v", err)
+	}
+	if _, err := c.sendRequest(&request{Method: "GET", Endpoint: "Status/Endpoints", Headers: &apiHeaders{}}); err != nil {
+		t.Fatalf("sendRequest() error = %v", err)
+	}
+	if tokenRequests != 1 {
+		t.Errorf("token requested %d times, want the token to be cached", tokenRequests)
+	}
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrFetchLimitReached is returned by the ListAll helpers, along with the results fetched so far, when a list holds
+// more items than FetchAllOptions.MaxItems allows.
+var ErrFetchLimitReached = errors.New("fetch limit reached")
+
+// Defaults used by the ListAll helpers.
+const (
+	DefaultFetchAllInitialPageSize = 100
+	DefaultFetchAllMaxPageSize     = 1000
+	DefaultFetchAllMaxItems        = 100000
+)
+
+// FetchAllOptions controls how the ListAll helpers walk the pages of a list. A nil FetchAllOptions uses the defaults.
+//
+// Pages start small, so short lists come back quickly, and double in size as long as the list goes on, up to
+// MaxPageSize, so long lists take few round trips.
+type FetchAllOptions struct {
+	// InitialPageSize is the size of the first page. Defaults to DefaultFetchAllInitialPageSize.
+	InitialPageSize int
+	// MaxPageSize caps the page size. Defaults to DefaultFetchAllMaxPageSize.
+	MaxPageSize int
+	// MaxItems stops the walk once this many items have been fetched, as a guard against pulling an unexpectedly
+	// large list into memory. Defaults to DefaultFetchAllMaxItems; a negative value removes the limit.
+	MaxItems int
+	// PageInterval is the minimum time between page requests, to spread the load of a long walk on Keyfactor.
+	PageInterval time.Duration
+	// SortField and SortAscending set the order of the results, e.g. "Id". Sorting on a field that does not change
+	// while the walk runs keeps items from moving between pages.
+	SortField     string
+	SortAscending bool
+}
+
+// withDefaults returns a copy of o with zero fields set to their defaults.
+func (o *FetchAllOptions) withDefaults() FetchAllOptions {
+	opts := FetchAllOptions{}
+	if o != nil {
+		opts = *o
+	}
+	if opts.InitialPageSize <= 0 {
+		opts.InitialPageSize = DefaultFetchAllInitialPageSize
+	}
+	if opts.MaxPageSize <= 0 {
+		opts.MaxPageSize = DefaultFetchAllMaxPageSize
+	}
+	if opts.InitialPageSize > opts.MaxPageSize {
+		opts.InitialPageSize = opts.MaxPageSize
+	}
+	if opts.MaxItems == 0 {
+		opts.MaxItems = DefaultFetchAllMaxItems
+	}
+	return opts
+}
+
+// fetchAllPages walks the pages of a list, calling fetch with the paging of each page in turn until a short page
+// comes back. fetch returns the number of items on the page. If more than opts.MaxItems items are fetched, it stops
+// and returns ErrFetchLimitReached; callers should trim their results to opts.MaxItems. Near the limit, pages are cut
+// down to one item past it where page boundaries allow, so that a list of exactly MaxItems items is told apart from a
+// longer one without fetching much more.
+func (c *Client) fetchAllPages(ctx context.Context, opts FetchAllOptions, fetch func(*Paging) (int, error)) error {
+	pageSize := opts.InitialPageSize
+	fetched := 0
+	for {
+		size := pageSize
+		if opts.MaxItems > 0 {
+			size = limitedPageSize(pageSize, fetched, opts.MaxItems-fetched+1)
+		}
+		paging := &Paging{
+			PageReturned:  fetched/size + 1,
+			ReturnLimit:   size,
+			SortField:     opts.SortField,
+			SortAscending: opts.SortAscending,
+		}
+		n, err := fetch(paging)
+		if err != nil {
+			return err
+		}
+		fetched += n
+		if opts.MaxItems > 0 && fetched > opts.MaxItems {
+			c.warnf("Stopped listing after %d items, more than the limit set by FetchAllOptions.MaxItems", fetched)
+			return fmt.Errorf("%w: stopped after %d items", ErrFetchLimitReached, opts.MaxItems)
+		}
+		if n < size {
+			return nil
+		}
+
+		// Pages are numbered by size, so the size can only grow where the items fetched so far end on a page
+		// boundary of the larger size.
+		if next := pageSize * 2; next <= opts.MaxPageSize && fetched%next == 0 {
+			pageSize = next
+		}
+
+		if opts.PageInterval > 0 {
+			timer := time.NewTimer(opts.PageInterval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// limitedPageSize returns the size of the page to fetch after fetched items, at most pageSize and at least want if
+// want is smaller, such that the page starts right after the items fetched.
+func limitedPageSize(pageSize, fetched, want int) int {
+	for size := want; size < pageSize; size++ {
+		if fetched%size == 0 {
+			return size
+		}
+	}
+	return pageSize
+}
+
+// ListAllCertificateStores takes arguments for a query string, such as one built with the query package, to
+// facilitate a series of calls to Keyfactor that return every matching certificate store. An empty query matches
+// every store. opts may be nil. If the stores outnumber opts.MaxItems, the first MaxItems are returned along with an
+// error wrapping ErrFetchLimitReached.
+func (c *Client) ListAllCertificateStores(q string, opts *FetchAllOptions) ([]GetCertificateStoreResponse, error) {
+	return c.ListAllCertificateStoresContext(context.Background(), q, opts)
+}
+
+// ListAllCertificateStoresContext is like ListAllCertificateStores but uses ctx for the requests, allowing it to be
+// cancelled.
+func (c *Client) ListAllCertificateStoresContext(ctx context.Context, q string, opts *FetchAllOptions) ([]GetCertificateStoreResponse, error) {
+	o := opts.withDefaults()
+	all := []GetCertificateStoreResponse{}
+	err := c.fetchAllPages(ctx, o, func(paging *Paging) (int, error) {
+		page, err := c.SearchCertificateStoresContext(ctx, q, paging)
+		all = append(all, page...)
+		return len(page), err
+	})
+	if err != nil && !errors.Is(err, ErrFetchLimitReached) {
+		return nil, err
+	}
+	if o.MaxItems > 0 && len(all) > o.MaxItems {
+		all = all[:o.MaxItems]
+	}
+	return all, err
+}
+
+// ListAllCertificates takes arguments for a query string, such as one built with the query package, to facilitate a
+// series of calls to Keyfactor that return every matching certificate. search may be nil; its filters apply, and its
+// sort settings take precedence over those of opts. Its PageSize is ignored. If the certificates outnumber
+// opts.MaxItems, the first MaxItems are returned along with an error wrapping ErrFetchLimitReached.
+func (c *Client) ListAllCertificates(q string, search *SearchCertificatesOptions, opts *FetchAllOptions) ([]GetCertificateResponse, error) {
+	return c.ListAllCertificatesContext(context.Background(), q, search, opts)
+}
+
+// ListAllCertificatesContext is like ListAllCertificates but uses ctx for the requests, allowing it to be cancelled.
+// Unless search sets a CollectionId, the search is scoped to the collection set with WithCollection.
+func (c *Client) ListAllCertificatesContext(ctx context.Context, q string, search *SearchCertificatesOptions, opts *FetchAllOptions) ([]GetCertificateResponse, error) {
+	o := opts.withDefaults()
+	s := SearchCertificatesOptions{}
+	if search != nil {
+		s = *search
+	}
+	if s.SortField == "" {
+		s.SortField, s.SortAscending = o.SortField, o.SortAscending
+	}
+	q, err := ownerQuery(q, &s)
+	if err != nil {
+		return nil, err
+	}
+	collectionId := s.CollectionId
+	if collectionId <= 0 {
+		collectionId = collectionFromContext(ctx)
+	}
+
+	all := []GetCertificateResponse{}
+	err = c.fetchAllPages(ctx, o, func(paging *Paging) (int, error) {
+		page, err := c.searchCertificatesPage(ctx, q, &s, collectionId, paging.PageReturned, paging.ReturnLimit)
+		all = append(all, page...)
+		return len(page), err
+	})
+	if err != nil && !errors.Is(err, ErrFetchLimitReached) {
+		return nil, err
+	}
+	if o.MaxItems > 0 && len(all) > o.MaxItems {
+		all = all[:o.MaxItems]
+	}
+	return all, err
+}
+
+// ListAllAuditLogs takes arguments for a query string, such as one built with the query package, to facilitate a
+// series of calls to Keyfactor that return every matching audit log entry. An empty query matches every entry. opts
+// may be nil. If the entries outnumber opts.MaxItems, the first MaxItems are returned along with an error wrapping
+// ErrFetchLimitReached.
+func (c *Client) ListAllAuditLogs(q string, opts *FetchAllOptions) ([]AuditLogEntry, error) {
+	return c.ListAllAuditLogsContext(context.Background(), q, opts)
+}
+
+// ListAllAuditLogsContext is like ListAllAuditLogs but uses ctx for the requests, allowing it to be cancelled.
+func (c *Client) ListAllAuditLogsContext(ctx context.Context, q string, opts *FetchAllOptions) ([]AuditLogEntry, error) {
+	o := opts.withDefaults()
+	all := []AuditLogEntry{}
+	err := c.fetchAllPages(ctx, o, func(paging *Paging) (int, error) {
+		page, err := c.SearchAuditLogsContext(ctx, q, paging)
+		all = append(all, page...)
+		return len(page), err
+	})
+	if err != nil && !errors.Is(err, ErrFetchLimitReached) {
+		return nil, err
+	}
+	if o.MaxItems > 0 && len(all) > o.MaxItems {
+		all = all[:o.MaxItems]
+	}
+	return all, err
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestClient_ListAllCertificateStores(t *testing.T) {
+	total := 350
+	var pageSizes []int
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/KeyfactorAPI/CertificateStores" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		q := r.URL.Query()
+		page, _ := strconv.Atoi(q.Get("certificateStoreQuery.pageReturned"))
+		size, _ := strconv.Atoi(q.Get("certificateStoreQuery.returnLimit"))
+		pageSizes = append(pageSizes, size)
+		stores := []GetCertificateStoreResponse{}
+		for i := (page - 1) * size; i < page*size && i < total; i++ {
+			stores = append(stores, GetCertificateStoreResponse{Id: fmt.Sprintf("store-%03d", i)})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stores)
+	})
+
+	tests := []struct {
+		name          string
+		opts          *FetchAllOptions
+		wantLen       int
+		wantPageSizes []int
+		wantLimitErr  bool
+		total         int
+	}{
+		{name: "All", opts: &FetchAllOptions{InitialPageSize: 50, MaxPageSize: 200}, wantLen: total, wantPageSizes: []int{50, 50, 100, 200}},
+		{name: "Defaults", wantLen: total, wantPageSizes: []int{100, 100, 200}},
+		{name: "MaxItems", opts: &FetchAllOptions{InitialPageSize: 50, MaxItems: 120}, wantLen: 120, wantPageSizes: []int{50, 50, 25}, wantLimitErr: true},
+		// The limit is passed within the last, short page.
+		{name: "MaxItemsInLastPage", opts: &FetchAllOptions{MaxItems: 50}, total: 70, wantLen: 50, wantPageSizes: []int{51}, wantLimitErr: true},
+		// The list ends exactly at the limit on a full page.
+		{name: "MaxItemsExactly", opts: &FetchAllOptions{InitialPageSize: 50, MaxItems: 100}, total: 100, wantLen: 100, wantPageSizes: []int{50, 50, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pageSizes = nil
+			total = 350
+			if tt.total > 0 {
+				total = tt.total
+			}
+			got, err := c.ListAllCertificateStores("", tt.opts)
+			if tt.wantLimitErr != errors.Is(err, ErrFetchLimitReached) || (!tt.wantLimitErr && err != nil) {
+				t.Fatalf("ListAllCertificateStores() error = %v, want limit error %v", err, tt.wantLimitErr)
+			}
+			if len(got) != tt.wantLen {
+				t.Fatalf("ListAllCertificateStores() returned %d stores, want %d", len(got), tt.wantLen)
+			}
+			for i := range got {
+				if want := fmt.Sprintf("store-%03d", i); got[i].Id != want {
+					t.Fatalf("store %d = %s, want %s", i, got[i].Id, want)
+				}
+			}
+			if !reflect.DeepEqual(pageSizes, tt.wantPageSizes) {
+				t.Errorf("requested page sizes %v, want %v", pageSizes, tt.wantPageSizes)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidGUID is matched by errors returned when an ID that Keyfactor expects to be a GUID, such as a certificate
+// store or orchestrator agent ID, is malformed.
+var ErrInvalidGUID = errors.New("invalid GUID")
+
+// GUID is a Keyfactor identifier in the canonical 8-4-4-4-12 hexadecimal form, e.g.
+// "3a2b1c0d-4e5f-6a7b-8c9d-0e1f2a3b4c5d". Certificate stores and orchestrator agents are identified by GUIDs.
+type GUID string
+
+// ParseGUID parses s as a GUID. Surrounding braces and upper case hexadecimal digits are accepted; the returned GUID
+// is lower case without braces. An error matching ErrInvalidGUID is returned if s is not a GUID.
+func ParseGUID(s string) (GUID, error) {
+	g := strings.ToLower(strings.TrimSpace(s))
+	if strings.HasPrefix(g, "{") && strings.HasSuffix(g, "}") {
+		g = g[1 : len(g)-1]
+	}
+	if len(g) != 36 {
+		return "", fmt.Errorf("%w %q", ErrInvalidGUID, s)
+	}
+	for i, r := range g {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return "", fmt.Errorf("%w %q", ErrInvalidGUID, s)
+			}
+		default:
+			if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f') {
+				return "", fmt.Errorf("%w %q", ErrInvalidGUID, s)
+			}
+		}
+	}
+	return GUID(g), nil
+}
+
+// String returns the GUID as a string.
+func (g GUID) String() string {
+	return string(g)
+}
+
+// validateGUID returns a descriptive error if id, the ID of the named kind of object, is not a GUID. Keyfactor
+// answers a malformed GUID in a request path with an HTML error page from IIS rather than a useful message, so IDs
+// are checked before they are sent.
+func validateGUID(kind, id string) error {
+	if id == "" {
+		return fmt.Errorf("%s id is required", kind)
+	}
+	if _, err := ParseGUID(id); err != nil {
+		return fmt.Errorf("%s id: %w", kind, err)
+	}
+	return nil
+}
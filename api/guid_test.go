@@ -0,0 +1,64 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestParseGUID(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    GUID
+		wantErr bool
+	}{
+		{name: "Canonical", in: "3a2b1c0d-4e5f-6a7b-8c9d-0e1f2a3b4c5d", want: "3a2b1c0d-4e5f-6a7b-8c9d-0e1f2a3b4c5d"},
+		{name: "UpperCase", in: "3A2B1C0D-4E5F-6A7B-8C9D-0E1F2A3B4C5D", want: "3a2b1c0d-4e5f-6a7b-8c9d-0e1f2a3b4c5d"},
+		{name: "Braces", in: "{3a2b1c0d-4e5f-6a7b-8c9d-0e1f2a3b4c5d}", want: "3a2b1c0d-4e5f-6a7b-8c9d-0e1f2a3b4c5d"},
+		{name: "Empty", in: "", wantErr: true},
+		{name: "NoHyphens", in: "3a2b1c0d4e5f6a7b8c9d0e1f2a3b4c5d", wantErr: true},
+		{name: "NonHex", in: "3a2b1c0d-4e5f-6a7b-8c9d-0e1f2a3b4c5g", wantErr: true},
+		{name: "Name", in: "web01-iis-store", wantErr: true},
+		{name: "Truncated", in: "3a2b1c0d-4e5f-6a7b-8c9d-0e1f2a3b4c5", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGUID(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGUID(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidGUID) {
+				t.Errorf("ParseGUID(%q) error = %v, want ErrInvalidGUID", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseGUID(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_MalformedGUID(t *testing.T) {
+	var requests int
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	calls := map[string]func() error{
+		"GetCertificateStoreByID": func() error { _, err := c.GetCertificateStoreByID("not-a-guid"); return err },
+		"DeleteCertificateStore":  func() error { return c.DeleteCertificateStore("not-a-guid") },
+		"GetCertStoreInventory":   func() error { _, err := c.GetCertStoreInventory("not-a-guid"); return err },
+		"GetAgent":                func() error { _, err := c.GetAgent("not-a-guid"); return err },
+		"ApproveAgent":            func() error { _, err := c.ApproveAgent("not-a-guid"); return err },
+		"FetchAgentLogs":          func() error { _, err := c.FetchAgentLogs("not-a-guid"); return err },
+	}
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, ErrInvalidGUID) {
+			t.Errorf("%s() error = %v, want ErrInvalidGUID", name, err)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("malformed GUIDs sent %d requests, want 0", requests)
+	}
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// htmlSnippetLength is the maximum length of the text of an HTML page kept in an HTMLResponseError.
+const htmlSnippetLength = 300
+
+// maxHTMLErrorBody limits how much of an HTML page is read to describe it.
+const maxHTMLErrorBody = 64 << 10
+
+var (
+	htmlTitlePattern  = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlIgnorePattern = regexp.MustCompile(`(?is)<(script|style|head)[^>]*>.*?</(script|style|head)>`)
+	htmlTagPattern    = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// HTMLResponseError is returned when Keyfactor, or IIS or a proxy in front of it, answers with an HTML page instead
+// of JSON: an IIS error page for a wrong API path, a login page of a single sign-on proxy, or the Command portal
+// itself. It wraps a *RequestError with the response's status code, so errors.As matches either type.
+type HTMLResponseError struct {
+	RequestError
+	ContentType string
+	// Title is the title of the page, e.g. "404 - File or directory not found.".
+	Title string
+	// Snippet is the start of the text of the page, with its markup removed.
+	Snippet string
+}
+
+func (e *HTMLResponseError) Error() string {
+	msg := fmt.Sprintf("keyfactor returned an HTML page instead of JSON (status %d", e.StatusCode)
+	if e.Title != "" {
+		msg += fmt.Sprintf(", title %q", e.Title)
+	}
+	msg += ")"
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		msg += "; check the credentials and the authentication method configured for the Keyfactor API"
+	default:
+		msg += fmt.Sprintf("; check that the hostname and API path (%s) point at the Keyfactor API", EnvCommandAPIPath)
+	}
+	if e.Snippet != "" && e.Snippet != e.Title {
+		msg += ": " + e.Snippet
+	}
+	return msg + e.idSuffix()
+}
+
+// Unwrap returns the *RequestError carrying the status code and correlation IDs of the response.
+func (e *HTMLResponseError) Unwrap() error {
+	return &e.RequestError
+}
+
+// checkHTMLResponse returns an *HTMLResponseError if resp is an HTML page, going by its Content-Type or, if it has
+// none, by the start of its body. Otherwise it returns nil, leaving the body of resp to be read as before.
+func checkHTMLResponse(resp *http.Response, corrID string) error {
+	contentType := resp.Header.Get("Content-Type")
+	isHTML := false
+	if contentType != "" {
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		isHTML = mediaType == "text/html" || mediaType == "application/xhtml+xml"
+	} else if resp.Body != nil && resp.Body != http.NoBody {
+		br := bufio.NewReader(resp.Body)
+		start, _ := br.Peek(512)
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{br, resp.Body}
+		lower := bytes.ToLower(bytes.TrimSpace(start))
+		isHTML = bytes.HasPrefix(lower, []byte("<!doctype html")) || bytes.HasPrefix(lower, []byte("<html"))
+	}
+	if !isHTML {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxHTMLErrorBody))
+	resp.Body.Close()
+	title, snippet := describeHTML(string(body))
+	return &HTMLResponseError{
+		RequestError: RequestError{
+			StatusCode:    resp.StatusCode,
+			Message:       fmt.Sprintf("%d - keyfactor returned an HTML page instead of JSON", resp.StatusCode),
+			CorrelationID: corrID,
+			ActivityID:    responseActivityID(resp, nil),
+		},
+		ContentType: contentType,
+		Title:       title,
+		Snippet:     snippet,
+	}
+}
+
+// describeHTML returns the title of an HTML page and the start of its text.
+func describeHTML(page string) (title, snippet string) {
+	if m := htmlTitlePattern.FindStringSubmatch(page); m != nil {
+		title = collapseSpace(html.UnescapeString(m[1]))
+	}
+	text := htmlIgnorePattern.ReplaceAllString(page, " ")
+	text = collapseSpace(html.UnescapeString(htmlTagPattern.ReplaceAllString(text, " ")))
+	if len(text) > htmlSnippetLength {
+		text = strings.ToValidUTF8(text[:htmlSnippetLength], "") + "..."
+	}
+	return title, text
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClient_HTMLResponse(t *testing.T) {
+	iis404 := `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Strict//EN"><html><head><title>404 - File or directory not found.</title>
+		<style>body{margin:0}</style></head><body><div id="content"><h2>404 - File or directory not found.</h2>
+		<h3>The resource you are looking for might have been removed, had its name changed, or is temporarily unavailable.</h3></div></body></html>`
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		wantTitle   string
+		wantInError []string
+	}{
+		{
+			name: "IISNotFound", status: http.StatusNotFound, contentType: "text/html", body: iis404,
+			wantTitle:   "404 - File or directory not found.",
+			wantInError: []string{"status 404", "API path", "might have been removed"},
+		},
+		{
+			name: "LoginPage", status: http.StatusOK, contentType: "text/html; charset=utf-8",
+			body:        "<html><head><title>Sign in &amp; continue</title></head><body><form>Username</form></body></html>",
+			wantTitle:   "Sign in & continue",
+			wantInError: []string{"status 200", `"Sign in & continue"`, "Username"},
+		},
+		{
+			name: "Unauthorized", status: http.StatusUnauthorized, body: "<!doctype html><html><body>401 - Unauthorized</body></html>",
+			wantInError: []string{"status 401", "credentials", "401 - Unauthorized"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				} else {
+					w.Header()["Content-Type"] = nil
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := c.SearchAuditLogs("", nil)
+			var htmlErr *HTMLResponseError
+			if !errors.As(err, &htmlErr) {
+				t.Fatalf("error = %v (%T), want *HTMLResponseError", err, err)
+			}
+			if htmlErr.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", htmlErr.Title, tt.wantTitle)
+			}
+			for _, want := range tt.wantInError {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+			if strings.Contains(err.Error(), "<") {
+				t.Errorf("error %q contains markup", err)
+			}
+			var reqErr *RequestError
+			if !errors.As(err, &reqErr) || reqErr.StatusCode != tt.status || reqErr.CorrelationID == "" {
+				t.Errorf("error does not wrap a *RequestError with status %d: %+v", tt.status, reqErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrNoParameterDecoder is returned by DecodeParameters for store types with no registered InventoryParameterDecoder.
+var ErrNoParameterDecoder = errors.New("no inventory parameter decoder registered for store type")
+
+// InventoryParameterDecoder converts the raw Parameters of an inventory item into a typed value, such as an
+// IISBinding. It is given the whole item, as some store types encode details in the item name.
+type InventoryParameterDecoder func(item *CertStoreInventory) (interface{}, error)
+
+var (
+	inventoryDecodersMu sync.RWMutex
+	inventoryDecoders   = map[string]InventoryParameterDecoder{}
+)
+
+func init() {
+	for _, shortName := range []string{"IISU", "IIS", "WinIIS", "IISBindings"} {
+		RegisterInventoryParameters(shortName, decodeIISParameters)
+	}
+	for _, shortName := range []string{"F5-SL-REST", "F5-WS-REST", "F5-CA-REST"} {
+		RegisterInventoryParameters(shortName, decodeF5Parameters)
+	}
+	for _, shortName := range []string{"AKV", "AzureKeyVault"} {
+		RegisterInventoryParameters(shortName, decodeAKVParameters)
+	}
+}
+
+// RegisterInventoryParameters registers decode as the decoder of inventory item parameters for the store type with the
+// given short name, compared case-insensitively, replacing any decoder registered before. Orchestrator extension
+// authors can use it to give their store type's parameters a Go type:
+//
+//	api.RegisterInventoryParameters("MyStore", func(item *api.CertStoreInventory) (interface{}, error) {
+//		return MyStoreParams{Region: fmt.Sprint(item.Parameters["Region"])}, nil
+//	})
+//
+// Decoders are registered for the IIS (IISBinding), F5 (F5InventoryParameters), and Azure Key Vault
+// (AKVInventoryParameters) store types. Passing a nil decode removes the registration.
+func RegisterInventoryParameters(shortName string, decode InventoryParameterDecoder) {
+	inventoryDecodersMu.Lock()
+	defer inventoryDecodersMu.Unlock()
+	key := strings.ToLower(shortName)
+	if decode == nil {
+		delete(inventoryDecoders, key)
+		return
+	}
+	inventoryDecoders[key] = decode
+}
+
+// DecodeParameters returns the parameters of the inventory item decoded by the decoder registered for storeType, the
+// short name of the store's type. It returns an error wrapping ErrNoParameterDecoder if there is none; Parameters
+// still holds the raw values.
+func (inv *CertStoreInventory) DecodeParameters(storeType string) (interface{}, error) {
+	inventoryDecodersMu.RLock()
+	decode := inventoryDecoders[strings.ToLower(storeType)]
+	inventoryDecodersMu.RUnlock()
+	if decode == nil {
+		return nil, fmt.Errorf("%w %s", ErrNoParameterDecoder, storeType)
+	}
+	v, err := decode(inv)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode parameters of inventory item %s: %w", inv.Name, err)
+	}
+	return v, nil
+}
+
+// F5InventoryParameters holds the parameters of an item in an F5 certificate store inventory.
+type F5InventoryParameters struct {
+	// Partition is the BIG-IP partition holding the certificate, e.g. "Common".
+	Partition string
+}
+
+// AKVInventoryParameters holds the parameters of an item in an Azure Key Vault certificate store inventory.
+type AKVInventoryParameters struct {
+	// Tags are the Azure tags set on the certificate.
+	Tags map[string]string
+}
+
+// decodeIISParameters decodes the site binding of an IIS inventory item.
+func decodeIISParameters(item *CertStoreInventory) (interface{}, error) {
+	port, err := intParameter(item.Parameters, "Port")
+	if err != nil {
+		return nil, err
+	}
+	sniFlag, err := intParameter(item.Parameters, "SniFlag")
+	if err != nil {
+		return nil, err
+	}
+	return IISBinding{
+		SiteName:  stringParameter(item.Parameters, "SiteName"),
+		IPAddress: stringParameter(item.Parameters, "IPAddress"),
+		Port:      port,
+		HostName:  stringParameter(item.Parameters, "HostName"),
+		SniFlag:   sniFlag,
+		Protocol:  stringParameter(item.Parameters, "Protocol"),
+	}, nil
+}
+
+// decodeF5Parameters decodes the partition of an F5 inventory item, taking it from the item's full path, such as
+// "/Common/www.example.com", if it is not a parameter.
+func decodeF5Parameters(item *CertStoreInventory) (interface{}, error) {
+	partition := stringParameter(item.Parameters, "Partition")
+	if partition == "" && strings.HasPrefix(item.Name, "/") {
+		partition, _, _ = strings.Cut(strings.TrimPrefix(item.Name, "/"), "/")
+	}
+	return F5InventoryParameters{Partition: partition}, nil
+}
+
+// decodeAKVParameters decodes the tags of an Azure Key Vault inventory item, which Keyfactor reports either as an
+// object or as a JSON encoded string.
+func decodeAKVParameters(item *CertStoreInventory) (interface{}, error) {
+	params := AKVInventoryParameters{}
+	switch tags := item.Parameters["Tags"].(type) {
+	case nil:
+	case string:
+		if strings.TrimSpace(tags) == "" {
+			break
+		}
+		if err := json.Unmarshal([]byte(tags), &params.Tags); err != nil {
+			return nil, fmt.Errorf("invalid Tags: %w", err)
+		}
+	case map[string]interface{}:
+		params.Tags = make(map[string]string, len(tags))
+		for k, v := range tags {
+			params.Tags[k] = fmt.Sprint(v)
+		}
+	default:
+		return nil, fmt.Errorf("invalid Tags of type %T", tags)
+	}
+	return params, nil
+}
+
+// stringParameter returns the named parameter as a string, or "" if it is not set.
+func stringParameter(params map[string]interface{}, name string) string {
+	switch v := params[name].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// intParameter returns the named parameter as an integer, or 0 if it is not set. Keyfactor reports numeric parameters
+// as numbers or strings, and some store types describe the value after it, as in "1 - SNI Enabled".
+func intParameter(params map[string]interface{}, name string) (int, error) {
+	switch v := params[name].(type) {
+	case nil:
+		return 0, nil
+	case json.Number:
+		n, err := strconv.Atoi(string(v))
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s %s", name, v)
+		}
+		return n, nil
+	case float64:
+		if v != float64(int(v)) {
+			return 0, fmt.Errorf("invalid %s %v", name, v)
+		}
+		return int(v), nil
+	case string:
+		s := strings.TrimSpace(v)
+		if s == "" {
+			return 0, nil
+		}
+		if i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' }); i > 0 {
+			s = s[:i]
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s %q", name, v)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("invalid %s of type %T", name, v)
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCertStoreInventory_DecodeParameters(t *testing.T) {
+	type regionParams struct{ Region string }
+	RegisterInventoryParameters("ExampleCloud", func(item *CertStoreInventory) (interface{}, error) {
+		return regionParams{Region: stringParameter(item.Parameters, "Region")}, nil
+	})
+	t.Cleanup(func() { RegisterInventoryParameters("ExampleCloud", nil) })
+
+	tests := []struct {
+		name      string
+		storeType string
+		item      CertStoreInventory
+		want      interface{}
+		wantErr   error
+	}{
+		{
+			name:      "IIS",
+			storeType: "iisu",
+			item: CertStoreInventory{Name: "www", Parameters: map[string]interface{}{
+				"SiteName": "Default Web Site", "IPAddress": "*", "Port": "8443", "HostName": "www.example.com",
+				"SniFlag": "1 - SNI Enabled", "Protocol": "https",
+			}},
+			want: IISBinding{SiteName: "Default Web Site", IPAddress: "*", Port: 8443, HostName: "www.example.com", SniFlag: 1, Protocol: "https"},
+		},
+		{
+			name:      "F5PartitionFromName",
+			storeType: "F5-SL-REST",
+			item:      CertStoreInventory{Name: "/Tenant_A/www.example.com"},
+			want:      F5InventoryParameters{Partition: "Tenant_A"},
+		},
+		{
+			name:      "AKVTagsString",
+			storeType: "AKV",
+			item:      CertStoreInventory{Parameters: map[string]interface{}{"Tags": `{"env": "prod"}`}},
+			want:      AKVInventoryParameters{Tags: map[string]string{"env": "prod"}},
+		},
+		{
+			name:      "AKVTagsObject",
+			storeType: "AKV",
+			item:      CertStoreInventory{Parameters: map[string]interface{}{"Tags": map[string]interface{}{"owner": "pki"}}},
+			want:      AKVInventoryParameters{Tags: map[string]string{"owner": "pki"}},
+		},
+		{
+			name:      "Registered",
+			storeType: "ExampleCloud",
+			item:      CertStoreInventory{Parameters: map[string]interface{}{"Region": "eu-west-1"}},
+			want:      regionParams{Region: "eu-west-1"},
+		},
+		{
+			name:      "Unregistered",
+			storeType: "PEM",
+			wantErr:   ErrNoParameterDecoder,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.item.DecodeParameters(tt.storeType)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DecodeParameters() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeParameters() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	bad := CertStoreInventory{Parameters: map[string]interface{}{"Port": "https"}}
+	if _, err := bad.DecodeParameters("IISU"); err == nil {
+		t.Errorf("DecodeParameters() with an invalid port succeeded, want error")
+	}
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// StoreInventory is the inventory of a certificate store, one entry per alias, as returned by GetCertStoreInventory:
+//
+//	inv, err := c.GetCertStoreInventory(storeId)
+//	...
+//	if api.StoreInventory(*inv).HasThumbprint(thumbprint) {
+type StoreInventory []CertStoreInventory
+
+// HasThumbprint reports whether the inventory entry holds a certificate with the given thumbprint, in any format.
+func (inv *CertStoreInventory) HasThumbprint(thumbprint string) bool {
+	thumbprint = NormalizeThumbprint(thumbprint)
+	if thumbprint == "" {
+		return false
+	}
+	if inv.thumbprints != nil {
+		return inv.thumbprints[thumbprint]
+	}
+	for _, cert := range inv.Certificates {
+		if NormalizeThumbprint(cert.Thumbprint) == thumbprint {
+			return true
+		}
+	}
+	return false
+}
+
+// HasSerial reports whether the inventory entry holds a certificate with the given serial number, a hexadecimal
+// string in any case, with or without separators and leading zeros.
+func (inv *CertStoreInventory) HasSerial(serial string) bool {
+	serial = normalizeSerial(serial)
+	if serial == "" {
+		return false
+	}
+	if inv.serials != nil {
+		return inv.serials[serial]
+	}
+	for _, cert := range inv.Certificates {
+		if normalizeSerial(cert.SerialNumber) == serial {
+			return true
+		}
+	}
+	return false
+}
+
+// CertificatesExpiringBefore returns the certificates of the inventory entry that expire before t, soonest first.
+// Certificates without an expiry date are left out.
+func (inv *CertStoreInventory) CertificatesExpiringBefore(t time.Time) []InventoriedCertificate {
+	expiring := []InventoriedCertificate{}
+	for _, cert := range inv.Certificates {
+		if cert.NotAfter.Valid() && cert.NotAfter.Before(t) {
+			expiring = append(expiring, cert)
+		}
+	}
+	sort.SliceStable(expiring, func(i, j int) bool { return expiring[i].NotAfter.Before(expiring[j].NotAfter.Time) })
+	return expiring
+}
+
+// HasThumbprint reports whether any entry of the inventory holds a certificate with the given thumbprint.
+func (s StoreInventory) HasThumbprint(thumbprint string) bool {
+	for i := range s {
+		if s[i].HasThumbprint(thumbprint) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasSerial reports whether any entry of the inventory holds a certificate with the given serial number.
+func (s StoreInventory) HasSerial(serial string) bool {
+	for i := range s {
+		if s[i].HasSerial(serial) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindByAlias returns the entry of the inventory with the given alias, or nil if there is none. An exact match is
+// preferred; otherwise aliases are compared case-insensitively, as store types such as Java keystores lower case
+// them.
+func (s StoreInventory) FindByAlias(alias string) *CertStoreInventory {
+	var folded *CertStoreInventory
+	for i := range s {
+		if s[i].Name == alias {
+			return &s[i]
+		}
+		if folded == nil && strings.EqualFold(s[i].Name, alias) {
+			folded = &s[i]
+		}
+	}
+	return folded
+}
+
+// CertificatesExpiringBefore returns the certificates of every entry of the inventory that expire before t, soonest
+// first.
+func (s StoreInventory) CertificatesExpiringBefore(t time.Time) []InventoriedCertificate {
+	expiring := []InventoriedCertificate{}
+	for i := range s {
+		expiring = append(expiring, s[i].CertificatesExpiringBefore(t)...)
+	}
+	sort.SliceStable(expiring, func(i, j int) bool { return expiring[i].NotAfter.Before(expiring[j].NotAfter.Time) })
+	return expiring
+}
+
+// normalizeSerial returns a certificate serial number as upper case hexadecimal with no separators or leading zeros.
+func normalizeSerial(serial string) string {
+	serial = NormalizeThumbprint(serial)
+	if trimmed := strings.TrimLeft(serial, "0"); trimmed != "" || serial == "" {
+		return trimmed
+	}
+	return "0"
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCertStoreInventory_Queries(t *testing.T) {
+	day := func(d int) Timestamp { return NewTimestamp(time.Date(2024, 6, d, 0, 0, 0, 0, time.UTC)) }
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/KeyfactorAPI/CertificateStores/5f1e0a2c-0001-4000-8000-000000000001/Inventory" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"Name": "web", "Certificates": [
+				{"Id": 1, "Thumbprint": "0A1B2C", "SerialNumber": "00F1", "NotAfter": "2024-06-20T00:00:00Z"},
+				{"Id": 2, "Thumbprint": "3D4E5F", "SerialNumber": "A2", "NotAfter": "2024-06-05T00:00:00Z"}
+			]},
+			{"Name": "API", "Certificates": [{"Id": 3, "Thumbprint": "6A7B8C", "SerialNumber": "B3", "NotAfter": "2024-06-10T00:00:00Z"}]}
+		]`))
+	})
+
+	resp, err := c.GetCertStoreInventory("5f1e0a2c-0001-4000-8000-000000000001")
+	if err != nil {
+		t.Fatalf("GetCertStoreInventory() error = %v", err)
+	}
+	inv := StoreInventory(*resp)
+	// Certificates built by hand have no index, so the queries fall back to searching them.
+	manual := StoreInventory{{Name: "web", Certificates: (*resp)[0].Certificates}, {Name: "API", Certificates: (*resp)[1].Certificates}}
+
+	for name, inv := range map[string]StoreInventory{"Indexed": inv, "Unindexed": manual} {
+		t.Run(name, func(t *testing.T) {
+			if !inv.HasThumbprint("0a:1b:2c") || inv.HasThumbprint("FFFF") || inv.HasThumbprint("") {
+				t.Errorf("HasThumbprint() results are wrong")
+			}
+			if !inv.HasSerial("f1") || !inv.HasSerial("00:B3") || inv.HasSerial("F") || inv.HasSerial("") {
+				t.Errorf("HasSerial() results are wrong")
+			}
+			if got := inv.FindByAlias("api"); got == nil || got.Name != "API" {
+				t.Errorf("FindByAlias(api) = %+v", got)
+			}
+			if got := inv.FindByAlias("missing"); got != nil {
+				t.Errorf("FindByAlias(missing) = %+v, want nil", got)
+			}
+
+			var ids []int
+			for _, cert := range inv.CertificatesExpiringBefore(day(15).Time) {
+				ids = append(ids, cert.Id)
+			}
+			if want := []int{2, 3}; !reflect.DeepEqual(ids, want) {
+				t.Errorf("CertificatesExpiringBefore() = %v, want %v", ids, want)
+			}
+			if got := inv[0].CertificatesExpiringBefore(day(1).Time); len(got) != 0 {
+				t.Errorf("CertificatesExpiringBefore() = %v, want none", got)
+			}
+		})
+	}
+}
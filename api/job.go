@@ -0,0 +1,290 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Keyfactor/keyfactor-go-client/query"
+)
+
+// DefaultJobPollInterval is the interval used by Job.WaitForCompletion when a non-positive poll interval is given.
+const DefaultJobPollInterval = 10 * time.Second
+
+// Error implements the error interface.
+func (e *JobError) Error() string {
+	return fmt.Sprintf("orchestrator job %s failed: %s", e.JobId, e.Message)
+}
+
+// GetJob returns a handle to the orchestrator job with the given ID, such as one of the IDs returned by
+// AddCertificateToStores or RemoveCertificateFromStores. No request is made until the job is queried.
+func (c *Client) GetJob(jobId string) *Job {
+	return &Job{Id: jobId, client: c}
+}
+
+// GetJobs returns a handle for each of the given orchestrator job IDs.
+func (c *Client) GetJobs(jobIds []string) []*Job {
+	jobs := make([]*Job, len(jobIds))
+	for i, id := range jobIds {
+		jobs[i] = c.GetJob(id)
+	}
+	return jobs
+}
+
+// GetJobHistory takes arguments for an orchestrator job ID to facilitate a call to Keyfactor that returns every
+// recorded run of the job. An empty list is returned while the job is still waiting for an orchestrator to pick it up.
+func (c *Client) GetJobHistory(jobId string) ([]JobHistory, error) {
+	return c.GetJobHistoryContext(context.Background(), jobId)
+}
+
+// GetJobHistoryContext is like GetJobHistory but uses ctx for the requests, allowing it to be cancelled.
+func (c *Client) GetJobHistoryContext(ctx context.Context, jobId string) ([]JobHistory, error) {
+	c.infof("Getting history of orchestrator job %s", jobId)
+
+	if jobId == "" {
+		return nil, errors.New("job id is required to get orchestrator job history")
+	}
+
+	qStr, err := query.Field("JobId").Eq(jobId).Build()
+	if err != nil {
+		return nil, err
+	}
+	return c.ListJobHistoryContext(ctx, qStr)
+}
+
+// ListJobHistory takes arguments for a query string, such as one built with the query package, to facilitate a series
+// of calls to Keyfactor that return every matching orchestrator job run across all orchestrators, oldest first, e.g.
+// `Result -eq 3` for every failed run.
+func (c *Client) ListJobHistory(q string) ([]JobHistory, error) {
+	return c.ListJobHistoryContext(context.Background(), q)
+}
+
+// ListJobHistoryContext is like ListJobHistory but uses ctx for the requests, allowing it to be cancelled.
+func (c *Client) ListJobHistoryContext(ctx context.Context, q string) ([]JobHistory, error) {
+	c.infof("Listing orchestrator job history matching query '%s'", q)
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	all := []JobHistory{}
+	for page := 1; ; page++ {
+		params := []StringTuple{{"pq.queryString", q}}
+		params = append(params, (&Paging{PageReturned: page, ReturnLimit: searchPageSize, SortField: "OperationStart", SortAscending: true}).query()...)
+
+		keyfactorAPIStruct := &request{
+			Method:   "GET",
+			Endpoint: "OrchestratorJobs/JobHistory",
+			Headers:  headers,
+			Query:    &apiQuery{Query: params},
+			Context:  ctx,
+		}
+
+		resp, err := c.sendRequest(keyfactorAPIStruct)
+		if err != nil {
+			return nil, err
+		}
+
+		var jsonResp []JobHistory
+		err = c.decodeResponse(resp.Body, &jsonResp)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, jsonResp...)
+		if len(jsonResp) < searchPageSize {
+			return all, nil
+		}
+	}
+}
+
+// Status returns the most recent run of the job, or nil if no orchestrator has reported on it yet.
+func (j *Job) Status() (*JobHistory, error) {
+	return j.StatusContext(context.Background())
+}
+
+// StatusContext is like Status but uses ctx for the requests, allowing it to be cancelled.
+func (j *Job) StatusContext(ctx context.Context) (*JobHistory, error) {
+	history, err := j.client.GetJobHistoryContext(ctx, j.Id)
+	if err != nil {
+		return nil, err
+	}
+	var latest *JobHistory
+	for i := range history {
+		if latest == nil || history[i].JobHistoryId > latest.JobHistoryId {
+			latest = &history[i]
+		}
+	}
+	return latest, nil
+}
+
+// WaitForCompletion polls Keyfactor every pollInterval until an orchestrator reports a result for the job, and
+// returns that run's history. If the job failed, the history is returned together with a *JobError carrying the
+// orchestrator's message; a job that completed with warnings is not treated as an error. Waiting stops with the
+// context's error if ctx is cancelled or its deadline passes first.
+func (j *Job) WaitForCompletion(ctx context.Context, pollInterval time.Duration) (*JobHistory, error) {
+	if pollInterval <= 0 {
+		pollInterval = DefaultJobPollInterval
+	}
+	j.client.infof("Waiting for orchestrator job %s to complete", j.Id)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for orchestrator job %s: %w", j.Id, ctx.Err())
+		case <-timer.C:
+		}
+
+		status, err := j.StatusContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if status != nil && status.Result != JobResultUnknown {
+			j.client.infof("Orchestrator job %s completed with result %s", j.Id, status.Result)
+			if status.Result == JobResultFailure {
+				return status, &JobError{JobId: j.Id, Message: status.Message}
+			}
+			if status.Result == JobResultWarning {
+				j.client.warnf("Orchestrator job %s completed with warnings: %s", j.Id, status.Message)
+			}
+			return status, nil
+		}
+
+		j.client.debugf("Orchestrator job %s has not completed, checking again in %s", j.Id, pollInterval)
+		timer.Reset(pollInterval)
+	}
+}
+
+// ScheduleCustomJob takes arguments for ScheduleCustomJobArgs to facilitate a call to Keyfactor that schedules a job of
+// a custom job type, one defined by an orchestrator extension rather than by a certificate store type. The returned
+// Job tracks the job like any other; once it has run, pass the JobHistoryId of its run to GetCustomJobResultData to
+// read the data the extension reported. Required arguments are:
+//   - AgentId     : string
+//   - JobTypeName : string
+func (c *Client) ScheduleCustomJob(args *ScheduleCustomJobArgs) (*Job, error) {
+	return c.ScheduleCustomJobContext(context.Background(), args)
+}
+
+// ScheduleCustomJobContext is like ScheduleCustomJob but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) ScheduleCustomJobContext(ctx context.Context, args *ScheduleCustomJobArgs) (*Job, error) {
+	if args == nil || args.AgentId == "" || args.JobTypeName == "" {
+		return nil, errors.New("orchestrator agent id and job type name are required to schedule a custom job")
+	}
+	c.infof("Scheduling custom job %s on orchestrator %s", args.JobTypeName, args.AgentId)
+
+	if err := validateGUID("orchestrator agent", args.AgentId); err != nil {
+		return nil, err
+	}
+
+	payload := scheduleCustomJobRequest{
+		AgentId:     args.AgentId,
+		JobTypeName: args.JobTypeName,
+		Schedule:    args.Schedule,
+	}
+	if payload.Schedule == nil {
+		immediate := true
+		payload.Schedule = &InventorySchedule{Immediate: &immediate}
+	}
+	if len(args.JobFields) > 0 {
+		payload.JobFields = make(map[string]string, len(args.JobFields))
+		for name, value := range args.JobFields {
+			if s, ok := value.(string); ok {
+				payload.JobFields[name] = s
+				continue
+			}
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of job field %s: %w", name, err)
+			}
+			payload.JobFields[name] = string(encoded)
+		}
+	}
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "POST",
+		Endpoint: "OrchestratorJobs/Custom",
+		Headers:  headers,
+		Payload:  payload,
+		Context:  ctx,
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonResp := &customJobResponse{}
+	err = c.decodeResponse(resp.Body, jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	if jsonResp.JobId == "" {
+		return nil, fmt.Errorf("keyfactor returned no job id for custom job %s", args.JobTypeName)
+	}
+	c.infof("Scheduled custom job %s as orchestrator job %s", args.JobTypeName, jsonResp.JobId)
+	return c.GetJob(jsonResp.JobId), nil
+}
+
+// GetCustomJobResultData takes arguments for the JobHistoryId of a run of a custom job to facilitate a call to
+// Keyfactor that returns the data the orchestrator extension reported for the run. Its format is defined by the
+// extension.
+func (c *Client) GetCustomJobResultData(jobHistoryId int64) (string, error) {
+	return c.GetCustomJobResultDataContext(context.Background(), jobHistoryId)
+}
+
+// GetCustomJobResultDataContext is like GetCustomJobResultData but uses ctx for the request, allowing it to be
+// cancelled.
+func (c *Client) GetCustomJobResultDataContext(ctx context.Context, jobHistoryId int64) (string, error) {
+	c.infof("Getting result data of orchestrator job run %d", jobHistoryId)
+
+	if jobHistoryId <= 0 {
+		return "", errors.New("job history id is required to get custom job result data")
+	}
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "GET",
+		Endpoint: "OrchestratorJobs/JobStatus/Data",
+		Headers:  headers,
+		Query:    &apiQuery{Query: []StringTuple{{"jobHistoryId", strconv.FormatInt(jobHistoryId, 10)}}},
+		Context:  ctx,
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return "", err
+	}
+
+	var jsonResp struct {
+		JobHistoryId int64  `json:"JobHistoryId"`
+		Data         string `json:"Data"`
+	}
+	err = c.decodeResponse(resp.Body, &jsonResp)
+	if err != nil {
+		return "", err
+	}
+	return jsonResp.Data, nil
+}
@@ -0,0 +1,70 @@
+package api
+
+import "github.com/Keyfactor/keyfactor-go-client/enums"
+
+// Job is a handle to an orchestrator job created by Keyfactor, such as the add and remove jobs scheduled by
+// AddCertificateToStores and RemoveCertificateFromStores. Use Client.GetJob to obtain one from a job ID.
+type Job struct {
+	Id     string
+	client *Client
+}
+
+// JobResult is the outcome reported by an orchestrator once a job has run.
+type JobResult = enums.JobStatus
+
+const (
+	JobResultUnknown = enums.JobStatusUnknown
+	JobResultSuccess = enums.JobStatusSuccess
+	JobResultWarning = enums.JobStatusWarning
+	JobResultFailure = enums.JobStatusFailure
+)
+
+// JobHistory is a single entry returned by /OrchestratorJobs/JobHistory, describing a run of an orchestrator job.
+type JobHistory struct {
+	JobHistoryId   int64              `json:"JobHistoryId"`
+	AgentMachine   string             `json:"AgentMachine"`
+	JobId          string             `json:"JobId"`
+	Schedule       *InventorySchedule `json:"Schedule,omitempty"`
+	JobType        string             `json:"JobType"`
+	OperationStart Timestamp          `json:"OperationStart"`
+	OperationEnd   Timestamp          `json:"OperationEnd"`
+	Message        string             `json:"Message"`
+	Result         JobResult          `json:"Result"`
+	Status         int                `json:"Status"`
+	StorePath      string             `json:"StorePath"`
+	ClientMachine  string             `json:"ClientMachine"`
+}
+
+// JobError is returned by Job.WaitForCompletion when an orchestrator reports that a job failed.
+type JobError struct {
+	JobId   string
+	Message string
+}
+
+// ScheduleCustomJobArgs holds the arguments of ScheduleCustomJob.
+type ScheduleCustomJobArgs struct {
+	// AgentId is the ID of the orchestrator to run the job.
+	AgentId string
+	// JobTypeName is the name of the custom job type, as registered by the orchestrator extension.
+	JobTypeName string
+	// Schedule is when to run the job. The job runs immediately if it is nil.
+	Schedule *InventorySchedule
+	// JobFields are passed to the orchestrator extension with the job. Keyfactor accepts only string values, so other
+	// values are sent JSON encoded.
+	JobFields map[string]interface{}
+}
+
+// scheduleCustomJobRequest is the body of a request to /OrchestratorJobs/Custom.
+type scheduleCustomJobRequest struct {
+	AgentId     string             `json:"AgentId"`
+	JobTypeName string             `json:"JobTypeName"`
+	Schedule    *InventorySchedule `json:"Schedule,omitempty"`
+	JobFields   map[string]string  `json:"JobFields,omitempty"`
+}
+
+// customJobResponse is the response of /OrchestratorJobs/Custom.
+type customJobResponse struct {
+	JobId          string `json:"JobId"`
+	OrchestratorId string `json:"OrchestratorId"`
+	JobTypeName    string `json:"JobTypeName"`
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJob_WaitForCompletion(t *testing.T) {
+	tests := []struct {
+		name       string
+		polls      [][]JobHistory
+		timeout    time.Duration
+		wantResult JobResult
+		wantJobErr bool
+		wantCtxErr bool
+	}{
+		{
+			name: "SuccessAfterPending",
+			polls: [][]JobHistory{
+				{},
+				{{JobHistoryId: 1, JobId: "job-1", Result: JobResultUnknown}},
+				{{JobHistoryId: 1, JobId: "job-1", Result: JobResultSuccess}},
+			},
+			wantResult: JobResultSuccess,
+		},
+		{
+			name:       "Warning",
+			polls:      [][]JobHistory{{{JobHistoryId: 1, JobId: "job-1", Result: JobResultWarning, Message: "alias exists"}}},
+			wantResult: JobResultWarning,
+		},
+		{
+			name:       "Failure",
+			polls:      [][]JobHistory{{{JobHistoryId: 1, JobId: "job-1", Result: JobResultFailure, Message: "access denied"}}},
+			wantResult: JobResultFailure,
+			wantJobErr: true,
+		},
+		{
+			name: "LatestRunWins",
+			polls: [][]JobHistory{{
+				{JobHistoryId: 2, JobId: "job-1", Result: JobResultSuccess},
+				{JobHistoryId: 1, JobId: "job-1", Result: JobResultFailure},
+			}},
+			wantResult: JobResultSuccess,
+		},
+		{
+			name:       "ContextDeadline",
+			polls:      [][]JobHistory{{}},
+			timeout:    50 * time.Millisecond,
+			wantCtxErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/KeyfactorAPI/OrchestratorJobs/JobHistory" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				if got := r.URL.Query().Get("pq.queryString"); got != `JobId -eq "job-1"` {
+					t.Errorf("pq.queryString = %q", got)
+				}
+				poll := tt.polls[len(tt.polls)-1]
+				if calls < len(tt.polls) {
+					poll = tt.polls[calls]
+				}
+				calls++
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(poll)
+			})
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			got, err := c.GetJob("job-1").WaitForCompletion(ctx, time.Millisecond)
+			if tt.wantCtxErr {
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("WaitForCompletion() error = %v, want context.DeadlineExceeded", err)
+				}
+				return
+			}
+			var jobErr *JobError
+			if errors.As(err, &jobErr) != tt.wantJobErr {
+				t.Fatalf("WaitForCompletion() error = %v, wantJobErr %v", err, tt.wantJobErr)
+			}
+			if !tt.wantJobErr && err != nil {
+				t.Fatalf("WaitForCompletion() error = %v", err)
+			}
+			if got == nil || got.Result != tt.wantResult {
+				t.Fatalf("WaitForCompletion() = %+v, want result %s", got, tt.wantResult)
+			}
+			if calls < len(tt.polls) {
+				t.Errorf("WaitForCompletion() polled %d times, want at least %d", calls, len(tt.polls))
+			}
+		})
+	}
+}
+
+func TestClient_ScheduleCustomJob(t *testing.T) {
+	const agentId = "3a1c9a42-5b1e-4d2f-9f0a-7e6b5c4d3e2f"
+	var body scheduleCustomJobRequest
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "POST /KeyfactorAPI/OrchestratorJobs/Custom":
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("request body: %v", err)
+			}
+			w.Write([]byte(`{"JobId": "0f6a2d1e-7b3c-4e5f-8a9b-1c2d3e4f5a6b", "OrchestratorId": "` + agentId + `", "JobTypeName": "RotateKeys"}`))
+		case "GET /KeyfactorAPI/OrchestratorJobs/JobStatus/Data":
+			if got := r.URL.Query().Get("jobHistoryId"); got != "42" {
+				t.Errorf("jobHistoryId = %q, want 42", got)
+			}
+			w.Write([]byte(`{"JobHistoryId": 42, "Data": "{\"rotated\": 3}"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	job, err := c.ScheduleCustomJob(&ScheduleCustomJobArgs{
+		AgentId:     agentId,
+		JobTypeName: "RotateKeys",
+		JobFields:   map[string]interface{}{"Vault": "kv/pki", "MaxKeys": 3, "Targets": []string{"a", "b"}},
+	})
+	if err != nil {
+		t.Fatalf("ScheduleCustomJob() error = %v", err)
+	}
+	if job.Id != "0f6a2d1e-7b3c-4e5f-8a9b-1c2d3e4f5a6b" {
+		t.Errorf("ScheduleCustomJob() job = %s", job.Id)
+	}
+	wantFields := map[string]string{"Vault": "kv/pki", "MaxKeys": "3", "Targets": `["a","b"]`}
+	if body.AgentId != agentId || body.JobTypeName != "RotateKeys" || body.Schedule == nil || body.Schedule.Immediate == nil ||
+		!*body.Schedule.Immediate || !reflect.DeepEqual(body.JobFields, wantFields) {
+		t.Errorf("ScheduleCustomJob() sent %+v", body)
+	}
+
+	data, err := c.GetCustomJobResultData(42)
+	if err != nil || data != `{"rotated": 3}` {
+		t.Errorf("GetCustomJobResultData() = %q, %v", data, err)
+	}
+
+	if _, err := c.ScheduleCustomJob(&ScheduleCustomJobArgs{AgentId: agentId}); err == nil {
+		t.Errorf("ScheduleCustomJob() without a job type succeeded, want error")
+	}
+}
+
+func TestClient_ListJobHistory_OldestFirst(t *testing.T) {
+	var query url.Values
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	})
+	if _, err := c.ListJobHistory("Result -eq 3"); err != nil {
+		t.Fatalf("ListJobHistory() error = %v", err)
+	}
+	if query.Get("pq.sortField") != "OperationStart" || query.Get("pq.sortAscending") != "0" {
+		t.Errorf("ListJobHistory() sent %s, want OperationStart sorted ascending", query.Encode())
+	}
+}
+
+func TestClient_ListJobHistory_Timestamps(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"JobHistoryId": 31, "OperationStart": "2024-05-01T12:05:00.123", "OperationEnd": null}]`))
+	})
+	got, err := c.ListJobHistory("Result -eq 3")
+	if err != nil || len(got) != 1 {
+		t.Fatalf("ListJobHistory() = %+v, %v", got, err)
+	}
+	want := time.Date(2024, 5, 1, 12, 5, 0, 123000000, time.UTC)
+	if !got[0].OperationStart.Equal(want) || got[0].OperationStart.Location() != time.UTC || got[0].OperationEnd.Valid() {
+		t.Errorf("ListJobHistory() run started %s and ended %s, want started %s and not ended", got[0].OperationStart, got[0].OperationEnd, want)
+	}
+}
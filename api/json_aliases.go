@@ -0,0 +1,175 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// jsonAliasTag is the struct tag listing the names other Command releases give a field's JSON key, separated by
+// commas, e.g.
+//
+//	CertStoreType int `json:"CertStoreType" jsonalias:"StoreType"`
+//
+// The built-in codecs decode a key named by an alias into the field when the response does not also have the field's
+// own key. Keys are matched case-insensitively, as with encoding/json, so aliases that only differ in case, such as Id
+// and ID, are not needed.
+const jsonAliasTag = "jsonalias"
+
+// aliasedTypes caches whether a type, or a type it holds, has fields with aliases.
+var aliasedTypes sync.Map
+
+// applyJSONAliases renames the keys of data that are aliases of the fields of t, the type data is decoded into, to the
+// fields' JSON names. data is returned as is if t has no aliases, nothing was renamed, or data cannot be parsed, which
+// is left for the codec to report.
+func applyJSONAliases(data []byte, t reflect.Type) []byte {
+	if !hasJSONAliases(t) {
+		return data
+	}
+	var raw interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return data
+	}
+	if !renameJSONAliases(raw, t) {
+		return data
+	}
+	renamed, err := json.Marshal(raw)
+	if err != nil {
+		return data
+	}
+	return renamed
+}
+
+// renameJSONAliases renames the alias keys of the objects in x, a generically decoded JSON document, in place, and
+// reports whether any were renamed.
+func renameJSONAliases(x interface{}, t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if x == nil || reflect.PtrTo(t).Implements(jsonUnmarshalerType) || !hasJSONAliases(t) {
+		return false
+	}
+
+	renamed := false
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		items, _ := x.([]interface{})
+		for _, item := range items {
+			renamed = renameJSONAliases(item, t.Elem()) || renamed
+		}
+	case reflect.Map:
+		obj, _ := x.(map[string]interface{})
+		for _, v := range obj {
+			renamed = renameJSONAliases(v, t.Elem()) || renamed
+		}
+	case reflect.Struct:
+		obj, ok := x.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		aliases := jsonFieldAliases(t)
+		present := make(map[string]bool, len(obj))
+		for k := range obj {
+			present[strings.ToLower(k)] = true
+		}
+		for k, v := range obj {
+			name, ok := aliases[strings.ToLower(k)]
+			if !ok || present[strings.ToLower(name)] {
+				continue
+			}
+			delete(obj, k)
+			obj[name] = v
+			present[strings.ToLower(name)] = true
+			renamed = true
+		}
+		fields := jsonFieldTypes(t)
+		for k, v := range obj {
+			if ft, ok := fields[strings.ToLower(k)]; ok {
+				renamed = renameJSONAliases(v, ft) || renamed
+			}
+		}
+	}
+	return renamed
+}
+
+// jsonFieldAliases returns the JSON names of the fields of the struct type t that have aliases, keyed by the
+// lower-case alias. Aliases of embedded structs are promoted, as jsonFieldTypes promotes their fields.
+func jsonFieldAliases(t reflect.Type) map[string]string {
+	aliases := map[string]string{}
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		for _, alias := range strings.Split(f.Tag.Get(jsonAliasTag), ",") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				aliases[strings.ToLower(alias)] = name
+			}
+		}
+	}
+	for _, et := range embedded {
+		for alias, name := range jsonFieldAliases(et) {
+			if _, ok := aliases[alias]; !ok {
+				aliases[alias] = name
+			}
+		}
+	}
+	return aliases
+}
+
+// hasJSONAliases reports whether t, or a type it holds, has fields with aliases.
+func hasJSONAliases(t reflect.Type) bool {
+	if has, ok := aliasedTypes.Load(t); ok {
+		return has.(bool)
+	}
+	has := typeHasJSONAliases(t, map[reflect.Type]bool{})
+	aliasedTypes.Store(t, has)
+	return has
+}
+
+func typeHasJSONAliases(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if visiting[t] || reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		return false
+	}
+	visiting[t] = true
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return typeHasJSONAliases(t.Elem(), visiting)
+	case reflect.Struct:
+		if len(jsonFieldAliases(t)) > 0 {
+			return true
+		}
+		for _, ft := range jsonFieldTypes(t) {
+			if typeHasJSONAliases(ft, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// commandReleases are the Command releases whose captured responses are in testdata.
+var commandReleases = []string{"command-10", "command-12"}
+
+func TestJSONAliases_CapturedPayloads(t *testing.T) {
+	for _, codec := range []struct {
+		name  string
+		codec Codec
+	}{{"Lenient", LenientCodec}, {"Strict", StrictCodec}} {
+		t.Run(codec.name, func(t *testing.T) {
+			var stores []GetCertificateStoreResponse
+			for _, release := range commandReleases {
+				data, err := os.ReadFile(filepath.Join("testdata", release, "certificate_store.json"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				var store GetCertificateStoreResponse
+				if err := codec.codec.Decode(data, &store); err != nil {
+					t.Fatalf("Decode(%s) error = %v", release, err)
+				}
+				if store.CertStoreType != 105 || store.StorePath != "/etc/ssl/app.pem" || !store.Approved {
+					t.Errorf("Decode(%s) = %+v", release, store)
+				}
+				stores = append(stores, store)
+			}
+			if !reflect.DeepEqual(stores[0], stores[1]) {
+				t.Errorf("releases decode differently:\n%+v\n%+v", stores[0], stores[1])
+			}
+		})
+	}
+}
+
+func TestClient_GetCertificateStoreByID_CommandReleases(t *testing.T) {
+	for _, release := range commandReleases {
+		t.Run(release, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", release, "certificate_store.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write(data)
+			})
+			store, err := c.GetCertificateStoreByID("3f2a5c1e-8b7d-4e6f-9a0b-1c2d3e4f5a6b")
+			if err != nil {
+				t.Fatalf("GetCertificateStoreByID() error = %v", err)
+			}
+			if store.CertStoreType != 105 || store.Properties["Owner"] == nil {
+				t.Errorf("GetCertificateStoreByID() = %+v", store)
+			}
+		})
+	}
+}
+
+func TestApplyJSONAliases(t *testing.T) {
+	storeType := reflect.TypeOf(GetCertificateStoreResponse{})
+
+	// The field's own key wins over an alias.
+	var store GetCertificateStoreResponse
+	if err := LenientCodec.Decode([]byte(`{"StoreType": 1, "certstoretype": 2}`), &store); err != nil || store.CertStoreType != 2 {
+		t.Errorf("Decode() = %d, %v, want 2", store.CertStoreType, err)
+	}
+
+	for _, data := range []string{
+		`{"CertStoreType": 105, "ClientMachine": "web01"}`,
+		`{"StoreType": `,
+	} {
+		if got := applyJSONAliases([]byte(data), storeType); !bytes.Equal(got, []byte(data)) {
+			t.Errorf("applyJSONAliases(%s) = %s, want it unchanged", data, got)
+		}
+	}
+	if got := applyJSONAliases([]byte(`{"StoreType": 105}`), reflect.TypeOf(CertificateStoreType{})); string(got) != `{"StoreType": 105}` {
+		t.Errorf("applyJSONAliases() renamed the keys of a type without aliases: %s", got)
+	}
+
+	var list []CertStoreTypeResponse
+	data := []byte(`[{"ShortName": "PEM", "PrivateKeyAllowed": "Optional", "JobProperties": ["Owner"]}]`)
+	if err := StrictCodec.Decode(data, &list); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if len(list) != 1 || list[0].PrivateKeyAllowed != "Optional" || !reflect.DeepEqual(list[0].JobProperties, []string{"Owner"}) {
+		t.Errorf("Decode() = %+v", list)
+	}
+}
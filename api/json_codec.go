@@ -0,0 +1,258 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Codec decodes the JSON body of a Keyfactor response into v, a non-nil pointer. Set AuthConfig.Codec to choose how
+// tolerant the client is of responses that do not match its models.
+type Codec interface {
+	Decode(data []byte, v interface{}) error
+}
+
+var (
+	// LenientCodec is the default Codec. It tolerates the malformed JSON some Command releases return: NaN and Infinity
+	// literals are decoded as null, numbers and booleans sent as strings are decoded into numeric and boolean fields,
+	// numbers sent into string fields are kept as their text, and unknown fields are ignored. Keys are matched to fields
+	// case-insensitively, as with encoding/json, and the names other Command releases give a model's fields are decoded
+	// into them.
+	LenientCodec Codec = lenientCodec{}
+	// StrictCodec decodes responses with encoding/json and fails on fields the models do not define, so that changes to
+	// the API are noticed rather than silently dropped. Field names of other Command releases that a model knows are
+	// accepted.
+	StrictCodec Codec = strictCodec{}
+)
+
+type lenientCodec struct{}
+
+func (lenientCodec) Decode(data []byte, v interface{}) error {
+	data = replaceNonFiniteLiterals(data)
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return json.Unmarshal(data, v)
+	}
+	data = applyJSONAliases(data, rv.Elem().Type())
+
+	// Most responses are well formed, so decode into a scratch value first and only normalize on a type mismatch.
+	scratch := reflect.New(rv.Elem().Type())
+	err := json.Unmarshal(data, scratch.Interface())
+	if err == nil {
+		rv.Elem().Set(scratch.Elem())
+		return nil
+	}
+	if _, ok := err.(*json.UnmarshalTypeError); !ok {
+		return err
+	}
+
+	var raw interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	normalized, err := json.Marshal(coerceJSON(raw, rv.Elem().Type()))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(normalized, v)
+}
+
+type strictCodec struct{}
+
+func (strictCodec) Decode(data []byte, v interface{}) error {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		data = applyJSONAliases(data, rv.Elem().Type())
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// decodeResponse decodes a response body with the client's Codec. An empty body returns io.EOF, as json.Decoder does.
+func (c *Client) decodeResponse(r io.Reader, v interface{}) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return io.EOF
+	}
+	codec := c.codec
+	if codec == nil {
+		codec = LenientCodec
+	}
+	return codec.Decode(data, v)
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// coerceJSON converts the values of a generically decoded JSON document to the JSON types that t expects, where that
+// can be done without losing information. Values that cannot be converted are left for encoding/json to reject.
+func coerceJSON(x interface{}, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if x == nil || reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		return x
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		s, ok := x.(string)
+		if !ok {
+			return x
+		}
+		s = strings.TrimSpace(s)
+		if s == "" || isNonFiniteLiteral(s) {
+			return nil
+		}
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return x
+		}
+		return json.Number(s)
+	case reflect.Bool:
+		if s, ok := x.(string); ok {
+			if b, err := strconv.ParseBool(strings.TrimSpace(s)); err == nil {
+				return b
+			}
+		}
+		return x
+	case reflect.String:
+		switch v := x.(type) {
+		case json.Number:
+			return string(v)
+		case bool:
+			return strconv.FormatBool(v)
+		}
+		return x
+	case reflect.Slice, reflect.Array:
+		items, ok := x.([]interface{})
+		if !ok {
+			return x
+		}
+		for i := range items {
+			items[i] = coerceJSON(items[i], t.Elem())
+		}
+		return items
+	case reflect.Map:
+		obj, ok := x.(map[string]interface{})
+		if !ok {
+			return x
+		}
+		for k, v := range obj {
+			obj[k] = coerceJSON(v, t.Elem())
+		}
+		return obj
+	case reflect.Struct:
+		obj, ok := x.(map[string]interface{})
+		if !ok {
+			return x
+		}
+		fields := jsonFieldTypes(t)
+		for k, v := range obj {
+			if ft, ok := fields[strings.ToLower(k)]; ok {
+				obj[k] = coerceJSON(v, ft)
+			}
+		}
+		return obj
+	default:
+		return x
+	}
+}
+
+// jsonFieldTypes returns the types of the fields encoding/json decodes into a struct of type t, keyed by the lower-case
+// JSON name. Fields of embedded structs are promoted unless t has a field of the same name.
+func jsonFieldTypes(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = f.Type
+	}
+	for _, et := range embedded {
+		for name, ft := range jsonFieldTypes(et) {
+			if _, ok := fields[name]; !ok {
+				fields[name] = ft
+			}
+		}
+	}
+	return fields
+}
+
+// isNonFiniteLiteral reports whether s is one of the non-finite float literals .NET serializers emit.
+func isNonFiniteLiteral(s string) bool {
+	switch s {
+	case "NaN", "Infinity", "-Infinity", "+Infinity":
+		return true
+	}
+	return false
+}
+
+// replaceNonFiniteLiterals replaces the bare NaN and Infinity literals outside strings in data, which are not valid
+// JSON, with null.
+func replaceNonFiniteLiterals(data []byte) []byte {
+	if !bytes.Contains(data, []byte("NaN")) && !bytes.Contains(data, []byte("Infinity")) {
+		return data
+	}
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		if inString {
+			out = append(out, b)
+			if b == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if b == '"' {
+				inString = false
+			}
+			continue
+		}
+		if b == '"' {
+			inString = true
+			out = append(out, b)
+			continue
+		}
+		replaced := false
+		for _, lit := range []string{"NaN", "Infinity", "-Infinity", "+Infinity"} {
+			if bytes.HasPrefix(data[i:], []byte(lit)) {
+				out = append(out, "null"...)
+				i += len(lit) - 1
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			out = append(out, b)
+		}
+	}
+	return out
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCodec_Decode(t *testing.T) {
+	type nested struct {
+		Attempts int
+		Enabled  bool
+	}
+	type model struct {
+		Id       int
+		Name     string
+		Ratio    float64
+		Duration *float64
+		Serial   string
+		Note     string
+		Items    []nested
+		Labels   map[string]int
+		Created  Timestamp
+		Raw      interface{}
+	}
+	ratio := 0.5
+
+	tests := []struct {
+		name      string
+		codec     Codec
+		body      string
+		want      model
+		wantErr   bool
+		wantField bool
+	}{
+		{
+			name:  "WellFormed",
+			codec: LenientCodec,
+			body:  `{"Id": 7, "Name": "web01", "Ratio": 0.5}`,
+			want:  model{Id: 7, Name: "web01", Ratio: 0.5},
+		},
+		{
+			name:  "StringifiedNumbers",
+			codec: LenientCodec,
+			body:  `{"id": "7", "RATIO": " 0.5 ", "Items": [{"attempts": "3", "enabled": "true"}], "Labels": {"a": "1"}}`,
+			want:  model{Id: 7, Ratio: 0.5, Items: []nested{{Attempts: 3, Enabled: true}}, Labels: map[string]int{"a": 1}},
+		},
+		{
+			name:  "NonFinite",
+			codec: LenientCodec,
+			body:  `{"Id": 1, "Duration": NaN, "Ratio": "Infinity", "Note": "NaN stays", "Raw": -Infinity}`,
+			want:  model{Id: 1, Note: "NaN stays"},
+		},
+		{
+			name:  "NumberIntoString",
+			codec: LenientCodec,
+			body:  `{"Serial": 1234567890123, "Duration": "0.5", "Unknown": true}`,
+			want:  model{Serial: "1234567890123", Duration: &ratio},
+		},
+		{
+			name:    "Invalid",
+			codec:   LenientCodec,
+			body:    `{"Id": "seven"}`,
+			wantErr: true,
+		},
+		{
+			name:  "StrictWellFormed",
+			codec: StrictCodec,
+			body:  `{"id": 7, "Created": "2024-01-02T03:04:05Z"}`,
+			want:  model{Id: 7},
+		},
+		{
+			name:      "StrictUnknownField",
+			codec:     StrictCodec,
+			body:      `{"Id": 7, "Unknown": true}`,
+			wantErr:   true,
+			wantField: true,
+		},
+		{
+			name:    "StrictStringifiedNumber",
+			codec:   StrictCodec,
+			body:    `{"Id": "7"}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got model
+			err := tt.codec.Decode([]byte(tt.body), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if tt.wantField && !strings.Contains(err.Error(), "unknown field") {
+					t.Errorf("Decode() error = %v, want unknown field error", err)
+				}
+				return
+			}
+			got.Created = Timestamp{}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_decodeResponse(t *testing.T) {
+	body := `[{"Id": "12", "Message": "ok", "Future": "field"}]`
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	})
+
+	logs, err := c.SearchAuditLogs("", nil)
+	if err != nil {
+		t.Fatalf("SearchAuditLogs() error = %v", err)
+	}
+	if len(logs) != 1 || logs[0].Id != 12 {
+		t.Errorf("SearchAuditLogs() = %+v, want one entry with Id 12", logs)
+	}
+
+	c.codec = StrictCodec
+	if _, err := c.SearchAuditLogs("", nil); err == nil {
+		t.Errorf("SearchAuditLogs() with StrictCodec succeeded, want error")
+	}
+
+	if err := c.decodeResponse(strings.NewReader(" "), &logs); !errors.Is(err, io.EOF) {
+		t.Errorf("decodeResponse() of an empty body error = %v, want io.EOF", err)
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+
+	"github.com/Keyfactor/keyfactor-go-client/keystore"
+	"github.com/spbsoluble/go-pkcs12"
+)
+
+// Bundle decodes the PKCS#12 blob returned by EnrollPFX into a keystore.Bundle, which can then be re-encoded as a
+// PKCS#12 file with different encryption or as a Java KeyStore. password must be the password supplied in
+// EnrollPFXFctArgs.
+func (r *EnrollResponse) Bundle(password string) (*keystore.Bundle, error) {
+	if r == nil || r.CertificateInformation.PKCS12Blob == "" {
+		return nil, errors.New("enrollment response does not contain a PKCS#12 blob; enroll with CertFormat PFX")
+	}
+
+	pfxDer, err := base64.StdEncoding.DecodeString(r.CertificateInformation.PKCS12Blob)
+	if err != nil {
+		return nil, err
+	}
+
+	priv, leaf, chain, err := pkcs12.DecodeChain(pfxDer, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &keystore.Bundle{PrivateKey: priv, Certificate: leaf, Chain: chain}, nil
+}
+
+// RecoverBundle takes arguments for a certificate ID or thumbprint to facilitate a call to Keyfactor that recovers
+// the certificate and its private key, returning them as a keystore.Bundle ready to be encoded as PKCS#12 or JKS.
+// The private key must have been retained by Keyfactor.
+func (c *Client) RecoverBundle(certId int, thumbprint string) (*keystore.Bundle, error) {
+	return c.RecoverBundleContext(context.Background(), certId, thumbprint)
+}
+
+// RecoverBundleContext is like RecoverBundle but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) RecoverBundleContext(ctx context.Context, certId int, thumbprint string) (*keystore.Bundle, error) {
+	c.infof("Recovering certificate bundle (id: %d, thumbprint: %s)", certId, thumbprint)
+
+	// The recovery password only protects the PFX in transit, so a throwaway value is used.
+	password, err := randomPassword()
+	if err != nil {
+		return nil, err
+	}
+
+	priv, leaf, chain, err := c.RecoverCertificateContext(ctx, certId, thumbprint, "", "", password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &keystore.Bundle{PrivateKey: priv, Certificate: leaf, Chain: chain}, nil
+}
+
+// randomPassword returns a random hex string suitable for protecting a PFX in transit.
+func randomPassword() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+
+	"github.com/Keyfactor/keyfactor-go-client/keystore"
+)
+
+func TestEnrollResponse_Bundle(t *testing.T) {
+	chain := newTestChain(t)
+	pfx, err := (&keystore.Bundle{PrivateKey: chain.leafKey, Certificate: chain.leaf}).PKCS12("enroll-password", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := base64.StdEncoding.EncodeToString(pfx)
+
+	tests := []struct {
+		name     string
+		resp     *EnrollResponse
+		password string
+		want     *x509.Certificate
+		wantErr  bool
+	}{
+		{
+			name:     "PFXEnrollment",
+			resp:     &EnrollResponse{CertificateInformation: CertificateInformation{PKCS12Blob: blob}},
+			password: "enroll-password",
+			want:     chain.leaf,
+		},
+		{
+			name:     "WrongPassword",
+			resp:     &EnrollResponse{CertificateInformation: CertificateInformation{PKCS12Blob: blob}},
+			password: "wrong",
+			wantErr:  true,
+		},
+		{
+			name:    "NoBlob",
+			resp:    &EnrollResponse{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.resp.Bundle(tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Bundle() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !got.Certificate.Equal(tt.want) {
+				t.Errorf("Bundle() certificate = %s, want %s", got.Certificate.Subject, tt.want.Subject)
+			}
+			if got.PrivateKey == nil {
+				t.Errorf("Bundle() returned no private key")
+			}
+		})
+	}
+}
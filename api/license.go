@@ -0,0 +1,68 @@
+package api
+
+import (
+	"fmt"
+	"time"
+)
+
+// GetLicense hits the /License endpoint with a GET request and returns the installed Keyfactor Command license,
+// including its expiration date, licensed products, and per-feature quantities.
+func (c *Client) GetLicense() (*GetLicenseResponse, error) {
+	c.infof("Getting Keyfactor license information")
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "GET",
+		Endpoint: "License",
+		Headers:  headers,
+		Payload:  nil,
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonResp := &GetLicenseResponse{}
+	err = c.decodeResponse(resp.Body, jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// Expiration returns the license expiration date.
+func (l *LicenseData) Expiration() (time.Time, error) {
+	if !l.ExpirationDate.Valid() {
+		return time.Time{}, fmt.Errorf("unable to parse license expiration date %q", l.ExpirationDate.Raw)
+	}
+	return l.ExpirationDate.Time, nil
+}
+
+// ExpiresWithin reports whether the license, or any enabled feature with its own expiration date, lapses within d
+// of now. Dates that cannot be parsed are treated as expiring so that monitoring errs on the side of alerting.
+func (l *LicenseData) ExpiresWithin(d time.Duration) bool {
+	deadline := time.Now().Add(d)
+	expired := func(t Timestamp) bool {
+		return !t.Valid() || t.Before(deadline)
+	}
+
+	if expired(l.ExpirationDate) {
+		return true
+	}
+	for _, product := range l.LicensedProducts {
+		for _, feature := range product.LicensedFeatures {
+			if feature.Enabled && feature.ExpirationDate != (Timestamp{}) && expired(feature.ExpirationDate) {
+				return true
+			}
+		}
+	}
+	return false
+}
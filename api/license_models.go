@@ -0,0 +1,41 @@
+package api
+
+// GetLicenseResponse holds the response data returned by /License
+type GetLicenseResponse struct {
+	KeyfactorVersion string      `json:"KeyfactorVersion"`
+	LicenseData      LicenseData `json:"LicenseData"`
+}
+
+// LicenseData describes the Keyfactor Command license currently installed.
+type LicenseData struct {
+	LicenseId        string            `json:"LicenseId"`
+	Customer         LicensedCustomer  `json:"Customer"`
+	IssuedDate       Timestamp         `json:"IssuedDate"`
+	ExpirationDate   Timestamp         `json:"ExpirationDate"`
+	LicensedProducts []LicensedProduct `json:"LicensedProducts"`
+}
+
+// LicensedCustomer identifies the customer a license was issued to.
+type LicensedCustomer struct {
+	Name string `json:"Name"`
+	Id   string `json:"Id"`
+}
+
+// LicensedProduct is a Keyfactor product covered by the license, along with its licensed features.
+type LicensedProduct struct {
+	ProductId        string            `json:"ProductId"`
+	DisplayName      string            `json:"DisplayName"`
+	MajorRev         string            `json:"MajorRev"`
+	MinorRev         string            `json:"MinorRev"`
+	LicensedFeatures []LicensedFeature `json:"LicensedFeatures"`
+}
+
+// LicensedFeature is a single licensed feature. Quantity holds the licensed count, such as the number of
+// certificates or orchestrators, and ExpirationDate is set when the feature expires separately from the license.
+type LicensedFeature struct {
+	FeatureID      string    `json:"FeatureID"`
+	DisplayName    string    `json:"DisplayName"`
+	Enabled        bool      `json:"Enabled"`
+	Quantity       int       `json:"Quantity"`
+	ExpirationDate Timestamp `json:"ExpirationDate"`
+}
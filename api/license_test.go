@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient_GetLicense(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/KeyfactorAPI/License" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"KeyfactorVersion": "10.4.1",
+			"LicenseData": {
+				"LicenseId": "abc-123",
+				"Customer": {"Name": "Acme", "Id": "42"},
+				"IssuedDate": "2023-01-01T00:00:00",
+				"ExpirationDate": "2024-01-01T00:00:00",
+				"LicensedProducts": [{
+					"ProductId": "Command",
+					"DisplayName": "Keyfactor Command",
+					"LicensedFeatures": [{"FeatureID": "Certificates", "Enabled": true, "Quantity": 50000}]
+				}]
+			}
+		}`))
+	})
+
+	got, err := c.GetLicense()
+	if err != nil {
+		t.Fatalf("GetLicense() error = %v", err)
+	}
+	if got.KeyfactorVersion != "10.4.1" || got.LicenseData.Customer.Name != "Acme" {
+		t.Errorf("GetLicense() = %+v", got)
+	}
+	if q := got.LicenseData.LicensedProducts[0].LicensedFeatures[0].Quantity; q != 50000 {
+		t.Errorf("GetLicense() feature quantity = %d, want 50000", q)
+	}
+	exp, err := got.LicenseData.Expiration()
+	if err != nil || !exp.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expiration() = %v, %v", exp, err)
+	}
+}
+
+func TestLicenseData_ExpiresWithin(t *testing.T) {
+	later := NewTimestamp(time.Now().Add(365 * 24 * time.Hour))
+	soon := NewTimestamp(time.Now().Add(7 * 24 * time.Hour))
+
+	tests := []struct {
+		name    string
+		license LicenseData
+		want    bool
+	}{
+		{name: "Valid", license: LicenseData{ExpirationDate: later}, want: false},
+		{name: "LicenseExpiring", license: LicenseData{ExpirationDate: soon}, want: true},
+		{
+			name: "FeatureExpiring",
+			license: LicenseData{ExpirationDate: later, LicensedProducts: []LicensedProduct{{
+				LicensedFeatures: []LicensedFeature{{Enabled: true, ExpirationDate: soon}},
+			}}},
+			want: true,
+		},
+		{
+			name: "DisabledFeatureIgnored",
+			license: LicenseData{ExpirationDate: later, LicensedProducts: []LicensedProduct{{
+				LicensedFeatures: []LicensedFeature{{Enabled: false, ExpirationDate: soon}},
+			}}},
+			want: false,
+		},
+		{name: "Unparseable", license: LicenseData{ExpirationDate: Timestamp{Raw: "never"}}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.license.ExpiresWithin(30 * 24 * time.Hour); got != tt.want {
+				t.Errorf("ExpiresWithin() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
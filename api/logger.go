@@ -0,0 +1,250 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+)
+
+// LogLevel is the severity of a message logged by a client.
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota + 1
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+// String returns the name of the level, e.g. "INFO".
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "DEBUG"
+	case LogLevelInfo:
+		return "INFO"
+	case LogLevelWarn:
+		return "WARN"
+	case LogLevelError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", int(l))
+	}
+}
+
+// LogFields holds the structured fields of a log message. Messages carry the client operation that logged them, such
+// as "GetCertificate", in "operation", and requests to Keyfactor carry "method", "endpoint", and "correlation_id".
+type LogFields map[string]interface{}
+
+// Logger receives the messages a client logs, set with AuthConfig.Logger. Implementations must be safe for concurrent
+// use.
+type Logger interface {
+	Log(level LogLevel, msg string, fields LogFields)
+}
+
+// LoggerFunc adapts a function to a Logger.
+type LoggerFunc func(level LogLevel, msg string, fields LogFields)
+
+// Log calls f(level, msg, fields).
+func (f LoggerFunc) Log(level LogLevel, msg string, fields LogFields) {
+	f(level, msg, fields)
+}
+
+// NewStdLogger returns a Logger that writes messages at or above minLevel to l as "[LEVEL] message key=value ...",
+// the format Terraform reads log levels from. A nil l writes to the standard logger of the log package, which is where
+// messages go unless SetDefaultLogger or AuthConfig.Logger says otherwise.
+func NewStdLogger(l *log.Logger, minLevel LogLevel) Logger {
+	return LoggerFunc(func(level LogLevel, msg string, fields LogFields) {
+		if level < minLevel {
+			return
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "[%s] %s", level, msg)
+		for _, k := range sortedFieldKeys(fields) {
+			fmt.Fprintf(&b, " %s=%v", k, fields[k])
+		}
+		out := l
+		if out == nil {
+			out = log.Default()
+		}
+		out.Output(2, b.String())
+	})
+}
+
+// NewJSONLogger returns a Logger that writes messages at or above minLevel to w as JSON objects, one per line, with
+// the fields alongside "time", "level", and "msg".
+func NewJSONLogger(w io.Writer, minLevel LogLevel) Logger {
+	var mu sync.Mutex
+	return LoggerFunc(func(level LogLevel, msg string, fields LogFields) {
+		if level < minLevel {
+			return
+		}
+		entry := make(map[string]interface{}, len(fields)+3)
+		for k, v := range fields {
+			entry[k] = v
+		}
+		entry["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+		entry["level"] = level.String()
+		entry["msg"] = msg
+		line, err := json.Marshal(entry)
+		if err != nil {
+			line, _ = json.Marshal(map[string]string{"level": level.String(), "msg": msg, "error": err.Error()})
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		w.Write(append(line, '\n'))
+	})
+}
+
+// stdLogger is the default Logger, until SetDefaultLogger replaces it.
+var stdLogger = NewStdLogger(nil, LogLevelDebug)
+
+// defaultLogger holds the loggerHolder of the Logger set with SetDefaultLogger.
+var defaultLogger atomic.Value
+
+// loggerHolder wraps a Logger so that Loggers of different types can be stored in defaultLogger.
+type loggerHolder struct {
+	Logger
+}
+
+// SetDefaultLogger sets the Logger of clients created without AuthConfig.Logger and of helpers that log without a
+// client, such as GetTemplateResponse.ValidateEnrollment. A nil l restores the standard logger of the log package.
+func SetDefaultLogger(l Logger) {
+	if l == nil {
+		l = stdLogger
+	}
+	defaultLogger.Store(loggerHolder{l})
+}
+
+// currentDefaultLogger returns the Logger set with SetDefaultLogger.
+func currentDefaultLogger() Logger {
+	if h, ok := defaultLogger.Load().(loggerHolder); ok {
+		return h.Logger
+	}
+	return stdLogger
+}
+
+// loggerOrDefault returns the client's logger, or the default logger if it has none.
+func (c *Client) loggerOrDefault() Logger {
+	if c == nil || c.logger == nil {
+		return currentDefaultLogger()
+	}
+	return c.logger
+}
+
+// Logger returns the Logger the client logs to: AuthConfig.Logger, or the default logger if it was not set. Helpers
+// built on a client, such as the importer package, log to it too.
+func (c *Client) Logger() Logger {
+	return c.loggerOrDefault()
+}
+
+func (c *Client) debugf(format string, args ...interface{}) {
+	logTo(c.loggerOrDefault(), LogLevelDebug, nil, format, args...)
+}
+
+func (c *Client) infof(format string, args ...interface{}) {
+	logTo(c.loggerOrDefault(), LogLevelInfo, nil, format, args...)
+}
+
+func (c *Client) warnf(format string, args ...interface{}) {
+	logTo(c.loggerOrDefault(), LogLevelWarn, nil, format, args...)
+}
+
+func (c *Client) errorf(format string, args ...interface{}) {
+	logTo(c.loggerOrDefault(), LogLevelError, nil, format, args...)
+}
+
+// logWith logs a message with fields, such as the endpoint of a request.
+func (c *Client) logWith(level LogLevel, fields LogFields, format string, args ...interface{}) {
+	logTo(c.loggerOrDefault(), level, fields, format, args...)
+}
+
+// logTo formats a message and logs it to logger with fields and the operation it was logged by.
+func logTo(logger Logger, level LogLevel, fields LogFields, format string, args ...interface{}) {
+	if logger == nil {
+		logger = currentDefaultLogger()
+	}
+	all := make(LogFields, len(fields)+1)
+	if op := callerOperation(); op != "" {
+		all["operation"] = op
+	}
+	for k, v := range fields {
+		all[k] = v
+	}
+	logger.Log(level, fmt.Sprintf(format, args...), all)
+}
+
+// apiPackage is the import path of this package, which function names in stack frames start with.
+var apiPackage = reflect.TypeOf(Client{}).PkgPath() + "."
+
+// callerOperation returns the operation a message is logged by: the innermost exported function or method of this
+// package on the stack, e.g. "GetCertificate" for (*Client).GetCertificateContext, or "Job.WaitForCompletion". It
+// returns "" if unexported code, such as a background goroutine, was not called by one.
+func callerOperation() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, apiPackage) {
+			if op, ok := operationName(strings.TrimPrefix(frame.Function, apiPackage)); ok {
+				return op
+			}
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// operationName returns the operation named by a function name relative to this package, such as
+// "(*Client).GetCertificateContext.func1", and whether the function and its receiver, if any, are exported. Methods of
+// Client are named without their receiver, and the Context suffix of context variants is dropped.
+func operationName(function string) (string, bool) {
+	if i := strings.Index(function, "["); i >= 0 {
+		function = function[:i] + function[strings.LastIndex(function, "]")+1:]
+	}
+	var parts []string
+	for _, part := range strings.Split(function, ".") {
+		// Closures are named after the function that declares them.
+		if strings.HasPrefix(part, "func") && strings.Trim(part[4:], "0123456789") == "" {
+			break
+		}
+		parts = append(parts, strings.TrimSuffix(strings.Trim(part, "(*)"), "-fm"))
+	}
+	for _, part := range parts {
+		if part == "" || !unicode.IsUpper([]rune(part)[0]) {
+			return "", false
+		}
+	}
+	if len(parts) == 0 || len(parts) > 2 {
+		return "", false
+	}
+	name := parts[len(parts)-1]
+	if name != "Context" {
+		name = strings.TrimSuffix(name, "Context")
+	}
+	if len(parts) == 2 && parts[0] != "Client" {
+		name = parts[0] + "." + name
+	}
+	return name, true
+}
+
+// sortedFieldKeys returns the keys of fields in order.
+func sortedFieldKeys(fields LogFields) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
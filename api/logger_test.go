@@ -0,0 +1,105 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOperationName(t *testing.T) {
+	tests := []struct {
+		function string
+		want     string
+		wantOk   bool
+	}{
+		{function: "(*Client).GetCertificateContext", want: "GetCertificate", wantOk: true},
+		{function: "(*Client).GetCertificateContextContext", want: "GetCertificateContext", wantOk: true},
+		{function: "(*Client).ListCertificateCollections.func1.2", want: "ListCertificateCollections", wantOk: true},
+		{function: "(*Job).WaitForCompletion", want: "Job.WaitForCompletion", wantOk: true},
+		{function: "(*Client).sendRequest"},
+		{function: "(*cacheTransport).RoundTrip"},
+		{function: "fetchAll[...]"},
+		{function: "glob..func1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.function, func(t *testing.T) {
+			got, ok := operationName(tt.function)
+			if got != tt.want && tt.wantOk || ok != tt.wantOk {
+				t.Errorf("operationName() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestClient_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"Id": 10, "Name": "Web Servers"}]`))
+	})
+	c.logger = NewJSONLogger(&buf, LogLevelInfo)
+
+	var global bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&global)
+
+	if _, err := c.ListCertificateCollections(); err != nil {
+		t.Fatalf("ListCertificateCollections() error = %v", err)
+	}
+	if global.Len() != 0 {
+		t.Errorf("client with a Logger wrote to the global logger: %s", global.String())
+	}
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("logged line %q is not JSON: %v", line, err)
+		}
+		if entry["level"] == "DEBUG" {
+			t.Errorf("logged %v below the minimum level", entry)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("logged %d entries, want 2: %s", len(entries), buf.String())
+	}
+	if entries[0]["operation"] != "ListCertificateCollections" || entries[0]["msg"] != "Listing Keyfactor certificate collections" {
+		t.Errorf("first entry = %v", entries[0])
+	}
+	request := entries[1]
+	if request["operation"] != "ListCertificateCollections" || request["endpoint"] != "CertificateCollections" ||
+		request["method"] != "GET" || request["correlation_id"] == "" || request["level"] != "INFO" {
+		t.Errorf("request entry = %v", request)
+	}
+}
+
+func TestSetDefaultLogger(t *testing.T) {
+	var messages []string
+	SetDefaultLogger(LoggerFunc(func(level LogLevel, msg string, fields LogFields) {
+		messages = append(messages, level.String()+" "+fields["operation"].(string)+": "+msg)
+	}))
+	defer SetDefaultLogger(nil)
+
+	template := &GetTemplateResponse{CommonName: "WebServer", TemplateRegexes: []TemplateRegex{{SubjectPart: "DNS", RegEx: "("}}}
+	if err := template.ValidateEnrollment(&SANs{DNS: []string{"www.example.com"}}, nil, nil); err != nil {
+		t.Fatalf("ValidateEnrollment() error = %v", err)
+	}
+	want := "WARN GetTemplateResponse.ValidateEnrollment: Ignoring invalid DNS regex on template WebServer: error parsing regexp: missing closing ): `(`"
+	if len(messages) != 1 || messages[0] != want {
+		t.Errorf("logged %q, want %q", messages, want)
+	}
+}
+
+func TestNewStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewStdLogger(log.New(&buf, "", 0), LogLevelWarn)
+	logger.Log(LogLevelInfo, "dropped", nil)
+	logger.Log(LogLevelWarn, "Keyfactor is slow", LogFields{"operation": "GetCertificate", "endpoint": "Certificates/1"})
+	if got, want := buf.String(), "[WARN] Keyfactor is slow endpoint=Certificates/1 operation=GetCertificate\n"; got != want {
+		t.Errorf("NewStdLogger() wrote %q, want %q", got, want)
+	}
+}
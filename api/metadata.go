@@ -3,8 +3,10 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 
 	"github.com/Keyfactor/keyfactor-go-client-sdk/api/keyfactor"
 )
@@ -19,6 +21,11 @@ import (
 // CertificateMetadata or Metadata are blank, any metadata associated with a certificate
 // will be erased.
 func (c *Client) UpdateMetadata(um *UpdateMetadataArgs) error {
+	return c.UpdateMetadataContext(context.Background(), um)
+}
+
+// UpdateMetadataContext is like UpdateMetadata but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) UpdateMetadataContext(ctx context.Context, um *UpdateMetadataArgs) error {
 	// Metadata in Keyfactor varies between deployments
 	// Instead of hard coding possibilities, take array of string tuple types and create
 	// string-indexed array of interfaces for JSON compilation
@@ -59,10 +66,9 @@ func (c *Client) UpdateMetadata(um *UpdateMetadataArgs) error {
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
-	resp, err := apiClient.CertificateApi.CertificateUpdateMetadata(context.Background()).XKeyfactorRequestedWith(xKeyfactorRequestedWith).MetadataUpdate(newReq).CollectionId(int32(um.CollectionId)).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, err := apiClient.CertificateApi.CertificateUpdateMetadata(ctx).XKeyfactorRequestedWith(xKeyfactorRequestedWith).MetadataUpdate(newReq).CollectionId(int32(um.CollectionId)).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
 		return err
@@ -74,21 +80,108 @@ func (c *Client) UpdateMetadata(um *UpdateMetadataArgs) error {
 	return nil
 }
 
+// UpdateMetadataByQuery takes arguments for a certificate query string, such as one built with the query package, and
+// a map of metadata field names to values, to facilitate a single call to Keyfactor that sets the fields on every
+// matching certificate, e.g. to backfill a new field:
+//
+//	err := c.UpdateMetadataByQuery(`IssuedDN -contains "prod.example.com"`, map[string]string{"Environment": "prod"}, nil)
+//
+// Unlike UpdateMetadata, fields not named are left unchanged. opts may be nil; by default, values certificates
+// already have are kept. The fields must exist in Keyfactor. The query is required, so that a mistake cannot update
+// every certificate.
+func (c *Client) UpdateMetadataByQuery(q string, fields map[string]string, opts *UpdateMetadataByQueryOptions) error {
+	return c.UpdateMetadataByQueryContext(context.Background(), q, fields, opts)
+}
+
+// UpdateMetadataByQueryContext is like UpdateMetadataByQuery but uses ctx for the requests, allowing it to be
+// cancelled. Unless opts sets a CollectionId, the update is scoped to the collection set with WithCollection.
+func (c *Client) UpdateMetadataByQueryContext(ctx context.Context, q string, fields map[string]string, opts *UpdateMetadataByQueryOptions) error {
+	c.infof("Updating metadata of certificates matching query '%s'", q)
+
+	if q == "" {
+		return errors.New("query is required to update metadata by query")
+	}
+	if len(fields) == 0 {
+		return errors.New("metadata is required to update metadata by query")
+	}
+	if opts == nil {
+		opts = &UpdateMetadataByQueryOptions{}
+	}
+
+	known, err := c.GetAllMetadataFieldsContext(ctx)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	metadata := make([]keyfactor.ModelsMetadataSingleUpdateRequest, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, field := range known {
+			if field.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("metadata field %q does not exist in Keyfactor", name)
+		}
+		name, value, overwrite := name, fields[name], opts.OverwriteExisting
+		metadata = append(metadata, keyfactor.ModelsMetadataSingleUpdateRequest{
+			MetadataName:      &name,
+			Value:             &value,
+			OverwriteExisting: &overwrite,
+		})
+	}
+
+	collectionId := opts.CollectionId
+	if collectionId <= 0 {
+		collectionId = collectionFromContext(ctx)
+	}
+
+	xKeyfactorRequestedWith := "APIClient"
+	xKeyfactorApiVersion := "1"
+
+	apiClient := c.sdkClient()
+
+	newReq := keyfactor.ModelsMetadataAllUpdateRequest{Query: &q, Metadata: metadata}
+	updateReq := apiClient.CertificateApi.CertificateUpdateAllMetadata(ctx).XKeyfactorRequestedWith(xKeyfactorRequestedWith).MetadataUpdate(newReq).XKeyfactorApiVersion(xKeyfactorApiVersion)
+	if collectionId > 0 {
+		updateReq = updateReq.CollectionId(int32(collectionId))
+	}
+	resp, err := updateReq.Execute()
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("[ERROR] Something unexpected happened, PUT call to /Certificates/Metadata/All returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
 func (c *Client) GetAllMetadataFields() ([]MetadataField, error) {
+	return c.GetAllMetadataFieldsContext(context.Background())
+}
+
+// GetAllMetadataFieldsContext is like GetAllMetadataFields but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) GetAllMetadataFieldsContext(ctx context.Context) ([]MetadataField, error) {
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
-	resp, _, err := apiClient.MetadataFieldApi.MetadataFieldGetAllMetadataFields(context.Background()).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, _, err := apiClient.MetadataFieldApi.MetadataFieldGetAllMetadataFields(ctx).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
 		return nil, err
 	}
 
-	var newResp []MetadataField
+	newResp := make([]MetadataField, 0, len(resp))
 	for i := range resp {
 		mapResp, _ := resp[i].ToMap()
 		jsonData, _ := json.Marshal(mapResp)
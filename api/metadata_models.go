@@ -21,3 +21,12 @@ type MetadataField struct {
 	DefaultValue string `json:"DefaultValue"`
 	DisplayOrder int    `json:"DisplayOrder"`
 }
+
+// UpdateMetadataByQueryOptions holds the optional settings of UpdateMetadataByQuery.
+type UpdateMetadataByQueryOptions struct {
+	// OverwriteExisting replaces values the certificates already have. By default only certificates without a value
+	// for a field are updated, which suits backfilling a new field.
+	OverwriteExisting bool
+	// CollectionId scopes the update to a certificate collection when greater than zero.
+	CollectionId int
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 	"reflect"
 	"testing"
@@ -69,3 +70,53 @@ func TestClient_UpdateMetadata(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_UpdateMetadataByQuery(t *testing.T) {
+	var sent map[string]interface{}
+	var collection string
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/KeyfactorAPI/MetadataFields":
+			w.Write([]byte(`[{"Id": 1, "Name": "Environment"}, {"Id": 2, "Name": "Owner"}]`))
+		case r.Method == "PUT" && r.URL.Path == "/KeyfactorAPI/Certificates/Metadata/All":
+			collection = r.URL.Query().Get("collectionId")
+			json.NewDecoder(r.Body).Decode(&sent)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	q := `IssuedDN -contains "prod.example.com"`
+	err := c.UpdateMetadataByQuery(q, map[string]string{"Owner": "pki-team", "Environment": "prod"}, &UpdateMetadataByQueryOptions{CollectionId: 3})
+	if err != nil {
+		t.Fatalf("UpdateMetadataByQuery() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"Query": q,
+		"Metadata": []interface{}{
+			map[string]interface{}{"MetadataName": "Environment", "Value": "prod", "OverwriteExisting": false},
+			map[string]interface{}{"MetadataName": "Owner", "Value": "pki-team", "OverwriteExisting": false},
+		},
+	}
+	if !reflect.DeepEqual(sent, want) || collection != "3" {
+		t.Errorf("UpdateMetadataByQuery() sent %v to collection %q, want %v", sent, collection, want)
+	}
+
+	sent = nil
+	for name, fields := range map[string]map[string]string{
+		"UnknownField": {"CostCenter": "42"},
+		"NoFields":     {},
+	} {
+		if err := c.UpdateMetadataByQuery(q, fields, nil); err == nil {
+			t.Errorf("UpdateMetadataByQuery() %s succeeded, want error", name)
+		}
+	}
+	if err := c.UpdateMetadataByQuery("", map[string]string{"Owner": "x"}, nil); err == nil {
+		t.Errorf("UpdateMetadataByQuery() without a query succeeded, want error")
+	}
+	if sent != nil {
+		t.Errorf("UpdateMetadataByQuery() sent an update for an invalid request: %v", sent)
+	}
+}
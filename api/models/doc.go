@@ -0,0 +1,14 @@
+// Package models is where the request and response bodies of the Keyfactor Command API endpoints wrapped by the api
+// package are generated from the Command OpenAPI specification.
+//
+// Generation is not wired in yet: no generated models are committed, and the api package still uses its hand-written
+// types, so nothing imports this package. To generate the models of a Command release, point KEYFACTOR_OPENAPI_SPEC at
+// its specification, e.g. a copy of https://<host>/KeyfactorAPI/swagger/v1/swagger.json, and run
+//
+//	KEYFACTOR_OPENAPI_SPEC=swagger.json go generate ./api/models
+//
+// which writes models_gen.go. The endpoints to generate models for are listed in models.json, along with Go names for
+// schemas whose default names would be ambiguous. See internal/genmodels for the generator.
+package models
+
+//go:generate go run ../../internal/genmodels -spec $KEYFACTOR_OPENAPI_SPEC -config models.json -out models_gen.go
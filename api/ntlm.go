@@ -0,0 +1,187 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/md4"
+)
+
+// NTLM message flags, from MS-NLMP section 2.2.2.5.
+const (
+	ntlmNegotiateUnicode         = 0x00000001
+	ntlmRequestTarget            = 0x00000004
+	ntlmNegotiateNTLM            = 0x00000200
+	ntlmNegotiateAlwaysSign      = 0x00008000
+	ntlmNegotiateExtendedSession = 0x00080000
+	ntlmNegotiateTargetInfo      = 0x00800000
+	ntlmNegotiate128             = 0x20000000
+	ntlmNegotiate56              = 0x80000000
+
+	ntlmNegotiateFlags = ntlmNegotiateUnicode | ntlmRequestTarget | ntlmNegotiateNTLM | ntlmNegotiateAlwaysSign |
+		ntlmNegotiateExtendedSession | ntlmNegotiate128 | ntlmNegotiate56
+)
+
+// ntlmSignature starts every NTLM message.
+var ntlmSignature = []byte("NTLMSSP\x00")
+
+// ntlmAvTimestamp is the AV_PAIR ID of the server's timestamp in the target info of a challenge.
+const ntlmAvTimestamp = 7
+
+// ntlmChallenge holds the fields of an NTLM CHALLENGE_MESSAGE used to answer it.
+type ntlmChallenge struct {
+	flags           uint32
+	serverChallenge [8]byte
+	targetInfo      []byte
+}
+
+// ntlmNegotiateMessage returns the NEGOTIATE_MESSAGE that starts an NTLM handshake.
+func ntlmNegotiateMessage() []byte {
+	msg := make([]byte, 32)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 1)
+	binary.LittleEndian.PutUint32(msg[12:], ntlmNegotiateFlags)
+	// The domain and workstation fields are left empty.
+	return msg
+}
+
+// parseNTLMChallenge parses the CHALLENGE_MESSAGE a server answers a NEGOTIATE_MESSAGE with.
+func parseNTLMChallenge(msg []byte) (*ntlmChallenge, error) {
+	if len(msg) < 32 || !bytes.Equal(msg[:8], ntlmSignature) || binary.LittleEndian.Uint32(msg[8:]) != 2 {
+		return nil, errors.New("invalid NTLM challenge message")
+	}
+	c := &ntlmChallenge{flags: binary.LittleEndian.Uint32(msg[20:])}
+	copy(c.serverChallenge[:], msg[24:32])
+	if c.flags&ntlmNegotiateTargetInfo != 0 && len(msg) >= 48 {
+		length := int(binary.LittleEndian.Uint16(msg[40:]))
+		offset := int(binary.LittleEndian.Uint32(msg[44:]))
+		if offset+length > len(msg) {
+			return nil, errors.New("invalid NTLM challenge target info")
+		}
+		c.targetInfo = msg[offset : offset+length]
+	}
+	return c, nil
+}
+
+// timestamp returns the server's timestamp from the challenge's target info, if it has one.
+func (c *ntlmChallenge) timestamp() ([]byte, bool) {
+	info := c.targetInfo
+	for len(info) >= 4 {
+		id := binary.LittleEndian.Uint16(info)
+		length := int(binary.LittleEndian.Uint16(info[2:]))
+		if len(info) < 4+length || id == 0 {
+			break
+		}
+		if id == ntlmAvTimestamp && length == 8 {
+			return info[4:12], true
+		}
+		info = info[4+length:]
+	}
+	return nil, false
+}
+
+// ntlmAuthenticateMessage returns the AUTHENTICATE_MESSAGE answering challenge with an NTLMv2 response for the user
+// in domain. clientChallenge is 8 random bytes.
+func ntlmAuthenticateMessage(challenge *ntlmChallenge, domain, user, password string, clientChallenge []byte) []byte {
+	key := ntowfv2(domain, user, password)
+
+	timestamp, fromServer := challenge.timestamp()
+	if !fromServer {
+		timestamp = ntlmFiletime(time.Now())
+	}
+	temp := ntlmv2Temp(timestamp, clientChallenge, challenge.targetInfo)
+	ntProof := hmacMD5(key, challenge.serverChallenge[:], temp)
+	ntResponse := append(ntProof, temp...)
+	// A client must send an empty LMv2 response when the server sent a timestamp (MS-NLMP 3.1.5.1.2).
+	lmResponse := make([]byte, 24)
+	if !fromServer {
+		lmResponse = append(hmacMD5(key, challenge.serverChallenge[:], clientChallenge), clientChallenge...)
+	}
+
+	fields := [][]byte{lmResponse, ntResponse, utf16le(domain), utf16le(user), nil, nil}
+	const headerLen = 64
+	msg := make([]byte, headerLen)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 3)
+	offset := headerLen
+	for i, field := range fields {
+		pos := 12 + i*8
+		binary.LittleEndian.PutUint16(msg[pos:], uint16(len(field)))
+		binary.LittleEndian.PutUint16(msg[pos+2:], uint16(len(field)))
+		binary.LittleEndian.PutUint32(msg[pos+4:], uint32(offset))
+		offset += len(field)
+	}
+	binary.LittleEndian.PutUint32(msg[60:], challenge.flags)
+	for _, field := range fields {
+		msg = append(msg, field...)
+	}
+	return msg
+}
+
+// ntowfv2 returns the NTLMv2 response key of a user, from MS-NLMP section 3.3.2.
+func ntowfv2(domain, user, password string) []byte {
+	h := md4.New()
+	h.Write(utf16le(password))
+	return hmacMD5(h.Sum(nil), utf16le(strings.ToUpper(user)+domain))
+}
+
+// ntlmv2Temp returns the client blob of an NTLMv2 response.
+func ntlmv2Temp(timestamp, clientChallenge, targetInfo []byte) []byte {
+	temp := []byte{1, 1, 0, 0, 0, 0, 0, 0}
+	temp = append(temp, timestamp...)
+	temp = append(temp, clientChallenge...)
+	temp = append(temp, 0, 0, 0, 0)
+	temp = append(temp, targetInfo...)
+	return append(temp, 0, 0, 0, 0)
+}
+
+// ntlmFiletime encodes t as a Windows FILETIME, the number of 100ns intervals since 1601.
+func ntlmFiletime(t time.Time) []byte {
+	const epochDelta = 116444736000000000
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, uint64(t.UnixNano()/100+epochDelta))
+	return b
+}
+
+// ntlmClientChallenge returns a random client challenge.
+func ntlmClientChallenge() ([]byte, error) {
+	b := make([]byte, 8)
+	_, err := rand.Read(b)
+	return b, err
+}
+
+// splitNTLMUser splits a DOMAIN\user name, using domain if the name has no domain. A user principal name such as
+// user@example.com is returned as is with an empty domain, which NTLM accepts.
+func splitNTLMUser(username, domain string) (string, string) {
+	if i := strings.Index(username, `\`); i >= 0 {
+		return username[:i], username[i+1:]
+	}
+	if strings.Contains(username, "@") {
+		return "", username
+	}
+	return domain, username
+}
+
+func hmacMD5(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(md5.New, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+func utf16le(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	b := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(b[2*i:], u)
+	}
+	return b
+}
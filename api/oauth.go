@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OAuthConfig configures a client to authenticate to Keyfactor with an OAuth 2.0 bearer token instead of a username
+// and password, passed in AuthConfig.OAuth. Either AccessToken, or ClientID, ClientSecret, and TokenURL for the client
+// credentials flow, must be set.
+type OAuthConfig struct {
+	// AccessToken is a token obtained out of band. It is used as is and never refreshed.
+	AccessToken string
+	// ClientID and ClientSecret identify the client to the identity provider at TokenURL. Tokens are requested with
+	// the client credentials grant and refreshed shortly before they expire.
+	ClientID     string
+	ClientSecret string
+	TokenURL     string
+	Scopes       []string
+	// Audience is sent with token requests for identity providers that require it, such as Auth0.
+	Audience string
+}
+
+// tokenExpiryMargin is how long before its expiry a token is refreshed, so that it does not expire in flight.
+const tokenExpiryMargin = 30 * time.Second
+
+func (o *OAuthConfig) validate() error {
+	if o.AccessToken != "" {
+		return nil
+	}
+	if o.ClientID == "" || o.ClientSecret == "" || o.TokenURL == "" {
+		return errors.New("an OAuth access token, or client ID, client secret, and token URL, are required")
+	}
+	return nil
+}
+
+// ProxyAuthConfig configures a bearer token for a reverse proxy that authenticates requests before they reach
+// Keyfactor, such as Azure AD Application Proxy, passed in AuthConfig.ProxyAuth. The token is sent in its own header,
+// alongside the credentials Keyfactor itself checks. For Azure AD, set OAuth to the client credentials of an app
+// registration allowed to use the proxy, with TokenURL https://login.microsoftonline.com/{tenant}/oauth2/v2.0/token
+// and the scope {application ID URI}/.default.
+type ProxyAuthConfig struct {
+	// Header is the request header carrying the token. Defaults to Proxy-Authorization. It cannot be Authorization,
+	// which carries the Keyfactor credentials.
+	Header string
+	// OAuth holds the token, or the client credentials to request one with.
+	OAuth OAuthConfig
+}
+
+// DefaultProxyAuthHeader is the header used by ProxyAuthConfig when none is set.
+const DefaultProxyAuthHeader = "Proxy-Authorization"
+
+func (p *ProxyAuthConfig) header() string {
+	if p.Header == "" {
+		return DefaultProxyAuthHeader
+	}
+	return p.Header
+}
+
+func (p *ProxyAuthConfig) validate() error {
+	if strings.EqualFold(p.header(), "Authorization") {
+		return errors.New("proxy authentication header cannot be Authorization, which carries the Keyfactor credentials")
+	}
+	if err := p.OAuth.validate(); err != nil {
+		return fmt.Errorf("proxy authentication: %w", err)
+	}
+	return nil
+}
+
+// oauthTransport is an http.RoundTripper that authenticates each request with a bearer token in header, or in the
+// Authorization header if header is empty, replacing any basic authorization set by the caller or the SDK.
+type oauthTransport struct {
+	base http.RoundTripper
+	// tokenBase sends the requests for tokens. Defaults to base.
+	tokenBase http.RoundTripper
+	config    OAuthConfig
+	header    string
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func (t *oauthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.accessToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	header := t.header
+	if header == "" {
+		header = "Authorization"
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set(header, "Bearer "+token)
+	return t.base.RoundTrip(req)
+}
+
+// accessToken returns the configured access token, or a cached token from the identity provider, requesting a new one
+// if it is missing or about to expire.
+func (t *oauthTransport) accessToken(ctx context.Context) (string, error) {
+	if t.config.AccessToken != "" {
+		return t.config.AccessToken, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token != "" && time.Now().Before(t.expires) {
+		return t.token, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {t.config.ClientID},
+		"client_secret": {t.config.ClientSecret},
+	}
+	if len(t.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(t.config.Scopes, " "))
+	}
+	if t.config.Audience != "" {
+		form.Set("audience", t.config.Audience)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	tokenBase := t.tokenBase
+	if tokenBase == nil {
+		tokenBase = t.base
+	}
+	resp, err := tokenBase.RoundTrip(req)
+	if err != nil {
+		return "", fmt.Errorf("unable to get OAuth access token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unable to get OAuth access token: %s returned status %d", t.config.TokenURL, resp.StatusCode)
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", fmt.Errorf("unable to decode OAuth access token: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return "", errors.New("identity provider returned an empty OAuth access token")
+	}
+	t.token = tokenResp.AccessToken
+	t.expires = time.Now().Add(time.Duration(tokenResp.ExpiresIn)*time.Second - tokenExpiryMargin)
+	return t.token, nil
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewKeyfactorClient_ProxyAuth(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth2/v2.0/token":
+			if got := r.Header.Get("Authorization"); got != "" {
+				t.Errorf("token request Authorization = %q, want none", got)
+			}
+			r.ParseForm()
+			if r.PostForm.Get("client_id") != "proxy-app" || r.PostForm.Get("scope") != "api://command/.default" {
+				t.Errorf("token request form = %v", r.PostForm)
+			}
+			w.Write([]byte(`{"access_token": "aad-tok", "token_type": "Bearer", "expires_in": 3600}`))
+		case "/KeyfactorAPI/Status/Endpoints":
+			if got := r.Header.Get("Proxy-Authorization"); got != "Bearer aad-tok" {
+				t.Errorf("Proxy-Authorization = %q, want bearer token", got)
+			}
+			if user, pass, ok := r.BasicAuth(); !ok || user != `EXAMPLE\svc` || pass != "p@ss" {
+				t.Errorf("Authorization = %q, want basic auth", r.Header.Get("Authorization"))
+			}
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	auth := &AuthConfig{
+		Hostname:   srv.URL,
+		Username:   "svc",
+		Password:   "p@ss",
+		Domain:     "EXAMPLE",
+		APIPath:    "KeyfactorAPI",
+		SkipVerify: true,
+		ProxyAuth: &ProxyAuthConfig{OAuth: OAuthConfig{
+			ClientID:     "proxy-app",
+			ClientSecret: "s3cret",
+			TokenURL:     srv.URL + "/oauth2/v2.0/token",
+			Scopes:       []string{"api://command/.default"},
+		}},
+	}
+	c, err := NewKeyfactorClient(auth)
+	if err != nil {
+		t.Fatalf("NewKeyfactorClient() error = %v", err)
+	}
+	if _, err := c.sendRequest(&request{Method: "GET", Endpoint: "Status/Endpoints", Headers: &apiHeaders{}}); err != nil {
+		t.Fatalf("sendRequest() error = %v", err)
+	}
+
+	auth.ProxyAuth.Header = "authorization"
+	if _, err := NewKeyfactorClient(auth); err == nil {
+		t.Errorf("NewKeyfactorClient() with proxy token in the Authorization header succeeded, want error")
+	}
+}
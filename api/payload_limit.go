@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// DefaultMaxRequestBytes is the default of AuthConfig.MaxRequestBytes, the 4 MB request limit IIS applies to ASP.NET
+// applications such as Command unless it is raised.
+const DefaultMaxRequestBytes = 4 << 20
+
+// ErrPayloadTooLarge is returned for a request whose body is over the client's request size limit, which is then not
+// sent, or that Keyfactor rejected as too large with status 413.
+var ErrPayloadTooLarge = errors.New("request payload too large")
+
+// storeLocationBatchSize is the largest number of certificate store locations sent in one request by
+// AddCertificateToStores and RemoveCertificateFromStores. Command handles much larger requests slowly enough for them
+// to time out, even below the size limit.
+var storeLocationBatchSize = 250
+
+// requestLimit returns the largest request body the client sends, or 0 if there is no limit.
+func (c *Client) requestLimit() int {
+	switch {
+	case c.maxRequestBytes < 0:
+		return 0
+	case c.maxRequestBytes == 0:
+		return DefaultMaxRequestBytes
+	default:
+		return c.maxRequestBytes
+	}
+}
+
+// payloadLimitTransport is an http.RoundTripper that refuses to send requests over the client's size limit, and
+// reports a 413 response from Keyfactor as ErrPayloadTooLarge.
+type payloadLimitTransport struct {
+	base   http.RoundTripper
+	client *Client
+}
+
+func (t *payloadLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if limit := t.client.requestLimit(); limit > 0 && req.ContentLength > int64(limit) {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, fmt.Errorf("%w: %s request to %s is %d bytes, over the limit of %d bytes", ErrPayloadTooLarge, req.Method, req.URL.Path, req.ContentLength, limit)
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusRequestEntityTooLarge {
+		return resp, err
+	}
+	resp.Body.Close()
+	return nil, fmt.Errorf("%w: Keyfactor rejected the %d byte %s request to %s with status %d", ErrPayloadTooLarge, req.ContentLength, req.Method, req.URL.Path, resp.StatusCode)
+}
+
+// payloadBatches splits the n items of a request into batches, given as [start, end) index pairs, of at most maxCount
+// items and whose request body stays within maxBytes. base is the size of the request without items and size returns
+// the encoded size of an item. maxBytes of 0 means no size limit. An item too large to send on its own is an error
+// wrapping ErrPayloadTooLarge.
+func payloadBatches(n, maxCount, maxBytes, base int, size func(i int) int) ([][2]int, error) {
+	var batches [][2]int
+	start, bytes := 0, base
+	for i := 0; i < n; i++ {
+		// Items after the first are preceded by a comma.
+		itemSize := size(i) + 1
+		if maxBytes > 0 && base+itemSize > maxBytes {
+			return nil, fmt.Errorf("%w: item %d alone makes a request of %d bytes, over the limit of %d bytes", ErrPayloadTooLarge, i, base+itemSize, maxBytes)
+		}
+		if i > start && (i-start >= maxCount || (maxBytes > 0 && bytes+itemSize > maxBytes)) {
+			batches = append(batches, [2]int{start, i})
+			start, bytes = i, base
+		}
+		bytes += itemSize
+	}
+	if start < n {
+		batches = append(batches, [2]int{start, n})
+	}
+	return batches, nil
+}
+
+// jsonSize returns the length of the JSON encoding of v, or 0 if it cannot be encoded, in which case sending it fails
+// later with the encoding error.
+func jsonSize(v interface{}) int {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
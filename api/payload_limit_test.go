@@ -0,0 +1,140 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPayloadBatches(t *testing.T) {
+	sizes := []int{9, 9, 9, 9, 9}
+	size := func(i int) int { return sizes[i] }
+	tests := []struct {
+		name     string
+		maxCount int
+		maxBytes int
+		want     [][2]int
+		wantErr  bool
+	}{
+		{name: "Unlimited", maxCount: 10, want: [][2]int{{0, 5}}},
+		{name: "Count", maxCount: 2, want: [][2]int{{0, 2}, {2, 4}, {4, 5}}},
+		// The base request is 20 bytes and each item 10 with its comma, so three items fit in 50 bytes.
+		{name: "Bytes", maxCount: 10, maxBytes: 50, want: [][2]int{{0, 3}, {3, 5}}},
+		{name: "CountAndBytes", maxCount: 2, maxBytes: 50, want: [][2]int{{0, 2}, {2, 4}, {4, 5}}},
+		{name: "ItemTooLarge", maxCount: 10, maxBytes: 25, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := payloadBatches(len(sizes), tt.maxCount, tt.maxBytes, 20, size)
+			if tt.wantErr {
+				if !errors.Is(err, ErrPayloadTooLarge) {
+					t.Errorf("payloadBatches() error = %v, want ErrPayloadTooLarge", err)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("payloadBatches() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_requestLimit(t *testing.T) {
+	var requests int
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.ContentLength > 100 {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	})
+	send := func(payloadLen int) error {
+		_, err := c.sendRequest(&request{Method: "POST", Endpoint: "Certificates/Import", Headers: &apiHeaders{}, Payload: strings.Repeat("a", payloadLen)})
+		return err
+	}
+
+	c.maxRequestBytes = 200
+	if err := send(300); !errors.Is(err, ErrPayloadTooLarge) || requests != 0 {
+		t.Errorf("sendRequest() over the limit error = %v after %d requests, want ErrPayloadTooLarge without sending", err, requests)
+	}
+	if err := send(150); !errors.Is(err, ErrPayloadTooLarge) || requests != 1 {
+		t.Errorf("sendRequest() rejected with 413 error = %v after %d requests, want ErrPayloadTooLarge", err, requests)
+	}
+	if err := send(50); err != nil {
+		t.Errorf("sendRequest() under the limit error = %v", err)
+	}
+	c.maxRequestBytes = -1
+	if err := send(DefaultMaxRequestBytes); !errors.Is(err, ErrPayloadTooLarge) || requests != 3 {
+		t.Errorf("sendRequest() without a limit error = %v after %d requests, want the 413 from Keyfactor", err, requests)
+	}
+}
+
+func TestClient_AddCertificateToStores_Batches(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		batches []int
+	)
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			CertificateStores []map[string]interface{}
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		batches = append(batches, len(body.CertificateStores))
+		n := len(batches)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if body.CertificateStores[0]["CertificateStoreId"] == "store-007" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"Message": "store not found"}`))
+			return
+		}
+		fmt.Fprintf(w, `["job-%d"]`, n)
+	})
+	defer func(size int) { storeLocationBatchSize = size }(storeLocationBatchSize)
+	storeLocationBatchSize = 4
+
+	stores := []CertificateStore{}
+	for i := 0; i < 10; i++ {
+		stores = append(stores, CertificateStore{CertificateStoreId: fmt.Sprintf("store-%03d", i), Alias: "web"})
+	}
+	jobs, err := c.AddCertificateToStores(&AddCertificateToStore{CertificateId: 1, CertificateStores: &stores})
+	if err != nil {
+		t.Fatalf("AddCertificateToStores() error = %v", err)
+	}
+	if !reflect.DeepEqual(batches, []int{4, 4, 2}) || len(jobs) != 3 {
+		t.Errorf("AddCertificateToStores() sent batches %v and returned jobs %v, want 4, 4, 2", batches, jobs)
+	}
+
+	// A small size limit splits the stores further.
+	batches = nil
+	c.maxRequestBytes = 250
+	if _, err := c.AddCertificateToStores(&AddCertificateToStore{CertificateId: 1, CertificateStores: &stores}); err != nil {
+		t.Fatalf("AddCertificateToStores() with a size limit error = %v", err)
+	}
+	if len(batches) <= 3 {
+		t.Errorf("AddCertificateToStores() with a size limit sent batches %v, want more than 3", batches)
+	}
+
+	// A failed batch does not stop the others.
+	batches = nil
+	c.maxRequestBytes = 0
+	storeLocationBatchSize = 7
+	stores = append(stores, stores[7:]...)
+	jobs, err = c.AddCertificateToStores(&AddCertificateToStore{CertificateId: 1, CertificateStores: &stores})
+	if err == nil || !strings.Contains(err.Error(), "store-007") || strings.Contains(err.Error(), "store-000") || len(jobs) != 1 {
+		t.Errorf("AddCertificateToStores() with a failed batch = %v, %v", jobs, err)
+	}
+
+	c.maxRequestBytes = 100
+	if _, err := c.AddCertificateToStores(&AddCertificateToStore{CertificateId: 1, CertificateStores: &stores}); !errors.Is(err, ErrPayloadTooLarge) {
+		t.Errorf("AddCertificateToStores() under a tiny limit error = %v, want ErrPayloadTooLarge", err)
+	}
+}
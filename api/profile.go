@@ -0,0 +1,165 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultProfile is the profile used when none is selected.
+const DefaultProfile = "default"
+
+var (
+	// EnvCommandConfigFile overrides the path of the profile file read by NewClientFromProfile.
+	EnvCommandConfigFile = "KEYFACTOR_AUTH_CONFIG_FILE"
+	// EnvCommandConfigProfile selects the profile used by NewClientFromProfile when none is passed.
+	EnvCommandConfigProfile = "KEYFACTOR_AUTH_CONFIG_PROFILE"
+)
+
+// ConfigFile is a profile file holding the connection settings of one or more Keyfactor environments, in the format
+// written by kfutil to ~/.keyfactor/command_config.json:
+//
+//	{
+//	  "servers": {
+//	    "default": {"host": "kf-dev.example.com", "username": "svc-dev", "password": "...", "domain": "EXAMPLE"},
+//	    "prod": {"host": "kf.example.com", "client_id": "...", "client_secret": "...", "oauth_token_url": "..."}
+//	  }
+//	}
+type ConfigFile struct {
+	Servers map[string]ServerProfile `json:"servers"`
+}
+
+// ServerProfile holds the settings of one environment in a ConfigFile.
+type ServerProfile struct {
+	Host          string `json:"host"`
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	Domain        string `json:"domain,omitempty"`
+	APIPath       string `json:"api_path,omitempty"`
+	SkipTLSVerify bool   `json:"skip_tls_verify,omitempty"`
+	// AuthType is "basic" or "oauth". If empty, OAuth is used when ClientID or AccessToken is set.
+	AuthType      string   `json:"auth_type,omitempty"`
+	ClientID      string   `json:"client_id,omitempty"`
+	ClientSecret  string   `json:"client_secret,omitempty"`
+	OAuthTokenURL string   `json:"oauth_token_url,omitempty"`
+	Scopes        []string `json:"scopes,omitempty"`
+	Audience      string   `json:"audience,omitempty"`
+	AccessToken   string   `json:"access_token,omitempty"`
+}
+
+// DefaultConfigFilePath returns the path of the profile file shared with kfutil, ~/.keyfactor/command_config.json.
+func DefaultConfigFilePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to locate home directory: %w", err)
+	}
+	return filepath.Join(home, ".keyfactor", "command_config.json"), nil
+}
+
+// LoadConfigFile reads the profile file at path.
+func LoadConfigFile(path string) (*ConfigFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read Keyfactor config file: %w", err)
+	}
+	cf := &ConfigFile{}
+	if err := json.Unmarshal(data, cf); err != nil {
+		return nil, fmt.Errorf("invalid Keyfactor config file %s: %w", path, err)
+	}
+	return cf, nil
+}
+
+// Profiles returns the names of the profiles in the file, sorted.
+func (f *ConfigFile) Profiles() []string {
+	names := make([]string, 0, len(f.Servers))
+	for name := range f.Servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AuthConfig returns the client configuration of the named profile, or of DefaultProfile if profile is empty.
+func (f *ConfigFile) AuthConfig(profile string) (*AuthConfig, error) {
+	if profile == "" {
+		profile = DefaultProfile
+	}
+	server, ok := f.Servers[profile]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found in Keyfactor config file, available profiles: %s", profile, strings.Join(f.Profiles(), ", "))
+	}
+	if server.Host == "" {
+		return nil, fmt.Errorf("profile %q has no host", profile)
+	}
+
+	auth := &AuthConfig{
+		Hostname:   server.Host,
+		APIPath:    server.APIPath,
+		SkipVerify: server.SkipTLSVerify,
+	}
+	switch strings.ToLower(server.AuthType) {
+	case "oauth":
+	case "basic":
+		auth.Username, auth.Password, auth.Domain = server.Username, server.Password, server.Domain
+		return auth, nil
+	case "":
+		if server.ClientID == "" && server.AccessToken == "" {
+			auth.Username, auth.Password, auth.Domain = server.Username, server.Password, server.Domain
+			return auth, nil
+		}
+	default:
+		return nil, fmt.Errorf("profile %q has unsupported auth type %q", profile, server.AuthType)
+	}
+	auth.OAuth = &OAuthConfig{
+		ClientID:     server.ClientID,
+		ClientSecret: server.ClientSecret,
+		TokenURL:     server.OAuthTokenURL,
+		Scopes:       server.Scopes,
+		Audience:     server.Audience,
+		AccessToken:  server.AccessToken,
+	}
+	return auth, nil
+}
+
+// AuthConfigFromProfile returns the client configuration of a profile in the profile file at path. If path is empty,
+// the file named by KEYFACTOR_AUTH_CONFIG_FILE is read, or else DefaultConfigFilePath. If profile is empty, the
+// profile named by KEYFACTOR_AUTH_CONFIG_PROFILE is used, or else DefaultProfile.
+func AuthConfigFromProfile(path, profile string) (*AuthConfig, error) {
+	if path == "" {
+		path = os.Getenv(EnvCommandConfigFile)
+	}
+	if path == "" {
+		var err error
+		if path, err = DefaultConfigFilePath(); err != nil {
+			return nil, err
+		}
+	}
+	if profile == "" {
+		profile = os.Getenv(EnvCommandConfigProfile)
+	}
+
+	cf, err := LoadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return cf.AuthConfig(profile)
+}
+
+// NewClientFromProfile creates a new Keyfactor client configured from a profile in a profile file, as described by
+// AuthConfigFromProfile, e.g. to target production:
+//
+//	client, err := api.NewClientFromProfile("", "prod")
+func NewClientFromProfile(path, profile string) (*Client, error) {
+	auth, err := AuthConfigFromProfile(path, profile)
+	if err != nil {
+		return nil, err
+	}
+	if auth.OAuth == nil && (auth.Username == "" || auth.Password == "") {
+		return nil, errors.New("profile is missing a username or password")
+	}
+	return NewKeyfactorClient(auth)
+}
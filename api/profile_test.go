@@ -0,0 +1,65 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAuthConfigFromProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "command_config.json")
+	err := os.WriteFile(path, []byte(`{
+  "servers": {
+    "default": {"host": "kf-dev.example.com", "username": "svc-dev", "password": "dev-pass", "domain": "EXAMPLE", "api_path": "KeyfactorAPI"},
+    "stage": {"host": "kf-stage.example.com", "auth_type": "oauth", "client_id": "kfutil", "client_secret": "s3cret", "oauth_token_url": "https://idp.example.com/token", "scopes": ["openid"], "skip_tls_verify": true},
+    "prod": {"host": "kf.example.com", "access_token": "eyJhbGciOi"},
+    "legacy": {"host": "kf-old.example.com", "auth_type": "kerberos"},
+    "nohost": {"username": "svc"}
+  }
+}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		envProfile string
+		profile    string
+		want       *AuthConfig
+		wantErr    bool
+	}{
+		{
+			name: "Default",
+			want: &AuthConfig{Hostname: "kf-dev.example.com", Username: "svc-dev", Password: "dev-pass", Domain: "EXAMPLE", APIPath: "KeyfactorAPI"},
+		},
+		{
+			name:    "OAuth",
+			profile: "stage",
+			want: &AuthConfig{Hostname: "kf-stage.example.com", SkipVerify: true, OAuth: &OAuthConfig{
+				ClientID: "kfutil", ClientSecret: "s3cret", TokenURL: "https://idp.example.com/token", Scopes: []string{"openid"},
+			}},
+		},
+		{
+			name:       "ProfileFromEnv",
+			envProfile: "prod",
+			want:       &AuthConfig{Hostname: "kf.example.com", OAuth: &OAuthConfig{AccessToken: "eyJhbGciOi"}},
+		},
+		{name: "UnknownProfile", profile: "qa", wantErr: true},
+		{name: "UnsupportedAuthType", profile: "legacy", wantErr: true},
+		{name: "MissingHost", profile: "nohost", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvCommandConfigFile, path)
+			t.Setenv(EnvCommandConfigProfile, tt.envProfile)
+			got, err := AuthConfigFromProfile("", tt.profile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AuthConfigFromProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AuthConfigFromProfile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package api
+
+// ProgressPhase names the step a bulk operation is in.
+type ProgressPhase string
+
+// Phases reported by the bulk helpers.
+const (
+	// ProgressPhaseSearching is reported while the items to process are looked up, e.g. the certificates matching a
+	// query. Total is not known yet.
+	ProgressPhaseSearching ProgressPhase = "searching"
+	// ProgressPhaseResolving is reported while each item is prepared, e.g. a store's certificate aliases are found.
+	ProgressPhaseResolving ProgressPhase = "resolving"
+	// ProgressPhaseProcessing is reported as items are sent to Keyfactor.
+	ProgressPhaseProcessing ProgressPhase = "processing"
+	// ProgressPhaseDone is reported once, when the operation has finished.
+	ProgressPhaseDone ProgressPhase = "done"
+)
+
+// Progress describes how far a bulk operation has got.
+type Progress struct {
+	Phase ProgressPhase
+	// Completed and Failed count the items that have succeeded and failed so far, out of Total.
+	Completed int
+	Failed    int
+	Total     int
+}
+
+// ProgressFunc is called by bulk helpers as their work advances, so that callers can report progress to users. It is
+// called from the goroutine running the operation and should return quickly.
+type ProgressFunc func(Progress)
+
+// report calls fn with p, if fn is set.
+func (fn ProgressFunc) report(p Progress) {
+	if fn != nil {
+		fn(p)
+	}
+}
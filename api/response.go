@@ -0,0 +1,194 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// requestIdHeaders lists the response headers Keyfactor and common proxies use to identify a request, in order of
+// preference.
+var requestIdHeaders = []string{"x-keyfactor-request-id", "x-request-id", "request-id", "x-correlation-id"}
+
+// ResponseMetadata holds the raw HTTP details of a response from Keyfactor, for callers that need more than the
+// decoded result, e.g. to implement their own paging or caching, or to quote a request ID in a support case.
+type ResponseMetadata struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Header     http.Header
+	// RequestID is the server-assigned request identifier, if the response carried one.
+	RequestID string
+	// CorrelationID is the correlation ID sent with the request, and ActivityID the server-side activity ID reported
+	// in the response, if any.
+	CorrelationID string
+	ActivityID    string
+	// TotalCount is the value of the x-total-count header, or -1 if the response did not include it.
+	TotalCount int
+	// Links maps each relation in the Link header (e.g. "next") to its URL.
+	Links    map[string]string
+	Duration time.Duration
+}
+
+// ResponseCapture collects the metadata of every response to requests made with a context returned by
+// WithResponseCapture.
+type ResponseCapture struct {
+	mu        sync.Mutex
+	responses []*ResponseMetadata
+}
+
+type responseCaptureKey struct{}
+
+// WithResponseCapture returns a copy of ctx that records the metadata of every response to a request made with it.
+// Pass the context to any of the Client's Context methods and inspect the capture once the call returns.
+func WithResponseCapture(ctx context.Context) (context.Context, *ResponseCapture) {
+	capture := &ResponseCapture{}
+	return context.WithValue(ctx, responseCaptureKey{}, capture), capture
+}
+
+// All returns the captured responses in the order they were received.
+func (rc *ResponseCapture) All() []*ResponseMetadata {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return append([]*ResponseMetadata(nil), rc.responses...)
+}
+
+// Last returns the most recently captured response, or nil if there is none.
+func (rc *ResponseCapture) Last() *ResponseMetadata {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if len(rc.responses) == 0 {
+		return nil
+	}
+	return rc.responses[len(rc.responses)-1]
+}
+
+func (rc *ResponseCapture) add(m *ResponseMetadata) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.responses = append(rc.responses, m)
+}
+
+// SetResponseHook registers fn to be called with the metadata of every response the client receives, including
+// error responses. It replaces any previously registered hook; pass nil to remove it. fn may be called concurrently.
+func (c *Client) SetResponseHook(fn func(*ResponseMetadata)) {
+	c.responseHookMu.Lock()
+	defer c.responseHookMu.Unlock()
+	c.responseHook = fn
+}
+
+// instrumentedHTTPClient returns a copy of the client's http.Client whose transport reports response metadata and
+// serves requests from the response cache when it is enabled. It is shared by sendRequest and the SDK client.
+func (c *Client) instrumentedHTTPClient() *http.Client {
+	c.instrumentedOnce.Do(func() {
+		base := c.httpClient
+		if base == nil {
+			base = http.DefaultClient
+		}
+		hc := *base
+		transport := hc.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		// The client timeout is enforced per request by timeoutTransport so that WithTimeout can override it.
+		timeout := hc.Timeout
+		hc.Timeout = 0
+		transport = &compressionTransport{base: transport, disabled: c.disableCompression}
+		transport = &breakerTransport{base: transport, client: c}
+		transport = &cacheTransport{base: transport, client: c}
+		transport = &metadataTransport{base: transport, client: c}
+		transport = &apiVersionTransport{base: transport, client: c}
+		transport = &basicAuthTransport{base: transport, client: c}
+		transport = &payloadLimitTransport{base: transport, client: c}
+		transport = &userAgentTransport{base: transport, client: c}
+		hc.Transport = &timeoutTransport{
+			base:    &correlationTransport{base: transport},
+			timeout: timeout,
+		}
+		c.instrumented = &hc
+	})
+	return c.instrumented
+}
+
+// metadataTransport is an http.RoundTripper that records the metadata of each response.
+type metadataTransport struct {
+	base   http.RoundTripper
+	client *Client
+}
+
+func (t *metadataTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	capture, _ := req.Context().Value(responseCaptureKey{}).(*ResponseCapture)
+	t.client.responseHookMu.RLock()
+	hook := t.client.responseHook
+	t.client.responseHookMu.RUnlock()
+	if capture == nil && hook == nil {
+		return resp, nil
+	}
+
+	m := newResponseMetadata(req, resp, time.Since(start))
+	if capture != nil {
+		capture.add(m)
+	}
+	if hook != nil {
+		hook(m)
+	}
+	return resp, nil
+}
+
+func newResponseMetadata(req *http.Request, resp *http.Response, d time.Duration) *ResponseMetadata {
+	m := &ResponseMetadata{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		TotalCount: -1,
+		Links:      parseLinkHeader(resp.Header.Values("Link")),
+		Duration:   d,
+	}
+	for _, h := range requestIdHeaders {
+		if id := resp.Header.Get(h); id != "" {
+			m.RequestID = id
+			break
+		}
+	}
+	m.CorrelationID = req.Header.Get(CorrelationIDHeader)
+	m.ActivityID = responseActivityID(resp, nil)
+	if total, err := strconv.Atoi(resp.Header.Get("x-total-count")); err == nil {
+		m.TotalCount = total
+	}
+	return m
+}
+
+// parseLinkHeader parses RFC 8288 Link header values such as `<https://host/api?page=2>; rel="next"`.
+func parseLinkHeader(values []string) map[string]string {
+	links := map[string]string{}
+	for _, value := range values {
+		for _, link := range strings.Split(value, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			target = strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+			for _, param := range parts[1:] {
+				key, val, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || !strings.EqualFold(key, "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(val, `"`)) {
+					links[strings.ToLower(rel)] = target
+				}
+			}
+		}
+	}
+	return links
+}
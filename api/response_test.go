@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestClient_ResponseMetadata(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("x-total-count", "42")
+		w.Header().Set("x-request-id", "req-123")
+		w.Header().Set("Link", `<https://kf/KeyfactorAPI/AppSetting?pq.pageReturned=2>; rel="next"`)
+		switch r.URL.Path {
+		case "/KeyfactorAPI/AppSetting", "/KeyfactorAPI/CertificateStoreTypes":
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	var hooked []*ResponseMetadata
+	c.SetResponseHook(func(m *ResponseMetadata) { hooked = append(hooked, m) })
+	if _, err := c.GetAppSettings(); err != nil {
+		t.Fatalf("GetAppSettings() error = %v", err)
+	}
+	if len(hooked) != 1 {
+		t.Fatalf("hook called %d times, want 1", len(hooked))
+	}
+	m := hooked[0]
+	if m.StatusCode != http.StatusOK || m.TotalCount != 42 || m.RequestID != "req-123" || m.Method != http.MethodGet {
+		t.Errorf("hook metadata = %+v", m)
+	}
+	if m.Links["next"] != "https://kf/KeyfactorAPI/AppSetting?pq.pageReturned=2" {
+		t.Errorf("hook links = %v", m.Links)
+	}
+
+	// Error responses are reported too.
+	if _, err := c.GetAppSetting(7); err == nil {
+		t.Errorf("GetAppSetting(7) succeeded, want error")
+	}
+	if len(hooked) != 2 || hooked[1].StatusCode != http.StatusNotFound {
+		t.Errorf("hook did not report the error response")
+	}
+
+	c.SetResponseHook(nil)
+	ctx, capture := WithResponseCapture(context.Background())
+	if _, err := c.ListCertificateStoreTypesContext(ctx); err != nil {
+		t.Fatalf("ListCertificateStoreTypesContext() error = %v", err)
+	}
+	last := capture.Last()
+	if last == nil || last.TotalCount != 42 || last.RequestID != "req-123" {
+		t.Errorf("captured metadata = %+v", last)
+	}
+	if len(hooked) != 2 {
+		t.Errorf("removed hook was still called")
+	}
+}
+
+func Test_parseLinkHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   map[string]string
+	}{
+		{name: "None", values: nil, want: map[string]string{}},
+		{
+			name:   "Multiple",
+			values: []string{`<https://kf/a?p=2>; rel="next", <https://kf/a?p=9>; rel=last`, `<https://kf/a?p=1>; REL="first prev"`},
+			want: map[string]string{
+				"next":  "https://kf/a?p=2",
+				"last":  "https://kf/a?p=9",
+				"first": "https://kf/a?p=1",
+				"prev":  "https://kf/a?p=1",
+			},
+		},
+		{name: "Malformed", values: []string{`https://kf/a; rel="next"`}, want: map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseLinkHeader(tt.values); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseLinkHeader() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
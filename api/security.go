@@ -2,20 +2,18 @@ package api
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 
-	"github.com/Keyfactor/keyfactor-go-client-sdk/api/keyfactor"
+	"github.com/Keyfactor/keyfactor-go-client/query"
 )
 
 // GetSecurityIdentities hits the /Security/Identities endpoint with a GET request and returns a list of
 // GetSecurityIdentityResponse structs. The function takes no arguments.
 // TODO?
 func (c *Client) GetSecurityIdentities() ([]GetSecurityIdentityResponse, error) {
-	log.Println("[INFO] Getting Keyfactor security identity list")
+	c.infof("Getting Keyfactor security identity list")
 
 	// Set Keyfactor-specific headers
 	headers := &apiHeaders{
@@ -38,7 +36,7 @@ func (c *Client) GetSecurityIdentities() ([]GetSecurityIdentityResponse, error)
 	}
 
 	var jsonResp []GetSecurityIdentityResponse
-	err = json.NewDecoder(resp.Body).Decode(&jsonResp)
+	err = c.decodeResponse(resp.Body, &jsonResp)
 	if err != nil {
 		return nil, err
 	}
@@ -49,7 +47,7 @@ func (c *Client) GetSecurityIdentities() ([]GetSecurityIdentityResponse, error)
 // and returns a CreateSecurityIdentityResponse struct. The function takes argument for a CreateSecurityIdentityArg struct
 // TODO?
 func (c *Client) CreateSecurityIdentity(csia *CreateSecurityIdentityArg) (*CreateSecurityIdentityResponse, error) {
-	log.Println("[INFO] Creating new Keyfactor security identity")
+	c.infof("Creating new Keyfactor security identity")
 
 	// Verify argument
 	if csia == nil || csia.AccountName == "" {
@@ -77,7 +75,7 @@ func (c *Client) CreateSecurityIdentity(csia *CreateSecurityIdentityArg) (*Creat
 	}
 
 	jsonResp := &CreateSecurityIdentityResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&jsonResp)
+	err = c.decodeResponse(resp.Body, &jsonResp)
 	if err != nil {
 		return nil, err
 	}
@@ -87,15 +85,20 @@ func (c *Client) CreateSecurityIdentity(csia *CreateSecurityIdentityArg) (*Creat
 // DeleteSecurityIdentity takes arguments for a security identity ID, and makes an associated call to Keyfactor to
 // delete the identity.
 func (c *Client) DeleteSecurityIdentity(id int) error {
-	log.Printf("[INFO] Deleting Keyfactor security identity with ID %d", id)
+	return c.DeleteSecurityIdentityContext(context.Background(), id)
+}
+
+// DeleteSecurityIdentityContext is like DeleteSecurityIdentity but uses ctx for the request, allowing it to be
+// cancelled.
+func (c *Client) DeleteSecurityIdentityContext(ctx context.Context, id int) error {
+	c.infof("Deleting Keyfactor security identity with ID %d", id)
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
-	_, httpResp, err := apiClient.SecurityApi.SecurityIdentityPermissions(context.Background(), int32(id)).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	_, httpResp, err := apiClient.SecurityApi.SecurityIdentityPermissions(ctx, int32(id)).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
 		return err
@@ -109,7 +112,7 @@ func (c *Client) DeleteSecurityIdentity(id int) error {
 
 // TODO?
 func (c *Client) GetSecurityRoles() (GetSecurityRolesResponse, error) {
-	log.Println("[INFO] Getting list of Keyfactor security roles")
+	c.infof("Getting list of Keyfactor security roles")
 
 	// Set Keyfactor-specific headers
 	headers := &apiHeaders{
@@ -132,7 +135,7 @@ func (c *Client) GetSecurityRoles() (GetSecurityRolesResponse, error) {
 	}
 
 	var jsonResp GetSecurityRolesResponse
-	err = json.NewDecoder(resp.Body).Decode(&jsonResp)
+	err = c.decodeResponse(resp.Body, &jsonResp)
 	if err != nil {
 		return nil, err
 	}
@@ -141,7 +144,7 @@ func (c *Client) GetSecurityRoles() (GetSecurityRolesResponse, error) {
 
 // TODO?
 func (c *Client) GetSecurityRole(id interface{}) (*GetSecurityRoleResponse, error) {
-	log.Printf("[INFO] Getting Keyfactor security role with ID %v", id)
+	c.infof("Getting Keyfactor security role with ID %v", id)
 
 	// Set Keyfactor-specific headers
 	headers := &apiHeaders{
@@ -168,7 +171,7 @@ func (c *Client) GetSecurityRole(id interface{}) (*GetSecurityRoleResponse, erro
 			return nil, err
 		}
 		jsonResp := &GetSecurityRoleResponse{}
-		err = json.NewDecoder(resp.Body).Decode(&jsonResp)
+		err = c.decodeResponse(resp.Body, &jsonResp)
 		if err != nil {
 			return nil, err
 		}
@@ -176,9 +179,9 @@ func (c *Client) GetSecurityRole(id interface{}) (*GetSecurityRoleResponse, erro
 
 	case string:
 		endpoint = "Security/Roles" // Append ID to complete endpoint
-		query := &apiQuery{
+		q := &apiQuery{
 			Query: []StringTuple{
-				{"pq.queryString", fmt.Sprintf("name -eq \"%s\"", id.(string))},
+				{"pq.queryString", query.Field("name").Eq(id.(string)).String()},
 			},
 		}
 		keyfactorAPIStruct = &request{
@@ -186,7 +189,7 @@ func (c *Client) GetSecurityRole(id interface{}) (*GetSecurityRoleResponse, erro
 			Endpoint: endpoint,
 			Headers:  headers,
 			Payload:  nil,
-			Query:    query,
+			Query:    q,
 		}
 
 		resp, err := c.sendRequest(keyfactorAPIStruct)
@@ -195,10 +198,10 @@ func (c *Client) GetSecurityRole(id interface{}) (*GetSecurityRoleResponse, erro
 		}
 
 		jsonResp := &GetSecurityRolesResponse{}
-		err = json.NewDecoder(resp.Body).Decode(&jsonResp)
+		err = c.decodeResponse(resp.Body, &jsonResp)
 
 		for i, jResp := range *jsonResp {
-			log.Printf("[INFO] Getting Keyfactor security role with %v ID %v", i, jResp)
+			c.infof("Getting Keyfactor security role with %v ID %v", i, jResp)
 			return &GetSecurityRoleResponse{
 				Id:          jResp.ID,
 				Name:        jResp.Name,
@@ -216,15 +219,19 @@ func (c *Client) GetSecurityRole(id interface{}) (*GetSecurityRoleResponse, erro
 // DeleteSecurityRole takes arguments for a security role ID, and makes an associated call to Keyfactor to
 // delete the role.
 func (c *Client) DeleteSecurityRole(id int) error {
-	log.Printf("[INFO] Deleting Keyfactor security role with ID %d", id)
+	return c.DeleteSecurityRoleContext(context.Background(), id)
+}
+
+// DeleteSecurityRoleContext is like DeleteSecurityRole but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) DeleteSecurityRoleContext(ctx context.Context, id int) error {
+	c.infof("Deleting Keyfactor security role with ID %d", id)
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
-	resp, err := apiClient.SecurityRolesApi.SecurityRolesDeleteSecurityRole(context.Background(), int32(id)).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, err := apiClient.SecurityRolesApi.SecurityRolesDeleteSecurityRole(ctx, int32(id)).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
 		return err
@@ -240,7 +247,7 @@ func (c *Client) DeleteSecurityRole(id int) error {
 // struct and returns a CreateSecurityRoleResponse struct.
 // TODO?
 func (c *Client) CreateSecurityRole(input *CreateSecurityRoleArg) (*CreateSecurityRoleResponse, error) {
-	log.Println("[INFO] Creating new Keyfactor security role")
+	c.infof("Creating new Keyfactor security role")
 
 	// Verify argument
 	if input == nil || input.Name == "" || input.Description == "" {
@@ -268,7 +275,7 @@ func (c *Client) CreateSecurityRole(input *CreateSecurityRoleArg) (*CreateSecuri
 	}
 
 	jsonResp := &CreateSecurityRoleResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&jsonResp)
+	err = c.decodeResponse(resp.Body, &jsonResp)
 	if err != nil {
 		return nil, err
 	}
@@ -279,7 +286,7 @@ func (c *Client) CreateSecurityRole(input *CreateSecurityRoleArg) (*CreateSecuri
 // struct and returns a CreateSecurityRoleResponse struct.
 // TODO?
 func (c *Client) UpdateSecurityRole(input *UpdateSecurityRoleArg) (*UpdateSecurityRoleResponse, error) {
-	log.Printf("[INFO] Updating Keyfactor security role with ID %d", input.Id)
+	c.infof("Updating Keyfactor security role with ID %d", input.Id)
 
 	// Verify argument
 	if input == nil {
@@ -313,7 +320,7 @@ func (c *Client) UpdateSecurityRole(input *UpdateSecurityRoleArg) (*UpdateSecuri
 	}
 
 	jsonResp := &UpdateSecurityRoleResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&jsonResp)
+	err = c.decodeResponse(resp.Body, &jsonResp)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// newSecurityTestServer fakes a Keyfactor server that advertises either the claims or the legacy identity API and
+// records the calls made against it.
+func newSecurityTestServer(t *testing.T, claims bool, calls *[]string, roleUpdate *UpdateSecurityRoleArg) *Client {
+	return newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		*calls = append(*calls, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /KeyfactorAPI/Status/Endpoints":
+			endpoints := []string{"GET /Status/Endpoints", "GET /Security/Identities"}
+			if claims {
+				endpoints = append(endpoints, "GET /Security/Claims", "POST /Security/Roles/{id}/Claims")
+			}
+			json.NewEncoder(w).Encode(endpoints)
+		case "POST /KeyfactorAPI/Security/Roles/5/Claims":
+			w.WriteHeader(http.StatusNoContent)
+		case "GET /KeyfactorAPI/Security/Identities":
+			json.NewEncoder(w).Encode([]GetSecurityIdentityResponse{{Id: 1, AccountName: `KEYFACTOR\admins`}})
+		case "POST /KeyfactorAPI/Security/Identities":
+			json.NewEncoder(w).Encode(CreateSecurityIdentityResponse{Id: 2, AccountName: `KEYFACTOR\operators`})
+		case "GET /KeyfactorAPI/Security/Roles/5":
+			json.NewEncoder(w).Encode(GetSecurityRoleResponse{
+				Id:          5,
+				Name:        "Operators",
+				Description: "Certificate operators",
+				Identities:  []SecurityIdentity{{AccountName: `KEYFACTOR\admins`}},
+				Permissions: []string{"Certificates:Read"},
+			})
+		case "PUT /KeyfactorAPI/Security/Roles":
+			json.NewDecoder(r.Body).Decode(roleUpdate)
+			json.NewEncoder(w).Encode(UpdateSecurityRoleResponse{})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func TestClient_GetSecurityModel(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims bool
+		want   SecurityModel
+	}{
+		{name: "Command11", claims: true, want: SecurityModelClaims},
+		{name: "Legacy", claims: false, want: SecurityModelIdentities},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			c := newSecurityTestServer(t, tt.claims, &calls, &UpdateSecurityRoleArg{})
+			for i := 0; i < 2; i++ {
+				got, err := c.GetSecurityModel()
+				if err != nil {
+					t.Fatalf("GetSecurityModel() error = %v", err)
+				}
+				if got != tt.want {
+					t.Errorf("GetSecurityModel() = %v, want %v", got, tt.want)
+				}
+			}
+			if len(calls) != 1 {
+				t.Errorf("GetSecurityModel() made %d requests, want 1 (cached)", len(calls))
+			}
+		})
+	}
+}
+
+func TestClient_AddSecurityClaimToRole(t *testing.T) {
+	tests := []struct {
+		name           string
+		claims         bool
+		claim          *SecurityClaimArg
+		wantCall       string
+		wantIdentities int
+		wantErr        error
+	}{
+		{
+			name:     "ClaimsOAuthSubject",
+			claims:   true,
+			claim:    &SecurityClaimArg{ClaimType: ClaimTypeOAuthSubject, ClaimValue: "0oa1b2c3", ProviderAuthenticationScheme: "okta"},
+			wantCall: "POST /KeyfactorAPI/Security/Roles/5/Claims",
+		},
+		{
+			name:           "LegacyGroup",
+			claims:         false,
+			claim:          &SecurityClaimArg{ClaimType: ClaimTypeGroup, ClaimValue: `KEYFACTOR\operators`},
+			wantCall:       "POST /KeyfactorAPI/Security/Identities",
+			wantIdentities: 2,
+		},
+		{
+			name:    "LegacyOAuthSubject",
+			claims:  false,
+			claim:   &SecurityClaimArg{ClaimType: ClaimTypeOAuthSubject, ClaimValue: "0oa1b2c3"},
+			wantErr: ErrClaimTypeNotSupported,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			update := &UpdateSecurityRoleArg{}
+			c := newSecurityTestServer(t, tt.claims, &calls, update)
+
+			err := c.AddSecurityClaimToRole(5, tt.claim)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AddSecurityClaimToRole() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			found := false
+			for _, call := range calls {
+				found = found || call == tt.wantCall
+			}
+			if !found {
+				t.Errorf("AddSecurityClaimToRole() calls = %v, want %s", calls, tt.wantCall)
+			}
+			if tt.wantIdentities > 0 && (update.Identities == nil || len(*update.Identities) != tt.wantIdentities) {
+				t.Errorf("AddSecurityClaimToRole() role update identities = %v, want %d", update.Identities, tt.wantIdentities)
+			}
+		})
+	}
+}
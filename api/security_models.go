@@ -123,3 +123,71 @@ type UpdateSecurityRoleArg struct {
 type UpdateSecurityRoleResponse struct {
 	CreateSecurityRoleResponse
 }
+
+// SecurityModel identifies which security API a Keyfactor Command server exposes.
+type SecurityModel int
+
+const (
+	// SecurityModelIdentities is the legacy Active Directory identity API (/Security/Identities) used before
+	// Command 11.
+	SecurityModelIdentities SecurityModel = iota + 1
+	// SecurityModelClaims is the claims-based API (/Security/Claims) introduced in Command 11, which supports OAuth
+	// and OIDC subjects in addition to Active Directory accounts.
+	SecurityModelClaims
+)
+
+// SecurityClaimType is the kind of subject a security claim matches.
+type SecurityClaimType string
+
+// Security claim types supported by Command 11 and later. Only ClaimTypeUser and ClaimTypeGroup can be represented
+// by the legacy identity API.
+const (
+	ClaimTypeUser          SecurityClaimType = "User"
+	ClaimTypeGroup         SecurityClaimType = "Group"
+	ClaimTypeComputer      SecurityClaimType = "Computer"
+	ClaimTypeOAuthOid      SecurityClaimType = "OAuthOid"
+	ClaimTypeOAuthRole     SecurityClaimType = "OAuthRole"
+	ClaimTypeOAuthSubject  SecurityClaimType = "OAuthSubject"
+	ClaimTypeOAuthClientId SecurityClaimType = "OAuthClientId"
+)
+
+// SecurityClaimArg holds the request body required to create a security claim or attach one to a role.
+type SecurityClaimArg struct {
+	ClaimType                    SecurityClaimType `json:"ClaimType"`
+	ClaimValue                   string            `json:"ClaimValue"`
+	ProviderAuthenticationScheme string            `json:"ProviderAuthenticationScheme,omitempty"`
+	Description                  string            `json:"Description,omitempty"`
+}
+
+// SecurityClaim holds the response data returned by /Security/Claims
+type SecurityClaim struct {
+	Id                           int                       `json:"Id"`
+	ClaimType                    SecurityClaimType         `json:"ClaimType"`
+	ClaimValue                   string                    `json:"ClaimValue"`
+	ProviderAuthenticationScheme string                    `json:"ProviderAuthenticationScheme,omitempty"`
+	Description                  string                    `json:"Description,omitempty"`
+	Roles                        []SecurityRoleInformation `json:"Roles,omitempty"`
+}
+
+// IdentityPermissions holds the effective permissions of a security identity returned by /Security/Identities/{id},
+// including those granted through the groups the identity belongs to.
+type IdentityPermissions struct {
+	Identity               string            `json:"Identity"`
+	SecuredAreaPermissions []PermissionGrant `json:"SecuredAreaPermissions"`
+	CollectionPermissions  []PermissionGrant `json:"CollectionPermissions"`
+	ContainerPermissions   []PermissionGrant `json:"ContainerPermissions"`
+}
+
+// PermissionGrant is a permission, in "area:action" format, along with the names of the roles granting it.
+type PermissionGrant struct {
+	Permission     string   `json:"Permission"`
+	GrantedByRoles []string `json:"GrantedByRoles"`
+}
+
+// MissingPermissionError is returned by CheckPermission when the authenticated identity lacks a permission. It
+// matches ErrPermissionDenied with errors.Is.
+type MissingPermissionError struct {
+	Identity string
+	Area     string
+	Action   string
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPermissionDenied is matched by errors returned when the authenticated identity lacks a Keyfactor permission.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// CheckPermission takes arguments for a secured area and action, e.g. "CertificateStoreManagement" and "Modify", to
+// facilitate calls to Keyfactor that check whether the authenticated identity holds the permission before an
+// operation needing it is attempted. Nil is returned if it does, and a *MissingPermissionError otherwise. Areas and
+// actions are compared case-insensitively.
+//
+// Permissions are looked up through the security identity matching the client's username, so an account that is only
+// granted access through a group it belongs to cannot be checked and an error not matching ErrPermissionDenied is
+// returned.
+func (c *Client) CheckPermission(area, action string) error {
+	if area == "" || action == "" {
+		return errors.New("area and action are required to check a permission")
+	}
+	perms, err := c.GetEffectivePermissions()
+	if err != nil {
+		return err
+	}
+	if perms.HasPermission(area, action) {
+		return nil
+	}
+	return &MissingPermissionError{Identity: perms.Identity, Area: area, Action: action}
+}
+
+// GetEffectivePermissions returns the permissions of the authenticated identity, as granted by all of its roles.
+func (c *Client) GetEffectivePermissions() (*IdentityPermissions, error) {
+	c.infof("Getting effective permissions of the authenticated Keyfactor identity")
+	if c.username == "" {
+		return nil, errors.New("the client has no username to look up permissions for")
+	}
+
+	identities, err := c.GetSecurityIdentities()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list security identities: %w", err)
+	}
+	id := 0
+	for _, identity := range identities {
+		if sameAccountName(identity.AccountName, c.username) {
+			id = identity.Id
+			break
+		}
+	}
+	if id == 0 {
+		return nil, fmt.Errorf("no security identity found for %s; its permissions cannot be determined", c.username)
+	}
+	return c.GetSecurityIdentityPermissions(id)
+}
+
+// GetSecurityIdentityPermissions takes arguments for a security identity ID to facilitate a call to Keyfactor that
+// returns the identity's effective permissions.
+func (c *Client) GetSecurityIdentityPermissions(id int) (*IdentityPermissions, error) {
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "GET",
+		Endpoint: fmt.Sprintf("Security/Identities/%d", id),
+		Headers:  headers,
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonResp := &IdentityPermissions{}
+	err = c.decodeResponse(resp.Body, jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// HasPermission reports whether the identity is granted action on the secured area.
+func (p *IdentityPermissions) HasPermission(area, action string) bool {
+	for _, grant := range p.SecuredAreaPermissions {
+		a, act, _ := strings.Cut(grant.Permission, ":")
+		if strings.EqualFold(strings.TrimSpace(a), area) && strings.EqualFold(strings.TrimSpace(act), action) {
+			return true
+		}
+	}
+	return false
+}
+
+// Error implements the error interface.
+func (e *MissingPermissionError) Error() string {
+	return fmt.Sprintf("%s is missing %s: %s permission", e.Identity, e.Area, e.Action)
+}
+
+// Unwrap allows errors.Is(err, ErrPermissionDenied) to match.
+func (e *MissingPermissionError) Unwrap() error {
+	return ErrPermissionDenied
+}
+
+// sameAccountName reports whether two Active Directory account names are the same, treating a name without a domain
+// as matching the same name in any domain.
+func sameAccountName(a, b string) bool {
+	if strings.EqualFold(a, b) {
+		return true
+	}
+	_, userA, okA := strings.Cut(a, "\\")
+	_, userB, okB := strings.Cut(b, "\\")
+	switch {
+	case okA && !okB:
+		return strings.EqualFold(userA, b)
+	case !okA && okB:
+		return strings.EqualFold(a, userB)
+	}
+	return false
+}
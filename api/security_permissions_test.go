@@ -0,0 +1,82 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClient_CheckPermission(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/KeyfactorAPI/Security/Identities":
+			w.Write([]byte(`[
+				{"Id": 3, "AccountName": "KEYFACTOR\\PKI Admins", "IdentityType": "Group"},
+				{"Id": 7, "AccountName": "KEYFACTOR\\svc-automation", "IdentityType": "User"}
+			]`))
+		case "/KeyfactorAPI/Security/Identities/7":
+			w.Write([]byte(`{
+				"Identity": "KEYFACTOR\\svc-automation",
+				"SecuredAreaPermissions": [
+					{"Permission": "Certificates:Read", "GrantedByRoles": ["Automation"]},
+					{"Permission": "CertificateStoreManagement:Read", "GrantedByRoles": ["Automation", "PKI Admins"]}
+				]
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	c.username = `KEYFACTOR\svc-automation`
+
+	tests := []struct {
+		name       string
+		area       string
+		action     string
+		wantDenied bool
+		wantErr    bool
+	}{
+		{name: "Granted", area: "CertificateStoreManagement", action: "Read"},
+		{name: "CaseInsensitive", area: "certificates", action: "read"},
+		{name: "Missing", area: "CertificateStoreManagement", action: "Modify", wantErr: true, wantDenied: true},
+		{name: "NoArea", action: "Read", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.CheckPermission(tt.area, tt.action)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckPermission() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrPermissionDenied) != tt.wantDenied {
+				t.Errorf("CheckPermission() error = %v, want ErrPermissionDenied %v", err, tt.wantDenied)
+			}
+			if tt.wantDenied && !strings.Contains(err.Error(), "missing CertificateStoreManagement: Modify permission") {
+				t.Errorf("CheckPermission() error = %q", err)
+			}
+		})
+	}
+
+	c.username = "svc-unknown"
+	if err := c.CheckPermission("Certificates", "Read"); err == nil || errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("CheckPermission() for an account without an identity error = %v, want lookup error", err)
+	}
+}
+
+func TestSameAccountName(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: `KEYFACTOR\svc`, b: `keyfactor\SVC`, want: true},
+		{a: `KEYFACTOR\svc`, b: "svc", want: true},
+		{a: "svc", b: `KEYFACTOR\svc`, want: true},
+		{a: `KEYFACTOR\svc`, b: `OTHER\svc`, want: false},
+		{a: "svc", b: "svc2", want: false},
+	}
+	for _, tt := range tests {
+		if got := sameAccountName(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameAccountName(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/Keyfactor/keyfactor-go-client-sdk/api/keyfactor"
+)
+
+// GetSMTPProfile asks Keyfactor for its SMTP configuration. The relay password is never returned.
+func (c *Client) GetSMTPProfile() (*SMTPProfile, error) {
+	return c.GetSMTPProfileContext(context.Background())
+}
+
+// GetSMTPProfileContext is like GetSMTPProfile but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) GetSMTPProfileContext(ctx context.Context) (*SMTPProfile, error) {
+	c.infof("Getting Keyfactor SMTP profile")
+
+	xKeyfactorRequestedWith := "APIClient"
+	xKeyfactorApiVersion := "1"
+
+	apiClient := c.sdkClient()
+
+	resp, _, err := apiClient.SMTPApi.SMTPSMTP(ctx).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	var newResp SMTPProfile
+	mapResp, _ := resp.ToMap()
+	jsonData, _ := json.Marshal(mapResp)
+	if err := json.Unmarshal(jsonData, &newResp); err != nil {
+		return nil, err
+	}
+	return &newResp, nil
+}
+
+// UpdateSMTPProfile takes arguments for an SMTPProfile to facilitate a call to Keyfactor that replaces its SMTP
+// configuration. Host, Port and SenderAccount are required, as are RelayUsername and RelayPassword when the relay
+// uses explicit credentials. The updated profile is returned.
+func (c *Client) UpdateSMTPProfile(profile *SMTPProfile) (*SMTPProfile, error) {
+	return c.UpdateSMTPProfileContext(context.Background(), profile)
+}
+
+// UpdateSMTPProfileContext is like UpdateSMTPProfile but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) UpdateSMTPProfileContext(ctx context.Context, profile *SMTPProfile) (*SMTPProfile, error) {
+	c.infof("Updating Keyfactor SMTP profile")
+	if err := validateSMTPProfile(profile); err != nil {
+		return nil, err
+	}
+
+	xKeyfactorRequestedWith := "APIClient"
+	xKeyfactorApiVersion := "1"
+
+	apiClient := c.sdkClient()
+
+	var newReq keyfactor.KeyfactorAPIModelsSMTPSMTPRequest
+	jsonData, _ := json.Marshal(profile)
+	if err := json.Unmarshal(jsonData, &newReq); err != nil {
+		return nil, err
+	}
+
+	resp, _, err := apiClient.SMTPApi.SMTPUpdateSMTP(ctx).XKeyfactorRequestedWith(xKeyfactorRequestedWith).SmtpProfile(newReq).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	var newResp SMTPProfile
+	mapResp, _ := resp.ToMap()
+	jsonData, _ = json.Marshal(mapResp)
+	if err := json.Unmarshal(jsonData, &newResp); err != nil {
+		return nil, err
+	}
+	return &newResp, nil
+}
+
+// TestSMTPProfile takes arguments for an SMTPProfile and a recipient address to facilitate a call to Keyfactor that
+// sends a test email through the profile, so environment bootstrap can verify that alerting will work before relying
+// on it. The profile does not need to be saved first. An error is returned if Keyfactor could not send the email.
+func (c *Client) TestSMTPProfile(profile *SMTPProfile, recipient string) error {
+	return c.TestSMTPProfileContext(context.Background(), profile, recipient)
+}
+
+// TestSMTPProfileContext is like TestSMTPProfile but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) TestSMTPProfileContext(ctx context.Context, profile *SMTPProfile, recipient string) error {
+	c.infof("Sending test email to %s", recipient)
+	if recipient == "" {
+		return errors.New("a recipient is required to test the SMTP profile")
+	}
+	if err := validateSMTPProfile(profile); err != nil {
+		return err
+	}
+
+	xKeyfactorRequestedWith := "APIClient"
+	xKeyfactorApiVersion := "1"
+
+	apiClient := c.sdkClient()
+
+	var newReq keyfactor.KeyfactorAPIModelsSMTPSMTPTestRequest
+	jsonData, _ := json.Marshal(profile)
+	if err := json.Unmarshal(jsonData, &newReq); err != nil {
+		return err
+	}
+	newReq.TestRecipient = &recipient
+
+	_, _, err := apiClient.SMTPApi.SMTPTestSMTP(ctx).XKeyfactorRequestedWith(xKeyfactorRequestedWith).SmtpProfile(newReq).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	return err
+}
+
+func validateSMTPProfile(profile *SMTPProfile) error {
+	if profile == nil {
+		return errors.New("an SMTP profile is required")
+	}
+	if profile.Host == "" {
+		return errors.New("SMTP host is required")
+	}
+	if profile.Port <= 0 {
+		return errors.New("SMTP port is required")
+	}
+	if profile.SenderAccount == "" {
+		return errors.New("SMTP sender account is required")
+	}
+	if profile.RelayAuthenticationType == SMTPRelayExplicit && (profile.RelayUsername == "" || profile.RelayPassword == "") {
+		return errors.New("relay username and password are required for explicit SMTP relay authentication")
+	}
+	return nil
+}
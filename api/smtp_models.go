@@ -0,0 +1,26 @@
+package api
+
+// SMTPRelayAuthType is how Keyfactor authenticates to the SMTP relay.
+type SMTPRelayAuthType int
+
+const (
+	SMTPRelayAnonymous SMTPRelayAuthType = iota
+	// SMTPRelayIntegrated authenticates as the Keyfactor service account.
+	SMTPRelayIntegrated
+	// SMTPRelayExplicit authenticates with RelayUsername and RelayPassword.
+	SMTPRelayExplicit
+)
+
+// SMTPProfile is the SMTP configuration Keyfactor uses to send alert and workflow emails.
+type SMTPProfile struct {
+	Id                      int               `json:"Id,omitempty"`
+	Host                    string            `json:"Host"`
+	Port                    int               `json:"Port"`
+	SenderAccount           string            `json:"SenderAccount"`
+	SenderName              string            `json:"SenderName"`
+	UseSSL                  bool              `json:"UseSSL"`
+	RelayAuthenticationType SMTPRelayAuthType `json:"RelayAuthenticationType"`
+	RelayUsername           string            `json:"RelayUsername,omitempty"`
+	// RelayPassword is write-only; Keyfactor never returns it.
+	RelayPassword string `json:"RelayPassword,omitempty"`
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestClient_SMTPProfile(t *testing.T) {
+	var updated, tested map[string]interface{}
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /KeyfactorAPI/SMTP":
+			w.Write([]byte(`{"Id": 1, "Host": "smtp.example.com", "Port": 587, "SenderAccount": "keyfactor@example.com", "UseSSL": true, "RelayAuthenticationType": 2, "RelayUsername": "relay"}`))
+		case "PUT /KeyfactorAPI/SMTP":
+			json.NewDecoder(r.Body).Decode(&updated)
+			delete(updated, "RelayPassword")
+			json.NewEncoder(w).Encode(updated)
+		case "POST /KeyfactorAPI/SMTP/Test":
+			json.NewDecoder(r.Body).Decode(&tested)
+			if tested["Host"] == "bad.example.com" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"ErrorCode": "0xA0110010", "Message": "Unable to connect to the SMTP server."}`))
+				return
+			}
+			json.NewEncoder(w).Encode(tested)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	profile, err := c.GetSMTPProfile()
+	if err != nil {
+		t.Fatalf("GetSMTPProfile() error = %v", err)
+	}
+	if profile.Host != "smtp.example.com" || profile.Port != 587 || !profile.UseSSL || profile.RelayAuthenticationType != SMTPRelayExplicit {
+		t.Errorf("GetSMTPProfile() = %+v", profile)
+	}
+
+	profile.Port = 25
+	profile.RelayPassword = "s3cret"
+	got, err := c.UpdateSMTPProfile(profile)
+	if err != nil {
+		t.Fatalf("UpdateSMTPProfile() error = %v", err)
+	}
+	if updated["Port"] != float64(25) || got.Port != 25 {
+		t.Errorf("UpdateSMTPProfile() sent %v, returned %+v", updated, got)
+	}
+
+	tests := []struct {
+		name      string
+		profile   *SMTPProfile
+		recipient string
+		wantErr   bool
+	}{
+		{name: "Valid", profile: &SMTPProfile{Host: "smtp.example.com", Port: 25, SenderAccount: "kf@example.com"}, recipient: "ops@example.com"},
+		{name: "SendFails", profile: &SMTPProfile{Host: "bad.example.com", Port: 25, SenderAccount: "kf@example.com"}, recipient: "ops@example.com", wantErr: true},
+		{name: "NoRecipient", profile: &SMTPProfile{Host: "smtp.example.com", Port: 25, SenderAccount: "kf@example.com"}, wantErr: true},
+		{name: "MissingRelayPassword", profile: &SMTPProfile{Host: "smtp.example.com", Port: 25, SenderAccount: "kf@example.com", RelayAuthenticationType: SMTPRelayExplicit, RelayUsername: "relay"}, recipient: "ops@example.com", wantErr: true},
+		{name: "NilProfile", recipient: "ops@example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.TestSMTPProfile(tt.profile, tt.recipient)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TestSMTPProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && tested["TestRecipient"] != tt.recipient {
+				t.Errorf("TestSMTPProfile() sent %v", tested)
+			}
+		})
+	}
+}
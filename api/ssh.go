@@ -0,0 +1,424 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ListSSHServerGroups takes arguments for a query string, e.g. `GroupName -contains "prod"`, and paging settings to
+// facilitate a call to Keyfactor that returns the matching SSH server groups. An empty query returns every group.
+func (c *Client) ListSSHServerGroups(q string, paging *Paging) ([]SSHServerGroup, error) {
+	return c.ListSSHServerGroupsContext(context.Background(), q, paging)
+}
+
+// ListSSHServerGroupsContext is like ListSSHServerGroups but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) ListSSHServerGroupsContext(ctx context.Context, q string, paging *Paging) ([]SSHServerGroup, error) {
+	c.infof("Listing Keyfactor SSH server groups matching query '%s'", q)
+
+	jsonResp := []SSHServerGroup{}
+	err := c.sendSSHRequest(&request{
+		Method:   "GET",
+		Endpoint: "SSH/ServerGroups",
+		Query:    &apiQuery{Query: sshQuery(q, paging)},
+		Context:  ctx,
+	}, &jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// GetSSHServerGroup takes arguments for a server group ID to facilitate a call to Keyfactor that returns the SSH
+// server group.
+func (c *Client) GetSSHServerGroup(id string) (*SSHServerGroup, error) {
+	return c.GetSSHServerGroupContext(context.Background(), id)
+}
+
+// GetSSHServerGroupContext is like GetSSHServerGroup but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) GetSSHServerGroupContext(ctx context.Context, id string) (*SSHServerGroup, error) {
+	c.infof("Getting Keyfactor SSH server group with ID %s", id)
+
+	if err := validateGUID("ssh server group", id); err != nil {
+		return nil, err
+	}
+	jsonResp := &SSHServerGroup{}
+	err := c.sendSSHRequest(&request{
+		Method:   "GET",
+		Endpoint: "SSH/ServerGroups/" + id,
+		Context:  ctx,
+	}, jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// GetSSHServerGroupByName takes arguments for a server group name to facilitate a call to Keyfactor that returns the
+// SSH server group with that name.
+func (c *Client) GetSSHServerGroupByName(name string) (*SSHServerGroup, error) {
+	return c.GetSSHServerGroupByNameContext(context.Background(), name)
+}
+
+// GetSSHServerGroupByNameContext is like GetSSHServerGroupByName but uses ctx for the request, allowing it to be
+// cancelled.
+func (c *Client) GetSSHServerGroupByNameContext(ctx context.Context, name string) (*SSHServerGroup, error) {
+	c.infof("Getting Keyfactor SSH server group %s", name)
+
+	if name == "" {
+		return nil, errors.New("ssh server group name is required")
+	}
+	if _, err := ParseGUID(name); err == nil {
+		return nil, fmt.Errorf("ssh server group name %s is a GUID; use GetSSHServerGroup to get a group by id", name)
+	}
+	jsonResp := &SSHServerGroup{}
+	err := c.sendSSHRequest(&request{
+		Method:   "GET",
+		Endpoint: "SSH/ServerGroups/" + name,
+		Context:  ctx,
+	}, jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// CreateSSHServerGroup takes arguments for CreateSSHServerGroupArgs to facilitate a call to Keyfactor that creates an
+// SSH server group.
+func (c *Client) CreateSSHServerGroup(args *CreateSSHServerGroupArgs) (*SSHServerGroup, error) {
+	return c.CreateSSHServerGroupContext(context.Background(), args)
+}
+
+// CreateSSHServerGroupContext is like CreateSSHServerGroup but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) CreateSSHServerGroupContext(ctx context.Context, args *CreateSSHServerGroupArgs) (*SSHServerGroup, error) {
+	if args == nil || args.GroupName == "" || args.OwnerName == "" {
+		return nil, errors.New("group name and owner name are required to create an ssh server group")
+	}
+	c.infof("Creating Keyfactor SSH server group %s", args.GroupName)
+
+	jsonResp := &SSHServerGroup{}
+	err := c.sendSSHRequest(&request{
+		Method:   "POST",
+		Endpoint: "SSH/ServerGroups",
+		Payload:  args,
+		Context:  ctx,
+	}, jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// UpdateSSHServerGroup takes arguments for UpdateSSHServerGroupArgs to facilitate a call to Keyfactor that replaces
+// the name, owner, sync schedule, and management of an SSH server group.
+func (c *Client) UpdateSSHServerGroup(args *UpdateSSHServerGroupArgs) (*SSHServerGroup, error) {
+	return c.UpdateSSHServerGroupContext(context.Background(), args)
+}
+
+// UpdateSSHServerGroupContext is like UpdateSSHServerGroup but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) UpdateSSHServerGroupContext(ctx context.Context, args *UpdateSSHServerGroupArgs) (*SSHServerGroup, error) {
+	if args == nil {
+		return nil, errors.New("arguments are required to update an ssh server group")
+	}
+	c.infof("Updating Keyfactor SSH server group with ID %s", args.Id)
+
+	if err := validateGUID("ssh server group", args.Id); err != nil {
+		return nil, err
+	}
+	if args.GroupName == "" || args.OwnerName == "" {
+		return nil, errors.New("group name and owner name are required to update an ssh server group")
+	}
+	jsonResp := &SSHServerGroup{}
+	err := c.sendSSHRequest(&request{
+		Method:   "PUT",
+		Endpoint: "SSH/ServerGroups",
+		Payload:  args,
+		Context:  ctx,
+	}, jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// DeleteSSHServerGroup takes arguments for a server group ID to facilitate a call to Keyfactor that deletes the SSH
+// server group.
+func (c *Client) DeleteSSHServerGroup(id string) error {
+	return c.DeleteSSHServerGroupContext(context.Background(), id)
+}
+
+// DeleteSSHServerGroupContext is like DeleteSSHServerGroup but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) DeleteSSHServerGroupContext(ctx context.Context, id string) error {
+	c.infof("Deleting Keyfactor SSH server group with ID %s", id)
+
+	if err := validateGUID("ssh server group", id); err != nil {
+		return err
+	}
+	return c.sendSSHRequest(&request{
+		Method:   "DELETE",
+		Endpoint: "SSH/ServerGroups/" + id,
+		Context:  ctx,
+	}, nil)
+}
+
+// GetSSHServerGroupAccess takes arguments for a server group ID to facilitate a call to Keyfactor that returns the
+// access policy of the SSH server group.
+func (c *Client) GetSSHServerGroupAccess(id string) (*SSHServerGroupAccess, error) {
+	return c.GetSSHServerGroupAccessContext(context.Background(), id)
+}
+
+// GetSSHServerGroupAccessContext is like GetSSHServerGroupAccess but uses ctx for the request, allowing it to be
+// cancelled.
+func (c *Client) GetSSHServerGroupAccessContext(ctx context.Context, id string) (*SSHServerGroupAccess, error) {
+	c.infof("Getting access policy of Keyfactor SSH server group with ID %s", id)
+
+	if err := validateGUID("ssh server group", id); err != nil {
+		return nil, err
+	}
+	jsonResp := &SSHServerGroupAccess{}
+	err := c.sendSSHRequest(&request{
+		Method:   "GET",
+		Endpoint: "SSH/ServerGroups/Access/" + id,
+		Context:  ctx,
+	}, jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// AddSSHServerGroupAccess takes arguments for SSHServerGroupAccessArgs to facilitate a call to Keyfactor that allows
+// the SSH users to log on as the logon names on every server of the group. Logon names that do not exist on a server
+// are created. The resulting access policy is returned.
+func (c *Client) AddSSHServerGroupAccess(args *SSHServerGroupAccessArgs) (*SSHServerGroupAccess, error) {
+	return c.AddSSHServerGroupAccessContext(context.Background(), args)
+}
+
+// AddSSHServerGroupAccessContext is like AddSSHServerGroupAccess but uses ctx for the request, allowing it to be
+// cancelled.
+func (c *Client) AddSSHServerGroupAccessContext(ctx context.Context, args *SSHServerGroupAccessArgs) (*SSHServerGroupAccess, error) {
+	return c.changeSSHServerGroupAccess(ctx, "POST", args)
+}
+
+// RemoveSSHServerGroupAccess takes arguments for SSHServerGroupAccessArgs to facilitate a call to Keyfactor that stops
+// the SSH users from logging on as the logon names on the servers of the group. The resulting access policy is
+// returned.
+func (c *Client) RemoveSSHServerGroupAccess(args *SSHServerGroupAccessArgs) (*SSHServerGroupAccess, error) {
+	return c.RemoveSSHServerGroupAccessContext(context.Background(), args)
+}
+
+// RemoveSSHServerGroupAccessContext is like RemoveSSHServerGroupAccess but uses ctx for the request, allowing it to
+// be cancelled.
+func (c *Client) RemoveSSHServerGroupAccessContext(ctx context.Context, args *SSHServerGroupAccessArgs) (*SSHServerGroupAccess, error) {
+	return c.changeSSHServerGroupAccess(ctx, "DELETE", args)
+}
+
+// changeSSHServerGroupAccess sends a request to the server group access endpoint that adds or removes access.
+func (c *Client) changeSSHServerGroupAccess(ctx context.Context, method string, args *SSHServerGroupAccessArgs) (*SSHServerGroupAccess, error) {
+	if args == nil {
+		return nil, errors.New("arguments are required to change the access policy of an ssh server group")
+	}
+	c.infof("Changing access policy of Keyfactor SSH server group with ID %s", args.ServerGroupId)
+
+	if err := validateGUID("ssh server group", args.ServerGroupId); err != nil {
+		return nil, err
+	}
+	if len(args.LogonUsers) == 0 {
+		return nil, errors.New("at least one logon is required to change the access policy of an ssh server group")
+	}
+	for _, lu := range args.LogonUsers {
+		if lu.LogonName == "" {
+			return nil, errors.New("logon name is required to change the access policy of an ssh server group")
+		}
+	}
+	jsonResp := &SSHServerGroupAccess{}
+	err := c.sendSSHRequest(&request{
+		Method:   method,
+		Endpoint: "SSH/ServerGroups/Access",
+		Payload:  args,
+		Context:  ctx,
+	}, jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// Users returns the SSH users allowed to log on as logonName on the servers of the group. Logon names are compared
+// case-sensitively, as Linux account names are.
+func (a *SSHServerGroupAccess) Users(logonName string) []SSHUser {
+	for _, lu := range a.LogonUsers {
+		if lu.LogonName == logonName {
+			return lu.Users
+		}
+	}
+	return nil
+}
+
+// LogonNames returns the logon names the SSH user with the given username is allowed to log on as on the servers of
+// the group. Usernames are compared case-insensitively.
+func (a *SSHServerGroupAccess) LogonNames(username string) []string {
+	names := []string{}
+	for _, lu := range a.LogonUsers {
+		for _, u := range lu.Users {
+			if strings.EqualFold(u.Username, username) {
+				names = append(names, lu.LogonName)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// ListSSHLogons takes arguments for a query string, e.g. `ServerName -eq "web01.example.com"`, and paging settings to
+// facilitate a call to Keyfactor that returns the matching SSH logons. An empty query returns every logon.
+func (c *Client) ListSSHLogons(q string, paging *Paging) ([]SSHLogonSummary, error) {
+	return c.ListSSHLogonsContext(context.Background(), q, paging)
+}
+
+// ListSSHLogonsContext is like ListSSHLogons but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) ListSSHLogonsContext(ctx context.Context, q string, paging *Paging) ([]SSHLogonSummary, error) {
+	c.infof("Listing Keyfactor SSH logons matching query '%s'", q)
+
+	jsonResp := []SSHLogonSummary{}
+	err := c.sendSSHRequest(&request{
+		Method:   "GET",
+		Endpoint: "SSH/Logons",
+		Query:    &apiQuery{Query: sshQuery(q, paging)},
+		Context:  ctx,
+	}, &jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// GetSSHLogon takes arguments for a logon ID to facilitate a call to Keyfactor that returns the SSH logon, including
+// its server and the SSH users allowed to log on as it.
+func (c *Client) GetSSHLogon(id int) (*SSHLogon, error) {
+	return c.GetSSHLogonContext(context.Background(), id)
+}
+
+// GetSSHLogonContext is like GetSSHLogon but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) GetSSHLogonContext(ctx context.Context, id int) (*SSHLogon, error) {
+	c.infof("Getting Keyfactor SSH logon with ID %d", id)
+
+	if id <= 0 {
+		return nil, errors.New("ssh logon id is required")
+	}
+	jsonResp := &SSHLogon{}
+	err := c.sendSSHRequest(&request{
+		Method:   "GET",
+		Endpoint: fmt.Sprintf("SSH/Logons/%d", id),
+		Context:  ctx,
+	}, jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// CreateSSHLogon takes arguments for CreateSSHLogonArgs to facilitate a call to Keyfactor that creates a logon on an
+// SSH server and allows the given SSH users to log on as it.
+func (c *Client) CreateSSHLogon(args *CreateSSHLogonArgs) (*SSHLogon, error) {
+	return c.CreateSSHLogonContext(context.Background(), args)
+}
+
+// CreateSSHLogonContext is like CreateSSHLogon but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) CreateSSHLogonContext(ctx context.Context, args *CreateSSHLogonArgs) (*SSHLogon, error) {
+	if args == nil || args.Username == "" || args.ServerId <= 0 {
+		return nil, errors.New("username and server id are required to create an ssh logon")
+	}
+	c.infof("Creating Keyfactor SSH logon %s on server %d", args.Username, args.ServerId)
+
+	jsonResp := &SSHLogon{}
+	err := c.sendSSHRequest(&request{
+		Method:   "POST",
+		Endpoint: "SSH/Logons",
+		Payload:  args,
+		Context:  ctx,
+	}, jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// DeleteSSHLogon takes arguments for a logon ID to facilitate a call to Keyfactor that deletes the SSH logon.
+func (c *Client) DeleteSSHLogon(id int) error {
+	return c.DeleteSSHLogonContext(context.Background(), id)
+}
+
+// DeleteSSHLogonContext is like DeleteSSHLogon but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) DeleteSSHLogonContext(ctx context.Context, id int) error {
+	c.infof("Deleting Keyfactor SSH logon with ID %d", id)
+
+	if id <= 0 {
+		return errors.New("ssh logon id is required")
+	}
+	return c.sendSSHRequest(&request{
+		Method:   "DELETE",
+		Endpoint: fmt.Sprintf("SSH/Logons/%d", id),
+		Context:  ctx,
+	}, nil)
+}
+
+// SetSSHLogonAccess takes arguments for SetSSHLogonAccessArgs to facilitate a call to Keyfactor that replaces the SSH
+// users allowed to log on as a logon. An empty UserIds revokes everyone's access.
+func (c *Client) SetSSHLogonAccess(args *SetSSHLogonAccessArgs) (*SSHLogonAccess, error) {
+	return c.SetSSHLogonAccessContext(context.Background(), args)
+}
+
+// SetSSHLogonAccessContext is like SetSSHLogonAccess but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) SetSSHLogonAccessContext(ctx context.Context, args *SetSSHLogonAccessArgs) (*SSHLogonAccess, error) {
+	if args == nil || args.LogonId <= 0 {
+		return nil, errors.New("ssh logon id is required to set logon access")
+	}
+	c.infof("Setting access to Keyfactor SSH logon with ID %d", args.LogonId)
+
+	payload := *args
+	if payload.UserIds == nil {
+		payload.UserIds = []int{}
+	}
+	jsonResp := &SSHLogonAccess{}
+	err := c.sendSSHRequest(&request{
+		Method:   "POST",
+		Endpoint: "SSH/Logons/Access",
+		Payload:  &payload,
+		Context:  ctx,
+	}, jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// sshQuery returns the query parameters of the SSH list endpoints.
+func sshQuery(q string, paging *Paging) []StringTuple {
+	var params []StringTuple
+	if q != "" {
+		params = append(params, StringTuple{"pq.queryString", q})
+	}
+	return append(params, paging.query()...)
+}
+
+// sendSSHRequest sends a request to an SSH endpoint and decodes the response body into v, unless v is nil.
+func (c *Client) sendSSHRequest(req *request, v interface{}) error {
+	// Set Keyfactor-specific headers
+	req.Headers = &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	resp, err := c.sendRequest(req)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return nil
+	}
+	return c.decodeResponse(resp.Body, v)
+}
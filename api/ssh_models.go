@@ -0,0 +1,133 @@
+package api
+
+// SSHUser is a Keyfactor SSH user: a person or service account whose public key can be granted access to logons.
+type SSHUser struct {
+	Id       int     `json:"Id"`
+	Username string  `json:"Username"`
+	Key      *SSHKey `json:"Key,omitempty"`
+	LogonIds []int   `json:"LogonIds,omitempty"`
+}
+
+// SSHKey is the public SSH key of an SSHUser.
+type SSHKey struct {
+	Id           int       `json:"Id"`
+	Fingerprint  string    `json:"Fingerprint"`
+	PublicKey    string    `json:"PublicKey"`
+	KeyType      string    `json:"KeyType"`
+	KeyLength    int       `json:"KeyLength"`
+	CreationDate Timestamp `json:"CreationDate"`
+	StaleDate    Timestamp `json:"StaleDate"`
+	Email        string    `json:"Email"`
+	Comments     []string  `json:"Comments,omitempty"`
+	LogonCount   int       `json:"LogonCount"`
+}
+
+// SSHServerGroup is a group of SSH servers that share an owner, a sync schedule, and access policy.
+type SSHServerGroup struct {
+	Id              string             `json:"Id"`
+	GroupName       string             `json:"GroupName"`
+	Owner           *SSHUser           `json:"Owner,omitempty"`
+	SyncSchedule    *InventorySchedule `json:"SyncSchedule,omitempty"`
+	UnderManagement bool               `json:"UnderManagement"`
+	ServerCount     int                `json:"ServerCount"`
+}
+
+// CreateSSHServerGroupArgs holds the arguments of CreateSSHServerGroup.
+type CreateSSHServerGroupArgs struct {
+	// OwnerName is the username of the SSH user that owns the group, e.g. "EXAMPLE\\pki".
+	OwnerName string `json:"OwnerName"`
+	GroupName string `json:"GroupName"`
+	// SyncSchedule is how often the authorized keys of the group's servers are synchronized.
+	SyncSchedule *InventorySchedule `json:"SyncSchedule,omitempty"`
+	// UnderManagement makes Keyfactor manage the authorized keys of the group's servers rather than only inventory
+	// them.
+	UnderManagement *bool `json:"UnderManagement,omitempty"`
+}
+
+// UpdateSSHServerGroupArgs holds the arguments of UpdateSSHServerGroup. Every field is replaced.
+type UpdateSSHServerGroupArgs struct {
+	Id              string             `json:"Id"`
+	OwnerName       string             `json:"OwnerName"`
+	GroupName       string             `json:"GroupName"`
+	SyncSchedule    *InventorySchedule `json:"SyncSchedule,omitempty"`
+	UnderManagement bool               `json:"UnderManagement"`
+}
+
+// SSHServer is a server whose authorized keys Keyfactor inventories or manages.
+type SSHServer struct {
+	Id              int                `json:"Id"`
+	AgentId         string             `json:"AgentId"`
+	Hostname        string             `json:"Hostname"`
+	Port            int                `json:"Port"`
+	ServerGroupId   string             `json:"ServerGroupId"`
+	GroupName       string             `json:"GroupName"`
+	Orchestrator    string             `json:"Orchestrator"`
+	SyncSchedule    *InventorySchedule `json:"SyncSchedule,omitempty"`
+	UnderManagement bool               `json:"UnderManagement"`
+	Owner           *SSHUser           `json:"Owner,omitempty"`
+}
+
+// SSHLogon is a Linux account on an SSH server, along with the SSH users allowed to log on as it.
+type SSHLogon struct {
+	Id        int        `json:"Id"`
+	Username  string     `json:"Username"`
+	LastLogon Timestamp  `json:"LastLogon"`
+	Server    *SSHServer `json:"Server,omitempty"`
+	KeyCount  int        `json:"KeyCount"`
+	Access    []SSHUser  `json:"Access,omitempty"`
+}
+
+// SSHLogonSummary is a logon as returned by ListSSHLogons, which names its server rather than including it.
+type SSHLogonSummary struct {
+	Id                    int       `json:"Id"`
+	Username              string    `json:"Username"`
+	LastLogon             Timestamp `json:"LastLogon"`
+	ServerId              int       `json:"ServerId"`
+	ServerName            string    `json:"ServerName"`
+	GroupName             string    `json:"GroupName"`
+	KeyCount              int       `json:"KeyCount"`
+	ServerUnderManagement bool      `json:"ServerUnderManagement"`
+}
+
+// CreateSSHLogonArgs holds the arguments of CreateSSHLogon.
+type CreateSSHLogonArgs struct {
+	// Username is the name of the Linux account, e.g. "deploy".
+	Username string `json:"Username"`
+	ServerId int    `json:"ServerId"`
+	// UserIds are the IDs of the SSH users allowed to log on as the account.
+	UserIds []int `json:"UserIds,omitempty"`
+}
+
+// SetSSHLogonAccessArgs holds the arguments of SetSSHLogonAccess.
+type SetSSHLogonAccessArgs struct {
+	LogonId int `json:"LogonId"`
+	// UserIds are the IDs of the SSH users allowed to log on. Users not listed lose their access.
+	UserIds []int `json:"UserIds"`
+}
+
+// SSHLogonAccess lists the SSH users allowed to log on as a logon.
+type SSHLogonAccess struct {
+	LogonId   int       `json:"LogonId"`
+	LogonName string    `json:"LogonName"`
+	Users     []SSHUser `json:"Users"`
+}
+
+// SSHServerGroupAccess is the access policy of a server group: the SSH users allowed to log on as each logon name on
+// every server of the group.
+type SSHServerGroupAccess struct {
+	ServerGroupId string           `json:"ServerGroupId"`
+	LogonUsers    []SSHLogonAccess `json:"LogonUsers"`
+}
+
+// SSHServerGroupAccessArgs holds the arguments of AddSSHServerGroupAccess and RemoveSSHServerGroupAccess.
+type SSHServerGroupAccessArgs struct {
+	ServerGroupId string          `json:"ServerGroupId"`
+	LogonUsers    []SSHLogonUsers `json:"LogonUsers"`
+}
+
+// SSHLogonUsers names SSH users and the logon name they are granted or denied in SSHServerGroupAccessArgs.
+type SSHLogonUsers struct {
+	LogonName string `json:"LogonName"`
+	// Users are the usernames of the SSH users, e.g. "EXAMPLE\\alice".
+	Users []string `json:"Users"`
+}
@@ -0,0 +1,222 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+const testSSHGroupId = "5e8d3c4a-1b2f-4c6d-9e8f-0a1b2c3d4e5f"
+
+func TestClient_SSHServerGroups(t *testing.T) {
+	type call struct {
+		method, path, query string
+		body                map[string]interface{}
+	}
+	var calls []call
+	groupJSON := `{"Id": "` + testSSHGroupId + `", "GroupName": "prod-linux", "Owner": {"Id": 2, "Username": "EXAMPLE\\pki"}, "UnderManagement": true, "ServerCount": "3"}`
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		calls = append(calls, call{r.Method, r.URL.Path, r.URL.RawQuery, body})
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "DELETE" && r.URL.Path == "/KeyfactorAPI/SSH/ServerGroups/"+testSSHGroupId:
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/KeyfactorAPI/SSH/ServerGroups":
+			if r.Method == "GET" {
+				w.Write([]byte(`[` + groupJSON + `]`))
+				return
+			}
+			w.Write([]byte(groupJSON))
+		case r.URL.Path == "/KeyfactorAPI/SSH/ServerGroups/"+testSSHGroupId, r.URL.Path == "/KeyfactorAPI/SSH/ServerGroups/prod-linux":
+			w.Write([]byte(groupJSON))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	want := SSHServerGroup{Id: testSSHGroupId, GroupName: "prod-linux", Owner: &SSHUser{Id: 2, Username: `EXAMPLE\pki`}, UnderManagement: true, ServerCount: 3}
+
+	groups, err := c.ListSSHServerGroups(`GroupName -contains "prod"`, &Paging{PageReturned: 1, ReturnLimit: 50})
+	if err != nil {
+		t.Fatalf("ListSSHServerGroups() error = %v", err)
+	}
+	if !reflect.DeepEqual(groups, []SSHServerGroup{want}) {
+		t.Errorf("ListSSHServerGroups() = %+v, want %+v", groups, want)
+	}
+	if q, _ := url.ParseQuery(calls[0].query); q.Get("pq.queryString") != `GroupName -contains "prod"` || q.Get("pq.returnLimit") != "50" {
+		t.Errorf("ListSSHServerGroups() query = %s", calls[0].query)
+	}
+
+	if got, err := c.GetSSHServerGroup(testSSHGroupId); err != nil || !reflect.DeepEqual(*got, want) {
+		t.Errorf("GetSSHServerGroup() = %+v, %v", got, err)
+	}
+	if got, err := c.GetSSHServerGroupByName("prod-linux"); err != nil || got.Id != testSSHGroupId {
+		t.Errorf("GetSSHServerGroupByName() = %+v, %v", got, err)
+	}
+
+	calls = nil
+	managed := true
+	if _, err := c.CreateSSHServerGroup(&CreateSSHServerGroupArgs{OwnerName: `EXAMPLE\pki`, GroupName: "prod-linux", UnderManagement: &managed}); err != nil {
+		t.Fatalf("CreateSSHServerGroup() error = %v", err)
+	}
+	if _, err := c.UpdateSSHServerGroup(&UpdateSSHServerGroupArgs{Id: testSSHGroupId, OwnerName: `EXAMPLE\pki`, GroupName: "prod-linux"}); err != nil {
+		t.Fatalf("UpdateSSHServerGroup() error = %v", err)
+	}
+	if err := c.DeleteSSHServerGroup(testSSHGroupId); err != nil {
+		t.Fatalf("DeleteSSHServerGroup() error = %v", err)
+	}
+	var methods []string
+	for _, call := range calls {
+		methods = append(methods, call.method)
+	}
+	if !reflect.DeepEqual(methods, []string{"POST", "PUT", "DELETE"}) {
+		t.Errorf("requests = %v, want POST, PUT, DELETE", methods)
+	}
+	if calls[0].body["GroupName"] != "prod-linux" || calls[0].body["UnderManagement"] != true {
+		t.Errorf("CreateSSHServerGroup() sent %v", calls[0].body)
+	}
+	if calls[1].body["Id"] != testSSHGroupId || calls[1].body["UnderManagement"] != false {
+		t.Errorf("UpdateSSHServerGroup() sent %v", calls[1].body)
+	}
+
+	calls = nil
+	invalid := []error{}
+	_, err = c.GetSSHServerGroup("prod-linux")
+	invalid = append(invalid, err)
+	_, err = c.GetSSHServerGroupByName(testSSHGroupId)
+	invalid = append(invalid, err)
+	_, err = c.CreateSSHServerGroup(&CreateSSHServerGroupArgs{GroupName: "prod-linux"})
+	invalid = append(invalid, err)
+	_, err = c.UpdateSSHServerGroup(&UpdateSSHServerGroupArgs{Id: testSSHGroupId})
+	invalid = append(invalid, err)
+	invalid = append(invalid, c.DeleteSSHServerGroup(""))
+	for i, err := range invalid {
+		if err == nil {
+			t.Errorf("invalid call %d succeeded, want error", i)
+		}
+	}
+	if len(calls) != 0 {
+		t.Errorf("invalid calls sent %d requests, want none", len(calls))
+	}
+}
+
+func TestClient_SSHServerGroupAccess(t *testing.T) {
+	var lastMethod string
+	var lastBody SSHServerGroupAccessArgs
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		lastMethod = r.Method
+		json.NewDecoder(r.Body).Decode(&lastBody)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/KeyfactorAPI/SSH/ServerGroups/Access", "/KeyfactorAPI/SSH/ServerGroups/Access/" + testSSHGroupId:
+			w.Write([]byte(`{"ServerGroupId": "` + testSSHGroupId + `", "LogonUsers": [
+				{"LogonId": 10, "LogonName": "deploy", "Users": [{"Id": 1, "Username": "EXAMPLE\\alice"}, {"Id": 2, "Username": "EXAMPLE\\bob"}]},
+				{"LogonId": 11, "LogonName": "root", "Users": [{"Id": 1, "Username": "EXAMPLE\\alice"}]}
+			]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	access, err := c.GetSSHServerGroupAccess(testSSHGroupId)
+	if err != nil {
+		t.Fatalf("GetSSHServerGroupAccess() error = %v", err)
+	}
+	if users := access.Users("deploy"); len(users) != 2 || users[1].Username != `EXAMPLE\bob` {
+		t.Errorf("Users(deploy) = %+v", users)
+	}
+	if users := access.Users("Deploy"); users != nil {
+		t.Errorf("Users(Deploy) = %+v, want nil", users)
+	}
+	if got := access.LogonNames(`example\ALICE`); !reflect.DeepEqual(got, []string{"deploy", "root"}) {
+		t.Errorf("LogonNames(alice) = %v", got)
+	}
+	if got := access.LogonNames(`EXAMPLE\carol`); len(got) != 0 {
+		t.Errorf("LogonNames(carol) = %v, want none", got)
+	}
+
+	args := &SSHServerGroupAccessArgs{ServerGroupId: testSSHGroupId, LogonUsers: []SSHLogonUsers{{LogonName: "deploy", Users: []string{`EXAMPLE\bob`}}}}
+	if _, err := c.AddSSHServerGroupAccess(args); err != nil || lastMethod != "POST" || !reflect.DeepEqual(lastBody, *args) {
+		t.Errorf("AddSSHServerGroupAccess() sent %s %+v, error = %v", lastMethod, lastBody, err)
+	}
+	if _, err := c.RemoveSSHServerGroupAccess(args); err != nil || lastMethod != "DELETE" {
+		t.Errorf("RemoveSSHServerGroupAccess() sent %s, error = %v", lastMethod, err)
+	}
+	if _, err := c.AddSSHServerGroupAccess(&SSHServerGroupAccessArgs{ServerGroupId: testSSHGroupId}); err == nil {
+		t.Errorf("AddSSHServerGroupAccess() without logons succeeded, want error")
+	}
+}
+
+func TestClient_SSHLogons(t *testing.T) {
+	var lastMethod, lastPath, lastQuery string
+	var lastBody map[string]interface{}
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		lastMethod, lastPath, lastQuery = r.Method, r.URL.Path, r.URL.RawQuery
+		lastBody = nil
+		json.NewDecoder(r.Body).Decode(&lastBody)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/KeyfactorAPI/SSH/Logons" && r.Method == "GET":
+			w.Write([]byte(`[{"Id": 10, "Username": "deploy", "ServerId": 4, "ServerName": "web01.example.com", "GroupName": "prod-linux", "KeyCount": 2, "ServerUnderManagement": true}]`))
+		case r.URL.Path == "/KeyfactorAPI/SSH/Logons/10" && r.Method == "DELETE":
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/KeyfactorAPI/SSH/Logons", r.URL.Path == "/KeyfactorAPI/SSH/Logons/10":
+			w.Write([]byte(`{"Id": 10, "Username": "deploy", "LastLogon": "2024-05-01T10:00:00", "Server": {"Id": 4, "Hostname": "web01.example.com", "Port": 22}, "Access": [{"Id": 1, "Username": "EXAMPLE\\alice"}]}`))
+		case r.URL.Path == "/KeyfactorAPI/SSH/Logons/Access":
+			w.Write([]byte(`{"LogonId": 10, "LogonName": "deploy", "Users": []}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	logons, err := c.ListSSHLogons(`ServerName -eq "web01.example.com"`, nil)
+	if err != nil {
+		t.Fatalf("ListSSHLogons() error = %v", err)
+	}
+	wantSummary := SSHLogonSummary{Id: 10, Username: "deploy", ServerId: 4, ServerName: "web01.example.com", GroupName: "prod-linux", KeyCount: 2, ServerUnderManagement: true}
+	if !reflect.DeepEqual(logons, []SSHLogonSummary{wantSummary}) {
+		t.Errorf("ListSSHLogons() = %+v", logons)
+	}
+	if q, _ := url.ParseQuery(lastQuery); q.Get("pq.queryString") != `ServerName -eq "web01.example.com"` {
+		t.Errorf("ListSSHLogons() query = %s", lastQuery)
+	}
+
+	logon, err := c.GetSSHLogon(10)
+	if err != nil {
+		t.Fatalf("GetSSHLogon() error = %v", err)
+	}
+	if logon.Server == nil || logon.Server.Port != 22 || len(logon.Access) != 1 || !logon.LastLogon.Valid() {
+		t.Errorf("GetSSHLogon() = %+v", logon)
+	}
+
+	if _, err := c.CreateSSHLogon(&CreateSSHLogonArgs{Username: "deploy", ServerId: 4, UserIds: []int{1}}); err != nil {
+		t.Fatalf("CreateSSHLogon() error = %v", err)
+	}
+	if lastMethod != "POST" || lastBody["Username"] != "deploy" || lastBody["ServerId"] != float64(4) {
+		t.Errorf("CreateSSHLogon() sent %s %v", lastMethod, lastBody)
+	}
+
+	access, err := c.SetSSHLogonAccess(&SetSSHLogonAccessArgs{LogonId: 10})
+	if err != nil {
+		t.Fatalf("SetSSHLogonAccess() error = %v", err)
+	}
+	if users, ok := lastBody["UserIds"].([]interface{}); !ok || len(users) != 0 {
+		t.Errorf("SetSSHLogonAccess() sent UserIds %v, want []", lastBody["UserIds"])
+	}
+	if access.LogonName != "deploy" || len(access.Users) != 0 {
+		t.Errorf("SetSSHLogonAccess() = %+v", access)
+	}
+
+	if err := c.DeleteSSHLogon(10); err != nil || lastMethod != "DELETE" || lastPath != "/KeyfactorAPI/SSH/Logons/10" {
+		t.Errorf("DeleteSSHLogon() sent %s %s, error = %v", lastMethod, lastPath, err)
+	}
+	if _, err := c.CreateSSHLogon(&CreateSSHLogonArgs{Username: "deploy"}); err == nil {
+		t.Errorf("CreateSSHLogon() without a server succeeded, want error")
+	}
+	if _, err := c.GetSSHLogon(0); err == nil {
+		t.Errorf("GetSSHLogon(0) succeeded, want error")
+	}
+}
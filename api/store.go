@@ -5,10 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Keyfactor/keyfactor-go-client-sdk/api/keyfactor"
+	"github.com/Keyfactor/keyfactor-go-client/query"
 )
 
 // CreateStore takes arguments for CreateStoreFctArgs to facilitate the creation
@@ -20,15 +24,36 @@ import (
 //   - Properties    : []StringTuple *Note - Method converts this array of StringTuples to a JSON string if provided
 //   - AgentId       : string
 //
-// TODO?
+// If ValidateAgent is set, the orchestrator is first checked for the capability of the store type, and an error
+// wrapping ErrStoreTypeNotSupported is returned instead of creating a store it cannot service. If ValidateProperties
+// is set, the properties are checked against those the store type defines. The store type is looked up once per
+// AuthConfig.StoreTypeCacheTTL for these checks, however many stores of the type are created.
 func (c *Client) CreateStore(ca *CreateStoreFctArgs) (*CreateStoreResponse, error) {
-	log.Println("[INFO] Creating new certificate store with Keyfactor")
+	c.infof("Creating new certificate store with Keyfactor")
 
 	// Validate that the required fields are present
 	err := validateCreateStoreArgs(ca)
 	if err != nil {
 		return nil, err
 	}
+	if ca.ValidateAgent {
+		if err := c.validateAgentSupportsStoreTypeId(ca.AgentId, ca.CertStoreType); err != nil {
+			return nil, err
+		}
+	}
+	if ca.ValidateProperties {
+		storeType, err := c.cachedStoreTypeById(context.Background(), ca.CertStoreType)
+		if err != nil {
+			return nil, err
+		}
+		props := ca.Properties
+		if ca.PropertiesString != "" {
+			props = unmarshalPropertiesString(ca.PropertiesString)
+		}
+		if err := checkStoreProperties(storeType, canonicalProperties(props)); err != nil {
+			return nil, err
+		}
+	}
 
 	// API doesn't know what a StringTuple type is. Convert this type to an array of interfaces
 	// that the JSON library can serialize. Then, serialize to JSON, and convert to string.
@@ -62,10 +87,11 @@ func (c *Client) CreateStore(ca *CreateStoreFctArgs) (*CreateStoreResponse, erro
 	}
 
 	jsonResp := &CreateStoreResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&jsonResp)
+	err = c.decodeResponse(resp.Body, &jsonResp)
 	if err != nil {
 		return nil, err
 	}
+	jsonResp.Properties = unmarshalPropertiesString(jsonResp.PropertiesString)
 	return jsonResp, nil
 }
 
@@ -78,9 +104,19 @@ func (c *Client) CreateStore(ca *CreateStoreFctArgs) (*CreateStoreResponse, erro
 //   - Properties    : []StringTuple *Note - Method converts this slice of StringTuples to a JSON string if provided
 //   - AgentId       : string
 //
+// To keep competing writers from overwriting each other's changes, read the store, set ExpectedRevision to its
+// Revision, and UpdateStore returns an error wrapping ErrConflict if the store changed in the meantime. Conditional
+// updates of the same store through one Client are serialized; writers in other processes can still change the store
+// between the check and the update, which the next conditional update of theirs or ours then detects.
+//
 // TODO?
 func (c *Client) UpdateStore(ua *UpdateStoreFctArgs) (*UpdateStoreResponse, error) {
-	log.Println("[INFO] Creating new certificate store with Keyfactor")
+	return c.UpdateStoreContext(context.Background(), ua)
+}
+
+// UpdateStoreContext is like UpdateStore but uses ctx for the requests, allowing it to be cancelled.
+func (c *Client) UpdateStoreContext(ctx context.Context, ua *UpdateStoreFctArgs) (*UpdateStoreResponse, error) {
+	c.infof("Updating certificate store %s in Keyfactor", ua.Id)
 
 	// Validate that the required fields are present
 	err := validateUpdateStoreArgs(ua)
@@ -88,6 +124,21 @@ func (c *Client) UpdateStore(ua *UpdateStoreFctArgs) (*UpdateStoreResponse, erro
 		return nil, err
 	}
 
+	if ua.ExpectedRevision != "" {
+		if err := validateGUID("certificate store", ua.Id); err != nil {
+			return nil, err
+		}
+		unlock := c.lockStore(ua.Id)
+		defer unlock()
+		current, err := c.GetCertificateStoreByIDContext(ctx, ua.Id)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkStoreRevision(current, ua.ExpectedRevision); err != nil {
+			return nil, err
+		}
+	}
+
 	// API doesn't know what a StringTuple type is. Convert this type to an array of interfaces
 	// that the JSON library can serialize. Then, serialize to JSON, and convert to string.
 	if ua.PropertiesString == "" {
@@ -112,6 +163,7 @@ func (c *Client) UpdateStore(ua *UpdateStoreFctArgs) (*UpdateStoreResponse, erro
 		Endpoint: "CertificateStores",
 		Headers:  headers,
 		Payload:  &ua,
+		Context:  ctx,
 	}
 
 	resp, err := c.sendRequest(keyfactorAPIStruct)
@@ -120,24 +172,33 @@ func (c *Client) UpdateStore(ua *UpdateStoreFctArgs) (*UpdateStoreResponse, erro
 	}
 
 	jsonResp := &UpdateStoreResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&jsonResp)
+	err = c.decodeResponse(resp.Body, &jsonResp)
 	if err != nil {
 		return nil, err
 	}
+	jsonResp.Properties = unmarshalPropertiesString(jsonResp.PropertiesString)
 	return jsonResp, nil
 }
 
 // DeleteCertificateStore takes arguments for a certificate store ID to facilitate a call to Keyfactor
 // that deletes a certificate store. Only the store ID is required.
 func (c *Client) DeleteCertificateStore(storeId string) error {
+	return c.DeleteCertificateStoreContext(context.Background(), storeId)
+}
+
+// DeleteCertificateStoreContext is like DeleteCertificateStore but uses ctx for the request, allowing it to be
+// cancelled.
+func (c *Client) DeleteCertificateStoreContext(ctx context.Context, storeId string) error {
+	if err := validateGUID("certificate store", storeId); err != nil {
+		return err
+	}
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
-	resp, err := apiClient.CertificateStoreApi.CertificateStoreDeleteCertificateStore(context.Background(), storeId).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, err := apiClient.CertificateStoreApi.CertificateStoreDeleteCertificateStore(ctx, storeId).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
 		return err
@@ -150,9 +211,46 @@ func (c *Client) DeleteCertificateStore(storeId string) error {
 	return nil
 }
 
-// ListCertificateStores takes no arguments and returns a slice of CertificateStore objects
-// that represent all certificate stores associated with a Keyfactor Command instance.
+// SetCertificateStorePassword takes arguments for a certificate store ID and a StoreSecret to facilitate a call to
+// Keyfactor that sets the password used to access the certificate store. The secret is either a value stored in the
+// Keyfactor Command database or a reference to a password held by a PAM provider. The store's type must allow the
+// store password to be set.
+func (c *Client) SetCertificateStorePassword(storeId string, secret *StoreSecret) error {
+	c.infof("Setting password of certificate store %s", storeId)
 
+	if err := validateGUID("certificate store", storeId); err != nil {
+		return err
+	}
+	if err := validateStoreSecret(secret); err != nil {
+		return err
+	}
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "PUT",
+		Endpoint: "CertificateStores/Password",
+		Headers:  headers,
+		Payload: &setStorePasswordBody{
+			CertStoreId: storeId,
+			NewPassword: secret,
+		},
+	}
+
+	_, err := c.sendRequest(keyfactorAPIStruct)
+	return err
+}
+
+// ListCertificateStores takes no arguments and returns a slice of CertificateStore objects
+// that represent all certificate stores associated with a Keyfactor Command instance. The slice is empty, not nil, if
+// there are no stores, and nil whenever an error is returned. params filters the stores by field; an Approved filter
+// may be a bool or the string "true" or "false", to tell stores created by discovery apart from approved ones.
 // TODO?
 func (c *Client) ListCertificateStores(params *map[string]interface{}) (*[]GetCertificateStoreResponse, error) {
 	// Set Keyfactor-specific headers
@@ -163,7 +261,7 @@ func (c *Client) ListCertificateStores(params *map[string]interface{}) (*[]GetCe
 		},
 	}
 
-	query := apiQuery{
+	q := apiQuery{
 		Query: []StringTuple{},
 	}
 	if params != nil {
@@ -176,7 +274,15 @@ func (c *Client) ListCertificateStores(params *map[string]interface{}) (*[]GetCe
 			return &[]GetCertificateStoreResponse{*resp}, nil
 		}
 
-		query, _ = buildQuery(*params, "certificateStoreQuery.queryString")
+		filters, fErr := storeListParams(*params)
+		if fErr != nil {
+			return nil, fErr
+		}
+		var qErr error
+		q, qErr = buildQuery(filters, "certificateStoreQuery.queryString")
+		if qErr != nil {
+			return nil, qErr
+		}
 	}
 
 	endpoint := "CertificateStores/"
@@ -185,30 +291,88 @@ func (c *Client) ListCertificateStores(params *map[string]interface{}) (*[]GetCe
 		Endpoint: endpoint,
 		Headers:  headers,
 		Payload:  nil,
-		Query:    &query,
+		Query:    &q,
 	}
 
 	resp, err := c.sendRequest(keyfactorAPIStruct)
 	if err != nil {
-		return &[]GetCertificateStoreResponse{}, err
+		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return &[]GetCertificateStoreResponse{}, fmt.Errorf("[ERROR] Something unexpected happened, %s call to %s returned status %d", keyfactorAPIStruct.Method, keyfactorAPIStruct.Endpoint, resp.StatusCode)
+		return nil, fmt.Errorf("[ERROR] Something unexpected happened, %s call to %s returned status %d", keyfactorAPIStruct.Method, keyfactorAPIStruct.Endpoint, resp.StatusCode)
 	}
 	var jsonResp []GetCertificateStoreResponse
-	err = json.NewDecoder(resp.Body).Decode(&jsonResp)
+	err = c.decodeResponse(resp.Body, &jsonResp)
 	if err != nil {
 		return nil, err
 	}
 	return &jsonResp, nil
 }
 
+// SearchCertificateStores takes arguments for a query string, such as one built with the query package, to facilitate
+// a call to Keyfactor that returns the matching certificate stores. An empty query matches every store. paging may be
+// nil; use its sort settings to have Keyfactor order the stores, e.g. by ClientMachine, rather than sorting a full
+// result set client-side.
+func (c *Client) SearchCertificateStores(q string, paging *Paging) ([]GetCertificateStoreResponse, error) {
+	return c.SearchCertificateStoresContext(context.Background(), q, paging)
+}
+
+// SearchCertificateStoresContext is like SearchCertificateStores but uses ctx for the request, allowing it to be
+// cancelled.
+func (c *Client) SearchCertificateStoresContext(ctx context.Context, q string, paging *Paging) ([]GetCertificateStoreResponse, error) {
+	c.infof("Searching certificate stores matching query '%s'", q)
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	var params []StringTuple
+	if q != "" {
+		params = append(params, StringTuple{"certificateStoreQuery.queryString", q})
+	}
+	params = append(params, paging.prefixedQuery("certificateStoreQuery")...)
+
+	keyfactorAPIStruct := &request{
+		Method:   "GET",
+		Endpoint: "CertificateStores",
+		Headers:  headers,
+		Query:    &apiQuery{Query: params},
+		Context:  ctx,
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonResp []GetCertificateStoreResponse
+	err = c.decodeResponse(resp.Body, &jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
 // GetCertificateStoreByID takes arguments for a certificate store ID to facilitate a call to Keyfactor
 // that retrieves a certificate store context. Only the store ID is required. A pointer to a GetStoreByIDResp struct
-// is returned that contains information on the certificate store.
+// is returned that contains information on the certificate store, including the result of its last inventory.
 // TODO?
 func (c *Client) GetCertificateStoreByID(storeId string) (*GetCertificateStoreResponse, error) {
+	return c.GetCertificateStoreByIDContext(context.Background(), storeId)
+}
+
+// GetCertificateStoreByIDContext is like GetCertificateStoreByID but uses ctx for the request, allowing it to be
+// cancelled.
+func (c *Client) GetCertificateStoreByIDContext(ctx context.Context, storeId string) (*GetCertificateStoreResponse, error) {
+	if err := validateGUID("certificate store", storeId); err != nil {
+		return nil, err
+	}
+
 	// Set Keyfactor-specific headers
 	headers := &apiHeaders{
 		Headers: []StringTuple{
@@ -223,6 +387,7 @@ func (c *Client) GetCertificateStoreByID(storeId string) (*GetCertificateStoreRe
 		Endpoint: endpoint,
 		Headers:  headers,
 		Payload:  nil,
+		Context:  ctx,
 	}
 
 	resp, err := c.sendRequest(keyfactorAPIStruct)
@@ -231,7 +396,7 @@ func (c *Client) GetCertificateStoreByID(storeId string) (*GetCertificateStoreRe
 	}
 
 	jsonResp := &GetCertificateStoreResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&jsonResp)
+	err = c.decodeResponse(resp.Body, &jsonResp)
 	if err != nil {
 		return nil, err
 	}
@@ -239,27 +404,46 @@ func (c *Client) GetCertificateStoreByID(storeId string) (*GetCertificateStoreRe
 	return jsonResp, nil
 }
 
+// GetLastInventory takes arguments for a certificate store, as returned by GetCertificateStoreByID, to facilitate a
+// call to Keyfactor that returns the most recent run of the store's inventory job. The run is also set as the store's
+// LastInventory. Nil is returned if the store has no inventory job or the job has not run yet.
+func (c *Client) GetLastInventory(ctx context.Context, store *GetCertificateStoreResponse) (*JobHistory, error) {
+	if store == nil {
+		return nil, errors.New("certificate store is required to get its last inventory")
+	}
+	if store.CertStoreInventoryJobId == "" {
+		store.LastInventory = nil
+		return nil, nil
+	}
+	last, err := c.GetJob(store.CertStoreInventoryJobId).StatusContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get last inventory of certificate store %s: %w", store.Id, err)
+	}
+	store.LastInventory = last
+	return last, nil
+}
+
 // GetCertificateStoreByID takes arguments for a certificate store ID to facilitate a call to Keyfactor
 // that retrieves a certificate store context. Only the store ID is required. A pointer to a GetStoreByIDResp struct
 // is returned that contains information on the certificate store.
 // TODO?
 func (c *Client) GetCertificateStoreByContainerID(containerID interface{}) (*[]GetCertificateStoreResponse, error) {
 
-	query := apiQuery{
+	q := apiQuery{
 		Query: []StringTuple{},
 	}
 	switch containerID.(type) {
 	case int:
-		query.Query = append(query.Query, StringTuple{
-			"certificateStoreQuery.queryString", fmt.Sprintf(`ContainerId -eq "%d"`, containerID),
+		q.Query = append(q.Query, StringTuple{
+			"certificateStoreQuery.queryString", query.Field("ContainerId").Eq(containerID).String(),
 		})
 	case string:
 		ct, ctErr := c.GetStoreContainer(containerID.(string))
 		if ctErr != nil {
 			return nil, ctErr
 		}
-		query.Query = append(query.Query, StringTuple{
-			"certificateStoreQuery.queryString", fmt.Sprintf(`ContainerId -eq %d`, *ct.Id),
+		q.Query = append(q.Query, StringTuple{
+			"certificateStoreQuery.queryString", query.Field("ContainerId").Eq(*ct.Id).String(),
 		})
 	}
 
@@ -274,12 +458,12 @@ func (c *Client) GetCertificateStoreByContainerID(containerID interface{}) (*[]G
 	endpoint := "CertificateStores"
 
 	var keyfactorAPIStruct *request
-	if query.Query != nil {
+	if q.Query != nil {
 		keyfactorAPIStruct = &request{
 			Method:   "GET",
 			Endpoint: endpoint,
 			Headers:  headers,
-			Query:    &query,
+			Query:    &q,
 		}
 	} else {
 		keyfactorAPIStruct = &request{
@@ -295,7 +479,7 @@ func (c *Client) GetCertificateStoreByContainerID(containerID interface{}) (*[]G
 	}
 
 	jsonResp := &[]GetCertificateStoreResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&jsonResp)
+	err = c.decodeResponse(resp.Body, &jsonResp)
 	if err != nil {
 		return nil, err
 	}
@@ -304,43 +488,80 @@ func (c *Client) GetCertificateStoreByContainerID(containerID interface{}) (*[]G
 }
 
 // AddCertificateToStores takes argument for a AddCertificateToStore structure and is used to add a configured certificate
-// from one or more certificate stores.
+// from one or more certificate stores. The returned orchestrator job IDs can be tracked with GetJobs.
+//
+// With ValidateAliases, the alias of each store is first checked against the CustomAliasAllowed setting of the store's
+// type, and a StoreAliasError is returned without scheduling any job if some do not meet it.
+//
+// Long store lists are sent in several requests, each within the client's request size limit. If some of them fail,
+// the job IDs of the others are returned along with an error naming the stores that were not scheduled.
 func (c *Client) AddCertificateToStores(config *AddCertificateToStore) ([]string, error) {
-	log.Printf("[INFO] Adding certificate with ID %d to one or more certificate stores", config.CertificateId)
+	return c.AddCertificateToStoresContext(context.Background(), config)
+}
+
+// AddCertificateToStoresContext is like AddCertificateToStores but uses ctx for the requests, allowing it to be
+// cancelled.
+func (c *Client) AddCertificateToStoresContext(ctx context.Context, config *AddCertificateToStore) ([]string, error) {
+	c.infof("Adding certificate with ID %d to one or more certificate stores", config.CertificateId)
+
+	if config.CertificateStores == nil || len(*config.CertificateStores) == 0 {
+		return nil, errors.New("at least one certificate store is required to add a certificate to certificate stores")
+	}
+	if config.ValidateAliases {
+		if err := c.validateStoreAliases(ctx, *config.CertificateStores); err != nil {
+			return nil, err
+		}
+	}
+
+	schedule, err := jobSchedule(config.InventorySchedule, config.MaintenanceWindow, time.Now())
+	if err != nil {
+		return nil, err
+	}
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
 	newCollectionId := int32(config.CollectionId)
 	var newCertStoresList []keyfactor.ModelsCertificateStoreEntry
 	for _, cert := range *config.CertificateStores {
-		newProvider := int32(cert.EntryPassword.Provider)
-		var newParams map[string]string
-		data, _ := json.Marshal(cert.EntryPassword.Parameters)
-		json.Unmarshal(data, &newParams)
-		var newEntryPassword = keyfactor.ModelsKeyfactorAPISecret{
-			SecretValue: &cert.EntryPassword.SecretValue,
-			Parameters:  &newParams,
-			Provider:    &newProvider,
-		}
+		cert := cert
 		var newCert = keyfactor.ModelsCertificateStoreEntry{
 			CertificateStoreId: cert.CertificateStoreId,
 			Alias:              &cert.Alias,
 			JobFields:          nil,
 			Overwrite:          &cert.Overwrite,
-			EntryPassword:      &newEntryPassword,
 			PfxPassword:        nil,
 			IncludePrivateKey:  nil,
 		}
+		if cert.EntryPassword != nil {
+			newProvider := int32(cert.EntryPassword.Provider)
+			var newParams map[string]string
+			data, _ := json.Marshal(cert.EntryPassword.Parameters)
+			json.Unmarshal(data, &newParams)
+			newCert.EntryPassword = &keyfactor.ModelsKeyfactorAPISecret{
+				SecretValue: &cert.EntryPassword.SecretValue,
+				Parameters:  &newParams,
+				Provider:    &newProvider,
+			}
+		}
+		if cert.PfxPassword != "" {
+			newCert.PfxPassword = &cert.PfxPassword
+		}
+		if cert.IncludePrivateKey {
+			newCert.IncludePrivateKey = &cert.IncludePrivateKey
+		}
+		if len(cert.EntryParameters) > 0 {
+			// The SDK models job fields as nested objects, but Keyfactor expects a flat name/value map.
+			newCert.AdditionalProperties = map[string]interface{}{"JobFields": cert.EntryParameters}
+		}
 		newCertStoresList = append(newCertStoresList, newCert)
 	}
 
-	jsonInvSched, _ := json.Marshal(config.InventorySchedule)
+	jsonInvSched, _ := json.Marshal(schedule)
 	var newSchedule keyfactor.KeyfactorCommonSchedulingKeyfactorSchedule
-	json.Unmarshal(jsonInvSched, newSchedule)
+	json.Unmarshal(jsonInvSched, &newSchedule)
 	var newReq = keyfactor.KeyfactorApiModelsCertificateStoresAddCertificateRequest{
 		CertificateId:     int32(config.CertificateId),
 		CertificateStores: newCertStoresList,
@@ -348,114 +569,285 @@ func (c *Client) AddCertificateToStores(config *AddCertificateToStore) ([]string
 		CollectionId:      &newCollectionId,
 	}
 
-	resp, _, err := apiClient.CertificateStoreApi.CertificateStoreAddCertificate(context.Background()).XKeyfactorRequestedWith(xKeyfactorRequestedWith).AddRequest(newReq).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
-
+	baseReq := newReq
+	baseReq.CertificateStores = nil
+	batches, err := payloadBatches(len(newCertStoresList), storeLocationBatchSize, c.requestLimit(), jsonSize(&baseReq),
+		func(i int) int { return jsonSize(&newCertStoresList[i]) })
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("unable to add certificate %d to certificate stores: %w", config.CertificateId, err)
 	}
 
-	return resp, nil
+	var jobIds, failed []string
+	for _, batch := range batches {
+		batchReq := newReq
+		batchReq.CertificateStores = newCertStoresList[batch[0]:batch[1]]
+		resp, _, err := apiClient.CertificateStoreApi.CertificateStoreAddCertificate(ctx).XKeyfactorRequestedWith(xKeyfactorRequestedWith).AddRequest(batchReq).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+		if err != nil {
+			if len(batches) == 1 {
+				return nil, err
+			}
+			c.errorf("Unable to add certificate %d to %d certificate stores: %s", config.CertificateId, batch[1]-batch[0], err)
+			var ids []string
+			for _, store := range (*config.CertificateStores)[batch[0]:batch[1]] {
+				ids = append(ids, store.CertificateStoreId)
+			}
+			failed = append(failed, fmt.Sprintf("%s (%s)", strings.Join(ids, ", "), err))
+			continue
+		}
+		jobIds = append(jobIds, resp...)
+	}
+	if len(failed) > 0 {
+		return jobIds, fmt.Errorf("unable to add certificate %d to certificate stores: %s", config.CertificateId, strings.Join(failed, "; "))
+	}
+	return jobIds, nil
 }
 
 // RemoveCertificateFromStores takes argument for a RemoveCertificateFromStore structure, and is used to remove a certificate
-// from one or more certificate stores.
-func (c *Client) RemoveCertificateFromStores(config *RemoveCertificateFromStore) ([]string, error) {
-	log.Println("[INFO] Removing certificate from one or more certificate stores")
+// from one or more certificate stores. Stores without an alias have it resolved from their inventory by certificate ID
+// or thumbprint. Long store lists, such as when decommissioning a certificate everywhere it is deployed, are sent to
+// Keyfactor in chunks, made smaller where needed to stay within the client's request size limit, and a chunk that
+// fails with a transient error is retried with backoff. A store whose alias cannot be resolved or whose chunk keeps
+// failing does not stop the others from being processed. The returned report holds the orchestrator job and error of
+// every store location; its jobs can be tracked with GetJobs. It is returned along with an error if any location
+// failed; use its Remaining method to retry just those stores. opts may be nil.
+func (c *Client) RemoveCertificateFromStores(config *RemoveCertificateFromStore, opts *BulkStoreOptions) (*StoreRemovalReport, error) {
+	return c.RemoveCertificateFromStoresContext(context.Background(), config, opts)
+}
 
+// removalBatches splits the locations the certificate is removed from into batches of at most maxCount locations
+// that keep each request within the client's request size limit.
+func (c *Client) removalBatches(config *RemoveCertificateFromStore, stores []CertificateStore, maxCount int) ([][2]int, error) {
+	base := removalRequest(config, nil)
+	full := removalRequest(config, stores)
+	batches, err := payloadBatches(len(stores), maxCount, c.requestLimit(), jsonSize(&base), func(i int) int {
+		return jsonSize(&full.CertificateStores[i])
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to remove certificate from certificate stores: %w", err)
+	}
+	return batches, nil
+}
+
+// removeCertificateLocations schedules the removal of the certificate from stores, whose aliases must already be
+// resolved, using the schedule and collection of config.
+func (c *Client) removeCertificateLocations(ctx context.Context, config *RemoveCertificateFromStore, stores []CertificateStore) ([]string, *http.Response, error) {
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
+	newReq := removalRequest(config, stores)
+	return apiClient.CertificateStoreApi.CertificateStoreRemoveCertificate(ctx).XKeyfactorRequestedWith(xKeyfactorRequestedWith).RemovalRequest(newReq).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+}
+
+// removalRequest returns the request that removes the certificate of config from stores.
+func removalRequest(config *RemoveCertificateFromStore, stores []CertificateStore) keyfactor.KeyfactorApiModelsCertificateStoresRemoveCertificateRequest {
 	newCollectionId := int32(config.CollectionId)
 	var newCertStoresList []keyfactor.ModelsCertificateLocationSpecifier
-	for _, cert := range *config.CertificateStores {
+	for _, cert := range stores {
+		cert := cert
 		var newCert = keyfactor.ModelsCertificateLocationSpecifier{
 			Alias:              &cert.Alias,
 			CertificateStoreId: &cert.CertificateStoreId,
 			JobFields:          nil,
 		}
+		if len(cert.EntryParameters) > 0 {
+			newCert.AdditionalProperties = map[string]interface{}{"JobFields": cert.EntryParameters}
+		}
 		newCertStoresList = append(newCertStoresList, newCert)
 	}
 
 	jsonInvSched, _ := json.Marshal(config.InventorySchedule)
 	var newSchedule keyfactor.KeyfactorCommonSchedulingKeyfactorSchedule
-	json.Unmarshal(jsonInvSched, newSchedule)
-	var newReq = keyfactor.KeyfactorApiModelsCertificateStoresRemoveCertificateRequest{
+	json.Unmarshal(jsonInvSched, &newSchedule)
+	return keyfactor.KeyfactorApiModelsCertificateStoresRemoveCertificateRequest{
 		CertificateStores: newCertStoresList,
 		Schedule:          newSchedule,
 		CollectionId:      &newCollectionId,
 	}
+}
 
-	resp, _, err := apiClient.CertificateStoreApi.CertificateStoreRemoveCertificate(context.Background()).XKeyfactorRequestedWith(xKeyfactorRequestedWith).RemovalRequest(newReq).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+// resolveStoreAliases returns a store in config with its alias filled in. The store's own alias takes precedence, then
+// config.Alias; otherwise the store's inventory is searched for config.CertificateId or config.Thumbprint, and one
+// entry is returned for every alias the certificate is stored under.
+func (c *Client) resolveStoreAliases(ctx context.Context, config *RemoveCertificateFromStore, store CertificateStore) ([]CertificateStore, error) {
+	if store.Alias == "" {
+		store.Alias = config.Alias
+	}
+	if store.Alias != "" {
+		return []CertificateStore{store}, nil
+	}
+	if config.CertificateId <= 0 && config.Thumbprint == "" {
+		return nil, fmt.Errorf("an alias, certificate id, or thumbprint is required to remove a certificate from certificate store %s", store.CertificateStoreId)
+	}
 
+	inventory, err := c.GetCertStoreInventoryContext(ctx, store.CertificateStoreId)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("unable to get inventory of certificate store %s: %w", store.CertificateStoreId, err)
+	}
+	var aliases []string
+	for _, item := range *inventory {
+		for _, cert := range item.Certificates {
+			if (config.CertificateId > 0 && cert.Id == config.CertificateId) ||
+				ThumbprintsEqual(cert.Thumbprint, config.Thumbprint) {
+				aliases = append(aliases, item.Name)
+				break
+			}
+		}
+	}
+	if len(aliases) == 0 {
+		return nil, fmt.Errorf("certificate (id: %d, thumbprint: %s) was not found in the inventory of certificate store %s", config.CertificateId, config.Thumbprint, store.CertificateStoreId)
 	}
+	var stores []CertificateStore
+	for _, alias := range aliases {
+		c.debugf("Resolved alias %q in certificate store %s", alias, store.CertificateStoreId)
+		entry := store
+		entry.Alias = alias
+		stores = append(stores, entry)
+	}
+	return stores, nil
+}
 
-	return resp, nil
+// EntryParameters returns the binding as the entry parameters expected by IIS certificate stores.
+func (b IISBinding) EntryParameters() map[string]interface{} {
+	params := map[string]interface{}{
+		"SiteName":  b.SiteName,
+		"IPAddress": b.IPAddress,
+		"Port":      strconv.Itoa(b.Port),
+		"HostName":  b.HostName,
+		"SniFlag":   strconv.Itoa(b.SniFlag),
+		"Protocol":  b.Protocol,
+	}
+	if b.IPAddress == "" {
+		params["IPAddress"] = "*"
+	}
+	if b.Port == 0 {
+		params["Port"] = "443"
+	}
+	if b.Protocol == "" {
+		params["Protocol"] = "https"
+	}
+	return params
 }
 
 func (c *Client) GetCertStoreInventory(storeId string) (*[]CertStoreInventory, error) {
+	return c.GetCertStoreInventoryContext(context.Background(), storeId)
+}
 
-	xKeyfactorRequestedWith := "APIClient"
-	xKeyfactorApiVersion := "1"
+// GetCertStoreInventoryContext is like GetCertStoreInventory but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) GetCertStoreInventoryContext(ctx context.Context, storeId string) (*[]CertStoreInventory, error) {
+	if err := validateGUID("certificate store", storeId); err != nil {
+		return nil, err
+	}
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
 
-	resp, _, err := apiClient.CertificateStoreApi.CertificateStoreGetCertificateStoreInventory(context.Background(), storeId).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	keyfactorAPIStruct := &request{
+		Method:   "GET",
+		Endpoint: fmt.Sprintf("CertificateStores/%s/Inventory", storeId),
+		Headers:  headers,
+		Context:  ctx,
+	}
 
+	resp, err := c.sendRequest(keyfactorAPIStruct)
 	if err != nil {
 		return nil, err
 	}
 
-	var newResp []CertStoreInventory
+	// Numbers are decoded as json.Number so that IDs and numeric parameters keep their precision.
+	var jsonResp []certStoreInventoryJSON
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&jsonResp); err != nil {
+		return nil, err
+	}
 
-	if len(resp) == 0 {
-		newResp = []CertStoreInventory{}
-	} else {
-		for _, certInv := range resp {
-			var newInvCertList []InventoriedCertificate
-			var newParams = make(map[string]interface{})
-			for _, param := range certInv.Parameters {
-				for key, value := range param {
-					newParams[key] = value
+	newResp := make([]CertStoreInventory, 0, len(jsonResp))
+	for _, certInv := range jsonResp {
+		var newInvCertList []InventoriedCertificate
+		var thumbprints = make(map[string]bool)
+		var serials = make(map[string]bool)
+		var newParams = make(map[string]interface{})
+		for key, value := range certInv.Parameters {
+			// Some Command releases nest the parameters one level deeper.
+			if nested, ok := value.(map[string]interface{}); ok {
+				for k, v := range nested {
+					newParams[k] = v
 				}
+				continue
 			}
-			for _, storedCert := range certInv.Certificates {
-				var newInvCert = InventoriedCertificate{
-					Id:                       int(*storedCert.Id),
-					IssuedDN:                 *storedCert.IssuedDN.Get(),
-					SerialNumber:             *storedCert.SerialNumber,
-					NotBefore:                storedCert.NotBefore.String(),
-					NotAfter:                 storedCert.NotAfter.String(),
-					SigningAlgorithm:         *storedCert.SigningAlgorithm,
-					IssuerDN:                 *storedCert.IssuerDN.Get(),
-					Thumbprint:               *storedCert.Thumbprint,
-					CertStoreInventoryItemId: int(*storedCert.CertStoreInventoryItemId),
-				}
-				newInvCertList = append(newInvCertList, newInvCert)
+			newParams[key] = value
+		}
+		for _, storedCert := range certInv.Certificates {
+			id, err := jsonInt(storedCert.Id, "certificate Id")
+			if err != nil {
+				return nil, err
+			}
+			itemId, err := jsonInt(storedCert.CertStoreInventoryItemId, "CertStoreInventoryItemId")
+			if err != nil {
+				return nil, err
 			}
-			var newInv = CertStoreInventory{
-				CertStoreInventoryItemId: 0,
-				Name:                     *certInv.Name,
-				Certificates:             newInvCertList,
-				Thumbprints:              nil,
-				Serials:                  nil,
-				Ids:                      nil,
-				Properties:               nil,
-				Parameters:               newParams,
+			var newInvCert = InventoriedCertificate{
+				Id:                       id,
+				IssuedDN:                 storedCert.IssuedDN,
+				SerialNumber:             storedCert.SerialNumber,
+				NotBefore:                storedCert.NotBefore,
+				NotAfter:                 storedCert.NotAfter,
+				SigningAlgorithm:         storedCert.SigningAlgorithm,
+				IssuerDN:                 storedCert.IssuerDN,
+				Thumbprint:               storedCert.Thumbprint,
+				CertStoreInventoryItemId: itemId,
 			}
-			newResp = append(newResp, newInv)
+			newInvCertList = append(newInvCertList, newInvCert)
+			thumbprints[NormalizeThumbprint(newInvCert.Thumbprint)] = true
+			serials[normalizeSerial(newInvCert.SerialNumber)] = true
+		}
+		var newInv = CertStoreInventory{
+			CertStoreInventoryItemId: 0,
+			Name:                     certInv.Name,
+			Certificates:             newInvCertList,
+			Properties:               nil,
+			Parameters:               newParams,
+			thumbprints:              thumbprints,
+			serials:                  serials,
 		}
+		newResp = append(newResp, newInv)
 	}
 
-	//jsonResp.Properties = unmarshalPropertiesString(jsonResp.PropertiesString)
 	return &newResp, nil
 }
 
+// jsonInt converts an ID decoded as a json.Number to an int, returning an error naming field if it is not an integer
+// or does not fit in one. An absent ID is 0.
+func jsonInt(n json.Number, field string) (int, error) {
+	if n == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseInt(string(n), 10, 64)
+	if err != nil || int64(int(v)) != v {
+		return 0, fmt.Errorf("invalid %s %s in Keyfactor response", field, n)
+	}
+	return int(v), nil
+}
+
+// Equal reports whether two inventory schedules are equivalent. An Immediate value of false is treated the same as an
+// unset one.
+func (s InventorySchedule) Equal(o InventorySchedule) bool {
+	if s.Immediate != nil && !*s.Immediate {
+		s.Immediate = nil
+	}
+	if o.Immediate != nil && !*o.Immediate {
+		o.Immediate = nil
+	}
+	return reflect.DeepEqual(s, o)
+}
+
 // unmarshalPropertiesString unmarshalls a JSON string and serializes it into an array of StringTuple.
 func unmarshalPropertiesString(properties string) map[string]interface{} {
 	if properties != "" {
@@ -466,7 +858,11 @@ func unmarshalPropertiesString(properties string) map[string]interface{} {
 		}
 		// Then, iterate through each key:value pair and serialize into map[string]string
 		newMap := make(map[string]interface{})
-		for key, value := range tempInterface.(map[string]interface{}) {
+		properties, ok := tempInterface.(map[string]interface{})
+		if !ok {
+			return newMap
+		}
+		for key, value := range properties {
 			newMap[key] = value
 		}
 		return newMap
@@ -475,6 +871,26 @@ func unmarshalPropertiesString(properties string) map[string]interface{} {
 	return make(map[string]interface{})
 }
 
+// checkStoreProperties returns an error if props sets a property the store type does not define, or leaves out a
+// property it requires that has no default value.
+func checkStoreProperties(storeType *CertificateStoreType, props map[string]interface{}) error {
+	defined := storeType.propertyDefinitions()
+	for name := range props {
+		if _, ok := defined[name]; !ok {
+			return fmt.Errorf("certificate store type %s does not define property %s; check the version of its orchestrator extension", storeType.ShortName, name)
+		}
+	}
+	for name, def := range defined {
+		if value, ok := props[name]; ok && value != "" {
+			continue
+		}
+		if def.Required && def.Type != "Bool" && isEmptyDefault(def.DefaultValue) {
+			return fmt.Errorf("certificate store type %s requires property %s (%s)", storeType.ShortName, name, def.DisplayName)
+		}
+	}
+	return nil
+}
+
 func validateCreateStoreArgs(ca *CreateStoreFctArgs) error {
 	if ca.ClientMachine == "" {
 		return errors.New("client machine is required for creation of new certificate store")
@@ -489,6 +905,23 @@ func validateCreateStoreArgs(ca *CreateStoreFctArgs) error {
 	return nil
 }
 
+func validateStoreSecret(secret *StoreSecret) error {
+	if secret == nil {
+		return errors.New("a secret is required to set a certificate store password")
+	}
+	if secret.Provider > 0 {
+		if secret.SecretValue != "" {
+			return errors.New("a store password secret must use either a secret value or a PAM provider, not both")
+		}
+		if len(secret.Parameters) == 0 {
+			return fmt.Errorf("parameters are required to look up the store password in PAM provider %d", secret.Provider)
+		}
+	} else if secret.SecretValue == "" {
+		return errors.New("a secret value or PAM provider is required to set a certificate store password")
+	}
+	return nil
+}
+
 func validateUpdateStoreArgs(ca *UpdateStoreFctArgs) error {
 	if ca.ClientMachine == "" {
 		return errors.New("client machine is required for creation of new certificate store")
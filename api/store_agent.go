@@ -0,0 +1,232 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrStoreTypeNotSupported is returned when an orchestrator has not registered the capability for a certificate store
+// type, and so cannot run jobs against stores of that type.
+var ErrStoreTypeNotSupported = errors.New("orchestrator does not support certificate store type")
+
+// SupportsStoreType reports whether the agent registered a capability for the store type with the given capability
+// name, e.g. "IIS" for a "CertStores.IIS.Inventory" registration.
+func (a *Agent) SupportsStoreType(capability string) bool {
+	prefix := "certstores." + strings.ToLower(capability)
+	for _, c := range a.Capabilities {
+		c = strings.ToLower(c)
+		if c == prefix || strings.HasPrefix(c, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAgentCapabilities takes arguments for an orchestrator agent ID to facilitate a call to Keyfactor that retrieves
+// the capabilities the orchestrator registered with, e.g. "CertStores.IIS.Inventory".
+func (c *Client) GetAgentCapabilities(agentId string) ([]string, error) {
+	agent, err := c.getAgent(agentId)
+	if err != nil {
+		return nil, err
+	}
+	return agent.Capabilities, nil
+}
+
+// ValidateAgentSupportsStoreType takes arguments for an orchestrator agent ID and a certificate store type short name
+// to facilitate calls to Keyfactor that check the orchestrator registered the capability of the store type. An error
+// wrapping ErrStoreTypeNotSupported is returned if it did not, as Keyfactor would otherwise accept stores of that type
+// on the orchestrator that no job could ever run against.
+func (c *Client) ValidateAgentSupportsStoreType(agentId, storeTypeShortName string) error {
+	if storeTypeShortName == "" {
+		return errors.New("certificate store type short name is required to validate orchestrator capabilities")
+	}
+	storeType, err := c.GetCertificateStoreTypeByName(storeTypeShortName)
+	if err != nil {
+		return fmt.Errorf("unable to get certificate store type %s: %w", storeTypeShortName, err)
+	}
+	return c.validateAgentCapability(agentId, storeType.Capability)
+}
+
+// validateAgentSupportsStoreTypeId is like ValidateAgentSupportsStoreType but looks the store type up by its ID.
+func (c *Client) validateAgentSupportsStoreTypeId(agentId string, storeTypeId int) error {
+	storeType, err := c.cachedStoreTypeById(context.Background(), storeTypeId)
+	if err != nil {
+		return err
+	}
+	return c.validateAgentCapability(agentId, storeType.Capability)
+}
+
+func (c *Client) validateAgentCapability(agentId, capability string) error {
+	agent, err := c.getAgent(agentId)
+	if err != nil {
+		return err
+	}
+	if !agent.SupportsStoreType(capability) {
+		return fmt.Errorf("%w: orchestrator %s (%s) has no %s capability", ErrStoreTypeNotSupported, agent.ClientMachine, agentId, capability)
+	}
+	return nil
+}
+
+// getAgent returns the orchestrator with the given ID, or an error if there is none.
+func (c *Client) getAgent(agentId string) (*Agent, error) {
+	if err := validateGUID("orchestrator agent", agentId); err != nil {
+		return nil, err
+	}
+	agents, err := c.GetAgent(agentId)
+	if err != nil {
+		return nil, err
+	}
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("orchestrator agent %s not found", agentId)
+	}
+	return &agents[0], nil
+}
+
+// ReassignStoreAgent takes arguments for a list of certificate store IDs and an orchestrator agent ID to facilitate
+// calls to Keyfactor that move every store to the new agent, e.g. when an orchestrator host is replaced. All stores
+// are looked up and the agent is checked for the capability of each store type before any store is changed; if the
+// agent cannot service one of them, nothing is updated. Stores already assigned to the agent are left untouched. The
+// IDs of the stores that were updated are returned, along with an error naming any store that failed to update.
+func (c *Client) ReassignStoreAgent(storeIds []string, newAgentId string) ([]string, error) {
+	c.infof("Reassigning %d certificate stores to orchestrator %s", len(storeIds), newAgentId)
+
+	if len(storeIds) == 0 {
+		return nil, errors.New("at least one certificate store id is required")
+	}
+	if newAgentId == "" {
+		return nil, errors.New("orchestrator agent id is required to reassign certificate stores")
+	}
+
+	agent, err := c.getAgent(newAgentId)
+	if err != nil {
+		return nil, err
+	}
+
+	stores := make([]*GetCertificateStoreResponse, 0, len(storeIds))
+	capabilities := map[int]string{}
+	var unsupported []string
+	for _, id := range storeIds {
+		store, err := c.GetCertificateStoreByID(id)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get certificate store %s: %w", id, err)
+		}
+		stores = append(stores, store)
+
+		capability, ok := capabilities[store.CertStoreType]
+		if !ok {
+			storeType, err := c.GetCertificateStoreTypeById(store.CertStoreType)
+			if err != nil {
+				return nil, fmt.Errorf("unable to get certificate store type %d: %w", store.CertStoreType, err)
+			}
+			capability = storeType.Capability
+			capabilities[store.CertStoreType] = capability
+		}
+		if !agent.SupportsStoreType(capability) {
+			unsupported = append(unsupported, fmt.Sprintf("%s (%s)", id, capability))
+		}
+	}
+	if len(unsupported) > 0 {
+		return nil, fmt.Errorf("orchestrator %s (%s) does not support the store type of certificate stores: %s", agent.ClientMachine, newAgentId, strings.Join(unsupported, ", "))
+	}
+
+	var updated, failed []string
+	for _, store := range stores {
+		if strings.EqualFold(store.AgentId, newAgentId) {
+			c.infof("Certificate store %s is already assigned to orchestrator %s", store.Id, newAgentId)
+			continue
+		}
+		if _, err := c.UpdateStore(reassignStoreArgs(store, newAgentId)); err != nil {
+			c.errorf("Unable to reassign certificate store %s: %s", store.Id, err)
+			failed = append(failed, fmt.Sprintf("%s (%s)", store.Id, err))
+			continue
+		}
+		updated = append(updated, store.Id)
+	}
+	if len(failed) > 0 {
+		return updated, fmt.Errorf("unable to reassign certificate stores: %s", strings.Join(failed, "; "))
+	}
+	return updated, nil
+}
+
+// reassignStoreArgs builds the arguments to update store with everything but its agent unchanged.
+func reassignStoreArgs(store *GetCertificateStoreResponse, agentId string) *UpdateStoreFctArgs {
+	approved := store.Approved
+	createIfMissing := store.CreateIfMissing
+	agentAssigned := true
+	setNewPasswordAllowed := store.SetNewPasswordAllowed
+	schedule := store.InventorySchedule
+
+	args := &UpdateStoreFctArgs{
+		Id: store.Id,
+		CreateStoreFctArgs: CreateStoreFctArgs{
+			ClientMachine:         store.ClientMachine,
+			StorePath:             store.StorePath,
+			CertStoreType:         store.CertStoreType,
+			Approved:              &approved,
+			CreateIfMissing:       &createIfMissing,
+			PropertiesString:      store.PropertiesString,
+			AgentId:               agentId,
+			AgentAssigned:         &agentAssigned,
+			InventorySchedule:     &schedule,
+			SetNewPasswordAllowed: &setNewPasswordAllowed,
+		},
+	}
+	if store.ContainerId > 0 {
+		containerId := store.ContainerId
+		args.ContainerId = &containerId
+	}
+	return args
+}
+
+// StoreTypeAvailability reports which orchestrators can service a certificate store type.
+type StoreTypeAvailability struct {
+	StoreType CertificateStoreType
+	// Agents lists the IDs of the approved orchestrators that registered the store type's capability.
+	Agents []string
+}
+
+// Available reports whether at least one approved orchestrator can service the store type.
+func (a *StoreTypeAvailability) Available() bool {
+	return len(a.Agents) > 0
+}
+
+// GetStoreTypeAvailability takes no arguments and facilitates calls to Keyfactor that list the certificate store
+// types and the orchestrators, returning for each store type the approved orchestrators able to service it. It suits
+// pre-flight checks before provisioning stores, since Keyfactor accepts stores of a type no orchestrator can run
+// jobs against. Store types are sorted by ShortName.
+func (c *Client) GetStoreTypeAvailability() ([]StoreTypeAvailability, error) {
+	return c.GetStoreTypeAvailabilityContext(context.Background())
+}
+
+// GetStoreTypeAvailabilityContext is like GetStoreTypeAvailability but uses ctx for the requests, allowing it to be
+// cancelled.
+func (c *Client) GetStoreTypeAvailabilityContext(ctx context.Context) ([]StoreTypeAvailability, error) {
+	c.infof("Checking orchestrator availability of certificate store types")
+
+	storeTypes, err := c.ListCertificateStoreTypesContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list certificate store types: %w", err)
+	}
+	agents, err := c.GetAgentListContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list orchestrators: %w", err)
+	}
+
+	availability := make([]StoreTypeAvailability, 0, len(*storeTypes))
+	for _, storeType := range *storeTypes {
+		a := StoreTypeAvailability{StoreType: storeType}
+		for i := range agents {
+			if agents[i].Status == AgentStatusApproved && agents[i].SupportsStoreType(storeType.Capability) {
+				a.Agents = append(a.Agents, agents[i].AgentId)
+			}
+		}
+		availability = append(availability, a)
+	}
+	sort.Slice(availability, func(i, j int) bool {
+		return availability[i].StoreType.ShortName < availability[j].StoreType.ShortName
+	})
+	return availability, nil
+}
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClient_ReassignStoreAgent(t *testing.T) {
+	stores := map[string]GetCertificateStoreResponse{
+		"5f1e0a2c-0001-4000-8000-000000000001": {Id: "5f1e0a2c-0001-4000-8000-000000000001", ClientMachine: "web01", StorePath: "My", CertStoreType: 2, AgentId: "a9c3d7e4-0001-4000-8000-000000000001", ContainerId: 4, PropertiesString: `{"spnwithport":{"value":"false"}}`},
+		"5f1e0a2c-0002-4000-8000-000000000002": {Id: "5f1e0a2c-0002-4000-8000-000000000002", ClientMachine: "web02", StorePath: "My", CertStoreType: 2, AgentId: "a9c3d7e4-0002-4000-8000-000000000002"},
+		"5f1e0a2c-0003-4000-8000-000000000003": {Id: "5f1e0a2c-0003-4000-8000-000000000003", ClientMachine: "app01", StorePath: "/etc/ssl/app.pem", CertStoreType: 5, AgentId: "a9c3d7e4-0001-4000-8000-000000000001"},
+	}
+	var updates []UpdateStoreFctArgs
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/KeyfactorAPI/Agents/a9c3d7e4-0002-4000-8000-000000000002":
+			w.Write([]byte(`{"AgentId": "a9c3d7e4-0002-4000-8000-000000000002", "ClientMachine": "orch02", "Capabilities": ["CertStores.IIS.Inventory", "CertStores.IIS.Management"]}`))
+		case r.Method == "GET" && r.URL.Path == "/KeyfactorAPI/CertificateStoreTypes/2":
+			w.Write([]byte(`{"StoreType": 2, "ShortName": "IIS", "Capability": "IIS"}`))
+		case r.Method == "GET" && r.URL.Path == "/KeyfactorAPI/CertificateStoreTypes/5":
+			w.Write([]byte(`{"StoreType": 5, "ShortName": "PEM", "Capability": "PEM"}`))
+		case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/KeyfactorAPI/CertificateStores/"):
+			store, ok := stores[strings.TrimPrefix(r.URL.Path, "/KeyfactorAPI/CertificateStores/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(store)
+		case strings.EqualFold(r.Method, "PUT") && r.URL.Path == "/KeyfactorAPI/CertificateStores":
+			var update UpdateStoreFctArgs
+			json.NewDecoder(r.Body).Decode(&update)
+			updates = append(updates, update)
+			json.NewEncoder(w).Encode(UpdateStoreResponse{})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	tests := []struct {
+		name        string
+		storeIds    []string
+		agentId     string
+		wantUpdated []string
+		wantErr     bool
+	}{
+		{name: "Reassigned", storeIds: []string{"5f1e0a2c-0001-4000-8000-000000000001", "5f1e0a2c-0002-4000-8000-000000000002"}, agentId: "a9c3d7e4-0002-4000-8000-000000000002", wantUpdated: []string{"5f1e0a2c-0001-4000-8000-000000000001"}},
+		{name: "UnsupportedStoreType", storeIds: []string{"5f1e0a2c-0001-4000-8000-000000000001", "5f1e0a2c-0003-4000-8000-000000000003"}, agentId: "a9c3d7e4-0002-4000-8000-000000000002", wantErr: true},
+		{name: "UnknownStore", storeIds: []string{"5f1e0a2c-00ff-4000-8000-0000000000ff"}, agentId: "a9c3d7e4-0002-4000-8000-000000000002", wantErr: true},
+		{name: "NoAgent", storeIds: []string{"5f1e0a2c-0001-4000-8000-000000000001"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updates = nil
+			got, err := c.ReassignStoreAgent(tt.storeIds, tt.agentId)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReassignStoreAgent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(updates) > 0 {
+					t.Errorf("ReassignStoreAgent() updated stores %+v after failing validation", updates)
+				}
+				return
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantUpdated, ",") {
+				t.Errorf("ReassignStoreAgent() = %v, want %v", got, tt.wantUpdated)
+			}
+			if len(updates) != 1 {
+				t.Fatalf("ReassignStoreAgent() sent %d updates, want 1", len(updates))
+			}
+			u := updates[0]
+			if u.Id != "5f1e0a2c-0001-4000-8000-000000000001" || u.AgentId != "a9c3d7e4-0002-4000-8000-000000000002" || u.ClientMachine != "web01" || u.ContainerId == nil || *u.ContainerId != 4 {
+				t.Errorf("ReassignStoreAgent() update = %+v", u)
+			}
+			if u.PropertiesString != stores["5f1e0a2c-0001-4000-8000-000000000001"].PropertiesString {
+				t.Errorf("ReassignStoreAgent() properties = %s, want %s", u.PropertiesString, stores["5f1e0a2c-0001-4000-8000-000000000001"].PropertiesString)
+			}
+		})
+	}
+}
+
+func TestClient_ValidateAgentSupportsStoreType(t *testing.T) {
+	var created int
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/KeyfactorAPI/Agents/a9c3d7e4-0001-4000-8000-000000000001":
+			w.Write([]byte(`{"AgentId": "a9c3d7e4-0001-4000-8000-000000000001", "ClientMachine": "orch01", "Capabilities": ["CertStores.IIS.Inventory", "CertStores.IIS.Management"]}`))
+		case r.Method == "GET" && r.URL.Path == "/KeyfactorAPI/CertificateStoreTypes/Name/IIS":
+			w.Write([]byte(`[{"StoreType": 2, "ShortName": "IIS", "Capability": "IIS"}]`))
+		case r.Method == "GET" && r.URL.Path == "/KeyfactorAPI/CertificateStoreTypes/Name/PEM":
+			w.Write([]byte(`[{"StoreType": 5, "ShortName": "PEM", "Capability": "PEM"}]`))
+		case r.Method == "GET" && r.URL.Path == "/KeyfactorAPI/CertificateStoreTypes/2":
+			w.Write([]byte(`{"StoreType": 2, "ShortName": "IIS", "Capability": "IIS"}`))
+		case r.Method == "GET" && r.URL.Path == "/KeyfactorAPI/CertificateStoreTypes/5":
+			w.Write([]byte(`{"StoreType": 5, "ShortName": "PEM", "Capability": "PEM"}`))
+		case r.Method == "POST" && r.URL.Path == "/KeyfactorAPI/CertificateStores":
+			created++
+			w.Write([]byte(`{"Id": "new-store"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	t.Run("Capabilities", func(t *testing.T) {
+		got, err := c.GetAgentCapabilities("a9c3d7e4-0001-4000-8000-000000000001")
+		if err != nil {
+			t.Fatalf("GetAgentCapabilities() error = %v", err)
+		}
+		if strings.Join(got, ",") != "CertStores.IIS.Inventory,CertStores.IIS.Management" {
+			t.Errorf("GetAgentCapabilities() = %v", got)
+		}
+	})
+
+	tests := []struct {
+		name      string
+		agentId   string
+		shortName string
+		wantErr   bool
+		wantUnsup bool
+	}{
+		{name: "Supported", agentId: "a9c3d7e4-0001-4000-8000-000000000001", shortName: "IIS"},
+		{name: "Unsupported", agentId: "a9c3d7e4-0001-4000-8000-000000000001", shortName: "PEM", wantErr: true, wantUnsup: true},
+		{name: "UnknownAgent", agentId: "a9c3d7e4-0009-4000-8000-000000000009", shortName: "IIS", wantErr: true},
+		{name: "NoShortName", agentId: "a9c3d7e4-0001-4000-8000-000000000001", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.ValidateAgentSupportsStoreType(tt.agentId, tt.shortName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateAgentSupportsStoreType() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrStoreTypeNotSupported) != tt.wantUnsup {
+				t.Errorf("ValidateAgentSupportsStoreType() error = %v, want ErrStoreTypeNotSupported %v", err, tt.wantUnsup)
+			}
+		})
+	}
+
+	createTests := []struct {
+		name          string
+		storeType     int
+		validateAgent bool
+		wantErr       bool
+	}{
+		{name: "CreateValidated", storeType: 2, validateAgent: true},
+		{name: "CreateRejected", storeType: 5, validateAgent: true, wantErr: true},
+		{name: "CreateUnvalidated", storeType: 5},
+	}
+	for _, tt := range createTests {
+		t.Run(tt.name, func(t *testing.T) {
+			created = 0
+			_, err := c.CreateStore(&CreateStoreFctArgs{
+				ClientMachine: "web01",
+				StorePath:     "My",
+				CertStoreType: tt.storeType,
+				AgentId:       "a9c3d7e4-0001-4000-8000-000000000001",
+				ValidateAgent: tt.validateAgent,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateStore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if wantCreated := !tt.wantErr; (created == 1) != wantCreated {
+				t.Errorf("CreateStore() sent %d create requests", created)
+			}
+		})
+	}
+}
+
+func TestClient_GetStoreTypeAvailability(t *testing.T) {
+	agent := func(id string, status int, capabilities ...string) map[string]interface{} {
+		return map[string]interface{}{
+			"AgentId": id, "ClientMachine": "orch-" + id[len(id)-1:], "Username": `KEYFACTOR\orch`, "AgentPlatform": 2,
+			"Status": status, "Version": "10.4.0", "Thumbprint": "AA", "LegacyThumbprint": "", "Capabilities": capabilities,
+		}
+	}
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/KeyfactorAPI/CertificateStoreTypes":
+			w.Write([]byte(`[
+				{"StoreType": 5, "Name": "PEM File", "ShortName": "PEM", "Capability": "PEM"},
+				{"StoreType": 2, "Name": "IIS Personal", "ShortName": "IIS", "Capability": "IIS"},
+				{"StoreType": 106, "Name": "Azure Key Vault", "ShortName": "AKV", "Capability": "AzureKeyVault"}
+			]`))
+		case "/KeyfactorAPI/Agents":
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				agent("a9c3d7e4-0001-4000-8000-000000000001", AgentStatusApproved, "CertStores.IIS.Inventory", "CertStores.IIS.Management"),
+				agent("a9c3d7e4-0002-4000-8000-000000000002", AgentStatusApproved, "CertStores.IIS.Inventory"),
+				agent("a9c3d7e4-0003-4000-8000-000000000003", AgentStatusDisapproved, "CertStores.PEM.Inventory"),
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	got, err := c.GetStoreTypeAvailability()
+	if err != nil {
+		t.Fatalf("GetStoreTypeAvailability() error = %v", err)
+	}
+	want := map[string][]string{
+		"AKV": nil,
+		"IIS": {"a9c3d7e4-0001-4000-8000-000000000001", "a9c3d7e4-0002-4000-8000-000000000002"},
+		"PEM": nil,
+	}
+	if len(got) != len(want) || got[0].StoreType.ShortName != "AKV" {
+		t.Fatalf("GetStoreTypeAvailability() = %+v", got)
+	}
+	for _, a := range got {
+		if !reflect.DeepEqual(a.Agents, want[a.StoreType.ShortName]) || a.Available() != (want[a.StoreType.ShortName] != nil) {
+			t.Errorf("GetStoreTypeAvailability() %s agents = %v, want %v", a.StoreType.ShortName, a.Agents, want[a.StoreType.ShortName])
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrAliasRequired is returned when a certificate is added to or reenrolled in a certificate store without an
+	// alias, and the store's type requires one.
+	ErrAliasRequired = errors.New("certificate store type requires a custom alias")
+	// ErrAliasForbidden is returned when a certificate is added to or reenrolled in a certificate store with an alias,
+	// and the store's type does not allow custom aliases.
+	ErrAliasForbidden = errors.New("certificate store type does not allow a custom alias")
+)
+
+// ValidateAlias checks the alias of a certificate added to or reenrolled in a store of the type against the type's
+// CustomAliasAllowed setting. An error wrapping ErrAliasRequired is returned if the type requires an alias and alias is
+// empty, and one wrapping ErrAliasForbidden if the type forbids custom aliases and alias is not empty.
+func (t *CertificateStoreType) ValidateAlias(alias string) error {
+	switch {
+	case strings.EqualFold(t.CustomAliasAllowed, CustomAliasRequired) && strings.TrimSpace(alias) == "":
+		return fmt.Errorf("%w: stores of type %s need an alias for each certificate", ErrAliasRequired, t.ShortName)
+	case strings.EqualFold(t.CustomAliasAllowed, CustomAliasForbidden) && alias != "":
+		return fmt.Errorf("%w: the orchestrator names the certificates in stores of type %s, so alias %q cannot be used", ErrAliasForbidden, t.ShortName, alias)
+	}
+	return nil
+}
+
+// CustomAlias returns the CustomAliasAllowed setting of the store's type, which Keyfactor reports in the reenrollment
+// status of a store as a number, as CustomAliasForbidden, CustomAliasOptional, or CustomAliasRequired. It returns ""
+// for numbers it does not know.
+func (r ReEnrollmnentConfig) CustomAlias() string {
+	switch r.CustomAliasAllowed {
+	case 0:
+		return CustomAliasForbidden
+	case 1:
+		return CustomAliasOptional
+	case 2:
+		return CustomAliasRequired
+	default:
+		return ""
+	}
+}
+
+// StoreAliasError is returned by AddCertificateToStores with ValidateAliases when the aliases of some certificate
+// stores do not meet the CustomAliasAllowed setting of their store types, or the stores could not be looked up. It maps
+// the ID of each such store to its error.
+type StoreAliasError map[string]error
+
+func (e StoreAliasError) Error() string {
+	return storeErrorsMessage("given the certificate with their aliases", e)
+}
+
+// Is reports whether the error of any of the stores matches target, so that errors.Is matches them.
+func (e StoreAliasError) Is(target error) bool {
+	return storeErrorsIs(e, target)
+}
+
+// As finds the first error of the stores, by store ID, that matches target, so that errors.As matches them.
+func (e StoreAliasError) As(target interface{}) bool {
+	return storeErrorsAs(e, target)
+}
+
+// Unwrap returns the errors of the stores, for Go 1.20 and later.
+func (e StoreAliasError) Unwrap() []error {
+	return storeErrors(e)
+}
+
+// validateStoreAliases checks the alias of each store against the CustomAliasAllowed setting of the store's type,
+// returning a StoreAliasError for the stores whose alias does not meet it. Store types are looked up through the store
+// type cache, so stores of one type cost one lookup.
+func (c *Client) validateStoreAliases(ctx context.Context, stores []CertificateStore) error {
+	ids := make([]string, 0, len(stores))
+	for _, store := range stores {
+		ids = append(ids, store.CertificateStoreId)
+	}
+	fetched, err := c.GetCertificateStoresByIDsContext(ctx, ids, 0)
+	failed := StoreAliasError{}
+	var fetchErr StoreFetchError
+	if errors.As(err, &fetchErr) {
+		for id, err := range fetchErr {
+			failed[id] = err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	for _, store := range stores {
+		found, ok := fetched[store.CertificateStoreId]
+		if !ok {
+			continue
+		}
+		storeType, err := c.cachedStoreTypeById(ctx, found.CertStoreType)
+		if err == nil {
+			err = storeType.ValidateAlias(store.Alias)
+		}
+		if err != nil {
+			failed[store.CertificateStoreId] = err
+		}
+	}
+	if len(failed) > 0 {
+		c.errorf("%d of %d certificate stores cannot be given the certificate with their aliases", len(failed), len(ids))
+		return failed
+	}
+	return nil
+}
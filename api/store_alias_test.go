@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	aliasRequiredStore  = "1d5a3b0e-6f4c-4b2a-9e8d-7c6b5a4f3e21"
+	aliasOptionalStore  = "2e6b4c1f-7a5d-4c3b-8f9e-8d7c6b5a4f32"
+	aliasForbiddenStore = "3f7c5d2a-8b6e-4d4c-9a0f-9e8d7c6b5a43"
+)
+
+// newAliasTestServer returns a client of a server with a store of each CustomAliasAllowed setting, recording the
+// requests that schedule jobs and the store type lookups.
+func newAliasTestServer(t *testing.T) (c *Client, jobs func() []string, typeLookups func() int) {
+	var (
+		mu        sync.Mutex
+		scheduled []string
+		lookups   int
+	)
+	storeTypes := map[string]int{aliasRequiredStore: 105, aliasOptionalStore: 106, aliasForbiddenStore: 107}
+	settings := map[int]string{105: CustomAliasRequired, 106: CustomAliasOptional, 107: CustomAliasForbidden}
+	c = newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		id := strings.TrimPrefix(r.URL.Path, "/KeyfactorAPI/CertificateStores/")
+		typeId, isType := pathId(r.URL.Path, "/KeyfactorAPI/CertificateStoreTypes/", "")
+		switch {
+		case r.Method == "GET" && storeTypes[id] != 0:
+			fmt.Fprintf(w, `{"Id": %q, "CertStoreType": %d, "AgentId": "6c1f0a2b-3d4e-4f5a-8b9c-0d1e2f3a4b5c"}`, id, storeTypes[id])
+		case r.Method == "GET" && isType:
+			lookups++
+			fmt.Fprintf(w, `{"StoreType": %d, "ShortName": "Type%d", "CustomAliasAllowed": %q, "SupportedOperations": {"Enrollment": true}}`,
+				typeId, typeId, settings[typeId])
+		case r.Method == "POST":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			scheduled = append(scheduled, fmt.Sprintf("%s %v", r.URL.Path, body["AgentGuid"]))
+			w.Write([]byte(`["job-1"]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	jobs = func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), scheduled...)
+	}
+	typeLookups = func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return lookups
+	}
+	return c, jobs, typeLookups
+}
+
+func TestClient_AddCertificateToStores_ValidateAliases(t *testing.T) {
+	c, jobs, typeLookups := newAliasTestServer(t)
+
+	stores := []CertificateStore{
+		{CertificateStoreId: aliasRequiredStore},
+		{CertificateStoreId: aliasOptionalStore},
+		{CertificateStoreId: aliasForbiddenStore, Alias: "web"},
+	}
+	_, err := c.AddCertificateToStores(&AddCertificateToStore{CertificateId: 1, CertificateStores: &stores, ValidateAliases: true})
+	var aliasErr StoreAliasError
+	if !errors.As(err, &aliasErr) || len(aliasErr) != 2 || len(jobs()) != 0 {
+		t.Fatalf("AddCertificateToStores() error = %v with jobs %q, want a StoreAliasError for 2 stores and no jobs", err, jobs())
+	}
+	if !errors.Is(aliasErr[aliasRequiredStore], ErrAliasRequired) || !errors.Is(aliasErr[aliasForbiddenStore], ErrAliasForbidden) {
+		t.Errorf("AddCertificateToStores() error = %v", err)
+	}
+	if !aliasErr.Is(ErrAliasRequired) || !aliasErr.Is(ErrAliasForbidden) {
+		t.Errorf("StoreAliasError does not match the errors of its stores: %v", err)
+	}
+
+	stores = []CertificateStore{
+		{CertificateStoreId: aliasRequiredStore, Alias: "web"},
+		{CertificateStoreId: aliasOptionalStore},
+		{CertificateStoreId: aliasForbiddenStore},
+	}
+	if _, err := c.AddCertificateToStores(&AddCertificateToStore{CertificateId: 1, CertificateStores: &stores, ValidateAliases: true}); err != nil {
+		t.Fatalf("AddCertificateToStores() error = %v", err)
+	}
+	if len(jobs()) != 1 {
+		t.Errorf("AddCertificateToStores() scheduled %q, want one request", jobs())
+	}
+	if typeLookups() != 3 {
+		t.Errorf("store types were looked up %d times, want each of the 3 once", typeLookups())
+	}
+}
+
+func TestClient_ReenrollCertificateStore(t *testing.T) {
+	c, jobs, _ := newAliasTestServer(t)
+
+	err := c.ReenrollCertificateStore(&ReenrollCertificateStoreArgs{CertificateStoreId: aliasRequiredStore, SubjectName: "CN=www.example.com"})
+	if !errors.Is(err, ErrAliasRequired) || len(jobs()) != 0 {
+		t.Errorf("ReenrollCertificateStore() without an alias error = %v with jobs %q, want ErrAliasRequired", err, jobs())
+	}
+	err = c.ReenrollCertificateStore(&ReenrollCertificateStoreArgs{CertificateStoreId: aliasForbiddenStore, SubjectName: "CN=www.example.com", Alias: "web"})
+	if !errors.Is(err, ErrAliasForbidden) || len(jobs()) != 0 {
+		t.Errorf("ReenrollCertificateStore() with an alias error = %v with jobs %q, want ErrAliasForbidden", err, jobs())
+	}
+
+	err = c.ReenrollCertificateStore(&ReenrollCertificateStoreArgs{CertificateStoreId: aliasRequiredStore, SubjectName: "CN=www.example.com", Alias: "web"})
+	if err != nil {
+		t.Fatalf("ReenrollCertificateStore() error = %v", err)
+	}
+	want := "/KeyfactorAPI/CertificateStores/Reenrollment 6c1f0a2b-3d4e-4f5a-8b9c-0d1e2f3a4b5c"
+	if got := jobs(); len(got) != 1 || got[0] != want {
+		t.Errorf("ReenrollCertificateStore() sent %q, want %q with the store's orchestrator", got, want)
+	}
+}
+
+func TestReEnrollmnentConfig_CustomAlias(t *testing.T) {
+	for setting, want := range map[int]string{0: CustomAliasForbidden, 1: CustomAliasOptional, 2: CustomAliasRequired, 7: ""} {
+		if got := (ReEnrollmnentConfig{CustomAliasAllowed: setting}).CustomAlias(); got != want {
+			t.Errorf("CustomAlias() of %d = %q, want %q", setting, got, want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/Keyfactor/keyfactor-go-client/query"
+)
+
+// SearchCertificateStoresByApproval is like SearchCertificateStores but returns only the stores whose Approved flag
+// matches approved. Stores created by discovery jobs are unapproved until an administrator approves them, so searching
+// for approved stores leaves them out of reconciliation.
+func (c *Client) SearchCertificateStoresByApproval(q string, approved bool, paging *Paging) ([]GetCertificateStoreResponse, error) {
+	return c.SearchCertificateStoresByApprovalContext(context.Background(), q, approved, paging)
+}
+
+// SearchCertificateStoresByApprovalContext is like SearchCertificateStoresByApproval but uses ctx for the request,
+// allowing it to be cancelled.
+func (c *Client) SearchCertificateStoresByApprovalContext(ctx context.Context, q string, approved bool, paging *Paging) ([]GetCertificateStoreResponse, error) {
+	approvedQuery, err := approvalQuery(q, approved)
+	if err != nil {
+		return nil, err
+	}
+	return c.SearchCertificateStoresContext(ctx, approvedQuery, paging)
+}
+
+// approvalQuery adds a filter on the Approved field to the query string q.
+func approvalQuery(q string, approved bool) (string, error) {
+	approval, err := query.Field("Approved").Eq(approved).Build()
+	if err != nil {
+		return "", err
+	}
+	if q == "" {
+		return approval, nil
+	}
+	return fmt.Sprintf("(%s) AND %s", q, approval), nil
+}
+
+// storeListParams returns a copy of the ListCertificateStores parameters in which an Approved filter given as the
+// string "true" or "false", or a slice holding one, is converted to a bool, since Keyfactor rejects a quoted value
+// for the field.
+func storeListParams(params map[string]interface{}) (map[string]interface{}, error) {
+	var value string
+	switch v := params["Approved"].(type) {
+	case string:
+		value = v
+	case []string:
+		if len(v) != 1 {
+			return nil, fmt.Errorf("certificate store Approved filter must have a single value, got %d", len(v))
+		}
+		value = v[0]
+	default:
+		return params, nil
+	}
+	approved, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate store Approved filter %q: %w", value, err)
+	}
+	converted := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		converted[k] = v
+	}
+	converted["Approved"] = approved
+	return converted, nil
+}
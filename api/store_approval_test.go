@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestClient_SearchCertificateStoresByApproval(t *testing.T) {
+	var lastQuery url.Values
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		lastQuery = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"Id": "3f2a5c1e-8b7d-4e6f-9a0b-1c2d3e4f5a6b", "ClientMachine": "web01", "Approved": false}]`))
+	})
+
+	tests := []struct {
+		name     string
+		q        string
+		approved bool
+		want     string
+	}{
+		{name: "Approved", approved: true, want: "Approved -eq true"},
+		{name: "Unapproved", approved: false, want: "Approved -eq false"},
+		{name: "WithQuery", q: `ClientMachine -startswith "web"`, approved: true, want: `(ClientMachine -startswith "web") AND Approved -eq true`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.SearchCertificateStoresByApproval(tt.q, tt.approved, nil)
+			if err != nil {
+				t.Fatalf("SearchCertificateStoresByApproval() error = %v", err)
+			}
+			if len(got) != 1 || got[0].Approved {
+				t.Errorf("SearchCertificateStoresByApproval() = %+v", got)
+			}
+			if q := lastQuery.Get("certificateStoreQuery.queryString"); q != tt.want {
+				t.Errorf("SearchCertificateStoresByApproval() sent query %q, want %q", q, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_ListCertificateStoresApproved(t *testing.T) {
+	var lastQuery url.Values
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		lastQuery = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"Id": "3f2a5c1e-8b7d-4e6f-9a0b-1c2d3e4f5a6b", "ClientMachine": "web01", "Approved": true}]`))
+	})
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		want    string
+		wantErr bool
+	}{
+		{name: "Bool", params: map[string]interface{}{"Approved": false}, want: "Approved -eq false"},
+		{name: "String", params: map[string]interface{}{"Approved": "true"}, want: "Approved -eq true"},
+		{name: "Slice", params: map[string]interface{}{"Approved": []string{"true"}, "ClientMachine": "web01"}, want: `Approved -eq true AND ClientMachine -eq "web01"`},
+		{name: "Invalid", params: map[string]interface{}{"Approved": "pending"}, wantErr: true},
+		{name: "SeveralValues", params: map[string]interface{}{"Approved": []string{"true", "false"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.ListCertificateStores(&tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ListCertificateStores() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(*got) != 1 || !(*got)[0].Approved {
+				t.Errorf("ListCertificateStores() = %+v", *got)
+			}
+			if q := lastQuery.Get("certificateStoreQuery.queryString"); q != tt.want {
+				t.Errorf("ListCertificateStores() sent query %q, want %q", q, tt.want)
+			}
+		})
+	}
+}
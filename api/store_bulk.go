@@ -0,0 +1,204 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults used by RemoveCertificateFromStores when the corresponding BulkStoreOptions field is not set.
+const (
+	DefaultBulkChunkSize    = 50
+	DefaultBulkMaxRetries   = 3
+	DefaultBulkRetryBackoff = 2 * time.Second
+)
+
+// BulkStoreOptions controls how RemoveCertificateFromStores splits up and retries a large store list.
+type BulkStoreOptions struct {
+	// ChunkSize is the number of store locations sent to Keyfactor per request. Defaults to DefaultBulkChunkSize.
+	ChunkSize int
+	// MaxRetries is the number of times a chunk is retried after a transient failure, such as a connection that could
+	// not be made or a 429 or 5xx response. A request that was sent but got no response is not retried, as Keyfactor
+	// may have scheduled its jobs. Defaults to DefaultBulkMaxRetries; use a negative value to disable retries.
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry of a chunk; it doubles with every further retry. Defaults to
+	// DefaultBulkRetryBackoff.
+	RetryBackoff time.Duration
+	// Progress, if set, is called as store aliases are resolved, after each chunk is sent, and when the run is done.
+	// Its counts are of store locations.
+	Progress ProgressFunc
+}
+
+// StoreRemovalResult is the outcome of removing a certificate from a single store location.
+type StoreRemovalResult struct {
+	CertificateStoreId string
+	Alias              string
+	// JobId is the orchestrator job scheduled for the location. It is empty if the request failed, or if Keyfactor
+	// did not return one job per location, in which case the jobs of the chunk are only listed in the report.
+	JobId string
+	// Attempts is the number of requests made for the chunk the location was sent in. It is zero if the location
+	// could not be resolved.
+	Attempts int
+	Err      error
+}
+
+// StoreRemovalReport is returned by RemoveCertificateFromStores.
+type StoreRemovalReport struct {
+	// Results holds one entry per store location, in the order they were processed. A store whose alias was
+	// resolved from its inventory has one entry per alias the certificate was found under.
+	Results []StoreRemovalResult
+	// JobIds lists every orchestrator job that was scheduled.
+	JobIds []string
+}
+
+// Failed returns the results of the locations the certificate could not be removed from.
+func (r *StoreRemovalReport) Failed() []StoreRemovalResult {
+	var failed []StoreRemovalResult
+	for _, res := range r.Results {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// Remaining returns the stores of config that still need the certificate removed, so that an interrupted or partly
+// failed run can be resumed by calling RemoveCertificateFromStores again with only those stores.
+func (r *StoreRemovalReport) Remaining(config *RemoveCertificateFromStore) []CertificateStore {
+	failed := map[string]bool{}
+	for _, res := range r.Failed() {
+		failed[res.CertificateStoreId] = true
+	}
+	var remaining []CertificateStore
+	if config == nil || config.CertificateStores == nil {
+		return remaining
+	}
+	for _, store := range *config.CertificateStores {
+		if failed[store.CertificateStoreId] {
+			remaining = append(remaining, store)
+		}
+	}
+	return remaining
+}
+
+// RemoveCertificateFromStoresContext is like RemoveCertificateFromStores but uses ctx for the requests, allowing it to
+// be cancelled.
+func (c *Client) RemoveCertificateFromStoresContext(ctx context.Context, config *RemoveCertificateFromStore, opts *BulkStoreOptions) (*StoreRemovalReport, error) {
+	if config == nil || config.CertificateStores == nil || len(*config.CertificateStores) == 0 {
+		return nil, errors.New("at least one certificate store is required to remove a certificate from certificate stores")
+	}
+	if opts == nil {
+		opts = &BulkStoreOptions{}
+	}
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultBulkChunkSize
+	}
+	maxRetries := opts.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultBulkMaxRetries
+	} else if maxRetries < 0 {
+		maxRetries = 0
+	}
+	backoff := opts.RetryBackoff
+	if backoff <= 0 {
+		backoff = DefaultBulkRetryBackoff
+	}
+	c.infof("Removing certificate from %d certificate stores in chunks of %d", len(*config.CertificateStores), chunkSize)
+
+	report := &StoreRemovalReport{}
+	var locations []CertificateStore
+	stores := *config.CertificateStores
+	for i, store := range stores {
+		resolved, err := c.resolveStoreAliases(ctx, config, store)
+		if err != nil {
+			c.errorf("%s", err)
+			report.Results = append(report.Results, StoreRemovalResult{CertificateStoreId: store.CertificateStoreId, Alias: store.Alias, Err: err})
+		} else {
+			locations = append(locations, resolved...)
+		}
+		opts.Progress.report(Progress{Phase: ProgressPhaseResolving, Completed: i + 1 - len(report.Results), Failed: len(report.Results), Total: len(stores)})
+	}
+
+	// Locations whose store could not be resolved count towards the total as failures.
+	total := len(locations) + len(report.Results)
+	progress := func(phase ProgressPhase) {
+		failed := len(report.Failed())
+		opts.Progress.report(Progress{Phase: phase, Completed: len(report.Results) - failed, Failed: failed, Total: total})
+	}
+	progress(ProgressPhaseProcessing)
+
+	batches, err := c.removalBatches(config, locations, chunkSize)
+	if err != nil {
+		return report, err
+	}
+	for _, batch := range batches {
+		chunk := locations[batch[0]:batch[1]]
+
+		jobIds, attempts, err := c.removeChunkWithRetry(ctx, config, chunk, maxRetries, backoff)
+		report.JobIds = append(report.JobIds, jobIds...)
+		for i, store := range chunk {
+			res := StoreRemovalResult{CertificateStoreId: store.CertificateStoreId, Alias: store.Alias, Attempts: attempts, Err: err}
+			if err == nil && len(jobIds) == len(chunk) {
+				res.JobId = jobIds[i]
+			}
+			report.Results = append(report.Results, res)
+		}
+		progress(ProgressPhaseProcessing)
+	}
+	progress(ProgressPhaseDone)
+
+	if failed := report.Failed(); len(failed) > 0 {
+		return report, fmt.Errorf("unable to remove certificate from %d of %d certificate store locations; first error: %w", len(failed), len(report.Results), failed[0].Err)
+	}
+	return report, nil
+}
+
+// removeChunkWithRetry sends a chunk of locations to Keyfactor, retrying transient failures. It returns the scheduled
+// job IDs and the number of requests made.
+func (c *Client) removeChunkWithRetry(ctx context.Context, config *RemoveCertificateFromStore, chunk []CertificateStore, maxRetries int, backoff time.Duration) ([]string, int, error) {
+	for attempt := 1; ; attempt++ {
+		var sent int32
+		trace := &httptrace.ClientTrace{WroteHeaders: func() { atomic.StoreInt32(&sent, 1) }}
+		jobIds, resp, err := c.removeCertificateLocations(httptrace.WithClientTrace(ctx, trace), config, chunk)
+		if err == nil {
+			return jobIds, attempt, nil
+		}
+		if attempt > maxRetries || errors.Is(err, ErrPayloadTooLarge) || !isTransientFailure(ctx, resp, atomic.LoadInt32(&sent) == 1) {
+			return nil, attempt, err
+		}
+
+		wait := backoff << (attempt - 1)
+		c.warnf("Removing certificate from %d certificate store locations failed (attempt %d): %s; retrying in %s", len(chunk), attempt, err, wait)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, attempt, fmt.Errorf("retrying certificate removal: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// isTransientFailure reports whether a failed request is worth retrying: the request got no response and was never
+// sent, e.g. because the connection could not be made, or the response was a rate limit or server-side error. A
+// request that was sent without a response, such as one that timed out, is not retried, as Keyfactor may have
+// scheduled its jobs already.
+func isTransientFailure(ctx context.Context, resp *http.Response, sent bool) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if resp == nil {
+		return !sent
+	}
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient_RemoveCertificateFromStores_Report(t *testing.T) {
+	requests := 0
+	flakyFailures := 0
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method+" "+r.URL.Path != "POST /KeyfactorAPI/CertificateStores/Certificates/Remove" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		requests++
+		var body struct {
+			CertificateStores []CertificateStore
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		var jobIds []string
+		for _, store := range body.CertificateStores {
+			switch store.CertificateStoreId {
+			case "flaky":
+				if flakyFailures < 2 {
+					flakyFailures++
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+			case "broken":
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"Message": "store is locked"}`))
+				return
+			}
+			jobIds = append(jobIds, "job-"+store.CertificateStoreId)
+		}
+		json.NewEncoder(w).Encode(jobIds)
+	})
+
+	config := &RemoveCertificateFromStore{
+		Alias: "web",
+		CertificateStores: &[]CertificateStore{
+			{CertificateStoreId: "s1"},
+			{CertificateStoreId: "s2"},
+			{CertificateStoreId: "flaky"},
+			{CertificateStoreId: "s3"},
+			{CertificateStoreId: "broken"},
+		},
+	}
+	var progress []Progress
+	opts := &BulkStoreOptions{ChunkSize: 2, RetryBackoff: time.Millisecond, Progress: func(p Progress) { progress = append(progress, p) }}
+
+	report, err := c.RemoveCertificateFromStoresContext(context.Background(), config, opts)
+	if err == nil {
+		t.Fatalf("RemoveCertificateFromStoresContext() error = nil, want error for the broken store")
+	}
+	if report == nil || len(report.Results) != 5 {
+		t.Fatalf("RemoveCertificateFromStoresContext() report = %+v", report)
+	}
+	if requests != 5 {
+		t.Errorf("requests = %d, want 5 (3 chunks, 2 retries)", requests)
+	}
+
+	wantJobs := map[string]string{"s1": "job-s1", "s2": "job-s2", "flaky": "job-flaky", "s3": "job-s3", "broken": ""}
+	for _, res := range report.Results {
+		if res.JobId != wantJobs[res.CertificateStoreId] {
+			t.Errorf("result for %s has job %q, want %q", res.CertificateStoreId, res.JobId, wantJobs[res.CertificateStoreId])
+		}
+		if res.Alias != "web" {
+			t.Errorf("result for %s has alias %q, want web", res.CertificateStoreId, res.Alias)
+		}
+	}
+	if res := report.Results[2]; res.Attempts != 3 || res.Err != nil {
+		t.Errorf("flaky store result = %+v, want success after 3 attempts", res)
+	}
+	if !reflect.DeepEqual(report.JobIds, []string{"job-s1", "job-s2", "job-flaky", "job-s3"}) {
+		t.Errorf("JobIds = %v", report.JobIds)
+	}
+
+	if len(progress) != 10 {
+		t.Fatalf("progress = %+v, want 5 resolving, 4 processing, and 1 done", progress)
+	}
+	if p := progress[4]; p.Phase != ProgressPhaseResolving || p.Completed != 5 || p.Total != 5 {
+		t.Errorf("last resolving progress = %+v", p)
+	}
+	if p := progress[7]; p.Phase != ProgressPhaseProcessing || p.Completed != 4 || p.Failed != 0 {
+		t.Errorf("progress after second chunk = %+v", p)
+	}
+	if p := progress[9]; p != (Progress{Phase: ProgressPhaseDone, Completed: 4, Failed: 1, Total: 5}) {
+		t.Errorf("done progress = %+v", p)
+	}
+
+	remaining := report.Remaining(config)
+	if len(remaining) != 1 || remaining[0].CertificateStoreId != "broken" {
+		t.Fatalf("Remaining() = %+v, want only the broken store", remaining)
+	}
+
+	// Non-transient failures are not retried.
+	requests = 0
+	_, err = c.RemoveCertificateFromStoresContext(context.Background(), &RemoveCertificateFromStore{Alias: "web", CertificateStores: &remaining}, opts)
+	if err == nil || requests != 1 {
+		t.Errorf("retrying the broken store: error = %v, requests = %d, want error after 1 request", err, requests)
+	}
+
+	if _, err := c.RemoveCertificateFromStoresContext(context.Background(), &RemoveCertificateFromStore{}, nil); err == nil {
+		t.Errorf("RemoveCertificateFromStoresContext() with no stores succeeded, want error")
+	}
+}
+
+func TestClient_RemoveCertificateFromStores_NoResponse(t *testing.T) {
+	config := &RemoveCertificateFromStore{Alias: "web", CertificateStores: &[]CertificateStore{{CertificateStoreId: "s1"}}}
+	opts := &BulkStoreOptions{RetryBackoff: time.Millisecond}
+
+	// The connection drops after the request was sent, so Keyfactor may have scheduled the job.
+	requests := 0
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	})
+	report, err := c.RemoveCertificateFromStores(config, opts)
+	if err == nil || requests != 1 || report.Results[0].Attempts != 1 {
+		t.Errorf("RemoveCertificateFromStores() without a response: error = %v, requests = %d, want error after 1 request", err, requests)
+	}
+
+	// A request that could not be sent is retried.
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	srv.Close()
+	c = &Client{hostname: srv.URL, httpClient: srv.Client(), apiPath: "KeyfactorAPI"}
+	report, err = c.RemoveCertificateFromStores(config, opts)
+	if err == nil || report.Results[0].Attempts != DefaultBulkMaxRetries+1 {
+		t.Errorf("RemoveCertificateFromStores() without a connection: error = %v, attempts = %d, want %d", err, report.Results[0].Attempts, DefaultBulkMaxRetries+1)
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrConflict is returned by UpdateStore when UpdateStoreFctArgs.ExpectedRevision is set and the certificate store has
+// been changed by another writer since that revision was read.
+var ErrConflict = errors.New("certificate store was modified by another writer")
+
+// Revision returns an opaque revision of the certificate store's configuration, which changes whenever the store's
+// type, client machine, store path, orchestrator, container, properties, or inventory schedule change. Set it as
+// UpdateStoreFctArgs.ExpectedRevision to update the store only if it has not changed since it was read. Keyfactor
+// never returns the values of secret properties, so changing only a secret does not change the revision.
+func (s *GetCertificateStoreResponse) Revision() (string, error) {
+	spec, err := s.Spec()
+	if err != nil {
+		return "", err
+	}
+	return spec.Hash()
+}
+
+// checkStoreRevision returns an error wrapping ErrConflict if the revision of the store is not expected.
+func checkStoreRevision(store *GetCertificateStoreResponse, expected string) error {
+	revision, err := store.Revision()
+	if err != nil {
+		return fmt.Errorf("unable to compute revision of certificate store %s: %w", store.Id, err)
+	}
+	if revision != expected {
+		return fmt.Errorf("%w: certificate store %s is at revision %s, expected %s", ErrConflict, store.Id, revision, expected)
+	}
+	return nil
+}
+
+// lockStore serializes the conditional updates of a certificate store made through the client, so that two goroutines
+// cannot both pass the revision check before either has written. It returns the function that releases the lock.
+func (c *Client) lockStore(storeId string) func() {
+	c.storeLocksMu.Lock()
+	if c.storeLocks == nil {
+		c.storeLocks = map[string]*sync.Mutex{}
+	}
+	mu, ok := c.storeLocks[storeId]
+	if !ok {
+		mu = &sync.Mutex{}
+		c.storeLocks[storeId] = mu
+	}
+	c.storeLocksMu.Unlock()
+
+	mu.Lock()
+	return mu.Unlock
+}
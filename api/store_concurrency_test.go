@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestClient_UpdateStoreConditional(t *testing.T) {
+	const storeId = "3f2a5c1e-8b7d-4e6f-9a0b-1c2d3e4f5a6b"
+	const agentId = "6c1f0a2b-3d4e-4f5a-8b9c-0d1e2f3a4b5c"
+	var (
+		mu    sync.Mutex
+		store = map[string]interface{}{
+			"Id": storeId, "ClientMachine": "web01.example.com", "Storepath": "/etc/ssl/app.pem", "CertStoreType": 105,
+			"AgentId": agentId, "Properties": `{"Owner": {"value": "root"}}`,
+		}
+		puts int
+	)
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/KeyfactorAPI/CertificateStores/"+storeId:
+			json.NewEncoder(w).Encode(store)
+		case strings.EqualFold(r.Method, "PUT") && r.URL.Path == "/KeyfactorAPI/CertificateStores":
+			puts++
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			store["Properties"] = body["Properties"]
+			json.NewEncoder(w).Encode(store)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	current, err := c.GetCertificateStoreByID(storeId)
+	if err != nil {
+		t.Fatalf("GetCertificateStoreByID() error = %v", err)
+	}
+	revision, err := current.Revision()
+	if err != nil || revision == "" {
+		t.Fatalf("Revision() = %q, %v", revision, err)
+	}
+	args := func(owner, expected string) *UpdateStoreFctArgs {
+		return &UpdateStoreFctArgs{
+			Id: storeId,
+			CreateStoreFctArgs: CreateStoreFctArgs{
+				ClientMachine: "web01.example.com", StorePath: "/etc/ssl/app.pem", CertStoreType: 105, AgentId: agentId,
+				Properties: map[string]interface{}{"Owner": owner},
+			},
+			ExpectedRevision: expected,
+		}
+	}
+
+	// Two writers that read the same revision race; only one of them may win.
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, owner := range []string{"alice", "bob"} {
+		wg.Add(1)
+		go func(i int, owner string) {
+			defer wg.Done()
+			_, errs[i] = c.UpdateStore(args(owner, revision))
+		}(i, owner)
+	}
+	wg.Wait()
+	conflicts := 0
+	for _, err := range errs {
+		if errors.Is(err, ErrConflict) {
+			conflicts++
+		} else if err != nil {
+			t.Fatalf("UpdateStore() error = %v", err)
+		}
+	}
+	if conflicts != 1 || puts != 1 {
+		t.Errorf("concurrent conditional updates: %d conflicts and %d updates, want 1 and 1", conflicts, puts)
+	}
+
+	updated, err := c.GetCertificateStoreByID(storeId)
+	if err != nil {
+		t.Fatalf("GetCertificateStoreByID() error = %v", err)
+	}
+	newRevision, _ := updated.Revision()
+	if newRevision == revision {
+		t.Errorf("Revision() did not change after the store was updated")
+	}
+	if _, err := c.UpdateStore(args("carol", newRevision)); err != nil {
+		t.Errorf("UpdateStore() at the current revision error = %v", err)
+	}
+	if _, err := c.UpdateStore(args("dave", "")); err != nil || puts != 3 {
+		t.Errorf("unconditional UpdateStore() error = %v, %d updates", err, puts)
+	}
+}
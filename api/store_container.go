@@ -3,30 +3,35 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"strconv"
+	"strings"
 
-	"github.com/Keyfactor/keyfactor-go-client-sdk/api/keyfactor"
+	"github.com/Keyfactor/keyfactor-go-client/query"
 )
 
 // GetStoreContainers returns a list of store containers
 func (c *Client) GetStoreContainers() (*[]CertStoreContainer, error) {
-	log.Println("[INFO] Listing certificate store containers.")
+	return c.GetStoreContainersContext(context.Background())
+}
+
+// GetStoreContainersContext is like GetStoreContainers but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) GetStoreContainersContext(ctx context.Context) (*[]CertStoreContainer, error) {
+	c.infof("Listing certificate store containers.")
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
-	resp, _, err := apiClient.CertificateStoreContainerApi.CertificateStoreContainerGetAllCertificateStoreContainers(context.Background()).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, _, err := apiClient.CertificateStoreContainerApi.CertificateStoreContainerGetAllCertificateStoreContainers(ctx).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
 		return nil, err
 	}
 
-	var newResp []CertStoreContainer
+	newResp := make([]CertStoreContainer, 0, len(resp))
 	for i, _ := range resp {
 		var newCont CertStoreContainer
 		mapResp, _ := resp[i].ToMap()
@@ -41,9 +46,9 @@ func (c *Client) GetStoreContainers() (*[]CertStoreContainer, error) {
 // GetStoreContainer takes an ID and returns a single store container
 // TODO?
 func (c *Client) GetStoreContainer(id interface{}) (*CertStoreContainer, error) {
-	log.Printf("[INFO] Fetching certificate store containers %s.\n", id)
+	c.infof("Fetching certificate store containers %s.", id)
 	var endpoint string
-	var query apiQuery
+	var q apiQuery
 	var jsonResp interface{}
 
 	headers := &apiHeaders{
@@ -68,21 +73,21 @@ func (c *Client) GetStoreContainer(id interface{}) (*CertStoreContainer, error)
 	} else {
 		// Endpoint returns a list of store containers
 		endpoint = "CertificateStoreContainers"
-		query = apiQuery{
+		q = apiQuery{
 			Query: []StringTuple{},
 		}
-		query.Query = append(query.Query, StringTuple{
-			"pq.queryString", fmt.Sprintf(`Name -eq "%s"`, id),
+		q.Query = append(q.Query, StringTuple{
+			"pq.queryString", query.Field("Name").Eq(fmt.Sprintf("%s", id)).String(),
 		})
 		jsonResp = &[]CertStoreContainer{}
 	}
 	var keyfactorAPIStruct *request
-	if query.Query != nil {
+	if q.Query != nil {
 		keyfactorAPIStruct = &request{
 			Method:   "GET",
 			Endpoint: endpoint,
 			Headers:  headers,
-			Query:    &query,
+			Query:    &q,
 		}
 	} else {
 		keyfactorAPIStruct = &request{
@@ -97,7 +102,7 @@ func (c *Client) GetStoreContainer(id interface{}) (*CertStoreContainer, error)
 		return nil, err
 	}
 
-	err = json.NewDecoder(resp.Body).Decode(&jsonResp)
+	err = c.decodeResponse(resp.Body, &jsonResp)
 	if err != nil {
 		return nil, err
 	}
@@ -112,3 +117,94 @@ func (c *Client) GetStoreContainer(id interface{}) (*CertStoreContainer, error)
 	}
 	return nil, fmt.Errorf("invalid API response from Keyfactor while getting cert store container %s", id)
 }
+
+// storeContainerChunkSize is the number of certificate stores assigned to a container per request.
+var storeContainerChunkSize = 100
+
+// AssignStoresToContainer takes arguments for a list of certificate store IDs and a certificate store container ID to
+// facilitate calls to Keyfactor that move every store into the container, e.g. when reorganizing stores after a
+// change to the container taxonomy. Stores are assigned in batches; a failed batch does not stop the others. The IDs
+// of the stores that were assigned are returned, along with an error naming the stores that were not.
+func (c *Client) AssignStoresToContainer(storeIds []string, containerId int) ([]string, error) {
+	c.infof("Assigning %d certificate stores to certificate store container %d", len(storeIds), containerId)
+
+	if len(storeIds) == 0 {
+		return nil, errors.New("at least one certificate store id is required")
+	}
+	if containerId <= 0 {
+		return nil, errors.New("certificate store container id is required to assign certificate stores")
+	}
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	var assigned, failed []string
+	for start := 0; start < len(storeIds); start += storeContainerChunkSize {
+		end := start + storeContainerChunkSize
+		if end > len(storeIds) {
+			end = len(storeIds)
+		}
+		chunk := storeIds[start:end]
+
+		keyfactorAPIStruct := &request{
+			Method:   "PUT",
+			Endpoint: "CertificateStores/Assign",
+			Headers:  headers,
+			Payload:  &containerAssignment{KeystoreIds: chunk, CertStoreContainerId: containerId},
+		}
+
+		if _, err := c.sendRequest(keyfactorAPIStruct); err != nil {
+			c.errorf("Unable to assign %d certificate stores to container %d: %s", len(chunk), containerId, err)
+			failed = append(failed, fmt.Sprintf("%s (%s)", strings.Join(chunk, ", "), err))
+			continue
+		}
+		assigned = append(assigned, chunk...)
+	}
+	if len(failed) > 0 {
+		return assigned, fmt.Errorf("unable to assign certificate stores to container %d: %s", containerId, strings.Join(failed, "; "))
+	}
+	return assigned, nil
+}
+
+// UnassignStoresFromContainer takes arguments for a list of certificate store IDs to facilitate calls to Keyfactor that
+// remove each store from its certificate store container, leaving everything else about the store unchanged. Stores
+// that are not in a container are skipped. The IDs of the stores that were updated are returned, along with an error
+// naming any store that failed to update.
+func (c *Client) UnassignStoresFromContainer(storeIds []string) ([]string, error) {
+	c.infof("Removing %d certificate stores from their containers", len(storeIds))
+
+	if len(storeIds) == 0 {
+		return nil, errors.New("at least one certificate store id is required")
+	}
+
+	var updated, failed []string
+	for _, id := range storeIds {
+		store, err := c.GetCertificateStoreByID(id)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%s)", id, err))
+			continue
+		}
+		if store.ContainerId <= 0 {
+			c.infof("Certificate store %s is not in a container", id)
+			continue
+		}
+
+		args := reassignStoreArgs(store, store.AgentId)
+		args.ContainerId = nil
+		if _, err := c.UpdateStore(args); err != nil {
+			c.errorf("Unable to remove certificate store %s from container %d: %s", id, store.ContainerId, err)
+			failed = append(failed, fmt.Sprintf("%s (%s)", id, err))
+			continue
+		}
+		updated = append(updated, id)
+	}
+	if len(failed) > 0 {
+		return updated, fmt.Errorf("unable to remove certificate stores from their containers: %s", strings.Join(failed, "; "))
+	}
+	return updated, nil
+}
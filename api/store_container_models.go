@@ -6,5 +6,11 @@ type CertStoreContainer struct {
 	Name               string `json:"Name"`
 	OverwriteSchedules bool   `json:"OverwriteSchedules"`
 	Schedule           string `json:"Schedule"`
-	CertStoreType      int    `json:"CertStoreType"`
+	CertStoreType      int    `json:"CertStoreType" jsonalias:"StoreType,StoreTypeId"`
+}
+
+// containerAssignment is the request body for /CertificateStores/Assign.
+type containerAssignment struct {
+	KeystoreIds          []string `json:"KeystoreIds"`
+	CertStoreContainerId int      `json:"CertStoreContainerId"`
 }
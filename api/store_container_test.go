@@ -1,8 +1,12 @@
 package api
 
 import (
+	"encoding/json"
 	"io"
 	"log"
+	"net/http"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -124,3 +128,88 @@ func TestClient_GetStoreContainers(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_AssignStoresToContainer(t *testing.T) {
+	var assignments []containerAssignment
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/KeyfactorAPI/CertificateStores/Assign" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body containerAssignment
+		json.NewDecoder(r.Body).Decode(&body)
+		for _, id := range body.KeystoreIds {
+			if id == "locked" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		assignments = append(assignments, body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	defer func(size int) { storeContainerChunkSize = size }(storeContainerChunkSize)
+	storeContainerChunkSize = 2
+
+	got, err := c.AssignStoresToContainer([]string{"s1", "s2", "s3", "locked", "s5"}, 7)
+	if err == nil || !strings.Contains(err.Error(), "s3, locked") {
+		t.Errorf("AssignStoresToContainer() error = %v, want failure naming the locked batch", err)
+	}
+	if want := []string{"s1", "s2", "s5"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AssignStoresToContainer() = %v, want %v", got, want)
+	}
+	if len(assignments) != 2 || assignments[0].CertStoreContainerId != 7 {
+		t.Errorf("AssignStoresToContainer() sent %+v", assignments)
+	}
+
+	if _, err := c.AssignStoresToContainer([]string{"s1"}, 0); err == nil {
+		t.Errorf("AssignStoresToContainer() without a container succeeded, want error")
+	}
+	if _, err := c.AssignStoresToContainer(nil, 7); err == nil {
+		t.Errorf("AssignStoresToContainer() without stores succeeded, want error")
+	}
+}
+
+func TestClient_UnassignStoresFromContainer(t *testing.T) {
+	stores := map[string]GetCertificateStoreResponse{
+		"5f1e0a2c-0005-4000-8000-000000000005": {Id: "5f1e0a2c-0005-4000-8000-000000000005", ClientMachine: "web01", StorePath: "My", CertStoreType: 2, AgentId: "a9c3d7e4-0001-4000-8000-000000000001", ContainerId: 4},
+		"5f1e0a2c-0006-4000-8000-000000000006": {Id: "5f1e0a2c-0006-4000-8000-000000000006", ClientMachine: "web02", StorePath: "My", CertStoreType: 2, AgentId: "a9c3d7e4-0001-4000-8000-000000000001"},
+	}
+	var updates []map[string]interface{}
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/KeyfactorAPI/CertificateStores/"):
+			store, ok := stores[strings.TrimPrefix(r.URL.Path, "/KeyfactorAPI/CertificateStores/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(store)
+		case strings.EqualFold(r.Method, "PUT") && r.URL.Path == "/KeyfactorAPI/CertificateStores":
+			var update map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&update)
+			updates = append(updates, update)
+			json.NewEncoder(w).Encode(UpdateStoreResponse{})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	got, err := c.UnassignStoresFromContainer([]string{"5f1e0a2c-0005-4000-8000-000000000005", "5f1e0a2c-0006-4000-8000-000000000006", "5f1e0a2c-00ff-4000-8000-0000000000ff"})
+	if err == nil || !strings.Contains(err.Error(), "5f1e0a2c-00ff-4000-8000-0000000000ff") {
+		t.Errorf("UnassignStoresFromContainer() error = %v, want failure naming the missing store", err)
+	}
+	if want := []string{"5f1e0a2c-0005-4000-8000-000000000005"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UnassignStoresFromContainer() = %v, want %v", got, want)
+	}
+	if len(updates) != 1 {
+		t.Fatalf("UnassignStoresFromContainer() sent %d updates, want 1", len(updates))
+	}
+	if _, ok := updates[0]["ContainerId"]; ok {
+		t.Errorf("UnassignStoresFromContainer() update kept ContainerId: %v", updates[0])
+	}
+	if updates[0]["AgentId"] != "a9c3d7e4-0001-4000-8000-000000000001" || updates[0]["ClientMachine"] != "web01" {
+		t.Errorf("UnassignStoresFromContainer() update changed the store: %v", updates[0])
+	}
+}
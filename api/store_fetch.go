@@ -0,0 +1,269 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultStoreFetchConcurrency is the number of certificate stores GetCertificateStoresByIDs fetches at once when no
+// concurrency is given.
+const DefaultStoreFetchConcurrency = 8
+
+// StoreFetchError is returned by GetCertificateStoresByIDs when some of the certificate stores could not be fetched.
+// It maps the ID of each such store to its error.
+type StoreFetchError map[string]error
+
+func (e StoreFetchError) Error() string {
+	return storeErrorsMessage("fetched", e)
+}
+
+// Is reports whether the error of any of the stores matches target, so that errors.Is matches them.
+func (e StoreFetchError) Is(target error) bool {
+	return storeErrorsIs(e, target)
+}
+
+// As finds the first error of the stores, by store ID, that matches target, so that errors.As matches them.
+func (e StoreFetchError) As(target interface{}) bool {
+	return storeErrorsAs(e, target)
+}
+
+// Unwrap returns the errors of the stores, for Go 1.20 and later.
+func (e StoreFetchError) Unwrap() []error {
+	return storeErrors(e)
+}
+
+// storeErrorsMessage lists the errors of certificate stores, by store ID, that could not be verb.
+func storeErrorsMessage(verb string, errs map[string]error) string {
+	ids := storeErrorIds(errs)
+	msgs := make([]string, len(ids))
+	for i, id := range ids {
+		msgs[i] = fmt.Sprintf("%s: %s", id, errs[id])
+	}
+	return fmt.Sprintf("%d certificate stores could not be %s: %s", len(errs), verb, strings.Join(msgs, "; "))
+}
+
+// storeErrorsIs reports whether the error of any of the stores matches target. Go releases before 1.20 do not unwrap
+// multiple errors, so errors.Is is called for each one.
+func storeErrorsIs(errs map[string]error, target error) bool {
+	for _, err := range errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// storeErrorsAs sets target to the first error of the stores, by store ID, that matches it.
+func storeErrorsAs(errs map[string]error, target interface{}) bool {
+	for _, id := range storeErrorIds(errs) {
+		if errors.As(errs[id], target) {
+			return true
+		}
+	}
+	return false
+}
+
+// storeErrorIds returns the sorted IDs of the stores with errors.
+func storeErrorIds(errs map[string]error) []string {
+	ids := make([]string, 0, len(errs))
+	for id := range errs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func storeErrors(errs map[string]error) []error {
+	list := make([]error, 0, len(errs))
+	for _, err := range errs {
+		list = append(list, err)
+	}
+	return list
+}
+
+// GetCertificateStoresByIDs takes arguments for a list of certificate store IDs to facilitate calls to Keyfactor that
+// retrieve each store, making at most concurrency requests at once; DefaultStoreFetchConcurrency is used if it is not
+// greater than zero. The stores are returned keyed by ID. If some could not be retrieved, the others are returned
+// along with a StoreFetchError holding the error of each store that failed.
+func (c *Client) GetCertificateStoresByIDs(ids []string, concurrency int) (map[string]*GetCertificateStoreResponse, error) {
+	return c.GetCertificateStoresByIDsContext(context.Background(), ids, concurrency)
+}
+
+// GetCertificateStoresByIDsContext is like GetCertificateStoresByIDs but uses ctx for the requests, allowing them to be
+// cancelled. Stores not yet requested when ctx is cancelled fail with the context's error.
+func (c *Client) GetCertificateStoresByIDsContext(ctx context.Context, ids []string, concurrency int) (map[string]*GetCertificateStoreResponse, error) {
+	if concurrency <= 0 {
+		concurrency = DefaultStoreFetchConcurrency
+	}
+	c.infof("Fetching %d certificate stores, %d at a time", len(ids), concurrency)
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		stores = make(map[string]*GetCertificateStoreResponse, len(ids))
+		failed = StoreFetchError{}
+	)
+	sem := make(chan struct{}, concurrency)
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			mu.Lock()
+			failed[id] = ctx.Err()
+			mu.Unlock()
+			continue
+		}
+		wg.Add(1)
+		go func(id string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			store, err := c.GetCertificateStoreByIDContext(ctx, id)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed[id] = err
+				return
+			}
+			stores[id] = store
+		}(id)
+	}
+	wg.Wait()
+
+	if len(failed) > 0 {
+		c.errorf("%d of %d certificate stores could not be fetched", len(failed), len(seen))
+		return stores, failed
+	}
+	return stores, nil
+}
+
+// storeDeleteBatchSize is the number of certificate stores DeleteCertificateStores deletes per request.
+const storeDeleteBatchSize = 100
+
+// StoreDeleteError is returned by DeleteCertificateStores when some of the certificate stores could not be deleted.
+// It maps the ID of each such store to its error.
+type StoreDeleteError map[string]error
+
+func (e StoreDeleteError) Error() string {
+	return storeErrorsMessage("deleted", e)
+}
+
+// Is reports whether the error of any of the stores matches target, so that errors.Is matches them.
+func (e StoreDeleteError) Is(target error) bool {
+	return storeErrorsIs(e, target)
+}
+
+// As finds the first error of the stores, by store ID, that matches target, so that errors.As matches them.
+func (e StoreDeleteError) As(target interface{}) bool {
+	return storeErrorsAs(e, target)
+}
+
+// Unwrap returns the errors of the stores, for Go 1.20 and later.
+func (e StoreDeleteError) Unwrap() []error {
+	return storeErrors(e)
+}
+
+// DeleteCertificateStores takes arguments for a list of certificate store IDs to facilitate calls to Keyfactor that
+// delete the stores, many per request. Keyfactor rejects a request as a whole if any of its stores cannot be deleted,
+// so after a rejected request the stores of the request that still exist are reported as failed; the others were
+// deleted. A StoreDeleteError holding the error of each store that was not deleted is returned if there are any.
+func (c *Client) DeleteCertificateStores(ids []string) error {
+	return c.DeleteCertificateStoresContext(context.Background(), ids)
+}
+
+// DeleteCertificateStoresContext is like DeleteCertificateStores but uses ctx for the requests, allowing them to be
+// cancelled.
+func (c *Client) DeleteCertificateStoresContext(ctx context.Context, ids []string) error {
+	c.infof("Deleting %d certificate stores", len(ids))
+
+	failed := StoreDeleteError{}
+	var valid []string
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if err := validateGUID("certificate store", id); err != nil {
+			failed[id] = err
+			continue
+		}
+		valid = append(valid, id)
+	}
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	for start := 0; start < len(valid); start += storeDeleteBatchSize {
+		end := start + storeDeleteBatchSize
+		if end > len(valid) {
+			end = len(valid)
+		}
+		batch := valid[start:end]
+
+		keyfactorAPIStruct := &request{
+			Method:   "DELETE",
+			Endpoint: "CertificateStores",
+			Headers:  headers,
+			Payload:  batch,
+			Context:  ctx,
+		}
+		_, err := c.sendRequest(keyfactorAPIStruct)
+		if err == nil {
+			continue
+		}
+		c.errorf("Deleting certificate stores %d-%d of %d failed: %s", start+1, end, len(valid), err)
+		if ctx.Err() != nil {
+			for _, id := range valid[start:] {
+				failed[id] = err
+			}
+			break
+		}
+		for id, deleteErr := range c.remainingStores(ctx, batch, err) {
+			failed[id] = deleteErr
+		}
+	}
+
+	if len(failed) > 0 {
+		return failed
+	}
+	return nil
+}
+
+// remainingStores returns the stores of ids that still exist after a failed request to delete them, each with the
+// error of the request.
+func (c *Client) remainingStores(ctx context.Context, ids []string, deleteErr error) map[string]error {
+	remaining := map[string]error{}
+	stores, err := c.GetCertificateStoresByIDsContext(ctx, ids, 0)
+	for id := range stores {
+		remaining[id] = deleteErr
+	}
+	if fetchErr, ok := err.(StoreFetchError); ok {
+		for id, getErr := range fetchErr {
+			var reqErr *RequestError
+			if errors.As(getErr, &reqErr) && reqErr.StatusCode == http.StatusNotFound {
+				continue
+			}
+			// Whether the store still exists is unknown, so it is reported as not deleted.
+			remaining[id] = deleteErr
+		}
+	}
+	return remaining
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient_GetCertificateStoresByIDs(t *testing.T) {
+	const missing = "5f1e0a2c-00ff-4000-8000-0000000000ff"
+	var (
+		mu                sync.Mutex
+		inFlight, maxSeen int
+	)
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxSeen {
+			maxSeen = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		id := strings.TrimPrefix(r.URL.Path, "/KeyfactorAPI/CertificateStores/")
+		if id == missing {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"Message": "Certificate store not found"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"Id": %q, "ClientMachine": "host-%s"}`, id, id[len(id)-2:])
+	})
+
+	var ids []string
+	for i := 1; i <= 20; i++ {
+		ids = append(ids, fmt.Sprintf("5f1e0a2c-%04x-4000-8000-%012x", i, i))
+	}
+	ids = append(ids, ids[0], missing, "not-a-guid")
+
+	stores, err := c.GetCertificateStoresByIDs(ids, 4)
+	var fetchErr StoreFetchError
+	if !errors.As(err, &fetchErr) {
+		t.Fatalf("GetCertificateStoresByIDs() error = %v, want StoreFetchError", err)
+	}
+	if len(fetchErr) != 2 || fetchErr[missing] == nil || fetchErr["not-a-guid"] == nil {
+		t.Errorf("GetCertificateStoresByIDs() failed stores = %v", fetchErr)
+	}
+	var reqErr *RequestError
+	if !errors.As(fetchErr[missing], &reqErr) || reqErr.StatusCode != http.StatusNotFound {
+		t.Errorf("GetCertificateStoresByIDs() error of missing store = %v", fetchErr[missing])
+	}
+	// The store errors are matched through Is and As, as Go releases before 1.20 ignore Unwrap() []error.
+	reqErr = nil
+	if !fetchErr.As(&reqErr) || reqErr.StatusCode != http.StatusNotFound || !fetchErr.Is(reqErr) || fetchErr.Is(ErrAliasRequired) {
+		t.Errorf("StoreFetchError does not match the error of the missing store: %v", fetchErr)
+	}
+	if len(stores) != 20 {
+		t.Fatalf("GetCertificateStoresByIDs() returned %d stores, want 20", len(stores))
+	}
+	for _, id := range ids[:20] {
+		if stores[id] == nil || stores[id].Id != id {
+			t.Errorf("GetCertificateStoresByIDs() store %s = %+v", id, stores[id])
+		}
+	}
+	if maxSeen > 4 {
+		t.Errorf("GetCertificateStoresByIDs() made %d concurrent requests, want at most 4", maxSeen)
+	}
+
+	stores, err = c.GetCertificateStoresByIDs(ids[:3], 0)
+	if err != nil || len(stores) != 3 {
+		t.Errorf("GetCertificateStoresByIDs() = %d stores, %v", len(stores), err)
+	}
+}
+
+func TestClient_DeleteCertificateStores(t *testing.T) {
+	const locked = "5f1e0a2c-00ff-4000-8000-0000000000ff"
+	var (
+		mu       sync.Mutex
+		existing = map[string]bool{locked: true}
+		requests int
+	)
+	var ids []string
+	for i := 1; i <= 150; i++ {
+		id := fmt.Sprintf("5f1e0a2c-%04x-4000-8000-%012x", i, i)
+		existing[id] = true
+		ids = append(ids, id)
+	}
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == "DELETE" && r.URL.Path == "/KeyfactorAPI/CertificateStores":
+			requests++
+			var batch []string
+			if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+				t.Errorf("DELETE body: %v", err)
+			}
+			for _, id := range batch {
+				if id == locked {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`{"Message": "Certificate store has pending jobs"}`))
+					return
+				}
+			}
+			for _, id := range batch {
+				delete(existing, id)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == "GET":
+			id := strings.TrimPrefix(r.URL.Path, "/KeyfactorAPI/CertificateStores/")
+			if !existing[id] {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprintf(w, `{"Id": %q}`, id)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	if err := c.DeleteCertificateStores(ids[:120]); err != nil {
+		t.Fatalf("DeleteCertificateStores() error = %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("DeleteCertificateStores() made %d requests, want 2", requests)
+	}
+
+	err := c.DeleteCertificateStores(append(ids[100:], locked, "not-a-guid"))
+	var deleteErr StoreDeleteError
+	if !errors.As(err, &deleteErr) {
+		t.Fatalf("DeleteCertificateStores() error = %v, want StoreDeleteError", err)
+	}
+	if len(deleteErr) != 32 || deleteErr[locked] == nil || deleteErr["not-a-guid"] == nil {
+		t.Errorf("DeleteCertificateStores() failed %d stores: %v", len(deleteErr), deleteErr)
+	}
+	for _, id := range ids[100:120] {
+		if deleteErr[id] != nil {
+			t.Errorf("DeleteCertificateStores() reported already deleted store %s as failed", id)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// InventorySnapshot is the inventory of a set of certificate stores at a point in time. It can be saved as JSON and
+// compared with a later snapshot using the diff package, e.g. to feed change detection without a database.
+type InventorySnapshot struct {
+	TakenAt time.Time `json:"TakenAt"`
+	// Stores maps each certificate store ID to its inventory.
+	Stores map[string][]CertStoreInventory `json:"Stores"`
+}
+
+// SnapshotStoreInventories takes arguments for a list of certificate store IDs to facilitate calls to Keyfactor that
+// retrieve the current inventory of each store. The snapshot reflects the last inventory job run by the orchestrator
+// for each store, not a live read of the store itself.
+func (c *Client) SnapshotStoreInventories(storeIds []string) (*InventorySnapshot, error) {
+	return c.SnapshotStoreInventoriesContext(context.Background(), storeIds)
+}
+
+// SnapshotStoreInventoriesContext is like SnapshotStoreInventories but uses ctx for the request, allowing it to be
+// cancelled.
+func (c *Client) SnapshotStoreInventoriesContext(ctx context.Context, storeIds []string) (*InventorySnapshot, error) {
+	c.infof("Taking inventory snapshot of %d certificate stores", len(storeIds))
+	if len(storeIds) == 0 {
+		return nil, errors.New("at least one certificate store id is required to take an inventory snapshot")
+	}
+
+	snapshot := &InventorySnapshot{TakenAt: time.Now().UTC(), Stores: make(map[string][]CertStoreInventory, len(storeIds))}
+	for _, id := range storeIds {
+		inventory, err := c.GetCertStoreInventoryContext(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get inventory of certificate store %s: %w", id, err)
+		}
+		snapshot.Stores[id] = *inventory
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestClient_SnapshotStoreInventories(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/KeyfactorAPI/CertificateStores/5f1e0a2c-0001-4000-8000-000000000001/Inventory":
+			w.Write([]byte(`[{"Name": "web", "Certificates": [{"Id": 1, "Thumbprint": "AAAA"}]}]`))
+		case "/KeyfactorAPI/CertificateStores/5f1e0a2c-0002-4000-8000-000000000002/Inventory":
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	snapshot, err := c.SnapshotStoreInventories([]string{"5f1e0a2c-0001-4000-8000-000000000001", "5f1e0a2c-0002-4000-8000-000000000002"})
+	if err != nil {
+		t.Fatalf("SnapshotStoreInventories() error = %v", err)
+	}
+	if snapshot.TakenAt.IsZero() || len(snapshot.Stores) != 2 {
+		t.Fatalf("SnapshotStoreInventories() = %+v", snapshot)
+	}
+	if s1 := snapshot.Stores["5f1e0a2c-0001-4000-8000-000000000001"]; len(s1) != 1 || s1[0].Name != "web" || s1[0].Certificates[0].Thumbprint != "AAAA" {
+		t.Errorf("SnapshotStoreInventories() s1 = %+v", s1)
+	}
+
+	if _, err := c.SnapshotStoreInventories([]string{"5f1e0a2c-0001-4000-8000-000000000001", "5f1e0a2c-00ff-4000-8000-0000000000ff"}); err == nil {
+		t.Errorf("SnapshotStoreInventories() with a missing store succeeded, want error")
+	}
+	if _, err := c.SnapshotStoreInventories(nil); err == nil {
+		t.Errorf("SnapshotStoreInventories(nil) succeeded, want error")
+	}
+}
+
+func TestClient_GetCertStoreInventory_Numbers(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/KeyfactorAPI/CertificateStores/5f1e0a2c-0001-4000-8000-000000000001/Inventory":
+			w.Write([]byte(`[{"Name": "www", "Parameters": {"SiteName": "Default Web Site", "Port": 8443, "SniFlag": "1"},
+				"Certificates": [{"Id": 9007199254740993, "CertStoreInventoryItemId": 4294967297, "Thumbprint": "AAAA",
+				"NotAfter": "2030-01-01T00:00:00Z"}]}]`))
+		case "/KeyfactorAPI/CertificateStores/5f1e0a2c-0002-4000-8000-000000000002/Inventory":
+			w.Write([]byte(`[{"Name": "www", "Certificates": [{"Id": 1.5}]}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	inv, err := c.GetCertStoreInventory("5f1e0a2c-0001-4000-8000-000000000001")
+	if err != nil {
+		t.Fatalf("GetCertStoreInventory() error = %v", err)
+	}
+	if len(*inv) != 1 || len((*inv)[0].Certificates) != 1 {
+		t.Fatalf("GetCertStoreInventory() = %+v", inv)
+	}
+	item := (*inv)[0]
+	if cert := item.Certificates[0]; cert.Id != 9007199254740993 || cert.CertStoreInventoryItemId != 4294967297 || !cert.NotAfter.Valid() {
+		t.Errorf("GetCertStoreInventory() certificate = %+v", cert)
+	}
+	if port, ok := item.Parameters["Port"].(json.Number); !ok || port != "8443" {
+		t.Errorf("GetCertStoreInventory() Port parameter = %#v, want json.Number", item.Parameters["Port"])
+	}
+	binding, err := item.DecodeParameters("IISU")
+	if err != nil || binding.(IISBinding).Port != 8443 || binding.(IISBinding).SniFlag != 1 {
+		t.Errorf("DecodeParameters() = %+v, %v", binding, err)
+	}
+
+	if _, err := c.GetCertStoreInventory("5f1e0a2c-0002-4000-8000-000000000002"); err == nil {
+		t.Errorf("GetCertStoreInventory() with a fractional Id succeeded, want error")
+	}
+}
@@ -1,5 +1,7 @@
 package api
 
+import "encoding/json"
+
 // CreateStoreFctArgs holds the function arguments used for calling the CreateStore method.
 type CreateStoreFctArgs struct {
 	ContainerId             *int    `json:"ContainerId,omitempty"`
@@ -12,7 +14,8 @@ type CreateStoreFctArgs struct {
 	// String JSON name-value pairs; this field is not recommended. Instead, please use Properties. This field is
 	// automatically populated by the CreateStore method. However, if configured, this field will be used.
 	PropertiesString string `json:"Properties,omitempty"`
-	// Mapped name-value pair field used to configure properties.
+	// Mapped name-value pair field used to configure properties. The StoreProperties method of IISUStoreProperties,
+	// WinCertStoreProperties, JKSStoreProperties, and PEMStoreProperties builds them for those store types.
 	Properties            map[string]interface{} `json:"-"`
 	AgentId               string                 `json:"AgentId"`
 	AgentAssigned         *bool                  `json:"AgentAssigned,omitempty"`
@@ -21,19 +24,30 @@ type CreateStoreFctArgs struct {
 	ReEnrollmentStatus    *ReEnrollmnentConfig   `json:"ReEnrollmentStatus,omitempty"`
 	SetNewPasswordAllowed *bool                  `json:"SetNewPasswordAllowed,omitempty"`
 	Password              *interface{}           `json:"Password,omitempty"` // type: api.StorePasswordConfig
+	// ValidateAgent makes CreateStore check that the orchestrator supports the store type before creating the store.
+	ValidateAgent bool `json:"-"`
+	// ValidateProperties makes CreateStore check the properties against the store type's property definitions before
+	// creating the store, rejecting properties it does not define and missing required ones.
+	ValidateProperties bool `json:"-"`
 }
 
 // UpdateStoreFctArgs holds the function arguments used for calling the UpdateStore method.
 type UpdateStoreFctArgs struct {
 	Id string `json:"Id,omitempty"`
 	CreateStoreFctArgs
+	// ExpectedRevision, if set, makes the update conditional: UpdateStore reads the store first and returns an error
+	// wrapping ErrConflict, without updating it, unless its Revision is ExpectedRevision.
+	ExpectedRevision string `json:"-"`
 }
 
-// InventorySchedule holds configuration data for creating an inventory schedule for a certificate store in Keyfactor
+// InventorySchedule holds configuration data for creating an inventory schedule for a certificate store in Keyfactor.
+// At most one field is set; a schedule with no fields set means inventory is turned off.
 type InventorySchedule struct {
 	Immediate   *bool              `json:"Immediate,omitempty"`
 	Interval    *InventoryInterval `json:"Interval,omitempty"`
 	Daily       *InventoryDaily    `json:"Daily,omitempty"`
+	Weekly      *InventoryWeekly   `json:"Weekly,omitempty"`
+	Monthly     *InventoryMonthly  `json:"Monthly,omitempty"`
 	ExactlyOnce *InventoryOnce     `json:"ExactlyOnce,omitempty"`
 }
 
@@ -47,6 +61,18 @@ type InventoryDaily struct {
 	Time string `json:"Time"`
 }
 
+// InventoryWeekly specifies that the inventory should happen at a given time on the listed days of the week
+type InventoryWeekly struct {
+	Days []string `json:"Days"`
+	Time string   `json:"Time"`
+}
+
+// InventoryMonthly specifies that the inventory should happen at a given time on a day of the month
+type InventoryMonthly struct {
+	Day  int    `json:"Day"`
+	Time string `json:"Time"`
+}
+
 // InventoryOnce specifies that the inventory should happen once, at a given time
 type InventoryOnce struct {
 	Time string `json:"Time"`
@@ -73,29 +99,47 @@ type StorePasswordConfig struct {
 	IsManaged                   bool                       `json:"IsManaged"`
 }
 
+// StoreSecret is a certificate store password, held either in the Keyfactor Command database (SecretValue) or by a
+// PAM provider (Provider and Parameters). Set exactly one of the two.
+type StoreSecret struct {
+	// SecretValue is the password itself, stored in the Keyfactor Command database.
+	SecretValue string `json:"SecretValue,omitempty"`
+	// Provider is the ID of the PAM provider that holds the password.
+	Provider int `json:"Provider,omitempty"`
+	// Parameters identify the password within the PAM provider, e.g. a vault path or secret name. The names depend on
+	// the provider type.
+	Parameters map[string]string `json:"Parameters,omitempty"`
+}
+
+// setStorePasswordBody is the request body for /CertificateStores/Password.
+type setStorePasswordBody struct {
+	CertStoreId string       `json:"CertStoreId"`
+	NewPassword *StoreSecret `json:"NewPassword"`
+}
+
 /* Future non-critical functionality */
 
 type ProviderTypeParamValues struct {
 	Id                *int                `json:"Id"`
 	Value             *string             `json:"Value"`
-	InstanceId        *string             `json:InstanceId"`
-	InstanceGuid      *string             `json:InstanceGuid"`
+	InstanceId        *string             `json:"InstanceId"`
+	InstanceGuid      *string             `json:"InstanceGuid"`
 	ProviderTypeParam *ProviderTypeParams `json:"ProviderTypeParam"`
 	// Provider     ProviderParams
 }
 
 type ProviderTypeParams struct {
 	Id            *int    `json:"Id"`
-	Name          *string `json:"Name`
-	DisplayName   *string `json:"DisplayName`
+	Name          *string `json:"Name"`
+	DisplayName   *string `json:"DisplayName"`
 	DataType      *int    `json:"DataType"`
-	InstanceLevel bool    `json:"InstanceLevel`
+	InstanceLevel bool    `json:"InstanceLevel"`
 	// ProviderType
 }
 
 // Used to post regular secret-type fields
 type SecretField struct {
-	SecretValue string `json:"SecretValue`
+	SecretValue string `json:"SecretValue"`
 }
 
 // Used to post PAM-type secret fields
@@ -105,7 +149,7 @@ type PAMField struct {
 	// value is the parameter value
 	Parameters interface{} `json:"Parameters"`
 	// Provider int id
-	Provider string `json:Provider"`
+	Provider string `json:"Provider"`
 }
 
 // CertStoreTypeResponse contains the response elements returned from the GetCertificateStoreType method.
@@ -130,8 +174,8 @@ type CertStoreTypeResponse struct {
 		Style          string `json:"Style"`
 	} `json:"PasswordOptions"`
 	StorePathValue     []string `json:"store_path_value"`
-	PrivateKeyAllowed  string   `json:"private_key_allowed"`
-	JobProperties      []string `json:"job_properties"`
+	PrivateKeyAllowed  string   `json:"private_key_allowed" jsonalias:"PrivateKeyAllowed"`
+	JobProperties      []string `json:"job_properties" jsonalias:"JobProperties"`
 	ServerRequired     bool     `json:"ServerRequired"`
 	PowerShell         bool     `json:"PowerShell"`
 	BlueprintAllowed   bool     `json:"BlueprintAllowed"`
@@ -146,11 +190,12 @@ type CertStoreTypeResponse struct {
 
 type GetCertificateStoreResponse struct {
 	Id                      string                 `json:"Id,omitempty"`
+	DisplayName             string                 `json:"DisplayName,omitempty"`
 	ContainerId             int                    `json:"ContainerId,omitempty"`
 	ClientMachine           string                 `json:"ClientMachine,omitempty"`
 	StorePath               string                 `json:"Storepath,omitempty"`
 	CertStoreInventoryJobId string                 `json:"CertStoreInventoryJobId,omitempty"`
-	CertStoreType           int                    `json:"CertStoreType,omitempty"`
+	CertStoreType           int                    `json:"CertStoreType,omitempty" jsonalias:"StoreType,StoreTypeId"`
 	Approved                bool                   `json:"Approved,omitempty"`
 	CreateIfMissing         bool                   `json:"CreateIfMissing,omitempty"`
 	PropertiesString        string                 `json:"Properties,omitempty"`
@@ -162,6 +207,9 @@ type GetCertificateStoreResponse struct {
 	ReenrollmentStatus      ReEnrollmnentConfig    `json:"ReenrollmentStatus,omitempty"`
 	SetNewPasswordAllowed   bool                   `json:"SetNewPasswordAllowed,omitempty"`
 	Password                StorePasswordConfig    `json:"Password,omitempty"`
+	// LastInventory is the most recent run of the store's inventory job. Looking it up takes another request, so it is
+	// only populated by GetLastInventory, and is nil if the store has no inventory job or the job has not run yet.
+	LastInventory *JobHistory `json:"-"`
 }
 
 // PropertyDefinition defines property fields associated with a certificate store type, and is returned by the
@@ -176,24 +224,28 @@ type PropertyDefinition struct {
 	Required     bool   `json:"Required"`
 }
 
-// CreateStoreResponse contains the response elements returned from the CreateStore method.
+// CreateStoreResponse contains the response elements returned from the CreateStore method. The Properties field is
+// populated from PropertiesString in the same way as GetCertificateStoreByID, so a created or updated store can be
+// compared directly against one read back from Keyfactor.
 type CreateStoreResponse struct {
-	Id                      string              `json:"Id"`
-	ContainerId             int                 `json:"ContainerId"`
-	ClientMachine           string              `json:"ClientMachine"`
-	Storepath               string              `json:"Storepath"`
-	CertStoreInventoryJobId string              `json:"CertStoreInventoryJobId"`
-	CertStoreType           int                 `json:"cert_store_type"`
-	Approved                bool                `json:"Approved"`
-	CreateIfMissing         bool                `json:"CreateIfMissing"`
-	PropertiesString        string              `json:"Properties"`
-	Properties              map[string]string   `json:"-"`
-	AgentId                 string              `json:"AgentId"`
-	AgentAssigned           bool                `json:"AgentAssigned"`
-	ContainerName           string              `json:"ContainerName"`
-	InventorySchedule       InventorySchedule   `json:"InventorySchedule"`
-	ReenrollmentStatus      ReEnrollmnentConfig `json:"ReenrollmentStatus"`
-	SetNewPasswordAllowed   bool                `json:"SetNewPasswordAllowed"`
+	Id                      string                 `json:"Id"`
+	DisplayName             string                 `json:"DisplayName"`
+	ContainerId             int                    `json:"ContainerId"`
+	ClientMachine           string                 `json:"ClientMachine"`
+	Storepath               string                 `json:"Storepath"`
+	CertStoreInventoryJobId string                 `json:"CertStoreInventoryJobId"`
+	CertStoreType           int                    `json:"CertStoreType" jsonalias:"StoreType,StoreTypeId"`
+	Approved                bool                   `json:"Approved"`
+	CreateIfMissing         bool                   `json:"CreateIfMissing"`
+	PropertiesString        string                 `json:"Properties"`
+	Properties              map[string]interface{} `json:"-"`
+	AgentId                 string                 `json:"AgentId"`
+	AgentAssigned           bool                   `json:"AgentAssigned"`
+	ContainerName           string                 `json:"ContainerName"`
+	InventorySchedule       InventorySchedule      `json:"InventorySchedule"`
+	ReenrollmentStatus      ReEnrollmnentConfig    `json:"ReenrollmentStatus"`
+	SetNewPasswordAllowed   bool                   `json:"SetNewPasswordAllowed"`
+	Password                StorePasswordConfig    `json:"Password"`
 }
 
 // UpdateStoreResponse contains the response elements returned from the UpdateStore method.
@@ -209,19 +261,53 @@ type AddCertificateToStore struct {
 	// and provide appropriate reference information for the certificate in the store.
 	CertificateStores *[]CertificateStore `json:"CertificateStores,omitempty"`
 
-	// The inventory schedule for the add job
+	// The schedule for the add job, e.g. ImmediateSchedule() or ScheduleAt(t). Keyfactor runs the job immediately
+	// if it is not set.
 	InventorySchedule *InventorySchedule `json:"Schedule,omitempty"`
 
 	// An integer containing the Keyfactor Command reference ID of the certificate to be added to the certificate store(s).
 	CollectionId int `json:"CollectionId,omitempty"`
+
+	// MaintenanceWindow, if set, requires the add job to run within the window. A job scheduled outside it is
+	// rejected with ErrOutsideMaintenanceWindow, or moved to the next opening if the window allows deferring.
+	MaintenanceWindow *MaintenanceWindow `json:"-"`
+
+	// ValidateAliases makes AddCertificateToStores check the alias of each store against the CustomAliasAllowed
+	// setting of the store's type before scheduling any job, instead of the jobs of those stores failing on the
+	// orchestrator.
+	ValidateAliases bool `json:"-"`
+}
+
+// ReenrollCertificateStoreArgs holds the function arguments used for calling the ReenrollCertificateStore method.
+type ReenrollCertificateStoreArgs struct {
+	// The GUID of the certificate store to reenroll a certificate in.
+	CertificateStoreId string `json:"KeystoreId"`
+	// The subject of the certificate to enroll, e.g. "CN=www.example.com,O=Example".
+	SubjectName string `json:"SubjectName"`
+	// The GUID of the orchestrator that generates the key pair. Defaults to the orchestrator of the store.
+	AgentId string `json:"AgentGuid,omitempty"`
+	// The alias of the certificate in the store. Whether it may, must, or must not be given depends on the
+	// CustomAliasAllowed setting of the store's type.
+	Alias string `json:"Alias,omitempty"`
+	// Entry parameters of the store type, by name.
+	EntryParameters map[string]interface{} `json:"JobProperties,omitempty"`
+	// The certificate authority to enroll with, e.g. "DC-CA.example.com\\Example Issuing CA", and the template to
+	// enroll for. Keyfactor picks them if they are not set.
+	CertificateAuthority string `json:"CertificateAuthority,omitempty"`
+	CertificateTemplate  string `json:"CertificateTemplate,omitempty"`
 }
 
 // RemoveCertificateFromStore contains configuration data required to remove a certificate associated with a specific
 // alias from one or more certificate stores.
 type RemoveCertificateFromStore struct {
 	// An integer containing the Keyfactor Command reference ID of the certificate to be removed to the certificate store(s).
-	CertificateId int    `json:"CertificateId"`
-	Alias         string `json:"Alias"`
+	CertificateId int `json:"CertificateId"`
+	// The thumbprint of the certificate to be removed. Like CertificateId, it is used to look up the alias in each
+	// store's inventory when no alias is given.
+	Thumbprint string `json:"-"`
+	// The alias to remove from every store that does not set its own CertificateStore.Alias. When neither is set, the
+	// alias is resolved from the store's inventory using CertificateId or Thumbprint.
+	Alias string `json:"Alias"`
 	// An array of certificate store GUIDs to identify the certificate stores to which the certificate should be removed
 	// and provide appropriate reference information for the certificate in the store.
 	CertificateStores *[]CertificateStore `json:"CertificateStores,omitempty"`
@@ -253,6 +339,108 @@ type CertificateStore struct {
 
 	// A Boolean that sets whether to include the private key of the certificate in the certificate store if private keys are optional for the given certificate store (true) or not (false). The default is false.
 	IncludePrivateKey bool `json:"IncludePrivateKey,omitempty"`
+
+	// Entry parameters defined by the store type for this store, keyed by parameter name (e.g. the site and binding
+	// of an IIS store, see IISBinding). Sent to Keyfactor as the entry's JobFields.
+	EntryParameters map[string]interface{} `json:"JobFields,omitempty"`
+}
+
+// IISBinding describes the IIS site binding a certificate is deployed to. Use EntryParameters to convert it to the
+// entry parameters of a CertificateStore.
+type IISBinding struct {
+	SiteName  string
+	IPAddress string
+	Port      int
+	HostName  string
+	// SniFlag is the IIS SSL flags value: 0 for no SNI, 1 for SNI, 2 for a centralized certificate store binding, and
+	// 3 for both.
+	SniFlag  int
+	Protocol string
+}
+
+// StoreProperties is implemented by the typed properties of the built-in certificate store types, such as
+// IISUStoreProperties. Set the Properties of CreateStoreFctArgs to the result of its StoreProperties method.
+type StoreProperties interface {
+	// StoreTypeShortName returns the short name of the store type the properties belong to.
+	StoreTypeShortName() string
+	// StoreProperties returns the properties keyed by the names the store type defines them with.
+	StoreProperties() map[string]interface{}
+}
+
+// WinRMProperties are the properties of the Windows store types, whose orchestrator reaches the server with WinRM.
+type WinRMProperties struct {
+	// ServerUsername and ServerPassword are the credentials used to reach the server. Leave them nil to connect as
+	// the orchestrator's service account.
+	ServerUsername *StoreSecret
+	ServerPassword *StoreSecret
+	// ServerUseSsl connects to the server with WinRM over HTTPS.
+	ServerUseSsl bool
+	// WinRMProtocol is "http" or "https", and WinRMPort the port WinRM listens on. They default to "https" and 5986.
+	WinRMProtocol string
+	WinRMPort     int
+	// SPNWithPort includes the port in the service principal name used to authenticate with Kerberos.
+	SPNWithPort bool
+}
+
+// IISUStoreProperties are the properties of an IIS bound certificate store (store type IISU). The binding of each
+// certificate is given by its entry parameters; see IISBinding.
+type IISUStoreProperties struct {
+	WinRMProperties
+}
+
+// WinCertStoreProperties are the properties of a Windows certificate store (store type WinCert). The StorePath of the
+// store is the name of the certificate store on the server, such as "My".
+type WinCertStoreProperties struct {
+	WinRMProperties
+}
+
+// WinCertEntryParameters are the entry parameters of a certificate added to a WinCert store. Use EntryParameters to
+// convert them to the entry parameters of a CertificateStore.
+type WinCertEntryParameters struct {
+	// ProviderName is the cryptographic provider to store the private key with, such as
+	// "Microsoft Enhanced RSA and AES Cryptographic Provider". Empty uses the server's default.
+	ProviderName string
+	// SAN is the subject alternative names to request on reenrollment, such as "dns=www.example.com&dns=example.com".
+	SAN string
+}
+
+// RemoteFileProperties are the properties shared by the remote file store types, whose orchestrator reaches the
+// server with SSH or, for Windows servers, WinRM.
+type RemoteFileProperties struct {
+	// ServerUsername and ServerPassword are the credentials used to reach the server. The password may instead be
+	// an SSH private key.
+	ServerUsername *StoreSecret
+	ServerPassword *StoreSecret
+	// ServerUseSsl connects to Windows servers with WinRM over HTTPS.
+	ServerUseSsl bool
+	// LinuxFilePermissionsOnStoreCreation and LinuxFileOwnerOnStoreCreation set the mode, e.g. "600", and owner of a
+	// store file the orchestrator creates.
+	LinuxFilePermissionsOnStoreCreation string
+	LinuxFileOwnerOnStoreCreation       string
+	// SudoImpersonatingUser is the user to run commands on the server as with sudo.
+	SudoImpersonatingUser string
+}
+
+// JKSStoreProperties are the properties of a Java keystore (store type RFJKS). The password of the keystore is the
+// store password of CreateStoreFctArgs.
+type JKSStoreProperties struct {
+	RemoteFileProperties
+}
+
+// PEMStoreProperties are the properties of a PEM file certificate store (store type RFPEM).
+type PEMStoreProperties struct {
+	RemoteFileProperties
+	// IsTrustStore marks a store holding only certificates, without private keys.
+	IsTrustStore bool
+	// IncludesChain stores the chain of each certificate after it.
+	IncludesChain bool
+	// SeparatePrivateKeyFilePath is the path of the file holding the private key, if it is not stored in the store
+	// file itself.
+	SeparatePrivateKeyFilePath string
+	// IsRSAPrivateKey stores the private key in PKCS#1 format rather than PKCS#8.
+	IsRSAPrivateKey bool
+	// IgnorePrivateKeyOnInventory inventories the certificates without reading the private key.
+	IgnorePrivateKeyOnInventory bool
 }
 
 type ListCertificateStoresResponse struct {
@@ -268,23 +456,44 @@ type CertStoreInventory struct {
 	CertStoreInventoryItemId int                      `json:"CertStoreInventoryItemId"`
 	Name                     string                   `json:"Name,omitempty"`
 	Certificates             []InventoriedCertificate `json:"Certificates,omitempty"`
-	Thumbprints              map[string]bool          `json:"-"`
-	Serials                  map[string]bool          `json:"-"`
-	Ids                      map[int]bool             `json:"-"`
 	Properties               map[string]interface{}   `json:"-"`
-	Parameters               map[string]interface{}   `json:"-"`
+	// Parameters are the entry parameters of the item as Keyfactor reports them. Numbers are json.Number values.
+	Parameters map[string]interface{} `json:"-"`
+
+	// thumbprints and serials index Certificates by normalized thumbprint and serial number. They are built by
+	// GetCertStoreInventory; the query methods search Certificates when they are nil.
+	thumbprints map[string]bool
+	serials     map[string]bool
+}
+
+// certStoreInventoryJSON is an item of a certificate store inventory as returned by Keyfactor, decoded with numbers
+// as json.Number.
+type certStoreInventoryJSON struct {
+	Name         string `json:"Name"`
+	Certificates []struct {
+		Id                       json.Number `json:"Id"`
+		IssuedDN                 string      `json:"IssuedDN"`
+		SerialNumber             string      `json:"SerialNumber"`
+		NotBefore                Timestamp   `json:"NotBefore"`
+		NotAfter                 Timestamp   `json:"NotAfter"`
+		SigningAlgorithm         string      `json:"SigningAlgorithm"`
+		IssuerDN                 string      `json:"IssuerDN"`
+		Thumbprint               string      `json:"Thumbprint"`
+		CertStoreInventoryItemId json.Number `json:"CertStoreInventoryItemId"`
+	} `json:"Certificates"`
+	Parameters map[string]interface{} `json:"Parameters"`
 }
 
 type InventoriedCertificate struct {
-	Id                       int    `json:"Id"`
-	IssuedDN                 string `json:"IssuedDN"`
-	SerialNumber             string `json:"SerialNumber"`
-	NotBefore                string `json:"NotBefore"`
-	NotAfter                 string `json:"NotAfter"`
-	SigningAlgorithm         string `json:"SigningAlgorithm"`
-	IssuerDN                 string `json:"IssuerDN"`
-	Thumbprint               string `json:"Thumbprint"`
-	CertStoreInventoryItemId int    `json:"CertStoreInventoryItemId"`
+	Id                       int       `json:"Id"`
+	IssuedDN                 string    `json:"IssuedDN"`
+	SerialNumber             string    `json:"SerialNumber"`
+	NotBefore                Timestamp `json:"NotBefore"`
+	NotAfter                 Timestamp `json:"NotAfter"`
+	SigningAlgorithm         string    `json:"SigningAlgorithm"`
+	IssuerDN                 string    `json:"IssuerDN"`
+	Thumbprint               string    `json:"Thumbprint"`
+	CertStoreInventoryItemId int       `json:"CertStoreInventoryItemId"`
 }
 
 type EntryPassword struct {
@@ -0,0 +1,109 @@
+package api
+
+import "strconv"
+
+// Keyfactor sends Bool and MultipleChoice properties as strings, so the typed properties are converted the same way.
+
+// StoreTypeShortName returns "IISU".
+func (p IISUStoreProperties) StoreTypeShortName() string {
+	return "IISU"
+}
+
+// StoreProperties returns the properties of the IISU store.
+func (p IISUStoreProperties) StoreProperties() map[string]interface{} {
+	return p.WinRMProperties.properties()
+}
+
+// StoreTypeShortName returns "WinCert".
+func (p WinCertStoreProperties) StoreTypeShortName() string {
+	return "WinCert"
+}
+
+// StoreProperties returns the properties of the WinCert store.
+func (p WinCertStoreProperties) StoreProperties() map[string]interface{} {
+	return p.WinRMProperties.properties()
+}
+
+func (p WinRMProperties) properties() map[string]interface{} {
+	props := map[string]interface{}{
+		"ServerUseSsl":   strconv.FormatBool(p.ServerUseSsl),
+		"WinRM Protocol": p.WinRMProtocol,
+		"WinRM Port":     strconv.Itoa(p.WinRMPort),
+		"spnwithport":    strconv.FormatBool(p.SPNWithPort),
+	}
+	if p.WinRMProtocol == "" {
+		props["WinRM Protocol"] = "https"
+	}
+	if p.WinRMPort == 0 {
+		props["WinRM Port"] = "5986"
+	}
+	addSecretProperty(props, "ServerUsername", p.ServerUsername)
+	addSecretProperty(props, "ServerPassword", p.ServerPassword)
+	return props
+}
+
+// EntryParameters returns the entry parameters expected by WinCert certificate stores.
+func (e WinCertEntryParameters) EntryParameters() map[string]interface{} {
+	params := map[string]interface{}{}
+	if e.ProviderName != "" {
+		params["ProviderName"] = e.ProviderName
+	}
+	if e.SAN != "" {
+		params["SAN"] = e.SAN
+	}
+	return params
+}
+
+// StoreTypeShortName returns "RFJKS".
+func (p JKSStoreProperties) StoreTypeShortName() string {
+	return "RFJKS"
+}
+
+// StoreProperties returns the properties of the RFJKS store.
+func (p JKSStoreProperties) StoreProperties() map[string]interface{} {
+	return p.RemoteFileProperties.properties()
+}
+
+// StoreTypeShortName returns "RFPEM".
+func (p PEMStoreProperties) StoreTypeShortName() string {
+	return "RFPEM"
+}
+
+// StoreProperties returns the properties of the RFPEM store.
+func (p PEMStoreProperties) StoreProperties() map[string]interface{} {
+	props := p.RemoteFileProperties.properties()
+	props["IsTrustStore"] = strconv.FormatBool(p.IsTrustStore)
+	props["IncludesChain"] = strconv.FormatBool(p.IncludesChain)
+	props["IsRSAPrivateKey"] = strconv.FormatBool(p.IsRSAPrivateKey)
+	props["IgnorePrivateKeyOnInventory"] = strconv.FormatBool(p.IgnorePrivateKeyOnInventory)
+	if p.SeparatePrivateKeyFilePath != "" {
+		props["SeparatePrivateKeyFilePath"] = p.SeparatePrivateKeyFilePath
+	}
+	return props
+}
+
+func (p RemoteFileProperties) properties() map[string]interface{} {
+	props := map[string]interface{}{
+		"ServerUseSsl": strconv.FormatBool(p.ServerUseSsl),
+	}
+	if p.LinuxFilePermissionsOnStoreCreation != "" {
+		props["LinuxFilePermissionsOnStoreCreation"] = p.LinuxFilePermissionsOnStoreCreation
+	}
+	if p.LinuxFileOwnerOnStoreCreation != "" {
+		props["LinuxFileOwnerOnStoreCreation"] = p.LinuxFileOwnerOnStoreCreation
+	}
+	if p.SudoImpersonatingUser != "" {
+		props["SudoImpersonatingUser"] = p.SudoImpersonatingUser
+	}
+	addSecretProperty(props, "ServerUsername", p.ServerUsername)
+	addSecretProperty(props, "ServerPassword", p.ServerPassword)
+	return props
+}
+
+// addSecretProperty sets the Secret property name of props to secret, if it is not nil. Keyfactor expects the value of
+// a Secret property to be the secret itself or a reference to a PAM provider.
+func addSecretProperty(props map[string]interface{}, name string, secret *StoreSecret) {
+	if secret != nil {
+		props[name] = *secret
+	}
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStoreProperties(t *testing.T) {
+	tests := []struct {
+		name      string
+		props     StoreProperties
+		shortName string
+		want      string
+	}{
+		{
+			name:      "IISUDefaults",
+			props:     IISUStoreProperties{},
+			shortName: "IISU",
+			want:      `{"ServerUseSsl":{"value":"false"},"WinRM Port":{"value":"5986"},"WinRM Protocol":{"value":"https"},"spnwithport":{"value":"false"}}`,
+		},
+		{
+			name: "WinCertWithCredentials",
+			props: WinCertStoreProperties{WinRMProperties{
+				ServerUsername: &StoreSecret{SecretValue: `EXAMPLE\svc-kf`},
+				ServerPassword: &StoreSecret{Provider: 2, Parameters: map[string]string{"SecretId": "winrm"}},
+				ServerUseSsl:   true,
+				WinRMProtocol:  "http",
+				WinRMPort:      5985,
+			}},
+			shortName: "WinCert",
+			want: `{"ServerPassword":{"value":{"Provider":2,"Parameters":{"SecretId":"winrm"}}},` +
+				`"ServerUseSsl":{"value":"true"},"ServerUsername":{"value":{"SecretValue":"EXAMPLE\\svc-kf"}},` +
+				`"WinRM Port":{"value":"5985"},"WinRM Protocol":{"value":"http"},"spnwithport":{"value":"false"}}`,
+		},
+		{
+			name:      "JKS",
+			props:     JKSStoreProperties{RemoteFileProperties{LinuxFilePermissionsOnStoreCreation: "600"}},
+			shortName: "RFJKS",
+			want:      `{"LinuxFilePermissionsOnStoreCreation":{"value":"600"},"ServerUseSsl":{"value":"false"}}`,
+		},
+		{
+			name:      "PEMSeparateKey",
+			props:     PEMStoreProperties{SeparatePrivateKeyFilePath: "/etc/ssl/private/app.key", IncludesChain: true},
+			shortName: "RFPEM",
+			want: `{"IgnorePrivateKeyOnInventory":{"value":"false"},"IncludesChain":{"value":"true"},` +
+				`"IsRSAPrivateKey":{"value":"false"},"IsTrustStore":{"value":"false"},` +
+				`"SeparatePrivateKeyFilePath":{"value":"/etc/ssl/private/app.key"},"ServerUseSsl":{"value":"false"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.props.StoreTypeShortName(); got != tt.shortName {
+				t.Errorf("StoreTypeShortName() = %q, want %q", got, tt.shortName)
+			}
+			got, err := json.Marshal(buildPropertiesInterface(tt.props.StoreProperties()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("StoreProperties() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWinCertEntryParameters(t *testing.T) {
+	got, _ := json.Marshal(WinCertEntryParameters{SAN: "dns=www.example.com"}.EntryParameters())
+	if want := `{"SAN":"dns=www.example.com"}`; string(got) != want {
+		t.Errorf("EntryParameters() = %s, want %s", got, want)
+	}
+}
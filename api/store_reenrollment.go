@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ReenrollCertificateStore takes arguments for a ReenrollCertificateStoreArgs structure to facilitate a call to
+// Keyfactor that schedules a reenrollment job: the orchestrator of the certificate store generates a key pair,
+// Keyfactor enrolls for a certificate with it, and the certificate is placed in the store.
+//
+// The store's type is checked first, so that a job that would only fail on the orchestrator is not scheduled: an error
+// is returned if the type does not support enrollment, and one wrapping ErrAliasRequired or ErrAliasForbidden if the
+// alias does not meet the type's CustomAliasAllowed setting.
+func (c *Client) ReenrollCertificateStore(args *ReenrollCertificateStoreArgs) error {
+	return c.ReenrollCertificateStoreContext(context.Background(), args)
+}
+
+// ReenrollCertificateStoreContext is like ReenrollCertificateStore but uses ctx for the requests, allowing it to be
+// cancelled.
+func (c *Client) ReenrollCertificateStoreContext(ctx context.Context, args *ReenrollCertificateStoreArgs) error {
+	if args == nil || args.SubjectName == "" {
+		return errors.New("subject name is required to reenroll a certificate store")
+	}
+	c.infof("Scheduling reenrollment of certificate store %s", args.CertificateStoreId)
+
+	store, err := c.GetCertificateStoreByIDContext(ctx, args.CertificateStoreId)
+	if err != nil {
+		return err
+	}
+	storeType, err := c.cachedStoreTypeById(ctx, store.CertStoreType)
+	if err != nil {
+		return err
+	}
+	if storeType.SupportedOperations != nil && !storeType.SupportedOperations.Enrollment {
+		return fmt.Errorf("unable to reenroll certificate store %s: certificate store type %s does not support enrollment", store.Id, storeType.ShortName)
+	}
+	if err := storeType.ValidateAlias(args.Alias); err != nil {
+		return fmt.Errorf("unable to reenroll certificate store %s: %w", store.Id, err)
+	}
+
+	payload := *args
+	if payload.AgentId == "" {
+		payload.AgentId = store.AgentId
+	}
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "POST",
+		Endpoint: "CertificateStores/Reenrollment",
+		Headers:  headers,
+		Payload:  &payload,
+		Context:  ctx,
+	}
+
+	_, err = c.sendRequest(keyfactorAPIStruct)
+	return err
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Keyfactor/keyfactor-go-client/enums"
+)
+
+// ErrOutsideMaintenanceWindow is returned by AddCertificateToStores when the management job would run outside the
+// configured maintenance window and the window does not allow deferring it.
+var ErrOutsideMaintenanceWindow = errors.New("job would run outside the maintenance window")
+
+// MaintenanceWindow is a recurring daily period during which changes to certificate stores are approved, e.g. 22:00
+// to 02:00 on weekdays.
+type MaintenanceWindow struct {
+	// Start and End are the times of day the window opens and closes, as "15:04". A window whose End is not after its
+	// Start runs past midnight.
+	Start string
+	End   string
+	// Days lists the days on which the window opens. Every day is used if it is empty.
+	Days []time.Weekday
+	// Location is the time zone of Start and End. Defaults to UTC.
+	Location *time.Location
+	// Defer schedules a job that would run outside the window for when the window next opens, instead of failing
+	// with ErrOutsideMaintenanceWindow.
+	Defer bool
+}
+
+// ImmediateSchedule returns a job schedule that runs the job as soon as an orchestrator picks it up.
+func ImmediateSchedule() *InventorySchedule {
+	immediate := true
+	return &InventorySchedule{Immediate: &immediate}
+}
+
+// ScheduleAt returns a job schedule that runs the job once, at t.
+func ScheduleAt(t time.Time) *InventorySchedule {
+	return &InventorySchedule{ExactlyOnce: &InventoryOnce{Time: t.UTC().Format(time.RFC3339)}}
+}
+
+// Type returns the kind of the schedule, or enums.ScheduleTypeOff for a nil or empty schedule.
+func (s *InventorySchedule) Type() enums.ScheduleType {
+	switch {
+	case s == nil:
+		return enums.ScheduleTypeOff
+	case s.Immediate != nil && *s.Immediate:
+		return enums.ScheduleTypeImmediate
+	case s.Interval != nil:
+		return enums.ScheduleTypeInterval
+	case s.Daily != nil:
+		return enums.ScheduleTypeDaily
+	case s.Weekly != nil:
+		return enums.ScheduleTypeWeekly
+	case s.Monthly != nil:
+		return enums.ScheduleTypeMonthly
+	case s.ExactlyOnce != nil:
+		return enums.ScheduleTypeExactlyOnce
+	}
+	return enums.ScheduleTypeOff
+}
+
+// Contains reports whether t falls within the window.
+func (w *MaintenanceWindow) Contains(t time.Time) (bool, error) {
+	start, end, err := w.bounds()
+	if err != nil {
+		return false, err
+	}
+	t = t.In(w.location())
+	// A window running past midnight may have opened the day before.
+	for _, dayOffset := range []int{0, -1} {
+		day := time.Date(t.Year(), t.Month(), t.Day()+dayOffset, 0, 0, 0, 0, t.Location())
+		if !w.opensOn(day.Weekday()) {
+			continue
+		}
+		opens, closes := w.span(day, start, end)
+		if !t.Before(opens) && t.Before(closes) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Next returns t if it falls within the window, and otherwise the time the window next opens after t.
+func (w *MaintenanceWindow) Next(t time.Time) (time.Time, error) {
+	ok, err := w.Contains(t)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if ok {
+		return t, nil
+	}
+	start, end, _ := w.bounds()
+	local := t.In(w.location())
+	for dayOffset := 0; dayOffset <= 7; dayOffset++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+dayOffset, 0, 0, 0, 0, local.Location())
+		if !w.opensOn(day.Weekday()) {
+			continue
+		}
+		if opens, _ := w.span(day, start, end); opens.After(t) {
+			return opens, nil
+		}
+	}
+	return time.Time{}, errors.New("maintenance window never opens")
+}
+
+// bounds parses the window's start and end as offsets from midnight.
+func (w *MaintenanceWindow) bounds() (time.Duration, time.Duration, error) {
+	start, err := time.Parse("15:04", w.Start)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid maintenance window start %q: %w", w.Start, err)
+	}
+	end, err := time.Parse("15:04", w.End)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid maintenance window end %q: %w", w.End, err)
+	}
+	return time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute,
+		time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute, nil
+}
+
+// span returns when the window opening on day opens and closes.
+func (w *MaintenanceWindow) span(day time.Time, start, end time.Duration) (time.Time, time.Time) {
+	if end <= start {
+		end += 24 * time.Hour
+	}
+	return day.Add(start), day.Add(end)
+}
+
+func (w *MaintenanceWindow) opensOn(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *MaintenanceWindow) location() *time.Location {
+	if w.Location == nil {
+		return time.UTC
+	}
+	return w.Location
+}
+
+// jobSchedule returns the schedule to send for a management job given the requested schedule and maintenance window.
+// A nil or immediate schedule runs at now; an ExactlyOnce schedule runs at its time. Recurring schedules cannot be
+// checked against a window.
+func jobSchedule(schedule *InventorySchedule, window *MaintenanceWindow, now time.Time) (*InventorySchedule, error) {
+	if window == nil {
+		return schedule, nil
+	}
+
+	runAt := now
+	switch {
+	case schedule == nil, schedule.Immediate != nil && *schedule.Immediate:
+	case schedule.ExactlyOnce != nil:
+		t, err := time.Parse(time.RFC3339, schedule.ExactlyOnce.Time)
+		if err != nil {
+			return nil, fmt.Errorf("invalid job schedule time %q: %w", schedule.ExactlyOnce.Time, err)
+		}
+		runAt = t
+	default:
+		return nil, errors.New("only immediate or one-time job schedules can be checked against a maintenance window")
+	}
+
+	ok, err := window.Contains(runAt)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return schedule, nil
+	}
+	if !window.Defer {
+		return nil, fmt.Errorf("%w: %s is not between %s and %s", ErrOutsideMaintenanceWindow, runAt.In(window.location()).Format("Mon 15:04 MST"), window.Start, window.End)
+	}
+	next, err := window.Next(runAt)
+	if err != nil {
+		return nil, err
+	}
+	return ScheduleAt(next), nil
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindow_Next(t *testing.T) {
+	weeknights := &MaintenanceWindow{
+		Start: "22:00",
+		End:   "02:00",
+		Days:  []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	}
+	// 2024-05-06 is a Monday.
+	at := func(day, hour, min int) time.Time { return time.Date(2024, 5, day, hour, min, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name   string
+		window *MaintenanceWindow
+		t      time.Time
+		want   time.Time
+	}{
+		{name: "InsideBeforeMidnight", window: weeknights, t: at(6, 23, 0), want: at(6, 23, 0)},
+		{name: "InsideAfterMidnight", window: weeknights, t: at(7, 1, 30), want: at(7, 1, 30)},
+		{name: "SameDayOpening", window: weeknights, t: at(6, 12, 0), want: at(6, 22, 0)},
+		{name: "ClosedAtEnd", window: weeknights, t: at(7, 2, 0), want: at(7, 22, 0)},
+		{name: "SaturdayMorningAfterFriday", window: weeknights, t: at(11, 1, 0), want: at(11, 1, 0)},
+		{name: "WeekendSkipsToMonday", window: weeknights, t: at(11, 23, 0), want: at(13, 22, 0)},
+		{name: "SameDayWindowEveryDay", window: &MaintenanceWindow{Start: "09:00", End: "17:00"}, t: at(6, 17, 30), want: at(7, 9, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.window.Next(tt.t)
+			if err != nil {
+				t.Fatalf("Next() error = %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (&MaintenanceWindow{Start: "10pm", End: "02:00"}).Contains(at(6, 0, 0)); err == nil {
+		t.Errorf("Contains() with invalid start succeeded, want error")
+	}
+}
+
+func TestClient_AddCertificateToStores_MaintenanceWindow(t *testing.T) {
+	var schedule map[string]interface{}
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Schedule map[string]interface{}
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		schedule = body.Schedule
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`["job-1"]`))
+	})
+
+	now := time.Now().UTC()
+	// A window that opened an hour ago and one that opens in two hours, both for today only.
+	open := &MaintenanceWindow{Start: now.Add(-time.Hour).Format("15:04"), End: now.Add(time.Hour).Format("15:04")}
+	later := &MaintenanceWindow{Start: now.Add(2 * time.Hour).Format("15:04"), End: now.Add(3 * time.Hour).Format("15:04")}
+	stores := &[]CertificateStore{{CertificateStoreId: "s1", Alias: "web"}}
+
+	if _, err := c.AddCertificateToStores(&AddCertificateToStore{CertificateId: 1, CertificateStores: stores, InventorySchedule: ImmediateSchedule(), MaintenanceWindow: open}); err != nil {
+		t.Fatalf("AddCertificateToStores() inside window error = %v", err)
+	}
+	if schedule["Immediate"] != true {
+		t.Errorf("schedule inside window = %v, want immediate", schedule)
+	}
+
+	schedule = nil
+	_, err := c.AddCertificateToStoresContext(context.Background(), &AddCertificateToStore{CertificateId: 1, CertificateStores: stores, MaintenanceWindow: later})
+	if !errors.Is(err, ErrOutsideMaintenanceWindow) {
+		t.Fatalf("AddCertificateToStores() outside window error = %v, want ErrOutsideMaintenanceWindow", err)
+	}
+	if schedule != nil {
+		t.Errorf("a job was scheduled outside the window")
+	}
+
+	later.Defer = true
+	if _, err := c.AddCertificateToStores(&AddCertificateToStore{CertificateId: 1, CertificateStores: stores, MaintenanceWindow: later}); err != nil {
+		t.Fatalf("AddCertificateToStores() deferred error = %v", err)
+	}
+	once, _ := schedule["ExactlyOnce"].(map[string]interface{})
+	want := now.Add(2 * time.Hour).Truncate(time.Minute)
+	if once == nil || once["Time"] != want.Format(time.RFC3339) {
+		t.Errorf("deferred schedule = %v, want ExactlyOnce at %s", schedule, want.Format(time.RFC3339))
+	}
+
+	daily := &InventorySchedule{Daily: &InventoryDaily{Time: "2024-01-01T02:00:00Z"}}
+	if _, err := c.AddCertificateToStores(&AddCertificateToStore{CertificateId: 1, CertificateStores: stores, InventorySchedule: daily, MaintenanceWindow: open}); err == nil {
+		t.Errorf("AddCertificateToStores() with a recurring schedule and a window succeeded, want error")
+	}
+}
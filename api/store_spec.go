@@ -0,0 +1,253 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SecretMask replaces the value of secret properties in a StoreSpec. Keyfactor never returns secret values, so they
+// cannot take part in drift detection.
+const SecretMask = "********"
+
+// StoreSpec is the canonical form of the configuration of a certificate store, for tools such as Terraform providers
+// that compare the store declared in a configuration with the store in Keyfactor. Two stores with the same
+// configuration have the same StoreSpec and Hash, whether it was built from the arguments that created the store or
+// from the store Keyfactor returns:
+//   - ClientMachine and AgentId are lower case, as Keyfactor compares them case-insensitively.
+//   - Property values are strings, as Keyfactor returns them, and properties with no value are left out.
+//   - Secret properties are SecretMask, or a StoreSecret holding only the PAM provider and its parameters.
+//   - InventorySchedule is nil if inventory is off.
+type StoreSpec struct {
+	CertStoreType     int                    `json:"CertStoreType"`
+	ClientMachine     string                 `json:"ClientMachine"`
+	StorePath         string                 `json:"StorePath"`
+	AgentId           string                 `json:"AgentId"`
+	ContainerId       int                    `json:"ContainerId"`
+	Properties        map[string]interface{} `json:"Properties"`
+	InventorySchedule *InventorySchedule     `json:"InventorySchedule"`
+}
+
+// Spec returns the canonical configuration of the store the arguments create.
+func (ca *CreateStoreFctArgs) Spec() (*StoreSpec, error) {
+	props := ca.Properties
+	if ca.PropertiesString != "" {
+		props = nil
+		if err := json.Unmarshal([]byte(ca.PropertiesString), &props); err != nil {
+			return nil, fmt.Errorf("invalid store properties: %w", err)
+		}
+	}
+	spec := &StoreSpec{
+		CertStoreType: ca.CertStoreType,
+		ClientMachine: strings.ToLower(ca.ClientMachine),
+		StorePath:     ca.StorePath,
+		AgentId:       strings.ToLower(ca.AgentId),
+		Properties:    canonicalProperties(props),
+	}
+	if ca.ContainerId != nil {
+		spec.ContainerId = *ca.ContainerId
+	}
+	if ca.InventorySchedule != nil && !ca.InventorySchedule.Equal(InventorySchedule{}) {
+		schedule := *ca.InventorySchedule
+		spec.InventorySchedule = &schedule
+	}
+	return spec, nil
+}
+
+// Spec returns the canonical configuration of the store.
+func (s *GetCertificateStoreResponse) Spec() (*StoreSpec, error) {
+	props := s.Properties
+	if s.PropertiesString != "" {
+		props = nil
+		if err := json.Unmarshal([]byte(s.PropertiesString), &props); err != nil {
+			return nil, fmt.Errorf("invalid store properties: %w", err)
+		}
+	}
+	spec := &StoreSpec{
+		CertStoreType: s.CertStoreType,
+		ClientMachine: strings.ToLower(s.ClientMachine),
+		StorePath:     s.StorePath,
+		AgentId:       strings.ToLower(s.AgentId),
+		ContainerId:   s.ContainerId,
+		Properties:    canonicalProperties(props),
+	}
+	if !s.InventorySchedule.Equal(InventorySchedule{}) {
+		schedule := s.InventorySchedule
+		spec.InventorySchedule = &schedule
+	}
+	return spec, nil
+}
+
+// CanonicalJSON returns the spec encoded as JSON with object keys sorted.
+func (s *StoreSpec) CanonicalJSON() ([]byte, error) {
+	spec := *s
+	if spec.InventorySchedule != nil && spec.InventorySchedule.Immediate != nil && !*spec.InventorySchedule.Immediate {
+		schedule := *spec.InventorySchedule
+		schedule.Immediate = nil
+		spec.InventorySchedule = &schedule
+	}
+	return json.Marshal(&spec)
+}
+
+// Hash returns the hex encoded SHA-256 digest of the spec's CanonicalJSON.
+func (s *StoreSpec) Hash() (string, error) {
+	data, err := s.CanonicalJSON()
+	if err != nil {
+		return "", err
+	}
+	return hashHex(data), nil
+}
+
+// canonicalProperties returns store properties, as given to CreateStore or returned by Keyfactor, unwrapped from
+// {"value": ...}, with scalar values as strings and secrets masked.
+func canonicalProperties(props map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(props))
+	for name, value := range props {
+		if wrapped, ok := value.(map[string]interface{}); ok && len(wrapped) == 1 {
+			if v, ok := wrapped["value"]; ok {
+				value = v
+			}
+		}
+		switch v := value.(type) {
+		case nil:
+		case string:
+			if v != "" {
+				out[name] = v
+			}
+		case StoreSecret:
+			out[name] = canonicalSecret(v.Provider, v.Parameters)
+		case *StoreSecret:
+			if v != nil {
+				out[name] = canonicalSecret(v.Provider, v.Parameters)
+			}
+		case map[string]interface{}:
+			out[name] = canonicalSecret(secretProvider(v), secretParameters(v))
+		default:
+			out[name] = fmt.Sprint(v)
+		}
+	}
+	return out
+}
+
+// canonicalSecret returns the masked form of a secret property: its PAM provider reference, or SecretMask for a
+// secret held in the Keyfactor database.
+func canonicalSecret(provider int, params map[string]string) interface{} {
+	if provider == 0 {
+		return SecretMask
+	}
+	ref := &StoreSecret{Provider: provider}
+	if len(params) > 0 {
+		ref.Parameters = params
+	}
+	return ref
+}
+
+// secretProvider returns the PAM provider of a secret property as Keyfactor returns it, or 0 if there is none.
+func secretProvider(secret map[string]interface{}) int {
+	for _, key := range []string{"Provider", "ProviderId"} {
+		if id, ok := secret[key].(float64); ok && id > 0 {
+			return int(id)
+		}
+	}
+	return 0
+}
+
+// secretParameters returns the PAM provider parameters of a secret property as Keyfactor returns it, which are either
+// a name-value object or a ProviderTypeParameterValues list.
+func secretParameters(secret map[string]interface{}) map[string]string {
+	params := map[string]string{}
+	if values, ok := secret["Parameters"].(map[string]interface{}); ok {
+		for k, v := range values {
+			params[k] = fmt.Sprint(v)
+		}
+	}
+	if values, ok := secret["ProviderTypeParameterValues"].([]interface{}); ok {
+		for _, v := range values {
+			entry, _ := v.(map[string]interface{})
+			param, _ := entry["ProviderTypeParam"].(map[string]interface{})
+			name, _ := param["Name"].(string)
+			if name != "" && entry["Value"] != nil {
+				params[name] = fmt.Sprint(entry["Value"])
+			}
+		}
+	}
+	return params
+}
+
+// CanonicalJSON returns the store type definition encoded as JSON with object keys sorted, properties, entry
+// parameters, and job properties sorted by name, and the IDs Keyfactor assigns to a store type and its custom job
+// types left out, so that the definition in a configuration and the store type installed in Keyfactor encode the same.
+// The default values of secret properties are masked with SecretMask.
+func (t *CertificateStoreType) CanonicalJSON() ([]byte, error) {
+	ct := *t
+	ct.StoreType = 0
+	ct.InventoryJobType, ct.ManagementJobType, ct.DiscoveryJobType, ct.EnrollmentJobType = "", "", "", ""
+
+	if t.Properties != nil && len(*t.Properties) > 0 {
+		props := make([]StoreTypePropertyDefinition, len(*t.Properties))
+		for i, p := range *t.Properties {
+			p.StoreTypeID = 0
+			if p.DefaultValue != nil {
+				p.DefaultValue = fmt.Sprint(p.DefaultValue)
+			}
+			if strings.EqualFold(p.Type, "Secret") && p.DefaultValue != nil && p.DefaultValue != "" {
+				p.DefaultValue = SecretMask
+			}
+			props[i] = p
+		}
+		sort.SliceStable(props, func(i, j int) bool { return props[i].Name < props[j].Name })
+		ct.Properties = &props
+	} else {
+		ct.Properties = nil
+	}
+	if t.EntryParameters != nil && len(*t.EntryParameters) > 0 {
+		params := make([]EntryParameter, len(*t.EntryParameters))
+		for i, p := range *t.EntryParameters {
+			p.StoreTypeId = 0
+			params[i] = p
+		}
+		sort.SliceStable(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+		ct.EntryParameters = &params
+	} else {
+		ct.EntryParameters = nil
+	}
+	if t.JobProperties != nil && len(*t.JobProperties) > 0 {
+		jobProps := append([]string(nil), *t.JobProperties...)
+		sort.Strings(jobProps)
+		ct.JobProperties = &jobProps
+	} else {
+		ct.JobProperties = nil
+	}
+	if len(t.ArgumentFormats) > 0 {
+		var formats interface{}
+		if err := json.Unmarshal(t.ArgumentFormats, &formats); err != nil {
+			return nil, fmt.Errorf("invalid ArgumentFormats: %w", err)
+		}
+		ct.ArgumentFormats = nil
+		if formats != nil {
+			data, err := json.Marshal(formats)
+			if err != nil {
+				return nil, err
+			}
+			ct.ArgumentFormats = data
+		}
+	}
+	return json.Marshal(&ct)
+}
+
+// Hash returns the hex encoded SHA-256 digest of the store type's CanonicalJSON.
+func (t *CertificateStoreType) Hash() (string, error) {
+	data, err := t.CanonicalJSON()
+	if err != nil {
+		return "", err
+	}
+	return hashHex(data), nil
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestStoreSpec_Hash(t *testing.T) {
+	containerId := 3
+	args := &CreateStoreFctArgs{
+		ContainerId: &containerId, ClientMachine: "WEB01.example.com", StorePath: "IIS Personal", CertStoreType: 2,
+		AgentId: "2A5B1C3D-0000-4000-8000-000000000001",
+		Properties: map[string]interface{}{
+			"ServerUseSsl":   true,
+			"WinRm Port":     5986,
+			"spnwithport":    "",
+			"ServerUsername": &StoreSecret{Provider: 4, Parameters: map[string]string{"SecretPath": "win/admin"}},
+			"ServerPassword": &StoreSecret{SecretValue: "hunter2"},
+		},
+		InventorySchedule: &InventorySchedule{Interval: &InventoryInterval{Minutes: 60}},
+	}
+	store := &GetCertificateStoreResponse{
+		Id: "b6e5c0e6", ContainerId: 3, ClientMachine: "web01.example.com", StorePath: "IIS Personal", CertStoreType: 2,
+		AgentId: "2a5b1c3d-0000-4000-8000-000000000001", Approved: true,
+		PropertiesString: `{"WinRm Port": {"value": "5986"}, "ServerUseSsl": {"value": "true"},
+			"ServerUsername": {"value": {"ProviderId": 4, "ProviderTypeParameterValues": [
+				{"Value": "win/admin", "ProviderTypeParam": {"Name": "SecretPath"}}]}},
+			"ServerPassword": {"value": {"SecretValue": null}}}`,
+		InventorySchedule: InventorySchedule{Interval: &InventoryInterval{Minutes: 60}},
+	}
+
+	want := mustStoreHash(t, args.Spec)
+	if got := mustStoreHash(t, store.Spec); got != want {
+		a, _ := args.Spec()
+		b, _ := store.Spec()
+		ja, _ := a.CanonicalJSON()
+		jb, _ := b.CanonicalJSON()
+		t.Fatalf("hash of the store = %s, want %s\nargs:  %s\nstore: %s", got, want, ja, jb)
+	}
+	for i := 0; i < 20; i++ {
+		if got := mustStoreHash(t, args.Spec); got != want {
+			t.Fatalf("hash changed between calls: %s, want %s", got, want)
+		}
+	}
+
+	spec, _ := args.Spec()
+	data, _ := spec.CanonicalJSON()
+	if strings.Contains(string(data), "hunter2") {
+		t.Errorf("CanonicalJSON() = %s, exposes a secret", data)
+	}
+
+	store.PropertiesString = `{"WinRm Port": {"value": "5985"}}`
+	if mustStoreHash(t, store.Spec) == want {
+		t.Errorf("hash unchanged after properties changed")
+	}
+}
+
+func mustStoreHash(t *testing.T, spec func() (*StoreSpec, error)) string {
+	t.Helper()
+	s, err := spec()
+	if err != nil {
+		t.Fatalf("Spec() error = %v", err)
+	}
+	h, err := s.Hash()
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	return h
+}
+
+func TestCertificateStoreType_Hash(t *testing.T) {
+	declared := &CertificateStoreType{
+		Name: "Remote File PEM", ShortName: "RFPEM", Capability: "RFPEM",
+		Properties: &[]StoreTypePropertyDefinition{
+			{Name: "SeparatePrivateKeyFilePath", Type: "String", DefaultValue: ""},
+			{Name: "IsRSAPrivateKey", Type: "Bool", DefaultValue: false},
+		},
+		JobProperties:   &[]string{"b", "a"},
+		ArgumentFormats: json.RawMessage(`{"b": 1, "a": 2}`),
+	}
+	installed := &CertificateStoreType{
+		Name: "Remote File PEM", ShortName: "RFPEM", Capability: "RFPEM", StoreType: 107,
+		Properties: &[]StoreTypePropertyDefinition{
+			{StoreTypeID: 107, Name: "IsRSAPrivateKey", Type: "Bool", DefaultValue: "false"},
+			{StoreTypeID: 107, Name: "SeparatePrivateKeyFilePath", Type: "String", DefaultValue: ""},
+		},
+		JobProperties:     &[]string{"a", "b"},
+		ArgumentFormats:   json.RawMessage(`{"a":2,"b":1}`),
+		InventoryJobType:  "49a5a5fe-4ba2-4c4b-99ca-ba1a6e7ae0e2",
+		ManagementJobType: "ab3b4a35-2c5a-4a4d-9d9b-0dcd6a4e2b6a",
+	}
+	want, err := declared.Hash()
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if got, _ := installed.Hash(); got != want {
+		t.Errorf("hash of the installed store type = %s, want %s", got, want)
+	}
+	if (*installed.Properties)[0].StoreTypeID != 107 {
+		t.Errorf("Hash() modified the store type")
+	}
+
+	installed.PrivateKeyAllowed = "Required"
+	if got, _ := installed.Hash(); got == want {
+		t.Errorf("hash unchanged after PrivateKeyAllowed changed")
+	}
+}
@@ -1,7 +1,11 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
 	"reflect"
 	"testing"
 )
@@ -15,14 +19,57 @@ func TestClient_AddCertificateToStores(t *testing.T) {
 	type args struct {
 		config *AddCertificateToStore
 	}
+	var body map[string]interface{}
+	immediate := true
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/KeyfactorAPI/CertificateStores/Certificates/Add" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`["0c2ba84c-6a1c-4d7d-8b1b-1b3f7e4f0a11", "8d0f3b2a-5c4e-4a3b-9f1e-2d7c6b5a4e33"]`))
+	})
+
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    []string
-		wantErr bool
+		name       string
+		fields     fields
+		args       args
+		want       []string
+		wantStores string
+		wantErr    bool
 	}{
-		// TODO: Add test cases.
+		{
+			name:    "NoStores",
+			fields:  fields{hostname: srv.hostname, httpClient: srv.httpClient},
+			args:    args{config: &AddCertificateToStore{CertificateId: 12}},
+			wantErr: true,
+		},
+		{
+			name:   "PerStoreEntryParameters",
+			fields: fields{hostname: srv.hostname, httpClient: srv.httpClient},
+			args: args{config: &AddCertificateToStore{
+				CertificateId: 12,
+				CertificateStores: &[]CertificateStore{
+					{
+						CertificateStoreId: "iis-store",
+						Alias:              "web",
+						IncludePrivateKey:  true,
+						EntryParameters:    IISBinding{SiteName: "Default Web Site", HostName: "www.example.com", SniFlag: 1}.EntryParameters(),
+					},
+					{
+						CertificateStoreId: "f5-store",
+						Alias:              "web",
+						Overwrite:          true,
+						EntryParameters:    map[string]interface{}{"Partition": "Common"},
+					},
+				},
+				InventorySchedule: &InventorySchedule{Immediate: &immediate},
+			}},
+			want: []string{"0c2ba84c-6a1c-4d7d-8b1b-1b3f7e4f0a11", "8d0f3b2a-5c4e-4a3b-9f1e-2d7c6b5a4e33"},
+			wantStores: `[{"Alias":"web","CertificateStoreId":"iis-store","IncludePrivateKey":true,"JobFields":{"HostName":"www.example.com","IPAddress":"*","Port":"443","Protocol":"https","SiteName":"Default Web Site","SniFlag":"1"},"Overwrite":false},` +
+				`{"Alias":"web","CertificateStoreId":"f5-store","JobFields":{"Partition":"Common"},"Overwrite":true}]`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -30,7 +77,9 @@ func TestClient_AddCertificateToStores(t *testing.T) {
 				hostname:        tt.fields.hostname,
 				httpClient:      tt.fields.httpClient,
 				basicAuthString: tt.fields.basicAuthString,
+				apiPath:         "KeyfactorAPI",
 			}
+			body = nil
 			got, err := c.AddCertificateToStores(tt.args.config)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("AddCertificateToStores() error = %v, wantErr %v", err, tt.wantErr)
@@ -39,6 +88,12 @@ func TestClient_AddCertificateToStores(t *testing.T) {
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("AddCertificateToStores() got = %v, want %v", got, tt.want)
 			}
+			if tt.wantStores != "" {
+				gotStores, _ := json.Marshal(body["CertificateStores"])
+				if string(gotStores) != tt.wantStores {
+					t.Errorf("AddCertificateToStores() sent CertificateStores = %s, want %s", gotStores, tt.wantStores)
+				}
+			}
 		})
 	}
 }
@@ -52,6 +107,26 @@ func TestClient_CreateStore(t *testing.T) {
 	type args struct {
 		ca *CreateStoreFctArgs
 	}
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/KeyfactorAPI/CertificateStores" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"Id": "c2f1f2e4-1c5d-4b6e-9a1e-4f3c2b1a0d9e",
+			"DisplayName": "aks_demo - https://coolvault.vault.azure.net/",
+			"ContainerId": 3,
+			"ClientMachine": "aks_demo",
+			"Storepath": "https://coolvault.vault.azure.net/",
+			"CertStoreType": 106,
+			"Approved": true,
+			"Properties": "{\"VaultName\":{\"value\":\"coolvault\"}}",
+			"AgentId": "b7e0f5a4-8d1f-4e2c-a3b6-0c9d8e7f6a5b",
+			"InventorySchedule": {"Interval": {"Minutes": 60}}
+		}`))
+	})
+
 	tests := []struct {
 		name    string
 		fields  fields
@@ -59,7 +134,44 @@ func TestClient_CreateStore(t *testing.T) {
 		want    *CreateStoreResponse
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name:   "MissingClientMachine",
+			fields: fields{hostname: srv.hostname, httpClient: srv.httpClient},
+			args: args{ca: &CreateStoreFctArgs{
+				StorePath: "https://coolvault.vault.azure.net/",
+				AgentId:   "b7e0f5a4-8d1f-4e2c-a3b6-0c9d8e7f6a5b",
+			}},
+			wantErr: true,
+		},
+		{
+			name:   "FullStoreObject",
+			fields: fields{hostname: srv.hostname, httpClient: srv.httpClient},
+			args: args{ca: &CreateStoreFctArgs{
+				ClientMachine: "aks_demo",
+				StorePath:     "https://coolvault.vault.azure.net/",
+				CertStoreType: 106,
+				AgentId:       "b7e0f5a4-8d1f-4e2c-a3b6-0c9d8e7f6a5b",
+				Properties:    map[string]interface{}{"VaultName": "coolvault"},
+			}},
+			want: &CreateStoreResponse{
+				Id:               "c2f1f2e4-1c5d-4b6e-9a1e-4f3c2b1a0d9e",
+				DisplayName:      "aks_demo - https://coolvault.vault.azure.net/",
+				ContainerId:      3,
+				ClientMachine:    "aks_demo",
+				Storepath:        "https://coolvault.vault.azure.net/",
+				CertStoreType:    106,
+				Approved:         true,
+				PropertiesString: `{"VaultName":{"value":"coolvault"}}`,
+				Properties: map[string]interface{}{
+					"VaultName": map[string]interface{}{"value": "coolvault"},
+				},
+				AgentId: "b7e0f5a4-8d1f-4e2c-a3b6-0c9d8e7f6a5b",
+				InventorySchedule: InventorySchedule{
+					Interval: &InventoryInterval{Minutes: 60},
+				},
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -67,6 +179,7 @@ func TestClient_CreateStore(t *testing.T) {
 				hostname:        tt.fields.hostname,
 				httpClient:      tt.fields.httpClient,
 				basicAuthString: tt.fields.basicAuthString,
+				apiPath:         "KeyfactorAPI",
 			}
 			got, err := c.CreateStore(tt.args.ca)
 			if (err != nil) != tt.wantErr {
@@ -157,6 +270,33 @@ func TestClient_GetCertificateStoreByID(t *testing.T) {
 	type args struct {
 		storeId string
 	}
+	var historyRequests int
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/KeyfactorAPI/CertificateStores/c2f1f2e4-1c5d-4b6e-9a1e-4f3c2b1a0d9e":
+			w.Write([]byte(`{
+				"Id": "c2f1f2e4-1c5d-4b6e-9a1e-4f3c2b1a0d9e",
+				"ClientMachine": "iis01",
+				"Storepath": "My",
+				"CertStoreInventoryJobId": "0f6a2d1e-7b3c-4e5f-8a9b-1c2d3e4f5a6b",
+				"CertStoreType": 5,
+				"InventorySchedule": {"Weekly": {"Days": ["Monday", "Thursday"], "Time": "2023-01-02T03:00:00Z"}},
+				"ReenrollmentStatus": {"Data": true, "AgentId": "b7e0f5a4-8d1f-4e2c-a3b6-0c9d8e7f6a5b", "CustomAliasAllowed": 1},
+				"SetNewPasswordAllowed": true
+			}`))
+		case "/KeyfactorAPI/CertificateStores/5d3c2b1a-0000-4b6e-9a1e-4f3c2b1a0d9e":
+			w.Write([]byte(`{"Id": "5d3c2b1a-0000-4b6e-9a1e-4f3c2b1a0d9e", "ClientMachine": "iis02", "Storepath": "My"}`))
+		case "/KeyfactorAPI/OrchestratorJobs/JobHistory":
+			historyRequests++
+			w.Write([]byte(`[
+				{"JobHistoryId": 7, "JobId": "0f6a2d1e-7b3c-4e5f-8a9b-1c2d3e4f5a6b", "JobType": "Inventory", "Result": 1},
+				{"JobHistoryId": 9, "JobId": "0f6a2d1e-7b3c-4e5f-8a9b-1c2d3e4f5a6b", "JobType": "Inventory", "Result": 3, "Message": "access denied"}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
 	tests := []struct {
 		name    string
 		fields  fields
@@ -164,7 +304,35 @@ func TestClient_GetCertificateStoreByID(t *testing.T) {
 		want    *GetCertificateStoreResponse
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name:   "TypedSchedule",
+			fields: fields{hostname: srv.hostname, httpClient: srv.httpClient},
+			args:   args{storeId: "c2f1f2e4-1c5d-4b6e-9a1e-4f3c2b1a0d9e"},
+			want: &GetCertificateStoreResponse{
+				Id:                      "c2f1f2e4-1c5d-4b6e-9a1e-4f3c2b1a0d9e",
+				ClientMachine:           "iis01",
+				StorePath:               "My",
+				CertStoreInventoryJobId: "0f6a2d1e-7b3c-4e5f-8a9b-1c2d3e4f5a6b",
+				CertStoreType:           5,
+				Properties:              map[string]interface{}{},
+				InventorySchedule: InventorySchedule{
+					Weekly: &InventoryWeekly{Days: []string{"Monday", "Thursday"}, Time: "2023-01-02T03:00:00Z"},
+				},
+				ReenrollmentStatus:    ReEnrollmnentConfig{Data: true, AgentId: "b7e0f5a4-8d1f-4e2c-a3b6-0c9d8e7f6a5b", CustomAliasAllowed: 1},
+				SetNewPasswordAllowed: true,
+			},
+		},
+		{
+			name:   "NoInventoryJob",
+			fields: fields{hostname: srv.hostname, httpClient: srv.httpClient},
+			args:   args{storeId: "5d3c2b1a-0000-4b6e-9a1e-4f3c2b1a0d9e"},
+			want: &GetCertificateStoreResponse{
+				Id:            "5d3c2b1a-0000-4b6e-9a1e-4f3c2b1a0d9e",
+				ClientMachine: "iis02",
+				StorePath:     "My",
+				Properties:    map[string]interface{}{},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -172,6 +340,7 @@ func TestClient_GetCertificateStoreByID(t *testing.T) {
 				hostname:        tt.fields.hostname,
 				httpClient:      tt.fields.httpClient,
 				basicAuthString: tt.fields.basicAuthString,
+				apiPath:         "KeyfactorAPI",
 			}
 			got, err := c.GetCertificateStoreByID(tt.args.storeId)
 			if (err != nil) != tt.wantErr {
@@ -183,6 +352,33 @@ func TestClient_GetCertificateStoreByID(t *testing.T) {
 			}
 		})
 	}
+	if historyRequests != 0 {
+		t.Errorf("GetCertificateStoreByID() looked up job history %d times, want none", historyRequests)
+	}
+
+	t.Run("LastInventory", func(t *testing.T) {
+		store, err := srv.GetCertificateStoreByID("c2f1f2e4-1c5d-4b6e-9a1e-4f3c2b1a0d9e")
+		if err != nil {
+			t.Fatalf("GetCertificateStoreByID() error = %v", err)
+		}
+		last, err := srv.GetLastInventory(context.Background(), store)
+		want := &JobHistory{
+			JobHistoryId: 9,
+			JobId:        "0f6a2d1e-7b3c-4e5f-8a9b-1c2d3e4f5a6b",
+			JobType:      "Inventory",
+			Result:       JobResultFailure,
+			Message:      "access denied",
+		}
+		if err != nil || !reflect.DeepEqual(last, want) || store.LastInventory != last {
+			t.Errorf("GetLastInventory() = %+v, %v, want %+v set on the store", last, err, want)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := srv.GetLastInventory(ctx, store); !errors.Is(err, context.Canceled) {
+			t.Errorf("GetLastInventory() with a cancelled context error = %v, want context.Canceled", err)
+		}
+	})
 }
 
 func TestClient_ListCertificateStores(t *testing.T) {
@@ -206,7 +402,7 @@ func TestClient_ListCertificateStores(t *testing.T) {
 				httpClient:      tt.fields.httpClient,
 				basicAuthString: tt.fields.basicAuthString,
 			}
-			got, err := c.ListCertificateStores()
+			got, err := c.ListCertificateStores(nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ListCertificateStores() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -218,6 +414,34 @@ func TestClient_ListCertificateStores(t *testing.T) {
 	}
 }
 
+func TestClient_SearchCertificateStores(t *testing.T) {
+	var lastQuery url.Values
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		lastQuery = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"Id": "b", "ClientMachine": "web02"}, {"Id": "a", "ClientMachine": "web01"}]`))
+	})
+
+	got, err := c.SearchCertificateStores(`ClientMachine -startswith "web"`, &Paging{PageReturned: 2, SortField: "ClientMachine"})
+	if err != nil {
+		t.Fatalf("SearchCertificateStores() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Id != "b" {
+		t.Errorf("SearchCertificateStores() = %+v", got)
+	}
+	want := map[string]string{
+		"certificateStoreQuery.queryString":   `ClientMachine -startswith "web"`,
+		"certificateStoreQuery.pageReturned":  "2",
+		"certificateStoreQuery.sortField":     "ClientMachine",
+		"certificateStoreQuery.sortAscending": "1",
+	}
+	for k, v := range want {
+		if lastQuery.Get(k) != v {
+			t.Errorf("SearchCertificateStores() sent %s = %q, want %q", k, lastQuery.Get(k), v)
+		}
+	}
+}
+
 func TestClient_RemoveCertificateFromStores(t *testing.T) {
 	type fields struct {
 		hostname        string
@@ -227,14 +451,69 @@ func TestClient_RemoveCertificateFromStores(t *testing.T) {
 	type args struct {
 		config *RemoveCertificateFromStore
 	}
+	var body map[string]interface{}
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /KeyfactorAPI/CertificateStores/5f1e0a2c-0004-4000-8000-000000000004/Inventory":
+			w.Write([]byte(`[
+				{"Name": "app", "Certificates": [{"Id": 12, "Thumbprint": "0A1B2C"}]},
+				{"Name": "app-backup", "Certificates": [{"Id": 12, "Thumbprint": "0A1B2C"}]},
+				{"Name": "other", "Certificates": [{"Id": 13, "Thumbprint": "FFEEDD"}]}
+			]`))
+		case "POST /KeyfactorAPI/CertificateStores/Certificates/Remove":
+			json.NewDecoder(r.Body).Decode(&body)
+			w.Write([]byte(`["0c2ba84c-6a1c-4d7d-8b1b-1b3f7e4f0a11"]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    []string
-		wantErr bool
+		name       string
+		fields     fields
+		args       args
+		want       []string
+		wantStores string
+		wantErr    bool
 	}{
-		// TODO: Add test cases.
+		{
+			name:   "PerStoreAndDefaultAlias",
+			fields: fields{hostname: srv.hostname, httpClient: srv.httpClient},
+			args: args{config: &RemoveCertificateFromStore{
+				Alias:             "web",
+				CertificateStores: &[]CertificateStore{{CertificateStoreId: "iis-store"}, {CertificateStoreId: "jks-store", Alias: "tomcat"}},
+			}},
+			want:       []string{"0c2ba84c-6a1c-4d7d-8b1b-1b3f7e4f0a11"},
+			wantStores: `[{"Alias":"web","CertificateStoreId":"iis-store"},{"Alias":"tomcat","CertificateStoreId":"jks-store"}]`,
+		},
+		{
+			name:   "AliasFromInventoryByThumbprint",
+			fields: fields{hostname: srv.hostname, httpClient: srv.httpClient},
+			args: args{config: &RemoveCertificateFromStore{
+				Thumbprint:        "0a1b2c",
+				CertificateStores: &[]CertificateStore{{CertificateStoreId: "5f1e0a2c-0004-4000-8000-000000000004"}},
+			}},
+			want:       []string{"0c2ba84c-6a1c-4d7d-8b1b-1b3f7e4f0a11"},
+			wantStores: `[{"Alias":"app","CertificateStoreId":"5f1e0a2c-0004-4000-8000-000000000004"},{"Alias":"app-backup","CertificateStoreId":"5f1e0a2c-0004-4000-8000-000000000004"}]`,
+		},
+		{
+			name:   "NotInInventory",
+			fields: fields{hostname: srv.hostname, httpClient: srv.httpClient},
+			args: args{config: &RemoveCertificateFromStore{
+				CertificateId:     99,
+				CertificateStores: &[]CertificateStore{{CertificateStoreId: "5f1e0a2c-0004-4000-8000-000000000004"}},
+			}},
+			wantErr: true,
+		},
+		{
+			name:   "NoAliasOrCertificate",
+			fields: fields{hostname: srv.hostname, httpClient: srv.httpClient},
+			args: args{config: &RemoveCertificateFromStore{
+				CertificateStores: &[]CertificateStore{{CertificateStoreId: "5f1e0a2c-0004-4000-8000-000000000004"}},
+			}},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -242,15 +521,23 @@ func TestClient_RemoveCertificateFromStores(t *testing.T) {
 				hostname:        tt.fields.hostname,
 				httpClient:      tt.fields.httpClient,
 				basicAuthString: tt.fields.basicAuthString,
+				apiPath:         "KeyfactorAPI",
 			}
-			got, err := c.RemoveCertificateFromStores(tt.args.config)
+			body = nil
+			report, err := c.RemoveCertificateFromStores(tt.args.config, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("RemoveCertificateFromStores() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.want) {
+			if got := report.JobIds; !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("RemoveCertificateFromStores() got = %v, want %v", got, tt.want)
 			}
+			if tt.wantStores != "" {
+				gotStores, _ := json.Marshal(body["CertificateStores"])
+				if string(gotStores) != tt.wantStores {
+					t.Errorf("RemoveCertificateFromStores() sent CertificateStores = %s, want %s", gotStores, tt.wantStores)
+				}
+			}
 		})
 	}
 }
@@ -294,7 +581,7 @@ func TestClient_UpdateStore(t *testing.T) {
 
 func Test_buildPropertiesInterface(t *testing.T) {
 	type args struct {
-		properties map[string]string
+		properties map[string]interface{}
 	}
 	tests := []struct {
 		name string
@@ -319,7 +606,7 @@ func Test_unmarshalPropertiesString(t *testing.T) {
 	tests := []struct {
 		name string
 		args args
-		want map[string]string
+		want map[string]interface{}
 	}{
 		// TODO: Add test cases.
 	}
@@ -371,3 +658,70 @@ func Test_validateUpdateStoreArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestInventorySchedule_Equal(t *testing.T) {
+	immediate, notImmediate := true, false
+	tests := []struct {
+		name string
+		a, b InventorySchedule
+		want bool
+	}{
+		{name: "SameInterval", a: InventorySchedule{Interval: &InventoryInterval{Minutes: 60}}, b: InventorySchedule{Interval: &InventoryInterval{Minutes: 60}}, want: true},
+		{name: "DifferentInterval", a: InventorySchedule{Interval: &InventoryInterval{Minutes: 60}}, b: InventorySchedule{Interval: &InventoryInterval{Minutes: 30}}},
+		{name: "ImmediateFalseIsOff", a: InventorySchedule{Immediate: &notImmediate}, b: InventorySchedule{}, want: true},
+		{name: "ImmediateVsOff", a: InventorySchedule{Immediate: &immediate}, b: InventorySchedule{}},
+		{name: "DailyVsOnce", a: InventorySchedule{Daily: &InventoryDaily{Time: "2023-01-02T03:00:00Z"}}, b: InventorySchedule{ExactlyOnce: &InventoryOnce{Time: "2023-01-02T03:00:00Z"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Equal(tt.b); got != tt.want {
+				t.Errorf("Equal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_SetCertificateStorePassword(t *testing.T) {
+	var got setStorePasswordBody
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/KeyfactorAPI/CertificateStores/Password" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name    string
+		storeId string
+		secret  *StoreSecret
+		wantErr bool
+	}{
+		{name: "SecretValue", storeId: "c2f1f2e4-1c5d-4b6e-9a1e-4f3c2b1a0d9e", secret: &StoreSecret{SecretValue: "changeit"}},
+		{
+			name:    "PAMProvider",
+			storeId: "c2f1f2e4-1c5d-4b6e-9a1e-4f3c2b1a0d9e",
+			secret:  &StoreSecret{Provider: 3, Parameters: map[string]string{"SecretId": "keystores/tomcat"}},
+		},
+		{name: "PAMProviderWithoutParameters", storeId: "c2f1f2e4-1c5d-4b6e-9a1e-4f3c2b1a0d9e", secret: &StoreSecret{Provider: 3}, wantErr: true},
+		{name: "ValueAndProvider", storeId: "c2f1f2e4-1c5d-4b6e-9a1e-4f3c2b1a0d9e", secret: &StoreSecret{SecretValue: "changeit", Provider: 3, Parameters: map[string]string{"SecretId": "x"}}, wantErr: true},
+		{name: "EmptySecret", storeId: "c2f1f2e4-1c5d-4b6e-9a1e-4f3c2b1a0d9e", secret: &StoreSecret{}, wantErr: true},
+		{name: "MissingStoreId", secret: &StoreSecret{SecretValue: "changeit"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = setStorePasswordBody{}
+			err := c.SetCertificateStorePassword(tt.storeId, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetCertificateStorePassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.CertStoreId != tt.storeId || !reflect.DeepEqual(got.NewPassword, tt.secret) {
+				t.Errorf("SetCertificateStorePassword() sent %+v", got)
+			}
+		})
+	}
+}
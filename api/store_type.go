@@ -5,11 +5,38 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 
 	"github.com/Keyfactor/keyfactor-go-client-sdk/api/keyfactor"
+	"github.com/Keyfactor/keyfactor-go-client/enums"
 )
 
+// ErrAlreadyExists is matched by errors returned when creating an object that already exists in Keyfactor.
+var ErrAlreadyExists = errors.New("already exists")
+
+// Flags returns the supported operations as a set of enums.StoreTypeOperation flags.
+func (o *StoreTypeSupportedOperations) Flags() enums.StoreTypeOperation {
+	var flags enums.StoreTypeOperation
+	if o == nil {
+		return flags
+	}
+	if o.Add {
+		flags |= enums.StoreTypeOperationAdd
+	}
+	if o.Remove {
+		flags |= enums.StoreTypeOperationRemove
+	}
+	if o.Create {
+		flags |= enums.StoreTypeOperationCreate
+	}
+	if o.Discovery {
+		flags |= enums.StoreTypeOperationDiscovery
+	}
+	if o.Enrollment {
+		flags |= enums.StoreTypeOperationEnrollment
+	}
+	return flags
+}
+
 //type StringInt int32
 //
 //// UnmarshalJSON create a custom unmarshal for the StringInt
@@ -47,11 +74,17 @@ import (
 
 // GetCertificateStoreType takes arguments for a certificate store type ID or name and if found will return the certificate store type
 func (c *Client) GetCertificateStoreType(id interface{}) (*CertificateStoreType, error) {
+	return c.GetCertificateStoreTypeContext(context.Background(), id)
+}
+
+// GetCertificateStoreTypeContext is like GetCertificateStoreType but uses ctx for the request, allowing it to be
+// cancelled.
+func (c *Client) GetCertificateStoreTypeContext(ctx context.Context, id interface{}) (*CertificateStoreType, error) {
 	switch id.(type) {
 	case int:
-		return c.GetCertificateStoreTypeById(id.(int))
+		return c.GetCertificateStoreTypeByIdContext(ctx, id.(int))
 	case string:
-		return c.GetCertificateStoreTypeByName(id.(string))
+		return c.GetCertificateStoreTypeByNameContext(ctx, id.(string))
 	}
 
 	return nil, errors.New("invalid type for id, must pass either string or integer")
@@ -60,14 +93,19 @@ func (c *Client) GetCertificateStoreType(id interface{}) (*CertificateStoreType,
 // GetCertificateStoreTypeByName takes arguments for a certificate store type ID to facilitate a call to Keyfactor
 // that retrieves certificate store context associated with a store type ID
 func (c *Client) GetCertificateStoreTypeByName(name string) (*CertificateStoreType, error) {
+	return c.GetCertificateStoreTypeByNameContext(context.Background(), name)
+}
+
+// GetCertificateStoreTypeByNameContext is like GetCertificateStoreTypeByName but uses ctx for the request, allowing it
+// to be cancelled.
+func (c *Client) GetCertificateStoreTypeByNameContext(ctx context.Context, name string) (*CertificateStoreType, error) {
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
-	resp, _, err := apiClient.CertificateStoreTypeApi.CertificateStoreTypeGetCertificateStoreType1(context.Background(), name).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, _, err := apiClient.CertificateStoreTypeApi.CertificateStoreTypeGetCertificateStoreType1(ctx, name).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
 		return nil, err
@@ -91,14 +129,19 @@ func (c *Client) GetCertificateStoreTypeByName(name string) (*CertificateStoreTy
 // GetCertificateStoreTypeById takes arguments for a certificate store type ID to facilitate a call to Keyfactor
 // that retrieves certificate store context associated with a store type ID
 func (c *Client) GetCertificateStoreTypeById(id int) (*CertificateStoreType, error) {
+	return c.GetCertificateStoreTypeByIdContext(context.Background(), id)
+}
+
+// GetCertificateStoreTypeByIdContext is like GetCertificateStoreTypeById but uses ctx for the request, allowing it to
+// be cancelled.
+func (c *Client) GetCertificateStoreTypeByIdContext(ctx context.Context, id int) (*CertificateStoreType, error) {
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
-	resp, _, err := apiClient.CertificateStoreTypeApi.CertificateStoreTypeGetCertificateStoreType0(context.Background(), int32(id)).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, _, err := apiClient.CertificateStoreTypeApi.CertificateStoreTypeGetCertificateStoreType0(ctx, int32(id)).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
 		return nil, err
@@ -114,20 +157,25 @@ func (c *Client) GetCertificateStoreTypeById(id int) (*CertificateStoreType, err
 
 // ListCertificateStoreTypes takes no arguments and returns a list of certificate store types from Keyfactor.
 func (c *Client) ListCertificateStoreTypes() (*[]CertificateStoreType, error) {
+	return c.ListCertificateStoreTypesContext(context.Background())
+}
+
+// ListCertificateStoreTypesContext is like ListCertificateStoreTypes but uses ctx for the request, allowing it to be
+// cancelled.
+func (c *Client) ListCertificateStoreTypesContext(ctx context.Context) (*[]CertificateStoreType, error) {
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
-	resp, _, err := apiClient.CertificateStoreTypeApi.CertificateStoreTypeGetTypes(context.Background()).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, _, err := apiClient.CertificateStoreTypeApi.CertificateStoreTypeGetTypes(ctx).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
 		return nil, err
 	}
 
-	var newResp []CertificateStoreType
+	newResp := make([]CertificateStoreType, 0, len(resp))
 	for i, _ := range resp {
 		var newCertType CertificateStoreType
 		mapResp, _ := resp[i].ToMap()
@@ -147,22 +195,33 @@ func (c *Client) ListCertificateStoreTypes() (*[]CertificateStoreType, error) {
 //   - Properties    : []StringTuple *Note - Method converts this array of StringTuples to a JSON string if provided
 //   - AgentId       : string
 func (c *Client) CreateStoreType(ca *CertificateStoreType) (*CertificateStoreType, error) {
-	log.Println("[INFO] Creating new certificate store type with Keyfactor")
+	return c.CreateStoreTypeContext(context.Background(), ca)
+}
+
+// CreateStoreTypeContext is like CreateStoreType but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) CreateStoreTypeContext(ctx context.Context, ca *CertificateStoreType) (*CertificateStoreType, error) {
+	c.infof("Creating new certificate store type with Keyfactor")
+
+	if err := validateStoreTypeOptions(ca); err != nil {
+		return nil, err
+	}
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
 	var newReq keyfactor.KeyfactorApiModelsCertificateStoresTypesCertificateStoreTypeCreationRequest
-	jsonData, _ := json.Marshal(ca)
-	err := json.Unmarshal(jsonData, &newReq)
+	jsonData, err := storeTypeRequestJSON(ca, true)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(jsonData, &newReq)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, _, err := apiClient.CertificateStoreTypeApi.CertificateStoreTypeCreateCertificateStoreType(context.Background()).XKeyfactorRequestedWith(xKeyfactorRequestedWith).CertStoreType(newReq).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, _, err := apiClient.CertificateStoreTypeApi.CertificateStoreTypeCreateCertificateStoreType(ctx).XKeyfactorRequestedWith(xKeyfactorRequestedWith).CertStoreType(newReq).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 	if err != nil {
 		return nil, err
 	}
@@ -181,23 +240,106 @@ func (c *Client) CreateStoreType(ca *CertificateStoreType) (*CertificateStoreTyp
 	return &newResp, nil
 }
 
+// CreateStoreTypeWithOptions behaves like CreateStoreType, but first checks Keyfactor for a store type with the same
+// ShortName or Capability, compared case-insensitively. If one exists, a *StoreTypeExistsError carrying its ID is
+// returned, or, when opts.Upsert is set, the existing store type is updated in place instead. opts may be nil.
+func (c *Client) CreateStoreTypeWithOptions(ca *CertificateStoreType, opts *CreateStoreTypeOptions) (*CertificateStoreType, error) {
+	if ca == nil || ca.ShortName == "" {
+		return nil, errors.New("short name is required to create a certificate store type")
+	}
+	if opts == nil {
+		opts = &CreateStoreTypeOptions{}
+	}
+
+	existing, err := c.findStoreType(ca.ShortName, ca.Capability)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return c.CreateStoreType(ca)
+	}
+
+	if !opts.Upsert {
+		return nil, &StoreTypeExistsError{ExistingId: existing.StoreType, ShortName: existing.ShortName, Capability: existing.Capability}
+	}
+	c.infof("Certificate store type %s already exists with ID %d, updating it", existing.ShortName, existing.StoreType)
+	update := *ca
+	update.StoreType = existing.StoreType
+	return c.UpdateStoreType(&update)
+}
+
+// Error implements the error interface.
+func (e *StoreTypeExistsError) Error() string {
+	return fmt.Sprintf("certificate store type %s (capability %s) %s with ID %d", e.ShortName, e.Capability, ErrAlreadyExists, e.ExistingId)
+}
+
+// Unwrap allows errors.Is(err, ErrAlreadyExists) to match.
+func (e *StoreTypeExistsError) Unwrap() error {
+	return ErrAlreadyExists
+}
+
+// findStoreType returns the store type whose ShortName or Capability matches, or nil if there is none.
+func (c *Client) findStoreType(shortName, capability string) (*CertificateStoreType, error) {
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "GET",
+		Endpoint: "CertificateStoreTypes",
+		Headers:  headers,
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonResp []CertificateStoreType
+	err = c.decodeResponse(resp.Body, &jsonResp)
+	if err != nil {
+		return nil, err
+	}
+
+	return matchStoreType(jsonResp, shortName, capability), nil
+}
+
+// UpdateStoreType takes arguments for a CertificateStoreType to facilitate a call to Keyfactor that replaces the
+// definition of the store type with ID StoreType. Every field is sent, so update a store type fetched from Keyfactor
+// rather than one built from scratch, or its PasswordOptions, PowerShell, and PrivateKeyAllowed settings are reset.
 func (c *Client) UpdateStoreType(ca *CertificateStoreType) (*CertificateStoreType, error) {
-	log.Println("[INFO] Creating new certificate store type with Keyfactor")
+	return c.UpdateStoreTypeContext(context.Background(), ca)
+}
+
+// UpdateStoreTypeContext is like UpdateStoreType but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) UpdateStoreTypeContext(ctx context.Context, ca *CertificateStoreType) (*CertificateStoreType, error) {
+	c.infof("Updating certificate store type with Keyfactor")
+	defer c.InvalidateStoreTypeCache()
+
+	if err := validateStoreTypeOptions(ca); err != nil {
+		return nil, err
+	}
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
 	var newReq keyfactor.KeyfactorApiModelsCertificateStoresTypesCertificateStoreTypeUpdateRequest
-	jsonData, _ := json.Marshal(ca)
-	err := json.Unmarshal(jsonData, &newReq)
+	jsonData, err := storeTypeRequestJSON(ca, false)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(jsonData, &newReq)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, _, err := apiClient.CertificateStoreTypeApi.CertificateStoreTypeUpdateCertificateStoreType(context.Background()).XKeyfactorRequestedWith(xKeyfactorRequestedWith).CertStoreType(newReq).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, _, err := apiClient.CertificateStoreTypeApi.CertificateStoreTypeUpdateCertificateStoreType(ctx).XKeyfactorRequestedWith(xKeyfactorRequestedWith).CertStoreType(newReq).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
 		return nil, err
@@ -210,16 +352,98 @@ func (c *Client) UpdateStoreType(ca *CertificateStoreType) (*CertificateStoreTyp
 
 	return &newResp, nil
 }
+
+// storeTypeRequestJSON encodes a store type for the SDK's create or update request model. The SDK models only accept
+// string property defaults, and silently drop every field of a request that fails to decode, so defaults such as
+// false are sent as "false", the way the UI sends them. On creation, the job types are also sent under the names the
+// create request expects. Fields the models do not define, such as JobProperties and ArgumentFormats, are kept as
+// additional properties and sent as they are.
+func storeTypeRequestJSON(ca *CertificateStoreType, create bool) ([]byte, error) {
+	jsonData, err := json.Marshal(ca)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(jsonData, &fields); err != nil {
+		return nil, err
+	}
+
+	if props, ok := fields["Properties"].([]interface{}); ok {
+		for _, p := range props {
+			prop, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			switch v := prop["DefaultValue"].(type) {
+			case nil, string:
+			default:
+				prop["DefaultValue"] = fmt.Sprint(v)
+			}
+		}
+	}
+	if create {
+		for _, name := range []string{"InventoryJobType", "ManagementJobType", "DiscoveryJobType", "EnrollmentJobType"} {
+			if v, _ := fields[name].(string); v != "" && fields[name+"Id"] == nil {
+				fields[name+"Id"] = v
+			}
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// validateStoreTypeOptions checks the enumerated string fields of a store type, which Keyfactor would otherwise reject
+// with a less helpful error or silently reset.
+func validateStoreTypeOptions(ca *CertificateStoreType) error {
+	if ca == nil {
+		return errors.New("certificate store type is required")
+	}
+	switch ca.PrivateKeyAllowed {
+	case "", PrivateKeyForbidden, PrivateKeyOptional, PrivateKeyRequired:
+	default:
+		return fmt.Errorf("invalid PrivateKeyAllowed %q; must be %s, %s, or %s", ca.PrivateKeyAllowed, PrivateKeyForbidden, PrivateKeyOptional, PrivateKeyRequired)
+	}
+	switch ca.CustomAliasAllowed {
+	case "", CustomAliasForbidden, CustomAliasOptional, CustomAliasRequired:
+	default:
+		return fmt.Errorf("invalid CustomAliasAllowed %q; must be %s, %s, or %s", ca.CustomAliasAllowed, CustomAliasForbidden, CustomAliasOptional, CustomAliasRequired)
+	}
+	if ca.PasswordOptions != nil {
+		switch ca.PasswordOptions.Style {
+		case "", PasswordStyleDefault, PasswordStyleCustom:
+		default:
+			return fmt.Errorf("invalid password style %q; must be %s or %s", ca.PasswordOptions.Style, PasswordStyleDefault, PasswordStyleCustom)
+		}
+	}
+	return nil
+}
+
+// propertyDefinitions returns the store type's property definitions by name.
+func (t *CertificateStoreType) propertyDefinitions() map[string]StoreTypePropertyDefinition {
+	defined := map[string]StoreTypePropertyDefinition{}
+	if t.Properties != nil {
+		for _, def := range *t.Properties {
+			defined[def.Name] = def
+		}
+	}
+	return defined
+}
+
 func (c *Client) DeleteCertificateStoreType(id int) (*DeleteStoreType, error) {
-	log.Printf("[INFO] Attempting to delete certificate store type %d", id)
+	return c.DeleteCertificateStoreTypeContext(context.Background(), id)
+}
+
+// DeleteCertificateStoreTypeContext is like DeleteCertificateStoreType but uses ctx for the request, allowing it to be
+// cancelled.
+func (c *Client) DeleteCertificateStoreTypeContext(ctx context.Context, id int) (*DeleteStoreType, error) {
+	c.infof("Attempting to delete certificate store type %d", id)
+	defer c.InvalidateStoreTypeCache()
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
-	resp, err := apiClient.CertificateStoreTypeApi.CertificateStoreTypeDeleteCertificateStoreType(context.Background(), int32(id)).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, err := apiClient.CertificateStoreTypeApi.CertificateStoreTypeDeleteCertificateStoreType(ctx, int32(id)).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
 		return nil, err
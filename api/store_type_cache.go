@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultStoreTypeCacheTTL is the default of AuthConfig.StoreTypeCacheTTL.
+const DefaultStoreTypeCacheTTL = 10 * time.Minute
+
+// storeTypeCache holds the certificate store types looked up to validate stores, keyed by short name, so that creating
+// many stores of one type looks the type up once. Lookups by ID are resolved to a short name through ids.
+type storeTypeCache struct {
+	mu      sync.Mutex
+	entries map[string]*storeTypeCacheEntry
+	ids     map[int]string
+}
+
+// storeTypeCacheEntry is a cached store type. done is closed once the lookup that filled it has finished, so callers
+// that miss while the lookup is in flight wait for it rather than making their own.
+type storeTypeCacheEntry struct {
+	done      chan struct{}
+	storeType *CertificateStoreType
+	err       error
+	expires   time.Time
+}
+
+// storeTypeTTL returns how long store types are cached, or 0 if they are not.
+func (c *Client) storeTypeTTL() time.Duration {
+	switch {
+	case c.storeTypeCacheTTL < 0:
+		return 0
+	case c.storeTypeCacheTTL == 0:
+		return DefaultStoreTypeCacheTTL
+	default:
+		return c.storeTypeCacheTTL
+	}
+}
+
+// cachedStoreTypeByName returns the store type with the short name, from the cache if it was looked up within the
+// cache TTL. The returned store type is shared and must not be modified.
+func (c *Client) cachedStoreTypeByName(ctx context.Context, shortName string) (*CertificateStoreType, error) {
+	return c.cachedStoreType(ctx, shortName, func() (*CertificateStoreType, error) {
+		storeType, err := c.GetCertificateStoreTypeByNameContext(ctx, shortName)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get certificate store type %s: %w", shortName, err)
+		}
+		return storeType, nil
+	})
+}
+
+// cachedStoreTypeById is like cachedStoreTypeByName but looks the store type up by its ID.
+func (c *Client) cachedStoreTypeById(ctx context.Context, id int) (*CertificateStoreType, error) {
+	if c.storeTypeTTL() > 0 {
+		cache := c.storeTypeCache()
+		cache.mu.Lock()
+		shortName, ok := cache.ids[id]
+		cache.mu.Unlock()
+		if ok {
+			return c.cachedStoreTypeByName(ctx, shortName)
+		}
+	}
+	storeType, err := c.GetCertificateStoreTypeByIdContext(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get certificate store type %d: %w", id, err)
+	}
+	return c.cachedStoreType(ctx, storeType.ShortName, func() (*CertificateStoreType, error) { return storeType, nil })
+}
+
+// cachedStoreType returns the cached store type with the short name, calling lookup to fill the cache if it has none
+// or it expired. Failed lookups are not cached.
+func (c *Client) cachedStoreType(ctx context.Context, shortName string, lookup func() (*CertificateStoreType, error)) (*CertificateStoreType, error) {
+	ttl := c.storeTypeTTL()
+	if ttl == 0 {
+		return lookup()
+	}
+
+	cache := c.storeTypeCache()
+	cache.mu.Lock()
+	entry, ok := cache.entries[shortName]
+	if ok {
+		select {
+		case <-entry.done:
+			if time.Now().After(entry.expires) {
+				ok = false
+			}
+		default:
+		}
+	}
+	if !ok {
+		entry = &storeTypeCacheEntry{done: make(chan struct{})}
+		cache.entries[shortName] = entry
+		cache.mu.Unlock()
+
+		entry.storeType, entry.err = lookup()
+		cache.mu.Lock()
+		if entry.err != nil {
+			delete(cache.entries, shortName)
+		} else {
+			entry.expires = time.Now().Add(ttl)
+			cache.ids[entry.storeType.StoreType] = shortName
+		}
+		cache.mu.Unlock()
+		close(entry.done)
+		return entry.storeType, entry.err
+	}
+	cache.mu.Unlock()
+
+	select {
+	case <-entry.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if entry.err != nil {
+		// The lookup this call waited on failed; try again rather than sharing another caller's error.
+		return c.cachedStoreType(ctx, shortName, lookup)
+	}
+	return entry.storeType, nil
+}
+
+// storeTypeCache returns the client's store type cache, creating it if needed.
+func (c *Client) storeTypeCache() *storeTypeCache {
+	c.storeTypesMu.Lock()
+	defer c.storeTypesMu.Unlock()
+	if c.storeTypes == nil {
+		c.storeTypes = &storeTypeCache{entries: map[string]*storeTypeCacheEntry{}, ids: map[int]string{}}
+	}
+	return c.storeTypes
+}
+
+// InvalidateStoreTypeCache discards the cached certificate store types, e.g. after a store type was changed by another
+// client. The client's own changes to store types invalidate the cache.
+func (c *Client) InvalidateStoreTypeCache() {
+	c.storeTypesMu.Lock()
+	defer c.storeTypesMu.Unlock()
+	c.storeTypes = nil
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient_CreateStoreValidateProperties(t *testing.T) {
+	const agentId = "6c1f0a2b-3d4e-4f5a-8b9c-0d1e2f3a4b5c"
+	var (
+		mu      sync.Mutex
+		lookups int
+		created int
+	)
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/KeyfactorAPI/CertificateStoreTypes/105":
+			lookups++
+			w.Write([]byte(`{"StoreType": 105, "ShortName": "PEM", "Properties": [
+				{"Name": "Owner", "DisplayName": "File Owner", "Type": "String", "Required": true},
+				{"Name": "Mode", "Type": "String", "Required": true, "DefaultValue": "0600"},
+				{"Name": "IncludeChain", "Type": "Bool", "Required": true}]}`))
+		case r.Method == "POST" && r.URL.Path == "/KeyfactorAPI/CertificateStores":
+			created++
+			fmt.Fprintf(w, `{"Id": "3f2a5c1e-8b7d-4e6f-9a0b-1c2d3e4f5a6b", "CertStoreType": 105}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	args := func(props map[string]interface{}) *CreateStoreFctArgs {
+		return &CreateStoreFctArgs{
+			ClientMachine: "web01.example.com", StorePath: "/etc/ssl/app.pem", CertStoreType: 105, AgentId: agentId,
+			Properties: props, ValidateProperties: true,
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		if _, err := c.CreateStore(args(map[string]interface{}{"Owner": "root"})); err != nil {
+			t.Fatalf("CreateStore() error = %v", err)
+		}
+	}
+	if lookups != 1 || created != 20 {
+		t.Errorf("creating 20 stores made %d store type lookups and created %d stores, want 1 and 20", lookups, created)
+	}
+
+	tests := []struct {
+		name    string
+		args    *CreateStoreFctArgs
+		wantErr string
+	}{
+		{name: "Undefined", args: args(map[string]interface{}{"Owner": "root", "Group": "ssl"}), wantErr: "does not define property Group"},
+		{name: "MissingRequired", args: args(map[string]interface{}{"Owner": ""}), wantErr: "requires property Owner (File Owner)"},
+		{name: "PropertiesString", args: func() *CreateStoreFctArgs {
+			a := args(nil)
+			a.PropertiesString = `{"Owner": {"value": "root"}, "Group": {"value": "ssl"}}`
+			return a
+		}(), wantErr: "does not define property Group"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created = 0
+			if _, err := c.CreateStore(tt.args); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CreateStore() error = %v, want %q", err, tt.wantErr)
+			}
+			if created != 0 {
+				t.Errorf("CreateStore() created a store with invalid properties")
+			}
+		})
+	}
+
+	c.InvalidateStoreTypeCache()
+	if _, err := c.CreateStore(args(map[string]interface{}{"Owner": "root"})); err != nil || lookups != 2 {
+		t.Errorf("CreateStore() after InvalidateStoreTypeCache() error = %v with %d lookups, want 2", err, lookups)
+	}
+
+	c.storeTypeCacheTTL = -1
+	for i := 0; i < 2; i++ {
+		if _, err := c.CreateStore(args(map[string]interface{}{"Owner": "root"})); err != nil {
+			t.Fatalf("CreateStore() without a cache error = %v", err)
+		}
+	}
+	if lookups != 4 {
+		t.Errorf("CreateStore() without a cache made %d lookups, want 4", lookups)
+	}
+}
+
+func TestClient_cachedStoreTypeByName(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		lookups int
+	)
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		lookups++
+		mu.Unlock()
+		// Hold the lookup long enough for the other callers to miss the cache while it is in flight.
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"StoreType": 110, "ShortName": "AKV"}]`))
+	})
+	c.storeTypeCacheTTL = 200 * time.Millisecond
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if storeType, err := c.cachedStoreTypeByName(context.Background(), "AKV"); err != nil || storeType.StoreType != 110 {
+				t.Errorf("cachedStoreTypeByName() = %+v, %v", storeType, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if lookups != 1 {
+		t.Errorf("concurrent cachedStoreTypeByName() made %d lookups, want 1", lookups)
+	}
+
+	// Lookups by ID use the store type cached by name.
+	if _, err := c.cachedStoreTypeById(context.Background(), 110); err != nil || lookups != 1 {
+		t.Errorf("cachedStoreTypeById() error = %v after %d lookups, want 1", err, lookups)
+	}
+
+	time.Sleep(250 * time.Millisecond)
+	if _, err := c.cachedStoreTypeByName(context.Background(), "AKV"); err != nil || lookups != 2 {
+		t.Errorf("cachedStoreTypeByName() after the TTL error = %v after %d lookups, want 2", err, lookups)
+	}
+}
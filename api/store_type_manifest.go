@@ -0,0 +1,136 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// StoreTypeInstallResult reports the outcome of InstallExtensionStoreTypes.
+type StoreTypeInstallResult struct {
+	// Created lists the store types that were created, as returned by Keyfactor.
+	Created []CertificateStoreType
+	// Existing lists the store types already present in Keyfactor with the same ShortName or Capability. They are
+	// left unchanged.
+	Existing []CertificateStoreType
+}
+
+// extensionManifest is the part of an orchestrator extension's integration-manifest.json that describes the
+// certificate store types it implements.
+type extensionManifest struct {
+	About struct {
+		Orchestrator struct {
+			StoreTypes json.RawMessage `json:"store_types"`
+		} `json:"orchestrator"`
+	} `json:"about"`
+}
+
+// ParseExtensionManifest reads the certificate store types defined by a Keyfactor Universal Orchestrator extension.
+// manifest is either the extension's integration-manifest.json or its store_types.json, which holds a list of store
+// type definitions. Store types are returned in the order they are listed, or sorted by ShortName if the manifest
+// keys them by name.
+func ParseExtensionManifest(manifest io.Reader) ([]CertificateStoreType, error) {
+	data, err := io.ReadAll(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read extension manifest: %w", err)
+	}
+	data = bytes.TrimSpace(data)
+
+	storeTypes := data
+	if len(data) > 0 && data[0] == '{' {
+		var m extensionManifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("invalid extension manifest: %w", err)
+		}
+		storeTypes = bytes.TrimSpace(m.About.Orchestrator.StoreTypes)
+		if len(storeTypes) == 0 || bytes.Equal(storeTypes, []byte("null")) {
+			return nil, errors.New("extension manifest does not define any certificate store types")
+		}
+	}
+
+	var types []CertificateStoreType
+	if len(storeTypes) > 0 && storeTypes[0] == '{' {
+		// Older manifests key each store type by its name.
+		var byName map[string]CertificateStoreType
+		if err := json.Unmarshal(storeTypes, &byName); err != nil {
+			return nil, fmt.Errorf("invalid store types in extension manifest: %w", err)
+		}
+		for _, st := range byName {
+			types = append(types, st)
+		}
+		sort.Slice(types, func(i, j int) bool { return types[i].ShortName < types[j].ShortName })
+	} else if err := json.Unmarshal(storeTypes, &types); err != nil {
+		return nil, fmt.Errorf("invalid store types in extension manifest: %w", err)
+	}
+
+	if len(types) == 0 {
+		return nil, errors.New("extension manifest does not define any certificate store types")
+	}
+	for i := range types {
+		if types[i].ShortName == "" {
+			return nil, fmt.Errorf("store type %q in extension manifest has no short name", types[i].Name)
+		}
+		if err := validateStoreTypeOptions(&types[i]); err != nil {
+			return nil, fmt.Errorf("store type %s in extension manifest: %w", types[i].ShortName, err)
+		}
+	}
+	return types, nil
+}
+
+// InstallExtensionStoreTypes takes arguments for an orchestrator extension's manifest, as read by
+// ParseExtensionManifest, to facilitate calls to Keyfactor that create each certificate store type the extension
+// needs, along with its properties, entry parameters, and supported operations. Store types that already exist with
+// the same ShortName or Capability are reported as existing and left unchanged, so installing the same extension
+// twice is safe. The manifest is validated before anything is created. If a store type cannot be created, the
+// result lists what was done so far along with the error.
+func (c *Client) InstallExtensionStoreTypes(manifest io.Reader) (*StoreTypeInstallResult, error) {
+	return c.InstallExtensionStoreTypesContext(context.Background(), manifest)
+}
+
+// InstallExtensionStoreTypesContext is like InstallExtensionStoreTypes but uses ctx for the requests, allowing it to
+// be cancelled.
+func (c *Client) InstallExtensionStoreTypesContext(ctx context.Context, manifest io.Reader) (*StoreTypeInstallResult, error) {
+	types, err := ParseExtensionManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	c.infof("Installing %d certificate store types from extension manifest", len(types))
+
+	present, err := c.ListCertificateStoreTypesContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list certificate store types: %w", err)
+	}
+
+	result := &StoreTypeInstallResult{}
+	for i := range types {
+		if existing := matchStoreType(*present, types[i].ShortName, types[i].Capability); existing != nil {
+			c.infof("Certificate store type %s already exists with ID %d", existing.ShortName, existing.StoreType)
+			result.Existing = append(result.Existing, *existing)
+			continue
+		}
+		created, err := c.CreateStoreTypeContext(ctx, &types[i])
+		if err != nil {
+			return result, fmt.Errorf("unable to create certificate store type %s: %w", types[i].ShortName, err)
+		}
+		result.Created = append(result.Created, *created)
+		*present = append(*present, *created)
+	}
+	return result, nil
+}
+
+// matchStoreType returns the store type in types whose ShortName or Capability matches, compared case-insensitively,
+// or nil if there is none.
+func matchStoreType(types []CertificateStoreType, shortName, capability string) *CertificateStoreType {
+	for i := range types {
+		if strings.EqualFold(types[i].ShortName, shortName) ||
+			(capability != "" && strings.EqualFold(types[i].Capability, capability)) {
+			return &types[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testIntegrationManifest = `{
+	"$schema": "https://keyfactor.github.io/integration-manifest-schema.json",
+	"integration_type": "orchestrator",
+	"name": "Example Orchestrator Extension",
+	"about": {
+		"orchestrator": {
+			"UOFramework": "10.4",
+			"store_types": [
+				{
+					"Name": "Example PEM",
+					"ShortName": "ExPEM",
+					"Capability": "ExPEM",
+					"LocalStore": false,
+					"SupportedOperations": {"Add": true, "Create": true, "Discovery": false, "Enrollment": false, "Remove": true},
+					"Properties": [{"Name": "SeparateChain", "DisplayName": "Separate Chain", "Type": "Bool", "DefaultValue": "false", "Required": false}],
+					"EntryParameters": [],
+					"PasswordOptions": {"EntrySupported": false, "StoreRequired": true, "Style": "Default"},
+					"PrivateKeyAllowed": "Optional",
+					"ServerRequired": true,
+					"CustomAliasAllowed": "Forbidden"
+				},
+				{
+					"Name": "IIS Bound",
+					"ShortName": "IISU",
+					"Capability": "IISU",
+					"PasswordOptions": {"EntrySupported": false, "StoreRequired": false, "Style": "Default"},
+					"PrivateKeyAllowed": "Required"
+				}
+			]
+		}
+	}
+}`
+
+func TestParseExtensionManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{name: "IntegrationManifest", in: testIntegrationManifest, want: []string{"ExPEM", "IISU"}},
+		{name: "StoreTypesFile", in: `[{"Name": "Example JKS", "ShortName": "ExJKS", "Capability": "ExJKS"}]`, want: []string{"ExJKS"}},
+		{name: "KeyedByName", in: `{"about": {"orchestrator": {"store_types": {"K8SSecret": {"ShortName": "K8SSecret"}, "K8SCert": {"ShortName": "K8SCert"}}}}}`, want: []string{"K8SCert", "K8SSecret"}},
+		{name: "NoStoreTypes", in: `{"about": {"orchestrator": {}}}`, wantErr: true},
+		{name: "MissingShortName", in: `[{"Name": "Nameless"}]`, wantErr: true},
+		{name: "InvalidPrivateKeyAllowed", in: `[{"ShortName": "Bad", "PrivateKeyAllowed": "Sometimes"}]`, wantErr: true},
+		{name: "NotJSON", in: `store_types:`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseExtensionManifest(strings.NewReader(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseExtensionManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var names []string
+			for _, st := range got {
+				names = append(names, st.ShortName)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("ParseExtensionManifest() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_InstallExtensionStoreTypes(t *testing.T) {
+	var created []map[string]interface{}
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/KeyfactorAPI/CertificateStoreTypes":
+			w.Write([]byte(`[{"StoreType": 2, "Name": "IIS Bound Certificate", "ShortName": "iisu", "Capability": "IISU"}]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/KeyfactorAPI/CertificateStoreTypes":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			created = append(created, body)
+			body["StoreType"] = 150
+			json.NewEncoder(w).Encode(body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	got, err := c.InstallExtensionStoreTypes(strings.NewReader(testIntegrationManifest))
+	if err != nil {
+		t.Fatalf("InstallExtensionStoreTypes() error = %v", err)
+	}
+	if len(got.Created) != 1 || got.Created[0].ShortName != "ExPEM" || got.Created[0].StoreType != 150 {
+		t.Errorf("InstallExtensionStoreTypes() created = %+v, want ExPEM", got.Created)
+	}
+	if len(got.Existing) != 1 || got.Existing[0].StoreType != 2 {
+		t.Errorf("InstallExtensionStoreTypes() existing = %+v, want IISU", got.Existing)
+	}
+	if len(created) != 1 {
+		t.Fatalf("InstallExtensionStoreTypes() sent %d creations, want 1", len(created))
+	}
+	if props, _ := created[0]["Properties"].([]interface{}); len(props) != 1 {
+		t.Errorf("InstallExtensionStoreTypes() sent properties %v, want SeparateChain", created[0]["Properties"])
+	}
+	if ops, _ := created[0]["SupportedOperations"].(map[string]interface{}); ops["Add"] != true || ops["Remove"] != true {
+		t.Errorf("InstallExtensionStoreTypes() sent supported operations %v", created[0]["SupportedOperations"])
+	}
+
+	if _, err := c.InstallExtensionStoreTypes(strings.NewReader(`[{"Name": "Nameless"}]`)); err == nil {
+		t.Errorf("InstallExtensionStoreTypes() with an invalid manifest succeeded, want error")
+	}
+}
@@ -1,5 +1,7 @@
 package api
 
+import "encoding/json"
+
 type CertificateStoreType struct {
 	Name                string                         `json:"Name"`
 	ShortName           string                         `json:"ShortName"`
@@ -25,6 +27,23 @@ type CertificateStoreType struct {
 	ManagementJobType   string                         `json:"ManagementJobType"`
 	DiscoveryJobType    string                         `json:"DiscoveryJobType"`
 	EnrollmentJobType   string                         `json:"EnrollmentJobType"`
+	// ArgumentFormats holds the formats the UI applies to job arguments. It is passed through unchanged, so that a
+	// store type fetched from Keyfactor is updated or copied without losing it.
+	ArgumentFormats json.RawMessage `json:"ArgumentFormats,omitempty"`
+}
+
+// CreateStoreTypeOptions controls how CreateStoreTypeWithOptions handles a store type that already exists.
+type CreateStoreTypeOptions struct {
+	// Upsert updates an existing store type with the same ShortName or Capability instead of returning an error.
+	Upsert bool
+}
+
+// StoreTypeExistsError is returned by CreateStoreTypeWithOptions when a store type with the same ShortName or
+// Capability already exists. It matches ErrAlreadyExists with errors.Is.
+type StoreTypeExistsError struct {
+	ExistingId int
+	ShortName  string
+	Capability string
 }
 
 type CertStoreTypeResponseList []struct {
@@ -36,7 +55,7 @@ type DeleteStoreType struct {
 }
 
 type StoreTypePropertyDefinition struct {
-	StoreTypeID  int         `json:"StoreTypeId;omitempty"`
+	StoreTypeID  int         `json:"StoreTypeId,omitempty"`
 	Name         string      `json:"Name"`
 	DisplayName  string      `json:"DisplayName"`
 	Type         string      `json:"Type"`
@@ -61,12 +80,39 @@ type EntryParameter struct {
 	Options      string `json:"Options"`
 }
 
+// StoreTypePasswordOptions configures the passwords of stores of a store type. EntrySupported allows a password per
+// certificate entry, as JKS and PFX stores need, and StoreRequired requires a store password. Style is
+// PasswordStyleDefault or PasswordStyleCustom.
 type StoreTypePasswordOptions struct {
-	EntrySupported bool   `json:"EntrySupported,omitempty"`
-	StoreRequired  bool   `json:"StoreRequired,omitempty"`
-	Style          string `json:"string,Style,omitempty"`
+	EntrySupported bool   `json:"EntrySupported"`
+	StoreRequired  bool   `json:"StoreRequired"`
+	Style          string `json:"Style,omitempty"`
 }
 
+// Password styles of StoreTypePasswordOptions. With PasswordStyleDefault Keyfactor generates entry passwords, with
+// PasswordStyleCustom the user supplies them.
+const (
+	PasswordStyleDefault = "Default"
+	PasswordStyleCustom  = "Custom"
+)
+
+// Values of CertificateStoreType.PrivateKeyAllowed, which controls whether certificates added to stores of the type
+// may, must, or must not include a private key.
+const (
+	PrivateKeyForbidden = "Forbidden"
+	PrivateKeyOptional  = "Optional"
+	PrivateKeyRequired  = "Required"
+)
+
+// Values of CertificateStoreType.CustomAliasAllowed, which controls whether certificates added to or reenrolled in
+// stores of the type may, must, or must not be given an alias. Without a custom alias, the orchestrator names the
+// entry, usually after the certificate's thumbprint.
+const (
+	CustomAliasForbidden = "Forbidden"
+	CustomAliasOptional  = "Optional"
+	CustomAliasRequired  = "Required"
+)
+
 type StoreTypeSupportedOperations struct {
 	Add        bool `json:"Add"`
 	Create     bool `json:"Create"`
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net/http"
@@ -343,3 +345,158 @@ func TestClient_UpdateStoreType(t *testing.T) {
 	}
 	runStoreTypeTests(t, tests, c)
 }
+
+func TestClient_CreateStoreTypeWithOptions(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/KeyfactorAPI/CertificateStoreTypes" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]CertificateStoreType{
+			{StoreType: 2, Name: "IIS Personal", ShortName: "IIS", Capability: "IIS"},
+			{StoreType: 106, Name: "Azure Keyvault", ShortName: "AKV", Capability: "AzureKeyVault"},
+		})
+	})
+
+	tests := []struct {
+		name      string
+		storeType *CertificateStoreType
+		wantId    int
+	}{
+		{name: "ShortNameConflict", storeType: &CertificateStoreType{Name: "Azure Key Vault", ShortName: "akv", Capability: "AKV2"}, wantId: 106},
+		{name: "CapabilityConflict", storeType: &CertificateStoreType{Name: "IIS Bound", ShortName: "IISU", Capability: "iis"}, wantId: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.CreateStoreTypeWithOptions(tt.storeType, nil)
+			if !errors.Is(err, ErrAlreadyExists) {
+				t.Fatalf("CreateStoreTypeWithOptions() error = %v, want ErrAlreadyExists", err)
+			}
+			var exists *StoreTypeExistsError
+			if !errors.As(err, &exists) || exists.ExistingId != tt.wantId {
+				t.Errorf("CreateStoreTypeWithOptions() error = %#v, want existing ID %d", err, tt.wantId)
+			}
+		})
+	}
+}
+
+func TestClient_UpdateStoreType_RoundTrip(t *testing.T) {
+	var updated map[string]interface{}
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/KeyfactorAPI/CertificateStoreTypes/104":
+			w.Write([]byte(`{
+				"StoreType": 104, "Name": "Java Keystore", "ShortName": "JKS", "Capability": "JKS",
+				"PasswordOptions": {"EntrySupported": true, "StoreRequired": true, "Style": "Custom"},
+				"PowerShell": true, "PrivateKeyAllowed": "Required", "CustomAliasAllowed": "Required"
+			}`))
+		case r.Method == "PUT" && r.URL.Path == "/KeyfactorAPI/CertificateStoreTypes":
+			body, _ := io.ReadAll(r.Body)
+			json.Unmarshal(body, &updated)
+			w.Write(body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	st, err := c.GetCertificateStoreType(104)
+	if err != nil {
+		t.Fatalf("GetCertificateStoreType() error = %v", err)
+	}
+	want := StoreTypePasswordOptions{EntrySupported: true, StoreRequired: true, Style: PasswordStyleCustom}
+	if st.PasswordOptions == nil || *st.PasswordOptions != want || !st.PowerShell || st.PrivateKeyAllowed != PrivateKeyRequired {
+		t.Fatalf("GetCertificateStoreType() = %+v, password options %+v", st, st.PasswordOptions)
+	}
+
+	st.Name = "Java Keystore (managed)"
+	got, err := c.UpdateStoreType(st)
+	if err != nil {
+		t.Fatalf("UpdateStoreType() error = %v", err)
+	}
+	sent, _ := updated["PasswordOptions"].(map[string]interface{})
+	if sent["Style"] != PasswordStyleCustom || sent["EntrySupported"] != true || updated["PowerShell"] != true || updated["PrivateKeyAllowed"] != PrivateKeyRequired {
+		t.Errorf("UpdateStoreType() sent %v", updated)
+	}
+	if got.PasswordOptions == nil || *got.PasswordOptions != want || !got.PowerShell || got.PrivateKeyAllowed != PrivateKeyRequired {
+		t.Errorf("UpdateStoreType() = %+v, password options %+v", got, got.PasswordOptions)
+	}
+
+	st.PasswordOptions.StoreRequired = false
+	if _, err := c.UpdateStoreType(st); err != nil {
+		t.Fatalf("UpdateStoreType() error = %v", err)
+	}
+	if sent, _ := updated["PasswordOptions"].(map[string]interface{}); sent["StoreRequired"] != false {
+		t.Errorf("UpdateStoreType() did not send StoreRequired false: %v", updated["PasswordOptions"])
+	}
+
+	st.PrivateKeyAllowed = "Sometimes"
+	if _, err := c.UpdateStoreType(st); err == nil {
+		t.Errorf("UpdateStoreType() with invalid PrivateKeyAllowed succeeded, want error")
+	}
+}
+
+func TestClient_StoreTypeDisplayFields_RoundTrip(t *testing.T) {
+	var updated, created map[string]interface{}
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/KeyfactorAPI/CertificateStoreTypes/105":
+			w.Write([]byte(`{
+				"StoreType": 105, "Name": "IIS Bound Certificate", "ShortName": "IISU", "Capability": "IISU",
+				"LocalStore": true, "ServerRequired": true, "BlueprintAllowed": true, "ServerRegistration": 3,
+				"InventoryEndpoint": "/AnyInventory/Update", "JobProperties": ["SiteName"],
+				"ArgumentFormats": {"SiteName": "{0}"},
+				"Properties": [{"StoreTypeId": 105, "Name": "WinRm Port", "DisplayName": "WinRM Port", "Type": "String", "DefaultValue": "5986"}]
+			}`))
+		case r.Method == "PUT" && r.URL.Path == "/KeyfactorAPI/CertificateStoreTypes":
+			json.Unmarshal(body, &updated)
+			w.Write(body)
+		case r.Method == "POST" && r.URL.Path == "/KeyfactorAPI/CertificateStoreTypes":
+			json.Unmarshal(body, &created)
+			w.Write(body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	st, err := c.GetCertificateStoreType(105)
+	if err != nil {
+		t.Fatalf("GetCertificateStoreType() error = %v", err)
+	}
+	if !st.LocalStore || !st.ServerRequired || !st.BlueprintAllowed || st.ServerRegistration != 3 || len(st.ArgumentFormats) == 0 {
+		t.Fatalf("GetCertificateStoreType() = %+v", st)
+	}
+	if props := *st.Properties; len(props) != 1 || props[0].StoreTypeID != 105 || props[0].DisplayName != "WinRM Port" {
+		t.Errorf("GetCertificateStoreType() properties = %+v", props)
+	}
+
+	if _, err := c.UpdateStoreType(st); err != nil {
+		t.Fatalf("UpdateStoreType() error = %v", err)
+	}
+	for k, want := range map[string]interface{}{
+		"LocalStore": true, "ServerRequired": true, "BlueprintAllowed": true, "ServerRegistration": float64(3),
+		"InventoryEndpoint": "/AnyInventory/Update",
+	} {
+		if updated[k] != want {
+			t.Errorf("UpdateStoreType() sent %s = %v, want %v", k, updated[k], want)
+		}
+	}
+	if formats, _ := updated["ArgumentFormats"].(map[string]interface{}); formats["SiteName"] != "{0}" {
+		t.Errorf("UpdateStoreType() sent ArgumentFormats %v", updated["ArgumentFormats"])
+	}
+
+	st.Properties = &[]StoreTypePropertyDefinition{{Name: "ServerUseSsl", DisplayName: "Use SSL", Type: "Bool", DefaultValue: true}}
+	st.InventoryJobType = "49ff0e5c-6b44-4b41-8d26-1c27cbb37d1b"
+	if _, err := c.CreateStoreType(st); err != nil {
+		t.Fatalf("CreateStoreType() error = %v", err)
+	}
+	if created["Name"] != "IIS Bound Certificate" || created["InventoryJobTypeId"] != st.InventoryJobType {
+		t.Errorf("CreateStoreType() sent %v", created)
+	}
+	if props, _ := created["Properties"].([]interface{}); len(props) != 1 || props[0].(map[string]interface{})["DefaultValue"] != "true" {
+		t.Errorf("CreateStoreType() sent properties %v, want a string default", created["Properties"])
+	}
+}
@@ -12,6 +12,11 @@ import (
 // of certificate template context. The primary query required to get certificate context is the template ID. A pointer
 // to a GetTemplateResponse structure is returned, containing the template context.
 func (c *Client) GetTemplate(Id interface{}) (*GetTemplateResponse, error) {
+	return c.GetTemplateContext(context.Background(), Id)
+}
+
+// GetTemplateContext is like GetTemplate but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) GetTemplateContext(ctx context.Context, Id interface{}) (*GetTemplateResponse, error) {
 	if Id == 0 {
 		return nil, errors.New("template id required to get template")
 	}
@@ -19,12 +24,11 @@ func (c *Client) GetTemplate(Id interface{}) (*GetTemplateResponse, error) {
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
 	newId := Id.(int32)
 
-	resp, _, err := apiClient.TemplateApi.TemplateGetTemplate(context.Background(), newId).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, _, err := apiClient.TemplateApi.TemplateGetTemplate(ctx, newId).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
 		return nil, err
@@ -41,20 +45,24 @@ func (c *Client) GetTemplate(Id interface{}) (*GetTemplateResponse, error) {
 // GetTemplates asks Keyfactor for a complete list of known certificate templates. A list of
 // GetTemplateResponse structures is returned, containing the template context.
 func (c *Client) GetTemplates() ([]GetTemplateResponse, error) {
+	return c.GetTemplatesContext(context.Background())
+}
+
+// GetTemplatesContext is like GetTemplates but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) GetTemplatesContext(ctx context.Context) ([]GetTemplateResponse, error) {
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
-	resp, _, err := apiClient.TemplateApi.TemplateGetTemplates(context.Background()).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, _, err := apiClient.TemplateApi.TemplateGetTemplates(ctx).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
 		return nil, err
 	}
 
-	var newResp []GetTemplateResponse
+	newResp := make([]GetTemplateResponse, 0, len(resp))
 	for i, _ := range resp {
 		var newTemp GetTemplateResponse
 		mapResp, _ := resp[i].ToMap()
@@ -63,25 +71,29 @@ func (c *Client) GetTemplates() ([]GetTemplateResponse, error) {
 		newResp = append(newResp, newTemp)
 	}
 
-	return newResp, err
+	return newResp, nil
 }
 
 // UpdateTemplate takes arguments for a UpdateTemplateArg structure used to facilitate the modification
 // of a certificate template. Required parameters for this function are elements of UpdateTemplateArg that can't be set to nil. A pointer
 // to a UpdateTemplateResponse structure is returned, containing the template context.
 func (c *Client) UpdateTemplate(uta *UpdateTemplateArg) (*UpdateTemplateResponse, error) {
+	return c.UpdateTemplateContext(context.Background(), uta)
+}
+
+// UpdateTemplateContext is like UpdateTemplate but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) UpdateTemplateContext(ctx context.Context, uta *UpdateTemplateArg) (*UpdateTemplateResponse, error) {
 
 	xKeyfactorRequestedWith := "APIClient"
 	xKeyfactorApiVersion := "1"
 
-	configuration := keyfactor.NewConfiguration(make(map[string]string))
-	apiClient := keyfactor.NewAPIClient(configuration)
+	apiClient := c.sdkClient()
 
 	var newReq keyfactor.ModelsTemplateUpdateRequest
 	jsonData, _ := json.Marshal(newReq)
 	json.Unmarshal(jsonData, &newReq)
 
-	resp, _, err := apiClient.TemplateApi.TemplateUpdateTemplate(context.Background()).XKeyfactorRequestedWith(xKeyfactorRequestedWith).Template(newReq).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	resp, _, err := apiClient.TemplateApi.TemplateUpdateTemplate(ctx).XKeyfactorRequestedWith(xKeyfactorRequestedWith).Template(newReq).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"strings"
+)
+
+// EnrollmentType is a bit in the AllowedEnrollmentTypes of a template, naming a way certificates may be enrolled for
+// with it.
+type EnrollmentType int
+
+const (
+	EnrollmentTypePFX  EnrollmentType = 1
+	EnrollmentTypeCSR  EnrollmentType = 2
+	EnrollmentTypeAuto EnrollmentType = 4
+)
+
+// AvailableTemplatesOptions controls which templates GetAvailableEnrollmentTemplates returns.
+type AvailableTemplatesOptions struct {
+	// EnrollmentType limits the templates to those allowing the given type of enrollment, e.g. EnrollmentTypeCSR.
+	// Templates allowing any type of enrollment are returned if it is zero.
+	EnrollmentType EnrollmentType
+	// Requesters lists the identities the caller enrolls as, e.g. "DOMAIN\\jsmith" and the groups the user is a
+	// member of, and is checked against the allowed requesters of templates that restrict them. Defaults to the
+	// username the client logged in with.
+	Requesters []string
+}
+
+// GetAvailableEnrollmentTemplates asks Keyfactor for the certificate templates and returns those the caller may
+// enroll with: templates enabled for enrollment, allowing opts.EnrollmentType if given, and either open to all
+// requesters or listing one of the caller's identities as an allowed requester. This lets self-service tools offer
+// only templates an enrollment would be accepted for. opts may be nil.
+func (c *Client) GetAvailableEnrollmentTemplates(opts *AvailableTemplatesOptions) ([]GetTemplateResponse, error) {
+	return c.GetAvailableEnrollmentTemplatesContext(context.Background(), opts)
+}
+
+// GetAvailableEnrollmentTemplatesContext is like GetAvailableEnrollmentTemplates but uses ctx for the request,
+// allowing it to be cancelled.
+func (c *Client) GetAvailableEnrollmentTemplatesContext(ctx context.Context, opts *AvailableTemplatesOptions) ([]GetTemplateResponse, error) {
+	if opts == nil {
+		opts = &AvailableTemplatesOptions{}
+	}
+	requesters := opts.Requesters
+	if len(requesters) == 0 && c.username != "" {
+		requesters = []string{c.username}
+	}
+	c.infof("Getting Keyfactor templates available for enrollment to %s", strings.Join(requesters, ", "))
+
+	templates, err := c.GetTemplatesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	available := []GetTemplateResponse{}
+	for _, template := range templates {
+		if template.AllowedEnrollmentTypes == 0 {
+			continue
+		}
+		if opts.EnrollmentType != 0 && template.AllowedEnrollmentTypes&int(opts.EnrollmentType) == 0 {
+			continue
+		}
+		if template.UseAllowedRequesters && !allowsRequester(template.AllowedRequesters, requesters) {
+			continue
+		}
+		available = append(available, template)
+	}
+	c.debugf("%d of %d Keyfactor templates are available for enrollment", len(available), len(templates))
+	return available, nil
+}
+
+// allowsRequester reports whether any of requesters is in allowed, ignoring case.
+func allowsRequester(allowed, requesters []string) bool {
+	for _, a := range allowed {
+		for _, r := range requesters {
+			if strings.EqualFold(a, r) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestClient_GetAvailableEnrollmentTemplates(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/KeyfactorAPI/Templates":
+			w.Write([]byte(`[
+				{"Id": 1, "CommonName": "WebServer", "AllowedEnrollmentTypes": 3},
+				{"Id": 2, "CommonName": "CodeSigning", "AllowedEnrollmentTypes": 2, "UseAllowedRequesters": true, "AllowedRequesters": ["EXAMPLE\\Developers"]},
+				{"Id": 3, "CommonName": "User", "AllowedEnrollmentTypes": 1, "UseAllowedRequesters": true, "AllowedRequesters": ["EXAMPLE\\jsmith"]},
+				{"Id": 4, "CommonName": "Machine", "AllowedEnrollmentTypes": 0}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	c.username = "example\\JSmith"
+
+	tests := []struct {
+		name string
+		opts *AvailableTemplatesOptions
+		want []int
+	}{
+		{name: "ClientUser", want: []int{1, 3}},
+		{name: "Groups", opts: &AvailableTemplatesOptions{Requesters: []string{"EXAMPLE\\jdoe", "example\\developers"}}, want: []int{1, 2}},
+		{name: "CSR", opts: &AvailableTemplatesOptions{EnrollmentType: EnrollmentTypeCSR}, want: []int{1}},
+		{name: "PFX", opts: &AvailableTemplatesOptions{EnrollmentType: EnrollmentTypePFX}, want: []int{1, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.GetAvailableEnrollmentTemplates(tt.opts)
+			if err != nil {
+				t.Fatalf("GetAvailableEnrollmentTemplates() error = %v", err)
+			}
+			var ids []int
+			for _, template := range got {
+				ids = append(ids, template.Id)
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("GetAvailableEnrollmentTemplates() = %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Errorf("GetAvailableEnrollmentTemplates() = %v, want %v", ids, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
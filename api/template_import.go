@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Keyfactor/keyfactor-go-client-sdk/api/keyfactor"
+)
+
+// ImportTemplates takes arguments for a configuration tenant, such as an Active Directory forest, to facilitate a call
+// to Keyfactor that imports the certificate templates published there, rather than waiting for the scheduled template
+// synchronization. The templates that were not known to Keyfactor before the import are returned.
+func (c *Client) ImportTemplates(configurationTenant string) ([]GetTemplateResponse, error) {
+	return c.ImportTemplatesContext(context.Background(), configurationTenant)
+}
+
+// ImportTemplatesContext is like ImportTemplates but uses ctx for the requests, allowing it to be cancelled.
+func (c *Client) ImportTemplatesContext(ctx context.Context, configurationTenant string) ([]GetTemplateResponse, error) {
+	c.infof("Importing certificate templates from configuration tenant '%s'", configurationTenant)
+
+	if configurationTenant == "" {
+		return nil, errors.New("configuration tenant is required to import templates")
+	}
+
+	before, err := c.GetTemplatesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[int]bool, len(before))
+	for _, template := range before {
+		known[template.Id] = true
+	}
+
+	xKeyfactorRequestedWith := "APIClient"
+	xKeyfactorApiVersion := "1"
+
+	apiClient := c.sdkClient()
+
+	req := keyfactor.KeyfactorApiModelsConfigurationTenantConfigurationTenantRequest{ConfigurationTenant: &configurationTenant}
+	httpResp, err := apiClient.TemplateApi.TemplateImport(ctx).ConfigurationTenantRequest(req).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode != http.StatusNoContent && httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("[ERROR] Something unexpected happened, POST call to /Templates/Import returned status %d", httpResp.StatusCode)
+	}
+
+	after, err := c.GetTemplatesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var imported []GetTemplateResponse
+	for _, template := range after {
+		if !known[template.Id] {
+			imported = append(imported, template)
+		}
+	}
+	c.debugf("Imported %d new certificate templates", len(imported))
+	return imported, nil
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestClient_ImportTemplates(t *testing.T) {
+	var imported bool
+	var tenant string
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/KeyfactorAPI/Templates/Import":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			tenant = body["ConfigurationTenant"]
+			imported = true
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == "GET" && r.URL.Path == "/KeyfactorAPI/Templates":
+			if imported {
+				w.Write([]byte(`[{"Id": 1, "CommonName": "WebServer"}, {"Id": 2, "CommonName": "CodeSigning2024"}]`))
+			} else {
+				w.Write([]byte(`[{"Id": 1, "CommonName": "WebServer"}]`))
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	c.EnableCache(nil)
+
+	if _, err := c.ImportTemplates(""); err == nil {
+		t.Errorf("ImportTemplates(\"\") succeeded, want error")
+	}
+
+	got, err := c.ImportTemplates("corp.example.com")
+	if err != nil {
+		t.Fatalf("ImportTemplates() error = %v", err)
+	}
+	if tenant != "corp.example.com" {
+		t.Errorf("ImportTemplates() sent tenant %q", tenant)
+	}
+	if len(got) != 1 || got[0].Id != 2 || got[0].CommonName != "CodeSigning2024" {
+		t.Errorf("ImportTemplates() = %+v, want the new template only", got)
+	}
+}
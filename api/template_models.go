@@ -21,6 +21,7 @@ type GetTemplateResponse struct {
 	RFCEnforcement         bool                       `json:"RFCEnforcement,omitempty"`
 	RequiresApproval       bool                       `json:"RequiresApproval,omitempty"`
 	KeyUsage               int                        `json:"KeyUsage,omitempty"`
+	ExtendedKeyUsages      []TemplateExtendedKeyUsage `json:"ExtendedKeyUsages,omitempty"`
 }
 
 type TemplateEnrollmentFields struct {
@@ -30,6 +31,12 @@ type TemplateEnrollmentFields struct {
 	DataType int
 }
 
+type TemplateExtendedKeyUsage struct {
+	Id          int
+	Oid         string
+	DisplayName string
+}
+
 type TemplateMetadataFields struct {
 	Id           int
 	DefaultValue string
@@ -71,3 +78,38 @@ type UpdateTemplateArg struct {
 }
 
 type UpdateTemplateResponse struct{ GetTemplateResponse }
+
+type TemplateDefault struct {
+	SubjectPart string
+	Value       string
+}
+
+// TemplateEnrollmentPolicy is the resolved enrollment policy for a template, combining the template specific settings
+// with the global template settings they override. It is returned by GetTemplateEnrollmentPolicy.
+type TemplateEnrollmentPolicy struct {
+	TemplateId       int
+	TemplateName     string
+	KeyType          string
+	KeySize          string
+	TemplateRegexes  []TemplateRegex
+	TemplateDefaults []TemplateDefault
+	RSAValidKeySizes []int
+	ECCValidCurves   []string
+	AllowKeyReuse    bool
+	AllowWildcards   bool
+	RFCEnforcement   bool
+	EnrollmentFields []TemplateEnrollmentFields
+}
+
+// templateEnrollmentSettings is the response body of /Enrollment/Settings/{id}.
+type templateEnrollmentSettings struct {
+	TemplateRegexes  []TemplateRegex
+	TemplateDefaults []TemplateDefault
+	TemplatePolicy   *struct {
+		RSAValidKeySizes []int
+		ECCValidCurves   []string
+		AllowKeyReuse    bool
+		AllowWildcards   bool
+		RFCEnforcement   bool
+	}
+}
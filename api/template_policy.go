@@ -0,0 +1,226 @@
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// eccCurveAliases lists the names Keyfactor and Go use for the supported ECC curves, keyed by curve OID.
+var eccCurveAliases = map[string][]string{
+	"1.2.840.10045.3.1.7": {"P-256", "secp256r1", "prime256v1", "nistP256"},
+	"1.3.132.0.34":        {"P-384", "secp384r1", "nistP384"},
+	"1.3.132.0.35":        {"P-521", "secp521r1", "nistP521"},
+}
+
+// GetTemplateEnrollmentPolicy takes arguments for a template ID to facilitate a call to Keyfactor that retrieves the
+// resolved enrollment settings for the template, i.e. its subject part regexes and defaults, allowed key sizes and
+// curves, and custom enrollment field definitions. The returned policy can validate enrollment inputs client-side
+// before anything is submitted to the CA.
+func (c *Client) GetTemplateEnrollmentPolicy(templateId int) (*TemplateEnrollmentPolicy, error) {
+	return c.GetTemplateEnrollmentPolicyContext(context.Background(), templateId)
+}
+
+// GetTemplateEnrollmentPolicyContext is like GetTemplateEnrollmentPolicy but uses ctx for the request, allowing it to
+// be cancelled.
+func (c *Client) GetTemplateEnrollmentPolicyContext(ctx context.Context, templateId int) (*TemplateEnrollmentPolicy, error) {
+	c.infof("Getting enrollment policy for Keyfactor template with ID %d", templateId)
+	if templateId <= 0 {
+		return nil, errors.New("template id required to get template enrollment policy")
+	}
+
+	xKeyfactorRequestedWith := "APIClient"
+	xKeyfactorApiVersion := "1"
+
+	apiClient := c.sdkClient()
+
+	resp, _, err := apiClient.EnrollmentApi.EnrollmentGetTemplateEnrollmentSettings(ctx, int32(templateId)).XKeyfactorRequestedWith(xKeyfactorRequestedWith).XKeyfactorApiVersion(xKeyfactorApiVersion).Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	var settings templateEnrollmentSettings
+	mapResp, _ := resp.ToMap()
+	jsonData, _ := json.Marshal(mapResp)
+	if err := json.Unmarshal(jsonData, &settings); err != nil {
+		return nil, err
+	}
+
+	// Enrollment field definitions and the template's key algorithm are not part of the resolved settings.
+	template, err := c.GetTemplateContext(ctx, int32(templateId))
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &TemplateEnrollmentPolicy{
+		TemplateId:       templateId,
+		TemplateName:     template.CommonName,
+		KeyType:          template.KeyType,
+		KeySize:          template.KeySize,
+		TemplateRegexes:  settings.TemplateRegexes,
+		TemplateDefaults: settings.TemplateDefaults,
+		EnrollmentFields: template.EnrollmentFields,
+	}
+	if p := settings.TemplatePolicy; p != nil {
+		policy.RSAValidKeySizes = p.RSAValidKeySizes
+		policy.ECCValidCurves = p.ECCValidCurves
+		policy.AllowKeyReuse = p.AllowKeyReuse
+		policy.AllowWildcards = p.AllowWildcards
+		policy.RFCEnforcement = p.RFCEnforcement
+	}
+	return policy, nil
+}
+
+// Default returns the default value configured for a subject part such as "O" or "L", and whether one is set.
+func (p *TemplateEnrollmentPolicy) Default(subjectPart string) (string, bool) {
+	for _, d := range p.TemplateDefaults {
+		if strings.EqualFold(d.SubjectPart, subjectPart) {
+			return d.Value, true
+		}
+	}
+	return "", false
+}
+
+// ValidateSubject checks each populated part of subject against the policy's subject part regexes.
+func (p *TemplateEnrollmentPolicy) ValidateSubject(subject *CertificateSubject) error {
+	if subject == nil {
+		return nil
+	}
+	parts := []StringTuple{
+		{"CN", subject.SubjectCommonName},
+		{"O", subject.SubjectOrganization},
+		{"OU", subject.SubjectOrganizationalUnit},
+		{"L", subject.SubjectLocality},
+		{"ST", subject.SubjectState},
+		{"C", subject.SubjectCountry},
+	}
+
+	var problems []string
+	for _, part := range parts {
+		if part.Elem2 == "" {
+			continue
+		}
+		for _, regex := range p.TemplateRegexes {
+			if !strings.EqualFold(regex.SubjectPart, part.Elem1) {
+				continue
+			}
+			re, err := regexp.Compile(regex.RegEx)
+			if err != nil {
+				logTo(nil, LogLevelWarn, nil, "Ignoring invalid %s regex on template %s: %v", regex.SubjectPart, p.TemplateName, err)
+				continue
+			}
+			if !re.MatchString(part.Elem2) {
+				msg := regex.Error
+				if msg == "" {
+					msg = fmt.Sprintf("does not match %s", regex.RegEx)
+				}
+				problems = append(problems, fmt.Sprintf("%s %q %s", part.Elem1, part.Elem2, msg))
+			}
+		}
+	}
+	return p.problems(problems)
+}
+
+// ValidateSANs checks sans against the policy's SAN regexes, and rejects wildcard DNS names when the policy does not
+// allow them.
+func (p *TemplateEnrollmentPolicy) ValidateSANs(sans *SANs) error {
+	problems := validateSANs(p.TemplateName, p.TemplateRegexes, sans)
+	if sans != nil && !p.AllowWildcards {
+		for _, dns := range sans.DNS {
+			if strings.Contains(dns, "*") {
+				problems = append(problems, fmt.Sprintf("wildcard DNS SAN %q is not allowed", dns))
+			}
+		}
+	}
+	return p.problems(problems)
+}
+
+// ValidateEnrollmentFields checks that every field is defined on the template and that multiple choice fields hold
+// one of their options.
+func (p *TemplateEnrollmentPolicy) ValidateEnrollmentFields(fields map[string]interface{}) error {
+	return p.problems(validateEnrollmentFields(p.EnrollmentFields, fields))
+}
+
+// ValidateKeySize checks an RSA key size in bits against the policy's allowed sizes. An empty allow list permits any
+// size.
+func (p *TemplateEnrollmentPolicy) ValidateKeySize(bits int) error {
+	if len(p.RSAValidKeySizes) == 0 {
+		return nil
+	}
+	for _, size := range p.RSAValidKeySizes {
+		if size == bits {
+			return nil
+		}
+	}
+	sizes := make([]string, len(p.RSAValidKeySizes))
+	for i, size := range p.RSAValidKeySizes {
+		sizes[i] = strconv.Itoa(size)
+	}
+	return p.problems([]string{fmt.Sprintf("RSA key size %d is not allowed (allowed: %s)", bits, strings.Join(sizes, ", "))})
+}
+
+// ValidateCurve checks an ECC curve, given by OID or by name such as "P-256", against the policy's allowed curves. An
+// empty allow list permits any curve.
+func (p *TemplateEnrollmentPolicy) ValidateCurve(curve string) error {
+	if len(p.ECCValidCurves) == 0 {
+		return nil
+	}
+	for _, allowed := range p.ECCValidCurves {
+		if sameCurve(allowed, curve) {
+			return nil
+		}
+	}
+	return p.problems([]string{fmt.Sprintf("ECC curve %s is not allowed (allowed: %s)", curve, strings.Join(p.ECCValidCurves, ", "))})
+}
+
+// ValidatePublicKey checks the algorithm and size of pub, for example the key of a CSR about to be enrolled, against
+// the policy.
+func (p *TemplateEnrollmentPolicy) ValidatePublicKey(pub crypto.PublicKey) error {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return p.ValidateKeySize(key.N.BitLen())
+	case *ecdsa.PublicKey:
+		return p.ValidateCurve(key.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return p.ValidateCurve("Ed25519")
+	}
+	return p.problems([]string{fmt.Sprintf("unsupported public key type %T", pub)})
+}
+
+func (p *TemplateEnrollmentPolicy) problems(problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("enrollment does not satisfy template %s: %s", p.TemplateName, strings.Join(problems, "; "))
+}
+
+// sameCurve reports whether a and b name the same ECC curve.
+func sameCurve(a, b string) bool {
+	if strings.EqualFold(a, b) {
+		return true
+	}
+	for oid, aliases := range eccCurveAliases {
+		names := append([]string{oid}, aliases...)
+		if containsFold(names, a) && containsFold(names, b) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClient_GetTemplateEnrollmentPolicy(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/KeyfactorAPI/Enrollment/Settings/12":
+			w.Write([]byte(`{
+				"TemplateRegexes": [
+					{"SubjectPart": "CN", "Regex": "^[a-z0-9.-]+\\.example\\.com$", "Error": "must be in example.com"},
+					{"SubjectPart": "DNS", "Regex": "\\.example\\.com$"}
+				],
+				"TemplateDefaults": [{"SubjectPart": "O", "Value": "Example Inc"}],
+				"TemplatePolicy": {"RSAValidKeySizes": [2048, 4096], "ECCValidCurves": ["1.2.840.10045.3.1.7"], "AllowWildcards": false}
+			}`))
+		case "/KeyfactorAPI/Templates/12":
+			w.Write([]byte(`{
+				"Id": 12, "CommonName": "WebServer", "KeyType": "RSA", "KeySize": "2048",
+				"EnrollmentFields": [{"Id": 1, "Name": "Environment", "Options": ["prod", "test"], "DataType": 2}]
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	if _, err := c.GetTemplateEnrollmentPolicy(0); err == nil {
+		t.Errorf("GetTemplateEnrollmentPolicy(0) succeeded, want error")
+	}
+	if _, err := c.GetTemplateEnrollmentPolicy(99); err == nil {
+		t.Errorf("GetTemplateEnrollmentPolicy(99) succeeded, want error")
+	}
+
+	policy, err := c.GetTemplateEnrollmentPolicy(12)
+	if err != nil {
+		t.Fatalf("GetTemplateEnrollmentPolicy() error = %v", err)
+	}
+	if policy.TemplateName != "WebServer" || policy.KeyType != "RSA" || len(policy.RSAValidKeySizes) != 2 || len(policy.EnrollmentFields) != 1 {
+		t.Fatalf("GetTemplateEnrollmentPolicy() = %+v", policy)
+	}
+	if o, ok := policy.Default("o"); !ok || o != "Example Inc" {
+		t.Errorf("Default(o) = %q, %v", o, ok)
+	}
+
+	rsa1024, _ := rsa.GenerateKey(rand.Reader, 1024)
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+
+	tests := []struct {
+		name    string
+		check   func() error
+		wantErr string
+	}{
+		{name: "ValidSubject", check: func() error {
+			return policy.ValidateSubject(&CertificateSubject{SubjectCommonName: "www.example.com", SubjectOrganization: "Example Inc"})
+		}},
+		{name: "InvalidSubject", wantErr: `CN "www.example.org" must be in example.com`, check: func() error {
+			return policy.ValidateSubject(&CertificateSubject{SubjectCommonName: "www.example.org"})
+		}},
+		{name: "WildcardSAN", wantErr: `wildcard DNS SAN "*.example.com" is not allowed`, check: func() error {
+			return policy.ValidateSANs(&SANs{DNS: []string{"*.example.com"}})
+		}},
+		{name: "SANTypeNotAllowed", wantErr: "SAN type ip4 is not allowed", check: func() error {
+			return policy.ValidateSANs(&SANs{DNS: []string{"www.example.com"}, IP4: []string{"10.0.0.1"}})
+		}},
+		{name: "EnrollmentFieldOption", wantErr: `must be one of prod, test`, check: func() error {
+			return policy.ValidateEnrollmentFields(map[string]interface{}{"Environment": "dev"})
+		}},
+		{name: "KeySize", wantErr: "RSA key size 1024 is not allowed", check: func() error {
+			return policy.ValidatePublicKey(&rsa1024.PublicKey)
+		}},
+		{name: "CurveByName", check: func() error {
+			return policy.ValidatePublicKey(&p256.PublicKey)
+		}},
+		{name: "CurveNotAllowed", wantErr: "ECC curve P-384 is not allowed", check: func() error {
+			return policy.ValidatePublicKey(&p384.PublicKey)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.check()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizeThumbprint returns thumbprint in the form Keyfactor stores it: upper case hexadecimal with no separators.
+// Spaces, colons, and hyphens are removed, as are the invisible formatting characters that Windows adds when a
+// thumbprint is copied from the certificate dialog.
+func NormalizeThumbprint(thumbprint string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == ':' || r == '-' || unicode.IsSpace(r) || unicode.Is(unicode.Cf, r):
+			return -1
+		default:
+			return unicode.ToUpper(r)
+		}
+	}, thumbprint)
+}
+
+// ThumbprintsEqual reports whether a and b are the same thumbprint once normalized. Empty thumbprints are never equal.
+func ThumbprintsEqual(a, b string) bool {
+	na := NormalizeThumbprint(a)
+	return na != "" && na == NormalizeThumbprint(b)
+}
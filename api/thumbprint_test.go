@@ -0,0 +1,38 @@
+package api
+
+import "testing"
+
+func TestNormalizeThumbprint(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "Canonical", in: "0A1B2C3D", want: "0A1B2C3D"},
+		{name: "LowerCase", in: "0a1b2c3d", want: "0A1B2C3D"},
+		{name: "Colons", in: "0a:1b:2c:3d", want: "0A1B2C3D"},
+		{name: "Spaces", in: " 0a 1b 2c 3d\t", want: "0A1B2C3D"},
+		{name: "Hyphens", in: "0A-1B-2C-3D", want: "0A1B2C3D"},
+		{name: "WindowsCopy", in: "\u200e0a 1b 2c 3d", want: "0A1B2C3D"},
+		{name: "Empty", in: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeThumbprint(tt.in); got != tt.want {
+				t.Errorf("NormalizeThumbprint(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThumbprintsEqual(t *testing.T) {
+	if !ThumbprintsEqual("0a:1b:2c", "0A1B2C") {
+		t.Errorf("ThumbprintsEqual() = false for differently formatted thumbprints")
+	}
+	if ThumbprintsEqual("0A1B2C", "0A1B2D") {
+		t.Errorf("ThumbprintsEqual() = true for different thumbprints")
+	}
+	if ThumbprintsEqual("", " : ") {
+		t.Errorf("ThumbprintsEqual() = true for empty thumbprints")
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultRequestTimeout bounds each call to Keyfactor made by a client whose TimeoutConfig does not set Request.
+const DefaultRequestTimeout = 10 * time.Second
+
+// TimeoutConfig sets the timeouts used by a client, passed in AuthConfig.Timeouts. Zero fields use the defaults.
+type TimeoutConfig struct {
+	// Request bounds each call to Keyfactor, from dialing to reading the response body. It is the default for calls
+	// that neither set their own timeout with WithTimeout nor pass a context with a deadline. Defaults to
+	// DefaultRequestTimeout; a negative value disables it.
+	Request time.Duration
+	// Dial bounds establishing a TCP connection.
+	Dial time.Duration
+	// TLSHandshake bounds the TLS handshake.
+	TLSHandshake time.Duration
+	// ResponseHeader bounds the wait for the response headers once the request has been written, which is where a
+	// slow CA shows up during enrollment.
+	ResponseHeader time.Duration
+}
+
+type timeoutKey struct{}
+
+// WithTimeout returns a copy of ctx that makes calls using it time out after d instead of the client's default
+// request timeout, e.g. to give an enrollment longer than an inventory read:
+//
+//	cert, err := client.EnrollCSRContext(api.WithTimeout(ctx, 2*time.Minute), args)
+//
+// Unlike context.WithTimeout, the timeout starts with each request rather than immediately, and a zero or negative d
+// disables the client default for the call. It only applies to the methods taking a context.
+func WithTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, d)
+}
+
+// newHTTPClient returns the HTTP client used to reach Keyfactor, with the timeouts of cfg applied.
+func newHTTPClient(cfg *TimeoutConfig) *http.Client {
+	timeouts := TimeoutConfig{}
+	if cfg != nil {
+		timeouts = *cfg
+	}
+	switch {
+	case timeouts.Request == 0:
+		timeouts.Request = DefaultRequestTimeout
+	case timeouts.Request < 0:
+		timeouts.Request = 0
+	}
+
+	hc := &http.Client{Timeout: timeouts.Request}
+	if timeouts.Dial > 0 || timeouts.TLSHandshake > 0 || timeouts.ResponseHeader > 0 {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if timeouts.Dial > 0 {
+			transport.DialContext = (&net.Dialer{Timeout: timeouts.Dial, KeepAlive: 30 * time.Second}).DialContext
+		}
+		if timeouts.TLSHandshake > 0 {
+			transport.TLSHandshakeTimeout = timeouts.TLSHandshake
+		}
+		if timeouts.ResponseHeader > 0 {
+			transport.ResponseHeaderTimeout = timeouts.ResponseHeader
+		}
+		hc.Transport = transport
+	}
+	return hc
+}
+
+// timeoutTransport is an http.RoundTripper that bounds each request by the timeout set with WithTimeout, or else by
+// the client default. It takes the place of http.Client.Timeout, which cannot be overridden per call.
+type timeoutTransport struct {
+	base    http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	timeout := t.timeout
+	if d, ok := req.Context().Value(timeoutKey{}).(time.Duration); ok {
+		timeout = d
+	} else if _, ok := req.Context().Deadline(); ok {
+		// The caller's own deadline takes precedence over the default.
+		timeout = 0
+	}
+	if timeout <= 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases the timeout of a request once its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient_RequestTimeout(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte(`{}`))
+	})
+	c.httpClient.Timeout = 20 * time.Millisecond
+
+	deadline, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr bool
+	}{
+		{name: "ClientDefault", ctx: context.Background(), wantErr: true},
+		{name: "PerCallOverride", ctx: WithTimeout(context.Background(), time.Second)},
+		{name: "PerCallShorter", ctx: WithTimeout(context.Background(), 10*time.Millisecond), wantErr: true},
+		{name: "Disabled", ctx: WithTimeout(context.Background(), 0)},
+		{name: "CallerDeadline", ctx: deadline},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(tt.ctx, "GET", c.hostname+"/KeyfactorAPI/Status/Endpoints", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := c.instrumentedHTTPClient().Do(req)
+			if err == nil {
+				_, err = io.ReadAll(resp.Body)
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_newHTTPClient(t *testing.T) {
+	hc := newHTTPClient(nil)
+	if hc.Timeout != DefaultRequestTimeout || hc.Transport != nil {
+		t.Errorf("newHTTPClient(nil) = timeout %s, transport %v", hc.Timeout, hc.Transport)
+	}
+	if hc := newHTTPClient(&TimeoutConfig{Request: -1}); hc.Timeout != 0 {
+		t.Errorf("newHTTPClient(Request: -1) timeout = %s, want none", hc.Timeout)
+	}
+
+	hc = newHTTPClient(&TimeoutConfig{Request: time.Minute, TLSHandshake: 5 * time.Second, ResponseHeader: 30 * time.Second})
+	transport, ok := hc.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("newHTTPClient() transport = %T, want *http.Transport", hc.Transport)
+	}
+	if hc.Timeout != time.Minute || transport.TLSHandshakeTimeout != 5*time.Second || transport.ResponseHeaderTimeout != 30*time.Second {
+		t.Errorf("newHTTPClient() = timeout %s, TLS handshake %s, response header %s", hc.Timeout, transport.TLSHandshakeTimeout, transport.ResponseHeaderTimeout)
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Timestamp is a date and time returned by the Keyfactor API. Keyfactor formats timestamps differently depending on
+// the endpoint and Command release, sometimes without a zone designator; Timestamp accepts each of these and holds the
+// time in UTC. A timestamp that cannot be parsed leaves Time zero and is kept in Raw, so that no information is lost.
+type Timestamp struct {
+	time.Time
+	// Raw is the timestamp as returned by Keyfactor. It is empty if the timestamp was null or was not decoded from a
+	// response.
+	Raw string
+}
+
+// NewTimestamp returns a Timestamp holding t in UTC.
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{Time: t.UTC()}
+}
+
+// Valid reports whether the timestamp holds a parsed time.
+func (t Timestamp) Valid() bool {
+	return !t.Time.IsZero()
+}
+
+// String returns the time in RFC 3339 format, or Raw if it could not be parsed.
+func (t Timestamp) String() string {
+	if !t.Valid() {
+		return t.Raw
+	}
+	return t.Time.Format(time.RFC3339Nano)
+}
+
+// MarshalJSON encodes the timestamp as an RFC 3339 string, or as Raw if it could not be parsed. A timestamp that was
+// null is encoded as null.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if !t.Valid() && t.Raw == "" {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON decodes a Keyfactor timestamp. Null and empty timestamps leave t zero, and a timestamp in a format
+// that is not recognized is kept in Raw rather than failing the whole response.
+func (t *Timestamp) UnmarshalJSON(b []byte) error {
+	*t = Timestamp{}
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	if err := json.Unmarshal(b, &t.Raw); err != nil {
+		return fmt.Errorf("invalid Keyfactor timestamp %s: %v", b, err)
+	}
+	if parsed, err := parseKeyfactorTime(t.Raw); err == nil {
+		t.Time = parsed
+	}
+	return nil
+}
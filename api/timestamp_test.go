@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimestamp_UnmarshalJSON(t *testing.T) {
+	want := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		in        string
+		want      time.Time
+		wantRaw   string
+		wantValid bool
+		wantErr   bool
+	}{
+		{name: "RFC3339", in: `"2024-03-01T12:30:00Z"`, want: want, wantRaw: "2024-03-01T12:30:00Z", wantValid: true},
+		{name: "Offset", in: `"2024-03-01T07:30:00-05:00"`, want: want, wantRaw: "2024-03-01T07:30:00-05:00", wantValid: true},
+		{name: "NoZone", in: `"2024-03-01T12:30:00"`, want: want, wantRaw: "2024-03-01T12:30:00", wantValid: true},
+		{name: "FractionalNoZone", in: `"2024-03-01T12:30:00.5"`, want: want.Add(500 * time.Millisecond), wantRaw: "2024-03-01T12:30:00.5", wantValid: true},
+		{name: "Null", in: `null`},
+		{name: "Empty", in: `""`},
+		{name: "Unparseable", in: `"sometime in March"`, wantRaw: "sometime in March"},
+		{name: "NotAString", in: `1709296200`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Timestamp
+			err := json.Unmarshal([]byte(tt.in), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal(%s) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Valid() != tt.wantValid || !got.Time.Equal(tt.want) || got.Raw != tt.wantRaw {
+				t.Errorf("Unmarshal(%s) = %+v, want %v (raw %q)", tt.in, got, tt.want, tt.wantRaw)
+			}
+			if got.Valid() && got.Location() != time.UTC {
+				t.Errorf("Unmarshal(%s) location = %v, want UTC", tt.in, got.Location())
+			}
+		})
+	}
+}
+
+func TestTimestamp_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		ts   Timestamp
+		want string
+	}{
+		{name: "Time", ts: NewTimestamp(time.Date(2024, 3, 1, 7, 30, 0, 0, time.FixedZone("EST", -5*3600))), want: `"2024-03-01T12:30:00Z"`},
+		{name: "Raw", ts: Timestamp{Raw: "sometime in March"}, want: `"sometime in March"`},
+		{name: "Zero", want: `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.ts)
+			if err != nil || string(got) != tt.want {
+				t.Errorf("Marshal() = %s, %v, want %s", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCertificateResponse_Timestamps(t *testing.T) {
+	var cert GetCertificateResponse
+	body := `{"Id": 1, "ImportDate": "2024-01-02T03:04:05.123", "NotBefore": "2024-01-01T00:00:00Z", "NotAfter": "2025-01-01T00:00:00+02:00", "RevocationEffDate": null}`
+	if err := json.Unmarshal([]byte(body), &cert); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if want := time.Date(2024, 12, 31, 22, 0, 0, 0, time.UTC); !cert.NotAfter.Equal(want) {
+		t.Errorf("NotAfter = %v, want %v", cert.NotAfter, want)
+	}
+	if want := time.Date(2024, 1, 2, 3, 4, 5, 123000000, time.UTC); !cert.ImportDate.Equal(want) {
+		t.Errorf("ImportDate = %v, want %v", cert.ImportDate, want)
+	}
+	if cert.RevocationEffDate.Valid() {
+		t.Errorf("RevocationEffDate = %v, want zero", cert.RevocationEffDate)
+	}
+}
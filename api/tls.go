@@ -0,0 +1,211 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Keyfactor/keyfactor-go-client/query"
+)
+
+const (
+	// DefaultTLSRenewBefore is how long before expiry a TLSCertificateSource starts looking for a replacement.
+	DefaultTLSRenewBefore = 72 * time.Hour
+	// DefaultTLSRetryInterval is the minimum time between replacement lookups after a failed or fruitless attempt.
+	DefaultTLSRetryInterval = 5 * time.Minute
+)
+
+// GetTLSCertificate recovers a certificate and its private key from Keyfactor by certificate ID or thumbprint and
+// returns a tls.Certificate ready for use in a tls.Config. The private key must have been retained by Keyfactor. The
+// returned certificate includes the issuing chain, excluding any self-signed root.
+func (c *Client) GetTLSCertificate(certId int, thumbprint string) (*tls.Certificate, error) {
+	return c.GetTLSCertificateContext(context.Background(), certId, thumbprint)
+}
+
+// GetTLSCertificateContext is like GetTLSCertificate but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) GetTLSCertificateContext(ctx context.Context, certId int, thumbprint string) (*tls.Certificate, error) {
+	bundle, err := c.RecoverBundleContext(ctx, certId, thumbprint)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildTLSCertificate(bundle.PrivateKey, bundle.Certificate, bundle.Chain)
+}
+
+// TLSCertificateSource serves a Keyfactor-managed certificate to a tls.Config and swaps in the renewed certificate
+// as expiry approaches. Assign its GetCertificate method to tls.Config.GetCertificate. It is safe for concurrent use.
+type TLSCertificateSource struct {
+	// RenewBefore is how long before expiry the source starts looking for a renewed certificate.
+	RenewBefore time.Duration
+	// RetryInterval is the minimum time between lookups when a renewed certificate is not yet available.
+	RetryInterval time.Duration
+
+	fetch  func(ctx context.Context, current *x509.Certificate) (*tls.Certificate, error)
+	logger Logger
+
+	// mu guards the fields below. It is not held while a renewed certificate is fetched, so handshakes are served the
+	// current certificate in the meantime.
+	mu          sync.Mutex
+	cert        *tls.Certificate
+	lastAttempt time.Time
+	refreshing  bool
+}
+
+// NewTLSCertificateSource recovers the certificate identified by certId or thumbprint and returns a
+// TLSCertificateSource serving it. When the certificate enters its renewal window, the source searches Keyfactor
+// for the newest active certificate with the same common name and, if it expires later than the current one,
+// recovers and serves it instead.
+func (c *Client) NewTLSCertificateSource(certId int, thumbprint string) (*TLSCertificateSource, error) {
+	cert, err := c.GetTLSCertificate(certId, thumbprint)
+	if err != nil {
+		return nil, err
+	}
+	return &TLSCertificateSource{
+		RenewBefore:   DefaultTLSRenewBefore,
+		RetryInterval: DefaultTLSRetryInterval,
+		fetch:         c.fetchRenewedTLSCertificate,
+		logger:        c.loggerOrDefault(),
+		cert:          cert,
+	}, nil
+}
+
+// GetCertificate returns the current certificate, refreshing it first if it is within the renewal window. One
+// handshake at a time refreshes the certificate, with the context of its ClientHelloInfo; the others are served the
+// current certificate meanwhile. If a refresh fails, the existing certificate continues to be served until it expires.
+func (s *TLSCertificateSource) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	s.mu.Lock()
+	now := time.Now()
+	if s.cert != nil && now.Before(s.cert.Leaf.NotAfter.Add(-s.RenewBefore)) {
+		defer s.mu.Unlock()
+		return s.cert, nil
+	}
+	if s.refreshing || (s.cert != nil && now.Sub(s.lastAttempt) < s.RetryInterval) {
+		defer s.mu.Unlock()
+		return s.current(now)
+	}
+	s.refreshing = true
+	s.lastAttempt = now
+	var leaf *x509.Certificate
+	if s.cert != nil {
+		leaf = s.cert.Leaf
+	}
+	s.mu.Unlock()
+
+	var ctx context.Context
+	if hello != nil {
+		ctx = hello.Context()
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	renewed, err := s.fetch(ctx, leaf)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshing = false
+	if err != nil {
+		logTo(s.logger, LogLevelWarn, nil, "Unable to refresh TLS certificate from Keyfactor: %s", err)
+		return s.current(time.Now())
+	}
+	if renewed != nil && (leaf == nil || renewed.Leaf.NotAfter.After(leaf.NotAfter)) {
+		logTo(s.logger, LogLevelInfo, nil, "Serving renewed TLS certificate %s, valid until %s", renewed.Leaf.Subject, renewed.Leaf.NotAfter)
+		s.cert = renewed
+	}
+	return s.current(time.Now())
+}
+
+// current returns the cached certificate if it has not yet expired.
+func (s *TLSCertificateSource) current(now time.Time) (*tls.Certificate, error) {
+	if s.cert == nil {
+		return nil, errors.New("no TLS certificate available")
+	}
+	if now.After(s.cert.Leaf.NotAfter) {
+		return nil, fmt.Errorf("TLS certificate %s expired at %s and no renewed certificate was found", s.cert.Leaf.Subject, s.cert.Leaf.NotAfter)
+	}
+	return s.cert, nil
+}
+
+// fetchRenewedTLSCertificate looks up the newest active certificate sharing current's common name and recovers it
+// if it expires later than current. A nil certificate is returned when no newer certificate exists.
+func (c *Client) fetchRenewedTLSCertificate(ctx context.Context, current *x509.Certificate) (*tls.Certificate, error) {
+	if current == nil {
+		return nil, errors.New("no current certificate to renew")
+	}
+
+	qStr, err := query.Field("IssuedCN").Eq(current.Subject.CommonName).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "GET",
+		Endpoint: "Certificates",
+		Headers:  headers,
+		Context:  ctx,
+		Query: &apiQuery{
+			Query: []StringTuple{
+				{"pq.queryString", qStr},
+				{"pq.sortField", "NotAfter"},
+				{"pq.sortAscending", "1"},
+				{"pq.returnLimit", "1"},
+				{"pq.includeRevoked", "false"},
+				{"pq.includeExpired", "false"},
+			},
+		},
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonResp []GetCertificateResponse
+	err = c.decodeResponse(resp.Body, &jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	if len(jsonResp) == 0 {
+		return nil, nil
+	}
+
+	newest := jsonResp[0]
+	if !newest.NotAfter.Valid() || !newest.NotAfter.After(current.NotAfter) {
+		return nil, nil
+	}
+	return c.GetTLSCertificateContext(ctx, newest.Id, "")
+}
+
+// buildTLSCertificate assembles a tls.Certificate from a recovered private key, leaf, and chain.
+func buildTLSCertificate(priv interface{}, leaf *x509.Certificate, chain []*x509.Certificate) (*tls.Certificate, error) {
+	if priv == nil {
+		return nil, errors.New("certificate was recovered without a private key")
+	}
+	if leaf == nil {
+		return nil, errors.New("certificate was recovered without a leaf certificate")
+	}
+
+	cert := &tls.Certificate{
+		Certificate: [][]byte{leaf.Raw},
+		PrivateKey:  priv,
+		Leaf:        leaf,
+	}
+	for _, ca := range chain {
+		if ca.Equal(leaf) || isSelfSigned(ca) {
+			continue
+		}
+		cert.Certificate = append(cert.Certificate, ca.Raw)
+	}
+	return cert, nil
+}
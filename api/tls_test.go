@@ -0,0 +1,226 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_buildTLSCertificate(t *testing.T) {
+	chain := newTestChain(t)
+
+	tests := []struct {
+		name      string
+		priv      interface{}
+		leaf      *x509.Certificate
+		chain     []*x509.Certificate
+		wantCerts int
+		wantErr   bool
+	}{
+		{
+			name:      "ExcludesRootAndDuplicateLeaf",
+			priv:      chain.leafKey,
+			leaf:      chain.leaf,
+			chain:     []*x509.Certificate{chain.leaf, chain.intermediate, chain.root},
+			wantCerts: 2,
+		},
+		{
+			name:      "LeafOnly",
+			priv:      chain.leafKey,
+			leaf:      chain.leaf,
+			wantCerts: 1,
+		},
+		{
+			name:    "MissingKey",
+			leaf:    chain.leaf,
+			wantErr: true,
+		},
+		{
+			name:    "MissingLeaf",
+			priv:    chain.leafKey,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildTLSCertificate(tt.priv, tt.leaf, tt.chain)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("buildTLSCertificate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got.Certificate) != tt.wantCerts {
+				t.Errorf("buildTLSCertificate() returned %d certificates, want %d", len(got.Certificate), tt.wantCerts)
+			}
+			if !got.Leaf.Equal(tt.leaf) {
+				t.Errorf("buildTLSCertificate() leaf = %s, want %s", got.Leaf.Subject, tt.leaf.Subject)
+			}
+		})
+	}
+}
+
+func TestTLSCertificateSource_GetCertificate(t *testing.T) {
+	current := newTestChain(t)
+	renewed := newTestChain(t)
+	renewed.leaf.NotAfter = current.leaf.NotAfter.Add(24 * time.Hour)
+
+	currentCert, _ := buildTLSCertificate(current.leafKey, current.leaf, nil)
+	renewedCert, _ := buildTLSCertificate(renewed.leafKey, renewed.leaf, nil)
+
+	expired := newTestChain(t)
+	expired.leaf.NotAfter = time.Now().Add(-time.Minute)
+	expiredCert, _ := buildTLSCertificate(expired.leafKey, expired.leaf, nil)
+
+	tests := []struct {
+		name        string
+		cert        *tls.Certificate
+		renewBefore time.Duration
+		fetched     *tls.Certificate
+		fetchErr    error
+		want        *tls.Certificate
+		wantFetch   bool
+		wantErr     bool
+	}{
+		{
+			name:        "OutsideRenewalWindow",
+			cert:        currentCert,
+			renewBefore: time.Hour,
+			want:        currentCert,
+		},
+		{
+			name:        "SwapsToRenewed",
+			cert:        currentCert,
+			renewBefore: 48 * time.Hour,
+			fetched:     renewedCert,
+			want:        renewedCert,
+			wantFetch:   true,
+		},
+		{
+			name:        "NoRenewalYet",
+			cert:        currentCert,
+			renewBefore: 48 * time.Hour,
+			want:        currentCert,
+			wantFetch:   true,
+		},
+		{
+			name:        "FetchErrorKeepsCurrent",
+			cert:        currentCert,
+			renewBefore: 48 * time.Hour,
+			fetchErr:    errors.New("unavailable"),
+			want:        currentCert,
+			wantFetch:   true,
+		},
+		{
+			name:        "ExpiredWithoutRenewal",
+			cert:        expiredCert,
+			renewBefore: time.Hour,
+			wantFetch:   true,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetched := false
+			s := &TLSCertificateSource{
+				RenewBefore:   tt.renewBefore,
+				RetryInterval: time.Hour,
+				cert:          tt.cert,
+				fetch: func(context.Context, *x509.Certificate) (*tls.Certificate, error) {
+					fetched = true
+					return tt.fetched, tt.fetchErr
+				},
+			}
+			got, err := s.GetCertificate(nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetCertificate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if fetched != tt.wantFetch {
+				t.Errorf("GetCertificate() fetched = %v, want %v", fetched, tt.wantFetch)
+			}
+			if got != tt.want {
+				t.Errorf("GetCertificate() returned unexpected certificate")
+			}
+
+			// A second call inside the retry interval must not hit Keyfactor again
+			fetched = false
+			s.GetCertificate(nil)
+			if fetched {
+				t.Errorf("GetCertificate() fetched again within retry interval")
+			}
+		})
+	}
+}
+
+func TestTLSCertificateSource_GetCertificate_Concurrent(t *testing.T) {
+	current := newTestChain(t)
+	renewed := newTestChain(t)
+	renewed.leaf.NotAfter = current.leaf.NotAfter.Add(24 * time.Hour)
+	currentCert, _ := buildTLSCertificate(current.leafKey, current.leaf, nil)
+	renewedCert, _ := buildTLSCertificate(renewed.leafKey, renewed.leaf, nil)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	var fetches int32
+	s := &TLSCertificateSource{
+		RenewBefore:   48 * time.Hour,
+		RetryInterval: time.Hour,
+		cert:          currentCert,
+		fetch: func(ctx context.Context, _ *x509.Certificate) (*tls.Certificate, error) {
+			atomic.AddInt32(&fetches, 1)
+			close(started)
+			<-release
+			return renewedCert, ctx.Err()
+		},
+	}
+	done := make(chan *tls.Certificate)
+	go func() {
+		got, _ := s.GetCertificate(nil)
+		done <- got
+	}()
+	<-started
+
+	// Handshakes during the refresh are served the current certificate without waiting for it.
+	for i := 0; i < 3; i++ {
+		if got, err := s.GetCertificate(nil); err != nil || got != currentCert {
+			t.Errorf("GetCertificate() during refresh = %v, want the current certificate", err)
+		}
+	}
+	close(release)
+	if got := <-done; got != renewedCert {
+		t.Errorf("GetCertificate() that refreshed returned unexpected certificate")
+	}
+	if got, _ := s.GetCertificate(nil); got != renewedCert || atomic.LoadInt32(&fetches) != 1 {
+		t.Errorf("GetCertificate() after refresh fetched %d times, want the renewed certificate after 1", fetches)
+	}
+}
+
+func TestClient_fetchRenewedTLSCertificate(t *testing.T) {
+	chain := newTestChain(t)
+	var gotQuery string
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query().Get("pq.queryString")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]GetCertificateResponse{
+			{Id: 7, NotAfter: Timestamp{Raw: chain.leaf.NotAfter.UTC().Format("2006-01-02T15:04:05")}},
+		})
+	})
+
+	got, err := c.fetchRenewedTLSCertificate(context.Background(), chain.leaf)
+	if err != nil {
+		t.Fatalf("fetchRenewedTLSCertificate() error = %v", err)
+	}
+	if got != nil {
+		t.Errorf("fetchRenewedTLSCertificate() returned a certificate that does not expire later")
+	}
+	if want := `IssuedCN -eq "www.example.com"`; gotQuery != want {
+		t.Errorf("fetchRenewedTLSCertificate() query = %s, want %s", gotQuery, want)
+	}
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+// DefaultUserAgent is the User-Agent of requests to Keyfactor, which AuthConfig.UserAgent is appended to.
+const DefaultUserAgent = "keyfactor-go-client"
+
+// ApplicationNameHeader is the request header carrying AuthConfig.ApplicationName, which IIS can log as a custom field
+// to tell the tools sharing a service account apart.
+const ApplicationNameHeader = "x-keyfactor-application-name"
+
+// EnvCommandUserAgent and EnvCommandApplicationName set AuthConfig.UserAgent and AuthConfig.ApplicationName.
+const (
+	EnvCommandUserAgent       = "KEYFACTOR_USER_AGENT"
+	EnvCommandApplicationName = "KEYFACTOR_APPLICATION_NAME"
+)
+
+// userAgent returns the User-Agent the client sends: DefaultUserAgent followed by the configured product tokens, e.g.
+// "keyfactor-go-client terraform-provider-keyfactor/2.1.0".
+func userAgent(custom string) string {
+	custom = strings.TrimSpace(custom)
+	if custom == "" {
+		return DefaultUserAgent
+	}
+	return DefaultUserAgent + " " + custom
+}
+
+// userAgentTransport is an http.RoundTripper that identifies the client on requests built by the client and by the
+// Keyfactor SDK alike, replacing the SDK's generated User-Agent.
+type userAgentTransport struct {
+	base   http.RoundTripper
+	client *Client
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", userAgent(t.client.userAgent))
+	if t.client.applicationName != "" {
+		req.Header.Set(ApplicationNameHeader, t.client.applicationName)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestClient_UserAgent(t *testing.T) {
+	tests := []struct {
+		name            string
+		userAgent       string
+		applicationName string
+		wantUserAgent   string
+	}{
+		{name: "Default", wantUserAgent: DefaultUserAgent},
+		{name: "Custom", userAgent: "kfutil/1.6.0", applicationName: "kfutil", wantUserAgent: "keyfactor-go-client kfutil/1.6.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got http.Header
+			c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Clone()
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`[]`))
+			})
+			c.userAgent, c.applicationName = tt.userAgent, tt.applicationName
+
+			if _, err := c.sendRequest(&request{Method: "GET", Endpoint: "Status/Endpoints", Headers: &apiHeaders{}}); err != nil {
+				t.Fatalf("sendRequest() error = %v", err)
+			}
+			if ua := got.Get("User-Agent"); ua != tt.wantUserAgent {
+				t.Errorf("User-Agent = %q, want %q", ua, tt.wantUserAgent)
+			}
+			if got.Get(ApplicationNameHeader) != tt.applicationName {
+				t.Errorf("%s = %q, want %q", ApplicationNameHeader, got.Get(ApplicationNameHeader), tt.applicationName)
+			}
+
+			// Requests made through the SDK are identified the same way.
+			got = nil
+			c.sdkClient().StatusApi.StatusGetEndpoints(context.Background()).Execute()
+			if got == nil || got.Get("User-Agent") != tt.wantUserAgent {
+				t.Errorf("SDK request User-Agent = %q, want %q", got.Get("User-Agent"), tt.wantUserAgent)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+)
+
+// WindowsAuthConfig configures a client to authenticate to Keyfactor with Windows integrated authentication, passed in
+// AuthConfig.WindowsAuth, for Command instances whose API accepts only Negotiate and NTLM, as IIS does once basic
+// authentication is disabled. Requests are authenticated with Kerberos tokens from Negotiate when it is set, falling
+// back to NTLM with the Username, Domain, and Password of the AuthConfig.
+type WindowsAuthConfig struct {
+	// Negotiate supplies the SPNEGO tokens of Kerberos authentication. Without it, only NTLM is used.
+	Negotiate NegotiateProvider
+	// DisableNTLM turns off the NTLM fallback, for domains that block NTLM. Negotiate is then required.
+	DisableNTLM bool
+}
+
+// NegotiateProvider supplies SPNEGO tokens for Kerberos authentication, such as from a keytab with a Kerberos library
+// or from the logged on user's credentials with Windows SSPI.
+type NegotiateProvider interface {
+	// Token returns the SPNEGO token that authenticates to the service principal spn, e.g.
+	// "HTTP/keyfactor.example.com".
+	Token(ctx context.Context, spn string) ([]byte, error)
+}
+
+// NegotiateProviderFunc adapts a function to a NegotiateProvider.
+type NegotiateProviderFunc func(ctx context.Context, spn string) ([]byte, error)
+
+// Token calls f(ctx, spn).
+func (f NegotiateProviderFunc) Token(ctx context.Context, spn string) ([]byte, error) {
+	return f(ctx, spn)
+}
+
+// validate fills the NTLM credentials of auth from the environment, if they are not set. Credentials are only
+// required if there is no Negotiate provider to authenticate with instead.
+func (w *WindowsAuthConfig) validate(auth *AuthConfig) error {
+	if w.DisableNTLM {
+		if w.Negotiate == nil {
+			return errors.New("a Negotiate provider is required for Windows authentication without NTLM")
+		}
+		return nil
+	}
+	if err := loadBasicAuthFromEnv(auth); err != nil && w.Negotiate == nil {
+		return fmt.Errorf("windows authentication: %w", err)
+	}
+	return nil
+}
+
+// windowsAuthTransport is an http.RoundTripper that authenticates requests with Kerberos or NTLM. IIS authenticates
+// the connection rather than the request for NTLM, so a request is first sent as is, and the NTLM handshake is only
+// made when Keyfactor answers 401.
+type windowsAuthTransport struct {
+	base      http.RoundTripper
+	negotiate NegotiateProvider
+	ntlm      bool
+	domain    string
+	username  string
+	password  string
+
+	// handshakeMu keeps other requests off the connections while an NTLM handshake is made, as its messages must be
+	// sent on the same connection and a concurrent request could take it from the pool between them. When NTLM is in
+	// use, each request holds it for reading and a handshake holds it for writing.
+	handshakeMu sync.RWMutex
+}
+
+// windowsAuthBaseTransport returns the transport to send Windows authenticated requests with: base if it is an
+// *http.Transport of the client's own, or else a clone of http.DefaultTransport. NTLM authenticates connections, so
+// sharing a pool with other clients would let them reuse a connection authenticated as this one. HTTP/2 is disabled,
+// as IIS does not accept Windows authentication over it and NTLM needs a connection per handshake.
+func windowsAuthBaseTransport(base http.RoundTripper) *http.Transport {
+	transport, ok := base.(*http.Transport)
+	if !ok || transport == nil || transport == http.DefaultTransport {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	transport.ForceAttemptHTTP2 = false
+	transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	return transport
+}
+
+func newWindowsAuthTransport(base http.RoundTripper, auth *AuthConfig) *windowsAuthTransport {
+	domain, username := splitNTLMUser(auth.Username, auth.Domain)
+	return &windowsAuthTransport{
+		base:      base,
+		negotiate: auth.WindowsAuth.Negotiate,
+		ntlm:      !auth.WindowsAuth.DisableNTLM && auth.Username != "" && auth.Password != "",
+		domain:    domain,
+		username:  username,
+		password:  auth.Password,
+	}
+}
+
+func (t *windowsAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := replayableBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	authz := ""
+	if t.negotiate != nil {
+		spn := "HTTP/" + req.URL.Hostname()
+		token, err := t.negotiate.Token(req.Context(), spn)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get Kerberos token for %s: %w", spn, err)
+		}
+		authz = "Negotiate " + base64.StdEncoding.EncodeToString(token)
+	}
+	if !t.ntlm {
+		return t.send(req, body, authz)
+	}
+
+	t.handshakeMu.RLock()
+	resp, err := t.send(req, body, authz)
+	t.handshakeMu.RUnlock()
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	scheme := ntlmScheme(resp.Header.Values("WWW-Authenticate"))
+	if scheme == "" {
+		return resp, nil
+	}
+	discard(resp)
+
+	t.handshakeMu.Lock()
+	defer t.handshakeMu.Unlock()
+	for attempt := 1; ; attempt++ {
+		resp, moved, err := t.handshake(req, body, scheme)
+		if err != nil || resp.StatusCode != http.StatusUnauthorized || !moved || attempt == ntlmHandshakeAttempts {
+			return resp, err
+		}
+		// A request whose response body was still being read returned its connection to the pool during the
+		// handshake, and the authenticate message was sent on it instead of the challenged connection.
+		discard(resp)
+	}
+}
+
+// ntlmHandshakeAttempts is the number of times an NTLM handshake is made when its messages are not sent on one
+// connection.
+const ntlmHandshakeAttempts = 3
+
+// handshake sends req with body through the NTLM handshake of scheme, returning the response to the authenticate
+// message and whether it was sent on a different connection than the negotiate message.
+func (t *windowsAuthTransport) handshake(req *http.Request, body []byte, scheme string) (*http.Response, bool, error) {
+	var negotiateConn, authenticateConn net.Conn
+	resp, err := t.sendTraced(req, body, scheme+" "+base64.StdEncoding.EncodeToString(ntlmNegotiateMessage()), &negotiateConn)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, false, err
+	}
+	challengeMsg, ok := authenticateToken(resp.Header.Values("WWW-Authenticate"), scheme)
+	if !ok {
+		return resp, false, nil
+	}
+	discard(resp)
+	challenge, err := parseNTLMChallenge(challengeMsg)
+	if err != nil {
+		return nil, false, err
+	}
+	clientChallenge, err := ntlmClientChallenge()
+	if err != nil {
+		return nil, false, err
+	}
+	authenticate := ntlmAuthenticateMessage(challenge, t.domain, t.username, t.password, clientChallenge)
+	resp, err = t.sendTraced(req, body, scheme+" "+base64.StdEncoding.EncodeToString(authenticate), &authenticateConn)
+	return resp, err == nil && negotiateConn != authenticateConn, err
+}
+
+// send sends a copy of req with body and the Authorization header authz, or none if authz is empty. Basic
+// authorization set by the SDK from its environment is never sent.
+func (t *windowsAuthTransport) send(req *http.Request, body []byte, authz string) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.Header.Del("Authorization")
+	if authz != "" {
+		r.Header.Set("Authorization", authz)
+	}
+	if body != nil {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	}
+	return t.base.RoundTrip(r)
+}
+
+// sendTraced is like send but stores the connection the request is sent on in conn, if the base transport reports it.
+func (t *windowsAuthTransport) sendTraced(req *http.Request, body []byte, authz string, conn *net.Conn) (*http.Response, error) {
+	trace := &httptrace.ClientTrace{GotConn: func(info httptrace.GotConnInfo) { *conn = info.Conn }}
+	return t.send(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), body, authz)
+}
+
+// replayableBody reads the body of req so it can be sent once per step of a handshake.
+func replayableBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	defer req.Body.Close()
+	return io.ReadAll(req.Body)
+}
+
+// ntlmScheme returns the scheme to send NTLM messages with, NTLM or Negotiate, from the schemes a 401 response
+// offers, or "" if it offers neither. IIS accepts NTLM messages in Negotiate too, which some sites only offer.
+func ntlmScheme(challenges []string) string {
+	scheme := ""
+	for _, c := range challenges {
+		fields := strings.Fields(c)
+		switch {
+		case len(fields) == 0:
+		case strings.EqualFold(fields[0], "NTLM"):
+			return "NTLM"
+		case strings.EqualFold(fields[0], "Negotiate"):
+			scheme = "Negotiate"
+		}
+	}
+	return scheme
+}
+
+// authenticateToken returns the decoded token of the scheme's WWW-Authenticate challenge.
+func authenticateToken(challenges []string, scheme string) ([]byte, bool) {
+	for _, c := range challenges {
+		fields := strings.Fields(c)
+		if len(fields) != 2 || !strings.EqualFold(fields[0], scheme) {
+			continue
+		}
+		token, err := base64.StdEncoding.DecodeString(fields[1])
+		if err == nil {
+			return token, true
+		}
+	}
+	return nil, false
+}
+
+// discard reads and closes the body of resp, so its connection can be reused for the next step of a handshake.
+func discard(resp *http.Response) {
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
@@ -0,0 +1,255 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNTLMv2Response(t *testing.T) {
+	// Test vectors from MS-NLMP section 4.2.4.
+	avPair := func(id uint16, value string) []byte {
+		b := make([]byte, 4)
+		binary.LittleEndian.PutUint16(b, id)
+		binary.LittleEndian.PutUint16(b[2:], uint16(len(utf16le(value))))
+		return append(b, utf16le(value)...)
+	}
+	targetInfo := append(append(avPair(2, "Domain"), avPair(1, "Server")...), 0, 0, 0, 0)
+	serverChallenge, _ := hex.DecodeString("0123456789abcdef")
+	clientChallenge := bytes.Repeat([]byte{0xaa}, 8)
+
+	key := ntowfv2("Domain", "User", "Password")
+	if got := hex.EncodeToString(key); got != "0c868a403bfd7a93a3001ef22ef02e3f" {
+		t.Errorf("ntowfv2() = %s", got)
+	}
+	lm := hmacMD5(key, serverChallenge, clientChallenge)
+	if got := hex.EncodeToString(lm); got != "86c35097ac9cec102554764a57cccc19" {
+		t.Errorf("LMv2 response = %s", got)
+	}
+	ntProof := hmacMD5(key, serverChallenge, ntlmv2Temp(make([]byte, 8), clientChallenge, targetInfo))
+	if got := hex.EncodeToString(ntProof); got != "68cd0ab851e51c96aabc927bebef6a1c" {
+		t.Errorf("NTProofStr = %s", got)
+	}
+}
+
+// fakeIIS emulates IIS with Windows authentication, which authenticates connections with NTLM and requests with
+// Kerberos.
+type fakeIIS struct {
+	password string
+	ticket   string
+
+	mu            sync.Mutex
+	authenticated map[string]bool
+	challenges    map[string][]byte
+	requests      int
+	bodies        []string
+}
+
+func (s *fakeIIS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	body, _ := io.ReadAll(r.Body)
+
+	ok := s.authenticated[r.RemoteAddr]
+	authz := strings.Fields(r.Header.Get("Authorization"))
+	switch {
+	case ok:
+	case len(authz) == 2 && authz[0] == "Negotiate" && s.ticket != "":
+		token, _ := base64.StdEncoding.DecodeString(authz[1])
+		ok = string(token) == s.ticket
+	case len(authz) == 2 && authz[0] == "NTLM":
+		msg, _ := base64.StdEncoding.DecodeString(authz[1])
+		switch binary.LittleEndian.Uint32(msg[8:]) {
+		case 1:
+			challenge := make([]byte, 48)
+			copy(challenge, ntlmSignature)
+			binary.LittleEndian.PutUint32(challenge[8:], 2)
+			binary.LittleEndian.PutUint32(challenge[20:], ntlmNegotiateFlags|ntlmNegotiateTargetInfo)
+			copy(challenge[24:], "challeng")
+			targetInfo := []byte{7, 0, 8, 0, 1, 2, 3, 4, 5, 6, 7, 8, 0, 0, 0, 0}
+			binary.LittleEndian.PutUint16(challenge[40:], uint16(len(targetInfo)))
+			binary.LittleEndian.PutUint16(challenge[42:], uint16(len(targetInfo)))
+			binary.LittleEndian.PutUint32(challenge[44:], 48)
+			challenge = append(challenge, targetInfo...)
+			s.challenges[r.RemoteAddr] = challenge
+			w.Header().Set("WWW-Authenticate", "NTLM "+base64.StdEncoding.EncodeToString(challenge))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		case 3:
+			challenge, err := parseNTLMChallenge(s.challenges[r.RemoteAddr])
+			if err != nil {
+				break
+			}
+			field := func(i int) []byte {
+				length := binary.LittleEndian.Uint16(msg[12+i*8:])
+				offset := binary.LittleEndian.Uint32(msg[16+i*8:])
+				return msg[offset : offset+uint32(length)]
+			}
+			nt := field(1)
+			key := ntowfv2(string(bytes.ReplaceAll(field(2), []byte{0}, nil)), string(bytes.ReplaceAll(field(3), []byte{0}, nil)), s.password)
+			ok = len(nt) > 16 && bytes.Equal(nt[:16], hmacMD5(key, challenge.serverChallenge[:], nt[16:]))
+			s.authenticated[r.RemoteAddr] = ok
+		}
+	}
+	if !ok {
+		w.Header().Add("WWW-Authenticate", "Negotiate")
+		w.Header().Add("WWW-Authenticate", "NTLM")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	s.bodies = append(s.bodies, string(body))
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{}`))
+}
+
+func TestClient_WindowsAuth(t *testing.T) {
+	newClient := func(t *testing.T, iis *fakeIIS, auth *AuthConfig) *Client {
+		c := newTestServer(t, iis.ServeHTTP)
+		auth.Timeouts = nil
+		hc := newAuthenticatedHTTPClient(auth)
+		hc.Transport.(*windowsAuthTransport).base = c.httpClient.Transport
+		c.httpClient = hc
+		return c
+	}
+	send := func(c *Client, payload string) error {
+		resp, err := c.sendRequest(&request{Method: "POST", Endpoint: "Status/Endpoints", Headers: &apiHeaders{}, Payload: payload})
+		if err != nil {
+			return err
+		}
+		// Drain the response so the authenticated connection is reused.
+		discard(resp)
+		return nil
+	}
+
+	t.Run("NTLM", func(t *testing.T) {
+		iis := &fakeIIS{password: "Password", authenticated: map[string]bool{}, challenges: map[string][]byte{}}
+		c := newClient(t, iis, &AuthConfig{Username: `EXAMPLE\svc-keyfactor`, Password: "Password", WindowsAuth: &WindowsAuthConfig{}})
+		if err := send(c, "first"); err != nil {
+			t.Fatalf("sendRequest() error = %v", err)
+		}
+		// The request is answered 401, then the handshake takes two more requests.
+		if iis.requests != 3 || len(iis.bodies) != 1 || iis.bodies[0] != `"first"` {
+			t.Errorf("first request took %d requests with bodies %q, want 3", iis.requests, iis.bodies)
+		}
+		// The connection stays authenticated.
+		if err := send(c, "second"); err != nil || iis.requests != 4 {
+			t.Errorf("second sendRequest() error = %v after %d requests, want 4", err, iis.requests)
+		}
+	})
+
+	t.Run("NTLMConcurrent", func(t *testing.T) {
+		iis := &fakeIIS{password: "Password", authenticated: map[string]bool{}, challenges: map[string][]byte{}}
+		c := newClient(t, iis, &AuthConfig{Username: `EXAMPLE\svc-keyfactor`, Password: "Password", WindowsAuth: &WindowsAuthConfig{}})
+		// Requests on other connections must not take the connection of a handshake between its messages.
+		var wg sync.WaitGroup
+		errs := make(chan error, 32)
+		for i := 0; i < cap(errs); i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs <- send(c, fmt.Sprint(i))
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			if err != nil {
+				t.Errorf("concurrent sendRequest() error = %v", err)
+			}
+		}
+		if len(iis.bodies) != cap(errs) {
+			t.Errorf("%d of %d concurrent requests were authenticated", len(iis.bodies), cap(errs))
+		}
+	})
+
+	t.Run("DedicatedConnections", func(t *testing.T) {
+		iis := &fakeIIS{password: "Password", authenticated: map[string]bool{}, challenges: map[string][]byte{}}
+		srv := newTestServer(t, iis.ServeHTTP)
+		newDedicatedClient := func(auth *AuthConfig) *Client {
+			hc := newAuthenticatedHTTPClient(auth)
+			base := hc.Transport.(*windowsAuthTransport).base.(*http.Transport)
+			if base == http.DefaultTransport || base.ForceAttemptHTTP2 || base.TLSNextProto == nil {
+				t.Errorf("Windows authentication uses the shared or an HTTP/2 transport")
+			}
+			base.TLSClientConfig = srv.httpClient.Transport.(*http.Transport).TLSClientConfig.Clone()
+			return &Client{hostname: srv.hostname, httpClient: hc, apiPath: "KeyfactorAPI"}
+		}
+		a := newDedicatedClient(&AuthConfig{Username: "svc-keyfactor", Domain: "EXAMPLE", Password: "Password", WindowsAuth: &WindowsAuthConfig{}})
+		b := newDedicatedClient(&AuthConfig{Username: "svc-other", Domain: "EXAMPLE", Password: "wrong", WindowsAuth: &WindowsAuthConfig{}})
+		if err := send(a, "first"); err != nil {
+			t.Fatalf("sendRequest() error = %v", err)
+		}
+		// The connection authenticated for a must not serve b.
+		if err := send(b, "second"); err == nil {
+			t.Errorf("sendRequest() of another client with a wrong password succeeded")
+		}
+		if err := send(a, "third"); err != nil {
+			t.Errorf("second sendRequest() error = %v", err)
+		}
+	})
+
+	t.Run("WrongPassword", func(t *testing.T) {
+		iis := &fakeIIS{password: "Password", authenticated: map[string]bool{}, challenges: map[string][]byte{}}
+		c := newClient(t, iis, &AuthConfig{Username: "svc-keyfactor", Domain: "EXAMPLE", Password: "wrong", WindowsAuth: &WindowsAuthConfig{}})
+		if err := send(c, "first"); err == nil {
+			t.Errorf("sendRequest() with a wrong password succeeded")
+		}
+	})
+
+	t.Run("Kerberos", func(t *testing.T) {
+		iis := &fakeIIS{ticket: "kerberos-ticket", authenticated: map[string]bool{}, challenges: map[string][]byte{}}
+		var spn string
+		provider := NegotiateProviderFunc(func(ctx context.Context, s string) ([]byte, error) {
+			spn = s
+			return []byte("kerberos-ticket"), nil
+		})
+		c := newClient(t, iis, &AuthConfig{WindowsAuth: &WindowsAuthConfig{Negotiate: provider, DisableNTLM: true}})
+		if err := send(c, "first"); err != nil || iis.requests != 1 {
+			t.Errorf("sendRequest() error = %v after %d requests, want 1", err, iis.requests)
+		}
+		if spn != "HTTP/127.0.0.1" {
+			t.Errorf("Kerberos token requested for %s, want HTTP/127.0.0.1", spn)
+		}
+	})
+
+	t.Run("KerberosFallsBackToNTLM", func(t *testing.T) {
+		iis := &fakeIIS{password: "Password", authenticated: map[string]bool{}, challenges: map[string][]byte{}}
+		provider := NegotiateProviderFunc(func(ctx context.Context, s string) ([]byte, error) { return []byte("unknown"), nil })
+		c := newClient(t, iis, &AuthConfig{Username: "svc-keyfactor@example.com", Password: "Password", WindowsAuth: &WindowsAuthConfig{Negotiate: provider}})
+		if err := send(c, "first"); err != nil || iis.requests != 3 {
+			t.Errorf("sendRequest() error = %v after %d requests, want 3", err, iis.requests)
+		}
+	})
+}
+
+func TestWindowsAuthConfig_validate(t *testing.T) {
+	t.Setenv(EnvCommandUsername, "")
+	t.Setenv(EnvCommandPassword, "")
+	provider := NegotiateProviderFunc(func(ctx context.Context, spn string) ([]byte, error) { return nil, nil })
+	tests := []struct {
+		name    string
+		auth    *AuthConfig
+		wantErr bool
+	}{
+		{name: "NTLM", auth: &AuthConfig{Username: "svc", Password: "secret", WindowsAuth: &WindowsAuthConfig{}}},
+		{name: "NTLMWithoutCredentials", auth: &AuthConfig{WindowsAuth: &WindowsAuthConfig{}}, wantErr: true},
+		{name: "KerberosWithoutCredentials", auth: &AuthConfig{WindowsAuth: &WindowsAuthConfig{Negotiate: provider}}},
+		{name: "NoNTLMWithoutKerberos", auth: &AuthConfig{Username: "svc", Password: "secret", WindowsAuth: &WindowsAuthConfig{DisableNTLM: true}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.auth.WindowsAuth.validate(tt.auth); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// webhookStepExtension is the name of the built-in workflow step extension that sends an HTTP request.
+const webhookStepExtension = "RESTRequest"
+
+// WorkflowToken returns the Keyfactor workflow token for the named value, e.g. WorkflowToken("CN") yields "$(CN)".
+// Tokens are substituted by Keyfactor when a workflow step runs, so they can be embedded in webhook URLs and payloads.
+func WorkflowToken(name string) string {
+	return "$(" + name + ")"
+}
+
+// NewWebhookStep builds a workflow step that calls an external webhook using Keyfactor's REST request step. The
+// returned step can be appended to a definition with AddWorkflowDefinitionStep.
+func NewWebhookStep(args *WebhookStepArgs) (*WorkflowStep, error) {
+	if args == nil || args.UniqueName == "" {
+		return nil, errors.New("unique name is required for a webhook workflow step")
+	}
+	if args.URL == "" {
+		return nil, errors.New("url is required for a webhook workflow step")
+	}
+
+	method := strings.ToUpper(args.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+	contentType := args.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	displayName := args.DisplayName
+	if displayName == "" {
+		displayName = args.UniqueName
+	}
+
+	var content string
+	switch p := args.Payload.(type) {
+	case nil:
+	case string:
+		content = p
+	case []byte:
+		content = string(p)
+	default:
+		encoded, err := json.Marshal(p)
+		if err != nil {
+			return nil, fmt.Errorf("encoding webhook payload: %w", err)
+		}
+		content = string(encoded)
+	}
+
+	params := map[string]interface{}{
+		"URL":            args.URL,
+		"Verb":           method,
+		"ContentType":    contentType,
+		"RequestContent": content,
+	}
+	if len(args.Headers) > 0 {
+		params["Headers"] = args.Headers
+	}
+	if args.DataBucketProperty != "" {
+		params["DataBucketProperty"] = args.DataBucketProperty
+	}
+
+	return &WorkflowStep{
+		ExtensionName:           webhookStepExtension,
+		UniqueName:              args.UniqueName,
+		DisplayName:             displayName,
+		Enabled:                 true,
+		ConfigurationParameters: params,
+		Conditions:              args.Conditions,
+	}, nil
+}
+
+// SlackWebhookPayload returns a payload for a Slack incoming webhook that posts text to the webhook's channel. text
+// may contain workflow tokens.
+func SlackWebhookPayload(text string) map[string]string {
+	return map[string]string{"text": text}
+}
+
+// CreateWorkflowDefinition takes arguments for CreateWorkflowDefinitionArg to facilitate a call to Keyfactor that
+// creates a new, unpublished workflow definition.
+func (c *Client) CreateWorkflowDefinition(arg *CreateWorkflowDefinitionArg) (*WorkflowDefinition, error) {
+	c.infof("Creating new Keyfactor workflow definition")
+
+	if arg == nil || arg.DisplayName == "" || arg.WorkflowType == "" {
+		return nil, errors.New("display name and workflow type are required to create a workflow definition")
+	}
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "POST",
+		Endpoint: "Workflow/Definitions",
+		Headers:  headers,
+		Payload:  arg,
+	}
+
+	return c.sendWorkflowDefinitionRequest(keyfactorAPIStruct)
+}
+
+// GetWorkflowDefinition takes arguments for a workflow definition ID to facilitate a call to Keyfactor that returns
+// the latest version of the definition, including its steps.
+func (c *Client) GetWorkflowDefinition(id string) (*WorkflowDefinition, error) {
+	c.infof("Getting Keyfactor workflow definition with ID %s", id)
+
+	if id == "" {
+		return nil, errors.New("workflow definition id is required")
+	}
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "GET",
+		Endpoint: "Workflow/Definitions/" + id,
+		Headers:  headers,
+	}
+
+	return c.sendWorkflowDefinitionRequest(keyfactorAPIStruct)
+}
+
+// SetWorkflowDefinitionSteps takes arguments for a workflow definition ID and an ordered list of steps to facilitate
+// a call to Keyfactor that replaces the steps of the definition. If the latest version of the definition is
+// published, Keyfactor creates a new draft version; call PublishWorkflowDefinition for the change to take effect.
+func (c *Client) SetWorkflowDefinitionSteps(id string, steps []WorkflowStep) (*WorkflowDefinition, error) {
+	c.infof("Setting %d steps on Keyfactor workflow definition with ID %s", len(steps), id)
+
+	if id == "" {
+		return nil, errors.New("workflow definition id is required")
+	}
+	if steps == nil {
+		steps = []WorkflowStep{}
+	}
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "PUT",
+		Endpoint: fmt.Sprintf("Workflow/Definitions/%s/Steps", id),
+		Headers:  headers,
+		Payload:  steps,
+	}
+
+	return c.sendWorkflowDefinitionRequest(keyfactorAPIStruct)
+}
+
+// AddWorkflowDefinitionStep appends step to the existing steps of a workflow definition. A step with the same
+// UniqueName is replaced in place instead, so the call can be repeated safely.
+func (c *Client) AddWorkflowDefinitionStep(id string, step *WorkflowStep) (*WorkflowDefinition, error) {
+	if step == nil || step.UniqueName == "" {
+		return nil, errors.New("a workflow step with a unique name is required")
+	}
+
+	definition, err := c.GetWorkflowDefinition(id)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := make([]WorkflowStep, 0, len(definition.Steps)+1)
+	replaced := false
+	for _, existing := range definition.Steps {
+		if existing.UniqueName == step.UniqueName {
+			existing = *step
+			replaced = true
+		}
+		steps = append(steps, existing)
+	}
+	if !replaced {
+		steps = append(steps, *step)
+	}
+
+	return c.SetWorkflowDefinitionSteps(id, steps)
+}
+
+// PublishWorkflowDefinition takes arguments for a workflow definition ID to facilitate a call to Keyfactor that
+// publishes the latest version of the definition, making it the version used by new workflow instances.
+func (c *Client) PublishWorkflowDefinition(id string) (*WorkflowDefinition, error) {
+	c.infof("Publishing Keyfactor workflow definition with ID %s", id)
+
+	if id == "" {
+		return nil, errors.New("workflow definition id is required")
+	}
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "POST",
+		Endpoint: fmt.Sprintf("Workflow/Definitions/%s/Publish", id),
+		Headers:  headers,
+	}
+
+	return c.sendWorkflowDefinitionRequest(keyfactorAPIStruct)
+}
+
+// DeleteWorkflowDefinition takes arguments for a workflow definition ID, and makes an associated call to Keyfactor
+// to delete the definition.
+func (c *Client) DeleteWorkflowDefinition(id string) error {
+	c.infof("Deleting Keyfactor workflow definition with ID %s", id)
+
+	if id == "" {
+		return errors.New("workflow definition id is required")
+	}
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	keyfactorAPIStruct := &request{
+		Method:   "DELETE",
+		Endpoint: "Workflow/Definitions/" + id,
+		Headers:  headers,
+	}
+
+	_, err := c.sendRequest(keyfactorAPIStruct)
+	return err
+}
+
+// ListWorkflowInstances takes arguments for a query string, such as one built with the query package, to facilitate a
+// call to Keyfactor that returns the matching workflow instances, e.g. `Status -eq "Suspended"` for the instances
+// waiting for an approval. An empty query matches every instance. paging may be nil.
+func (c *Client) ListWorkflowInstances(q string, paging *Paging) ([]WorkflowInstance, error) {
+	return c.ListWorkflowInstancesContext(context.Background(), q, paging)
+}
+
+// ListWorkflowInstancesContext is like ListWorkflowInstances but uses ctx for the request, allowing it to be cancelled.
+func (c *Client) ListWorkflowInstancesContext(ctx context.Context, q string, paging *Paging) ([]WorkflowInstance, error) {
+	c.infof("Listing Keyfactor workflow instances matching query '%s'", q)
+
+	// Set Keyfactor-specific headers
+	headers := &apiHeaders{
+		Headers: []StringTuple{
+			{"x-keyfactor-api-version", "1"},
+			{"x-keyfactor-requested-with", "APIClient"},
+		},
+	}
+
+	var params []StringTuple
+	if q != "" {
+		params = append(params, StringTuple{"query.queryString", q})
+	}
+	params = append(params, paging.prefixedQuery("query")...)
+
+	keyfactorAPIStruct := &request{
+		Method:   "GET",
+		Endpoint: "Workflow/Instances",
+		Headers:  headers,
+		Query:    &apiQuery{Query: params},
+		Context:  ctx,
+	}
+
+	resp, err := c.sendRequest(keyfactorAPIStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonResp := []WorkflowInstance{}
+	err = c.decodeResponse(resp.Body, &jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
+
+// sendWorkflowDefinitionRequest sends a request whose response body is a workflow definition.
+func (c *Client) sendWorkflowDefinitionRequest(req *request) (*WorkflowDefinition, error) {
+	resp, err := c.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonResp := &WorkflowDefinition{}
+	err = c.decodeResponse(resp.Body, jsonResp)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResp, nil
+}
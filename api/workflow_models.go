@@ -0,0 +1,103 @@
+package api
+
+// WorkflowDefinition holds the response data returned by /Workflow/Definitions.
+type WorkflowDefinition struct {
+	Id               string         `json:"Id"`
+	DisplayName      string         `json:"DisplayName"`
+	Description      string         `json:"Description"`
+	Key              string         `json:"Key"`
+	KeyDisplayName   string         `json:"KeyDisplayName"`
+	IsPublished      bool           `json:"IsPublished"`
+	WorkflowType     string         `json:"WorkflowType"`
+	Steps            []WorkflowStep `json:"Steps"`
+	DraftVersion     int            `json:"DraftVersion"`
+	PublishedVersion int            `json:"PublishedVersion"`
+}
+
+// CreateWorkflowDefinitionArg holds the configuration required to create a new workflow definition. For enrollment
+// workflow types, Key is the ID of the certificate template the workflow applies to.
+type CreateWorkflowDefinitionArg struct {
+	DisplayName  string `json:"DisplayName"`
+	Description  string `json:"Description,omitempty"`
+	Key          string `json:"Key,omitempty"`
+	WorkflowType string `json:"WorkflowType"`
+}
+
+// WorkflowStep is a single step of a workflow definition. ConfigurationParameters are specific to the step's
+// extension; use NewWebhookStep to build a REST request step.
+type WorkflowStep struct {
+	ExtensionName           string                    `json:"ExtensionName"`
+	UniqueName              string                    `json:"UniqueName"`
+	DisplayName             string                    `json:"DisplayName"`
+	Enabled                 bool                      `json:"Enabled"`
+	ConfigurationParameters map[string]interface{}    `json:"ConfigurationParameters,omitempty"`
+	Signals                 []WorkflowSignalConfig    `json:"Signals,omitempty"`
+	Conditions              []WorkflowConditionConfig `json:"Conditions,omitempty"`
+	Outputs                 map[string]string         `json:"Outputs,omitempty"`
+}
+
+// WorkflowSignalConfig lists the security roles allowed to send a signal, such as an approval, to a workflow step.
+type WorkflowSignalConfig struct {
+	SignalName string `json:"SignalName"`
+	RoleIds    []int  `json:"RoleIds"`
+}
+
+// WorkflowConditionConfig is a condition that must evaluate to true for a workflow step to run.
+type WorkflowConditionConfig struct {
+	Value string `json:"Value"`
+}
+
+// WebhookStepArgs describes a workflow step that sends an HTTP request to an external webhook, such as a Slack or
+// Teams incoming webhook.
+type WebhookStepArgs struct {
+	// UniqueName identifies the step within the definition. Required.
+	UniqueName string
+	// DisplayName defaults to UniqueName.
+	DisplayName string
+	// URL of the webhook. Required. May contain workflow tokens.
+	URL string
+	// Method is the HTTP verb used for the request and defaults to POST.
+	Method string
+	// ContentType defaults to application/json.
+	ContentType string
+	// Headers are added to the request, e.g. an Authorization header.
+	Headers map[string]string
+	// Payload is the request body. A string is sent as-is; any other value is encoded as JSON. Workflow tokens such
+	// as WorkflowToken("CN") may appear anywhere in the payload and are substituted by Keyfactor when the step runs.
+	Payload interface{}
+	// DataBucketProperty optionally names the workflow data bucket property that receives the webhook's response.
+	DataBucketProperty string
+	// Conditions optionally restrict when the step runs.
+	Conditions []WorkflowConditionConfig
+}
+
+// Statuses of a WorkflowInstance.
+const (
+	WorkflowStatusRunning   = "Running"
+	WorkflowStatusSuspended = "Suspended"
+	WorkflowStatusComplete  = "Complete"
+	WorkflowStatusFailed    = "Failed"
+	WorkflowStatusRejected  = "Rejected"
+)
+
+// WorkflowInstance is a run of a workflow definition, returned by ListWorkflowInstances. An instance waiting for a
+// signal, such as the approval of a certificate request, has Status WorkflowStatusSuspended.
+type WorkflowInstance struct {
+	Id                     string                     `json:"Id"`
+	Status                 string                     `json:"Status"`
+	CurrentStepId          string                     `json:"CurrentStepId"`
+	CurrentStepDisplayName string                     `json:"CurrentStepDisplayName"`
+	CurrentStepUniqueName  string                     `json:"CurrentStepUniqueName"`
+	Title                  string                     `json:"Title"`
+	LastModified           Timestamp                  `json:"LastModified"`
+	StartDate              Timestamp                  `json:"StartDate"`
+	Definition             WorkflowInstanceDefinition `json:"Definition"`
+}
+
+// WorkflowInstanceDefinition identifies the definition a WorkflowInstance runs.
+type WorkflowInstanceDefinition struct {
+	Id           string `json:"Id"`
+	DisplayName  string `json:"DisplayName"`
+	Version      int    `json:"Version"`
+	WorkflowType string `json:"WorkflowType"`
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestNewWebhookStep(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        *WebhookStepArgs
+		wantContent string
+		wantErr     bool
+	}{
+		{
+			name: "SlackPayload",
+			args: &WebhookStepArgs{
+				UniqueName: "notify-slack",
+				URL:        "https://hooks.slack.com/services/T000/B000/XXXX",
+				Payload:    SlackWebhookPayload("Issued " + WorkflowToken("CN")),
+			},
+			wantContent: `{"text":"Issued $(CN)"}`,
+		},
+		{
+			name: "RawPayload",
+			args: &WebhookStepArgs{
+				UniqueName: "notify-raw",
+				URL:        "https://example.com/hook",
+				Payload:    "cn=$(CN)",
+			},
+			wantContent: "cn=$(CN)",
+		},
+		{name: "MissingURL", args: &WebhookStepArgs{UniqueName: "notify"}, wantErr: true},
+		{name: "MissingName", args: &WebhookStepArgs{URL: "https://example.com/hook"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewWebhookStep(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewWebhookStep() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.ExtensionName != webhookStepExtension || !got.Enabled || got.DisplayName != tt.args.UniqueName {
+				t.Errorf("NewWebhookStep() = %+v", got)
+			}
+			params := got.ConfigurationParameters
+			if params["RequestContent"] != tt.wantContent {
+				t.Errorf("NewWebhookStep() RequestContent = %v, want %s", params["RequestContent"], tt.wantContent)
+			}
+			if params["Verb"] != http.MethodPost || params["ContentType"] != "application/json" || params["URL"] != tt.args.URL {
+				t.Errorf("NewWebhookStep() parameters = %v", params)
+			}
+		})
+	}
+}
+
+func TestClient_AddWorkflowDefinitionStep(t *testing.T) {
+	existing := []WorkflowStep{
+		{ExtensionName: "Email", UniqueName: "email-requester", Enabled: true},
+		{ExtensionName: webhookStepExtension, UniqueName: "notify-slack", Enabled: false},
+	}
+	tests := []struct {
+		name      string
+		step      string
+		wantNames []string
+	}{
+		{name: "Append", step: "notify-teams", wantNames: []string{"email-requester", "notify-slack", "notify-teams"}},
+		{name: "ReplaceExisting", step: "notify-slack", wantNames: []string{"email-requester", "notify-slack"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var put []WorkflowStep
+			c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.Method + " " + r.URL.Path {
+				case "GET /KeyfactorAPI/Workflow/Definitions/def-1":
+					json.NewEncoder(w).Encode(WorkflowDefinition{Id: "def-1", Steps: existing})
+				case "PUT /KeyfactorAPI/Workflow/Definitions/def-1/Steps":
+					json.NewDecoder(r.Body).Decode(&put)
+					json.NewEncoder(w).Encode(WorkflowDefinition{Id: "def-1", Steps: put})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			})
+
+			step, err := NewWebhookStep(&WebhookStepArgs{UniqueName: tt.step, URL: "https://example.com/hook"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := c.AddWorkflowDefinitionStep("def-1", step)
+			if err != nil {
+				t.Fatalf("AddWorkflowDefinitionStep() error = %v", err)
+			}
+			if len(got.Steps) != len(tt.wantNames) {
+				t.Fatalf("AddWorkflowDefinitionStep() steps = %+v, want %v", got.Steps, tt.wantNames)
+			}
+			for i, name := range tt.wantNames {
+				if got.Steps[i].UniqueName != name {
+					t.Errorf("AddWorkflowDefinitionStep() step %d = %s, want %s", i, got.Steps[i].UniqueName, name)
+				}
+			}
+			for _, s := range got.Steps {
+				if s.UniqueName == tt.step && (!s.Enabled || s.ConfigurationParameters["URL"] != "https://example.com/hook") {
+					t.Errorf("AddWorkflowDefinitionStep() step %s was not updated: %+v", tt.step, s)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_ListWorkflowInstances(t *testing.T) {
+	var gotQuery url.Values
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/KeyfactorAPI/Workflow/Instances" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		gotQuery = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"Id": "5f6c3f2e", "Status": "Suspended", "Title": "Enrollment for app.example.com",
+			"LastModified": "2024-05-01T12:00:00Z", "Definition": {"DisplayName": "Approval", "WorkflowType": "Enrollment"}}]`))
+	})
+
+	got, err := c.ListWorkflowInstances(`Status -eq "Suspended"`, &Paging{PageReturned: 2, ReturnLimit: 50})
+	if err != nil {
+		t.Fatalf("ListWorkflowInstances() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Status != WorkflowStatusSuspended || got[0].Definition.WorkflowType != "Enrollment" || !got[0].LastModified.Valid() {
+		t.Errorf("ListWorkflowInstances() = %+v", got)
+	}
+	if gotQuery.Get("query.queryString") != `Status -eq "Suspended"` || gotQuery.Get("query.pageReturned") != "2" || gotQuery.Get("query.returnLimit") != "50" {
+		t.Errorf("ListWorkflowInstances() sent query %v", gotQuery)
+	}
+}
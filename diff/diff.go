@@ -0,0 +1,149 @@
+// Package diff compares the desired spec of a Keyfactor Command certificate store or store type against the live
+// object and reports field-level differences, to decide whether an update is needed and to render drift reports:
+//
+//	store, err := client.GetCertificateStoreByID(id)
+//	if err != nil {
+//		return err
+//	}
+//	if changes := diff.Store(desired, store); len(changes) > 0 {
+//		log.Printf("certificate store %s has drifted:\n%s", id, changes)
+//		_, err = client.UpdateStore(&api.UpdateStoreFctArgs{Id: id, CreateStoreFctArgs: *desired})
+//	}
+//
+// Inventory compares two store inventory snapshots taken with Client.SnapshotStoreInventories and reports the
+// certificates that were added, removed, or renewed in between.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Change is a single field that differs between the desired and the actual state.
+type Change struct {
+	// Field is the path of the field, e.g. "StorePath", "Properties.ServerUseSsl" or
+	// "EntryParameters[Port].Required".
+	Field string
+	// Want is the desired value, or nil if the field should not exist.
+	Want interface{}
+	// Got is the actual value, or nil if the field does not exist.
+	Got interface{}
+}
+
+// String renders the change as `Field: got -> want`.
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Field, formatValue(c.Got), formatValue(c.Want))
+}
+
+// Changes is the list of differences returned by Store and StoreType, ordered by field. It is empty when the actual
+// state matches the desired state.
+type Changes []Change
+
+// String renders the changes as a drift report with one line per field.
+func (cs Changes) String() string {
+	lines := make([]string, len(cs))
+	for i, c := range cs {
+		lines[i] = "  " + c.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Fields returns the path of every changed field.
+func (cs Changes) Fields() []string {
+	fields := make([]string, len(cs))
+	for i, c := range cs {
+		fields[i] = c.Field
+	}
+	return fields
+}
+
+// differ accumulates changes under a common field prefix.
+type differ struct {
+	prefix  string
+	changes *Changes
+}
+
+func newDiffer() differ {
+	return differ{changes: &Changes{}}
+}
+
+func (d differ) sub(prefix string) differ {
+	return differ{prefix: d.prefix + prefix, changes: d.changes}
+}
+
+func (d differ) add(field string, want, got interface{}) {
+	*d.changes = append(*d.changes, Change{Field: d.prefix + field, Want: want, Got: got})
+}
+
+// compare records field when want and got are not deeply equal.
+func (d differ) compare(field string, want, got interface{}) {
+	if !reflect.DeepEqual(want, got) {
+		d.add(field, want, got)
+	}
+}
+
+// compareFold records field when want and got differ other than in case.
+func (d differ) compareFold(field string, want, got string) {
+	if !strings.EqualFold(want, got) {
+		d.add(field, want, got)
+	}
+}
+
+func (d differ) result() Changes {
+	changes := *d.changes
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// sameValue reports whether two loosely typed values, such as property values decoded from JSON, are equal once
+// rendered as text, so that true matches "true" and 443 matches "443".
+func sameValue(a, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+	return textValue(a) == textValue(b)
+}
+
+func textValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case bool, float64, float32, int, int32, int64, json.Number:
+		return fmt.Sprint(v)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "<unset>"
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "<unset>"
+		}
+		v = rv.Elem().Interface()
+	}
+	switch v := v.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case bool, float64, float32, int, int32, int64:
+		return fmt.Sprint(v)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%+v", v)
+	}
+	return string(b)
+}
@@ -0,0 +1,165 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Keyfactor/keyfactor-go-client/api"
+)
+
+func TestStore(t *testing.T) {
+	approved := true
+	containerId := 3
+	actual := &api.GetCertificateStoreResponse{
+		Id:               "a1b2",
+		ClientMachine:    "IIS01.example.com",
+		StorePath:        "IIS Personal",
+		CertStoreType:    5,
+		Approved:         true,
+		AgentId:          "4C5E1C3A-0B7F-4C3D-9A4B-27C3F3C1D1AA",
+		ContainerId:      3,
+		PropertiesString: `{"WinRmPort":{"value":"5985"},"ServerUseSsl":{"value":"true"},"ServerPassword":{"value":{"SecretValue":null}}}`,
+		InventorySchedule: api.InventorySchedule{
+			Interval: &api.InventoryInterval{Minutes: 60},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		desired *api.CreateStoreFctArgs
+		want    []string
+	}{
+		{
+			name: "InSync",
+			desired: &api.CreateStoreFctArgs{
+				ClientMachine: "iis01.example.com",
+				StorePath:     "IIS Personal",
+				CertStoreType: 5,
+				AgentId:       "4c5e1c3a-0b7f-4c3d-9a4b-27c3f3c1d1aa",
+				Approved:      &approved,
+				ContainerId:   &containerId,
+				Properties: map[string]interface{}{
+					"WinRmPort":      5985,
+					"ServerUseSsl":   true,
+					"ServerPassword": map[string]interface{}{"SecretValue": "hunter2"},
+				},
+				InventorySchedule: &api.InventorySchedule{Interval: &api.InventoryInterval{Minutes: 60}},
+			},
+		},
+		{
+			name: "Drifted",
+			desired: &api.CreateStoreFctArgs{
+				ClientMachine:     "IIS01.example.com",
+				StorePath:         "IIS Revoked",
+				PropertiesString:  `{"WinRmPort":{"value":"5986"},"spnwithport":{"value":"false"}}`,
+				InventorySchedule: &api.InventorySchedule{Daily: &api.InventoryDaily{Time: "2023-01-01T02:00:00Z"}},
+			},
+			want: []string{"InventorySchedule", "Properties.WinRmPort", "Properties.spnwithport", "StorePath"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Store(tt.desired, actual)
+			if fields := got.Fields(); len(fields)+len(tt.want) > 0 && !reflect.DeepEqual(fields, tt.want) {
+				t.Errorf("Store() fields = %v, want %v\n%s", fields, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestStoreType(t *testing.T) {
+	actual := &api.CertificateStoreType{
+		StoreType:           101,
+		Name:                "Windows IIS",
+		ShortName:           "IISU",
+		Capability:          "IISU",
+		SupportedOperations: &api.StoreTypeSupportedOperations{Add: true, Remove: true},
+		Properties: &[]api.StoreTypePropertyDefinition{
+			{Name: "WinRmPort", Type: "String", DefaultValue: "5985"},
+			{Name: "spnwithport", Type: "Bool", DefaultValue: "false"},
+		},
+		EntryParameters: &[]api.EntryParameter{{Name: "Port", Type: "String", DefaultValue: "443"}},
+		JobProperties:   &[]string{"b", "a"},
+	}
+
+	desired := *actual
+	desired.StoreType = 0
+	desired.JobProperties = &[]string{"a", "b"}
+	if got := StoreType(&desired, actual); len(got) != 0 {
+		t.Fatalf("StoreType() = %v, want no changes", got)
+	}
+
+	desired.SupportedOperations = &api.StoreTypeSupportedOperations{Add: true, Remove: true, Create: true}
+	desired.Properties = &[]api.StoreTypePropertyDefinition{
+		{Name: "WinRmPort", Type: "String", DefaultValue: "5986", Required: true},
+		{Name: "WinRmProtocol", Type: "MultipleChoice"},
+	}
+	desired.EntryParameters = nil
+	got := StoreType(&desired, actual)
+	want := []string{
+		"EntryParameters[Port]",
+		"Properties[WinRmPort].DefaultValue",
+		"Properties[WinRmPort].Required",
+		"Properties[WinRmProtocol]",
+		"Properties[spnwithport]",
+		"SupportedOperations.Create",
+	}
+	if !reflect.DeepEqual(got.Fields(), want) {
+		t.Fatalf("StoreType() fields = %v, want %v", got.Fields(), want)
+	}
+
+	report := got.String()
+	for _, line := range []string{
+		`Properties[WinRmPort].DefaultValue: "5985" -> "5986"`,
+		`SupportedOperations.Create: false -> true`,
+		`EntryParameters[Port]: {`,
+	} {
+		if !strings.Contains(report, line) {
+			t.Errorf("report missing %q:\n%s", line, report)
+		}
+	}
+	if !strings.Contains(report, "Properties[spnwithport]: {") || !strings.Contains(report, "-> <unset>") {
+		t.Errorf("report does not show removed property:\n%s", report)
+	}
+}
+
+func TestInventory(t *testing.T) {
+	entry := func(alias, thumbprint string) api.CertStoreInventory {
+		return api.CertStoreInventory{Name: alias, Certificates: []api.InventoriedCertificate{{Thumbprint: thumbprint}}}
+	}
+	before := &api.InventorySnapshot{Stores: map[string][]api.CertStoreInventory{
+		"s1":      {entry("web", "AAAA"), entry("api", "BBBB"), entry("old", "CCCC")},
+		"s2":      {entry("app", "DDDD")},
+		"skipped": {entry("gone", "EEEE")},
+	}}
+	after := &api.InventorySnapshot{Stores: map[string][]api.CertStoreInventory{
+		"s1":  {entry("web", "aaaa"), entry("api", "FFFF"), entry("new", "1111")},
+		"s2":  {entry("app", "DDDD")},
+		"new": {entry("fresh", "2222")},
+	}}
+
+	got := Inventory(before, after)
+	want := []struct {
+		kind  InventoryChangeKind
+		alias string
+	}{
+		{InventoryRenewed, "api"},
+		{InventoryAdded, "new"},
+		{InventoryRemoved, "old"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Inventory() = %+v, want %d changes", got, len(want))
+	}
+	for i, w := range want {
+		if got[i].StoreId != "s1" || got[i].Kind != w.kind || got[i].Alias != w.alias {
+			t.Errorf("Inventory()[%d] = %+v, want %s %s", i, got[i], w.kind, w.alias)
+		}
+	}
+	if got[0].Before.Thumbprint != "BBBB" || got[0].After.Thumbprint != "FFFF" {
+		t.Errorf("renewed change = %+v", got[0])
+	}
+	if got[1].Before != nil || got[2].After != nil {
+		t.Errorf("added/removed changes should have no before/after certificate: %+v", got[1:])
+	}
+}
@@ -0,0 +1,102 @@
+package diff
+
+import (
+	"sort"
+
+	"github.com/Keyfactor/keyfactor-go-client/api"
+)
+
+// InventoryChangeKind is the kind of change found between two inventory snapshots.
+type InventoryChangeKind string
+
+const (
+	// InventoryAdded is a certificate under an alias that was not in the earlier snapshot.
+	InventoryAdded InventoryChangeKind = "added"
+	// InventoryRemoved is an alias that is no longer in the store.
+	InventoryRemoved InventoryChangeKind = "removed"
+	// InventoryRenewed is an alias that now holds a different certificate, e.g. after a renewal or replacement.
+	InventoryRenewed InventoryChangeKind = "renewed"
+)
+
+// InventoryChange is a change to a single alias of a certificate store.
+type InventoryChange struct {
+	Kind    InventoryChangeKind
+	StoreId string
+	Alias   string
+	// Before and After are the certificates under the alias in each snapshot. Before is nil for an added alias and
+	// After is nil for a removed one.
+	Before *api.InventoriedCertificate
+	After  *api.InventoriedCertificate
+}
+
+// Inventory compares two inventory snapshots and returns the certificates that were added, removed, or renewed in
+// each store, ordered by store and alias. Entries are matched by alias and compared by thumbprint. Only stores
+// present in both snapshots are compared, so a store that could not be inventoried is not reported as emptied.
+func Inventory(before, after *api.InventorySnapshot) []InventoryChange {
+	var changes []InventoryChange
+	if before == nil || after == nil {
+		return changes
+	}
+
+	storeIds := make([]string, 0, len(after.Stores))
+	for id := range after.Stores {
+		if _, ok := before.Stores[id]; ok {
+			storeIds = append(storeIds, id)
+		}
+	}
+	sort.Strings(storeIds)
+
+	for _, id := range storeIds {
+		was := inventoryEntries(before.Stores[id])
+		now := inventoryEntries(after.Stores[id])
+		var aliases []string
+		for alias := range was {
+			aliases = append(aliases, alias)
+		}
+		for alias := range now {
+			if _, ok := was[alias]; !ok {
+				aliases = append(aliases, alias)
+			}
+		}
+		sort.Strings(aliases)
+
+		for _, alias := range aliases {
+			w, inBefore := was[alias]
+			n, inAfter := now[alias]
+			change := InventoryChange{StoreId: id, Alias: alias, Before: w, After: n}
+			switch {
+			case !inBefore:
+				change.Kind = InventoryAdded
+			case !inAfter:
+				change.Kind = InventoryRemoved
+			case !sameCertificate(w, n):
+				change.Kind = InventoryRenewed
+			default:
+				continue
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// inventoryEntries maps each alias in inventory to the certificate stored under it. The first certificate of an entry
+// is the end-entity certificate; any others are its chain.
+func inventoryEntries(inventory []api.CertStoreInventory) map[string]*api.InventoriedCertificate {
+	entries := make(map[string]*api.InventoriedCertificate, len(inventory))
+	for i := range inventory {
+		var cert *api.InventoriedCertificate
+		if len(inventory[i].Certificates) > 0 {
+			cert = &inventory[i].Certificates[0]
+		}
+		entries[inventory[i].Name] = cert
+	}
+	return entries
+}
+
+func sameCertificate(a, b *api.InventoriedCertificate) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return api.ThumbprintsEqual(a.Thumbprint, b.Thumbprint)
+}
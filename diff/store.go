@@ -0,0 +1,128 @@
+package diff
+
+import (
+	"encoding/json"
+
+	"github.com/Keyfactor/keyfactor-go-client/api"
+)
+
+// Store compares the desired spec of a certificate store against the live store. Only the fields set in desired are
+// compared: nil pointers, an empty AgentId and a zero CertStoreType mean "leave as is". Properties are compared by
+// name, with values matched as text so that true and "true" are equal; properties that hold secrets are skipped
+// because Keyfactor does not return their values. Passwords are write-only and never compared.
+func Store(desired *api.CreateStoreFctArgs, actual *api.GetCertificateStoreResponse) Changes {
+	d := newDiffer()
+	if desired == nil || actual == nil {
+		return d.result()
+	}
+
+	d.compareFold("ClientMachine", desired.ClientMachine, actual.ClientMachine)
+	d.compare("StorePath", desired.StorePath, actual.StorePath)
+	if desired.CertStoreType != 0 {
+		d.compare("CertStoreType", desired.CertStoreType, actual.CertStoreType)
+	}
+	if desired.AgentId != "" {
+		d.compareFold("AgentId", desired.AgentId, actual.AgentId)
+	}
+	if desired.ContainerId != nil {
+		d.compare("ContainerId", *desired.ContainerId, actual.ContainerId)
+	}
+	if desired.ContainerName != nil {
+		d.compare("ContainerName", *desired.ContainerName, actual.ContainerName)
+	}
+	if desired.Approved != nil {
+		d.compare("Approved", *desired.Approved, actual.Approved)
+	}
+	if desired.CreateIfMissing != nil {
+		d.compare("CreateIfMissing", *desired.CreateIfMissing, actual.CreateIfMissing)
+	}
+	if desired.AgentAssigned != nil {
+		d.compare("AgentAssigned", *desired.AgentAssigned, actual.AgentAssigned)
+	}
+	if desired.SetNewPasswordAllowed != nil {
+		d.compare("SetNewPasswordAllowed", *desired.SetNewPasswordAllowed, actual.SetNewPasswordAllowed)
+	}
+	if desired.InventorySchedule != nil && !desired.InventorySchedule.Equal(actual.InventorySchedule) {
+		d.add("InventorySchedule", *desired.InventorySchedule, actual.InventorySchedule)
+	}
+
+	storeProperties(d.sub("Properties."), desiredProperties(desired), actualProperties(actual))
+	return d.result()
+}
+
+// storeProperties compares the desired properties against the actual ones. Properties that are not in desired are
+// left alone, as Keyfactor fills in defaults for them.
+func storeProperties(d differ, desired, actual map[string]interface{}) {
+	for name, want := range desired {
+		if isSecret(want) {
+			continue
+		}
+		got, ok := actual[name]
+		if !ok {
+			d.add(name, want, nil)
+			continue
+		}
+		if isSecret(got) {
+			continue
+		}
+		if !sameValue(want, got) {
+			d.add(name, want, got)
+		}
+	}
+}
+
+// desiredProperties returns the properties of desired as plain values, taken from Properties or, if that is not set,
+// from PropertiesString.
+func desiredProperties(desired *api.CreateStoreFctArgs) map[string]interface{} {
+	if desired.Properties != nil {
+		return unwrapProperties(desired.Properties)
+	}
+	return parseProperties(desired.PropertiesString)
+}
+
+func actualProperties(actual *api.GetCertificateStoreResponse) map[string]interface{} {
+	if actual.Properties != nil {
+		return unwrapProperties(actual.Properties)
+	}
+	return parseProperties(actual.PropertiesString)
+}
+
+func parseProperties(properties string) map[string]interface{} {
+	m := map[string]interface{}{}
+	if properties == "" {
+		return m
+	}
+	if err := json.Unmarshal([]byte(properties), &m); err != nil {
+		return map[string]interface{}{}
+	}
+	return unwrapProperties(m)
+}
+
+// unwrapProperties replaces the {"value": v} wrappers Keyfactor uses for property values with v.
+func unwrapProperties(properties map[string]interface{}) map[string]interface{} {
+	m := make(map[string]interface{}, len(properties))
+	for name, v := range properties {
+		if wrapped, ok := v.(map[string]interface{}); ok && len(wrapped) == 1 {
+			if inner, ok := wrapped["value"]; ok {
+				v = inner
+			}
+		}
+		m[name] = v
+	}
+	return m
+}
+
+// isSecret reports whether a property value is a secret, e.g. {"SecretValue": "..."} or a PAM provider reference.
+func isSecret(v interface{}) bool {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, key := range []string{"SecretValue", "Provider", "Parameters"} {
+			if _, ok := v[key]; ok {
+				return true
+			}
+		}
+	case *api.StoreSecret, api.StoreSecret:
+		return true
+	}
+	return false
+}
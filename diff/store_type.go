@@ -0,0 +1,180 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Keyfactor/keyfactor-go-client/api"
+)
+
+// StoreType compares the desired definition of a certificate store type, e.g. one read from an orchestrator
+// extension's integration manifest, against the live store type. Unlike Store, the definition is compared in full,
+// except that a zero StoreType ID and nil SupportedOperations or PasswordOptions are not compared. Properties and
+// entry parameters are matched by name and compared attribute by attribute, and JobProperties is compared as a set.
+func StoreType(desired, actual *api.CertificateStoreType) Changes {
+	d := newDiffer()
+	if desired == nil || actual == nil {
+		return d.result()
+	}
+
+	if desired.StoreType != 0 {
+		d.compare("StoreType", desired.StoreType, actual.StoreType)
+	}
+	d.compare("Name", desired.Name, actual.Name)
+	d.compare("ShortName", desired.ShortName, actual.ShortName)
+	d.compare("Capability", desired.Capability, actual.Capability)
+	d.compare("ImportType", desired.ImportType, actual.ImportType)
+	d.compare("LocalStore", desired.LocalStore, actual.LocalStore)
+	d.compare("StorePathType", desired.StorePathType, actual.StorePathType)
+	d.compare("StorePathValue", desired.StorePathValue, actual.StorePathValue)
+	d.compare("PrivateKeyAllowed", desired.PrivateKeyAllowed, actual.PrivateKeyAllowed)
+	d.compare("ServerRequired", desired.ServerRequired, actual.ServerRequired)
+	d.compare("PowerShell", desired.PowerShell, actual.PowerShell)
+	d.compare("BlueprintAllowed", desired.BlueprintAllowed, actual.BlueprintAllowed)
+	d.compare("CustomAliasAllowed", desired.CustomAliasAllowed, actual.CustomAliasAllowed)
+	d.compare("ServerRegistration", desired.ServerRegistration, actual.ServerRegistration)
+	d.compare("InventoryEndpoint", desired.InventoryEndpoint, actual.InventoryEndpoint)
+	d.compare("InventoryJobType", desired.InventoryJobType, actual.InventoryJobType)
+	d.compare("ManagementJobType", desired.ManagementJobType, actual.ManagementJobType)
+	d.compare("DiscoveryJobType", desired.DiscoveryJobType, actual.DiscoveryJobType)
+	d.compare("EnrollmentJobType", desired.EnrollmentJobType, actual.EnrollmentJobType)
+
+	if want := desired.SupportedOperations; want != nil {
+		got := actual.SupportedOperations
+		if got == nil {
+			got = &api.StoreTypeSupportedOperations{}
+		}
+		ops := d.sub("SupportedOperations.")
+		ops.compare("Add", want.Add, got.Add)
+		ops.compare("Create", want.Create, got.Create)
+		ops.compare("Discovery", want.Discovery, got.Discovery)
+		ops.compare("Enrollment", want.Enrollment, got.Enrollment)
+		ops.compare("Remove", want.Remove, got.Remove)
+	}
+	if want := desired.PasswordOptions; want != nil {
+		got := actual.PasswordOptions
+		if got == nil {
+			got = &api.StoreTypePasswordOptions{}
+		}
+		opts := d.sub("PasswordOptions.")
+		opts.compare("EntrySupported", want.EntrySupported, got.EntrySupported)
+		opts.compare("StoreRequired", want.StoreRequired, got.StoreRequired)
+		opts.compare("Style", want.Style, got.Style)
+	}
+
+	storeTypeProperties(d, desired.Properties, actual.Properties)
+	entryParameters(d, desired.EntryParameters, actual.EntryParameters)
+	jobProperties(d, desired.JobProperties, actual.JobProperties)
+	return d.result()
+}
+
+func storeTypeProperties(d differ, desired, actual *[]api.StoreTypePropertyDefinition) {
+	want := map[string]api.StoreTypePropertyDefinition{}
+	got := map[string]api.StoreTypePropertyDefinition{}
+	var wantNames, gotNames []string
+	if desired != nil {
+		for _, p := range *desired {
+			want[p.Name] = p
+			wantNames = append(wantNames, p.Name)
+		}
+	}
+	if actual != nil {
+		for _, p := range *actual {
+			got[p.Name] = p
+			gotNames = append(gotNames, p.Name)
+		}
+	}
+
+	for _, name := range unionNames(wantNames, gotNames) {
+		w, inWant := want[name]
+		g, inGot := got[name]
+		field := fmt.Sprintf("Properties[%s]", name)
+		switch {
+		case !inGot:
+			d.add(field, w, nil)
+		case !inWant:
+			d.add(field, nil, g)
+		default:
+			p := d.sub(field + ".")
+			p.compare("DisplayName", w.DisplayName, g.DisplayName)
+			p.compare("Type", w.Type, g.Type)
+			p.compare("DependsOn", w.DependsOn, g.DependsOn)
+			p.compare("Required", w.Required, g.Required)
+			if !sameValue(w.DefaultValue, g.DefaultValue) && !(isBlank(w.DefaultValue) && isBlank(g.DefaultValue)) {
+				p.add("DefaultValue", w.DefaultValue, g.DefaultValue)
+			}
+		}
+	}
+}
+
+func entryParameters(d differ, desired, actual *[]api.EntryParameter) {
+	want := map[string]api.EntryParameter{}
+	got := map[string]api.EntryParameter{}
+	var wantNames, gotNames []string
+	if desired != nil {
+		for _, p := range *desired {
+			want[p.Name] = p
+			wantNames = append(wantNames, p.Name)
+		}
+	}
+	if actual != nil {
+		for _, p := range *actual {
+			got[p.Name] = p
+			gotNames = append(gotNames, p.Name)
+		}
+	}
+
+	for _, name := range unionNames(wantNames, gotNames) {
+		w, inWant := want[name]
+		g, inGot := got[name]
+		field := fmt.Sprintf("EntryParameters[%s]", name)
+		switch {
+		case !inGot:
+			d.add(field, w, nil)
+		case !inWant:
+			d.add(field, nil, g)
+		default:
+			p := d.sub(field + ".")
+			p.compare("DisplayName", w.DisplayName, g.DisplayName)
+			p.compare("Type", w.Type, g.Type)
+			p.compare("DependsOn", w.DependsOn, g.DependsOn)
+			p.compare("DefaultValue", w.DefaultValue, g.DefaultValue)
+			p.compare("Options", w.Options, g.Options)
+			p.compare("RequiredWhen", w.RequiredWhen, g.RequiredWhen)
+		}
+	}
+}
+
+func jobProperties(d differ, desired, actual *[]string) {
+	var want, got []string
+	if desired != nil {
+		want = append(want, *desired...)
+	}
+	if actual != nil {
+		got = append(got, *actual...)
+	}
+	sort.Strings(want)
+	sort.Strings(got)
+	if len(want) == 0 && len(got) == 0 {
+		return
+	}
+	d.compare("JobProperties", want, got)
+}
+
+// unionNames returns the distinct names in a and b, sorted.
+func unionNames(a, b []string) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, name := range append(append([]string{}, a...), b...) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isBlank(v interface{}) bool {
+	return v == nil || v == ""
+}
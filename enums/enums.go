@@ -0,0 +1,207 @@
+// Package enums holds typed constants for the numeric and string codes used by the Keyfactor Command API, so that
+// callers do not need to hardcode them:
+//
+//	q, err := query.Field("CertState").Eq(enums.CertificateStateActive).Build() // CertState -eq 1
+//	_, err = client.RevokeCertificatesByQuery(q, enums.RevocationReasonSuperseded, "rotated", false, nil)
+//
+// The api package uses these types for its own fields and arguments where they are typed, e.g. api.RevocationReason
+// and api.JobResult are aliases of RevocationReason and JobStatus. Raw integer fields of API responses, such as
+// GetCertificateResponse.CertState, can be converted, e.g. enums.CertificateState(cert.CertState).
+package enums
+
+import "fmt"
+
+// RevocationReason is a revocation reason code accepted by the Keyfactor revoke endpoint.
+type RevocationReason int
+
+// Revocation reason codes understood by Keyfactor. RevocationReasonCertificateHold and
+// RevocationReasonRemoveFromHold are only honoured by CAs that support certificate suspension.
+const (
+	RevocationReasonRemoveFromHold       RevocationReason = -1
+	RevocationReasonUnspecified          RevocationReason = 0
+	RevocationReasonKeyCompromise        RevocationReason = 1
+	RevocationReasonCACompromise         RevocationReason = 2
+	RevocationReasonAffiliationChanged   RevocationReason = 3
+	RevocationReasonSuperseded           RevocationReason = 4
+	RevocationReasonCessationOfOperation RevocationReason = 5
+	RevocationReasonCertificateHold      RevocationReason = 6
+	RevocationReasonRemoveFromCRL        RevocationReason = 7
+	RevocationReasonUnknown              RevocationReason = 999
+)
+
+var revocationReasonNames = map[RevocationReason]string{
+	RevocationReasonRemoveFromHold:       "RemoveFromHold",
+	RevocationReasonUnspecified:          "Unspecified",
+	RevocationReasonKeyCompromise:        "KeyCompromise",
+	RevocationReasonCACompromise:         "CACompromise",
+	RevocationReasonAffiliationChanged:   "AffiliationChanged",
+	RevocationReasonSuperseded:           "Superseded",
+	RevocationReasonCessationOfOperation: "CessationOfOperation",
+	RevocationReasonCertificateHold:      "CertificateHold",
+	RevocationReasonRemoveFromCRL:        "RemoveFromCRL",
+	RevocationReasonUnknown:              "Unknown",
+}
+
+// String returns the name of the revocation reason.
+func (r RevocationReason) String() string {
+	if n, ok := revocationReasonNames[r]; ok {
+		return n
+	}
+	return fmt.Sprintf("%d", int(r))
+}
+
+// CertificateState is the CertState of a certificate known to Keyfactor.
+type CertificateState int
+
+const (
+	CertificateStateUnknown CertificateState = iota
+	CertificateStateActive
+	CertificateStateRevoked
+	CertificateStateDenied
+	CertificateStateFailed
+	CertificateStatePending
+	CertificateStateCertificateAuthority
+	CertificateStateParentCertificateAuthority
+	CertificateStateExternalValidation
+)
+
+var certificateStateNames = map[CertificateState]string{
+	CertificateStateUnknown:                    "Unknown",
+	CertificateStateActive:                     "Active",
+	CertificateStateRevoked:                    "Revoked",
+	CertificateStateDenied:                     "Denied",
+	CertificateStateFailed:                     "Failed",
+	CertificateStatePending:                    "Pending",
+	CertificateStateCertificateAuthority:       "CertificateAuthority",
+	CertificateStateParentCertificateAuthority: "ParentCertificateAuthority",
+	CertificateStateExternalValidation:         "ExternalValidation",
+}
+
+// String returns the name of the certificate state.
+func (s CertificateState) String() string {
+	if n, ok := certificateStateNames[s]; ok {
+		return n
+	}
+	return fmt.Sprintf("%d", int(s))
+}
+
+// KeyType is the public key algorithm of a certificate, as reported in the KeyType of a certificate.
+type KeyType int
+
+const (
+	KeyTypeUnknown KeyType = iota
+	KeyTypeRSA
+	KeyTypeDSA
+	KeyTypeECC
+	KeyTypeDH
+	KeyTypeEd448
+	KeyTypeEd25519
+)
+
+var keyTypeNames = map[KeyType]string{
+	KeyTypeUnknown: "Unknown",
+	KeyTypeRSA:     "RSA",
+	KeyTypeDSA:     "DSA",
+	KeyTypeECC:     "ECC",
+	KeyTypeDH:      "DH",
+	KeyTypeEd448:   "Ed448",
+	KeyTypeEd25519: "Ed25519",
+}
+
+// String returns the name Keyfactor uses for the key type in templates and enrollment requests, e.g. "RSA".
+func (k KeyType) String() string {
+	if n, ok := keyTypeNames[k]; ok {
+		return n
+	}
+	return fmt.Sprintf("%d", int(k))
+}
+
+// JobStatus is the result an orchestrator reports once a job has run, as found in the Result of a job history entry.
+type JobStatus int
+
+const (
+	JobStatusUnknown JobStatus = iota
+	JobStatusSuccess
+	JobStatusWarning
+	JobStatusFailure
+)
+
+var jobStatusNames = map[JobStatus]string{
+	JobStatusUnknown: "Unknown",
+	JobStatusSuccess: "Success",
+	JobStatusWarning: "Warning",
+	JobStatusFailure: "Failure",
+}
+
+// String returns a human-readable name for the job status.
+func (s JobStatus) String() string {
+	if n, ok := jobStatusNames[s]; ok {
+		return n
+	}
+	return "Unknown"
+}
+
+// ScheduleType names the kind of a job schedule, which Keyfactor encodes as the single key set on the schedule
+// object, e.g. {"Interval": {"Minutes": 60}}.
+type ScheduleType string
+
+const (
+	ScheduleTypeOff         ScheduleType = "Off"
+	ScheduleTypeImmediate   ScheduleType = "Immediate"
+	ScheduleTypeInterval    ScheduleType = "Interval"
+	ScheduleTypeDaily       ScheduleType = "Daily"
+	ScheduleTypeWeekly      ScheduleType = "Weekly"
+	ScheduleTypeMonthly     ScheduleType = "Monthly"
+	ScheduleTypeExactlyOnce ScheduleType = "ExactlyOnce"
+)
+
+// StoreTypeOperation is a set of flags naming the job types a certificate store type supports.
+type StoreTypeOperation int
+
+const (
+	StoreTypeOperationAdd StoreTypeOperation = 1 << iota
+	StoreTypeOperationRemove
+	StoreTypeOperationCreate
+	StoreTypeOperationDiscovery
+	StoreTypeOperationEnrollment
+)
+
+var storeTypeOperationNames = []struct {
+	op   StoreTypeOperation
+	name string
+}{
+	{StoreTypeOperationAdd, "Add"},
+	{StoreTypeOperationRemove, "Remove"},
+	{StoreTypeOperationCreate, "Create"},
+	{StoreTypeOperationDiscovery, "Discovery"},
+	{StoreTypeOperationEnrollment, "Enrollment"},
+}
+
+// Has reports whether every operation in op is set.
+func (o StoreTypeOperation) Has(op StoreTypeOperation) bool {
+	return o&op == op
+}
+
+// String lists the set operations separated by "|", e.g. "Add|Remove", or "None".
+func (o StoreTypeOperation) String() string {
+	s := ""
+	for _, n := range storeTypeOperationNames {
+		if o.Has(n.op) {
+			if s != "" {
+				s += "|"
+			}
+			s += n.name
+			o &^= n.op
+		}
+	}
+	if o != 0 {
+		if s != "" {
+			s += "|"
+		}
+		s += fmt.Sprintf("%#x", int(o))
+	}
+	if s == "" {
+		return "None"
+	}
+	return s
+}
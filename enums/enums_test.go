@@ -0,0 +1,42 @@
+package enums
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		value fmt.Stringer
+		want  string
+	}{
+		{RevocationReasonKeyCompromise, "KeyCompromise"},
+		{RevocationReasonRemoveFromHold, "RemoveFromHold"},
+		{RevocationReason(42), "42"},
+		{CertificateStateRevoked, "Revoked"},
+		{CertificateStateExternalValidation, "ExternalValidation"},
+		{KeyTypeECC, "ECC"},
+		{KeyType(5), "Ed448"},
+		{KeyType(6), "Ed25519"},
+		{JobStatusFailure, "Failure"},
+		{JobStatus(9), "Unknown"},
+		{StoreTypeOperation(0), "None"},
+		{StoreTypeOperationAdd | StoreTypeOperationRemove, "Add|Remove"},
+		{StoreTypeOperationEnrollment | StoreTypeOperation(64), "Enrollment|0x40"},
+	}
+	for _, tt := range tests {
+		if got := tt.value.String(); got != tt.want {
+			t.Errorf("%T(%v).String() = %q, want %q", tt.value, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestStoreTypeOperation_Has(t *testing.T) {
+	ops := StoreTypeOperationAdd | StoreTypeOperationRemove | StoreTypeOperationCreate
+	if !ops.Has(StoreTypeOperationAdd | StoreTypeOperationRemove) {
+		t.Errorf("%s.Has(Add|Remove) = false, want true", ops)
+	}
+	if ops.Has(StoreTypeOperationAdd | StoreTypeOperationDiscovery) {
+		t.Errorf("%s.Has(Add|Discovery) = true, want false", ops)
+	}
+}
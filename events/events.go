@@ -0,0 +1,386 @@
+// Package events delivers Keyfactor Command events on a channel by polling the audit log, the orchestrator job
+// history, and the workflow instances, for environments where Keyfactor cannot call out to a webhook. Each source is
+// read from a cursor, so every event is delivered once, and the cursor can be saved to resume a subscription after a
+// restart.
+//
+//	sub := events.Subscribe(ctx, client, &events.Options{Interval: 30 * time.Second})
+//	for e := range sub.Events() {
+//		switch e.Type {
+//		case events.InventoryFailed:
+//			alert(e.Message)
+//		}
+//		saveCursor(sub.Cursor())
+//	}
+package events
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Keyfactor/keyfactor-go-client/api"
+	"github.com/Keyfactor/keyfactor-go-client/query"
+)
+
+// DefaultInterval is the time between polls when Options.Interval is not set.
+const DefaultInterval = time.Minute
+
+// pageSize is the number of audit log entries and workflow instances requested per page.
+const pageSize = 100
+
+// Type is the type of an Event.
+type Type string
+
+// Event types.
+const (
+	// CertificateIssued is a certificate being enrolled for or renewed, read from the audit log.
+	CertificateIssued Type = "certificate_issued"
+	// InventoryFailed is a failed run of a certificate store inventory job.
+	InventoryFailed Type = "inventory_failed"
+	// WorkflowPending is a workflow instance waiting for a signal, such as the approval of a certificate request.
+	WorkflowPending Type = "workflow_pending"
+)
+
+// Event is a Keyfactor event. Exactly one of Audit, Job, and Workflow is set, depending on Type.
+type Event struct {
+	Type    Type
+	Time    time.Time
+	Message string
+
+	Audit    *api.AuditLogEntry
+	Job      *api.JobHistory
+	Workflow *api.WorkflowInstance
+}
+
+// Cursor is the position of a subscription in each event source.
+type Cursor struct {
+	// AuditId is the ID of the last audit log entry read.
+	AuditId int `json:"auditId,omitempty"`
+	// JobHistoryId is the ID of the last failed inventory job run read.
+	JobHistoryId int64 `json:"jobHistoryId,omitempty"`
+	// PendingWorkflows are the IDs of the suspended workflow instances already delivered.
+	PendingWorkflows []string `json:"pendingWorkflows,omitempty"`
+}
+
+// Client is the subset of *api.Client used to poll for events.
+type Client interface {
+	SearchAuditLogsContext(ctx context.Context, q string, paging *api.Paging) ([]api.AuditLogEntry, error)
+	ListJobHistory(q string) ([]api.JobHistory, error)
+	ListWorkflowInstancesContext(ctx context.Context, q string, paging *api.Paging) ([]api.WorkflowInstance, error)
+}
+
+// Options configures a subscription.
+type Options struct {
+	// Interval is the time between polls. It defaults to DefaultInterval.
+	Interval time.Duration
+	// Types limits the subscription to the given event types. All types are delivered if it is empty.
+	Types []Type
+	// Cursor resumes a subscription from the position returned by Subscription.Cursor.
+	Cursor *Cursor
+	// Since is the time from which events are delivered for a source the Cursor has no position in. It defaults to the
+	// time of the call to Subscribe.
+	Since time.Time
+	// OnError is called when a poll fails. The subscription carries on and retries on the next poll. If it is nil,
+	// errors are logged to the logger of the client, if it has a Logger method as *api.Client does, and otherwise to
+	// the standard logger of the log package.
+	OnError func(error)
+}
+
+// Subscription is a stream of events. It is created by Subscribe.
+type Subscription struct {
+	client   Client
+	events   chan Event
+	interval time.Duration
+	since    time.Time
+	types    map[Type]bool
+	onError  func(error)
+
+	mu      sync.Mutex
+	cursor  Cursor
+	pending map[string]bool
+	// workflowsPolled is set once the suspended workflow instances are known, by a first poll or from a Cursor.
+	workflowsPolled bool
+}
+
+// clientLogger returns the logger of c, or the standard logger of the log package if c does not have one.
+func clientLogger(c Client) api.Logger {
+	if lc, ok := c.(interface{ Logger() api.Logger }); ok {
+		if logger := lc.Logger(); logger != nil {
+			return logger
+		}
+	}
+	return api.NewStdLogger(nil, api.LogLevelDebug)
+}
+
+// Subscribe starts polling Keyfactor for events, the first time right away. The subscription stops, and its Events
+// channel is closed, when ctx is done.
+func Subscribe(ctx context.Context, c Client, opts *Options) *Subscription {
+	if opts == nil {
+		opts = &Options{}
+	}
+	s := &Subscription{
+		client:   c,
+		events:   make(chan Event),
+		interval: opts.Interval,
+		since:    opts.Since,
+		types:    map[Type]bool{},
+		onError:  opts.OnError,
+		pending:  map[string]bool{},
+	}
+	if s.interval <= 0 {
+		s.interval = DefaultInterval
+	}
+	if s.since.IsZero() {
+		s.since = time.Now()
+	}
+	for _, t := range opts.Types {
+		s.types[t] = true
+	}
+	if len(s.types) == 0 {
+		s.types = map[Type]bool{CertificateIssued: true, InventoryFailed: true, WorkflowPending: true}
+	}
+	if s.onError == nil {
+		logger := clientLogger(c)
+		s.onError = func(err error) {
+			logger.Log(api.LogLevelError, fmt.Sprintf("Polling for Keyfactor events: %s", err), nil)
+		}
+	}
+	if opts.Cursor != nil {
+		s.workflowsPolled = true
+		s.cursor = *opts.Cursor
+		s.cursor.PendingWorkflows = nil
+		for _, id := range opts.Cursor.PendingWorkflows {
+			s.pending[id] = true
+		}
+	}
+
+	go s.run(ctx)
+	return s
+}
+
+// Events returns the channel events are delivered on, oldest first within each source.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Cursor returns the position of the subscription after the last event received from Events.
+func (s *Subscription) Cursor() Cursor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cursor := s.cursor
+	cursor.PendingWorkflows = make([]string, 0, len(s.pending))
+	for id := range s.pending {
+		cursor.PendingWorkflows = append(cursor.PendingWorkflows, id)
+	}
+	sort.Strings(cursor.PendingWorkflows)
+	return cursor
+}
+
+func (s *Subscription) run(ctx context.Context) {
+	defer close(s.events)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		for _, poll := range []struct {
+			t    Type
+			poll func(context.Context) error
+		}{
+			{CertificateIssued, s.pollAudit},
+			{InventoryFailed, s.pollJobs},
+			{WorkflowPending, s.pollWorkflows},
+		} {
+			if !s.types[poll.t] {
+				continue
+			}
+			if err := poll.poll(ctx); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				s.onError(err)
+			}
+		}
+		timer.Reset(s.interval)
+	}
+}
+
+// send delivers e, then moves the cursor with advance. It returns ctx's error if ctx is done first.
+func (s *Subscription) send(ctx context.Context, e Event, advance func(*Subscription)) error {
+	select {
+	case s.events <- e:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	s.mu.Lock()
+	advance(s)
+	s.mu.Unlock()
+	return nil
+}
+
+// pollAudit delivers the certificate issuances recorded in the audit log since the last entry read.
+func (s *Subscription) pollAudit(ctx context.Context) error {
+	s.mu.Lock()
+	after := s.cursor.AuditId
+	s.mu.Unlock()
+
+	cond := query.Field("Id").Gt(after)
+	if after == 0 {
+		cond = query.Field("Timestamp").Ge(s.since)
+	}
+	q, err := cond.Build()
+	if err != nil {
+		return err
+	}
+	for page := 1; ; page++ {
+		entries, err := s.client.SearchAuditLogsContext(ctx, q, &api.Paging{
+			PageReturned: page, ReturnLimit: pageSize, SortField: "Id", SortAscending: true,
+		})
+		if err != nil {
+			return err
+		}
+		for i := range entries {
+			entry := entries[i]
+			advance := func(s *Subscription) {
+				if entry.Id > s.cursor.AuditId {
+					s.cursor.AuditId = entry.Id
+				}
+			}
+			if !isIssuance(&entry) {
+				s.mu.Lock()
+				advance(s)
+				s.mu.Unlock()
+				continue
+			}
+			e := Event{Type: CertificateIssued, Time: entry.Timestamp.Time, Message: entry.Message, Audit: &entry}
+			if err := s.send(ctx, e, advance); err != nil {
+				return err
+			}
+		}
+		if len(entries) < pageSize {
+			return nil
+		}
+	}
+}
+
+func isIssuance(entry *api.AuditLogEntry) bool {
+	if !strings.EqualFold(entry.EntityType, "Certificate") {
+		return false
+	}
+	t := entry.CertificateEventType()
+	return t == api.CertificateEventIssued || t == api.CertificateEventRenewed
+}
+
+// pollJobs delivers the failed inventory job runs since the last run read.
+func (s *Subscription) pollJobs(ctx context.Context) error {
+	s.mu.Lock()
+	after := s.cursor.JobHistoryId
+	s.mu.Unlock()
+
+	cond := query.Field("JobHistoryId").Gt(after)
+	if after == 0 {
+		cond = query.Field("OperationStart").Ge(s.since)
+	}
+	q, err := query.And(
+		query.Field("JobType").Eq("Inventory"),
+		query.Field("Result").Eq(int(api.JobResultFailure)),
+		cond,
+	).Build()
+	if err != nil {
+		return err
+	}
+	runs, err := s.client.ListJobHistory(q)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].JobHistoryId < runs[j].JobHistoryId })
+	for i := range runs {
+		run := runs[i]
+		if run.JobHistoryId <= after {
+			continue
+		}
+		e := Event{Type: InventoryFailed, Time: firstTime(run.OperationEnd, run.OperationStart), Message: run.Message, Job: &run}
+		err := s.send(ctx, e, func(s *Subscription) {
+			if run.JobHistoryId > s.cursor.JobHistoryId {
+				s.cursor.JobHistoryId = run.JobHistoryId
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pollWorkflows delivers the suspended workflow instances not delivered yet. Unless the subscription was resumed from
+// a Cursor, instances last modified before Options.Since are taken as already delivered on the first poll.
+func (s *Subscription) pollWorkflows(ctx context.Context) error {
+	q, err := query.Field("Status").Eq(api.WorkflowStatusSuspended).Build()
+	if err != nil {
+		return err
+	}
+	var suspended []api.WorkflowInstance
+	for page := 1; ; page++ {
+		instances, err := s.client.ListWorkflowInstancesContext(ctx, q, &api.Paging{
+			PageReturned: page, ReturnLimit: pageSize, SortField: "LastModified", SortAscending: true,
+		})
+		if err != nil {
+			return err
+		}
+		suspended = append(suspended, instances...)
+		if len(instances) < pageSize {
+			break
+		}
+	}
+
+	current := make(map[string]bool, len(suspended))
+	for _, instance := range suspended {
+		current[instance.Id] = true
+	}
+	s.mu.Lock()
+	for id := range s.pending {
+		if !current[id] {
+			delete(s.pending, id)
+		}
+	}
+	if !s.workflowsPolled {
+		for _, instance := range suspended {
+			if instance.LastModified.Before(s.since) {
+				s.pending[instance.Id] = true
+			}
+		}
+		s.workflowsPolled = true
+	}
+	s.mu.Unlock()
+
+	for i := range suspended {
+		instance := suspended[i]
+		s.mu.Lock()
+		delivered := s.pending[instance.Id]
+		s.mu.Unlock()
+		if delivered {
+			continue
+		}
+		e := Event{Type: WorkflowPending, Time: instance.LastModified.Time, Message: instance.Title, Workflow: &instance}
+		if err := s.send(ctx, e, func(s *Subscription) { s.pending[instance.Id] = true }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// firstTime returns the first of the given job history times that is set, or the zero time.
+func firstTime(values ...api.Timestamp) time.Time {
+	for _, v := range values {
+		if v.Valid() {
+			return v.Time
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,224 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Keyfactor/keyfactor-go-client/api"
+)
+
+var _ Client = (*api.Client)(nil)
+
+type fakeClient struct {
+	mu        sync.Mutex
+	audit     []api.AuditLogEntry
+	jobs      []api.JobHistory
+	workflows []api.WorkflowInstance
+	queries   []string
+	jobsErr   error
+}
+
+func (f *fakeClient) SearchAuditLogsContext(_ context.Context, q string, paging *api.Paging) ([]api.AuditLogEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queries = append(f.queries, q)
+	var out []api.AuditLogEntry
+	for _, e := range f.audit {
+		if !strings.HasPrefix(q, "Id -gt ") || e.Id > atoi(strings.TrimPrefix(q, "Id -gt ")) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeClient) ListJobHistory(q string) ([]api.JobHistory, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queries = append(f.queries, q)
+	return f.jobs, f.jobsErr
+}
+
+func (f *fakeClient) ListWorkflowInstancesContext(context.Context, string, *api.Paging) ([]api.WorkflowInstance, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]api.WorkflowInstance(nil), f.workflows...), nil
+}
+
+func atoi(s string) int {
+	n := 0
+	for _, r := range s {
+		n = n*10 + int(r-'0')
+	}
+	return n
+}
+
+func receive(t *testing.T, sub *Subscription, n int) []Event {
+	t.Helper()
+	var got []Event
+	for len(got) < n {
+		select {
+		case e := <-sub.Events():
+			got = append(got, e)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("received %d events, want %d", len(got), n)
+		}
+	}
+	return got
+}
+
+func TestSubscribe(t *testing.T) {
+	since := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	c := &fakeClient{
+		audit: []api.AuditLogEntry{
+			{Id: 7, EntityType: "Certificate", Message: "Certificate AB12 enrolled via CSR"},
+			{Id: 8, EntityType: "Certificate", Message: "Metadata of certificate AB12 updated"},
+			{Id: 9, EntityType: "Certificate", Message: "Certificate CD34 renewed"},
+		},
+		jobs: []api.JobHistory{
+			{JobHistoryId: 31, JobType: "Inventory", Result: api.JobResultFailure, Message: "access denied", OperationStart: api.NewTimestamp(since.Add(5 * time.Minute))},
+		},
+		workflows: []api.WorkflowInstance{
+			{Id: "old", Status: api.WorkflowStatusSuspended, LastModified: api.NewTimestamp(since.Add(-time.Hour))},
+			{Id: "new", Status: api.WorkflowStatusSuspended, Title: "Enrollment for app.example.com", LastModified: api.NewTimestamp(since.Add(time.Minute))},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub := Subscribe(ctx, c, &Options{Interval: 10 * time.Millisecond, Since: since})
+
+	got := receive(t, sub, 4)
+	var types []Type
+	for _, e := range got {
+		types = append(types, e.Type)
+	}
+	want := []Type{CertificateIssued, CertificateIssued, InventoryFailed, WorkflowPending}
+	if !reflect.DeepEqual(types, want) {
+		t.Fatalf("event types = %v, want %v", types, want)
+	}
+	if got[2].Job.JobHistoryId != 31 || got[2].Time.IsZero() || got[3].Workflow.Id != "new" {
+		t.Errorf("events = %+v", got)
+	}
+
+	// Later polls deliver nothing new until Keyfactor records more events.
+	time.Sleep(50 * time.Millisecond)
+	c.mu.Lock()
+	c.audit = append(c.audit, api.AuditLogEntry{Id: 12, EntityType: "Certificate", Message: "Certificate EF56 issued"})
+	c.workflows = c.workflows[1:]
+	c.mu.Unlock()
+	if got := receive(t, sub, 1); got[0].Audit.Id != 12 {
+		t.Errorf("next event = %+v, want audit entry 12", got[0])
+	}
+	select {
+	case e := <-sub.Events():
+		t.Fatalf("unexpected event %+v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cursor := sub.Cursor()
+	if want := (Cursor{AuditId: 12, JobHistoryId: 31, PendingWorkflows: []string{"new"}}); !reflect.DeepEqual(cursor, want) {
+		t.Errorf("Cursor() = %+v, want %+v", cursor, want)
+	}
+	cancel()
+	for range sub.Events() {
+	}
+
+	// A subscription resumed from the cursor starts where the first left off.
+	c.queries = nil
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	resumed := Subscribe(ctx, c, &Options{Types: []Type{CertificateIssued}, Cursor: &cursor})
+	select {
+	case e := <-resumed.Events():
+		t.Fatalf("resumed subscription delivered %+v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.queries) == 0 || c.queries[0] != "Id -gt 12" {
+		t.Errorf("resumed subscription queried %q, want Id -gt 12", c.queries)
+	}
+}
+
+func TestSubscribe_Errors(t *testing.T) {
+	c := &fakeClient{jobsErr: errors.New("keyfactor unavailable")}
+	errs := make(chan error, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := Subscribe(ctx, c, &Options{
+		Interval: 10 * time.Millisecond,
+		Types:    []Type{InventoryFailed},
+		OnError:  func(err error) { errs <- err },
+	})
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errs:
+			if err != c.jobsErr {
+				t.Errorf("OnError() got %v, want %v", err, c.jobsErr)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("OnError() called %d times, want polling to retry", i)
+		}
+	}
+	cancel()
+	select {
+	case _, ok := <-sub.Events():
+		if ok {
+			t.Errorf("received an event, want closed channel")
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("Events() not closed after cancel")
+	}
+}
+
+func TestFirstTime(t *testing.T) {
+	// Keyfactor returns job history times without a zone, in UTC.
+	var run api.JobHistory
+	if err := json.Unmarshal([]byte(`{"OperationStart": "2024-05-01T12:05:00", "OperationEnd": "2024-05-01T12:06:30.5"}`), &run); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := firstTime(run.OperationEnd, run.OperationStart), time.Date(2024, 5, 1, 12, 6, 30, 500000000, time.UTC); !got.Equal(want) {
+		t.Errorf("firstTime() = %s, want the end %s", got, want)
+	}
+	run.OperationEnd = api.Timestamp{}
+	if got, want := firstTime(run.OperationEnd, run.OperationStart), time.Date(2024, 5, 1, 12, 5, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("firstTime() of a running job = %s, want the start %s", got, want)
+	}
+}
+
+// loggingClient is a fakeClient with a logger, like *api.Client.
+type loggingClient struct {
+	*fakeClient
+	logger api.Logger
+}
+
+func (c loggingClient) Logger() api.Logger {
+	return c.logger
+}
+
+func TestSubscribe_ErrorsLoggedToClient(t *testing.T) {
+	logged := make(chan string, 10)
+	c := loggingClient{
+		fakeClient: &fakeClient{jobsErr: errors.New("keyfactor unavailable")},
+		logger: api.LoggerFunc(func(level api.LogLevel, msg string, fields api.LogFields) {
+			if level == api.LogLevelError {
+				logged <- msg
+			}
+		}),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	Subscribe(ctx, c, &Options{Interval: 10 * time.Millisecond, Types: []Type{InventoryFailed}})
+	select {
+	case msg := <-logged:
+		if !strings.Contains(msg, "keyfactor unavailable") {
+			t.Errorf("logged %q, want the poll error", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("poll error was not logged to the client's logger")
+	}
+}
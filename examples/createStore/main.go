@@ -37,7 +37,7 @@ func main() {
 	// first create a pointer to an CreateStoreFctArgs struct,
 	// and populate the required fields. The below fields are the bare minimum. Note that the properties required vary
 	// between different store types. Use the GetCertificateStoreType method to determine the required fields.
-	properties := make(map[string]string)
+	properties := make(map[string]interface{})
 	properties["TenantID"] = "tenant"
 	properties["ResourceGroupName"] = "resource group name"
 	properties["ApplicationId"] = "app ID"
@@ -85,8 +85,8 @@ func main() {
 	metadata := &api.UpdateMetadataArgs{
 		CertID: 1860,
 		CertificateMetadata: []api.StringTuple{
-			{"Department", "IT"},
-			{"Email-Contact", "email@example.com"},
+			{Elem1: "Department", Elem2: "IT"},
+			{Elem1: "Email-Contact", Elem2: "email@example.com"},
 		},
 	}
 	// Then, call the update certificate metadata method with the request arguments.
@@ -0,0 +1,71 @@
+package export
+
+import "github.com/Keyfactor/keyfactor-go-client/api"
+
+// Column is a single exported field of a certificate.
+type Column struct {
+	// Header is the CSV header and NDJSON key of the column.
+	Header string
+	// Value extracts the column from a certificate. Strings and []string are rendered natively; other values are
+	// formatted with fmt for CSV and encoded as JSON for NDJSON.
+	Value func(cert *api.GetCertificateResponse) interface{}
+	// NeedsMetadata and NeedsSANs request certificate metadata or subject alternative names from Keyfactor, which
+	// are omitted from search results by default.
+	NeedsMetadata bool
+	NeedsSANs     bool
+}
+
+// Predefined certificate columns.
+var (
+	Id = Column{Header: "Id", Value: func(c *api.GetCertificateResponse) interface{} { return c.Id }}
+
+	Thumbprint = Column{Header: "Thumbprint", Value: func(c *api.GetCertificateResponse) interface{} { return c.Thumbprint }}
+
+	SerialNumber = Column{Header: "SerialNumber", Value: func(c *api.GetCertificateResponse) interface{} { return c.SerialNumber }}
+
+	CommonName = Column{Header: "CN", Value: func(c *api.GetCertificateResponse) interface{} { return c.IssuedCN }}
+
+	SubjectDN = Column{Header: "SubjectDN", Value: func(c *api.GetCertificateResponse) interface{} { return c.IssuedDN }}
+
+	IssuerDN = Column{Header: "IssuerDN", Value: func(c *api.GetCertificateResponse) interface{} { return c.IssuerDN }}
+
+	NotBefore = Column{Header: "NotBefore", Value: func(c *api.GetCertificateResponse) interface{} { return c.NotBefore }}
+
+	NotAfter = Column{Header: "NotAfter", Value: func(c *api.GetCertificateResponse) interface{} { return c.NotAfter }}
+
+	Template = Column{Header: "Template", Value: func(c *api.GetCertificateResponse) interface{} { return c.TemplateName }}
+
+	State = Column{Header: "State", Value: func(c *api.GetCertificateResponse) interface{} { return c.CertStateString }}
+
+	// SANs lists the values of every subject alternative name on the certificate.
+	SANs = Column{
+		Header: "SANs",
+		Value: func(c *api.GetCertificateResponse) interface{} {
+			sans := make([]string, 0, len(c.SubjectAltNameElements))
+			for _, san := range c.SubjectAltNameElements {
+				sans = append(sans, san.Value)
+			}
+			return sans
+		},
+		NeedsSANs: true,
+	}
+)
+
+// DefaultColumns is used when Options.Columns is empty.
+var DefaultColumns = []Column{Id, Thumbprint, CommonName, SANs, NotAfter}
+
+// Metadata returns a column holding the value of the named certificate metadata field. Certificates without the
+// field export an empty value.
+func Metadata(field string) Column {
+	return Column{
+		Header: field,
+		Value: func(c *api.GetCertificateResponse) interface{} {
+			fields, ok := c.Metadata.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			return fields[field]
+		},
+		NeedsMetadata: true,
+	}
+}
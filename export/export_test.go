@@ -0,0 +1,117 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Keyfactor/keyfactor-go-client/api"
+)
+
+// fakeSearcher serves fixed pages and records the search options it was called with.
+type fakeSearcher struct {
+	pages [][]api.GetCertificateResponse
+	opts  *api.SearchCertificatesOptions
+	err   error
+}
+
+func (f *fakeSearcher) SearchCertificatePages(q string, opts *api.SearchCertificatesOptions, fn func(page []api.GetCertificateResponse) error) error {
+	f.opts = opts
+	for _, page := range f.pages {
+		if err := fn(page); err != nil {
+			return err
+		}
+	}
+	return f.err
+}
+
+func testPages() [][]api.GetCertificateResponse {
+	return [][]api.GetCertificateResponse{
+		{{
+			Id:         1,
+			Thumbprint: "A1B2",
+			IssuedCN:   "www.example.com",
+			NotAfter:   api.NewTimestamp(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+			SubjectAltNameElements: []api.SubjectAltNameElements{
+				{Value: "www.example.com", Type: 2},
+				{Value: "example.com", Type: 2},
+			},
+			Metadata: map[string]interface{}{"Owner": "web-team"},
+		}},
+		{{Id: 2, Thumbprint: "C3D4", IssuedCN: "api, internal", NotAfter: api.NewTimestamp(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))}},
+	}
+}
+
+func TestCertificates(t *testing.T) {
+	columns := []Column{Thumbprint, CommonName, SANs, NotAfter, Metadata("Owner")}
+	tests := []struct {
+		name         string
+		format       Format
+		columns      []Column
+		searchErr    error
+		want         string
+		wantN        int
+		wantMetadata bool
+		wantVerbose  int
+		wantErr      bool
+	}{
+		{
+			name:    "CSV",
+			format:  CSV,
+			columns: columns,
+			want: "Thumbprint,CN,SANs,NotAfter,Owner\n" +
+				"A1B2,www.example.com,www.example.com;example.com,2025-01-01T00:00:00Z,web-team\n" +
+				"C3D4,\"api, internal\",,2025-02-01T00:00:00Z,\n",
+			wantN:        2,
+			wantMetadata: true,
+			wantVerbose:  1,
+		},
+		{
+			name:    "NDJSON",
+			format:  NDJSON,
+			columns: columns,
+			want: `{"Thumbprint":"A1B2","CN":"www.example.com","SANs":["www.example.com","example.com"],"NotAfter":"2025-01-01T00:00:00Z","Owner":"web-team"}` + "\n" +
+				`{"Thumbprint":"C3D4","CN":"api, internal","SANs":[],"NotAfter":"2025-02-01T00:00:00Z","Owner":null}` + "\n",
+			wantN:        2,
+			wantMetadata: true,
+			wantVerbose:  1,
+		},
+		{
+			name:    "OnlyRequestsWhatColumnsNeed",
+			format:  CSV,
+			columns: []Column{Id, Thumbprint},
+			want:    "Id,Thumbprint\n1,A1B2\n2,C3D4\n",
+			wantN:   2,
+		},
+		{
+			name:      "SearchError",
+			format:    NDJSON,
+			columns:   []Column{Id},
+			searchErr: errors.New("page 3 failed"),
+			want:      "{\"Id\":1}\n{\"Id\":2}\n",
+			wantN:     2,
+			wantErr:   true,
+		},
+		{name: "InvalidColumn", columns: []Column{{Header: "Broken"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &fakeSearcher{pages: testPages(), err: tt.searchErr}
+			var buf bytes.Buffer
+			n, err := Certificates(&buf, s, &Options{Format: tt.format, Columns: tt.columns})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Certificates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if n != tt.wantN {
+				t.Errorf("Certificates() wrote %d certificates, want %d", n, tt.wantN)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Certificates() output =\n%s\nwant\n%s", got, tt.want)
+			}
+			if s.opts != nil && (s.opts.IncludeMetadata != tt.wantMetadata || s.opts.Verbose != tt.wantVerbose) {
+				t.Errorf("Certificates() search options = %+v", s.opts)
+			}
+		})
+	}
+}
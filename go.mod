@@ -6,6 +6,5 @@ require (
 	github.com/Keyfactor/keyfactor-go-client-sdk v1.0.1
 	github.com/spbsoluble/go-pkcs12 v0.3.1
 	go.mozilla.org/pkcs7 v0.0.0-20210826202110-33d05740a352
+	golang.org/x/crypto v0.0.0-20220331220935-ae2d96664a29
 )
-
-require golang.org/x/crypto v0.0.0-20220331220935-ae2d96664a29 // indirect
@@ -0,0 +1,278 @@
+// Package importer bulk-imports existing certificates from a local directory into Keyfactor Command, as needed when
+// migrating from another certificate management platform. PEM, DER, and PKCS#12 files are supported; PEM files that
+// include a private key are imported together with the key.
+//
+//	report, err := importer.ImportDirectory(client, "/var/migration/certs", &importer.Options{
+//		PFXPassword: func(path string) string { return "changeit" },
+//		Metadata: func(path string, cert *x509.Certificate) (map[string]string, error) {
+//			return map[string]string{"Owner": filepath.Base(filepath.Dir(path))}, nil
+//		},
+//		SkipExisting: true,
+//	})
+package importer
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Keyfactor/keyfactor-go-client/api"
+	"github.com/Keyfactor/keyfactor-go-client/query"
+)
+
+// Client is the subset of *api.Client used by the importer.
+type Client interface {
+	ImportCertificate(args *api.ImportCertificateArgs) (*api.ImportCertificateResponse, error)
+	SearchCertificatePages(q string, opts *api.SearchCertificatesOptions, fn func(page []api.GetCertificateResponse) error) error
+}
+
+// Status is the outcome of importing a single file.
+type Status int
+
+const (
+	// StatusImported means the certificate was imported into Keyfactor.
+	StatusImported Status = iota
+	// StatusDuplicate means an earlier file in the same run had the same thumbprint.
+	StatusDuplicate
+	// StatusExisting means Keyfactor already held the certificate and SkipExisting was set.
+	StatusExisting
+	// StatusFailed means the file could not be read, parsed, or imported; Result.Err holds the reason.
+	StatusFailed
+)
+
+// String returns a human-readable name for the status.
+func (s Status) String() string {
+	switch s {
+	case StatusImported:
+		return "Imported"
+	case StatusDuplicate:
+		return "Duplicate"
+	case StatusExisting:
+		return "Existing"
+	case StatusFailed:
+		return "Failed"
+	}
+	return "Unknown"
+}
+
+// Options configures ImportDirectory.
+type Options struct {
+	// Extensions lists the file extensions considered, compared case-insensitively. Defaults to DefaultExtensions.
+	Extensions []string
+	// PFXPassword returns the password of a .pfx or .p12 file. Defaults to an empty password.
+	PFXPassword func(path string) string
+	// Metadata returns the Keyfactor metadata to set on the certificate imported from path. Returning an error
+	// fails that file only.
+	Metadata func(path string, cert *x509.Certificate) (map[string]string, error)
+	// StoreIds associates every imported certificate with these certificate stores.
+	StoreIds []string
+	// SkipExisting looks up each thumbprint in Keyfactor before importing and skips certificates already present.
+	SkipExisting bool
+	// Logger receives the progress of the import. Defaults to the logger of the client, if it has a Logger method as
+	// *api.Client does, and otherwise to the standard logger of the log package.
+	Logger api.Logger
+}
+
+// DefaultExtensions are the file extensions imported when Options.Extensions is empty.
+var DefaultExtensions = []string{".pem", ".crt", ".cer", ".der", ".pfx", ".p12"}
+
+// Result is the outcome of importing a single file.
+type Result struct {
+	Path       string
+	Thumbprint string
+	Subject    string
+	Status     Status
+	Err        error
+}
+
+// Report lists the outcome of every file considered by ImportDirectory, in walk order.
+type Report struct {
+	Results []Result
+}
+
+// Count returns the number of results with the given status.
+func (r *Report) Count(status Status) int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed returns the results of files that could not be imported.
+func (r *Report) Failed() []Result {
+	var failed []Result
+	for _, res := range r.Results {
+		if res.Status == StatusFailed {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// ImportDirectory walks dir recursively and imports every certificate file into Keyfactor through c. Certificates
+// are deduplicated by thumbprint, so the same certificate stored in several files is imported once; after a file fails
+// to import, the next file with its certificate is tried. A file that
+// fails does not stop the walk; it is recorded in the report, which is always returned. The error is only non-nil
+// if the directory itself could not be walked.
+func ImportDirectory(c Client, dir string, opts *Options) (*Report, error) {
+	if c == nil {
+		return nil, errors.New("a Keyfactor client is required to import certificates")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	extensions := opts.Extensions
+	if len(extensions) == 0 {
+		extensions = DefaultExtensions
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = clientLogger(c)
+	}
+
+	report := &Report{}
+	seen := make(map[string]bool)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !hasExtension(path, extensions) {
+			return nil
+		}
+
+		res := importFile(c, path, opts, seen)
+		if res.Err != nil {
+			logger.Log(api.LogLevelWarn, fmt.Sprintf("Unable to import %s: %s", path, res.Err), api.LogFields{"path": path})
+		} else {
+			logger.Log(api.LogLevelDebug, fmt.Sprintf("%s: %s (%s)", path, res.Status, res.Thumbprint), api.LogFields{"path": path, "thumbprint": res.Thumbprint})
+		}
+		report.Results = append(report.Results, res)
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	logger.Log(api.LogLevelInfo, fmt.Sprintf("Imported %d certificates from %s (%d duplicates, %d already in Keyfactor, %d failed)",
+		report.Count(StatusImported), dir, report.Count(StatusDuplicate), report.Count(StatusExisting), report.Count(StatusFailed)), nil)
+	return report, nil
+}
+
+// clientLogger returns the logger of c, or the standard logger of the log package if c does not have one.
+func clientLogger(c Client) api.Logger {
+	if lc, ok := c.(interface{ Logger() api.Logger }); ok {
+		if logger := lc.Logger(); logger != nil {
+			return logger
+		}
+	}
+	return api.NewStdLogger(nil, api.LogLevelDebug)
+}
+
+// importFile parses and imports a single file. Its thumbprint is marked as seen once the certificate is imported or
+// found in Keyfactor, so a later file with the same certificate is tried if this one fails.
+func importFile(c Client, path string, opts *Options, seen map[string]bool) Result {
+	res := Result{Path: path, Status: StatusFailed}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+
+	var parsed *parsedFile
+	switch ext := strings.ToLower(filepath.Ext(path)); {
+	case ext == ".pfx" || ext == ".p12":
+		password := ""
+		if opts.PFXPassword != nil {
+			password = opts.PFXPassword(path)
+		}
+		parsed, err = parsePFX(data, password)
+	case strings.Contains(string(data), "-----BEGIN"):
+		parsed, err = parsePEM(data)
+	default:
+		parsed, err = parseDER(data)
+	}
+	if err != nil {
+		res.Err = fmt.Errorf("parsing certificate: %w", err)
+		return res
+	}
+	res.Thumbprint = Thumbprint(parsed.leaf)
+	res.Subject = parsed.leaf.Subject.String()
+
+	if seen[res.Thumbprint] {
+		res.Status = StatusDuplicate
+		return res
+	}
+
+	if opts.SkipExisting {
+		exists, err := certificateExists(c, res.Thumbprint)
+		if err != nil {
+			res.Err = fmt.Errorf("checking for existing certificate: %w", err)
+			return res
+		}
+		if exists {
+			seen[res.Thumbprint] = true
+			res.Status = StatusExisting
+			return res
+		}
+	}
+
+	args := &api.ImportCertificateArgs{
+		Certificate: parsed.contents,
+		Password:    parsed.password,
+		StoreIds:    opts.StoreIds,
+	}
+	if opts.Metadata != nil {
+		metadata, err := opts.Metadata(path, parsed.leaf)
+		if err != nil {
+			res.Err = fmt.Errorf("mapping metadata: %w", err)
+			return res
+		}
+		args.Metadata = metadata
+		args.ImportMetadata = len(metadata) > 0
+	}
+
+	if _, err := c.ImportCertificate(args); err != nil {
+		res.Err = err
+		return res
+	}
+	seen[res.Thumbprint] = true
+	res.Status = StatusImported
+	return res
+}
+
+// errFound stops a certificate search as soon as a match is seen.
+var errFound = errors.New("found")
+
+// certificateExists reports whether Keyfactor holds a certificate with the given thumbprint.
+func certificateExists(c Client, thumbprint string) (bool, error) {
+	q, err := query.Field("Thumbprint").Eq(thumbprint).Build()
+	if err != nil {
+		return false, err
+	}
+	err = c.SearchCertificatePages(q, &api.SearchCertificatesOptions{PageSize: 1, IncludeRevoked: true, IncludeExpired: true},
+		func(page []api.GetCertificateResponse) error {
+			return errFound
+		})
+	if errors.Is(err, errFound) {
+		return true, nil
+	}
+	return false, err
+}
+
+func hasExtension(path string, extensions []string) bool {
+	ext := filepath.Ext(path)
+	for _, e := range extensions {
+		if strings.EqualFold(ext, e) {
+			return true
+		}
+	}
+	return false
+}
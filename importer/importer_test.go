@@ -0,0 +1,214 @@
+package importer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Keyfactor/keyfactor-go-client/api"
+	"github.com/Keyfactor/keyfactor-go-client/keystore"
+	"github.com/spbsoluble/go-pkcs12"
+)
+
+// fakeClient records imports and reports the thumbprints in existing as already present in Keyfactor. The first
+// failures imports fail.
+type fakeClient struct {
+	imports  []*api.ImportCertificateArgs
+	existing map[string]bool
+	failures int
+}
+
+func (f *fakeClient) ImportCertificate(args *api.ImportCertificateArgs) (*api.ImportCertificateResponse, error) {
+	if f.failures > 0 {
+		f.failures--
+		return nil, errors.New("import rejected")
+	}
+	f.imports = append(f.imports, args)
+	return &api.ImportCertificateResponse{ImportStatus: 1}, nil
+}
+
+func (f *fakeClient) SearchCertificatePages(q string, opts *api.SearchCertificatesOptions, fn func(page []api.GetCertificateResponse) error) error {
+	for thumbprint := range f.existing {
+		if q == `Thumbprint -eq "`+thumbprint+`"` {
+			return fn([]api.GetCertificateResponse{{Thumbprint: thumbprint}})
+		}
+	}
+	return nil
+}
+
+// newTestCert returns a self-signed certificate and its key.
+func newTestCert(t *testing.T, cn string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return cert, key
+}
+
+func writeFile(t *testing.T, dir, name string, data []byte) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImportDirectory(t *testing.T) {
+	web, _ := newTestCert(t, "www.example.com")
+	api1, apiKey := newTestCert(t, "api.example.com")
+	mail, mailKey := newTestCert(t, "mail.example.com")
+	legacy, _ := newTestCert(t, "legacy.example.com")
+
+	keyDER, _ := x509.MarshalPKCS8PrivateKey(apiKey)
+	pfx, err := (&keystore.Bundle{PrivateKey: mailKey, Certificate: mail}).PKCS12("changeit", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	writeFile(t, dir, "web.pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: web.Raw}))
+	writeFile(t, dir, "zz/web-copy.der", web.Raw)
+	writeFile(t, dir, "api.pem", append(
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: api1.Raw})...))
+	writeFile(t, dir, "mail.pfx", pfx)
+	writeFile(t, dir, "legacy.crt", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: legacy.Raw}))
+	writeFile(t, dir, "broken.cer", []byte("not a certificate"))
+	writeFile(t, dir, "notes.txt", []byte("ignored"))
+
+	c := &fakeClient{existing: map[string]bool{Thumbprint(legacy): true}}
+	report, err := ImportDirectory(c, dir, &Options{
+		PFXPassword: func(path string) string { return "changeit" },
+		Metadata: func(path string, cert *x509.Certificate) (map[string]string, error) {
+			return map[string]string{"Source": filepath.Base(path)}, nil
+		},
+		SkipExisting: true,
+	})
+	if err != nil {
+		t.Fatalf("ImportDirectory() error = %v", err)
+	}
+
+	want := map[string]Status{
+		"api.pem":         StatusImported,
+		"broken.cer":      StatusFailed,
+		"legacy.crt":      StatusExisting,
+		"mail.pfx":        StatusImported,
+		"zz/web-copy.der": StatusDuplicate,
+		"web.pem":         StatusImported,
+	}
+	if len(report.Results) != len(want) {
+		t.Fatalf("ImportDirectory() reported %d files, want %d: %+v", len(report.Results), len(want), report.Results)
+	}
+	for _, res := range report.Results {
+		rel, _ := filepath.Rel(dir, res.Path)
+		if res.Status != want[filepath.ToSlash(rel)] {
+			t.Errorf("%s: status = %s (%v), want %s", rel, res.Status, res.Err, want[filepath.ToSlash(rel)])
+		}
+	}
+	if got := len(report.Failed()); got != 1 {
+		t.Errorf("Failed() = %d results, want 1", got)
+	}
+
+	if len(c.imports) != 3 {
+		t.Fatalf("ImportCertificate called %d times, want 3", len(c.imports))
+	}
+	for _, args := range c.imports {
+		if !args.ImportMetadata || args.Metadata["Source"] == "" {
+			t.Errorf("import missing metadata: %+v", args.Metadata)
+		}
+		raw, err := base64.StdEncoding.DecodeString(args.Certificate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch args.Metadata["Source"] {
+		case "api.pem":
+			// The PEM key pair must be repackaged as a PFX carrying the key.
+			blocks, err := pkcs12.ToPEM(raw, args.Password)
+			if err != nil {
+				t.Fatalf("api.pem was not imported as a PFX: %v", err)
+			}
+			hasKey := false
+			for _, b := range blocks {
+				hasKey = hasKey || b.Type == "PRIVATE KEY"
+			}
+			if !hasKey {
+				t.Errorf("api.pem PFX has no private key")
+			}
+		case "web.pem":
+			if args.Password != "" {
+				t.Errorf("certificate-only import has a password")
+			}
+			if _, err := x509.ParseCertificate(raw); err != nil {
+				t.Errorf("web.pem was not imported as DER: %v", err)
+			}
+		case "mail.pfx":
+			if args.Password != "changeit" {
+				t.Errorf("mail.pfx password = %q, want changeit", args.Password)
+			}
+		}
+	}
+}
+
+func TestImportDirectory_RetryAfterFailure(t *testing.T) {
+	cert, key := newTestCert(t, "api.example.com")
+	keyDER, _ := x509.MarshalPKCS8PrivateKey(key)
+
+	dir := t.TempDir()
+	writeFile(t, dir, "a.der", cert.Raw)
+	writeFile(t, dir, "b.pem", append(
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...))
+	writeFile(t, dir, "c.crt", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+
+	var warnings []string
+	logger := api.LoggerFunc(func(level api.LogLevel, msg string, fields api.LogFields) {
+		if level == api.LogLevelWarn {
+			warnings = append(warnings, msg)
+		}
+	})
+	c := &fakeClient{failures: 1}
+	report, err := ImportDirectory(c, dir, &Options{Logger: logger})
+	if err != nil {
+		t.Fatalf("ImportDirectory() error = %v", err)
+	}
+
+	// The failed import of a.der must not make the PEM with the private key a duplicate.
+	want := []Status{StatusFailed, StatusImported, StatusDuplicate}
+	for i, res := range report.Results {
+		if res.Status != want[i] {
+			t.Errorf("%s: status = %s (%v), want %s", filepath.Base(res.Path), res.Status, res.Err, want[i])
+		}
+	}
+	if len(c.imports) != 1 || c.imports[0].Password == "" {
+		t.Errorf("ImportCertificate called with %+v, want b.pem with its key", c.imports)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "a.der") {
+		t.Errorf("Options.Logger got warnings %q, want one for a.der", warnings)
+	}
+}
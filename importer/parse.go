@@ -0,0 +1,160 @@
+package importer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Keyfactor/keyfactor-go-client/keystore"
+	"github.com/spbsoluble/go-pkcs12"
+)
+
+// parsedFile is a certificate file prepared for import.
+type parsedFile struct {
+	leaf *x509.Certificate
+	// contents is the base64 DER certificate or PFX sent to Keyfactor, and password protects a PFX.
+	contents string
+	password string
+}
+
+// parsePEM prepares a PEM file. A file holding a private key along with its certificate is repackaged as a PFX so
+// the key is imported too; otherwise only the first certificate in the file is imported.
+func parsePEM(data []byte) (*parsedFile, error) {
+	var (
+		certs []*x509.Certificate
+		key   crypto.PrivateKey
+	)
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		switch {
+		case block.Type == "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			certs = append(certs, cert)
+		case strings.HasSuffix(block.Type, "PRIVATE KEY"):
+			if block.Type == "ENCRYPTED PRIVATE KEY" || block.Headers["Proc-Type"] != "" {
+				return nil, errors.New("encrypted PEM private keys are not supported; convert the file to PFX")
+			}
+			k, err := parsePrivateKey(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			key = k
+		}
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM certificates found")
+	}
+
+	if key == nil {
+		return &parsedFile{leaf: certs[0], contents: base64.StdEncoding.EncodeToString(certs[0].Raw)}, nil
+	}
+
+	leaf, chain := certs[0], certs[1:]
+	for i, cert := range certs {
+		if publicKeyMatches(cert, key) {
+			leaf = cert
+			chain = append(append([]*x509.Certificate{}, certs[:i]...), certs[i+1:]...)
+			break
+		}
+	}
+	password, err := randomPassword()
+	if err != nil {
+		return nil, err
+	}
+	pfx, err := (&keystore.Bundle{PrivateKey: key, Certificate: leaf, Chain: chain}).PKCS12(password, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &parsedFile{leaf: leaf, contents: base64.StdEncoding.EncodeToString(pfx), password: password}, nil
+}
+
+// parsePFX prepares a PKCS#12 file, which is imported as-is.
+func parsePFX(data []byte, password string) (*parsedFile, error) {
+	blocks, err := pkcs12.ToPEM(data, password)
+	if err != nil {
+		return nil, err
+	}
+	var leaf *x509.Certificate
+	for _, block := range blocks {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if leaf == nil || (leaf.IsCA && !cert.IsCA) {
+			leaf = cert
+		}
+	}
+	if leaf == nil {
+		return nil, errors.New("no certificates found in PFX")
+	}
+	return &parsedFile{leaf: leaf, contents: base64.StdEncoding.EncodeToString(data), password: password}, nil
+}
+
+// parseDER prepares a binary DER certificate file.
+func parseDER(data []byte) (*parsedFile, error) {
+	cert, err := x509.ParseCertificate(data)
+	if err != nil {
+		return nil, err
+	}
+	return &parsedFile{leaf: cert, contents: base64.StdEncoding.EncodeToString(cert.Raw)}, nil
+}
+
+func parsePrivateKey(der []byte) (crypto.PrivateKey, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("unsupported PEM private key")
+}
+
+func publicKeyMatches(cert *x509.Certificate, key crypto.PrivateKey) bool {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k.PublicKey.Equal(cert.PublicKey)
+	case *ecdsa.PrivateKey:
+		return k.PublicKey.Equal(cert.PublicKey)
+	case ed25519.PrivateKey:
+		return k.Public().(ed25519.PublicKey).Equal(cert.PublicKey)
+	}
+	return false
+}
+
+// Thumbprint returns the Keyfactor thumbprint of a certificate: the uppercase hex SHA-1 of its DER encoding.
+func Thumbprint(cert *x509.Certificate) string {
+	sum := sha1.Sum(cert.Raw)
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// randomPassword returns a throwaway password for a PFX built from a PEM key pair.
+func randomPassword() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating PFX password: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+)
+
+type generator struct {
+	spec    *spec
+	cfg     *config
+	schemas []string          // schema names to generate, in the order they were found
+	seen    map[string]bool   // schema names already queued
+	names   map[string]string // schema name -> Go type name
+}
+
+// generate returns the formatted source of the models for the endpoints in cfg.
+func generate(s *spec, cfg *config) ([]byte, error) {
+	g := &generator{spec: s, cfg: cfg, seen: map[string]bool{}, names: map[string]string{}}
+
+	for _, endpoint := range cfg.Endpoints {
+		op, err := s.operation(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		for _, sc := range op.bodySchemas() {
+			g.walk(sc)
+		}
+	}
+	// Walking a schema may queue more schemas, so the list grows as it is processed.
+	for i := 0; i < len(g.schemas); i++ {
+		sc, ok := s.Components.Schemas[g.schemas[i]]
+		if !ok {
+			return nil, fmt.Errorf("schema %s is referenced but not defined in OpenAPI specification", g.schemas[i])
+		}
+		g.walk(sc)
+	}
+	if err := g.assignNames(); err != nil {
+		return nil, err
+	}
+
+	ordered := append([]string{}, g.schemas...)
+	sort.Slice(ordered, func(i, j int) bool { return g.names[ordered[i]] < g.names[ordered[j]] })
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by genmodels from the Keyfactor Command OpenAPI specification. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n", g.cfg.Package)
+	for _, name := range ordered {
+		g.writeType(&buf, name)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("unable to format generated models: %w", err)
+	}
+	return src, nil
+}
+
+// walk queues every component schema referenced by sc.
+func (g *generator) walk(sc *schema) {
+	if sc == nil {
+		return
+	}
+	if sc.Ref != "" {
+		name := refName(sc.Ref)
+		if !g.seen[name] {
+			g.seen[name] = true
+			g.schemas = append(g.schemas, name)
+		}
+		return
+	}
+	g.walk(sc.Items)
+	for _, p := range sc.Properties {
+		g.walk(p)
+	}
+	for _, s := range sc.AllOf {
+		g.walk(s)
+	}
+	g.walk(additionalProperties(sc))
+}
+
+// assignNames picks the Go name of every queued schema: the name from the config if there is one, otherwise the last
+// segment of the schema name, qualified with more segments as needed to keep names unique.
+func (g *generator) assignNames() error {
+	configured := map[string]string{}
+	for schemaName, goName := range g.cfg.Types {
+		configured[normalizeName(schemaName)] = goName
+	}
+
+	used := map[string]string{}
+	sorted := append([]string{}, g.schemas...)
+	sort.Strings(sorted)
+	for _, name := range sorted {
+		goName, ok := configured[normalizeName(name)]
+		if !ok {
+			continue
+		}
+		if other, dup := used[goName]; dup {
+			return fmt.Errorf("schemas %s and %s are both configured as type %s", other, name, goName)
+		}
+		used[goName] = name
+		g.names[name] = goName
+	}
+	// Schemas whose names collide are all qualified with one more segment, until the names are unique.
+	segments := map[string]int{}
+	for _, name := range sorted {
+		if _, ok := g.names[name]; !ok {
+			segments[name] = 1
+		}
+	}
+	for {
+		candidates := map[string][]string{}
+		for name, n := range segments {
+			parts := strings.Split(name, ".")
+			if n > len(parts) {
+				n = len(parts)
+			}
+			goName := exportedName(strings.Join(parts[len(parts)-n:], "."))
+			candidates[goName] = append(candidates[goName], name)
+		}
+		progress := false
+		for goName, names := range candidates {
+			if len(names) == 1 && used[goName] == "" {
+				continue
+			}
+			for _, name := range names {
+				if segments[name] < len(strings.Split(name, ".")) {
+					segments[name]++
+					progress = true
+				}
+			}
+		}
+		if !progress {
+			for goName, names := range candidates {
+				if len(names) > 1 || used[goName] != "" {
+					return fmt.Errorf("unable to give schema %s a unique type name; set one in the config", names[0])
+				}
+				g.names[names[0]] = goName
+			}
+			return nil
+		}
+	}
+}
+
+func (g *generator) writeType(buf *bytes.Buffer, name string) {
+	sc := g.spec.Components.Schemas[name]
+	goName := g.names[name]
+
+	fmt.Fprintf(buf, "\n// %s is generated from the %s schema.\n", goName, name)
+	if desc := oneLine(sc.Description); desc != "" {
+		fmt.Fprintf(buf, "//\n// %s\n", desc)
+	}
+	if !g.isStruct(sc) {
+		fmt.Fprintf(buf, "type %s %s\n", goName, g.goType(sc))
+		return
+	}
+
+	properties, required := flatten(g.spec, sc)
+	propNames := make([]string, 0, len(properties))
+	for p := range properties {
+		propNames = append(propNames, p)
+	}
+	sort.Strings(propNames)
+
+	fmt.Fprintf(buf, "type %s struct {\n", goName)
+	fields := map[string]bool{}
+	for _, p := range propNames {
+		field := exportedName(p)
+		for fields[field] {
+			field += "_"
+		}
+		fields[field] = true
+
+		prop := properties[p]
+		if desc := oneLine(prop.Description); desc != "" {
+			fmt.Fprintf(buf, "\t// %s\n", desc)
+		}
+		tag := p
+		if !required[p] {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(buf, "\t%s %s `json:%q`\n", field, g.fieldType(prop), tag)
+	}
+	fmt.Fprintf(buf, "}\n")
+}
+
+// fieldType is the type of a struct field holding sc. Nested structs are held by pointer, so that they can be omitted.
+func (g *generator) fieldType(sc *schema) string {
+	if target := g.refTarget(sc); target != nil && g.isStruct(target) {
+		return "*" + g.goType(sc)
+	}
+	return g.goType(sc)
+}
+
+func (g *generator) goType(sc *schema) string {
+	if sc == nil {
+		return "interface{}"
+	}
+	if sc.Ref != "" {
+		return g.names[refName(sc.Ref)]
+	}
+	if len(sc.AllOf) == 1 && len(sc.Properties) == 0 {
+		// Nullable references are wrapped in allOf.
+		return g.goType(sc.AllOf[0])
+	}
+	switch sc.Type {
+	case "string":
+		return "string"
+	case "integer":
+		if sc.Format == "int64" {
+			return "int64"
+		}
+		return "int"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + g.goType(sc.Items)
+	case "object":
+		if ap := additionalProperties(sc); ap != nil {
+			return "map[string]" + g.goType(ap)
+		}
+		return "map[string]interface{}"
+	}
+	return "interface{}"
+}
+
+// refTarget returns the component schema sc refers to, if any.
+func (g *generator) refTarget(sc *schema) *schema {
+	if sc == nil {
+		return nil
+	}
+	if sc.Ref != "" {
+		return g.spec.Components.Schemas[refName(sc.Ref)]
+	}
+	if len(sc.AllOf) == 1 && len(sc.Properties) == 0 {
+		return g.refTarget(sc.AllOf[0])
+	}
+	return nil
+}
+
+// isStruct reports whether a component schema is generated as a struct rather than a named basic type.
+func (g *generator) isStruct(sc *schema) bool {
+	properties, _ := flatten(g.spec, sc)
+	return len(properties) > 0 || sc.Type == "object" && additionalProperties(sc) == nil
+}
+
+// flatten merges the properties of sc with those of the schemas it composes with allOf.
+func flatten(s *spec, sc *schema) (map[string]*schema, map[string]bool) {
+	properties := map[string]*schema{}
+	required := map[string]bool{}
+	var visit func(sc *schema, depth int)
+	visit = func(sc *schema, depth int) {
+		if sc == nil || depth > 16 {
+			return
+		}
+		if sc.Ref != "" {
+			visit(s.Components.Schemas[refName(sc.Ref)], depth+1)
+			return
+		}
+		for _, part := range sc.AllOf {
+			visit(part, depth+1)
+		}
+		for name, p := range sc.Properties {
+			properties[name] = p
+		}
+		for _, name := range sc.Required {
+			required[name] = true
+		}
+	}
+	visit(sc, 0)
+	return properties, required
+}
+
+// additionalProperties returns the schema of the values of a map schema, or nil.
+func additionalProperties(sc *schema) *schema {
+	if len(sc.AdditionalProperties) == 0 {
+		return nil
+	}
+	var ap schema
+	if err := json.Unmarshal(sc.AdditionalProperties, &ap); err != nil {
+		// additionalProperties may also be a boolean.
+		return nil
+	}
+	return &ap
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
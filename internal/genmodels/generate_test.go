@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	data, err := os.ReadFile("testdata/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := parseSpec(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config{
+		Package:   "models",
+		Endpoints: []string{"GET /Agents/{id}", "put /certificatestores", "GET /Templates"},
+		Types:     map[string]string{"KeyfactorApiModelsAgentsAgentResponse": "Agent"},
+	}
+
+	got, err := generate(s, cfg)
+	if err != nil {
+		t.Fatalf("generate() error = %v", err)
+	}
+	// Compare ignoring the alignment added by gofmt.
+	src := regexp.MustCompile(`[ \t]+`).ReplaceAllString(string(got), " ")
+
+	for _, want := range []string{
+		"// Code generated by genmodels",
+		"package models",
+		"// Agent is generated from the Keyfactor.Api.Models.Agents.AgentResponse schema.\n//\n// An orchestrator registered with Keyfactor.\ntype Agent struct {",
+		"Status AgentStatus `json:\"Status,omitempty\"`",
+		"Capabilities []string `json:\"Capabilities,omitempty\"`",
+		"type AgentStatus int",
+		// Properties composed with allOf are merged, and required ones are not omitted.
+		"type CertificateStoreUpdateRequest struct {",
+		"ClientMachine string `json:\"ClientMachine\"`",
+		"// The ID of the store to update.\n Id string `json:\"Id,omitempty\"`",
+		"InventorySchedule *KeyfactorSchedule `json:\"InventorySchedule,omitempty\"`",
+		"Properties map[string]string `json:\"Properties,omitempty\"`",
+		"ReenrollmentStatus *ReenrollmentStatus",
+		// Schemas with the same last segment are qualified to keep their names unique.
+		"type SchedulingInterval struct {",
+		"type TemplatesInterval string",
+		"KeyRetentionDays int64",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generate() output does not contain %q:\n%s", want, src)
+		}
+	}
+	// Error responses and schemas only used by them are not generated.
+	if strings.Contains(src, "type Error struct") {
+		t.Errorf("generate() generated a model for an error response:\n%s", src)
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	data, err := os.ReadFile("testdata/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := parseSpec(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		cfg  *config
+	}{
+		{name: "UnknownEndpoint", cfg: &config{Package: "models", Endpoints: []string{"GET /Unknown"}}},
+		{name: "UnknownMethod", cfg: &config{Package: "models", Endpoints: []string{"DELETE /Templates"}}},
+		{name: "MalformedEndpoint", cfg: &config{Package: "models", Endpoints: []string{"/Templates"}}},
+		{name: "DuplicateType", cfg: &config{Package: "models", Endpoints: []string{"GET /Agents/{id}"}, Types: map[string]string{
+			"Keyfactor.Api.Models.Agents.AgentResponse":        "Agent",
+			"Keyfactor.Orchestrators.Common.Enums.AgentStatus": "Agent",
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := generate(s, tt.cfg); err == nil {
+				t.Errorf("generate() succeeded, want error")
+			}
+		})
+	}
+}
+
+func Test_parseConfig(t *testing.T) {
+	if _, err := parseConfig([]byte(`{"package": "models", "endpoints": ["GET /Agents"]}`)); err != nil {
+		t.Errorf("parseConfig() error = %v", err)
+	}
+	for _, data := range []string{`{"endpoints": ["GET /Agents"]}`, `{"package": "models"}`, `{`} {
+		if _, err := parseConfig([]byte(data)); err == nil {
+			t.Errorf("parseConfig(%s) succeeded, want error", data)
+		}
+	}
+}
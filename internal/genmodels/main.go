@@ -0,0 +1,79 @@
+// Command genmodels generates Go structs for the request and response bodies of Keyfactor Command API endpoints from
+// the OpenAPI specification served by Command, e.g. https://<host>/KeyfactorAPI/swagger/v1/swagger.json. It is run
+// with go generate from the package that holds the generated models:
+//
+//	KEYFACTOR_OPENAPI_SPEC=swagger.json go generate ./api/models
+//
+// The endpoints to generate models for, and optional Go names for their schemas, are read from a JSON config file:
+//
+//	{
+//	  "package": "models",
+//	  "endpoints": ["GET /Agents", "PUT /CertificateStores"],
+//	  "types": {"Keyfactor.Api.Models.Agents.AgentResponse": "Agent"}
+//	}
+//
+// Every schema referenced by the bodies of the listed endpoints is generated, along with the schemas they reference.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+)
+
+func main() {
+	specPath := flag.String("spec", "", "Path or URL of the Keyfactor Command OpenAPI specification.")
+	configPath := flag.String("config", "models.json", "Path of the generator config.")
+	outPath := flag.String("out", "models_gen.go", "Path of the generated file.")
+	flag.Parse()
+
+	if *specPath == "" {
+		log.Fatal("-spec is required; set KEYFACTOR_OPENAPI_SPEC to the path or URL of the Command OpenAPI specification")
+	}
+
+	specData, err := readSource(*specPath)
+	if err != nil {
+		log.Fatalf("unable to read OpenAPI specification: %s", err)
+	}
+	configData, err := os.ReadFile(*configPath)
+	if err != nil {
+		log.Fatalf("unable to read generator config: %s", err)
+	}
+
+	cfg, err := parseConfig(configData)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s, err := parseSpec(specData)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := generate(s, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*outPath, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Generated %s\n", *outPath)
+}
+
+// readSource reads a file, or fetches it if path is an HTTP(S) URL.
+func readSource(path string) ([]byte, error) {
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		return os.ReadFile(path)
+	}
+	resp, err := http.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", path, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
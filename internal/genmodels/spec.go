@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// config is the generator config file.
+type config struct {
+	// Package is the name of the generated package.
+	Package string `json:"package"`
+	// Endpoints lists the endpoints to generate models for, as "METHOD /Path" relative to the API path.
+	Endpoints []string `json:"endpoints"`
+	// Types maps schema names to the Go names of their types. Schemas that are not listed are named after the last
+	// segment of their schema name.
+	Types map[string]string `json:"types"`
+}
+
+func parseConfig(data []byte) (*config, error) {
+	var cfg config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid generator config: %w", err)
+	}
+	if cfg.Package == "" {
+		return nil, errors.New("invalid generator config: package is required")
+	}
+	if len(cfg.Endpoints) == 0 {
+		return nil, errors.New("invalid generator config: at least one endpoint is required")
+	}
+	return &cfg, nil
+}
+
+// spec is the subset of an OpenAPI 3 document used by the generator.
+type spec struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type operation struct {
+	RequestBody *body            `json:"requestBody"`
+	Responses   map[string]*body `json:"responses"`
+}
+
+type body struct {
+	Content map[string]struct {
+		Schema *schema `json:"schema"`
+	} `json:"content"`
+}
+
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Description          string             `json:"description"`
+	Items                *schema            `json:"items"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AllOf                []*schema          `json:"allOf"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+}
+
+func parseSpec(data []byte) (*spec, error) {
+	var s spec
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI specification: %w", err)
+	}
+	if len(s.Paths) == 0 || len(s.Components.Schemas) == 0 {
+		return nil, errors.New("invalid OpenAPI specification: no paths or component schemas found; only OpenAPI 3 documents are supported")
+	}
+	return &s, nil
+}
+
+// operation returns the operation for an endpoint given as "METHOD /Path". Paths are matched ignoring case and the
+// API path prefix, e.g. "/KeyfactorAPI".
+func (s *spec) operation(endpoint string) (*operation, error) {
+	method, path, ok := strings.Cut(strings.TrimSpace(endpoint), " ")
+	if !ok {
+		return nil, fmt.Errorf("invalid endpoint %q, want \"METHOD /Path\"", endpoint)
+	}
+	method = strings.ToLower(method)
+	path = "/" + strings.Trim(strings.TrimSpace(path), "/")
+
+	var found string
+	for p := range s.Paths {
+		if strings.EqualFold(trimAPIPath(p), path) {
+			found = p
+			break
+		}
+	}
+	if found == "" {
+		return nil, fmt.Errorf("endpoint %s not found in OpenAPI specification", endpoint)
+	}
+	raw, ok := s.Paths[found][method]
+	if !ok {
+		return nil, fmt.Errorf("endpoint %s has no %s operation in OpenAPI specification", found, strings.ToUpper(method))
+	}
+	var op operation
+	if err := json.Unmarshal(raw, &op); err != nil {
+		return nil, fmt.Errorf("invalid operation %s: %w", endpoint, err)
+	}
+	return &op, nil
+}
+
+// bodySchemas returns the JSON request body and success response schemas of op.
+func (op *operation) bodySchemas() []*schema {
+	var schemas []*schema
+	add := func(b *body) {
+		if b == nil {
+			return
+		}
+		for contentType, c := range b.Content {
+			if strings.Contains(contentType, "json") && c.Schema != nil {
+				schemas = append(schemas, c.Schema)
+				return
+			}
+		}
+	}
+	add(op.RequestBody)
+	for status, b := range op.Responses {
+		if strings.HasPrefix(status, "2") {
+			add(b)
+		}
+	}
+	return schemas
+}
+
+// trimAPIPath removes the API path prefix from a specification path, for specifications that include it in their
+// paths rather than in their server URL.
+func trimAPIPath(p string) string {
+	if len(p) >= len(apiPathPrefix) && strings.EqualFold(p[:len(apiPathPrefix)], apiPathPrefix) {
+		return p[len(apiPathPrefix):]
+	}
+	return p
+}
+
+const apiPathPrefix = "/KeyfactorAPI"
+
+const schemaRefPrefix = "#/components/schemas/"
+
+func refName(ref string) string {
+	return strings.TrimPrefix(ref, schemaRefPrefix)
+}
+
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// normalizeName folds a schema name for matching config entries, so that "Keyfactor.Api.Models.Agent" and
+// "KeyfactorApiModelsAgent" name the same schema.
+func normalizeName(name string) string {
+	return strings.ToLower(nonIdentifier.ReplaceAllString(name, ""))
+}
+
+// exportedName turns name into an exported Go identifier.
+func exportedName(name string) string {
+	parts := nonIdentifier.Split(name, -1)
+	var b strings.Builder
+	for _, p := range parts {
+		if p == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(p[:1]) + p[1:])
+	}
+	s := b.String()
+	if s == "" || s[0] >= '0' && s[0] <= '9' {
+		s = "X" + s
+	}
+	return s
+}
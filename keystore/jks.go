@@ -0,0 +1,210 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	jksMagic   = 0xFEEDFEED
+	jksVersion = 2
+
+	jksPrivateKeyTag  = 1
+	jksTrustedCertTag = 2
+
+	// jksDigestWhitener is the fixed string mixed into the keystore integrity digest by the Sun JKS provider.
+	jksDigestWhitener = "Mighty Aphrodite"
+)
+
+// oidJKSKeyProtector identifies the proprietary Sun algorithm used to protect private keys in a JKS.
+var oidJKSKeyProtector = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 42, 2, 17, 1, 1}
+
+// JKS encodes the bundle as a Java KeyStore holding a single private key entry under alias, with the certificate
+// chain attached. The key entry is protected with the same password as the keystore, which is what Tomcat and most
+// Java application servers expect. Aliases are lower-cased, matching the Java implementation.
+func (b *Bundle) JKS(alias, password string) ([]byte, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	if alias == "" {
+		return nil, errors.New("keystore: alias is required for a JKS private key entry")
+	}
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(b.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: %w", err)
+	}
+	protected, err := jksProtectKey(rand.Reader, pkcs8, password)
+	if err != nil {
+		return nil, err
+	}
+
+	w := newJKSWriter(1)
+	w.uint32(jksPrivateKeyTag)
+	if err := w.entryHeader(alias); err != nil {
+		return nil, err
+	}
+	w.bytes(protected)
+	chain := append([]*x509.Certificate{b.Certificate}, b.chain()...)
+	w.uint32(uint32(len(chain)))
+	for _, cert := range chain {
+		w.certificate(cert)
+	}
+	return w.finish(password), nil
+}
+
+// EncodeJKSTrustStore encodes certs as a Java trust store of trusted certificate entries. Each entry's alias is the
+// certificate's lower-cased common name, suffixed with a counter when names collide.
+func EncodeJKSTrustStore(certs []*x509.Certificate, password string) ([]byte, error) {
+	if len(certs) == 0 {
+		return nil, errors.New("keystore: at least one certificate is required for a trust store")
+	}
+
+	w := newJKSWriter(len(certs))
+	seen := make(map[string]int)
+	for _, cert := range certs {
+		if cert == nil {
+			return nil, errors.New("keystore: trust store certificate is nil")
+		}
+		alias := strings.ToLower(cert.Subject.CommonName)
+		if alias == "" {
+			alias = "cert"
+		}
+		seen[alias]++
+		if n := seen[alias]; n > 1 {
+			alias = fmt.Sprintf("%s-%d", alias, n)
+		}
+
+		w.uint32(jksTrustedCertTag)
+		if err := w.entryHeader(alias); err != nil {
+			return nil, err
+		}
+		w.certificate(cert)
+	}
+	return w.finish(password), nil
+}
+
+// jksWriter accumulates the big-endian JKS stream.
+type jksWriter struct {
+	buf       bytes.Buffer
+	timestamp uint64
+}
+
+func newJKSWriter(entries int) *jksWriter {
+	w := &jksWriter{timestamp: uint64(time.Now().UnixNano() / int64(time.Millisecond))}
+	w.uint32(jksMagic)
+	w.uint32(jksVersion)
+	w.uint32(uint32(entries))
+	return w
+}
+
+func (w *jksWriter) uint32(v uint32) {
+	binary.Write(&w.buf, binary.BigEndian, v)
+}
+
+func (w *jksWriter) bytes(b []byte) {
+	w.uint32(uint32(len(b)))
+	w.buf.Write(b)
+}
+
+// utf writes s in the length-prefixed modified UTF-8 of java.io.DataOutput.writeUTF, so that keytool reads back the
+// same alias.
+func (w *jksWriter) utf(s string) error {
+	b := modifiedUTF8(s)
+	if len(b) > 0xFFFF {
+		return fmt.Errorf("keystore: string %.20q... is too long for a JKS entry", s)
+	}
+	binary.Write(&w.buf, binary.BigEndian, uint16(len(b)))
+	w.buf.Write(b)
+	return nil
+}
+
+// modifiedUTF8 encodes s as Java's modified UTF-8: each UTF-16 code unit is encoded on its own, so characters outside
+// the Basic Multilingual Plane become two 3-byte surrogates, and NUL is encoded as the 2 bytes 0xC0 0x80.
+func modifiedUTF8(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	out := make([]byte, 0, len(s))
+	for _, u := range units {
+		switch {
+		case u != 0 && u < 0x80:
+			out = append(out, byte(u))
+		case u < 0x800:
+			out = append(out, 0xC0|byte(u>>6), 0x80|byte(u&0x3F))
+		default:
+			out = append(out, 0xE0|byte(u>>12), 0x80|byte(u>>6&0x3F), 0x80|byte(u&0x3F))
+		}
+	}
+	return out
+}
+
+func (w *jksWriter) entryHeader(alias string) error {
+	if err := w.utf(strings.ToLower(alias)); err != nil {
+		return err
+	}
+	binary.Write(&w.buf, binary.BigEndian, w.timestamp)
+	return nil
+}
+
+func (w *jksWriter) certificate(cert *x509.Certificate) {
+	w.utf("X.509")
+	w.bytes(cert.Raw)
+}
+
+// finish appends the keystore integrity digest and returns the encoded keystore.
+func (w *jksWriter) finish(password string) []byte {
+	h := sha1.New()
+	h.Write(utf16BE(password))
+	h.Write([]byte(jksDigestWhitener))
+	h.Write(w.buf.Bytes())
+	w.buf.Write(h.Sum(nil))
+	return w.buf.Bytes()
+}
+
+// jksProtectKey encrypts a PKCS#8 key with the Sun KeyProtector scheme: the key is XORed with a SHA-1 keystream
+// seeded by a random salt, followed by a SHA-1 integrity check, and wrapped in an EncryptedPrivateKeyInfo.
+func jksProtectKey(rand io.Reader, pkcs8 []byte, password string) ([]byte, error) {
+	passwd := utf16BE(password)
+
+	salt := make([]byte, sha1.Size)
+	if _, err := io.ReadFull(rand, salt); err != nil {
+		return nil, err
+	}
+
+	encrypted := make([]byte, len(pkcs8))
+	digest := salt
+	for i := 0; i < len(pkcs8); i += sha1.Size {
+		sum := sha1.Sum(append(append([]byte{}, passwd...), digest...))
+		digest = sum[:]
+		for j := 0; j < sha1.Size && i+j < len(pkcs8); j++ {
+			encrypted[i+j] = pkcs8[i+j] ^ digest[j]
+		}
+	}
+	check := sha1.Sum(append(append([]byte{}, passwd...), pkcs8...))
+
+	protected := append(append(salt, encrypted...), check[:]...)
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		AlgorithmIdentifier: pkix.AlgorithmIdentifier{Algorithm: oidJKSKeyProtector, Parameters: asn1.NullRawValue},
+		EncryptedData:       protected,
+	})
+}
+
+// utf16BE encodes s as big-endian UTF-16 without a terminator, the password encoding used by JKS.
+func utf16BE(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	out := make([]byte, 0, 2*len(units))
+	for _, u := range units {
+		out = append(out, byte(u>>8), byte(u))
+	}
+	return out
+}
@@ -0,0 +1,235 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+)
+
+// jksEntry is a decoded JKS entry used to verify encoder output.
+type jksEntry struct {
+	tag   uint32
+	alias string
+	key   []byte
+	certs []*x509.Certificate
+}
+
+// readJKS parses a keystore produced by the encoders, verifying its integrity digest.
+func readJKS(t *testing.T, data []byte, password string) []jksEntry {
+	t.Helper()
+	body, digest := data[:len(data)-sha1.Size], data[len(data)-sha1.Size:]
+	want := sha1.Sum(append(append(utf16BE(password), jksDigestWhitener...), body...))
+	if !bytes.Equal(digest, want[:]) {
+		t.Fatal("JKS integrity digest mismatch")
+	}
+
+	r := bytes.NewReader(body)
+	u32 := func() uint32 {
+		var v uint32
+		binary.Read(r, binary.BigEndian, &v)
+		return v
+	}
+	str := func() string {
+		var n uint16
+		binary.Read(r, binary.BigEndian, &n)
+		b := make([]byte, n)
+		r.Read(b)
+		return decodeModifiedUTF8(t, b)
+	}
+	blob := func() []byte {
+		b := make([]byte, u32())
+		r.Read(b)
+		return b
+	}
+	cert := func() *x509.Certificate {
+		if typ := str(); typ != "X.509" {
+			t.Fatalf("certificate type = %q, want X.509", typ)
+		}
+		c, err := x509.ParseCertificate(blob())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	if u32() != jksMagic || u32() != jksVersion {
+		t.Fatal("bad JKS header")
+	}
+	entries := make([]jksEntry, u32())
+	for i := range entries {
+		e := &entries[i]
+		e.tag = u32()
+		e.alias = str()
+		r.Seek(8, 1) // timestamp
+		if e.tag == jksPrivateKeyTag {
+			e.key = blob()
+			for n := u32(); n > 0; n-- {
+				e.certs = append(e.certs, cert())
+			}
+		} else {
+			e.certs = []*x509.Certificate{cert()}
+		}
+	}
+	if r.Len() != 0 {
+		t.Fatalf("%d trailing bytes in JKS", r.Len())
+	}
+	return entries
+}
+
+// decodeModifiedUTF8 reverses modifiedUTF8.
+func decodeModifiedUTF8(t *testing.T, b []byte) string {
+	t.Helper()
+	var units []uint16
+	for i := 0; i < len(b); {
+		switch c := b[i]; {
+		case c < 0x80:
+			units = append(units, uint16(c))
+			i++
+		case c&0xE0 == 0xC0 && i+1 < len(b):
+			units = append(units, uint16(c&0x1F)<<6|uint16(b[i+1]&0x3F))
+			i += 2
+		case c&0xF0 == 0xE0 && i+2 < len(b):
+			units = append(units, uint16(c&0x0F)<<12|uint16(b[i+1]&0x3F)<<6|uint16(b[i+2]&0x3F))
+			i += 3
+		default:
+			t.Fatalf("invalid modified UTF-8 % x", b)
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// jksRecoverKey reverses jksProtectKey.
+func jksRecoverKey(t *testing.T, der []byte, password string) []byte {
+	t.Helper()
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		t.Fatal(err)
+	}
+	if !info.AlgorithmIdentifier.Algorithm.Equal(oidJKSKeyProtector) {
+		t.Fatalf("key protector = %s", info.AlgorithmIdentifier.Algorithm)
+	}
+	data := info.EncryptedData
+	salt, encrypted, check := data[:sha1.Size], data[sha1.Size:len(data)-sha1.Size], data[len(data)-sha1.Size:]
+
+	passwd := utf16BE(password)
+	plain := make([]byte, len(encrypted))
+	digest := salt
+	for i := 0; i < len(encrypted); i += sha1.Size {
+		sum := sha1.Sum(append(append([]byte{}, passwd...), digest...))
+		digest = sum[:]
+		for j := 0; j < sha1.Size && i+j < len(encrypted); j++ {
+			plain[i+j] = encrypted[i+j] ^ digest[j]
+		}
+	}
+	if sum := sha1.Sum(append(passwd, plain...)); !bytes.Equal(sum[:], check) {
+		t.Fatal("JKS key integrity check failed")
+	}
+	return plain
+}
+
+func TestBundle_JKS(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	bundle := newTestBundle(t, key)
+
+	tests := []struct {
+		name      string
+		bundle    *Bundle
+		alias     string
+		wantAlias string
+		wantErr   bool
+	}{
+		{name: "PrivateKeyEntry", bundle: bundle, alias: "Tomcat", wantAlias: "tomcat"},
+		{name: "SupplementaryAlias", bundle: bundle, alias: "Tomcat-\U0001F600", wantAlias: "tomcat-\U0001F600"},
+		{name: "MissingAlias", bundle: bundle, wantErr: true},
+		{name: "MissingCertificate", bundle: &Bundle{PrivateKey: key}, alias: "tomcat", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.bundle.JKS(tt.alias, "changeit")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("JKS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			entries := readJKS(t, got, "changeit")
+			if len(entries) != 1 || entries[0].tag != jksPrivateKeyTag {
+				t.Fatalf("JKS() entries = %+v, want a single private key entry", entries)
+			}
+			e := entries[0]
+			if e.alias != tt.wantAlias {
+				t.Errorf("JKS() alias = %q, want %q", e.alias, tt.wantAlias)
+			}
+			if len(e.certs) != 2 || !e.certs[0].Equal(bundle.Certificate) || !e.certs[1].Equal(bundle.Chain[1]) {
+				t.Errorf("JKS() chain has %d certificates, want leaf then CA", len(e.certs))
+			}
+			recovered, err := x509.ParsePKCS8PrivateKey(jksRecoverKey(t, e.key, "changeit"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !recovered.(interface{ Equal(crypto.PrivateKey) bool }).Equal(key) {
+				t.Errorf("JKS() private key does not match")
+			}
+		})
+	}
+}
+
+func TestModifiedUTF8(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []byte
+	}{
+		{"tomcat", []byte("tomcat")},
+		{"caf\u00e9", []byte{'c', 'a', 'f', 0xC3, 0xA9}},
+		{"a\x00b", []byte{'a', 0xC0, 0x80, 'b'}},
+		// U+1F600 is the surrogate pair D83D DE00, each encoded on its own.
+		{"\U0001F600", []byte{0xED, 0xA0, 0xBD, 0xED, 0xB8, 0x80}},
+	}
+	for _, tt := range tests {
+		if got := modifiedUTF8(tt.s); !bytes.Equal(got, tt.want) {
+			t.Errorf("modifiedUTF8(%q) = % x, want % x", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeJKSTrustStore(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	first := newTestBundle(t, key).Chain[1]
+	second := newTestBundle(t, key).Chain[1]
+
+	tests := []struct {
+		name        string
+		certs       []*x509.Certificate
+		wantAliases []string
+		wantErr     bool
+	}{
+		{name: "DuplicateNames", certs: []*x509.Certificate{first, second}, wantAliases: []string{"test root ca", "test root ca-2"}},
+		{name: "Empty", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeJKSTrustStore(tt.certs, "changeit")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EncodeJKSTrustStore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			entries := readJKS(t, got, "changeit")
+			for i, e := range entries {
+				if e.tag != jksTrustedCertTag || e.alias != tt.wantAliases[i] || !e.certs[0].Equal(tt.certs[i]) {
+					t.Errorf("EncodeJKSTrustStore() entry %d = %q (tag %d), want %q", i, e.alias, e.tag, tt.wantAliases[i])
+				}
+			}
+		})
+	}
+}
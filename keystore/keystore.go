@@ -0,0 +1,49 @@
+// Package keystore converts certificates and private keys issued or recovered through Keyfactor Command into the
+// container formats expected by deployment targets: PKCS#12 bundles, with either legacy or modern encryption, and
+// Java KeyStores (JKS).
+//
+//	bundle := &keystore.Bundle{PrivateKey: key, Certificate: leaf, Chain: chain}
+//	pfx, err := bundle.PKCS12("changeit", &keystore.PKCS12Options{Encryption: keystore.PKCS12Modern})
+//	jks, err := bundle.JKS("tomcat", "changeit")
+package keystore
+
+import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+)
+
+// Bundle is a private key together with its certificate and issuing chain.
+type Bundle struct {
+	// PrivateKey is an *rsa.PrivateKey, *ecdsa.PrivateKey, or ed25519.PrivateKey.
+	PrivateKey crypto.PrivateKey
+	// Certificate is the leaf certificate matching PrivateKey.
+	Certificate *x509.Certificate
+	// Chain holds the issuing certificates, ordered from the leaf's issuer towards the root.
+	Chain []*x509.Certificate
+}
+
+func (b *Bundle) validate() error {
+	if b == nil {
+		return errors.New("keystore: bundle is nil")
+	}
+	if b.PrivateKey == nil {
+		return errors.New("keystore: bundle has no private key")
+	}
+	if b.Certificate == nil {
+		return errors.New("keystore: bundle has no certificate")
+	}
+	return nil
+}
+
+// chain returns the bundle's chain without the leaf certificate, which Keyfactor sometimes includes.
+func (b *Bundle) chain() []*x509.Certificate {
+	chain := make([]*x509.Certificate, 0, len(b.Chain))
+	for _, cert := range b.Chain {
+		if cert == nil || cert.Equal(b.Certificate) {
+			continue
+		}
+		chain = append(chain, cert)
+	}
+	return chain
+}
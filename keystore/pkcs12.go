@@ -0,0 +1,364 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/spbsoluble/go-pkcs12"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// PKCS12Encryption selects the algorithms used to protect a PKCS#12 bundle.
+type PKCS12Encryption int
+
+const (
+	// PKCS12Legacy encrypts certificates with RC2-40 and the private key with 3DES, with a SHA-1 MAC. This matches
+	// OpenSSL 1.x defaults and is readable by Java 8 and older Windows releases, but offers weak protection.
+	PKCS12Legacy PKCS12Encryption = iota
+	// PKCS12Modern encrypts certificates and the private key with AES-256-CBC keyed by PBKDF2-HMAC-SHA256, with a
+	// SHA-256 MAC. This matches OpenSSL 3 defaults and requires Java 11 or Windows Server 2019 and newer.
+	PKCS12Modern
+)
+
+// DefaultPKCS12Iterations is the PBKDF2 and MAC iteration count used for PKCS12Modern when none is configured.
+const DefaultPKCS12Iterations = 2048
+
+// PKCS12Options configures PKCS#12 encoding. A nil *PKCS12Options selects PKCS12Legacy.
+type PKCS12Options struct {
+	Encryption PKCS12Encryption
+	// Iterations overrides DefaultPKCS12Iterations for PKCS12Modern. It is ignored for PKCS12Legacy.
+	Iterations int
+}
+
+// PKCS12 encodes the bundle as a password-protected PKCS#12 (PFX) file.
+func (b *Bundle) PKCS12(password string, opts *PKCS12Options) ([]byte, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &PKCS12Options{}
+	}
+
+	switch opts.Encryption {
+	case PKCS12Legacy:
+		return pkcs12.Encode(rand.Reader, b.PrivateKey, b.Certificate, b.chain(), password)
+	case PKCS12Modern:
+		iterations := opts.Iterations
+		if iterations <= 0 {
+			iterations = DefaultPKCS12Iterations
+		}
+		return encodeModernPKCS12(rand.Reader, b.PrivateKey, b.Certificate, b.chain(), password, iterations)
+	}
+	return nil, fmt.Errorf("keystore: unsupported PKCS#12 encryption %d", opts.Encryption)
+}
+
+var (
+	oidDataContentType          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidEncryptedDataContentType = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+	oidLocalKeyID               = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
+	oidCertTypeX509Certificate  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+	oidPKCS8ShroudedKeyBag      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidCertBag                  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidPBES2                    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2                   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHmacWithSHA256           = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidAES256CBC                = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidSHA256                   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+)
+
+// ASN.1 structures from RFC 7292 (PKCS#12), RFC 2315 (PKCS#7), and RFC 8018 (PKCS#5).
+type pfxPdu struct {
+	Version  int
+	AuthSafe contentInfo
+	MacData  macData `asn1:"optional"`
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+type encryptedData struct {
+	Version              int
+	EncryptedContentInfo encryptedContentInfo
+}
+
+type encryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedContent           []byte `asn1:"tag:0,optional"`
+}
+
+type safeBag struct {
+	Id         asn1.ObjectIdentifier
+	Value      asn1.RawValue     `asn1:"tag:0,explicit"`
+	Attributes []pkcs12Attribute `asn1:"set,optional"`
+}
+
+type pkcs12Attribute struct {
+	Id    asn1.ObjectIdentifier
+	Value asn1.RawValue `asn1:"set"`
+}
+
+type certBag struct {
+	Id   asn1.ObjectIdentifier
+	Data []byte `asn1:"tag:0,explicit"`
+}
+
+type encryptedPrivateKeyInfo struct {
+	AlgorithmIdentifier pkix.AlgorithmIdentifier
+	EncryptedData       []byte
+}
+
+type pbes2Params struct {
+	Kdf              pkix.AlgorithmIdentifier
+	EncryptionScheme pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt       []byte
+	Iterations int
+	Prf        pkix.AlgorithmIdentifier
+}
+
+type macData struct {
+	Mac        digestInfo
+	MacSalt    []byte
+	Iterations int `asn1:"optional,default:1"`
+}
+
+type digestInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Digest    []byte
+}
+
+// encodeModernPKCS12 produces a PKCS#12 file laid out like OpenSSL 3: an encrypted SafeContents holding the
+// certificates and a plain SafeContents holding the PBES2-shrouded key, authenticated with an HMAC-SHA256 MAC.
+func encodeModernPKCS12(rand io.Reader, key interface{}, leaf *x509.Certificate, chain []*x509.Certificate, password string, iterations int) ([]byte, error) {
+	bmpPassword, err := bmpStringZeroTerminated(password)
+	if err != nil {
+		return nil, err
+	}
+	utf8Password := []byte(password)
+
+	fingerprint := sha1.Sum(leaf.Raw)
+	fingerprintDER, err := asn1.Marshal(fingerprint[:])
+	if err != nil {
+		return nil, err
+	}
+	localKeyID := pkcs12Attribute{
+		Id:    oidLocalKeyID,
+		Value: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: fingerprintDER},
+	}
+
+	// Certificates
+	var certBags []safeBag
+	for i, cert := range append([]*x509.Certificate{leaf}, chain...) {
+		bagDER, err := asn1.Marshal(certBag{Id: oidCertTypeX509Certificate, Data: cert.Raw})
+		if err != nil {
+			return nil, err
+		}
+		bag := safeBag{Id: oidCertBag, Value: explicitTag0(bagDER)}
+		if i == 0 {
+			bag.Attributes = []pkcs12Attribute{localKeyID}
+		}
+		certBags = append(certBags, bag)
+	}
+	certContentsDER, err := asn1.Marshal(certBags)
+	if err != nil {
+		return nil, err
+	}
+	certAlg, encryptedCerts, err := pbes2Encrypt(rand, certContentsDER, utf8Password, iterations)
+	if err != nil {
+		return nil, err
+	}
+	encryptedDataDER, err := asn1.Marshal(encryptedData{
+		EncryptedContentInfo: encryptedContentInfo{
+			ContentType:                oidDataContentType,
+			ContentEncryptionAlgorithm: certAlg,
+			EncryptedContent:           encryptedCerts,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Private key
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: %w", err)
+	}
+	keyAlg, encryptedKey, err := pbes2Encrypt(rand, pkcs8, utf8Password, iterations)
+	if err != nil {
+		return nil, err
+	}
+	keyInfoDER, err := asn1.Marshal(encryptedPrivateKeyInfo{AlgorithmIdentifier: keyAlg, EncryptedData: encryptedKey})
+	if err != nil {
+		return nil, err
+	}
+	keyContentsDER, err := asn1.Marshal([]safeBag{{
+		Id:         oidPKCS8ShroudedKeyBag,
+		Value:      explicitTag0(keyInfoDER),
+		Attributes: []pkcs12Attribute{localKeyID},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	keyContentsOctets, err := asn1.Marshal(keyContentsDER)
+	if err != nil {
+		return nil, err
+	}
+
+	authSafeDER, err := asn1.Marshal([]contentInfo{
+		{ContentType: oidEncryptedDataContentType, Content: explicitTag0(encryptedDataDER)},
+		{ContentType: oidDataContentType, Content: explicitTag0(keyContentsOctets)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	authSafeOctets, err := asn1.Marshal(authSafeDER)
+	if err != nil {
+		return nil, err
+	}
+
+	// MAC over the authenticated safe, keyed with the PKCS#12 KDF as required by RFC 7292 appendix B
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(rand, salt); err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, pkcs12KDF(salt, bmpPassword, iterations, 3, sha256.Size))
+	mac.Write(authSafeDER)
+
+	return asn1.Marshal(pfxPdu{
+		Version:  3,
+		AuthSafe: contentInfo{ContentType: oidDataContentType, Content: explicitTag0(authSafeOctets)},
+		MacData: macData{
+			Mac: digestInfo{
+				Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+				Digest:    mac.Sum(nil),
+			},
+			MacSalt:    salt,
+			Iterations: iterations,
+		},
+	})
+}
+
+// pbes2Encrypt encrypts plaintext with AES-256-CBC using a PBKDF2-HMAC-SHA256 derived key and returns the matching
+// algorithm identifier.
+func pbes2Encrypt(rand io.Reader, plaintext, password []byte, iterations int) (pkix.AlgorithmIdentifier, []byte, error) {
+	salt := make([]byte, 16)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand, salt); err != nil {
+		return pkix.AlgorithmIdentifier{}, nil, err
+	}
+	if _, err := io.ReadFull(rand, iv); err != nil {
+		return pkix.AlgorithmIdentifier{}, nil, err
+	}
+
+	block, err := aes.NewCipher(pbkdf2.Key(password, salt, iterations, 32, sha256.New))
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, nil, err
+	}
+	padLen := aes.BlockSize - len(plaintext)%aes.BlockSize
+	ciphertext := append(append([]byte{}, plaintext...), bytes.Repeat([]byte{byte(padLen)}, padLen)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, ciphertext)
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:       salt,
+		Iterations: iterations,
+		Prf:        pkix.AlgorithmIdentifier{Algorithm: oidHmacWithSHA256, Parameters: asn1.NullRawValue},
+	})
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, nil, err
+	}
+	ivDER, err := asn1.Marshal(iv)
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, nil, err
+	}
+	params, err := asn1.Marshal(pbes2Params{
+		Kdf:              pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+		EncryptionScheme: pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{FullBytes: ivDER}},
+	})
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, nil, err
+	}
+	return pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: params}}, ciphertext, nil
+}
+
+// pkcs12KDF implements the SHA-256 variant of the key derivation function from RFC 7292 appendix B.2.
+func pkcs12KDF(salt, password []byte, iterations int, id byte, size int) []byte {
+	const u, v = sha256.Size, 64
+
+	d := bytes.Repeat([]byte{id}, v)
+	i := append(fillWithRepeats(salt, v), fillWithRepeats(password, v)...)
+
+	var out []byte
+	one := big.NewInt(1)
+	for len(out) < size {
+		a := sha256.Sum256(append(append([]byte{}, d...), i...))
+		for n := 1; n < iterations; n++ {
+			a = sha256.Sum256(a[:])
+		}
+		out = append(out, a[:]...)
+		if len(out) >= size {
+			break
+		}
+
+		// I_j = (I_j + B + 1) mod 2^v for each v-byte block of I
+		b := new(big.Int).SetBytes(fillWithRepeats(a[:u], v))
+		for j := 0; j < len(i); j += v {
+			ij := new(big.Int).SetBytes(i[j : j+v])
+			ij.Add(ij, b)
+			ij.Add(ij, one)
+			sum := ij.Bytes()
+			if len(sum) > v {
+				sum = sum[len(sum)-v:]
+			}
+			block := i[j : j+v]
+			for k := range block {
+				block[k] = 0
+			}
+			copy(block[v-len(sum):], sum)
+		}
+	}
+	return out[:size]
+}
+
+// fillWithRepeats returns pattern repeated to the smallest multiple of v bytes that holds it.
+func fillWithRepeats(pattern []byte, v int) []byte {
+	if len(pattern) == 0 {
+		return nil
+	}
+	n := v * ((len(pattern) + v - 1) / v)
+	return bytes.Repeat(pattern, (n+len(pattern)-1)/len(pattern))[:n]
+}
+
+// bmpStringZeroTerminated encodes s as a null-terminated UCS-2 big-endian string, the password format required by
+// the PKCS#12 KDF.
+func bmpStringZeroTerminated(s string) ([]byte, error) {
+	out := make([]byte, 0, 2*len(s)+2)
+	for _, r := range s {
+		if r > 0xFFFF {
+			return nil, errors.New("keystore: password contains characters outside the Basic Multilingual Plane")
+		}
+		out = append(out, byte(r>>8), byte(r))
+	}
+	return append(out, 0, 0), nil
+}
+
+// explicitTag0 wraps DER-encoded content in a context-specific [0] constructed tag.
+func explicitTag0(der []byte) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: der}
+}
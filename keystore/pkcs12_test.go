@@ -0,0 +1,129 @@
+package keystore
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/spbsoluble/go-pkcs12"
+)
+
+// newTestBundle returns a leaf certificate and key issued by a self-signed CA, with the CA as the chain.
+func newTestBundle(t *testing.T, key crypto.Signer) *Bundle {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	leafTmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "www.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTmpl, ca, key.Public(), caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, _ := x509.ParseCertificate(leafDER)
+
+	// Keyfactor includes the leaf in the chain, which the encoders must drop
+	return &Bundle{PrivateKey: key, Certificate: leaf, Chain: []*x509.Certificate{leaf, ca}}
+}
+
+func TestBundle_PKCS12(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	tests := []struct {
+		name           string
+		bundle         *Bundle
+		opts           *PKCS12Options
+		decodePassword string
+		wantErr        bool
+		wantDecodeErr  bool
+	}{
+		{name: "LegacyDefault", bundle: newTestBundle(t, ecKey), decodePassword: "changeit"},
+		{name: "ModernECDSA", bundle: newTestBundle(t, ecKey), opts: &PKCS12Options{Encryption: PKCS12Modern}, decodePassword: "changeit"},
+		{name: "ModernRSA", bundle: newTestBundle(t, rsaKey), opts: &PKCS12Options{Encryption: PKCS12Modern, Iterations: 10000}, decodePassword: "changeit"},
+		{name: "ModernWrongPassword", bundle: newTestBundle(t, ecKey), opts: &PKCS12Options{Encryption: PKCS12Modern}, decodePassword: "wrong", wantDecodeErr: true},
+		{name: "UnknownEncryption", bundle: newTestBundle(t, ecKey), opts: &PKCS12Options{Encryption: 99}, wantErr: true},
+		{name: "MissingKey", bundle: &Bundle{Certificate: newTestBundle(t, ecKey).Certificate}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pfx, err := tt.bundle.PKCS12("changeit", tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PKCS12() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			// DecodeChain assumes Keyfactor's bag ordering, so inspect the bags directly
+			blocks, err := pkcs12.ToPEM(pfx, tt.decodePassword)
+			if (err != nil) != tt.wantDecodeErr {
+				t.Fatalf("ToPEM() error = %v, wantDecodeErr %v", err, tt.wantDecodeErr)
+			}
+			if tt.wantDecodeErr {
+				return
+			}
+
+			var certs []*x509.Certificate
+			var key crypto.PrivateKey
+			keyIDs := make(map[string]string)
+			for _, block := range blocks {
+				switch block.Type {
+				case "CERTIFICATE":
+					cert, err := x509.ParseCertificate(block.Bytes)
+					if err != nil {
+						t.Fatal(err)
+					}
+					certs = append(certs, cert)
+					if cert.Equal(tt.bundle.Certificate) {
+						keyIDs["cert"] = block.Headers["localKeyId"]
+					}
+				case "PRIVATE KEY":
+					if key, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+						key, err = x509.ParseECPrivateKey(block.Bytes)
+					}
+					if err != nil {
+						t.Fatal(err)
+					}
+					keyIDs["key"] = block.Headers["localKeyId"]
+				}
+			}
+			if len(certs) != 2 {
+				t.Errorf("PKCS12() contains %d certificates, want leaf and CA", len(certs))
+			}
+			if keyIDs["cert"] == "" || keyIDs["cert"] != keyIDs["key"] {
+				t.Errorf("PKCS12() leaf localKeyId = %q, key localKeyId = %q", keyIDs["cert"], keyIDs["key"])
+			}
+			if key == nil || !key.(interface{ Equal(crypto.PrivateKey) bool }).Equal(tt.bundle.PrivateKey) {
+				t.Errorf("PKCS12() private key does not match")
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+// Package monitoring summarises the operational health of a Keyfactor Command instance in a single call, for feeding
+// health checks and alerting: orchestrators that have stopped sending heartbeats, orchestrator jobs that failed
+// recently, and certificate stores whose latest inventory failed.
+//
+//	report, err := monitoring.Check(client, &monitoring.Options{HeartbeatThreshold: 15 * time.Minute})
+//	if err != nil {
+//		return err
+//	}
+//	if !report.Healthy() {
+//		alert(strings.Join(report.Problems(), "\n"))
+//	}
+package monitoring
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Keyfactor/keyfactor-go-client/api"
+	"github.com/Keyfactor/keyfactor-go-client/query"
+)
+
+// Defaults used when the corresponding Options field is not set.
+const (
+	DefaultHeartbeatThreshold = 30 * time.Minute
+	DefaultWindow             = 24 * time.Hour
+)
+
+// agentStatusApproved is the Keyfactor status of an approved orchestrator. Orchestrators that are new or disapproved
+// are not expected to send heartbeats and are not checked.
+const agentStatusApproved = 2
+
+// Client is the subset of *api.Client used to gather a health report.
+type Client interface {
+	GetAgentList() ([]api.Agent, error)
+	ListJobHistory(q string) ([]api.JobHistory, error)
+	GetJobHistory(jobId string) ([]api.JobHistory, error)
+	ListCertificateStores(params *map[string]interface{}) (*[]api.GetCertificateStoreResponse, error)
+}
+
+// Options controls the checks made by Check.
+type Options struct {
+	// HeartbeatThreshold is how long an approved orchestrator may go without being seen before it is reported as
+	// stale. Defaults to DefaultHeartbeatThreshold.
+	HeartbeatThreshold time.Duration
+	// Window is how far back to look for failed orchestrator jobs. Defaults to DefaultWindow.
+	Window time.Duration
+	// Now is the time the report is made at. Defaults to the current time.
+	Now time.Time
+}
+
+// StaleAgent is an approved orchestrator that has not been seen within the heartbeat threshold.
+type StaleAgent struct {
+	Agent api.Agent
+	// LastSeen is zero if the orchestrator has never been seen.
+	LastSeen time.Time
+	// Since is how long ago the orchestrator was last seen.
+	Since time.Duration
+}
+
+// FailedInventory is a certificate store whose most recent inventory run failed.
+type FailedInventory struct {
+	Store api.GetCertificateStoreResponse
+	Run   api.JobHistory
+}
+
+// Report is the result of Check.
+type Report struct {
+	CheckedAt time.Time
+	// Agents is the number of approved orchestrators checked for heartbeats.
+	Agents      int
+	StaleAgents []StaleAgent
+	// JobsInError is the number of orchestrator job runs that failed within the window, and JobsInErrorByType breaks
+	// it down by job type.
+	JobsInError       int
+	JobsInErrorByType map[string]int
+	FailedJobs        []api.JobHistory
+	FailedInventories []FailedInventory
+}
+
+// Healthy reports whether the report found no problems.
+func (r *Report) Healthy() bool {
+	return len(r.StaleAgents) == 0 && r.JobsInError == 0 && len(r.FailedInventories) == 0
+}
+
+// Problems describes each problem in the report on its own line, suitable for an alert body.
+func (r *Report) Problems() []string {
+	var problems []string
+	for _, a := range r.StaleAgents {
+		if a.LastSeen.IsZero() {
+			problems = append(problems, fmt.Sprintf("orchestrator %s (%s) has never been seen", a.Agent.ClientMachine, a.Agent.AgentId))
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("orchestrator %s (%s) last seen %s ago", a.Agent.ClientMachine, a.Agent.AgentId, a.Since.Round(time.Second)))
+	}
+	if r.JobsInError > 0 {
+		types := make([]string, 0, len(r.JobsInErrorByType))
+		for jobType := range r.JobsInErrorByType {
+			types = append(types, jobType)
+		}
+		sort.Strings(types)
+		counts := make([]string, len(types))
+		for i, jobType := range types {
+			counts[i] = fmt.Sprintf("%s: %d", jobType, r.JobsInErrorByType[jobType])
+		}
+		problems = append(problems, fmt.Sprintf("%d orchestrator jobs failed (%s)", r.JobsInError, strings.Join(counts, ", ")))
+	}
+	for _, f := range r.FailedInventories {
+		problems = append(problems, fmt.Sprintf("inventory of certificate store %s on %s failed: %s", f.Store.StorePath, f.Store.ClientMachine, f.Run.Message))
+	}
+	return problems
+}
+
+// Check gathers a health report from c. Every check is made even if an earlier one fails; the report holds the
+// results of the checks that succeeded, and the error describes the ones that did not.
+func Check(c Client, opts *Options) (*Report, error) {
+	if c == nil {
+		return nil, errors.New("a Keyfactor client is required to check health")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	threshold := opts.HeartbeatThreshold
+	if threshold <= 0 {
+		threshold = DefaultHeartbeatThreshold
+	}
+	window := opts.Window
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	report := &Report{CheckedAt: now, JobsInErrorByType: map[string]int{}}
+	var errs []string
+
+	if err := checkAgents(c, report, threshold); err != nil {
+		errs = append(errs, fmt.Sprintf("orchestrators: %s", err))
+	}
+	if err := checkJobs(c, report, now.Add(-window)); err != nil {
+		errs = append(errs, fmt.Sprintf("orchestrator jobs: %s", err))
+	} else if err := checkInventories(c, report); err != nil {
+		errs = append(errs, fmt.Sprintf("certificate store inventories: %s", err))
+	}
+
+	if len(errs) > 0 {
+		return report, fmt.Errorf("unable to complete health check: %s", strings.Join(errs, "; "))
+	}
+	return report, nil
+}
+
+func checkAgents(c Client, report *Report, threshold time.Duration) error {
+	agents, err := c.GetAgentList()
+	if err != nil {
+		return err
+	}
+	for _, agent := range agents {
+		if agent.Status != agentStatusApproved {
+			continue
+		}
+		report.Agents++
+		if !agent.LastSeen.Valid() {
+			report.StaleAgents = append(report.StaleAgents, StaleAgent{Agent: agent})
+			continue
+		}
+		if since := report.CheckedAt.Sub(agent.LastSeen.Time); since > threshold {
+			report.StaleAgents = append(report.StaleAgents, StaleAgent{Agent: agent, LastSeen: agent.LastSeen.Time, Since: since})
+		}
+	}
+	sort.SliceStable(report.StaleAgents, func(i, j int) bool {
+		return report.StaleAgents[i].LastSeen.Before(report.StaleAgents[j].LastSeen)
+	})
+	return nil
+}
+
+func checkJobs(c Client, report *Report, since time.Time) error {
+	q, err := query.Field("Result").Eq(int(api.JobResultFailure)).And(query.Field("OperationStart").Ge(since)).Build()
+	if err != nil {
+		return err
+	}
+	failed, err := c.ListJobHistory(q)
+	if err != nil {
+		return err
+	}
+	report.FailedJobs = failed
+	report.JobsInError = len(failed)
+	for _, run := range failed {
+		report.JobsInErrorByType[run.JobType]++
+	}
+	return nil
+}
+
+// checkInventories reports the stores whose inventory job is among the failed jobs and has not succeeded since.
+func checkInventories(c Client, report *Report) error {
+	failedJobs := map[string]bool{}
+	for _, run := range report.FailedJobs {
+		failedJobs[strings.ToLower(run.JobId)] = true
+	}
+	if len(failedJobs) == 0 {
+		return nil
+	}
+
+	stores, err := c.ListCertificateStores(nil)
+	if err != nil {
+		return err
+	}
+	if stores == nil {
+		return nil
+	}
+	for _, store := range *stores {
+		jobId := store.CertStoreInventoryJobId
+		if jobId == "" || !failedJobs[strings.ToLower(jobId)] {
+			continue
+		}
+		history, err := c.GetJobHistory(jobId)
+		if err != nil {
+			return err
+		}
+		var latest *api.JobHistory
+		for i := range history {
+			if latest == nil || history[i].JobHistoryId > latest.JobHistoryId {
+				latest = &history[i]
+			}
+		}
+		if latest != nil && latest.Result == api.JobResultFailure {
+			report.FailedInventories = append(report.FailedInventories, FailedInventory{Store: store, Run: *latest})
+		}
+	}
+	return nil
+}
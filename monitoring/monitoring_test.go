@@ -0,0 +1,114 @@
+package monitoring
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Keyfactor/keyfactor-go-client/api"
+)
+
+type fakeClient struct {
+	agents    []api.Agent
+	agentsErr error
+	failed    []api.JobHistory
+	history   map[string][]api.JobHistory
+	stores    []api.GetCertificateStoreResponse
+	query     string
+}
+
+func (f *fakeClient) GetAgentList() ([]api.Agent, error) { return f.agents, f.agentsErr }
+
+func (f *fakeClient) ListJobHistory(q string) ([]api.JobHistory, error) {
+	f.query = q
+	return f.failed, nil
+}
+
+func (f *fakeClient) GetJobHistory(jobId string) ([]api.JobHistory, error) {
+	return f.history[strings.ToLower(jobId)], nil
+}
+
+func (f *fakeClient) ListCertificateStores(*map[string]interface{}) (*[]api.GetCertificateStoreResponse, error) {
+	return &f.stores, nil
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	c := &fakeClient{
+		agents: []api.Agent{
+			{AgentId: "a1", ClientMachine: "orch1", Status: agentStatusApproved, LastSeen: api.NewTimestamp(time.Date(2024, 5, 1, 11, 55, 0, 0, time.UTC))},
+			{AgentId: "a2", ClientMachine: "orch2", Status: agentStatusApproved, LastSeen: api.NewTimestamp(time.Date(2024, 5, 1, 9, 0, 0, 123000000, time.UTC))},
+			{AgentId: "a3", ClientMachine: "orch3", Status: agentStatusApproved},
+			{AgentId: "a4", ClientMachine: "orch4", Status: 3, LastSeen: api.NewTimestamp(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))},
+		},
+		failed: []api.JobHistory{
+			{JobHistoryId: 10, JobId: "inv-1", JobType: "Inventory", Result: api.JobResultFailure, Message: "access denied"},
+			{JobHistoryId: 11, JobId: "inv-2", JobType: "Inventory", Result: api.JobResultFailure},
+			{JobHistoryId: 12, JobId: "add-1", JobType: "Management", Result: api.JobResultFailure},
+		},
+		history: map[string][]api.JobHistory{
+			"inv-1": {{JobHistoryId: 10, JobId: "inv-1", Result: api.JobResultFailure, Message: "access denied"}},
+			"inv-2": {
+				{JobHistoryId: 11, JobId: "inv-2", Result: api.JobResultFailure},
+				{JobHistoryId: 15, JobId: "inv-2", Result: api.JobResultSuccess},
+			},
+		},
+		stores: []api.GetCertificateStoreResponse{
+			{Id: "s1", ClientMachine: "iis01", StorePath: "My", CertStoreInventoryJobId: "INV-1"},
+			{Id: "s2", ClientMachine: "iis02", StorePath: "My", CertStoreInventoryJobId: "inv-2"},
+			{Id: "s3", ClientMachine: "iis03", StorePath: "My", CertStoreInventoryJobId: "inv-3"},
+		},
+	}
+
+	report, err := Check(c, &Options{Now: now})
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if report.Healthy() {
+		t.Errorf("Healthy() = true, want false")
+	}
+	if report.Agents != 3 || len(report.StaleAgents) != 2 {
+		t.Fatalf("Check() agents = %d, stale = %+v", report.Agents, report.StaleAgents)
+	}
+	if stale := report.StaleAgents[0]; stale.Agent.AgentId != "a3" || !stale.LastSeen.IsZero() {
+		t.Errorf("StaleAgents[0] = %+v, want never-seen orch3 first", stale)
+	}
+	if stale := report.StaleAgents[1]; stale.Agent.AgentId != "a2" || stale.Since < 2*time.Hour {
+		t.Errorf("StaleAgents[1] = %+v, want orch2", stale)
+	}
+	if !strings.Contains(c.query, "Result -eq 3") || !strings.Contains(c.query, `OperationStart -ge "2024-04-30T12:00:00Z"`) {
+		t.Errorf("job history query = %q", c.query)
+	}
+	if report.JobsInError != 3 || report.JobsInErrorByType["Inventory"] != 2 || report.JobsInErrorByType["Management"] != 1 {
+		t.Errorf("Check() jobs in error = %d %v", report.JobsInError, report.JobsInErrorByType)
+	}
+	if len(report.FailedInventories) != 1 || report.FailedInventories[0].Store.Id != "s1" {
+		t.Errorf("Check() failed inventories = %+v, want only s1", report.FailedInventories)
+	}
+
+	problems := strings.Join(report.Problems(), "\n")
+	for _, want := range []string{
+		"orchestrator orch3 (a3) has never been seen",
+		"orchestrator orch2 (a2) last seen 3h0m0s ago",
+		"3 orchestrator jobs failed (Inventory: 2, Management: 1)",
+		"inventory of certificate store My on iis01 failed: access denied",
+	} {
+		if !strings.Contains(problems, want) {
+			t.Errorf("Problems() missing %q:\n%s", want, problems)
+		}
+	}
+
+	c.agentsErr = errors.New("boom")
+	report, err = Check(c, &Options{Now: now})
+	if err == nil || !strings.Contains(err.Error(), "orchestrators: boom") {
+		t.Errorf("Check() error = %v, want orchestrator error", err)
+	}
+	if report == nil || report.JobsInError != 3 {
+		t.Errorf("Check() did not run the remaining checks after an error: %+v", report)
+	}
+
+	if _, err := Check(nil, nil); err == nil {
+		t.Errorf("Check(nil) succeeded, want error")
+	}
+}
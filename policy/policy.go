@@ -0,0 +1,235 @@
+// Package policy checks certificates against a compliance profile: a maximum validity period, the allowed key types
+// and sizes, the approved issuing CAs, and the metadata every certificate must carry. Certificates are checked as
+// returned by a certificate search or a certificate store inventory, and each broken constraint is reported as a
+// Violation:
+//
+//	profile := &policy.Profile{
+//		MaxValidity:      398 * 24 * time.Hour,
+//		AllowedKeys:      []policy.KeyConstraint{{Type: enums.KeyTypeRSA, MinSize: 2048}, {Type: enums.KeyTypeECC, MinSize: 256}},
+//		RequiredMetadata: []string{"Owner"},
+//	}
+//	certs, err := client.ListAllCertificates("", &api.SearchCertificatesOptions{IncludeMetadata: true}, nil)
+//	if err != nil {
+//		return err
+//	}
+//	for _, v := range profile.CheckCertificates(certs) {
+//		fmt.Println(v)
+//	}
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Keyfactor/keyfactor-go-client/api"
+	"github.com/Keyfactor/keyfactor-go-client/enums"
+)
+
+// Rule identifies the constraint of a Profile that a Violation breaks.
+type Rule string
+
+const (
+	RuleMaxValidity      Rule = "max-validity"
+	RuleKeyType          Rule = "key-type"
+	RuleKeySize          Rule = "key-size"
+	RuleApprovedCA       Rule = "approved-ca"
+	RuleRequiredMetadata Rule = "required-metadata"
+)
+
+// Profile is a set of constraints certificates must meet. A constraint that is not set is not checked.
+type Profile struct {
+	// Name identifies the profile in violations.
+	Name string
+	// MaxValidity is the longest a certificate may be valid for, from NotBefore to NotAfter.
+	MaxValidity time.Duration
+	// AllowedKeys lists the key types certificates may have, each with its smallest allowed size.
+	AllowedKeys []KeyConstraint
+	// ApprovedCAs lists the CAs that may issue certificates, each either the logical name Keyfactor knows the CA by
+	// or its distinguished name. Names are compared ignoring case and the spacing between the parts of a DN.
+	ApprovedCAs []string
+	// RequiredMetadata lists the metadata fields every certificate must have a value for. Search certificates with
+	// metadata included for it to be checked.
+	RequiredMetadata []string
+}
+
+// KeyConstraint allows keys of a type.
+type KeyConstraint struct {
+	Type enums.KeyType
+	// MinSize is the smallest key size allowed, in bits. Zero allows any size.
+	MinSize int
+}
+
+// Violation is a constraint of a Profile that a certificate breaks.
+type Violation struct {
+	Profile string
+	Rule    Rule
+	// CertificateId is the Keyfactor ID of the certificate, if known.
+	CertificateId int
+	Thumbprint    string
+	// Subject is the distinguished name of the certificate.
+	Subject string
+	// StoreId and Alias locate the certificate when it was checked as part of a store inventory.
+	StoreId string
+	Alias   string
+	Message string
+}
+
+// String returns a one-line description of the violation.
+func (v Violation) String() string {
+	where := v.Thumbprint
+	if v.Subject != "" {
+		where = fmt.Sprintf("%s (%s)", v.Subject, v.Thumbprint)
+	}
+	if v.StoreId != "" {
+		where = fmt.Sprintf("%s in store %s as %q", where, v.StoreId, v.Alias)
+	}
+	return fmt.Sprintf("%s: %s: %s", v.Rule, where, v.Message)
+}
+
+// CheckCertificate returns the violations of the profile by a certificate returned by a certificate search.
+func (p *Profile) CheckCertificate(cert *api.GetCertificateResponse) []Violation {
+	var violations []Violation
+	add := func(rule Rule, format string, args ...interface{}) {
+		violations = append(violations, Violation{
+			Profile:       p.Name,
+			Rule:          rule,
+			CertificateId: cert.Id,
+			Thumbprint:    cert.Thumbprint,
+			Subject:       cert.IssuedDN,
+			Message:       fmt.Sprintf(format, args...),
+		})
+	}
+
+	if msg := p.checkValidity(cert.NotBefore, cert.NotAfter); msg != "" {
+		add(RuleMaxValidity, "%s", msg)
+	}
+	if len(p.AllowedKeys) > 0 {
+		keyType := enums.KeyType(cert.KeyType)
+		allowed := false
+		minSize := 0
+		for _, k := range p.AllowedKeys {
+			if k.Type == keyType {
+				allowed = true
+				if minSize == 0 || k.MinSize < minSize {
+					minSize = k.MinSize
+				}
+			}
+		}
+		switch {
+		case !allowed:
+			add(RuleKeyType, "key type %s is not allowed", keyType)
+		case cert.KeySizeInBits < minSize:
+			add(RuleKeySize, "%s key of %d bits is smaller than %d bits", keyType, cert.KeySizeInBits, minSize)
+		}
+	}
+	if len(p.ApprovedCAs) > 0 && !p.approvedCA(cert.CertificateAuthorityName) && !p.approvedCA(cert.IssuerDN) {
+		add(RuleApprovedCA, "issuer %s is not an approved CA", issuerName(cert.CertificateAuthorityName, cert.IssuerDN))
+	}
+	if len(p.RequiredMetadata) > 0 {
+		metadata := cert.MetadataValues()
+		for _, field := range p.RequiredMetadata {
+			if strings.TrimSpace(metadata[field]) == "" {
+				add(RuleRequiredMetadata, "metadata field %s is not set", field)
+			}
+		}
+	}
+	return violations
+}
+
+// CheckCertificates returns the violations of the profile by each of the certificates, in the order of the
+// certificates.
+func (p *Profile) CheckCertificates(certs []api.GetCertificateResponse) []Violation {
+	var violations []Violation
+	for i := range certs {
+		violations = append(violations, p.CheckCertificate(&certs[i])...)
+	}
+	return violations
+}
+
+// CheckInventory returns the violations of the profile by the certificates in the inventory of a certificate store,
+// ordered by alias. Inventories do not report the key or the metadata of a certificate, so only the validity and the
+// issuer are checked; search for the certificates by ID to check the rest.
+func (p *Profile) CheckInventory(storeId string, inventory []api.CertStoreInventory) []Violation {
+	items := make([]api.CertStoreInventory, len(inventory))
+	copy(items, inventory)
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+
+	var violations []Violation
+	for _, item := range items {
+		for _, cert := range item.Certificates {
+			add := func(rule Rule, msg string) {
+				violations = append(violations, Violation{
+					Profile:       p.Name,
+					Rule:          rule,
+					CertificateId: cert.Id,
+					Thumbprint:    cert.Thumbprint,
+					Subject:       cert.IssuedDN,
+					StoreId:       storeId,
+					Alias:         item.Name,
+					Message:       msg,
+				})
+			}
+			if msg := p.checkValidity(cert.NotBefore, cert.NotAfter); msg != "" {
+				add(RuleMaxValidity, msg)
+			}
+			if len(p.ApprovedCAs) > 0 && !p.approvedCA(cert.IssuerDN) {
+				add(RuleApprovedCA, fmt.Sprintf("issuer %s is not an approved CA", cert.IssuerDN))
+			}
+		}
+	}
+	return violations
+}
+
+// checkValidity returns why the validity of a certificate breaks MaxValidity, or "" if it does not. A certificate
+// whose validity is not known is not reported.
+func (p *Profile) checkValidity(notBefore, notAfter api.Timestamp) string {
+	if p.MaxValidity <= 0 || !notBefore.Valid() || !notAfter.Valid() {
+		return ""
+	}
+	validity := notAfter.Sub(notBefore.Time)
+	if validity <= p.MaxValidity {
+		return ""
+	}
+	return fmt.Sprintf("valid for %s, longer than %s", formatDays(validity), formatDays(p.MaxValidity))
+}
+
+func (p *Profile) approvedCA(name string) bool {
+	if name == "" {
+		return false
+	}
+	name = normalizeDN(name)
+	for _, ca := range p.ApprovedCAs {
+		if strings.EqualFold(normalizeDN(ca), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeDN removes the spacing between the parts of a distinguished name, which Keyfactor and CAs format
+// differently.
+func normalizeDN(dn string) string {
+	parts := strings.Split(dn, ",")
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return strings.Join(parts, ",")
+}
+
+func issuerName(caName, issuerDN string) string {
+	if caName != "" {
+		return caName
+	}
+	return issuerDN
+}
+
+// formatDays formats a duration of whole days as days, and any other as a time.Duration.
+func formatDays(d time.Duration) string {
+	const day = 24 * time.Hour
+	if d%day == 0 {
+		return fmt.Sprintf("%d days", d/day)
+	}
+	return d.String()
+}
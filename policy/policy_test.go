@@ -0,0 +1,96 @@
+package policy
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Keyfactor/keyfactor-go-client/api"
+	"github.com/Keyfactor/keyfactor-go-client/enums"
+)
+
+var testProfile = &Profile{
+	Name:             "tls",
+	MaxValidity:      398 * 24 * time.Hour,
+	AllowedKeys:      []KeyConstraint{{Type: enums.KeyTypeRSA, MinSize: 2048}, {Type: enums.KeyTypeECC, MinSize: 256}},
+	ApprovedCAs:      []string{"CN=Example Issuing CA 1, O=Example", "ExampleCA2"},
+	RequiredMetadata: []string{"Owner"},
+}
+
+func validity(days int) (api.Timestamp, api.Timestamp) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return api.NewTimestamp(start), api.NewTimestamp(start.Add(time.Duration(days) * 24 * time.Hour))
+}
+
+func TestProfile_CheckCertificate(t *testing.T) {
+	tests := []struct {
+		name      string
+		cert      api.GetCertificateResponse
+		wantRules []Rule
+	}{
+		{
+			name: "Compliant",
+			cert: api.GetCertificateResponse{
+				KeyType: int(enums.KeyTypeRSA), KeySizeInBits: 3072, IssuerDN: "CN=Example Issuing CA 1,O=Example",
+				Metadata: map[string]interface{}{"Owner": "pki"},
+			},
+		},
+		{
+			name: "ApprovedByCAName",
+			cert: api.GetCertificateResponse{
+				KeyType: int(enums.KeyTypeECC), KeySizeInBits: 384, CertificateAuthorityName: "exampleca2", IssuerDN: "CN=Other",
+				Metadata: map[string]interface{}{"Owner": "pki"},
+			},
+		},
+		{
+			name: "Everything",
+			cert: api.GetCertificateResponse{
+				KeyType: int(enums.KeyTypeRSA), KeySizeInBits: 1024, IssuerDN: "CN=Rogue CA",
+				Metadata: map[string]interface{}{"Owner": " "},
+			},
+			wantRules: []Rule{RuleMaxValidity, RuleKeySize, RuleApprovedCA, RuleRequiredMetadata},
+		},
+		{
+			name: "KeyType",
+			cert: api.GetCertificateResponse{
+				KeyType: int(enums.KeyTypeDSA), KeySizeInBits: 2048, IssuerDN: "CN=Example Issuing CA 1, O=Example",
+				Metadata: map[string]interface{}{"Owner": "pki"},
+			},
+			wantRules: []Rule{RuleKeyType},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cert.NotBefore, tt.cert.NotAfter = validity(397)
+			if tt.name == "Everything" {
+				tt.cert.NotBefore, tt.cert.NotAfter = validity(825)
+			}
+			var got []Rule
+			for _, v := range testProfile.CheckCertificate(&tt.cert) {
+				if v.Profile != "tls" {
+					t.Errorf("violation profile = %q", v.Profile)
+				}
+				got = append(got, v.Rule)
+			}
+			if !reflect.DeepEqual(got, tt.wantRules) {
+				t.Errorf("CheckCertificate() rules = %v, want %v", got, tt.wantRules)
+			}
+		})
+	}
+}
+
+func TestProfile_CheckInventory(t *testing.T) {
+	notBefore, notAfter := validity(825)
+	inventory := []api.CertStoreInventory{
+		{Name: "web", Certificates: []api.InventoriedCertificate{{Thumbprint: "BBBB", IssuerDN: "CN=Rogue CA", NotBefore: notBefore, NotAfter: notAfter}}},
+		{Name: "api", Certificates: []api.InventoriedCertificate{{Thumbprint: "AAAA", IssuerDN: "CN=Example Issuing CA 1,O=Example"}}},
+	}
+	got := testProfile.CheckInventory("5f1e0a2c-0001-4000-8000-000000000001", inventory)
+	if len(got) != 2 || got[0].Rule != RuleMaxValidity || got[1].Rule != RuleApprovedCA || got[0].Alias != "web" {
+		t.Fatalf("CheckInventory() = %+v", got)
+	}
+	want := `max-validity: BBBB in store 5f1e0a2c-0001-4000-8000-000000000001 as "web": valid for 825 days, longer than 398 days`
+	if s := got[0].String(); s != want {
+		t.Errorf("Violation.String() = %q, want %q", s, want)
+	}
+}
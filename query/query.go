@@ -0,0 +1,287 @@
+// Package query provides a fluent builder for the Keyfactor Command query language used by the certificate, store,
+// and audit log search endpoints (the pq.queryString family of query parameters). Values are quoted and escaped by
+// the builder, so callers never need to hand-assemble query strings:
+//
+//	q := query.Field("Thumbprint").Eq(thumbprint).And(query.Field("CertState").Eq(enums.CertificateStateActive))
+//	qStr, err := q.Build() // Thumbprint -eq "ABC123..." AND CertState -eq 1
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Keyfactor query language comparison operators.
+const (
+	OpEquals        = "-eq"
+	OpNotEquals     = "-ne"
+	OpContains      = "-contains"
+	OpNotContains   = "-notcontains"
+	OpStartsWith    = "-startswith"
+	OpEndsWith      = "-endswith"
+	OpGreaterThan   = "-gt"
+	OpGreaterEquals = "-ge"
+	OpLessThan      = "-lt"
+	OpLessEquals    = "-le"
+	OpIsNull        = "-isnull"
+	OpIsNotNull     = "-isnotnull"
+)
+
+const (
+	joinAnd = "AND"
+	joinOr  = "OR"
+)
+
+// DateFormat is the layout used when a time.Time is passed as a query value.
+const DateFormat = "2006-01-02T15:04:05Z"
+
+var fieldNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// Raw is a query value that is emitted verbatim, without quoting or escaping. It is intended for Keyfactor query
+// tokens such as %TODAY% and must never be built from untrusted input.
+type Raw string
+
+// Today returns the Keyfactor %TODAY% token offset by the given number of days, e.g. Today(30) yields %TODAY+30%.
+func Today(offsetDays int) Raw {
+	switch {
+	case offsetDays > 0:
+		return Raw(fmt.Sprintf("%%TODAY+%d%%", offsetDays))
+	case offsetDays < 0:
+		return Raw(fmt.Sprintf("%%TODAY%d%%", offsetDays))
+	}
+	return Raw("%TODAY%")
+}
+
+// FieldRef names a queryable field. Use Field to create one, then call one of the comparison methods to produce a
+// Condition.
+type FieldRef struct {
+	name string
+}
+
+// Field returns a reference to the named query field, e.g. Field("IssuedCN") or Field("Metadata.Department").
+func Field(name string) FieldRef {
+	return FieldRef{name: name}
+}
+
+// Eq matches records whose field equals v.
+func (f FieldRef) Eq(v interface{}) *Condition { return f.compare(OpEquals, v) }
+
+// Ne matches records whose field does not equal v.
+func (f FieldRef) Ne(v interface{}) *Condition { return f.compare(OpNotEquals, v) }
+
+// Contains matches records whose field contains v.
+func (f FieldRef) Contains(v interface{}) *Condition { return f.compare(OpContains, v) }
+
+// NotContains matches records whose field does not contain v.
+func (f FieldRef) NotContains(v interface{}) *Condition { return f.compare(OpNotContains, v) }
+
+// StartsWith matches records whose field starts with v.
+func (f FieldRef) StartsWith(v interface{}) *Condition { return f.compare(OpStartsWith, v) }
+
+// EndsWith matches records whose field ends with v.
+func (f FieldRef) EndsWith(v interface{}) *Condition { return f.compare(OpEndsWith, v) }
+
+// Gt matches records whose field is greater than v.
+func (f FieldRef) Gt(v interface{}) *Condition { return f.compare(OpGreaterThan, v) }
+
+// Ge matches records whose field is greater than or equal to v.
+func (f FieldRef) Ge(v interface{}) *Condition { return f.compare(OpGreaterEquals, v) }
+
+// Lt matches records whose field is less than v.
+func (f FieldRef) Lt(v interface{}) *Condition { return f.compare(OpLessThan, v) }
+
+// Le matches records whose field is less than or equal to v.
+func (f FieldRef) Le(v interface{}) *Condition { return f.compare(OpLessEquals, v) }
+
+// IsNull matches records where the field has no value.
+func (f FieldRef) IsNull() *Condition { return f.unary(OpIsNull) }
+
+// IsNotNull matches records where the field has a value.
+func (f FieldRef) IsNotNull() *Condition { return f.unary(OpIsNotNull) }
+
+// In matches records whose field equals any of the supplied values. It is shorthand for a parenthesised chain of
+// Eq conditions joined with OR.
+func (f FieldRef) In(values ...interface{}) *Condition {
+	if len(values) == 0 {
+		return &Condition{err: fmt.Errorf("query: In() on field %q requires at least one value", f.name)}
+	}
+	conds := make([]*Condition, 0, len(values))
+	for _, v := range values {
+		conds = append(conds, f.Eq(v))
+	}
+	return Or(conds...)
+}
+
+func (f FieldRef) compare(op string, v interface{}) *Condition {
+	c := &Condition{field: f.name, operator: op}
+	if err := validateField(f.name); err != nil {
+		c.err = err
+		return c
+	}
+	value, err := formatValue(v)
+	if err != nil {
+		c.err = fmt.Errorf("query: field %q: %w", f.name, err)
+		return c
+	}
+	c.value = value
+	return c
+}
+
+func (f FieldRef) unary(op string) *Condition {
+	c := &Condition{field: f.name, operator: op}
+	c.err = validateField(f.name)
+	return c
+}
+
+// Condition is a single comparison or a group of conditions joined with AND/OR. Conditions are immutable; And and Or
+// return new values.
+type Condition struct {
+	field    string
+	operator string
+	value    string
+
+	join     string
+	children []*Condition
+
+	err error
+}
+
+// And returns a condition matching records that satisfy c and every one of others.
+func (c *Condition) And(others ...*Condition) *Condition {
+	return And(append([]*Condition{c}, others...)...)
+}
+
+// Or returns a condition matching records that satisfy c or any one of others.
+func (c *Condition) Or(others ...*Condition) *Condition {
+	return Or(append([]*Condition{c}, others...)...)
+}
+
+// And joins the supplied conditions with AND. Nil conditions are ignored.
+func And(conds ...*Condition) *Condition {
+	return group(joinAnd, conds)
+}
+
+// Or joins the supplied conditions with OR. Nil conditions are ignored.
+func Or(conds ...*Condition) *Condition {
+	return group(joinOr, conds)
+}
+
+func group(join string, conds []*Condition) *Condition {
+	g := &Condition{join: join}
+	for _, c := range conds {
+		if c == nil {
+			continue
+		}
+		if c.isGroup() && c.join == join {
+			// Flatten (a AND b) AND c into a AND b AND c
+			g.children = append(g.children, c.children...)
+			continue
+		}
+		g.children = append(g.children, c)
+	}
+	if len(g.children) == 1 {
+		return g.children[0]
+	}
+	return g
+}
+
+func (c *Condition) isGroup() bool {
+	return c.join != ""
+}
+
+// Err returns the first error recorded while building the condition, for example an invalid field name or an
+// unsupported value type.
+func (c *Condition) Err() error {
+	if c == nil {
+		return nil
+	}
+	if c.err != nil {
+		return c.err
+	}
+	for _, child := range c.children {
+		if err := child.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Build renders the condition as a Keyfactor query string. An error is returned if any part of the condition is
+// invalid.
+func (c *Condition) Build() (string, error) {
+	if err := c.Err(); err != nil {
+		return "", err
+	}
+	return c.String(), nil
+}
+
+// String renders the condition as a Keyfactor query string. Use Build to also surface construction errors.
+func (c *Condition) String() string {
+	if c == nil {
+		return ""
+	}
+	if !c.isGroup() {
+		if c.operator == OpIsNull || c.operator == OpIsNotNull {
+			return fmt.Sprintf("%s %s", c.field, c.operator)
+		}
+		return fmt.Sprintf("%s %s %s", c.field, c.operator, c.value)
+	}
+	parts := make([]string, 0, len(c.children))
+	for _, child := range c.children {
+		s := child.String()
+		if child.isGroup() && len(child.children) > 1 {
+			s = "(" + s + ")"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " "+c.join+" ")
+}
+
+// Escape quotes s for use as a string value in a Keyfactor query. Backslashes and double quotes are escaped with a
+// backslash.
+func Escape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+func validateField(name string) error {
+	if !fieldNamePattern.MatchString(name) {
+		return fmt.Errorf("query: invalid field name %q", name)
+	}
+	return nil
+}
+
+func formatValue(v interface{}) (string, error) {
+	switch val := v.(type) {
+	case Raw:
+		return string(val), nil
+	case string:
+		return Escape(val), nil
+	case bool:
+		return fmt.Sprintf("%t", val), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", val), nil
+	case float32, float64:
+		return fmt.Sprintf("%v", val), nil
+	case time.Time:
+		return Escape(val.UTC().Format(DateFormat)), nil
+	case nil:
+		return "", fmt.Errorf("nil value, use IsNull() instead")
+	}
+	// Enum types such as enums.CertificateState are sent as their numeric code, even though they implement
+	// fmt.Stringer.
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprintf("%d", rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprintf("%d", rv.Uint()), nil
+	}
+	if val, ok := v.(fmt.Stringer); ok {
+		return Escape(val.String()), nil
+	}
+	return "", fmt.Errorf("unsupported value type %T", v)
+}
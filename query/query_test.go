@@ -0,0 +1,127 @@
+package query
+
+import (
+	"testing"
+	"time"
+)
+
+// testState is an enum-like type whose String method must not be used in queries.
+type testState int
+
+func (s testState) String() string { return "Revoked" }
+
+func TestCondition_Build(t *testing.T) {
+	tests := []struct {
+		name    string
+		cond    *Condition
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "SingleString",
+			cond: Field("Thumbprint").Eq("ABC123"),
+			want: `Thumbprint -eq "ABC123"`,
+		},
+		{
+			name: "And",
+			cond: Field("Thumbprint").Eq("ABC123").And(Field("CertState").Eq(1)),
+			want: `Thumbprint -eq "ABC123" AND CertState -eq 1`,
+		},
+		{
+			name: "NestedGroups",
+			cond: Field("CertState").Eq(1).And(Field("IssuedCN").Eq("a.example.com").Or(Field("IssuedCN").Eq("b.example.com"))),
+			want: `CertState -eq 1 AND (IssuedCN -eq "a.example.com" OR IssuedCN -eq "b.example.com")`,
+		},
+		{
+			name: "FlattensSameJoin",
+			cond: Field("A").Eq(1).And(Field("B").Eq(2)).And(Field("C").Eq(3)),
+			want: `A -eq 1 AND B -eq 2 AND C -eq 3`,
+		},
+		{
+			name: "In",
+			cond: Field("ContainerId").In(1, 2).And(Field("Approved").Eq(true)),
+			want: `(ContainerId -eq 1 OR ContainerId -eq 2) AND Approved -eq true`,
+		},
+		{
+			name: "EscapesQuotesAndBackslashes",
+			cond: Field("IssuedDN").Contains(`O="Acme\Inc"`),
+			want: `IssuedDN -contains "O=\"Acme\\Inc\""`,
+		},
+		{
+			name: "InjectionAttemptStaysQuoted",
+			cond: Field("IssuedCN").Eq(`x" OR CertState -eq "1`),
+			want: `IssuedCN -eq "x\" OR CertState -eq \"1"`,
+		},
+		{
+			name: "Time",
+			cond: Field("NotAfter").Le(time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)),
+			want: `NotAfter -le "2023-04-01T12:00:00Z"`,
+		},
+		{
+			name: "NamedIntIsNumeric",
+			cond: Field("CertState").Eq(testState(2)),
+			want: `CertState -eq 2`,
+		},
+		{
+			name: "TodayToken",
+			cond: Field("NotAfter").Le(Today(30)).And(Field("NotAfter").Ge(Today(0))),
+			want: `NotAfter -le %TODAY+30% AND NotAfter -ge %TODAY%`,
+		},
+		{
+			name: "Unary",
+			cond: Field("RevocationEffDate").IsNull(),
+			want: `RevocationEffDate -isnull`,
+		},
+		{
+			name:    "InvalidField",
+			cond:    Field(`IssuedCN -eq "x" OR A`).Eq("y"),
+			wantErr: true,
+		},
+		{
+			name:    "UnsupportedValue",
+			cond:    Field("IssuedCN").Eq([]string{"x"}),
+			wantErr: true,
+		},
+		{
+			name:    "ErrorInNestedCondition",
+			cond:    Field("A").Eq(1).And(Field("B").Eq(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "EmptyIn",
+			cond:    Field("A").In(),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cond.Build()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Build() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Build() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToday(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset int
+		want   Raw
+	}{
+		{name: "Zero", offset: 0, want: "%TODAY%"},
+		{name: "Positive", offset: 7, want: "%TODAY+7%"},
+		{name: "Negative", offset: -7, want: "%TODAY-7%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Today(tt.offset); got != tt.want {
+				t.Errorf("Today() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,359 @@
+// Package revocation checks the live revocation status of certificates known to Keyfactor Command with the OCSP
+// responders and CRLs of their issuing CAs, and flags certificates whose status in Command disagrees with it, such as
+// a certificate revoked at the CA but still active in Command because a CA synchronization failed:
+//
+//	certs, err := client.ListAllCertificates(`CertState -eq 1`, nil, nil)
+//	if err != nil {
+//		return err
+//	}
+//	checker := &revocation.Checker{}
+//	for _, r := range checker.Check(ctx, certs) {
+//		if r.Mismatch {
+//			log.Printf("certificate %d: Command says revoked=%t, CA says %s", r.CertificateId, r.RecordedRevoked, r.Status)
+//		}
+//	}
+package revocation
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/Keyfactor/keyfactor-go-client/api"
+	"github.com/Keyfactor/keyfactor-go-client/enums"
+)
+
+// maxResponseSize limits the size of the OCSP responses, CRLs, and issuer certificates that are downloaded.
+const maxResponseSize = 64 << 20
+
+// Status is the revocation status of a certificate as reported by its CA.
+type Status int
+
+const (
+	StatusUnknown Status = iota
+	StatusGood
+	StatusRevoked
+)
+
+// String returns the name of the status.
+func (s Status) String() string {
+	switch s {
+	case StatusGood:
+		return "good"
+	case StatusRevoked:
+		return "revoked"
+	default:
+		return "unknown"
+	}
+}
+
+// Source is where a Status was read from.
+type Source string
+
+const (
+	SourceOCSP Source = "ocsp"
+	SourceCRL  Source = "crl"
+)
+
+// Result is the revocation status of a certificate.
+type Result struct {
+	CertificateId int
+	Thumbprint    string
+	// RecordedRevoked is whether Keyfactor Command records the certificate as revoked.
+	RecordedRevoked bool
+	// Status is the live status of the certificate, read from Source. It is StatusUnknown if it could not be
+	// determined, in which case Err says why.
+	Status Status
+	Source Source
+	// RevokedAt and Reason are the time and RFC 5280 reason code of the revocation, if the certificate is revoked.
+	RevokedAt time.Time
+	Reason    int
+	// Mismatch reports that the live status is known and disagrees with RecordedRevoked.
+	Mismatch bool
+	Err      error
+}
+
+// Checker checks the revocation status of certificates. The zero value is ready to use. CRLs and issuer certificates
+// are cached for the life of the Checker; CRLs are downloaded again once they pass their next update time.
+type Checker struct {
+	// HTTPClient is used to reach OCSP responders and download CRLs and issuer certificates. Defaults to a client
+	// with a 30 second timeout.
+	HTTPClient *http.Client
+	// Issuers are the CA certificates that issued the certificates to check. The issuer of a certificate not found
+	// here is downloaded from the CA issuers URL of the certificate, if it has one.
+	Issuers []*x509.Certificate
+	// DisableOCSP checks only CRLs.
+	DisableOCSP bool
+	// Now is the time CRL validity is checked at. Defaults to the current time.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	crls    map[string]*pkix.CertificateList
+	issuers map[string]*x509.Certificate
+}
+
+// Check returns the revocation status of each of the certificates, in the order of the certificates. Certificates
+// must have been retrieved with their content, which certificate searches return by default.
+func (c *Checker) Check(ctx context.Context, certs []api.GetCertificateResponse) []Result {
+	results := make([]Result, len(certs))
+	for i := range certs {
+		results[i] = c.CheckCertificate(ctx, &certs[i])
+	}
+	return results
+}
+
+// CheckCertificate returns the revocation status of a certificate. OCSP is tried first, as it is the more current,
+// and the CRLs of the certificate are used if the certificate names no OCSP responder or the responder fails.
+func (c *Checker) CheckCertificate(ctx context.Context, cert *api.GetCertificateResponse) Result {
+	result := Result{
+		CertificateId:   cert.Id,
+		Thumbprint:      cert.Thumbprint,
+		RecordedRevoked: enums.CertificateState(cert.CertState) == enums.CertificateStateRevoked,
+	}
+	leaf, err := parseCertificate(cert.ContentBytes)
+	if err != nil {
+		result.Err = fmt.Errorf("unable to parse certificate %d: %w", cert.Id, err)
+		return result
+	}
+	issuer, issuerErr := c.issuer(ctx, leaf)
+
+	var errs []string
+	if !c.DisableOCSP && len(leaf.OCSPServer) > 0 {
+		if issuer == nil {
+			errs = append(errs, fmt.Sprintf("ocsp: %s", issuerErr))
+		} else if err := c.checkOCSP(ctx, leaf, issuer, &result); err != nil {
+			errs = append(errs, fmt.Sprintf("ocsp: %s", err))
+		} else {
+			return finish(result)
+		}
+	}
+	if len(leaf.CRLDistributionPoints) > 0 {
+		if err := c.checkCRL(ctx, leaf, issuer, &result); err != nil {
+			errs = append(errs, fmt.Sprintf("crl: %s", err))
+		} else {
+			return finish(result)
+		}
+	}
+	if len(errs) == 0 {
+		errs = append(errs, "certificate names no OCSP responder or CRL distribution point")
+	}
+	result.Err = fmt.Errorf("unable to check revocation of certificate %d: %s", cert.Id, strings.Join(errs, "; "))
+	return result
+}
+
+func finish(result Result) Result {
+	result.Mismatch = result.Status != StatusUnknown && (result.Status == StatusRevoked) != result.RecordedRevoked
+	return result
+}
+
+// checkOCSP asks the OCSP responders of leaf for its status, stopping at the first that answers.
+func (c *Checker) checkOCSP(ctx context.Context, leaf, issuer *x509.Certificate, result *Result) error {
+	req, err := ocsp.CreateRequest(leaf, issuer, nil)
+	if err != nil {
+		return err
+	}
+	var lastErr error
+	for _, server := range leaf.OCSPServer {
+		body, err := c.fetch(ctx, http.MethodPost, server, "application/ocsp-request", req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp, err := ocsp.ParseResponseForCert(body, leaf, issuer)
+		if err != nil {
+			lastErr = fmt.Errorf("invalid response from %s: %w", server, err)
+			continue
+		}
+		result.Source = SourceOCSP
+		switch resp.Status {
+		case ocsp.Good:
+			result.Status = StatusGood
+		case ocsp.Revoked:
+			result.Status = StatusRevoked
+			result.RevokedAt = resp.RevokedAt
+			result.Reason = resp.RevocationReason
+		default:
+			lastErr = fmt.Errorf("%s does not know the certificate", server)
+			result.Source = ""
+			continue
+		}
+		return nil
+	}
+	return lastErr
+}
+
+// checkCRL looks leaf up in the first of its CRLs that can be downloaded. The CRL signature is checked if the issuer
+// is known.
+func (c *Checker) checkCRL(ctx context.Context, leaf, issuer *x509.Certificate, result *Result) error {
+	var lastErr error
+	for _, url := range leaf.CRLDistributionPoints {
+		crl, err := c.crl(ctx, url, issuer)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		result.Source = SourceCRL
+		result.Status = StatusGood
+		for _, revoked := range crl.TBSCertList.RevokedCertificates {
+			if revoked.SerialNumber.Cmp(leaf.SerialNumber) != 0 {
+				continue
+			}
+			result.Status = StatusRevoked
+			result.RevokedAt = revoked.RevocationTime
+			result.Reason = crlReason(revoked.Extensions)
+			break
+		}
+		return nil
+	}
+	return lastErr
+}
+
+// crl returns the CRL at url, downloading it unless a cached copy is still current.
+func (c *Checker) crl(ctx context.Context, url string, issuer *x509.Certificate) (*pkix.CertificateList, error) {
+	now := time.Now()
+	if c.Now != nil {
+		now = c.Now()
+	}
+	c.mu.Lock()
+	cached := c.crls[url]
+	c.mu.Unlock()
+	if cached != nil && !cached.HasExpired(now) {
+		return cached, nil
+	}
+
+	body, err := c.fetch(ctx, http.MethodGet, url, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	crl, err := x509.ParseCRL(body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CRL at %s: %w", url, err)
+	}
+	if issuer != nil {
+		if err := issuer.CheckCRLSignature(crl); err != nil {
+			return nil, fmt.Errorf("CRL at %s is not signed by %s: %w", url, issuer.Subject, err)
+		}
+	}
+	if crl.HasExpired(now) {
+		return nil, fmt.Errorf("CRL at %s expired at %s", url, crl.TBSCertList.NextUpdate.Format(time.RFC3339))
+	}
+
+	c.mu.Lock()
+	if c.crls == nil {
+		c.crls = map[string]*pkix.CertificateList{}
+	}
+	c.crls[url] = crl
+	c.mu.Unlock()
+	return crl, nil
+}
+
+// issuer returns the issuer of leaf from Issuers, or downloaded from the CA issuers URL of leaf.
+func (c *Checker) issuer(ctx context.Context, leaf *x509.Certificate) (*x509.Certificate, error) {
+	for _, candidate := range c.Issuers {
+		if bytes.Equal(candidate.RawSubject, leaf.RawIssuer) && leaf.CheckSignatureFrom(candidate) == nil {
+			return candidate, nil
+		}
+	}
+	var lastErr error = fmt.Errorf("issuer %s is not known", leaf.Issuer)
+	for _, url := range leaf.IssuingCertificateURL {
+		c.mu.Lock()
+		cached := c.issuers[url]
+		c.mu.Unlock()
+		if cached != nil {
+			return cached, nil
+		}
+		body, err := c.fetch(ctx, http.MethodGet, url, "", nil)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		issuer, err := parseCertificate(string(body))
+		if err != nil {
+			lastErr = fmt.Errorf("invalid issuer certificate at %s: %w", url, err)
+			continue
+		}
+		if err := leaf.CheckSignatureFrom(issuer); err != nil {
+			lastErr = fmt.Errorf("certificate at %s did not issue the certificate: %w", url, err)
+			continue
+		}
+		c.mu.Lock()
+		if c.issuers == nil {
+			c.issuers = map[string]*x509.Certificate{}
+		}
+		c.issuers[url] = issuer
+		c.mu.Unlock()
+		return issuer, nil
+	}
+	return nil, lastErr
+}
+
+func (c *Checker) fetch(ctx context.Context, method, url, contentType string, body []byte) ([]byte, error) {
+	client := c.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s returned status %d", method, url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxResponseSize {
+		return nil, fmt.Errorf("response from %s is larger than %d bytes", url, maxResponseSize)
+	}
+	return data, nil
+}
+
+// parseCertificate parses a certificate encoded as PEM, base64 DER, as Keyfactor returns certificate content, or DER.
+func parseCertificate(content string) (*x509.Certificate, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, errors.New("certificate has no content; retrieve it with its content included")
+	}
+	if block, _ := pem.Decode([]byte(content)); block != nil {
+		return x509.ParseCertificate(block.Bytes)
+	}
+	if der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(content), "")); err == nil {
+		return x509.ParseCertificate(der)
+	}
+	return x509.ParseCertificate([]byte(content))
+}
+
+// oidCRLReason is the OID of the reason code extension of a CRL entry.
+var oidCRLReason = asn1.ObjectIdentifier{2, 5, 29, 21}
+
+// crlReason returns the reason code of a CRL entry, or 0 (unspecified) if it has none.
+func crlReason(exts []pkix.Extension) int {
+	for _, ext := range exts {
+		// The value is an ASN.1 ENUMERATED of a single byte.
+		if ext.Id.Equal(oidCRLReason) && len(ext.Value) == 3 && ext.Value[0] == 0x0a && ext.Value[1] == 1 {
+			return int(ext.Value[2])
+		}
+	}
+	return 0
+}
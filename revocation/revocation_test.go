@@ -0,0 +1,135 @@
+package revocation
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/Keyfactor/keyfactor-go-client/api"
+	"github.com/Keyfactor/keyfactor-go-client/enums"
+)
+
+func TestChecker_Check(t *testing.T) {
+	now := time.Now()
+	revokedAt := now.Add(-time.Hour).UTC().Truncate(time.Second)
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Example Issuing CA"},
+		NotBefore:             now.Add(-24 * time.Hour),
+		NotAfter:              now.Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+	}
+	caDER, _ := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	ca, _ := x509.ParseCertificate(caDER)
+
+	var ocspRequests, crlRequests, issuerRequests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ocsp":
+			ocspRequests++
+			body, _ := io.ReadAll(r.Body)
+			req, err := ocsp.ParseRequest(body)
+			if err != nil {
+				t.Errorf("invalid OCSP request: %v", err)
+				return
+			}
+			tmpl := ocsp.Response{Status: ocsp.Good, SerialNumber: req.SerialNumber, ThisUpdate: now, NextUpdate: now.Add(time.Hour)}
+			if req.SerialNumber.Int64() == 100 {
+				tmpl.Status, tmpl.RevokedAt, tmpl.RevocationReason = ocsp.Revoked, revokedAt, ocsp.KeyCompromise
+			}
+			resp, _ := ocsp.CreateResponse(ca, ca, tmpl, caKey)
+			w.Write(resp)
+		case "/ca.crl":
+			crlRequests++
+			reason, _ := asn1.Marshal(asn1.Enumerated(ocsp.Superseded))
+			crl, _ := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+				Number:     big.NewInt(1),
+				ThisUpdate: now.Add(-time.Hour),
+				NextUpdate: now.Add(time.Hour),
+				RevokedCertificates: []pkix.RevokedCertificate{{
+					SerialNumber: big.NewInt(100), RevocationTime: revokedAt,
+					Extensions: []pkix.Extension{{Id: oidCRLReason, Value: reason}},
+				}},
+			}, ca, caKey)
+			w.Write(crl)
+		case "/ca.crt":
+			issuerRequests++
+			w.Write(caDER)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	issue := func(serial int64, state enums.CertificateState) api.GetCertificateResponse {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber:          big.NewInt(serial),
+			Subject:               pkix.Name{CommonName: "www.example.com"},
+			NotBefore:             now.Add(-time.Hour),
+			NotAfter:              now.Add(time.Hour),
+			OCSPServer:            []string{srv.URL + "/ocsp"},
+			IssuingCertificateURL: []string{srv.URL + "/ca.crt"},
+			CRLDistributionPoints: []string{srv.URL + "/ca.crl"},
+		}, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return api.GetCertificateResponse{Id: int(serial), CertState: int(state), ContentBytes: base64.StdEncoding.EncodeToString(der)}
+	}
+	certs := []api.GetCertificateResponse{
+		issue(100, enums.CertificateStateActive),
+		issue(101, enums.CertificateStateActive),
+		issue(102, enums.CertificateStateRevoked),
+		{Id: 103},
+	}
+
+	checker := &Checker{}
+	results := checker.Check(context.Background(), certs)
+	want := []struct {
+		status   Status
+		mismatch bool
+	}{{StatusRevoked, true}, {StatusGood, false}, {StatusGood, true}, {StatusUnknown, false}}
+	for i, w := range want {
+		if r := results[i]; r.Status != w.status || r.Mismatch != w.mismatch || (r.Status != StatusUnknown && (r.Source != SourceOCSP || r.Err != nil)) {
+			t.Errorf("Check() result %d = %+v, want status %s mismatch %t", i, r, w.status, w.mismatch)
+		}
+	}
+	if r := results[0]; !r.RevokedAt.Equal(revokedAt) || r.Reason != ocsp.KeyCompromise {
+		t.Errorf("Check() revocation = %s reason %d", r.RevokedAt, r.Reason)
+	}
+	if results[3].Err == nil {
+		t.Errorf("Check() of a certificate without content succeeded, want error")
+	}
+	if issuerRequests != 1 {
+		t.Errorf("issuer downloaded %d times, want it cached", issuerRequests)
+	}
+
+	crlChecker := &Checker{DisableOCSP: true, Issuers: []*x509.Certificate{ca}}
+	results = crlChecker.Check(context.Background(), certs[:2])
+	if r := results[0]; r.Source != SourceCRL || r.Status != StatusRevoked || !r.Mismatch || r.Reason != ocsp.Superseded {
+		t.Errorf("Check() with CRLs = %+v", r)
+	}
+	if r := results[1]; r.Source != SourceCRL || r.Status != StatusGood || r.Mismatch {
+		t.Errorf("Check() with CRLs = %+v", r)
+	}
+	if crlRequests != 1 || ocspRequests != 3 {
+		t.Errorf("made %d CRL and %d OCSP requests, want 1 and 3", crlRequests, ocspRequests)
+	}
+}
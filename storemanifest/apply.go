@@ -0,0 +1,279 @@
+package storemanifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Keyfactor/keyfactor-go-client/api"
+)
+
+// ApplyOptions configures Apply.
+type ApplyOptions struct {
+	// DryRun reports the changes Apply would make without making them.
+	DryRun bool
+}
+
+// Action is what Apply did, or would do, to a store.
+type Action string
+
+// Actions of a Change.
+const (
+	ActionCreate    Action = "create"
+	ActionUpdate    Action = "update"
+	ActionUnchanged Action = "unchanged"
+)
+
+// Change describes what Apply did to one store of the manifest.
+type Change struct {
+	// Store is the Key of the store.
+	Store  string
+	Action Action
+	// Id is the Keyfactor ID of the store. It is empty for stores created in a dry run.
+	Id string
+	// Fields names the fields that differed from the manifest for ActionUpdate, e.g. "properties.ServerUseSsl".
+	Fields []string
+}
+
+// ApplyResult is the outcome of Apply.
+type ApplyResult struct {
+	// Changes holds one entry for each store of the manifest, in manifest order.
+	Changes []Change
+	DryRun  bool
+}
+
+// Count returns the number of stores Apply took the given action on.
+func (r *ApplyResult) Count(action Action) int {
+	n := 0
+	for _, c := range r.Changes {
+		if c.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Apply makes the certificate stores of Keyfactor match the manifest. Stores of the manifest that do not exist are
+// created, stores that differ in orchestrator, container, properties, or inventory schedule are updated, and other
+// stores are left alone. Stores that exist in Keyfactor but not in the manifest are never removed.
+//
+// The manifest is checked in full before any store is changed: an unknown store type, orchestrator, or container, or a
+// store listed twice, is returned as an error with nothing applied. If creating or updating a store fails, Apply stops
+// and returns the changes made so far along with the error. Stores are updated only if they have not changed since
+// Apply read them; one that has fails with an error wrapping api.ErrConflict.
+func Apply(c Client, m *Manifest, opts *ApplyOptions) (*ApplyResult, error) {
+	if opts == nil {
+		opts = &ApplyOptions{}
+	}
+	if m.Version > Version {
+		return nil, fmt.Errorf("store manifest version %d is newer than the supported version %d", m.Version, Version)
+	}
+	refs, err := loadReferences(c)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := c.ListAllCertificateStores("", nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list certificate stores: %w", err)
+	}
+	current := make(map[string]*api.GetCertificateStoreResponse, len(existing))
+	for i := range existing {
+		store := &existing[i]
+		current[storeKey(refs.typeNames[store.CertStoreType], store.ClientMachine, store.StorePath)] = store
+	}
+
+	desired, err := resolveStores(m, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ApplyResult{Changes: make([]Change, 0, len(desired)), DryRun: opts.DryRun}
+	for _, d := range desired {
+		change := Change{Store: d.key}
+		store, ok := current[d.key]
+		if !ok {
+			change.Action = ActionCreate
+			if !opts.DryRun {
+				args := d.createArgs(nil)
+				created, err := c.CreateStore(&args)
+				if err != nil {
+					return result, fmt.Errorf("unable to create certificate store %s: %w", d.key, err)
+				}
+				change.Id = created.Id
+			}
+			result.Changes = append(result.Changes, change)
+			continue
+		}
+
+		change.Id = store.Id
+		currentProps, err := decodeProperties(store.PropertiesString)
+		if err != nil {
+			return result, fmt.Errorf("certificate store %s: %w", d.key, err)
+		}
+		change.Fields = d.diff(store, currentProps)
+		if len(change.Fields) == 0 {
+			change.Action = ActionUnchanged
+			result.Changes = append(result.Changes, change)
+			continue
+		}
+		change.Action = ActionUpdate
+		if !opts.DryRun {
+			revision, err := store.Revision()
+			if err != nil {
+				return result, fmt.Errorf("certificate store %s: %w", d.key, err)
+			}
+			args := &api.UpdateStoreFctArgs{Id: store.Id, CreateStoreFctArgs: d.createArgs(currentProps), ExpectedRevision: revision}
+			if _, err := c.UpdateStore(args); err != nil {
+				return result, fmt.Errorf("unable to update certificate store %s: %w", d.key, err)
+			}
+		}
+		result.Changes = append(result.Changes, change)
+	}
+	return result, nil
+}
+
+// desiredStore is a store of a manifest with its names resolved to Keyfactor IDs.
+type desiredStore struct {
+	key         string
+	store       *Store
+	storeTypeId int
+	agentId     string
+	containerId *int
+}
+
+// resolveStores resolves the store types, orchestrators, and containers named in the manifest.
+func resolveStores(m *Manifest, refs *references) ([]desiredStore, error) {
+	containerIds := map[string]int{}
+	for id, container := range refs.containers {
+		containerIds[strings.ToLower(container.Name)] = id
+	}
+	for _, container := range m.Containers {
+		if _, ok := containerIds[strings.ToLower(container.Name)]; !ok {
+			return nil, fmt.Errorf("certificate store container %q does not exist", container.Name)
+		}
+	}
+
+	desired := make([]desiredStore, 0, len(m.Stores))
+	seen := map[string]bool{}
+	for i := range m.Stores {
+		s := &m.Stores[i]
+		if s.StoreType == "" || s.ClientMachine == "" || s.StorePath == "" {
+			return nil, fmt.Errorf("store %d of the manifest needs a storeType, clientMachine, and storePath", i+1)
+		}
+		typeId, ok := refs.typeIds[strings.ToLower(s.StoreType)]
+		if !ok {
+			return nil, fmt.Errorf("store %s: unknown certificate store type %q", s.Key(), s.StoreType)
+		}
+		d := desiredStore{key: storeKey(refs.typeNames[typeId], s.ClientMachine, s.StorePath), store: s, storeTypeId: typeId}
+		if seen[d.key] {
+			return nil, fmt.Errorf("store %s is listed more than once", d.key)
+		}
+		seen[d.key] = true
+
+		d.agentId = s.AgentId
+		if d.agentId == "" {
+			if d.agentId = refs.agentIds[strings.ToLower(s.Orchestrator)]; d.agentId == "" {
+				return nil, fmt.Errorf("store %s: unknown orchestrator %q", d.key, s.Orchestrator)
+			}
+		}
+		if s.Container != "" {
+			id, ok := containerIds[strings.ToLower(s.Container)]
+			if !ok {
+				return nil, fmt.Errorf("store %s: certificate store container %q does not exist", d.key, s.Container)
+			}
+			d.containerId = &id
+		}
+		desired = append(desired, d)
+	}
+	return desired, nil
+}
+
+// createArgs returns the arguments to create the store, or to update it given the properties it currently has. Secret
+// properties the manifest does not set are sent back as Keyfactor returned them, so they are kept.
+func (d *desiredStore) createArgs(currentProps map[string]interface{}) api.CreateStoreFctArgs {
+	props := map[string]interface{}{}
+	for name, value := range currentProps {
+		if _, secret := value.(map[string]interface{}); secret {
+			props[name] = value
+		}
+	}
+	for name, value := range d.store.Properties {
+		props[name] = value
+	}
+	for name, secret := range d.store.Secrets {
+		props[name] = secret
+	}
+	return api.CreateStoreFctArgs{
+		ContainerId:       d.containerId,
+		ClientMachine:     d.store.ClientMachine,
+		StorePath:         d.store.StorePath,
+		CertStoreType:     d.storeTypeId,
+		AgentId:           d.agentId,
+		Properties:        props,
+		InventorySchedule: d.store.InventorySchedule,
+	}
+}
+
+// diff returns the fields in which the store differs from the manifest, sorted.
+func (d *desiredStore) diff(store *api.GetCertificateStoreResponse, currentProps map[string]interface{}) []string {
+	var fields []string
+	if !strings.EqualFold(store.AgentId, d.agentId) {
+		fields = append(fields, "agent")
+	}
+	containerId := 0
+	if d.containerId != nil {
+		containerId = *d.containerId
+	}
+	if store.ContainerId != containerId {
+		fields = append(fields, "container")
+	}
+	schedule := api.InventorySchedule{}
+	if d.store.InventorySchedule != nil {
+		schedule = *d.store.InventorySchedule
+	}
+	if !store.InventorySchedule.Equal(schedule) {
+		fields = append(fields, "inventorySchedule")
+	}
+	var changed []string
+	for name, want := range d.store.Properties {
+		if got, ok := currentProps[name]; !ok || !sameValue(got, want) {
+			changed = append(changed, "properties."+name)
+		}
+	}
+	sort.Strings(changed)
+	return append(fields, changed...)
+}
+
+// decodeProperties decodes the properties of a store as returned by Keyfactor, unwrapping their values.
+func decodeProperties(propertiesString string) (map[string]interface{}, error) {
+	if strings.TrimSpace(propertiesString) == "" {
+		return nil, nil
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(propertiesString), &raw); err != nil {
+		return nil, fmt.Errorf("invalid properties: %w", err)
+	}
+	for name, value := range raw {
+		raw[name] = unwrapProperty(value)
+	}
+	return raw, nil
+}
+
+// sameValue compares property values loosely, as Keyfactor returns every property as a string while manifests may
+// hold booleans and numbers.
+func sameValue(a, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b) && isScalar(a) && isScalar(b)
+}
+
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case string, bool, float64, int, json.Number:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,279 @@
+// Package storemanifest keeps certificate stores as code. Export dumps the certificate stores of a Keyfactor Command
+// instance, with the containers they belong to and the PAM provider references of their secrets, into a declarative
+// Manifest, and Apply makes Keyfactor match a manifest, creating and updating stores as needed. Applying the same
+// manifest twice changes nothing the second time.
+//
+// Manifests are written as indented JSON, which YAML parsers read as well, so they can be kept and reviewed alongside
+// other YAML configuration. The Manifest types carry yaml struct tags, so a manifest authored in YAML can be decoded
+// with any YAML library and passed to Apply.
+//
+//	m, err := storemanifest.Export(client, "")
+//	if err != nil {
+//		return err
+//	}
+//	err = m.Write(f)
+//	...
+//	result, err := storemanifest.Apply(client, m, &storemanifest.ApplyOptions{DryRun: true})
+package storemanifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Keyfactor/keyfactor-go-client/api"
+)
+
+// Version is the version of the manifest format written by Export.
+const Version = 1
+
+// Client is the subset of *api.Client used to export and apply manifests.
+type Client interface {
+	ListAllCertificateStores(q string, opts *api.FetchAllOptions) ([]api.GetCertificateStoreResponse, error)
+	ListCertificateStoreTypes() (*[]api.CertificateStoreType, error)
+	GetStoreContainers() (*[]api.CertStoreContainer, error)
+	GetAgentList() ([]api.Agent, error)
+	CreateStore(ca *api.CreateStoreFctArgs) (*api.CreateStoreResponse, error)
+	UpdateStore(ua *api.UpdateStoreFctArgs) (*api.UpdateStoreResponse, error)
+}
+
+// Manifest declares a set of certificate stores.
+type Manifest struct {
+	Version int `json:"version" yaml:"version"`
+	// Containers lists the certificate store containers the stores belong to. Apply does not create containers; it
+	// returns an error if one does not exist.
+	Containers []Container `json:"containers,omitempty" yaml:"containers,omitempty"`
+	Stores     []Store     `json:"stores" yaml:"stores"`
+}
+
+// Container is a certificate store container.
+type Container struct {
+	Name string `json:"name" yaml:"name"`
+	// StoreType is the short name of the store type of the container's stores.
+	StoreType string `json:"storeType" yaml:"storeType"`
+}
+
+// Store is a certificate store. A store is identified by its store type, client machine, and store path.
+type Store struct {
+	// StoreType is the short name of the store's type, such as "IISU".
+	StoreType     string `json:"storeType" yaml:"storeType"`
+	ClientMachine string `json:"clientMachine" yaml:"clientMachine"`
+	StorePath     string `json:"storePath" yaml:"storePath"`
+	// AgentId is the ID of the orchestrator that manages the store. If it is empty, the orchestrator is found by
+	// Orchestrator, its client machine name, which keeps a manifest portable between Keyfactor instances.
+	AgentId      string `json:"agentId,omitempty" yaml:"agentId,omitempty"`
+	Orchestrator string `json:"orchestrator,omitempty" yaml:"orchestrator,omitempty"`
+	// Container is the name of the container the store belongs to, if any.
+	Container string `json:"container,omitempty" yaml:"container,omitempty"`
+	// Properties are the store's properties other than secrets.
+	Properties map[string]interface{} `json:"properties,omitempty" yaml:"properties,omitempty"`
+	// Secrets are the store's secret properties. Export only fills in references to PAM providers; secrets held in
+	// the Keyfactor database are never exported. Keyfactor does not return secrets to compare against, so a secret
+	// does not by itself cause a store to be updated; it is sent whenever the store is created or updated.
+	Secrets           map[string]*api.StoreSecret `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+	InventorySchedule *api.InventorySchedule      `json:"inventorySchedule,omitempty" yaml:"inventorySchedule,omitempty"`
+}
+
+// Key returns the identity of the store, e.g. "IISU:web01.example.com:IIS Personal".
+func (s *Store) Key() string {
+	return storeKey(s.StoreType, s.ClientMachine, s.StorePath)
+}
+
+func storeKey(storeType, clientMachine, storePath string) string {
+	return fmt.Sprintf("%s:%s:%s", storeType, strings.ToLower(clientMachine), storePath)
+}
+
+// Read reads a manifest written by Write.
+func Read(r io.Reader) (*Manifest, error) {
+	m := &Manifest{}
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(m); err != nil {
+		return nil, fmt.Errorf("invalid store manifest: %w", err)
+	}
+	if m.Version > Version {
+		return nil, fmt.Errorf("store manifest version %d is newer than the supported version %d", m.Version, Version)
+	}
+	return m, nil
+}
+
+// Write writes the manifest as indented JSON, with stores sorted by Key.
+func (m *Manifest) Write(w io.Writer) error {
+	out := *m
+	if out.Version == 0 {
+		out.Version = Version
+	}
+	out.Stores = append([]Store(nil), m.Stores...)
+	sort.SliceStable(out.Stores, func(i, j int) bool { return out.Stores[i].Key() < out.Stores[j].Key() })
+	data, err := json.MarshalIndent(&out, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// Export returns a manifest of the certificate stores matching q, a query string such as one built with the query
+// package. An empty query exports every store.
+func Export(c Client, q string) (*Manifest, error) {
+	refs, err := loadReferences(c)
+	if err != nil {
+		return nil, err
+	}
+	stores, err := c.ListAllCertificateStores(q, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list certificate stores: %w", err)
+	}
+
+	m := &Manifest{Version: Version, Stores: make([]Store, 0, len(stores))}
+	containers := map[string]Container{}
+	for _, store := range stores {
+		storeType := refs.typeNames[store.CertStoreType]
+		if storeType == "" {
+			return nil, fmt.Errorf("certificate store %s has unknown store type %d", store.Id, store.CertStoreType)
+		}
+		props, secrets, err := splitProperties(store.PropertiesString)
+		if err != nil {
+			return nil, fmt.Errorf("certificate store %s: %w", store.Id, err)
+		}
+		s := Store{
+			StoreType:     storeType,
+			ClientMachine: store.ClientMachine,
+			StorePath:     store.StorePath,
+			AgentId:       store.AgentId,
+			Orchestrator:  refs.agentNames[strings.ToLower(store.AgentId)],
+			Properties:    props,
+			Secrets:       secrets,
+		}
+		if store.ContainerId > 0 {
+			if container, ok := refs.containers[store.ContainerId]; ok {
+				s.Container = container.Name
+				containers[container.Name] = Container{Name: container.Name, StoreType: refs.typeNames[container.CertStoreType]}
+			}
+		}
+		if schedule := store.InventorySchedule; !schedule.Equal(api.InventorySchedule{}) {
+			s.InventorySchedule = &schedule
+		}
+		m.Stores = append(m.Stores, s)
+	}
+	for _, container := range containers {
+		m.Containers = append(m.Containers, container)
+	}
+	sort.Slice(m.Containers, func(i, j int) bool { return m.Containers[i].Name < m.Containers[j].Name })
+	sort.SliceStable(m.Stores, func(i, j int) bool { return m.Stores[i].Key() < m.Stores[j].Key() })
+	return m, nil
+}
+
+// references holds the store types, containers, and orchestrators of a Keyfactor instance, for resolving names.
+type references struct {
+	typeNames  map[int]string
+	typeIds    map[string]int
+	containers map[int]api.CertStoreContainer
+	agentNames map[string]string
+	agentIds   map[string]string
+}
+
+func loadReferences(c Client) (*references, error) {
+	refs := &references{
+		typeNames:  map[int]string{},
+		typeIds:    map[string]int{},
+		containers: map[int]api.CertStoreContainer{},
+		agentNames: map[string]string{},
+		agentIds:   map[string]string{},
+	}
+	types, err := c.ListCertificateStoreTypes()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list certificate store types: %w", err)
+	}
+	for _, t := range *types {
+		refs.typeNames[t.StoreType] = t.ShortName
+		refs.typeIds[strings.ToLower(t.ShortName)] = t.StoreType
+	}
+	containers, err := c.GetStoreContainers()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list certificate store containers: %w", err)
+	}
+	for _, container := range *containers {
+		if container.Id != nil {
+			refs.containers[*container.Id] = container
+		}
+	}
+	agents, err := c.GetAgentList()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list orchestrators: %w", err)
+	}
+	for _, agent := range agents {
+		refs.agentNames[strings.ToLower(agent.AgentId)] = agent.ClientMachine
+		refs.agentIds[strings.ToLower(agent.ClientMachine)] = agent.AgentId
+	}
+	return refs, nil
+}
+
+// splitProperties decodes the properties of a store as returned by Keyfactor into its plain properties and the PAM
+// provider references of its secrets. Secrets held in the Keyfactor database are dropped.
+func splitProperties(propertiesString string) (map[string]interface{}, map[string]*api.StoreSecret, error) {
+	raw, err := decodeProperties(propertiesString)
+	if err != nil || len(raw) == 0 {
+		return nil, nil, err
+	}
+	props := map[string]interface{}{}
+	secrets := map[string]*api.StoreSecret{}
+	for name, value := range raw {
+		secret, ok := value.(map[string]interface{})
+		if !ok {
+			props[name] = value
+			continue
+		}
+		if ref := pamReference(secret); ref != nil {
+			secrets[name] = ref
+		}
+	}
+	if len(props) == 0 {
+		props = nil
+	}
+	if len(secrets) == 0 {
+		secrets = nil
+	}
+	return props, secrets, nil
+}
+
+// unwrapProperty returns the value of a property Keyfactor wraps as {"value": ...}.
+func unwrapProperty(value interface{}) interface{} {
+	if wrapped, ok := value.(map[string]interface{}); ok && len(wrapped) == 1 {
+		if v, ok := wrapped["value"]; ok {
+			return v
+		}
+	}
+	return value
+}
+
+// pamReference returns the PAM provider reference of a secret property, or nil if the secret is not held by a PAM
+// provider.
+func pamReference(secret map[string]interface{}) *api.StoreSecret {
+	provider, _ := secret["Provider"].(float64)
+	if provider == 0 {
+		provider, _ = secret["ProviderId"].(float64)
+	}
+	if provider == 0 {
+		return nil
+	}
+	ref := &api.StoreSecret{Provider: int(provider), Parameters: map[string]string{}}
+	if params, ok := secret["Parameters"].(map[string]interface{}); ok {
+		for k, v := range params {
+			ref.Parameters[k] = fmt.Sprint(v)
+		}
+	}
+	if values, ok := secret["ProviderTypeParameterValues"].([]interface{}); ok {
+		for _, v := range values {
+			entry, _ := v.(map[string]interface{})
+			param, _ := entry["ProviderTypeParam"].(map[string]interface{})
+			name, _ := param["Name"].(string)
+			if name != "" && entry["Value"] != nil {
+				ref.Parameters[name] = fmt.Sprint(entry["Value"])
+			}
+		}
+	}
+	return ref
+}
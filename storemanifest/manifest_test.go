@@ -0,0 +1,203 @@
+package storemanifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Keyfactor/keyfactor-go-client/api"
+)
+
+var _ Client = (*api.Client)(nil)
+
+type fakeClient struct {
+	stores  []api.GetCertificateStoreResponse
+	created []api.CreateStoreFctArgs
+	updated []api.UpdateStoreFctArgs
+}
+
+func (f *fakeClient) ListAllCertificateStores(string, *api.FetchAllOptions) ([]api.GetCertificateStoreResponse, error) {
+	return f.stores, nil
+}
+
+func (f *fakeClient) ListCertificateStoreTypes() (*[]api.CertificateStoreType, error) {
+	return &[]api.CertificateStoreType{{StoreType: 2, ShortName: "IISU"}, {StoreType: 7, ShortName: "RFPEM"}}, nil
+}
+
+func (f *fakeClient) GetStoreContainers() (*[]api.CertStoreContainer, error) {
+	id := 3
+	return &[]api.CertStoreContainer{{Id: &id, Name: "Web Servers", CertStoreType: 2}}, nil
+}
+
+func (f *fakeClient) GetAgentList() ([]api.Agent, error) {
+	return []api.Agent{{AgentId: "agent-1", ClientMachine: "orch01"}}, nil
+}
+
+func (f *fakeClient) CreateStore(ca *api.CreateStoreFctArgs) (*api.CreateStoreResponse, error) {
+	f.created = append(f.created, *ca)
+	f.stores = append(f.stores, storeFromArgs("new-store", ca))
+	return &api.CreateStoreResponse{Id: "new-store"}, nil
+}
+
+func (f *fakeClient) UpdateStore(ua *api.UpdateStoreFctArgs) (*api.UpdateStoreResponse, error) {
+	f.updated = append(f.updated, *ua)
+	for i := range f.stores {
+		if f.stores[i].Id == ua.Id {
+			if revision, _ := f.stores[i].Revision(); ua.ExpectedRevision != revision {
+				return nil, api.ErrConflict
+			}
+			f.stores[i] = storeFromArgs(ua.Id, &ua.CreateStoreFctArgs)
+		}
+	}
+	return &api.UpdateStoreResponse{}, nil
+}
+
+// storeFromArgs returns the store Keyfactor reports after creating or updating it with the given arguments.
+func storeFromArgs(id string, ca *api.CreateStoreFctArgs) api.GetCertificateStoreResponse {
+	props := map[string]interface{}{}
+	for name, value := range ca.Properties {
+		if _, secret := value.(*api.StoreSecret); secret {
+			value = map[string]interface{}{"SecretValue": nil}
+		} else {
+			value = fmtValue(value)
+		}
+		props[name] = map[string]interface{}{"value": value}
+	}
+	data, _ := json.Marshal(props)
+	store := api.GetCertificateStoreResponse{
+		Id: id, ClientMachine: ca.ClientMachine, StorePath: ca.StorePath, CertStoreType: ca.CertStoreType,
+		AgentId: ca.AgentId, PropertiesString: string(data),
+	}
+	if ca.ContainerId != nil {
+		store.ContainerId = *ca.ContainerId
+	}
+	if ca.InventorySchedule != nil {
+		store.InventorySchedule = *ca.InventorySchedule
+	}
+	return store
+}
+
+func fmtValue(v interface{}) interface{} {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m
+	}
+	data, _ := json.Marshal(v)
+	return strings.Trim(string(data), `"`)
+}
+
+func TestExportApply(t *testing.T) {
+	c := &fakeClient{stores: []api.GetCertificateStoreResponse{
+		{
+			Id: "store-1", ClientMachine: "web01", StorePath: "IIS Personal", CertStoreType: 2, AgentId: "agent-1",
+			ContainerId: 3,
+			PropertiesString: `{"ServerUseSsl": {"value": "true"}, "WinRm Port": "5986",
+				"ServerUsername": {"value": {"Provider": 4, "Parameters": {"SecretPath": "win/admin"}}},
+				"ServerPassword": {"value": {"SecretValue": null}}}`,
+			InventorySchedule: api.InventorySchedule{Interval: &api.InventoryInterval{Minutes: 60}},
+		},
+	}}
+
+	m, err := Export(c, "")
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	want := Store{
+		StoreType: "IISU", ClientMachine: "web01", StorePath: "IIS Personal", AgentId: "agent-1", Orchestrator: "orch01",
+		Container:  "Web Servers",
+		Properties: map[string]interface{}{"ServerUseSsl": "true", "WinRm Port": "5986"},
+		Secrets: map[string]*api.StoreSecret{
+			"ServerUsername": {Provider: 4, Parameters: map[string]string{"SecretPath": "win/admin"}},
+		},
+		InventorySchedule: &api.InventorySchedule{Interval: &api.InventoryInterval{Minutes: 60}},
+	}
+	if len(m.Stores) != 1 || !reflect.DeepEqual(m.Stores[0], want) {
+		t.Fatalf("Export() stores = %+v, want %+v", m.Stores, want)
+	}
+	if want := []Container{{Name: "Web Servers", StoreType: "IISU"}}; !reflect.DeepEqual(m.Containers, want) {
+		t.Errorf("Export() containers = %+v, want %+v", m.Containers, want)
+	}
+
+	var buf bytes.Buffer
+	if err := m.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "SecretValue") {
+		t.Errorf("Write() wrote a secret value:\n%s", buf.String())
+	}
+	m, err = Read(&buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	result, err := Apply(c, m, nil)
+	if err != nil {
+		t.Fatalf("Apply() of the exported manifest error = %v", err)
+	}
+	if n := result.Count(ActionUnchanged); n != 1 || len(c.updated) != 0 {
+		t.Fatalf("Apply() of the exported manifest = %+v, want no changes", result.Changes)
+	}
+
+	m.Stores[0].Properties["ServerUseSsl"] = false
+	m.Stores = append(m.Stores, Store{
+		StoreType: "rfpem", ClientMachine: "linux01", StorePath: "/etc/ssl/app.pem", Orchestrator: "ORCH01",
+		Properties: map[string]interface{}{"IsRSAPrivateKey": true},
+	})
+
+	result, err = Apply(c, m, &ApplyOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Apply() dry run error = %v", err)
+	}
+	wantChanges := []Change{
+		{Store: "IISU:web01:IIS Personal", Action: ActionUpdate, Id: "store-1", Fields: []string{"properties.ServerUseSsl"}},
+		{Store: "RFPEM:linux01:/etc/ssl/app.pem", Action: ActionCreate},
+	}
+	if !reflect.DeepEqual(result.Changes, wantChanges) || len(c.created)+len(c.updated) != 0 {
+		t.Fatalf("Apply() dry run = %+v, want %+v and no calls", result.Changes, wantChanges)
+	}
+
+	if _, err := Apply(c, m, nil); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(c.created) != 1 || c.created[0].AgentId != "agent-1" || c.created[0].CertStoreType != 7 {
+		t.Errorf("Apply() created %+v", c.created)
+	}
+	if len(c.updated) != 1 {
+		t.Fatalf("Apply() updated %d stores, want 1", len(c.updated))
+	}
+	props := c.updated[0].Properties
+	if props["ServerUseSsl"] != false || props["ServerUsername"] == nil || props["ServerPassword"] == nil {
+		t.Errorf("Apply() updated properties = %v, want kept secrets", props)
+	}
+
+	result, err = Apply(c, m, nil)
+	if err != nil || result.Count(ActionUnchanged) != 2 {
+		t.Errorf("second Apply() = %+v, %v, want no changes", result, err)
+	}
+}
+
+func TestApply_InvalidManifest(t *testing.T) {
+	tests := []struct {
+		name  string
+		store Store
+		want  string
+	}{
+		{name: "StoreType", store: Store{StoreType: "K8S", ClientMachine: "a", StorePath: "b", AgentId: "x"}, want: "unknown certificate store type"},
+		{name: "Orchestrator", store: Store{StoreType: "IISU", ClientMachine: "a", StorePath: "b", Orchestrator: "orch09"}, want: "unknown orchestrator"},
+		{name: "Container", store: Store{StoreType: "IISU", ClientMachine: "a", StorePath: "b", AgentId: "x", Container: "DB"}, want: "does not exist"},
+		{name: "Identity", store: Store{StoreType: "IISU", ClientMachine: "a"}, want: "needs a storeType"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &fakeClient{}
+			_, err := Apply(c, &Manifest{Stores: []Store{tt.store}}, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Apply() error = %v, want %q", err, tt.want)
+			}
+			if len(c.created) != 0 {
+				t.Errorf("Apply() created stores from an invalid manifest")
+			}
+		})
+	}
+}